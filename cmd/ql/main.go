@@ -4,7 +4,9 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -20,14 +22,22 @@ import (
 	"golang.org/x/term"
 
 	"github.com/quantumlife/quantumlife/internal/agent"
+	"github.com/quantumlife/quantumlife/internal/config"
 	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/embeddings"
+	"github.com/quantumlife/quantumlife/internal/eval"
+	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/identity"
+	"github.com/quantumlife/quantumlife/internal/learning"
 	"github.com/quantumlife/quantumlife/internal/llm"
+	"github.com/quantumlife/quantumlife/internal/llm/llmconfig"
 	"github.com/quantumlife/quantumlife/internal/memory"
 	"github.com/quantumlife/quantumlife/internal/spaces/calendar"
+	"github.com/quantumlife/quantumlife/internal/spaces/files"
 	"github.com/quantumlife/quantumlife/internal/spaces/gmail"
+	"github.com/quantumlife/quantumlife/internal/spaces/imap"
 	"github.com/quantumlife/quantumlife/internal/storage"
+	"github.com/quantumlife/quantumlife/internal/triage"
 	"github.com/quantumlife/quantumlife/internal/vectors"
 )
 
@@ -78,6 +88,8 @@ For more information, visit: https://github.com/quantumlife-hq/quantumlife`,
 	rootCmd.AddCommand(chatCmd())
 	rootCmd.AddCommand(spacesCmd())
 	rootCmd.AddCommand(calendarCmd())
+	rootCmd.AddCommand(evalCmd())
+	rootCmd.AddCommand(financeCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -485,7 +497,59 @@ func memoryCmd() *cobra.Command {
 		},
 	}
 
-	cmd.AddCommand(storeCmd, searchCmd, listCmd, statsCmd)
+	// memory reindex
+	reindexCmd := &cobra.Command{
+		Use:   "reindex",
+		Short: "Re-embed memories and items with the configured embedding model",
+		Long: `Re-embeds all memories and items into new vector collections using the
+current embedding model, then switches search over to them in one step.
+
+Run this after changing OLLAMA_EMBED_MODEL. An interrupted reindex resumes
+where it stopped when run again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			batchSize, _ := cmd.Flags().GetInt("batch-size")
+
+			db, vectorStore, embedder, err := initComponents()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			defer vectorStore.Close()
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			fmt.Printf("Reindexing with %s (%d dimensions)...\n", embedder.ModelName(), embedder.Dimension())
+
+			reindexer := memory.NewReindexer(db, vectorStore, embedder)
+			reindexer.BatchSize = batchSize
+			reindexer.OnBatch = func(collection string, embedded int) {
+				fmt.Printf("\r   %s: %d embedded", collection, embedded)
+			}
+
+			results, err := reindexer.Run(ctx)
+			fmt.Println()
+			if err != nil {
+				if ctx.Err() != nil {
+					fmt.Println("Interrupted. Run 'ql memory reindex' again to resume.")
+				}
+				return err
+			}
+
+			for _, r := range results {
+				resumed := ""
+				if r.Resumed {
+					resumed = " (resumed)"
+				}
+				fmt.Printf("   %s -> %s: %d embedded%s\n", r.Collection, r.Shadow, r.Embedded, resumed)
+			}
+			fmt.Println("Reindex complete. Search now uses the new collections.")
+			return nil
+		},
+	}
+	reindexCmd.Flags().Int("batch-size", 32, "Texts per embedding request")
+
+	cmd.AddCommand(storeCmd, searchCmd, listCmd, statsCmd, reindexCmd)
 	return cmd
 }
 
@@ -575,12 +639,16 @@ func agentCmd() *cobra.Command {
 				fmt.Println("   [!!] API Key: Not set (export ANTHROPIC_API_KEY)")
 			}
 
-			// Check Qdrant
-			vectorStore, err := vectors.NewStore(vectors.DefaultConfig())
+			// Check vector index
+			vectorStore, err := openVectorIndex()
 			if err != nil {
-				fmt.Println("   [!!] Qdrant: Not running")
+				fmt.Println("   [!!] Vectors: Not available")
 			} else {
-				fmt.Println("   [OK] Qdrant: Connected")
+				if _, ok := vectorStore.(*vectors.EmbeddedStore); ok {
+					fmt.Println("   [OK] Vectors: Embedded index")
+				} else {
+					fmt.Println("   [OK] Vectors: Qdrant connected")
+				}
 				vectorStore.Close()
 			}
 
@@ -657,8 +725,475 @@ The agent can help you:
 	}
 }
 
+// evalCmd measures model quality offline
+func evalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate model quality offline",
+	}
+
+	defaultDataset := func() string {
+		return filepath.Join(dataDir, "eval", "triage.jsonl")
+	}
+
+	// eval seed
+	seedCmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Build the triage dataset from fixtures and your corrections",
+		Long: `Adds cases to the triage golden dataset: the labelled emails in
+testutil/fixtures.go and the items you moved to another hat or
+reprioritized. Cases already in the dataset are replaced by newer ones.
+
+The dataset is JSON lines, one case per line, and can be edited by hand.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("dataset")
+			noFixtures, _ := cmd.Flags().GetBool("no-fixtures")
+			days, _ := cmd.Flags().GetInt("days")
+			if path == "" {
+				path = defaultDataset()
+			}
+
+			dataset, err := eval.Load(path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			before := len(dataset)
+
+			if !noFixtures {
+				fixtures := eval.FromFixtures()
+				dataset = eval.Merge(dataset, fixtures...)
+				fmt.Printf("   Fixtures: %d cases\n", len(fixtures))
+			}
+
+			db, err := openEvalDB()
+			if err != nil {
+				return err
+			}
+			if db != nil {
+				defer db.Close()
+				since := time.Now().AddDate(0, 0, -days)
+				overrides, err := eval.FromOverrides(context.Background(),
+					learning.NewCollector(db), storage.NewItemStore(db), since)
+				if err != nil {
+					return fmt.Errorf("failed to load corrections: %w", err)
+				}
+				dataset = eval.Merge(dataset, overrides...)
+				fmt.Printf("   Corrections: %d cases from the last %d days\n", len(overrides), days)
+			}
+
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+			if err := eval.Save(path, dataset); err != nil {
+				return err
+			}
+			fmt.Printf("Dataset %s: %d cases (%d new)\n", path, len(dataset), len(dataset)-before)
+			return nil
+		},
+	}
+	seedCmd.Flags().String("dataset", "", "Dataset file (default <data-dir>/eval/triage.jsonl)")
+	seedCmd.Flags().Bool("no-fixtures", false, "Only add your corrections")
+	seedCmd.Flags().Int("days", 90, "Include corrections from this many days back")
+
+	// eval triage
+	triageCmd := &cobra.Command{
+		Use:   "triage",
+		Short: "Replay the triage dataset and report accuracy",
+		Long: `Triages every case in the golden dataset and compares the result with
+the expected hat, priority and actions. Reports accuracy, per-hat
+precision and recall, the hat confusion matrix and latency.
+
+The router is set up from config.json as the daemon does. Use --provider
+and --model to compare one model against another. Memory and sender
+context are not used, so results depend on the prompt and model only.
+Without a dataset the fixtures are used.`,
+		Example: `  # Build the dataset, then evaluate the configured router
+  ql eval seed
+  ql eval triage
+
+  # Compare two local models
+  ql eval triage --provider ollama --model llama3.2
+  ql eval triage --provider ollama --model qwen2.5:7b`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("dataset")
+			provider, _ := cmd.Flags().GetString("provider")
+			model, _ := cmd.Flags().GetString("model")
+			maxRepairs, _ := cmd.Flags().GetInt("max-repairs")
+			limit, _ := cmd.Flags().GetInt("limit")
+			asJSON, _ := cmd.Flags().GetBool("json")
+
+			if path == "" {
+				path = defaultDataset()
+			}
+			cases, err := eval.Load(path)
+			if os.IsNotExist(err) && !cmd.Flags().Changed("dataset") {
+				fmt.Fprintf(os.Stderr, "No dataset at %s, using fixtures. Run 'ql eval seed' to add your corrections.\n", path)
+				cases, err = eval.FromFixtures(), nil
+			}
+			if err != nil {
+				return err
+			}
+			if limit > 0 && limit < len(cases) {
+				cases = cases[:limit]
+			}
+			if len(cases) == 0 {
+				return fmt.Errorf("dataset %s has no cases", path)
+			}
+
+			appCfg, err := config.Load(filepath.Join(dataDir, "config.json"))
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			routerCfg, err := evalRouterConfig(appCfg.LLM, provider, model)
+			if err != nil {
+				return err
+			}
+			routerCfg.MaxRepairs = maxRepairs
+			router := llm.NewRouter(routerCfg)
+
+			engine := triage.NewEngine(router, nil, triage.EngineConfig{
+				HighConfidence:   0.85,
+				MediumConfidence: 0.6,
+			})
+
+			// Hat sensitivity and redaction apply as they do in the daemon
+			db, err := openEvalDB()
+			if err != nil {
+				return err
+			}
+			if db != nil {
+				defer db.Close()
+				engine.SetHatStore(storage.NewHatStore(db))
+				if !appCfg.LLM.ConfidentialLocalOnly {
+					entities := storage.NewEntityStore(db)
+					router.SetScrubber(llm.NewScrubber(func() []string {
+						names, _ := entities.Names(core.EntityKindPerson)
+						return names
+					}))
+				}
+			}
+
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			runner := eval.NewRunner(engine)
+			runner.OnCase = func(done, total int, result eval.Result) {
+				fmt.Fprintf(os.Stderr, "\r   %d/%d triaged", done, total)
+			}
+			report, runErr := runner.Run(ctx, cases)
+			fmt.Fprintln(os.Stderr)
+			if runErr != nil {
+				fmt.Fprintf(os.Stderr, "Interrupted after %d of %d cases.\n", report.Cases, len(cases))
+			}
+
+			stats := router.GetStats()
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(map[string]interface{}{
+					"dataset": path,
+					"report":  report,
+					"router":  stats,
+				})
+			}
+
+			fmt.Printf("Triage evaluation of %s\n\n", path)
+			report.Print(os.Stdout)
+			fmt.Println("\nProviders:")
+			for _, p := range []llm.Provider{llm.ProviderOllama, llm.ProviderClaude, llm.ProviderAzure} {
+				s, ok := stats.Providers[p]
+				if !ok || s.Requests == 0 {
+					continue
+				}
+				fmt.Printf("   %s: %d requests, %d failed, %.0f%% invalid output, p50 %dms, $%.4f\n",
+					p, s.Requests, s.Failures, 100*s.ParseFailureRate, s.P50LatencyMs, s.CostUSD)
+			}
+			return nil
+		},
+	}
+	triageCmd.Flags().String("dataset", "", "Dataset file (default <data-dir>/eval/triage.jsonl)")
+	triageCmd.Flags().String("provider", "", "Only use this provider: ollama, claude or azure")
+	triageCmd.Flags().String("model", "", "Model for --provider (Azure: deployment)")
+	triageCmd.Flags().Int("max-repairs", 2, "Repair attempts for invalid output, -1 for none")
+	triageCmd.Flags().Int("limit", 0, "Only evaluate the first N cases")
+	triageCmd.Flags().Bool("json", false, "Print the report as JSON")
+
+	cmd.AddCommand(seedCmd, triageCmd)
+	return cmd
+}
+
+// evalRouterConfig sets up the router like the daemon does, or with only
+// provider, and fallback off, when one is given
+func evalRouterConfig(cfg config.LLMConfig, provider, model string) (llm.RouterConfig, error) {
+	routerCfg := llmconfig.RouterConfig(cfg)
+
+	claudeCfg := llm.DefaultConfig()
+	ollamaCfg := llm.DefaultOllamaConfig()
+	azureCfg := llm.DefaultAzureConfig()
+
+	switch llm.Provider(provider) {
+	case "":
+		if model != "" {
+			return routerCfg, fmt.Errorf("--model needs --provider")
+		}
+		routerCfg.Claude = llm.NewClient(claudeCfg)
+		routerCfg.Ollama = llm.NewOllamaClient(ollamaCfg)
+		if azure := llm.NewAzureClient(azureCfg); azure.IsConfigured() {
+			routerCfg.Azure = azure
+		}
+		return routerCfg, nil
+	case llm.ProviderClaude:
+		if model != "" {
+			claudeCfg.Model = model
+		}
+		routerCfg.Claude = llm.NewClient(claudeCfg)
+		if !routerCfg.Claude.IsConfigured() {
+			return routerCfg, fmt.Errorf("ANTHROPIC_API_KEY not set")
+		}
+	case llm.ProviderOllama:
+		if model != "" {
+			ollamaCfg.Model = model
+		}
+		routerCfg.Ollama = llm.NewOllamaClient(ollamaCfg)
+		if !routerCfg.Ollama.IsConfigured() {
+			return routerCfg, fmt.Errorf("Ollama not available at %s", ollamaCfg.BaseURL)
+		}
+	case llm.ProviderAzure:
+		if model != "" {
+			azureCfg.Deployment = model
+		}
+		routerCfg.Azure = llm.NewAzureClient(azureCfg)
+		if !routerCfg.Azure.IsConfigured() {
+			return routerCfg, fmt.Errorf("Azure OpenAI not configured: set AZURE_OPENAI_ENDPOINT, AZURE_OPENAI_API_KEY and AZURE_OPENAI_DEPLOYMENT")
+		}
+	default:
+		return routerCfg, fmt.Errorf("unknown provider %q: use ollama, claude or azure", provider)
+	}
+
+	routerCfg.EnableFallback = false
+	routerCfg.FallbackChain = []llm.Provider{llm.Provider(provider)}
+	return routerCfg, nil
+}
+
+// openEvalDB opens the database if QuantumLife is initialized, and
+// returns nil otherwise
+func openEvalDB() (*storage.DB, error) {
+	dbPath := filepath.Join(dataDir, "quantumlife.db")
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	db, err := storage.Open(storage.Config{Path: dbPath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return db, nil
+}
+
+func financeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "finance",
+		Short: "Finance operations",
+		Long: `Manage bank accounts and transactions.
+
+Examples:
+  ql finance import statement.ofx              - Import an OFX/QFX statement
+  ql finance import export.csv --account visa  - Import a CSV export into an account
+  ql finance report --year 2025                - Tax-year report as CSV
+  ql finance report --format html -o tax.html  - Printable report for last year`,
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import an OFX, QFX or CSV bank statement",
+		Long: `Import transactions from a downloaded bank statement. They are
+categorized like synced transactions; ones already imported or synced
+are skipped.
+
+CSV layouts are detected from the header. Add profiles for other banks
+under "finance.csv_profiles" in config.json.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			accountID, _ := cmd.Flags().GetString("account")
+			accountName, _ := cmd.Flags().GetString("account-name")
+
+			appCfg, err := config.Load(filepath.Join(dataDir, "config.json"))
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			stmt, err := newStatementImporter(appCfg.Finance).Parse(args[0], f, profile)
+			if err != nil {
+				return fmt.Errorf("failed to parse statement: %w", err)
+			}
+			if accountID != "" {
+				stmt.Account.AccountID = accountID
+			}
+			if accountName != "" {
+				stmt.Account.Name = accountName
+			}
+
+			space, db, err := openFinanceSpace(appCfg.Finance)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			result, err := space.Import(context.Background(), stmt)
+			if err != nil {
+				return fmt.Errorf("import failed: %w", err)
+			}
+
+			fmt.Printf("Imported %d of %d transactions into %s (%d duplicates skipped)\n",
+				result.Imported, result.Parsed, result.AccountID, result.Duplicates)
+			return nil
+		},
+	}
+	importCmd.Flags().String("profile", "", "CSV profile (detected from the header if empty)")
+	importCmd.Flags().String("account", "", "Account ID (default: from the statement, or \"imported\")")
+	importCmd.Flags().String("account-name", "", "Account display name")
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate a tax-year report",
+		Long: `Report a calendar year's business expenses, charitable giving,
+medical spending and spending in the categories listed under
+"finance.deductible_categories" in config.json. Business expenses are
+transactions, or parts of them, in the professional hat. Entries link
+to the closest email receipt.
+
+The CSV has one row per entry. The HTML report prints cleanly; print
+it from a browser to save it as a PDF.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			year, _ := cmd.Flags().GetInt("year")
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			if format != "csv" && format != "html" {
+				return fmt.Errorf("unknown format %q: use csv or html", format)
+			}
+
+			appCfg, err := config.Load(filepath.Join(dataDir, "config.json"))
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			space, db, err := openFinanceSpace(appCfg.Finance)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			if err := space.Load(); err != nil {
+				return fmt.Errorf("failed to load finance data: %w", err)
+			}
+
+			report, err := space.TaxReport(year)
+			if err != nil {
+				return err
+			}
+
+			w := os.Stdout
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if format == "html" {
+				_, err = io.WriteString(w, report.RenderHTML())
+			} else {
+				err = report.WriteCSV(w)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+
+			if output != "" {
+				fmt.Printf("Wrote the %d tax report to %s (%s in total)\n", report.Year, output, report.Total)
+				for _, rate := range report.MissingRates {
+					fmt.Printf("Warning: no exchange rate for %s; its transactions are left out\n", rate)
+				}
+			}
+			return nil
+		},
+	}
+	reportCmd.Flags().Int("year", time.Now().Year()-1, "Tax year")
+	reportCmd.Flags().String("format", "csv", "Output format: csv or html")
+	reportCmd.Flags().StringP("output", "o", "", "File to write (default: standard output)")
+
+	cmd.AddCommand(importCmd)
+	cmd.AddCommand(reportCmd)
+	return cmd
+}
+
+// newStatementImporter creates a statement importer with the configured
+// CSV profiles
+func newStatementImporter(cfg config.FinanceConfig) *finance.Importer {
+	profiles := make([]finance.CSVProfile, len(cfg.CSVProfiles))
+	for i, p := range cfg.CSVProfiles {
+		profiles[i] = finance.CSVProfile(p)
+	}
+	return finance.NewImporter(profiles...)
+}
+
+// deductibleCategories returns the categories tax-year reports list as
+// deductible
+func deductibleCategories(cfg config.FinanceConfig) []finance.Category {
+	categories := make([]finance.Category, len(cfg.DeductibleCategories))
+	for i, c := range cfg.DeductibleCategories {
+		categories[i] = finance.Category(c)
+	}
+	return categories
+}
+
+// openFinanceSpace opens the finance space backed by the database. The
+// identity stays locked, so Plaid items can be read but not synced.
+func openFinanceSpace(cfg config.FinanceConfig) (*finance.Space, *storage.DB, error) {
+	db, err := openEvalDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	if db == nil {
+		return nil, nil, fmt.Errorf("QuantumLife is not initialized. Run 'ql init' first")
+	}
+	if err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	identityStore := storage.NewIdentityStore(db)
+	you, _, err := identityStore.LoadIdentity()
+	if err != nil || you == nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("no identity found - run 'ql init' first")
+	}
+
+	space := finance.NewSpace(finance.SpaceConfig{
+		ID:                   "finance",
+		Name:                 "Finance",
+		DefaultHatID:         core.HatFinance,
+		PlaidConfig:          finance.DefaultPlaidConfig(),
+		HomeCurrency:         cfg.HomeCurrency,
+		LowBalanceThreshold:  cfg.LowBalanceThreshold,
+		DeductibleCategories: deductibleCategories(cfg),
+	})
+	space.SetStore(finance.NewStore(db, identity.NewManager(identityStore), you.ID))
+	space.SetExchange(finance.NewExchange(db, finance.NewHTTPRateProvider(cfg.FXRatesURL)))
+	space.SetItemStore(storage.NewItemStore(db))
+	return space, db, nil
+}
+
 // initComponents initializes all components needed for memory operations
-func initComponents() (*storage.DB, *vectors.Store, *embeddings.Service, error) {
+func initComponents() (*storage.DB, vectors.Index, *embeddings.Service, error) {
 	dbPath := filepath.Join(dataDir, "quantumlife.db")
 
 	// Check if initialized
@@ -671,10 +1206,10 @@ func initComponents() (*storage.DB, *vectors.Store, *embeddings.Service, error)
 		return nil, nil, nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	vectorStore, err := vectors.NewStore(vectors.DefaultConfig())
+	vectorStore, err := openVectorIndex()
 	if err != nil {
 		db.Close()
-		return nil, nil, nil, fmt.Errorf("failed to connect to Qdrant: %w\n\nMake sure Qdrant is running:\n  docker run -d -p 6333:6333 -p 6334:6334 qdrant/qdrant", err)
+		return nil, nil, nil, fmt.Errorf("failed to open vector index: %w\n\nEither run Qdrant:\n  docker run -d -p 6333:6333 -p 6334:6334 qdrant/qdrant\nor set \"vectors\": {\"backend\": \"embedded\"} in %s", err, filepath.Join(dataDir, "config.json"))
 	}
 
 	embedder := embeddings.NewService(embeddings.DefaultConfig())
@@ -686,16 +1221,39 @@ func initComponents() (*storage.DB, *vectors.Store, *embeddings.Service, error)
 		return nil, nil, nil, fmt.Errorf("Ollama not available: %w\n\nMake sure Ollama is running with the embedding model:\n  ollama pull nomic-embed-text\n  ollama serve", err)
 	}
 
-	// Ensure collections exist
-	if err := vectorStore.EnsureCollections(context.Background(), embedder.Dimension()); err != nil {
+	// Ensure collections exist and were built with the configured model
+	if _, err := embedder.DetectDimension(context.Background()); err != nil {
+		db.Close()
+		vectorStore.Close()
+		return nil, nil, nil, fmt.Errorf("embedding model %s not available: %w\n\nPull it with:\n  ollama pull %s", embedder.ModelName(), err, embedder.ModelName())
+	}
+	mismatches, err := memory.PrepareIndex(context.Background(), db, vectorStore, embedder)
+	if err != nil {
 		db.Close()
 		vectorStore.Close()
 		return nil, nil, nil, fmt.Errorf("failed to setup collections: %w", err)
 	}
+	for _, mismatch := range mismatches {
+		fmt.Fprintf(os.Stderr, "Warning: %s. Run 'ql memory reindex'.\n", mismatch)
+	}
 
 	return db, vectorStore, embedder, nil
 }
 
+// openVectorIndex opens the vector index selected in config.json
+func openVectorIndex() (vectors.Index, error) {
+	appCfg, err := config.Load(filepath.Join(dataDir, "config.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return vectors.Open(context.Background(), vectors.OpenConfig{
+		Backend: appCfg.Vectors.Backend,
+		Qdrant:  vectors.DefaultConfig(),
+		Dir:     filepath.Join(dataDir, "vectors"),
+	})
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -777,6 +1335,10 @@ func spacesCmd() *cobra.Command {
 				return addGmailSpace()
 			case "calendar":
 				return addCalendarSpace()
+			case "imap":
+				return addIMAPSpace()
+			case "markdown", "obsidian":
+				return addMarkdownSpace()
 			case "outlook", "gdrive", "dropbox":
 				fmt.Printf("Provider '%s' is coming soon!\n", provider)
 				return nil
@@ -786,6 +1348,8 @@ func spacesCmd() *cobra.Command {
 				fmt.Println("Available providers:")
 				fmt.Println("   gmail    - Google Gmail")
 				fmt.Println("   calendar - Google Calendar")
+				fmt.Println("   imap     - Any IMAP/SMTP mailbox (Fastmail, self-hosted, ...)")
+				fmt.Println("   markdown - Local Markdown/Obsidian vault")
 				fmt.Println("   outlook  - Microsoft Outlook (coming soon)")
 				fmt.Println("   gdrive   - Google Drive (coming soon)")
 				return nil
@@ -1052,6 +1616,11 @@ func syncSpace(db *storage.DB, spaceStore *storage.SpaceStore, credStore *storag
 
 	fmt.Printf("Syncing %s...\n", space.Name)
 
+	// Local spaces have no credentials
+	if space.Provider == "markdown" {
+		return syncMarkdownSpace(db, spaceStore, space)
+	}
+
 	// Load credentials
 	tokenData, err := credStore.Get(spaceID)
 	if err != nil {
@@ -1066,6 +1635,8 @@ func syncSpace(db *storage.DB, spaceStore *storage.SpaceStore, credStore *storag
 		return syncGmailSpace(db, spaceStore, space, tokenData)
 	case "google_calendar":
 		return syncCalendarSpace(db, spaceStore, space, tokenData)
+	case "imap":
+		return syncIMAPSpace(db, spaceStore, space, tokenData)
 	default:
 		return fmt.Errorf("sync not implemented for provider: %s", space.Provider)
 	}
@@ -1130,6 +1701,313 @@ func syncGmailSpace(db *storage.DB, spaceStore *storage.SpaceStore, space *stora
 	return nil
 }
 
+// addIMAPSpace connects a generic IMAP/SMTP mailbox
+func addIMAPSpace() error {
+	dbPath := filepath.Join(dataDir, "quantumlife.db")
+	db, err := storage.Open(storage.Config{Path: dbPath})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Load identity
+	identityStore := storage.NewIdentityStore(db)
+	you, encryptedKeys, err := identityStore.LoadIdentity()
+	if err != nil || you == nil {
+		return fmt.Errorf("no identity found - run 'ql init' first")
+	}
+
+	// Get passphrase
+	fmt.Print("Passphrase: ")
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	fmt.Println()
+
+	idMgr := identity.NewManager(identityStore)
+	if err := idMgr.Unlock(you, encryptedKeys, string(passphrase)); err != nil {
+		return fmt.Errorf("invalid passphrase")
+	}
+
+	// Account details
+	reader := bufio.NewReader(os.Stdin)
+	prompt := func(label, def string) string {
+		if def != "" {
+			fmt.Printf("%s [%s]: ", label, def)
+		} else {
+			fmt.Printf("%s: ", label)
+		}
+		value, _ := reader.ReadString('\n')
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return def
+		}
+		return value
+	}
+
+	creds := &imap.Credentials{UseTLS: true}
+	creds.Host = prompt("IMAP host (e.g. imap.fastmail.com)", "")
+	if creds.Host == "" {
+		return fmt.Errorf("imap host is required")
+	}
+	fmt.Sscanf(prompt("IMAP port", "993"), "%d", &creds.Port)
+	creds.UseTLS = creds.Port == 993
+	creds.StartTLS = !creds.UseTLS
+	creds.Username = prompt("Username (email address)", "")
+	fmt.Print("Password (app password recommended): ")
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	fmt.Println()
+	creds.Password = string(password)
+	creds.ArchiveMailbox = prompt("Archive folder", "Archive")
+	creds.SMTPHost = prompt("SMTP host (blank to disable sending)", strings.Replace(creds.Host, "imap.", "smtp.", 1))
+	if creds.SMTPHost != "" {
+		fmt.Sscanf(prompt("SMTP port", "587"), "%d", &creds.SMTPPort)
+	}
+
+	// Verify the account
+	spaceID := core.SpaceID(uuid.New().String())
+	imapSpace := imap.New(imap.Config{
+		ID:             spaceID,
+		Name:           "IMAP",
+		DefaultHatID:   core.HatPersonal,
+		Server:         creds.ServerConfig(),
+		ArchiveMailbox: creds.ArchiveMailbox,
+	})
+
+	ctx := context.Background()
+	fmt.Println("Connecting...")
+	if err := imapSpace.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer imapSpace.Disconnect(ctx)
+
+	// Save space to database
+	spaceStore := storage.NewSpaceStore(db)
+	spaceRecord := &storage.SpaceRecord{
+		ID:           spaceID,
+		Type:         core.SpaceTypeEmail,
+		Provider:     "imap",
+		Name:         "IMAP - " + creds.Username,
+		IsConnected:  true,
+		SyncStatus:   "idle",
+		DefaultHatID: core.HatPersonal,
+		Settings:     make(map[string]interface{}),
+	}
+
+	if err := spaceStore.Create(spaceRecord); err != nil {
+		return fmt.Errorf("failed to save space: %w", err)
+	}
+
+	// Save encrypted credentials
+	credData, err := imap.CredentialsToJSON(creds)
+	if err != nil {
+		return fmt.Errorf("failed to serialize credentials: %w", err)
+	}
+
+	credStore := storage.NewCredentialStore(db, idMgr)
+	if err := credStore.Store(spaceID, "password", credData, nil); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Mailbox connected successfully!\n")
+	fmt.Printf("   Email: %s\n", creds.Username)
+	fmt.Printf("   Space ID: %s\n", spaceID)
+	fmt.Println()
+	fmt.Println("Run 'ql spaces sync' to fetch your emails.")
+
+	return nil
+}
+
+// syncIMAPSpace syncs an IMAP space and stores new messages as items
+func syncIMAPSpace(db *storage.DB, spaceStore *storage.SpaceStore, space *storage.SpaceRecord, credData []byte) error {
+	creds, err := imap.CredentialsFromJSON(credData)
+	if err != nil {
+		return fmt.Errorf("invalid credential data: %w", err)
+	}
+
+	itemStore := storage.NewItemStore(db)
+	imapSpace := imap.New(imap.Config{
+		ID:             space.ID,
+		Name:           space.Name,
+		DefaultHatID:   space.DefaultHatID,
+		Server:         creds.ServerConfig(),
+		Mailbox:        creds.Mailbox,
+		ArchiveMailbox: creds.ArchiveMailbox,
+		Sender:         creds.Sender(),
+		OnItems: func(ctx context.Context, items []*core.Item) error {
+			for _, item := range items {
+				if _, err := itemStore.Upsert(item); err != nil {
+					return fmt.Errorf("save message %s: %w", item.ExternalID, err)
+				}
+			}
+			return nil
+		},
+	})
+
+	imapSpace.SetSyncCursor(space.SyncCursor)
+
+	// Connect
+	ctx := context.Background()
+	if err := imapSpace.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer imapSpace.Disconnect(ctx)
+
+	// Sync
+	result, err := imapSpace.Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("sync failed: %w", err)
+	}
+	for _, syncErr := range result.Errors {
+		fmt.Printf("   Warning: %v\n", syncErr)
+	}
+
+	// Update space record. The cursor only moves once every message is
+	// saved, so failed ones are fetched again on the next sync.
+	now := time.Now()
+	space.LastSyncAt = &now
+	space.SyncStatus = "idle"
+	if len(result.Errors) == 0 {
+		space.SyncCursor = result.Cursor
+	} else {
+		space.SyncStatus = "error"
+	}
+
+	if err := spaceStore.Update(space); err != nil {
+		return fmt.Errorf("failed to update space: %w", err)
+	}
+
+	fmt.Printf("   Found %d new messages (took %s)\n", result.NewItems, result.Duration.Round(time.Millisecond))
+
+	return nil
+}
+
+// addMarkdownSpace adds a local Markdown (Obsidian) vault as a space
+func addMarkdownSpace() error {
+	dbPath := filepath.Join(dataDir, "quantumlife.db")
+	db, err := storage.Open(storage.Config{Path: dbPath})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	fmt.Print("Vault directory: ")
+	reader := bufio.NewReader(os.Stdin)
+	dir, _ := reader.ReadString('\n')
+	dir = strings.TrimSpace(dir)
+	if dir == "" {
+		return fmt.Errorf("vault directory is required")
+	}
+
+	vault, err := files.NewVault(dir)
+	if err != nil {
+		return err
+	}
+
+	spaceID := core.SpaceID(uuid.New().String())
+	spaceStore := storage.NewSpaceStore(db)
+	spaceRecord := &storage.SpaceRecord{
+		ID:           spaceID,
+		Type:         core.SpaceTypeFiles,
+		Provider:     "markdown",
+		Name:         "Notes - " + filepath.Base(vault.Root()),
+		IsConnected:  true,
+		SyncStatus:   "idle",
+		DefaultHatID: core.HatPersonal,
+		Settings: map[string]interface{}{
+			"path": vault.Root(),
+		},
+	}
+
+	if err := spaceStore.Create(spaceRecord); err != nil {
+		return fmt.Errorf("failed to save space: %w", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("Vault added successfully!\n")
+	fmt.Printf("   Path: %s\n", vault.Root())
+	fmt.Printf("   Space ID: %s\n", spaceID)
+	fmt.Println()
+	fmt.Println("Run 'ql spaces sync' to import your notes.")
+
+	return nil
+}
+
+// syncMarkdownSpace imports new and modified notes from a vault as items
+func syncMarkdownSpace(db *storage.DB, spaceStore *storage.SpaceStore, space *storage.SpaceRecord) error {
+	path, _ := space.Settings["path"].(string)
+	if path == "" {
+		return fmt.Errorf("no vault path configured for space")
+	}
+
+	itemStore := storage.NewItemStore(db)
+	hatStore := storage.NewHatStore(db)
+	created := 0
+	filesSpace := files.New(files.Config{
+		ID:           space.ID,
+		Name:         space.Name,
+		DefaultHatID: space.DefaultHatID,
+		Path:         path,
+		OnItems: func(ctx context.Context, items []*core.Item) error {
+			for _, item := range items {
+				// Unknown front-matter hats fall back to the space default
+				if _, err := hatStore.GetByID(item.HatID); err != nil {
+					item.HatID = space.DefaultHatID
+				}
+				item.ID = core.ItemID(uuid.New().String())
+				isNew, err := itemStore.Upsert(item)
+				if err != nil {
+					return fmt.Errorf("save note %s: %w", item.ExternalID, err)
+				}
+				if isNew {
+					created++
+				}
+			}
+			return nil
+		},
+	})
+
+	filesSpace.SetSyncCursor(space.SyncCursor)
+
+	ctx := context.Background()
+	if err := filesSpace.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to open vault: %w", err)
+	}
+
+	result, err := filesSpace.Sync(ctx)
+	if err != nil {
+		return fmt.Errorf("sync failed: %w", err)
+	}
+	for _, syncErr := range result.Errors {
+		fmt.Printf("   Warning: %v\n", syncErr)
+	}
+
+	// Update space record. The cursor only moves once every message is
+	// saved, so failed ones are fetched again on the next sync.
+	now := time.Now()
+	space.LastSyncAt = &now
+	space.SyncStatus = "idle"
+	if len(result.Errors) == 0 {
+		space.SyncCursor = result.Cursor
+	} else {
+		space.SyncStatus = "error"
+	}
+
+	if err := spaceStore.Update(space); err != nil {
+		return fmt.Errorf("failed to update space: %w", err)
+	}
+
+	changed := result.NewItems + result.UpdatedItems
+	fmt.Printf("   Found %d new and %d updated notes (took %s)\n", created, changed-created, result.Duration.Round(time.Millisecond))
+
+	return nil
+}
+
 // openBrowser opens a URL in the default browser
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
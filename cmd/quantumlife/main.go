@@ -8,18 +8,36 @@ import (
 	"os/signal"
 	"path/filepath"
 	"syscall"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 
+	"github.com/quantumlife/quantumlife/internal/actions"
 	"github.com/quantumlife/quantumlife/internal/agent"
 	"github.com/quantumlife/quantumlife/internal/api"
+	"github.com/quantumlife/quantumlife/internal/briefing"
+	"github.com/quantumlife/quantumlife/internal/config"
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/dav"
 	"github.com/quantumlife/quantumlife/internal/embeddings"
+	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/identity"
+	"github.com/quantumlife/quantumlife/internal/intelligence"
 	"github.com/quantumlife/quantumlife/internal/learning"
+	"github.com/quantumlife/quantumlife/internal/ledger"
 	"github.com/quantumlife/quantumlife/internal/llm"
+	"github.com/quantumlife/quantumlife/internal/llm/llmconfig"
+	mcpserver "github.com/quantumlife/quantumlife/internal/mcp/server"
+	"github.com/quantumlife/quantumlife/internal/memory"
 	"github.com/quantumlife/quantumlife/internal/mesh"
 	"github.com/quantumlife/quantumlife/internal/proactive"
+	"github.com/quantumlife/quantumlife/internal/scheduler"
+	"github.com/quantumlife/quantumlife/internal/spaces/calendar"
+	"github.com/quantumlife/quantumlife/internal/spaces/files"
+	"github.com/quantumlife/quantumlife/internal/spaces/imap"
 	"github.com/quantumlife/quantumlife/internal/storage"
+	"github.com/quantumlife/quantumlife/internal/triage"
 	"github.com/quantumlife/quantumlife/internal/vectors"
 )
 
@@ -68,7 +86,7 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 	// Load identity (if exists)
 	identityStore := storage.NewIdentityStore(db)
 	identityMgr := identity.NewManager(identityStore)
-	you, _, err := identityStore.LoadIdentity()
+	you, encryptedKeys, err := identityStore.LoadIdentity()
 	if err != nil {
 		// Identity load error but not "not found" - continue without identity for setup
 		fmt.Printf("⚠️  Identity load issue: %v\n", err)
@@ -77,19 +95,39 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 
 	if you != nil {
 		fmt.Printf("👤 Identity: %s\n", you.Name)
+		// Stored mailbox and calendar credentials can only be read once unlocked
+		if passphrase := os.Getenv("QL_PASSPHRASE"); passphrase != "" {
+			if err := identityMgr.Unlock(you, encryptedKeys, passphrase); err != nil {
+				fmt.Printf("⚠️  Failed to unlock identity: %v\n", err)
+			}
+		}
 	} else {
 		fmt.Println("📝 No identity yet - setup will be available via web UI")
 	}
 
-	// Connect to Qdrant
-	vectorStore, err := vectors.NewStore(vectors.DefaultConfig())
+	appCfg, err := config.Load(filepath.Join(dataDir, "config.json"))
+	if err != nil {
+		fmt.Printf("⚠️  Failed to load config: %v\n", err)
+		appCfg = config.Default()
+	}
+
+	// Open the vector index (Qdrant or the embedded index)
+	vectorStore, err := vectors.Open(context.Background(), vectors.OpenConfig{
+		Backend: appCfg.Vectors.Backend,
+		Qdrant:  vectors.DefaultConfig(),
+		Dir:     filepath.Join(dataDir, "vectors"),
+	})
 	if err != nil {
-		fmt.Printf("⚠️  Qdrant not available: %v\n", err)
+		fmt.Printf("⚠️  Vector index not available: %v\n", err)
 		fmt.Println("   Some features will be limited")
 		vectorStore = nil
 	} else {
 		defer vectorStore.Close()
-		fmt.Println("✅ Qdrant connected")
+		if _, ok := vectorStore.(*vectors.EmbeddedStore); ok {
+			fmt.Println("✅ Embedded vector index opened")
+		} else {
+			fmt.Println("✅ Qdrant connected")
+		}
 	}
 
 	// Initialize embeddings
@@ -100,9 +138,21 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Println("✅ Ollama connected")
 
-		// Ensure vector collections
+		// Ensure vector collections and check they match the embedding model
 		if vectorStore != nil {
-			vectorStore.EnsureCollections(context.Background(), embedder.Dimension())
+			if _, err := embedder.DetectDimension(context.Background()); err != nil {
+				fmt.Printf("⚠️  Embedding model %s not available: %v\n", embedder.ModelName(), err)
+			}
+			mismatches, err := memory.PrepareIndex(context.Background(), db, vectorStore, embedder)
+			if err != nil {
+				fmt.Printf("⚠️  Failed to set up vector collections: %v\n", err)
+			}
+			for _, mismatch := range mismatches {
+				fmt.Printf("⚠️  %s\n", mismatch)
+			}
+			if len(mismatches) > 0 {
+				fmt.Println("   Semantic search is unreliable until you run: ql memory reindex")
+			}
 		}
 	}
 
@@ -114,6 +164,23 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		fmt.Println("✅ Claude API configured")
 	}
 
+	// Hybrid LLM router for chat and background jobs (local Ollama first, Claude for harder tasks)
+	router := llm.NewRouter(buildRouterConfig(appCfg.LLM, llmClient))
+	if err := router.SetUsageStore(storage.NewLLMUsageStore(db)); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	// Every cloud call is audited with its privacy decision; the API shares
+	// the ledger so entries stay on one hash chain
+	ledgerStore := ledger.NewStore(db.Conn())
+	router.SetAuditLogger(ledger.NewRecorder(ledgerStore))
+	if !appCfg.LLM.ConfidentialLocalOnly {
+		router.SetScrubber(llm.NewScrubber(personNames(db, you)))
+	}
+	if appCfg.LLM.MonthlyBudgetUSD > 0 || appCfg.LLM.DailyBudgetUSD > 0 {
+		fmt.Printf("💰 LLM budget: $%.2f/day, $%.2f/month (0 = unlimited)\n",
+			appCfg.LLM.DailyBudgetUSD, appCfg.LLM.MonthlyBudgetUSD)
+	}
+
 	// Create agent (may have nil identity)
 	ag := agent.New(agent.Config{
 		Identity:  you,
@@ -121,6 +188,7 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		Vectors:   vectorStore,
 		Embedder:  embedder,
 		LLMClient: llmClient,
+		Router:    router,
 	})
 
 	// Start agent loop
@@ -176,24 +244,84 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		fmt.Println("🧠 Learning service started")
 	}
 
+	// Finance data from imported statements and stored Plaid syncs
+	financeSpace := openFinanceSpace(db, identityMgr, you, appCfg.Finance)
+
 	// Initialize proactive service (depends on learning)
 	proactiveService := proactive.NewService(db, learningService, proactive.DefaultServiceConfig())
+	if financeSpace != nil {
+		proactiveService.TriggerDetector().SetFinanceSource(financeTriggerSource{financeSpace})
+	}
 	if err := proactiveService.Start(ctx); err != nil {
 		fmt.Printf("⚠️  Failed to start proactive service: %v\n", err)
 	} else {
 		fmt.Println("💡 Proactive service started")
 	}
 
+	// Google Calendar feeds meeting prep once stored credentials are unlocked
+	var calSpace *calendar.Space
+	if identityMgr.IsUnlocked() {
+		calSpace = openCalendarSpace(ctx, db, identityMgr)
+	}
+
+	// Schedule background maintenance jobs, briefings and meeting prep
+	sched := startScheduler(db, vectorStore, embedder, router, calSpace)
+
+	// Build MCP tool policy from config (open when no clients are configured)
+	mcpPolicy, err := buildMCPPolicy(appCfg.MCP)
+	if err != nil {
+		return fmt.Errorf("invalid MCP policy: %w", err)
+	} else if mcpPolicy == nil {
+		fmt.Println("⚠️  MCP endpoints open without authentication (mcp.allow_unauthenticated)")
+	} else if len(appCfg.MCP.Clients) == 0 {
+		fmt.Println("⚠️  MCP tool calls disabled - add clients under mcp.clients to enable them")
+	} else {
+		fmt.Printf("🔐 MCP policy enforced for %d client(s)\n", len(appCfg.MCP.Clients))
+	}
+
+	// Connect CalDAV/CardDAV account if configured
+	calDAV, cardDAV := buildDAVClients(appCfg.DAV)
+	if calDAV != nil {
+		fmt.Printf("📇 CalDAV/CardDAV account configured (%s)\n", appCfg.DAV.URL)
+	}
+
+	// Open and watch the Markdown vault if configured
+	notesVault := startNotesVault(ctx, db, appCfg.Notes)
+	if notesVault != nil {
+		fmt.Printf("📝 Notes vault watched (%s)\n", notesVault.Root())
+	}
+
+	// Sync and watch IMAP mailboxes; new mail is triaged and the suggested
+	// actions run against the mailbox it came from
+	triager := triage.NewEngine(router, memory.NewManager(db, vectorStore, embedder), triage.DefaultEngineConfig())
+	triager.SetHatStore(storage.NewHatStore(db))
+	triager.SetEntityStore(storage.NewEntityStore(db))
+	if identityMgr.IsUnlocked() {
+		if n := startIMAPSpaces(ctx, db, identityMgr, triager, ledger.NewRecorder(ledgerStore)); n > 0 {
+			fmt.Printf("📬 Watching %d IMAP mailbox(es)\n", n)
+		}
+	} else if you != nil {
+		fmt.Println("⚠️  IMAP mailboxes not watched - set QL_PASSPHRASE to unlock stored credentials")
+	}
+
 	// Create and start API server
 	server := api.New(api.Config{
-		Port:             port,
-		Agent:            ag,
-		DB:               db,
-		Identity:         you,
-		IdentityManager:  identityMgr,
-		MeshHub:          meshHub,
-		LearningService:  learningService,
-		ProactiveService: proactiveService,
+		Port:              port,
+		Agent:             ag,
+		DB:                db,
+		Identity:          you,
+		IdentityManager:   identityMgr,
+		MeshHub:           meshHub,
+		LearningService:   learningService,
+		ProactiveService:  proactiveService,
+		MCPPolicy:         mcpPolicy,
+		CalDAVClient:      calDAV,
+		CardDAVClient:     cardDAV,
+		NotesVault:        notesVault,
+		FinanceSpace:      financeSpace,
+		StatementImporter: newStatementImporter(appCfg.Finance),
+		LLMRouter:         router,
+		LedgerStore:       ledgerStore,
 	})
 
 	// Handle shutdown
@@ -203,6 +331,7 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		<-sigCh
 
 		fmt.Println("\n🛑 Shutting down...")
+		sched.Stop()
 		proactiveService.Stop()
 		learningService.Stop()
 		if meshHub != nil {
@@ -217,3 +346,466 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 	fmt.Printf("🌐 Open http://localhost:%d in your browser\n", port)
 	return server.Start()
 }
+
+// startScheduler registers and starts the daemon's scheduled jobs
+func startScheduler(db *storage.DB, vectorStore vectors.Index, embedder *embeddings.Service, router *llm.Router, calSpace *calendar.Space) *scheduler.Scheduler {
+	sched, _ := scheduler.NewScheduler(scheduler.DefaultConfig())
+
+	// Nightly memory consolidation: decay, summarize episodes, forget faded memories
+	consolidator := memory.NewConsolidator(
+		memory.NewManager(db, vectorStore, embedder),
+		router,
+		memory.DefaultConsolidationConfig(),
+	)
+	sched.Register(scheduler.NewTask("memory-consolidation").
+		Name("Memory consolidation").
+		Description("Decay memory importance, consolidate episodic memories and archive faded ones").
+		Daily("03:30").
+		Timeout(30 * time.Minute).
+		Handler(func(ctx context.Context) error {
+			report, err := consolidator.Run(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("🧹 Memory consolidation: %d decayed, %d consolidated, %d archived, %d deleted\n",
+				report.Decayed, report.Consolidated, report.Archived, report.Deleted)
+			return nil
+		}).
+		Build())
+
+	// Both look senders and attendees up in the entity graph, so one person
+	// writing from several addresses counts once
+	itemStore := storage.NewItemStore(db)
+	entities := storage.NewEntityStore(db)
+
+	// Morning briefing of the last day's items by hat
+	briefer := briefing.NewGenerator(router, itemStore, storage.NewHatStore(db), briefing.DefaultConfig())
+	briefer.SetEntityStore(entities)
+	sched.Register(scheduler.NewTask("morning-briefing").
+		Name("Morning briefing").
+		Description("Summarize the last day's items by hat").
+		Daily("07:00").
+		Timeout(10 * time.Minute).
+		Handler(func(ctx context.Context) error {
+			b, err := briefer.Generate(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("📰 Morning briefing: %s\n", b.Summary)
+			return nil
+		}).
+		Build())
+
+	// Meeting prep for the day's events, recording attendees in the entity graph
+	if calSpace != nil {
+		insights := intelligence.NewCrossDomainEngine(router, itemStore, calSpace, intelligence.DefaultConfig())
+		insights.SetEntityStore(entities)
+		sched.Register(scheduler.NewTask("meeting-prep").
+			Name("Meeting prep").
+			Description("Correlate upcoming events with related email and flag conflicts and follow-ups").
+			Daily("06:45").
+			Timeout(10 * time.Minute).
+			Handler(func(ctx context.Context) error {
+				found, err := insights.AnalyzeUpcoming(ctx, 1)
+				if err != nil {
+					return err
+				}
+				for _, insight := range found {
+					fmt.Printf("📅 %s\n", insight.Title)
+				}
+				return nil
+			}).
+			Build())
+	}
+
+	if err := sched.Start(); err != nil {
+		fmt.Printf("⚠️  Failed to start scheduler: %v\n", err)
+	} else {
+		fmt.Println("⏰ Scheduler started")
+	}
+	return sched
+}
+
+// buildRouterConfig sets up the hybrid LLM router: local Ollama first,
+// Claude for harder tasks, with the configured budgets and fallback chain
+func buildRouterConfig(cfg config.LLMConfig, claude *llm.Client) llm.RouterConfig {
+	routerCfg := llmconfig.RouterConfig(cfg)
+	routerCfg.Claude = claude
+	routerCfg.Ollama = llm.NewOllamaClient(llm.DefaultOllamaConfig())
+	return routerCfg
+}
+
+// personNames returns a function listing the names the PII scrubber
+// redacts: your own and those of people in the entity graph
+func personNames(db *storage.DB, you *core.You) func() []string {
+	entities := storage.NewEntityStore(db)
+	return func() []string {
+		names, err := entities.Names(core.EntityKindPerson)
+		if err != nil {
+			fmt.Printf("Warning: failed to load names for redaction: %v\n", err)
+		}
+		if you != nil && you.Name != "" {
+			names = append(names, you.Name)
+		}
+		return names
+	}
+}
+
+// buildDAVClients creates CalDAV and CardDAV clients for the configured account
+func buildDAVClients(cfg config.DAVConfig) (*dav.CalDAVClient, *dav.CardDAVClient) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+
+	davCfg := dav.Config{
+		URL:                cfg.URL,
+		Username:           cfg.Username,
+		Password:           cfg.Password,
+		DefaultCalendar:    cfg.DefaultCalendar,
+		DefaultAddressBook: cfg.DefaultAddressBook,
+	}
+	calDAV, err := dav.NewCalDAVClient(davCfg)
+	if err != nil {
+		fmt.Printf("⚠️  Invalid CalDAV config: %v\n", err)
+		return nil, nil
+	}
+	cardDAV, err := dav.NewCardDAVClient(davCfg)
+	if err != nil {
+		fmt.Printf("⚠️  Invalid CardDAV config: %v\n", err)
+		return nil, nil
+	}
+	return calDAV, cardDAV
+}
+
+// startNotesVault syncs the configured Markdown vault into the item store and
+// keeps it up to date in the background
+func startNotesVault(ctx context.Context, db *storage.DB, cfg config.NotesConfig) *files.Vault {
+	if cfg.VaultPath == "" {
+		return nil
+	}
+
+	defaultHat := core.HatPersonal
+	if cfg.HatID != "" {
+		defaultHat = core.HatID(cfg.HatID)
+	}
+
+	itemStore := storage.NewItemStore(db)
+	hatStore := storage.NewHatStore(db)
+	space := files.New(files.Config{
+		ID:           "notes",
+		Name:         "Notes",
+		DefaultHatID: defaultHat,
+		Path:         cfg.VaultPath,
+		OnItems: func(ctx context.Context, items []*core.Item) error {
+			for _, item := range items {
+				if _, err := hatStore.GetByID(item.HatID); err != nil {
+					item.HatID = defaultHat
+				}
+				item.ID = core.ItemID(uuid.New().String())
+				if _, err := itemStore.Upsert(item); err != nil {
+					return fmt.Errorf("save note %s: %w", item.ExternalID, err)
+				}
+			}
+			return nil
+		},
+		OnRemoved: func(ctx context.Context, paths []string) error {
+			for _, p := range paths {
+				if err := itemStore.DeleteByExternalID("notes", p); err != nil {
+					return fmt.Errorf("remove note %s: %w", p, err)
+				}
+			}
+			return nil
+		},
+	})
+
+	if err := space.Connect(ctx); err != nil {
+		fmt.Printf("⚠️  Notes vault unavailable: %v\n", err)
+		return nil
+	}
+	if _, err := space.Sync(ctx); err != nil {
+		fmt.Printf("⚠️  Notes vault sync failed: %v\n", err)
+	}
+
+	go func() {
+		if err := space.Watch(ctx); err != nil && ctx.Err() == nil {
+			fmt.Printf("⚠️  Notes vault watch stopped: %v\n", err)
+		}
+	}()
+
+	return space.GetVault()
+}
+
+// openCalendarSpace connects the first Google Calendar space. Reading its
+// stored token needs the identity unlocked.
+func openCalendarSpace(ctx context.Context, db *storage.DB, identityMgr *identity.Manager) *calendar.Space {
+	records, err := storage.NewSpaceStore(db).GetAll()
+	if err != nil {
+		fmt.Printf("⚠️  Failed to load spaces: %v\n", err)
+		return nil
+	}
+
+	credStore := storage.NewCredentialStore(db, identityMgr)
+	for _, record := range records {
+		if record.Provider != "google_calendar" || !record.IsConnected {
+			continue
+		}
+		tokenData, err := credStore.Get(record.ID)
+		if err != nil || tokenData == nil {
+			fmt.Printf("⚠️  No credentials for %s: %v\n", record.Name, err)
+			continue
+		}
+		token, err := calendar.TokenFromJSON(tokenData)
+		if err != nil {
+			fmt.Printf("⚠️  Invalid token for %s: %v\n", record.Name, err)
+			continue
+		}
+
+		space := calendar.New(calendar.Config{
+			ID:           record.ID,
+			Name:         record.Name,
+			DefaultHatID: record.DefaultHatID,
+			OAuthConfig:  calendar.DefaultOAuthConfig(),
+		})
+		space.SetToken(token)
+		if err := space.Connect(ctx); err != nil {
+			fmt.Printf("⚠️  %s unavailable: %v\n", record.Name, err)
+			continue
+		}
+		return space
+	}
+	return nil
+}
+
+// startIMAPSpaces syncs each connected IMAP space and keeps it up to date
+// over IDLE in the background. New messages are triaged, and the suggested
+// actions go to an action framework with that mailbox's handlers. It
+// returns the number of mailboxes being watched.
+func startIMAPSpaces(ctx context.Context, db *storage.DB, identityMgr *identity.Manager, triager *triage.Engine, recorder *ledger.Recorder) int {
+	spaceStore := storage.NewSpaceStore(db)
+	records, err := spaceStore.GetAll()
+	if err != nil {
+		fmt.Printf("⚠️  Failed to load spaces: %v\n", err)
+		return 0
+	}
+
+	credStore := storage.NewCredentialStore(db, identityMgr)
+	itemStore := storage.NewItemStore(db)
+	watched := 0
+	for _, record := range records {
+		if record.Provider != "imap" || !record.IsConnected {
+			continue
+		}
+		credData, err := credStore.Get(record.ID)
+		if err != nil || credData == nil {
+			fmt.Printf("⚠️  No credentials for %s: %v\n", record.Name, err)
+			continue
+		}
+		creds, err := imap.CredentialsFromJSON(credData)
+		if err != nil {
+			fmt.Printf("⚠️  Invalid credentials for %s: %v\n", record.Name, err)
+			continue
+		}
+
+		fw := actions.NewFramework(actions.DefaultConfig())
+		fw.SetLedgerRecorder(recorder)
+		space := imap.New(imap.Config{
+			ID:             record.ID,
+			Name:           record.Name,
+			DefaultHatID:   record.DefaultHatID,
+			Server:         creds.ServerConfig(),
+			Mailbox:        creds.Mailbox,
+			ArchiveMailbox: creds.ArchiveMailbox,
+			Sender:         creds.Sender(),
+			OnItems: func(ctx context.Context, items []*core.Item) error {
+				for _, item := range items {
+					created, err := itemStore.Upsert(item)
+					if err != nil {
+						return fmt.Errorf("save message %s: %w", item.ExternalID, err)
+					}
+					if created {
+						triageMessage(ctx, triager, fw, item)
+					}
+				}
+				return nil
+			},
+			// The cursor only moves once every message is saved
+			OnCursor: func(ctx context.Context, cursor string) error {
+				now := time.Now()
+				record.LastSyncAt = &now
+				record.SyncCursor = cursor
+				record.SyncStatus = "idle"
+				return spaceStore.Update(record)
+			},
+		})
+		space.SetSyncCursor(record.SyncCursor)
+
+		if err := space.Connect(ctx); err != nil {
+			fmt.Printf("⚠️  %s unavailable: %v\n", record.Name, err)
+			continue
+		}
+
+		// Handlers go through the space so they follow its reconnects
+		var sender actions.MailSender
+		if creds.SMTPHost != "" {
+			sender = space
+		}
+		actions.RegisterIMAPHandlers(fw, space, creds.ArchiveMailbox, sender)
+
+		go func(name string) {
+			if err := space.Watch(ctx); err != nil && ctx.Err() == nil {
+				fmt.Printf("⚠️  %s watch stopped: %v\n", name, err)
+			}
+		}(record.Name)
+		watched++
+	}
+	return watched
+}
+
+// triageMessage triages a newly synced message and submits the suggested
+// actions. Handlers find the message by its IMAP reference, so it is passed
+// along with each action. Failures are logged; the message stays synced.
+func triageMessage(ctx context.Context, triager *triage.Engine, fw *actions.Framework, item *core.Item) {
+	result, err := triager.Triage(ctx, item)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to triage %s: %v\n", item.ExternalID, err)
+		return
+	}
+	for i := range result.Actions {
+		params := make(map[string]interface{}, len(result.Actions[i].Parameters)+1)
+		for k, v := range result.Actions[i].Parameters {
+			params[k] = v
+		}
+		params["external_id"] = item.ExternalID
+		result.Actions[i].Parameters = params
+	}
+	if err := fw.ProcessSuggestedActions(ctx, item.ID, result.HatID, result.Actions); err != nil {
+		fmt.Printf("⚠️  Failed to act on %s: %v\n", item.ExternalID, err)
+	}
+}
+
+// deductibleCategories returns the categories tax-year reports list as
+// deductible
+func deductibleCategories(cfg config.FinanceConfig) []finance.Category {
+	categories := make([]finance.Category, len(cfg.DeductibleCategories))
+	for i, c := range cfg.DeductibleCategories {
+		categories[i] = finance.Category(c)
+	}
+	return categories
+}
+
+// openFinanceSpace loads the finance space from the database. The identity
+// is not unlocked here, so Plaid items are readable but not synced.
+func openFinanceSpace(db *storage.DB, identityMgr *identity.Manager, you *core.You, cfg config.FinanceConfig) *finance.Space {
+	if you == nil {
+		return nil
+	}
+
+	space := finance.NewSpace(finance.SpaceConfig{
+		ID:                   "finance",
+		Name:                 "Finance",
+		DefaultHatID:         core.HatFinance,
+		PlaidConfig:          finance.DefaultPlaidConfig(),
+		HomeCurrency:         cfg.HomeCurrency,
+		LowBalanceThreshold:  cfg.LowBalanceThreshold,
+		DeductibleCategories: deductibleCategories(cfg),
+	})
+	space.SetStore(finance.NewStore(db, identityMgr, you.ID))
+	space.SetExchange(finance.NewExchange(db, finance.NewHTTPRateProvider(cfg.FXRatesURL)))
+	space.SetItemStore(storage.NewItemStore(db))
+	if err := space.Load(); err != nil {
+		fmt.Printf("⚠️  Failed to load finance data: %v\n", err)
+	}
+	return space
+}
+
+// financeTriggerSource feeds the finance space's budgets and cash-flow
+// forecast to proactive triggers
+type financeTriggerSource struct {
+	space *finance.Space
+}
+
+func (f financeTriggerSource) BudgetStatuses(ctx context.Context) ([]proactive.BudgetStatus, error) {
+	report, err := f.space.GetBudgets()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]proactive.BudgetStatus, len(report.Envelopes))
+	for i, env := range report.Envelopes {
+		statuses[i] = proactive.BudgetStatus{
+			Category:    string(env.Category),
+			PeriodStart: env.PeriodStart,
+			PeriodEnd:   env.PeriodEnd,
+			Allowance:   env.Allowance(),
+			Spent:       env.Spent,
+		}
+	}
+	return statuses, nil
+}
+
+func (f financeTriggerSource) CashFlowWarnings(ctx context.Context) ([]proactive.CashFlowWarning, error) {
+	forecast, err := f.space.Forecast(finance.DefaultForecastDays)
+	if err != nil {
+		return nil, err
+	}
+	warnings := make([]proactive.CashFlowWarning, len(forecast.Warnings))
+	for i, w := range forecast.Warnings {
+		warnings[i] = proactive.CashFlowWarning{
+			AccountID:   w.AccountID,
+			AccountName: w.AccountName,
+			Date:        w.Date,
+			Balance:     w.Balance,
+			Threshold:   w.Threshold,
+			Payday:      w.Payday,
+			Bills:       w.Bills,
+		}
+	}
+	return warnings, nil
+}
+
+// newStatementImporter creates a statement importer with the configured
+// CSV profiles
+func newStatementImporter(cfg config.FinanceConfig) *finance.Importer {
+	profiles := make([]finance.CSVProfile, len(cfg.CSVProfiles))
+	for i, p := range cfg.CSVProfiles {
+		profiles[i] = finance.CSVProfile(p)
+	}
+	return finance.NewImporter(profiles...)
+}
+
+// buildMCPPolicy converts MCP config into a policy for tool calls and
+// resource reads. Without clients the policy rejects every request; a nil
+// policy (no enforcement) is only returned when unauthenticated access was
+// explicitly allowed.
+func buildMCPPolicy(cfg config.MCPConfig) (*mcpserver.Policy, error) {
+	if len(cfg.Clients) == 0 && cfg.AllowUnauthenticated {
+		return nil, nil
+	}
+
+	policy := mcpserver.NewPolicy()
+	for _, c := range cfg.Clients {
+		if c.Token == "" {
+			return nil, fmt.Errorf("client %q has no token", c.ID)
+		}
+		err := policy.AddClient(mcpserver.ClientPolicy{
+			ID:           c.ID,
+			Token:        c.Token,
+			AllowedTools: c.AllowedTools,
+			MaxAccess:    mcpserver.ToolAccess(c.MaxAccess),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for name, l := range cfg.RateLimits {
+		limit := mcpserver.RateLimit{Requests: l.Requests, Window: time.Duration(l.WindowSeconds) * time.Second}
+		switch access := mcpserver.ToolAccess(name); access {
+		case mcpserver.AccessRead, mcpserver.AccessWrite, mcpserver.AccessSensitive:
+			policy.SetDefaultRateLimit(access, limit)
+		default:
+			policy.SetRateLimit(name, limit)
+		}
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,406 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/quantumlife/quantumlife/internal/email"
+	"github.com/quantumlife/quantumlife/internal/spaces/imap"
+	"github.com/quantumlife/quantumlife/internal/triage"
+)
+
+// IMAPMailbox is the subset of the IMAP client used by the IMAP handlers.
+// This interface allows for mocking in unit tests.
+type IMAPMailbox interface {
+	GetMessage(ctx context.Context, ref imap.MessageRef) (*imap.Message, error)
+	Move(ctx context.Context, ref imap.MessageRef, dest string) (imap.MessageRef, error)
+	Copy(ctx context.Context, ref imap.MessageRef, dest string) (imap.MessageRef, error)
+	Delete(ctx context.Context, ref imap.MessageRef) error
+	AddFlags(ctx context.Context, ref imap.MessageRef, flags ...string) error
+	RemoveFlags(ctx context.Context, ref imap.MessageRef, flags ...string) error
+	SupportsKeywords(ctx context.Context, mailbox string) (bool, error)
+}
+
+// MailSender delivers outbound mail. imap.Space implements this interface.
+type MailSender interface {
+	Send(ctx context.Context, msg *email.Message) error
+}
+
+// ==================== IMAP Archive Handler ====================
+
+// IMAPArchiveHandler archives messages by moving them to the archive mailbox
+type IMAPArchiveHandler struct {
+	client         IMAPMailbox
+	archiveMailbox string
+}
+
+// NewIMAPArchiveHandler creates an IMAP archive handler
+func NewIMAPArchiveHandler(client IMAPMailbox, archiveMailbox string) *IMAPArchiveHandler {
+	if archiveMailbox == "" {
+		archiveMailbox = "Archive"
+	}
+	return &IMAPArchiveHandler{client: client, archiveMailbox: archiveMailbox}
+}
+
+// Type returns the action type
+func (h *IMAPArchiveHandler) Type() triage.ActionType {
+	return triage.ActionArchive
+}
+
+// Validate checks if the action can be executed
+func (h *IMAPArchiveHandler) Validate(ctx context.Context, action Action) error {
+	if h.client == nil {
+		return fmt.Errorf("imap client not configured")
+	}
+	_, err := getMessageRef(action)
+	return err
+}
+
+// Execute moves the message to the archive mailbox
+func (h *IMAPArchiveHandler) Execute(ctx context.Context, action Action) (*Result, error) {
+	ref, err := getMessageRef(action)
+	if err != nil {
+		return nil, err
+	}
+
+	archived, err := h.client.Move(ctx, ref, h.archiveMailbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive message: %w", err)
+	}
+
+	return &Result{
+		Success:  true,
+		Message:  "Message archived",
+		Undoable: archived.UID != 0,
+		Data: map[string]interface{}{
+			"message_id":   ref.String(),
+			"archived_ref": archived.String(),
+			"action":       "archive",
+		},
+	}, nil
+}
+
+// Undo moves the message back to its original mailbox
+func (h *IMAPArchiveHandler) Undo(ctx context.Context, action Action, result *Result) error {
+	ref, err := getMessageRef(action)
+	if err != nil {
+		return err
+	}
+	archived, err := resultRef(result, "archived_ref")
+	if err != nil {
+		return err
+	}
+
+	_, err = h.client.Move(ctx, archived, ref.Mailbox)
+	return err
+}
+
+// ==================== IMAP Label Handler ====================
+
+// IMAPLabelHandler labels messages with an IMAP keyword, or by copying them
+// into a folder when the server does not accept keywords
+type IMAPLabelHandler struct {
+	client IMAPMailbox
+}
+
+// NewIMAPLabelHandler creates an IMAP label handler
+func NewIMAPLabelHandler(client IMAPMailbox) *IMAPLabelHandler {
+	return &IMAPLabelHandler{client: client}
+}
+
+// Type returns the action type
+func (h *IMAPLabelHandler) Type() triage.ActionType {
+	return triage.ActionLabel
+}
+
+// Validate checks if the action can be executed
+func (h *IMAPLabelHandler) Validate(ctx context.Context, action Action) error {
+	if h.client == nil {
+		return fmt.Errorf("imap client not configured")
+	}
+	if action.Parameters["label"] == nil {
+		return fmt.Errorf("label name required")
+	}
+	_, err := getMessageRef(action)
+	return err
+}
+
+// Execute applies the label. Set the "folder" parameter to force a folder copy.
+func (h *IMAPLabelHandler) Execute(ctx context.Context, action Action) (*Result, error) {
+	ref, err := getMessageRef(action)
+	if err != nil {
+		return nil, err
+	}
+
+	labelName, ok := action.Parameters["label"].(string)
+	if !ok || labelName == "" {
+		return nil, fmt.Errorf("invalid label name")
+	}
+
+	asFolder, _ := action.Parameters["folder"].(bool)
+	if !asFolder {
+		supported, err := h.client.SupportsKeywords(ctx, ref.Mailbox)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check keyword support: %w", err)
+		}
+		asFolder = !supported
+	}
+
+	if asFolder {
+		copied, err := h.client.Copy(ctx, ref, labelName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy to folder: %w", err)
+		}
+		return &Result{
+			Success:  true,
+			Message:  fmt.Sprintf("Message filed in '%s'", labelName),
+			Undoable: copied.UID != 0,
+			Data: map[string]interface{}{
+				"message_id": ref.String(),
+				"label_name": labelName,
+				"mode":       "folder",
+				"copy_ref":   copied.String(),
+			},
+		}, nil
+	}
+
+	keyword := keywordFor(labelName)
+	if err := h.client.AddFlags(ctx, ref, keyword); err != nil {
+		return nil, fmt.Errorf("failed to apply label: %w", err)
+	}
+
+	return &Result{
+		Success:  true,
+		Message:  fmt.Sprintf("Label '%s' applied", labelName),
+		Undoable: true,
+		Data: map[string]interface{}{
+			"message_id": ref.String(),
+			"label_name": labelName,
+			"mode":       "keyword",
+			"keyword":    keyword,
+		},
+	}, nil
+}
+
+// Undo removes the keyword or deletes the folder copy
+func (h *IMAPLabelHandler) Undo(ctx context.Context, action Action, result *Result) error {
+	if result == nil {
+		return fmt.Errorf("no result to undo")
+	}
+
+	if result.Data["mode"] == "folder" {
+		copied, err := resultRef(result, "copy_ref")
+		if err != nil {
+			return err
+		}
+		return h.client.Delete(ctx, copied)
+	}
+
+	ref, err := getMessageRef(action)
+	if err != nil {
+		return err
+	}
+	keyword, ok := result.Data["keyword"].(string)
+	if !ok {
+		return fmt.Errorf("keyword not found in result")
+	}
+	return h.client.RemoveFlags(ctx, ref, keyword)
+}
+
+// ==================== IMAP Flag Handler ====================
+
+// IMAPFlagHandler sets the \Flagged flag on messages
+type IMAPFlagHandler struct {
+	client IMAPMailbox
+}
+
+// NewIMAPFlagHandler creates an IMAP flag handler
+func NewIMAPFlagHandler(client IMAPMailbox) *IMAPFlagHandler {
+	return &IMAPFlagHandler{client: client}
+}
+
+// Type returns the action type
+func (h *IMAPFlagHandler) Type() triage.ActionType {
+	return triage.ActionFlag
+}
+
+// Validate checks if the action can be executed
+func (h *IMAPFlagHandler) Validate(ctx context.Context, action Action) error {
+	if h.client == nil {
+		return fmt.Errorf("imap client not configured")
+	}
+	_, err := getMessageRef(action)
+	return err
+}
+
+// Execute performs the flag action
+func (h *IMAPFlagHandler) Execute(ctx context.Context, action Action) (*Result, error) {
+	ref, err := getMessageRef(action)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.client.AddFlags(ctx, ref, imap.FlagFlagged); err != nil {
+		return nil, fmt.Errorf("failed to flag message: %w", err)
+	}
+
+	return &Result{
+		Success:  true,
+		Message:  "Message flagged",
+		Undoable: true,
+		Data: map[string]interface{}{
+			"message_id": ref.String(),
+		},
+	}, nil
+}
+
+// Undo removes the flag
+func (h *IMAPFlagHandler) Undo(ctx context.Context, action Action, result *Result) error {
+	ref, err := getMessageRef(action)
+	if err != nil {
+		return err
+	}
+	return h.client.RemoveFlags(ctx, ref, imap.FlagFlagged)
+}
+
+// ==================== IMAP Reply Handler ====================
+
+// IMAPReplyHandler replies to IMAP messages over SMTP
+type IMAPReplyHandler struct {
+	client IMAPMailbox
+	sender MailSender
+}
+
+// NewIMAPReplyHandler creates an IMAP reply handler
+func NewIMAPReplyHandler(client IMAPMailbox, sender MailSender) *IMAPReplyHandler {
+	return &IMAPReplyHandler{client: client, sender: sender}
+}
+
+// Type returns the action type
+func (h *IMAPReplyHandler) Type() triage.ActionType {
+	return triage.ActionReply
+}
+
+// Validate checks if the action can be executed
+func (h *IMAPReplyHandler) Validate(ctx context.Context, action Action) error {
+	if h.client == nil {
+		return fmt.Errorf("imap client not configured")
+	}
+	if h.sender == nil {
+		return fmt.Errorf("smtp sender not configured")
+	}
+	if action.Parameters["body"] == nil {
+		return fmt.Errorf("reply body required")
+	}
+	_, err := getMessageRef(action)
+	return err
+}
+
+// Execute sends the reply to the original sender
+func (h *IMAPReplyHandler) Execute(ctx context.Context, action Action) (*Result, error) {
+	ref, err := getMessageRef(action)
+	if err != nil {
+		return nil, err
+	}
+
+	body, ok := action.Parameters["body"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid reply body")
+	}
+
+	original, err := h.client.GetMessage(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get original message: %w", err)
+	}
+
+	subject := original.Subject
+	if !strings.HasPrefix(strings.ToLower(subject), "re:") {
+		subject = "Re: " + subject
+	}
+
+	reply := &email.Message{
+		To:       []string{original.From},
+		Subject:  subject,
+		TextBody: body,
+	}
+	if original.MessageID != "" {
+		reply.Headers = map[string]string{
+			"In-Reply-To": original.MessageID,
+			"References":  original.MessageID,
+		}
+	}
+
+	if err := h.sender.Send(ctx, reply); err != nil {
+		return nil, fmt.Errorf("failed to send reply: %w", err)
+	}
+
+	// Mark the original answered; the reply is already sent if this fails
+	h.client.AddFlags(ctx, ref, imap.FlagAnswered)
+
+	return &Result{
+		Success:  true,
+		Message:  "Reply sent",
+		Undoable: false, // Can't unsend
+		Data: map[string]interface{}{
+			"message_id": ref.String(),
+			"to":         original.From,
+		},
+	}, nil
+}
+
+// Undo cannot undo sent emails
+func (h *IMAPReplyHandler) Undo(ctx context.Context, action Action, result *Result) error {
+	return fmt.Errorf("cannot undo sent emails")
+}
+
+// ==================== IMAP Helper Functions ====================
+
+// getMessageRef parses the IMAP message reference ("mailbox:uidvalidity:uid")
+func getMessageRef(action Action) (imap.MessageRef, error) {
+	messageID := getMessageID(action)
+	if messageID == "" {
+		return imap.MessageRef{}, fmt.Errorf("no message ID found")
+	}
+	return imap.ParseMessageRef(messageID)
+}
+
+func resultRef(result *Result, key string) (imap.MessageRef, error) {
+	if result == nil {
+		return imap.MessageRef{}, fmt.Errorf("no result to undo")
+	}
+	s, ok := result.Data[key].(string)
+	if !ok {
+		return imap.MessageRef{}, fmt.Errorf("%s not found in result", key)
+	}
+	return imap.ParseMessageRef(s)
+}
+
+// keywordFor turns a label name into a valid IMAP keyword atom
+func keywordFor(label string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r <= ' ' || r > '~':
+			return '_'
+		case strings.ContainsRune(`(){%*"\]`, r):
+			return '_'
+		}
+		return r
+	}, label)
+}
+
+// RegisterIMAPHandlers registers the IMAP archive, label and flag handlers,
+// and the reply handler when a sender is given. They take the place of the
+// Gmail handlers for the same action types, so use this instead of
+// RegisterAllHandlers when the mailbox is IMAP.
+func RegisterIMAPHandlers(fw *Framework, client IMAPMailbox, archiveMailbox string, sender MailSender) {
+	if client == nil {
+		return
+	}
+	fw.RegisterHandler(NewIMAPArchiveHandler(client, archiveMailbox))
+	fw.RegisterHandler(NewIMAPLabelHandler(client))
+	fw.RegisterHandler(NewIMAPFlagHandler(client))
+	if sender != nil {
+		fw.RegisterHandler(NewIMAPReplyHandler(client, sender))
+	}
+}
@@ -0,0 +1,343 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quantumlife/quantumlife/internal/email"
+	"github.com/quantumlife/quantumlife/internal/spaces/imap"
+	"github.com/quantumlife/quantumlife/internal/testutil/mockservers"
+	"github.com/quantumlife/quantumlife/internal/triage"
+)
+
+func newIMAPTestClient(t *testing.T) (*imap.Client, *mockservers.IMAPMockServer) {
+	t.Helper()
+
+	mock := mockservers.NewIMAPMockServer(t)
+	client, err := imap.Dial(context.Background(), imap.ServerConfig{
+		Host:     mock.Host,
+		Port:     mock.Port,
+		Username: mock.Username,
+		Password: mock.Password,
+	})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, mock
+}
+
+func imapAction(actionType triage.ActionType, params map[string]interface{}) Action {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	params["external_id"] = "INBOX:1:1"
+	return Action{Type: actionType, Parameters: params}
+}
+
+func hasIMAPFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestIMAPArchiveHandler(t *testing.T) {
+	client, mock := newIMAPTestClient(t)
+	h := NewIMAPArchiveHandler(client, "")
+	ctx := context.Background()
+	action := imapAction(triage.ActionArchive, nil)
+
+	if h.Type() != triage.ActionArchive {
+		t.Errorf("Type() = %s", h.Type())
+	}
+	if err := h.Validate(ctx, action); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	result, err := h.Execute(ctx, action)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.Success || !result.Undoable {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if mock.Message("INBOX", 1) != nil || len(mock.Mailbox("Archive").Messages) != 1 {
+		t.Fatal("message was not moved to Archive")
+	}
+
+	if err := h.Undo(ctx, action, result); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(mock.Mailbox("Archive").Messages) != 0 || len(mock.Mailbox("INBOX").Messages) != 2 {
+		t.Error("message was not moved back to INBOX")
+	}
+}
+
+func TestIMAPLabelHandler_Keyword(t *testing.T) {
+	client, mock := newIMAPTestClient(t)
+	h := NewIMAPLabelHandler(client)
+	ctx := context.Background()
+	action := imapAction(triage.ActionLabel, map[string]interface{}{"label": "Needs Reply"})
+
+	if err := h.Validate(ctx, action); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	result, err := h.Execute(ctx, action)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Data["mode"] != "keyword" || result.Data["keyword"] != "Needs_Reply" {
+		t.Errorf("unexpected result data: %+v", result.Data)
+	}
+	if !hasIMAPFlag(mock.Message("INBOX", 1).Flags, "Needs_Reply") {
+		t.Errorf("keyword not stored: %v", mock.Message("INBOX", 1).Flags)
+	}
+
+	if err := h.Undo(ctx, action, result); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if hasIMAPFlag(mock.Message("INBOX", 1).Flags, "Needs_Reply") {
+		t.Error("keyword not removed on undo")
+	}
+}
+
+func TestIMAPLabelHandler_Folder(t *testing.T) {
+	client, mock := newIMAPTestClient(t)
+	h := NewIMAPLabelHandler(client)
+	ctx := context.Background()
+	action := imapAction(triage.ActionLabel, map[string]interface{}{"label": "Receipts", "folder": true})
+
+	result, err := h.Execute(ctx, action)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Data["mode"] != "folder" || !result.Undoable {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(mock.Mailbox("Receipts").Messages) != 1 || mock.Message("INBOX", 1) == nil {
+		t.Fatal("expected a copy in Receipts with the original left in INBOX")
+	}
+
+	if err := h.Undo(ctx, action, result); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if len(mock.Mailbox("Receipts").Messages) != 0 {
+		t.Error("folder copy not removed on undo")
+	}
+}
+
+func TestIMAPFlagHandler(t *testing.T) {
+	client, mock := newIMAPTestClient(t)
+	h := NewIMAPFlagHandler(client)
+	ctx := context.Background()
+	action := imapAction(triage.ActionFlag, nil)
+
+	result, err := h.Execute(ctx, action)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.Success || !hasIMAPFlag(mock.Message("INBOX", 1).Flags, imap.FlagFlagged) {
+		t.Errorf("message not flagged: %+v", mock.Message("INBOX", 1).Flags)
+	}
+
+	if err := h.Undo(ctx, action, result); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if hasIMAPFlag(mock.Message("INBOX", 1).Flags, imap.FlagFlagged) {
+		t.Error("flag not removed on undo")
+	}
+}
+
+// recordingSender keeps sent messages instead of delivering them
+type recordingSender struct {
+	sent []*email.Message
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg *email.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestIMAPReplyHandler(t *testing.T) {
+	client, mock := newIMAPTestClient(t)
+	sender := &recordingSender{}
+	h := NewIMAPReplyHandler(client, sender)
+	ctx := context.Background()
+	action := imapAction(triage.ActionReply, map[string]interface{}{"body": "Will do."})
+
+	if err := h.Validate(ctx, action); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	result, err := h.Execute(ctx, action)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !result.Success || result.Undoable {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if len(sender.sent) != 1 {
+		t.Fatalf("expected 1 message sent, got %d", len(sender.sent))
+	}
+	reply := sender.sent[0]
+	if len(reply.To) != 1 || !strings.Contains(reply.To[0], "alice@example.com") {
+		t.Errorf("To = %v", reply.To)
+	}
+	if reply.Subject != "Re: Quarterly report" {
+		t.Errorf("Subject = %q", reply.Subject)
+	}
+	if reply.Headers["In-Reply-To"] != "<report-001@example.com>" {
+		t.Errorf("In-Reply-To = %q", reply.Headers["In-Reply-To"])
+	}
+	if reply.TextBody != "Will do." {
+		t.Errorf("TextBody = %q", reply.TextBody)
+	}
+	if !hasIMAPFlag(mock.Message("INBOX", 1).Flags, imap.FlagAnswered) {
+		t.Error("original message not marked answered")
+	}
+}
+
+// stubIMAPMailbox fails every call, for validation and error paths
+type stubIMAPMailbox struct{}
+
+var errIMAPStub = errors.New("connection reset")
+
+func (stubIMAPMailbox) GetMessage(ctx context.Context, ref imap.MessageRef) (*imap.Message, error) {
+	return nil, errIMAPStub
+}
+func (stubIMAPMailbox) Move(ctx context.Context, ref imap.MessageRef, dest string) (imap.MessageRef, error) {
+	return imap.MessageRef{}, errIMAPStub
+}
+func (stubIMAPMailbox) Copy(ctx context.Context, ref imap.MessageRef, dest string) (imap.MessageRef, error) {
+	return imap.MessageRef{}, errIMAPStub
+}
+func (stubIMAPMailbox) Delete(ctx context.Context, ref imap.MessageRef) error { return errIMAPStub }
+func (stubIMAPMailbox) AddFlags(ctx context.Context, ref imap.MessageRef, flags ...string) error {
+	return errIMAPStub
+}
+func (stubIMAPMailbox) RemoveFlags(ctx context.Context, ref imap.MessageRef, flags ...string) error {
+	return errIMAPStub
+}
+func (stubIMAPMailbox) SupportsKeywords(ctx context.Context, mailbox string) (bool, error) {
+	return false, errIMAPStub
+}
+
+func TestIMAPHandlers_Validate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		handler Handler
+		action  Action
+		wantErr string
+	}{
+		{
+			name:    "archive without client",
+			handler: NewIMAPArchiveHandler(nil, "Archive"),
+			action:  imapAction(triage.ActionArchive, nil),
+			wantErr: "imap client not configured",
+		},
+		{
+			name:    "gmail message id is rejected",
+			handler: NewIMAPFlagHandler(stubIMAPMailbox{}),
+			action:  Action{Parameters: map[string]interface{}{"message_id": "18c2f1a9"}},
+			wantErr: "invalid message reference",
+		},
+		{
+			name:    "label without name",
+			handler: NewIMAPLabelHandler(stubIMAPMailbox{}),
+			action:  imapAction(triage.ActionLabel, nil),
+			wantErr: "label name required",
+		},
+		{
+			name:    "reply without sender",
+			handler: NewIMAPReplyHandler(stubIMAPMailbox{}, nil),
+			action:  imapAction(triage.ActionReply, map[string]interface{}{"body": "ok"}),
+			wantErr: "smtp sender not configured",
+		},
+		{
+			name:    "reply without body",
+			handler: NewIMAPReplyHandler(stubIMAPMailbox{}, &recordingSender{}),
+			action:  imapAction(triage.ActionReply, nil),
+			wantErr: "reply body required",
+		},
+		{
+			name:    "valid flag",
+			handler: NewIMAPFlagHandler(stubIMAPMailbox{}),
+			action:  imapAction(triage.ActionFlag, nil),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.handler.Validate(ctx, tt.action)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIMAPHandlers_ExecuteError(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := NewIMAPArchiveHandler(stubIMAPMailbox{}, "").Execute(ctx, imapAction(triage.ActionArchive, nil)); !errors.Is(err, errIMAPStub) {
+		t.Errorf("archive error = %v", err)
+	}
+	if _, err := NewIMAPLabelHandler(stubIMAPMailbox{}).Execute(ctx, imapAction(triage.ActionLabel, map[string]interface{}{"label": "x"})); !errors.Is(err, errIMAPStub) {
+		t.Errorf("label error = %v", err)
+	}
+	if _, err := NewIMAPFlagHandler(stubIMAPMailbox{}).Execute(ctx, imapAction(triage.ActionFlag, nil)); !errors.Is(err, errIMAPStub) {
+		t.Errorf("flag error = %v", err)
+	}
+}
+
+func TestRegisterIMAPHandlers(t *testing.T) {
+	fw := NewFramework(DefaultConfig())
+	RegisterIMAPHandlers(fw, stubIMAPMailbox{}, "Archive", &recordingSender{})
+
+	for _, actionType := range []triage.ActionType{triage.ActionArchive, triage.ActionLabel, triage.ActionFlag, triage.ActionReply} {
+		if _, ok := fw.handlers[actionType]; !ok {
+			t.Errorf("handler for %s not registered", actionType)
+		}
+	}
+
+	noSMTP := NewFramework(DefaultConfig())
+	RegisterIMAPHandlers(noSMTP, stubIMAPMailbox{}, "Archive", nil)
+	if _, ok := noSMTP.handlers[triage.ActionReply]; ok {
+		t.Error("reply handler registered without a sender")
+	}
+
+	empty := NewFramework(DefaultConfig())
+	RegisterIMAPHandlers(empty, nil, "", nil)
+	if len(empty.handlers) != 0 {
+		t.Errorf("expected no handlers for nil client, got %d", len(empty.handlers))
+	}
+}
+
+func TestKeywordFor(t *testing.T) {
+	tests := map[string]string{
+		"Important":   "Important",
+		"Needs Reply": "Needs_Reply",
+		"a(b)*c":      "a_b__c",
+		"Café":        "Caf_",
+	}
+	for in, want := range tests {
+		if got := keywordFor(in); got != want {
+			t.Errorf("keywordFor(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -23,14 +23,16 @@ type Agent struct {
 
 	// Components
 	llm        *llm.Client
+	router     *llm.Router
 	memory     *memory.Manager
 	classifier *Classifier
 
 	// Stores
 	db        *storage.DB
-	vectors   *vectors.Store
+	vectors   vectors.Index
 	itemStore *storage.ItemStore
 	hatStore  *storage.HatStore
+	entities  *storage.EntityStore
 
 	// State
 	running bool
@@ -45,9 +47,10 @@ type Agent struct {
 type Config struct {
 	Identity  *core.You
 	DB        *storage.DB
-	Vectors   *vectors.Store
+	Vectors   vectors.Index
 	Embedder  *embeddings.Service
 	LLMClient *llm.Client
+	Router    *llm.Router // Chat provider routing; defaults to LLMClient alone
 }
 
 // New creates a new agent
@@ -59,15 +62,29 @@ func New(cfg Config) *Agent {
 
 	systemPrompt := buildSystemPrompt(cfg.Identity)
 
+	entities := storage.NewEntityStore(cfg.DB)
+	router := cfg.Router
+	if router == nil {
+		// Confidential and unclassified content goes to Claude redacted
+		router = llm.NewRouter(llm.RouterConfig{Claude: cfg.LLMClient})
+		router.SetScrubber(llm.NewScrubber(func() []string {
+			names, _ := entities.Names(core.EntityKindPerson)
+			return names
+		}))
+	}
+	classifier.SetRouter(router)
+
 	return &Agent{
 		identity:     cfg.Identity,
 		llm:          cfg.LLMClient,
+		router:       router,
 		memory:       memoryMgr,
 		classifier:   classifier,
 		db:           cfg.DB,
 		vectors:      cfg.Vectors,
 		itemStore:    itemStore,
 		hatStore:     hatStore,
+		entities:     entities,
 		systemPrompt: systemPrompt,
 		stopCh:       make(chan struct{}),
 	}
@@ -99,6 +116,12 @@ Current time: %s`, name, name, time.Now().Format(time.RFC1123))
 
 // Chat handles a conversation with the agent
 func (a *Agent) Chat(ctx context.Context, userMessage string, history []llm.Message) (string, error) {
+	return a.ChatStream(ctx, userMessage, history, nil)
+}
+
+// ChatStream is Chat with the response passed to onDelta as it is generated.
+// Cancelling ctx stops generation at the provider.
+func (a *Agent) ChatStream(ctx context.Context, userMessage string, history []llm.Message, onDelta llm.StreamFunc) (string, error) {
 	// Retrieve relevant memories
 	memories, err := a.memory.Retrieve(ctx, userMessage, memory.RetrieveOptions{Limit: 5})
 	if err != nil {
@@ -106,12 +129,14 @@ func (a *Agent) Chat(ctx context.Context, userMessage string, history []llm.Mess
 		fmt.Printf("Warning: failed to retrieve memories: %v\n", err)
 	}
 
-	// Build context with memories
+	// Build context with memories, noting the hats the context draws on
 	var contextParts []string
+	var hatIDs []core.HatID
 	if len(memories) > 0 {
 		contextParts = append(contextParts, "Relevant memories:")
 		for _, m := range memories {
 			contextParts = append(contextParts, fmt.Sprintf("- [%s] %s", m.Type, truncateContent(m.Content, 200)))
+			hatIDs = append(hatIDs, m.HatID)
 		}
 	}
 
@@ -121,25 +146,40 @@ func (a *Agent) Chat(ctx context.Context, userMessage string, history []llm.Mess
 		contextParts = append(contextParts, "\nRecent items:")
 		for _, item := range recentItems {
 			contextParts = append(contextParts, fmt.Sprintf("- [%s] %s (%s)", item.HatID, item.Subject, item.Type))
+			hatIDs = append(hatIDs, item.HatID)
 		}
 	}
 
+	// The strictest hat in the context decides where the chat may be sent
+	sensitivity, err := a.hatStore.Sensitivity(hatIDs...)
+	if err != nil {
+		return "", fmt.Errorf("chat failed: %w", err)
+	}
+
 	// Build enhanced system prompt
 	enhancedSystem := a.systemPrompt
 	if len(contextParts) > 0 {
 		enhancedSystem += "\n\n" + strings.Join(contextParts, "\n")
 	}
 
-	// Build messages
-	messages := make([]llm.Message, 0, len(history)+1)
-	messages = append(messages, history...)
-	messages = append(messages, llm.Message{Role: "user", Content: userMessage})
-
 	// Get response
-	response, err := a.llm.ChatWithHistory(ctx, enhancedSystem, messages)
+	req := llm.RouteRequest{
+		System:        enhancedSystem,
+		Prompt:        userMessage,
+		History:       history,
+		MinComplexity: llm.ComplexityHigh,
+		Sensitivity:   sensitivity,
+	}
+	var resp *llm.RouteResponse
+	if onDelta != nil {
+		resp, err = a.router.RouteStream(ctx, req, onDelta)
+	} else {
+		resp, err = a.router.Route(ctx, req)
+	}
 	if err != nil {
 		return "", fmt.Errorf("chat failed: %w", err)
 	}
+	response := resp.Content
 
 	// Store this interaction as episodic memory (async)
 	go func() {
@@ -175,6 +215,11 @@ func (a *Agent) ProcessItem(ctx context.Context, item *core.Item) error {
 		return fmt.Errorf("failed to update item: %w", err)
 	}
 
+	// Link the people, organizations and places involved
+	if err := a.entities.LinkItem(item, classification.EntityKinds); err != nil {
+		fmt.Printf("Warning: failed to link entities: %v\n", err)
+	}
+
 	// Store memory about this item
 	memoryContent := fmt.Sprintf("Received %s from %s: %s. Classified to %s hat with priority %d.",
 		item.Type, item.From, item.Summary, classification.HatID, classification.Priority)
@@ -295,3 +340,8 @@ func (a *Agent) Remember(ctx context.Context, query string, hatID core.HatID) ([
 	}
 	return a.memory.Retrieve(ctx, query, opts)
 }
+
+// SearchMemories retrieves memories with explicit retrieval options
+func (a *Agent) SearchMemories(ctx context.Context, query string, opts memory.RetrieveOptions) ([]*core.Memory, error) {
+	return a.memory.Retrieve(ctx, query, opts)
+}
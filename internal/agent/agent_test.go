@@ -3,9 +3,12 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,6 +37,23 @@ func testDB(t *testing.T) *storage.DB {
 func mockLLMServer(t *testing.T, responseContent string) *httptest.Server {
 	t.Helper()
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llm.Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Stream {
+			// Stream the response a word at a time
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i, word := range strings.SplitAfter(responseContent, " ") {
+				data, _ := json.Marshal(map[string]interface{}{
+					"type":  "content_block_delta",
+					"index": i,
+					"delta": map[string]string{"type": "text_delta", "text": word},
+				})
+				fmt.Fprintf(w, "event: content_block_delta\ndata: %s\n\n", data)
+			}
+			fmt.Fprint(w, "event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		response := map[string]interface{}{
 			"content": []map[string]interface{}{
@@ -428,6 +448,68 @@ func TestClassifier_ClassifyItem_WithMockLLM(t *testing.T) {
 	}
 }
 
+func TestClassifier_ClassifyItem_RejectsUnknownHat(t *testing.T) {
+	db := testDB(t)
+	hatStore := storage.NewHatStore(db)
+
+	// Not one of the hats, however often the model is asked to repair it
+	server := mockLLMServer(t, `{"hat_id": "travel", "confidence": 0.9, "priority": 2, "summary": "Trip"}`)
+	llmClient := llm.NewClient(llm.Config{APIKey: "test-key", BaseURL: server.URL})
+	classifier := NewClassifier(llmClient, hatStore)
+
+	_, err := classifier.ClassifyItem(context.Background(), &core.Item{Type: core.ItemTypeEmail, Subject: "Flight"})
+	if !errors.Is(err, llm.ErrInvalidOutput) {
+		t.Errorf("ClassifyItem() error = %v, want ErrInvalidOutput", err)
+	}
+	stats := classifier.router.GetStats().Providers[llm.ProviderClaude]
+	if stats.StructuredResponses != 3 || stats.ParseFailureRate != 1 {
+		t.Errorf("Claude stats = %+v", stats)
+	}
+}
+
+func TestClassifier_RedactsUnclassifiedItems(t *testing.T) {
+	db := testDB(t)
+	hatStore := storage.NewHatStore(db)
+
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": `{"hat_id": "health", "confidence": 0.9, "priority": 2, "summary": "Lab results"}`}},
+		})
+	}))
+	defer server.Close()
+
+	classifier := NewClassifier(llm.NewClient(llm.Config{APIKey: "test-key", BaseURL: server.URL}), hatStore)
+
+	// A new email still on the space's default hat
+	item := &core.Item{
+		Type:    core.ItemTypeEmail,
+		HatID:   core.HatPersonal,
+		From:    "results@clinic.example.com",
+		Subject: "Your lab results",
+		Body:    "Patient account 12345678: your results are ready.",
+	}
+	if _, err := classifier.ClassifyItem(context.Background(), item); err != nil {
+		t.Fatalf("ClassifyItem() error = %v", err)
+	}
+	if _, _, err := classifier.QuickClassify(context.Background(), item.Body); err != nil {
+		t.Fatalf("QuickClassify() error = %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("requests = %d, want 2", len(received))
+	}
+	for _, body := range received {
+		for _, secret := range []string{"results@clinic.example.com", "12345678"} {
+			if strings.Contains(body, secret) {
+				t.Errorf("model received %q", secret)
+			}
+		}
+	}
+}
+
 func TestClassifier_QuickClassify_WithMockLLM(t *testing.T) {
 	db := testDB(t)
 	hatStore := storage.NewHatStore(db)
@@ -725,6 +807,37 @@ func TestAgent_Chat_WithHistory(t *testing.T) {
 	}
 }
 
+func TestAgent_ChatStream(t *testing.T) {
+	db := testDB(t)
+
+	mockResponse := "Here is your day at a glance"
+	server := mockLLMServer(t, mockResponse)
+
+	agent := New(Config{
+		Identity:  &core.You{ID: "test", Name: "Test"},
+		DB:        db,
+		LLMClient: llm.NewClient(llm.Config{APIKey: "test-key", BaseURL: server.URL}),
+	})
+	session := NewChatSession(agent)
+
+	var deltas []string
+	response, err := session.StreamMessage(context.Background(), "What's on today?", func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("StreamMessage() error = %v", err)
+	}
+	if response != mockResponse || strings.Join(deltas, "") != mockResponse {
+		t.Errorf("StreamMessage() = %q, deltas %q", response, deltas)
+	}
+	if len(deltas) < 2 {
+		t.Errorf("response not streamed incrementally: %q", deltas)
+	}
+	if len(session.history) != 2 || session.history[1].Content != mockResponse {
+		t.Errorf("history = %+v", session.history)
+	}
+}
+
 // =============================================================================
 // Agent ProcessItem Tests
 // =============================================================================
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
 
 	"github.com/quantumlife/quantumlife/internal/llm"
@@ -27,7 +28,13 @@ func NewChatSession(agent *Agent) *ChatSession {
 
 // SendMessage sends a message and gets a response
 func (s *ChatSession) SendMessage(ctx context.Context, message string) (string, error) {
-	response, err := s.agent.Chat(ctx, message, s.history)
+	return s.StreamMessage(ctx, message, nil)
+}
+
+// StreamMessage sends a message and passes the response to onDelta as it is
+// generated. A cancelled response is left out of the history.
+func (s *ChatSession) StreamMessage(ctx context.Context, message string, onDelta llm.StreamFunc) (string, error) {
+	response, err := s.agent.ChatStream(ctx, message, s.history, onDelta)
 	if err != nil {
 		return "", err
 	}
@@ -49,13 +56,16 @@ func (s *ChatSession) Clear() {
 	s.history = make([]llm.Message, 0)
 }
 
-// RunInteractive runs an interactive chat in the terminal
+// RunInteractive runs an interactive chat in the terminal. Responses are
+// printed as they are generated; an interrupt stops the current response
+// and returns to the prompt.
 func (s *ChatSession) RunInteractive(ctx context.Context) error {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println()
 	fmt.Println("QuantumLife Agent")
 	fmt.Println("   Type 'exit' to quit, 'clear' to reset conversation")
+	fmt.Println("   Ctrl-C stops a response")
 	fmt.Println()
 
 	for {
@@ -86,12 +96,33 @@ func (s *ChatSession) RunInteractive(ctx context.Context) error {
 			continue
 		}
 
-		response, err := s.SendMessage(ctx, input)
-		if err != nil {
+		// Ctrl-C only stops the response while one is being generated
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		replyCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			select {
+			case <-interrupts:
+				cancel()
+			case <-replyCtx.Done():
+			}
+		}()
+
+		fmt.Print("\nAgent: ")
+		_, err = s.StreamMessage(replyCtx, input, func(delta string) {
+			fmt.Print(delta)
+		})
+		interrupted := replyCtx.Err() != nil && ctx.Err() == nil
+		signal.Stop(interrupts)
+		cancel()
+
+		switch {
+		case interrupted:
+			fmt.Print("\n[stopped]\n\n")
+		case err != nil:
 			fmt.Printf("\nError: %v\n\n", err)
-			continue
+		default:
+			fmt.Print("\n\n")
 		}
-
-		fmt.Printf("\nAgent: %s\n\n", response)
 	}
 }
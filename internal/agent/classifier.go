@@ -4,6 +4,7 @@ package agent
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -15,27 +16,38 @@ import (
 // Classifier routes items to the correct hat
 type Classifier struct {
 	llm      *llm.Client
+	router   *llm.Router
 	hatStore *storage.HatStore
 }
 
-// NewClassifier creates a classifier
+// NewClassifier creates a classifier. Requests go to llmClient, with
+// personal data redacted, until a router is set.
 func NewClassifier(llmClient *llm.Client, hatStore *storage.HatStore) *Classifier {
+	router := llm.NewRouter(llm.RouterConfig{Claude: llmClient})
+	router.SetScrubber(llm.NewScrubber(nil))
 	return &Classifier{
 		llm:      llmClient,
+		router:   router,
 		hatStore: hatStore,
 	}
 }
 
+// SetRouter sends classification requests through router
+func (c *Classifier) SetRouter(router *llm.Router) {
+	c.router = router
+}
+
 // ClassificationResult is the output of classification
 type ClassificationResult struct {
-	HatID       core.HatID `json:"hat_id"`
-	Confidence  float64    `json:"confidence"`
-	Priority    int        `json:"priority"`
-	Sentiment   string     `json:"sentiment"`
-	Summary     string     `json:"summary"`
-	Entities    []string   `json:"entities"`
-	ActionItems []string   `json:"action_items"`
-	Reasoning   string     `json:"reasoning"`
+	HatID       core.HatID                 `json:"hat_id"`
+	Confidence  float64                    `json:"confidence"`
+	Priority    int                        `json:"priority"`
+	Sentiment   string                     `json:"sentiment"`
+	Summary     string                     `json:"summary"`
+	Entities    []string                   `json:"entities"`
+	EntityKinds map[string]core.EntityKind `json:"entity_kinds"` // person, organization or place per entity
+	ActionItems []string                   `json:"action_items"`
+	Reasoning   string                     `json:"reasoning"`
 }
 
 // ClassifyItem determines which hat an item belongs to
@@ -48,10 +60,12 @@ func (c *Classifier) ClassifyItem(ctx context.Context, item *core.Item) (*Classi
 
 	// Build hat descriptions
 	var hatDescriptions []string
+	hatIDs := make([]string, 0, len(hats))
 	for _, h := range hats {
 		hatDescriptions = append(hatDescriptions, fmt.Sprintf(
 			"- %s (%s): %s", h.ID, h.Name, h.Description,
 		))
+		hatIDs = append(hatIDs, string(h.ID))
 	}
 
 	systemPrompt := fmt.Sprintf(`You are the QuantumLife classification agent. Your job is to analyze incoming items and route them to the correct life domain (hat).
@@ -67,6 +81,7 @@ Analyze the item and respond with ONLY a JSON object (no markdown, no explanatio
     "sentiment": "positive", "negative", or "neutral",
     "summary": "one sentence summary",
     "entities": ["list", "of", "people", "places", "orgs"],
+    "entity_kinds": {"each entity": "person", "organization" or "place"},
     "action_items": ["any", "actions", "required"],
     "reasoning": "brief explanation of why this hat"
 }`, strings.Join(hatDescriptions, "\n"))
@@ -79,28 +94,56 @@ Subject: %s
 Content:
 %s`, item.Type, item.From, item.Subject, truncateContent(item.Body, 2000))
 
-	response, err := c.llm.Chat(ctx, systemPrompt, userPrompt)
+	// The item's hat is only a default until it is classified
+	sensitivity, err := c.hatStore.Sensitivity(item.HatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hat sensitivity: %w", err)
+	}
+	sensitivity = sensitivity.Max(core.UnclassifiedSensitivity)
+
+	response, err := c.router.RouteJSON(ctx, llm.RouteRequest{
+		System:        systemPrompt,
+		Prompt:        userPrompt,
+		MinComplexity: llm.ComplexityHigh,
+		Sensitivity:   sensitivity,
+	}, classificationSchema(hatIDs))
+	if errors.Is(err, llm.ErrInvalidOutput) {
+		return nil, fmt.Errorf("failed to parse classification: %w (response: %s)", err, response.Content)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("LLM classification failed: %w", err)
 	}
 
 	// Parse response
 	var result ClassificationResult
-
-	// Clean response (remove markdown if present)
-	response = strings.TrimSpace(response)
-	response = strings.TrimPrefix(response, "```json")
-	response = strings.TrimPrefix(response, "```")
-	response = strings.TrimSuffix(response, "```")
-	response = strings.TrimSpace(response)
-
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse classification: %w (response: %s)", err, response)
+	if err := json.Unmarshal([]byte(response.Content), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse classification: %w (response: %s)", err, response.Content)
 	}
 
 	return &result, nil
 }
 
+// classificationSchema is the structure of a classification, with hat_id
+// limited to hatIDs
+func classificationSchema(hatIDs []string) *llm.Schema {
+	entityKind := llm.StringSchema(
+		string(core.EntityKindPerson), string(core.EntityKindOrganization), string(core.EntityKindPlace),
+	)
+	schema := llm.ObjectSchema(map[string]*llm.Schema{
+		"hat_id":       llm.StringSchema(hatIDs...),
+		"confidence":   llm.NumberSchema(0, 1),
+		"priority":     llm.IntegerSchema(1, 5),
+		"sentiment":    llm.StringSchema("positive", "negative", "neutral"),
+		"summary":      llm.StringSchema(),
+		"entities":     llm.ArraySchema(llm.StringSchema()),
+		"entity_kinds": {Type: "object", AdditionalProperties: entityKind},
+		"action_items": llm.ArraySchema(llm.StringSchema()),
+		"reasoning":    llm.StringSchema(),
+	}, "hat_id", "confidence", "priority", "summary")
+	schema.Title = "classification"
+	return schema
+}
+
 // QuickClassify does a fast classification without full analysis
 func (c *Classifier) QuickClassify(ctx context.Context, content string) (core.HatID, float64, error) {
 	hats, err := c.hatStore.GetActive()
@@ -108,15 +151,30 @@ func (c *Classifier) QuickClassify(ctx context.Context, content string) (core.Ha
 		return "", 0, err
 	}
 
-	var hatList []string
+	var hatList, hatIDs []string
 	for _, h := range hats {
 		hatList = append(hatList, fmt.Sprintf("%s:%s", h.ID, h.Name))
+		hatIDs = append(hatIDs, string(h.ID))
 	}
 
 	systemPrompt := `You are a quick classifier. Given content, respond with ONLY the hat_id and confidence as JSON: {"hat_id": "xxx", "confidence": 0.X}`
 	userPrompt := fmt.Sprintf("Hats: %s\n\nContent: %s", strings.Join(hatList, ", "), truncateContent(content, 500))
 
-	response, err := c.llm.Chat(ctx, systemPrompt, userPrompt)
+	schema := llm.ObjectSchema(map[string]*llm.Schema{
+		"hat_id":     llm.StringSchema(hatIDs...),
+		"confidence": llm.NumberSchema(0, 1),
+	}, "hat_id", "confidence")
+	schema.Title = "quick_classification"
+
+	response, err := c.router.RouteJSON(ctx, llm.RouteRequest{
+		System:        systemPrompt,
+		Prompt:        userPrompt,
+		MinComplexity: llm.ComplexityHigh,
+		Sensitivity:   core.UnclassifiedSensitivity,
+	}, schema)
+	if errors.Is(err, llm.ErrInvalidOutput) {
+		return core.HatPersonal, 0.5, nil // Default to personal
+	}
 	if err != nil {
 		return "", 0, err
 	}
@@ -125,13 +183,8 @@ func (c *Classifier) QuickClassify(ctx context.Context, content string) (core.Ha
 		HatID      string  `json:"hat_id"`
 		Confidence float64 `json:"confidence"`
 	}
-
-	response = strings.TrimSpace(response)
-	response = strings.TrimPrefix(response, "```json")
-	response = strings.TrimSuffix(response, "```")
-
-	if err := json.Unmarshal([]byte(response), &result); err != nil {
-		return core.HatPersonal, 0.5, nil // Default to personal
+	if err := json.Unmarshal([]byte(response.Content), &result); err != nil {
+		return core.HatPersonal, 0.5, nil
 	}
 
 	return core.HatID(result.HatID), result.Confidence, nil
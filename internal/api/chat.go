@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ChatDelta is broadcast over the WebSocket as an agent response is generated
+type ChatDelta struct {
+	ChatID string `json:"chat_id"`
+	Delta  string `json:"delta"`
+}
+
+// ChatResult is broadcast when an agent response finishes, and returned from
+// the chat endpoint
+type ChatResult struct {
+	ChatID    string `json:"chat_id"`
+	Response  string `json:"response"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// handleAgentChat runs a chat turn. The response is streamed over the
+// WebSocket as chat.delta messages tagged with chat_id, followed by
+// chat.done; the full response is also returned. Closing the request or
+// sending chat.cancel over the WebSocket stops generation.
+func (s *Server) handleAgentChat(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Message string `json:"message"`
+		ChatID  string `json:"chat_id"` // Optional, lets the client match deltas before the response arrives
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if input.Message == "" {
+		s.respondError(w, http.StatusBadRequest, "Message required")
+		return
+	}
+
+	chatID := input.ChatID
+	if chatID == "" {
+		chatID = uuid.New().String()
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	if !s.trackChat(chatID, cancel) {
+		s.respondError(w, http.StatusConflict, "chat_id already in use")
+		return
+	}
+	defer s.untrackChat(chatID)
+
+	var streamed strings.Builder
+	response, err := s.agent.ChatStream(ctx, input.Message, nil, func(delta string) {
+		streamed.WriteString(delta)
+		s.Broadcast("chat.delta", ChatDelta{ChatID: chatID, Delta: delta})
+	})
+
+	result := ChatResult{ChatID: chatID, Response: response}
+	switch {
+	case err != nil && ctx.Err() != nil:
+		result.Response = streamed.String()
+		result.Cancelled = true
+	case err != nil:
+		result.Error = err.Error()
+		s.Broadcast("chat.done", result)
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.Broadcast("chat.done", result)
+	s.respondJSON(w, http.StatusOK, result)
+}
+
+// handleWebSocketMessage handles a message sent by a WebSocket client
+func (s *Server) handleWebSocketMessage(msgType string, data json.RawMessage) {
+	switch msgType {
+	case "chat.cancel":
+		var input struct {
+			ChatID string `json:"chat_id"`
+		}
+		if json.Unmarshal(data, &input) == nil {
+			s.cancelChat(input.ChatID)
+		}
+	}
+}
+
+// trackChat records the cancel function of an in-flight chat. It reports
+// false if the ID is already taken.
+func (s *Server) trackChat(chatID string, cancel context.CancelFunc) bool {
+	s.chatsMu.Lock()
+	defer s.chatsMu.Unlock()
+	if s.chats == nil {
+		s.chats = make(map[string]context.CancelFunc)
+	}
+	if _, ok := s.chats[chatID]; ok {
+		return false
+	}
+	s.chats[chatID] = cancel
+	return true
+}
+
+func (s *Server) untrackChat(chatID string) {
+	s.chatsMu.Lock()
+	defer s.chatsMu.Unlock()
+	delete(s.chats, chatID)
+}
+
+// cancelChat stops an in-flight chat, reporting whether it was found
+func (s *Server) cancelChat(chatID string) bool {
+	s.chatsMu.Lock()
+	cancel, ok := s.chats[chatID]
+	s.chatsMu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+)
+
+// handleSearchEntities lists entities whose name or an alias matches q
+func (s *Server) handleSearchEntities(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	entities, err := s.entityStore.Search(r.URL.Query().Get("q"), limit)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, entities)
+}
+
+// handleEntityAbout returns everything about the entity behind an email
+// address or name
+func (s *Server) handleEntityAbout(w http.ResponseWriter, r *http.Request) {
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		s.respondError(w, http.StatusBadRequest, "ref required")
+		return
+	}
+
+	profile, err := s.entityStore.About(ref, 20)
+	if err != nil {
+		s.respondEntityError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, profile)
+}
+
+func (s *Server) handleGetEntity(w http.ResponseWriter, r *http.Request) {
+	profile, err := s.entityStore.Profile(chi.URLParam(r, "entityID"), 20)
+	if err != nil {
+		s.respondEntityError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, profile)
+}
+
+// handleMergeEntities folds source_id into the entity in the path
+func (s *Server) handleMergeEntities(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		SourceID string `json:"source_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if input.SourceID == "" {
+		s.respondError(w, http.StatusBadRequest, "source_id required")
+		return
+	}
+
+	entity, err := s.entityStore.Merge(chi.URLParam(r, "entityID"), input.SourceID)
+	if err != nil {
+		s.respondEntityError(w, err)
+		return
+	}
+
+	s.Broadcast("entity.merged", entity)
+	s.respondJSON(w, http.StatusOK, entity)
+}
+
+// handleSplitEntity moves aliases off the entity in the path onto a new one
+func (s *Server) handleSplitEntity(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Aliases []string `json:"aliases"`
+		Name    string   `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	entity, err := s.entityStore.Split(chi.URLParam(r, "entityID"), input.Aliases, input.Name)
+	if err != nil {
+		s.respondEntityError(w, err)
+		return
+	}
+
+	s.Broadcast("entity.split", entity)
+	s.respondJSON(w, http.StatusCreated, entity)
+}
+
+func (s *Server) respondEntityError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, core.ErrEntityNotFound):
+		s.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, core.ErrInvalidInput):
+		s.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,539 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/finance"
+	"github.com/quantumlife/quantumlife/internal/notifications"
+)
+
+const (
+	// maxStatementSize caps uploaded bank statements
+	maxStatementSize = 10 << 20
+
+	// maxWebhookSize caps Plaid webhook bodies
+	maxWebhookSize = 1 << 20
+)
+
+// handleFinanceImport imports an uploaded OFX, QFX or CSV statement. The
+// multipart form has the file under "file" and optional "profile",
+// "account" and "account_name" fields.
+func (s *Server) handleFinanceImport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStatementSize)
+	if err := r.ParseMultipartForm(maxStatementSize); err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid upload: "+err.Error())
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	stmt, err := s.statementImporter.Parse(header.Filename, file, r.FormValue("profile"))
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if account := r.FormValue("account"); account != "" {
+		stmt.Account.AccountID = account
+	}
+	if name := r.FormValue("account_name"); name != "" {
+		stmt.Account.Name = name
+	}
+
+	result, err := s.financeSpace.Import(r.Context(), stmt)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, result)
+}
+
+// handlePlaidWebhook receives Plaid webhooks. New transactions queue a
+// background sync of just that item; item errors and expiring consent ask the user to
+// re-link the bank.
+func (s *Server) handlePlaidWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+		return
+	}
+
+	if err := s.financeSpace.VerifyWebhook(r.Context(), r.Header.Get("Plaid-Verification"), body); err != nil {
+		if errors.Is(err, finance.ErrInvalidWebhook) {
+			s.respondError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		// Couldn't fetch the key; Plaid retries on failure
+		s.respondError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	var webhook finance.Webhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		s.respondError(w, http.StatusBadRequest, "invalid webhook: "+err.Error())
+		return
+	}
+
+	switch {
+	case webhook.WebhookType == finance.WebhookTypeTransactions && webhook.WebhookCode == finance.WebhookCodeSyncUpdatesAvailable:
+		// Plaid retries webhooks that aren't acknowledged quickly, so the
+		// sync runs in the background
+		err := s.financeSpace.QueueItemSync(webhook.ItemID)
+		if errors.Is(err, finance.ErrUnknownItem) {
+			s.respondJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		}
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		s.respondJSON(w, http.StatusOK, map[string]string{"status": "queued"})
+
+	case webhook.WebhookType == finance.WebhookTypeItem && webhook.WebhookCode == finance.WebhookCodeError:
+		conn, err := s.financeSpace.SetItemStatus(webhook.ItemID, finance.ConnectionStatusError)
+		if errors.Is(err, finance.ErrUnknownItem) {
+			s.respondJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		}
+		if err != nil {
+			s.respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		reason := "The connection stopped working."
+		if webhook.Error != nil && webhook.Error.DisplayMsg != "" {
+			reason = webhook.Error.DisplayMsg
+		}
+		s.notifyRelink(r.Context(), conn, reason, webhook.WebhookCode)
+		s.respondJSON(w, http.StatusOK, map[string]string{"status": "relink_required"})
+
+	case webhook.WebhookType == finance.WebhookTypeItem && webhook.WebhookCode == finance.WebhookCodePendingExpiration:
+		conn := s.financeSpace.GetItemConnection(webhook.ItemID)
+		if conn == nil {
+			s.respondJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+			return
+		}
+
+		reason := "Access to this bank expires soon."
+		if webhook.ConsentExpirationTime != nil {
+			reason = fmt.Sprintf("Access to this bank expires on %s.", webhook.ConsentExpirationTime.Format("January 2, 2006"))
+		}
+		s.notifyRelink(r.Context(), conn, reason, webhook.WebhookCode)
+		s.respondJSON(w, http.StatusOK, map[string]string{"status": "relink_required"})
+
+	default:
+		s.respondJSON(w, http.StatusOK, map[string]string{"status": "ignored"})
+	}
+}
+
+// notifyRelink asks the user to re-link a bank connection, if
+// notifications are configured
+func (s *Server) notifyRelink(ctx context.Context, conn *finance.Connection, reason, code string) {
+	if s.notificationService == nil {
+		return
+	}
+
+	_, err := s.notificationService.Create(ctx, notifications.CreateNotificationRequest{
+		Type:    notifications.NotifyActionRequired,
+		Title:   fmt.Sprintf("Reconnect %s", conn.InstitutionName),
+		Body:    reason + " Re-link the account to keep your transactions up to date.",
+		Urgency: notifications.UrgencyHigh,
+		HatID:   string(core.HatFinance),
+		ActionData: map[string]any{
+			"connection_id": conn.ID,
+			"item_id":       conn.ItemID,
+			"webhook_code":  code,
+		},
+	})
+	if err != nil {
+		fmt.Printf("Warning: failed to notify about %s: %v\n", conn.InstitutionName, err)
+	}
+}
+
+// handleRecategorizeTransaction moves a transaction to the category in
+// the body. Repeated corrections for a merchant become a learned rule.
+func (s *Server) handleRecategorizeTransaction(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Category string `json:"category"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	category := finance.Category(input.Category)
+	if !finance.IsValidCategory(category) {
+		s.respondError(w, http.StatusBadRequest, "unknown category: "+input.Category)
+		return
+	}
+
+	result, err := s.financeSpace.Recategorize(chi.URLParam(r, "transactionID"), category)
+	if errors.Is(err, finance.ErrTransactionNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// A correction of the categorization is a learning signal
+	if s.learningService != nil && result.Previous != category {
+		tx := result.Transaction
+		merchant := tx.MerchantName
+		if merchant == "" {
+			merchant = tx.Name
+		}
+		if err := s.learningService.Collector().CaptureTransactionCategorized(r.Context(), tx.TransactionID, merchant, string(result.Previous), string(category)); err != nil {
+			fmt.Printf("Warning: failed to record recategorization: %v\n", err)
+		}
+	}
+
+	s.respondJSON(w, http.StatusOK, result)
+}
+
+// handleSplitTransaction divides a transaction across the categories and
+// hats in the body, with amounts in the transaction's currency such as
+// {"amount":"120.00","currency":"USD"}. An empty list removes the split.
+func (s *Server) handleSplitTransaction(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Splits []finance.Split `json:"splits"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	tx, err := s.financeSpace.SplitTransaction(chi.URLParam(r, "transactionID"), input.Splits)
+	if errors.Is(err, finance.ErrTransactionNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, finance.ErrInvalidSplit) {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, tx)
+}
+
+// handleGetCategoryRules lists the categorization rules in the order they
+// are tried
+func (s *Server) handleGetCategoryRules(w http.ResponseWriter, r *http.Request) {
+	s.respondJSON(w, http.StatusOK, s.financeSpace.GetRules())
+}
+
+// handleCreateCategoryRule adds a user categorization rule
+func (s *Server) handleCreateCategoryRule(w http.ResponseWriter, r *http.Request) {
+	var rule finance.CategoryRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	rule = finance.CategoryRule{
+		Merchant:  rule.Merchant,
+		Pattern:   rule.Pattern,
+		MinAmount: rule.MinAmount,
+		MaxAmount: rule.MaxAmount,
+		Category:  rule.Category,
+		Priority:  rule.Priority,
+		Source:    finance.RuleSourceUser,
+	}
+	if err := rule.Validate(); err != nil {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.financeSpace.AddRule(&rule); err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, rule)
+}
+
+// handleDeleteCategoryRule removes a categorization rule
+func (s *Server) handleDeleteCategoryRule(w http.ResponseWriter, r *http.Request) {
+	err := s.financeSpace.DeleteRule(chi.URLParam(r, "ruleID"))
+	if errors.Is(err, finance.ErrRuleNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// handleGetBudgets reports every budget envelope in the current period, or
+// the period containing ?date=YYYY-MM-DD
+func (s *Server) handleGetBudgets(w http.ResponseWriter, r *http.Request) {
+	at := time.Now()
+	if date := r.URL.Query().Get("date"); date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, "date must be YYYY-MM-DD")
+			return
+		}
+		at = parsed
+	}
+
+	report, err := s.financeSpace.BudgetReport(at)
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, report)
+}
+
+// handleSetBudget sets a category's envelope from the current period on
+func (s *Server) handleSetBudget(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Amount   float64 `json:"amount"`
+		Rollover bool    `json:"rollover"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	env, err := s.financeSpace.SetBudget(finance.Category(chi.URLParam(r, "category")), input.Amount, input.Rollover)
+	if err != nil {
+		s.respondBudgetError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, env)
+}
+
+// handleDeleteBudget removes a category's envelope
+func (s *Server) handleDeleteBudget(w http.ResponseWriter, r *http.Request) {
+	if err := s.financeSpace.DeleteBudget(finance.Category(chi.URLParam(r, "category"))); err != nil {
+		s.respondBudgetError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// handleGetBudgetHistory returns an envelope's budget against actual
+// spending for each period
+func (s *Server) handleGetBudgetHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := s.financeSpace.GetBudgetHistory(finance.Category(chi.URLParam(r, "category")))
+	if err != nil {
+		s.respondBudgetError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"periods": history,
+		"count":   len(history),
+	})
+}
+
+// handleTransferBudget moves money between envelopes for the current
+// period
+func (s *Server) handleTransferBudget(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		From   finance.Category `json:"from"`
+		To     finance.Category `json:"to"`
+		Amount float64          `json:"amount"`
+		Note   string           `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	transfer, err := s.financeSpace.TransferBudget(input.From, input.To, input.Amount, input.Note)
+	if err != nil {
+		s.respondBudgetError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, transfer)
+}
+
+// handleSetBudgetPeriod changes how budget periods repeat
+func (s *Server) handleSetBudgetPeriod(w http.ResponseWriter, r *http.Request) {
+	var period finance.BudgetPeriod
+	if err := json.NewDecoder(r.Body).Decode(&period); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if err := s.financeSpace.SetBudgetPeriod(period); err != nil {
+		s.respondBudgetError(w, err)
+		return
+	}
+	s.respondJSON(w, http.StatusOK, period)
+}
+
+// respondBudgetError maps budget errors to status codes
+func (s *Server) respondBudgetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, finance.ErrBudgetNotFound):
+		s.respondError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, finance.ErrInvalidBudget):
+		s.respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// handleGetForecast projects each cash account's balance for the next
+// ?days= days
+func (s *Server) handleGetForecast(w http.ResponseWriter, r *http.Request) {
+	days := 0
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			s.respondError(w, http.StatusBadRequest, "Invalid days")
+			return
+		}
+		days = n
+	}
+
+	forecast, err := s.financeSpace.Forecast(days)
+	if errors.Is(err, finance.ErrInvalidForecast) {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, forecast)
+}
+
+// handleGetSubscriptions lists the active subscriptions with their price
+// history and alerts
+func (s *Server) handleGetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	s.respondJSON(w, http.StatusOK, s.financeSpace.GetSubscriptions())
+}
+
+// handleGetPlannedExpenses lists the planned expenses, soonest first
+func (s *Server) handleGetPlannedExpenses(w http.ResponseWriter, r *http.Request) {
+	planned := s.financeSpace.GetPlannedExpenses()
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"planned": planned,
+		"count":   len(planned),
+	})
+}
+
+// handleCreatePlannedExpense adds a one-off payment to the forecast
+func (s *Server) handleCreatePlannedExpense(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name      string           `json:"name"`
+		Amount    float64          `json:"amount"`
+		Currency  string           `json:"currency"`
+		Date      string           `json:"date"`
+		AccountID string           `json:"account_id"`
+		Category  finance.Category `json:"category"`
+		Note      string           `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	currency := input.Currency
+	if currency == "" {
+		currency = s.financeSpace.HomeCurrency()
+	}
+	planned := &finance.PlannedExpense{
+		Name:      input.Name,
+		Amount:    finance.NewMoney(input.Amount, currency),
+		Date:      input.Date,
+		AccountID: input.AccountID,
+		Category:  input.Category,
+		Note:      input.Note,
+	}
+
+	err := s.financeSpace.AddPlannedExpense(planned)
+	if errors.Is(err, finance.ErrInvalidPlannedExpense) {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, planned)
+}
+
+// handleDeletePlannedExpense removes a planned expense
+func (s *Server) handleDeletePlannedExpense(w http.ResponseWriter, r *http.Request) {
+	err := s.financeSpace.DeletePlannedExpense(chi.URLParam(r, "plannedID"))
+	if errors.Is(err, finance.ErrPlannedExpenseNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// handleGetReimbursements lists the expected reimbursements and what is
+// still owed
+func (s *Server) handleGetReimbursements(w http.ResponseWriter, r *http.Request) {
+	s.respondJSON(w, http.StatusOK, s.financeSpace.GetReimbursements())
+}
+
+// handleCreateReimbursement marks an expense as owed back. Without an
+// amount the whole expense is.
+func (s *Server) handleCreateReimbursement(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		TransactionID string  `json:"transaction_id"`
+		Amount        float64 `json:"amount"`
+		Payer         string  `json:"payer"`
+		Note          string  `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	reimbursement, err := s.financeSpace.ExpectReimbursement(input.TransactionID, input.Amount, input.Payer, input.Note)
+	if errors.Is(err, finance.ErrTransactionNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, finance.ErrInvalidReimbursement) {
+		s.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusCreated, reimbursement)
+}
+
+// handleDeleteReimbursement stops expecting a reimbursement
+func (s *Server) handleDeleteReimbursement(w http.ResponseWriter, r *http.Request) {
+	err := s.financeSpace.DeleteReimbursement(chi.URLParam(r, "reimbursementID"))
+	if errors.Is(err, finance.ErrReimbursementNotFound) {
+		s.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	s.respondJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
@@ -0,0 +1,716 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/quantumlife/quantumlife/internal/finance"
+	"github.com/quantumlife/quantumlife/internal/identity"
+	"github.com/quantumlife/quantumlife/internal/learning"
+	"github.com/quantumlife/quantumlife/internal/notifications"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+func uploadStatement(t *testing.T, srv *Server, filename, content string, fields map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if filename != "" {
+		fw, err := mw.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatalf("create form file: %v", err)
+		}
+		fw.Write([]byte(content))
+	}
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/api/v1/finance/import", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rr := httptest.NewRecorder()
+	srv.handleFinanceImport(rr, req)
+	return rr
+}
+
+func TestAPI_FinanceImport(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+
+	identityStore := storage.NewIdentityStore(db)
+	mgr := identity.NewManager(identityStore)
+	id, err := mgr.CreateIdentity("Test", "passphrase")
+	if err != nil {
+		t.Fatalf("CreateIdentity() error = %v", err)
+	}
+	space := finance.NewSpace(finance.SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(finance.NewStore(db, mgr, id.You.ID))
+	srv.financeSpace = space
+	srv.statementImporter = finance.NewImporter()
+
+	csv := "Date,Description,Amount\n2026-01-05,Coffee,-4.50\n2026-01-06,Groceries,-62.10\n"
+	rr := uploadStatement(t, srv, "export.csv", csv, map[string]string{"account": "checking", "account_name": "Checking"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var result finance.ImportResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if result.Imported != 2 || result.AccountID != "checking" {
+		t.Errorf("result = %+v", result)
+	}
+	if accounts := space.GetAccounts(); len(accounts) != 1 || accounts[0].Name != "Checking" {
+		t.Errorf("accounts = %+v", accounts)
+	}
+
+	// Uploading the same file again is harmless
+	rr = uploadStatement(t, srv, "export.csv", csv, map[string]string{"account": "checking"})
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.Imported != 0 || result.Duplicates != 2 {
+		t.Errorf("re-upload result = %+v", result)
+	}
+}
+
+func TestAPI_FinanceImport_BadRequest(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+	srv.financeSpace = finance.NewSpace(finance.SpaceConfig{ID: "finance", Name: "Finance"})
+	srv.statementImporter = finance.NewImporter()
+
+	if rr := uploadStatement(t, srv, "", "", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("missing file: expected status 400, got %d", rr.Code)
+	}
+	if rr := uploadStatement(t, srv, "export.csv", "Foo,Bar\n1,2\n", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown layout: expected status 400, got %d", rr.Code)
+	}
+}
+
+// plaidWebhookFixture is a finance space with one linked item, backed by a
+// local mock of the Plaid API that also serves the webhook signing key
+type plaidWebhookFixture struct {
+	srv      *Server
+	space    *finance.Space
+	store    *finance.Store
+	key      *ecdsa.PrivateKey
+	syncs    atomic.Int32
+	keyFetch int
+}
+
+func newPlaidWebhookFixture(t *testing.T) *plaidWebhookFixture {
+	t.Helper()
+
+	srv, db := testServer(t)
+	t.Cleanup(func() { db.Close() })
+
+	identityStore := storage.NewIdentityStore(db)
+	mgr := identity.NewManager(identityStore)
+	if _, err := mgr.CreateIdentity("Test", "passphrase"); err != nil {
+		t.Fatalf("CreateIdentity() error = %v", err)
+	}
+	you, keys, err := identityStore.LoadIdentity()
+	if err != nil {
+		t.Fatalf("LoadIdentity() error = %v", err)
+	}
+	if err := mgr.Unlock(you, keys, "passphrase"); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	f := &plaidWebhookFixture{srv: srv, key: key}
+
+	accounts := []finance.Account{{AccountID: "acc_1", Name: "Checking", Type: "depository", Balances: finance.AccountBalance{Current: 100}}}
+	plaid := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/webhook_verification_key/get":
+			f.keyFetch++
+			json.NewEncoder(w).Encode(map[string]interface{}{"key": finance.WebhookVerificationKey{
+				Alg: "ES256", Crv: "P-256", Kid: "key_1", Kty: "EC", Use: "sig",
+				X: base64.RawURLEncoding.EncodeToString(key.PublicKey.X.FillBytes(make([]byte, 32))),
+				Y: base64.RawURLEncoding.EncodeToString(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+			}})
+		case "/transactions/sync":
+			f.syncs.Add(1)
+			json.NewEncoder(w).Encode(finance.TransactionsSyncResponse{
+				Added: []finance.Transaction{{
+					TransactionID: "tx_1", AccountID: "acc_1", Amount: 4.50, Date: "2026-01-05",
+					Name: "Coffee", MerchantName: "Coffee", IsoCurrencyCode: "USD",
+				}},
+				NextCursor: "c1",
+			})
+		case "/accounts/balance/get":
+			json.NewEncoder(w).Encode(finance.AccountsResponse{Accounts: accounts})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(plaid.Close)
+
+	f.store = finance.NewStore(db, mgr, you.ID)
+	if err := f.store.SaveConnection(&finance.Connection{
+		ID:              "conn_1",
+		ItemID:          "item_1",
+		InstitutionID:   "ins_1",
+		InstitutionName: "Test Bank",
+		AccessToken:     "access-sandbox-secret",
+		Status:          finance.ConnectionStatusActive,
+		Accounts:        accounts,
+		CreatedAt:       time.Now(),
+	}); err != nil {
+		t.Fatalf("SaveConnection() error = %v", err)
+	}
+
+	f.space = finance.NewSpace(finance.SpaceConfig{
+		ID:          "finance",
+		Name:        "Finance",
+		PlaidConfig: finance.PlaidConfig{BaseURL: plaid.URL},
+	})
+	f.space.SetStore(f.store)
+	if err := f.space.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	srv.financeSpace = f.space
+	srv.notificationService = notifications.NewService(db)
+	return f
+}
+
+// sign returns a Plaid-Verification token for body, signed by the mock
+// Plaid key
+func (f *plaidWebhookFixture) sign(t *testing.T, body string) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": "key_1", "typ": "JWT"})
+	bodyHash := sha256.Sum256([]byte(body))
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat":                 time.Now().Unix(),
+		"request_body_sha256": hex.EncodeToString(bodyHash[:]),
+	})
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, f.key, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (f *plaidWebhookFixture) post(t *testing.T, body, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/api/v1/finance/webhooks/plaid", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Plaid-Verification", token)
+	}
+	rr := httptest.NewRecorder()
+	f.srv.handlePlaidWebhook(rr, req)
+	return rr
+}
+
+// send posts a correctly signed webhook
+func (f *plaidWebhookFixture) send(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	return f.post(t, body, f.sign(t, body))
+}
+
+func (f *plaidWebhookFixture) notifications(t *testing.T) []*notifications.Notification {
+	t.Helper()
+	list, err := f.srv.notificationService.List(context.Background(), notifications.NotificationFilter{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	return list
+}
+
+func TestAPI_PlaidWebhook_SyncUpdatesAvailable(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+
+	rr := f.send(t, `{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item_1"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var resp map[string]interface{}
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp["status"] != "queued" {
+		t.Errorf("response = %v", resp)
+	}
+
+	// The sync finishes after the webhook is acknowledged
+	deadline := time.Now().Add(5 * time.Second)
+	for len(f.space.GetTransactions(finance.TransactionFilter{})) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if txs := f.space.GetTransactions(finance.TransactionFilter{}); len(txs) != 1 {
+		t.Errorf("transactions = %d, want 1", len(txs))
+	}
+	if syncs := f.syncs.Load(); syncs != 1 {
+		t.Errorf("sync requests = %d, want 1", syncs)
+	}
+
+	// The signing key is cached
+	f.send(t, `{"webhook_type":"ITEM","webhook_code":"WEBHOOK_UPDATE_ACKNOWLEDGED","item_id":"item_1"}`)
+	if f.keyFetch != 1 {
+		t.Errorf("key fetches = %d, want 1", f.keyFetch)
+	}
+}
+
+func TestAPI_PlaidWebhook_InvalidSignature(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+	body := `{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item_1"}`
+
+	if rr := f.post(t, body, ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("missing token: expected status 401, got %d", rr.Code)
+	}
+	if rr := f.post(t, body, f.sign(t, `{}`)); rr.Code != http.StatusUnauthorized {
+		t.Errorf("token for another body: expected status 401, got %d", rr.Code)
+	}
+	if rr := f.post(t, body, "eyJhbGciOiJub25lIn0.e30."); rr.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned token: expected status 401, got %d", rr.Code)
+	}
+	if syncs := f.syncs.Load(); syncs != 0 {
+		t.Errorf("sync requests = %d, want 0", syncs)
+	}
+}
+
+func TestAPI_PlaidWebhook_ItemError(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+
+	rr := f.send(t, `{"webhook_type":"ITEM","webhook_code":"ERROR","item_id":"item_1","error":{"error_type":"ITEM_ERROR","error_code":"ITEM_LOGIN_REQUIRED","display_message":"Your bank password changed."}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	connections, _ := f.store.Connections()
+	if len(connections) != 1 || connections[0].Status != finance.ConnectionStatusError {
+		t.Errorf("stored connections = %+v", connections)
+	}
+
+	list := f.notifications(t)
+	if len(list) != 1 {
+		t.Fatalf("notifications = %d, want 1", len(list))
+	}
+	n := list[0]
+	if n.Type != notifications.NotifyActionRequired || !strings.Contains(n.Title, "Test Bank") || !strings.Contains(n.Body, "password changed") {
+		t.Errorf("notification = %+v", n)
+	}
+	if n.ActionData["connection_id"] != "conn_1" {
+		t.Errorf("action data = %v", n.ActionData)
+	}
+
+	// Updates for an errored item wait until it is re-linked
+	f.send(t, `{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item_1"}`)
+	if syncs := f.syncs.Load(); syncs != 0 {
+		t.Errorf("sync requests = %d, want 0", syncs)
+	}
+}
+
+func TestAPI_PlaidWebhook_PendingExpiration(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+
+	rr := f.send(t, `{"webhook_type":"ITEM","webhook_code":"PENDING_EXPIRATION","item_id":"item_1","consent_expiration_time":"2026-03-01T00:00:00Z"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	list := f.notifications(t)
+	if len(list) != 1 || !strings.Contains(list[0].Body, "March 1, 2026") {
+		t.Errorf("notifications = %+v", list)
+	}
+
+	// The item keeps syncing until consent actually expires
+	connections, _ := f.store.Connections()
+	if len(connections) != 1 || connections[0].Status != finance.ConnectionStatusActive {
+		t.Errorf("stored connections = %+v", connections)
+	}
+}
+
+func TestAPI_PlaidWebhook_Ignored(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+
+	for _, body := range []string{
+		`{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item_unknown"}`,
+		`{"webhook_type":"ITEM","webhook_code":"ERROR","item_id":"item_unknown"}`,
+		`{"webhook_type":"HOLDINGS","webhook_code":"DEFAULT_UPDATE","item_id":"item_1"}`,
+	} {
+		rr := f.send(t, body)
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "ignored") {
+			t.Errorf("%s: got %d %s", body, rr.Code, rr.Body.String())
+		}
+	}
+	if f.syncs.Load() != 0 || len(f.notifications(t)) != 0 {
+		t.Errorf("syncs = %d, notifications = %d", f.syncs.Load(), len(f.notifications(t)))
+	}
+}
+
+func TestAPI_RecategorizeTransaction(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+	if _, err := f.space.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	f.srv.learningService = learning.NewService(f.srv.db, learning.ServiceConfig{})
+
+	r := chi.NewRouter()
+	r.Put("/api/v1/finance/transactions/{transactionID}/category", f.srv.handleRecategorizeTransaction)
+	recategorize := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/v1/finance/transactions/"+id+"/category", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := recategorize("tx_1", `{"category":"groceries"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var result finance.Recategorization
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.Previous != finance.CategoryDining || result.Transaction == nil ||
+		result.Transaction.QLCategory != finance.CategoryGroceries || !result.Transaction.UserCategorized {
+		t.Errorf("result = %+v", result)
+	}
+
+	signals, err := f.srv.learningService.GetSignals(context.Background(), time.Now().Add(-time.Minute), learning.SignalTransactionCategorized)
+	if err != nil {
+		t.Fatalf("GetSignals() error = %v", err)
+	}
+	if len(signals) != 1 || signals[0].Value["to_category"] != "groceries" || signals[0].Value["merchant"] != "Coffee" {
+		t.Errorf("signals = %+v", signals)
+	}
+
+	if rr := recategorize("tx_1", `{"category":"snacks"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown category: expected status 400, got %d", rr.Code)
+	}
+	if rr := recategorize("tx_404", `{"category":"dining"}`); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown transaction: expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestAPI_CategoryRules(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/finance/rules", f.srv.handleGetCategoryRules)
+	r.Post("/api/v1/finance/rules", f.srv.handleCreateCategoryRule)
+	r.Delete("/api/v1/finance/rules/{ruleID}", f.srv.handleDeleteCategoryRule)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("POST", "/api/v1/finance/rules", `{"pattern":"coffee|cafe","max_amount":20,"category":"dining","priority":3,"source":"learned"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var rule finance.CategoryRule
+	json.NewDecoder(rr.Body).Decode(&rule)
+	if rule.ID == "" || rule.Source != finance.RuleSourceUser || rule.Priority != 3 {
+		t.Errorf("rule = %+v", rule)
+	}
+
+	if rr := do("POST", "/api/v1/finance/rules", `{"category":"dining"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("rule without conditions: expected status 400, got %d", rr.Code)
+	}
+
+	var rules []finance.CategoryRule
+	json.NewDecoder(do("GET", "/api/v1/finance/rules", "").Body).Decode(&rules)
+	if len(rules) != 1 || rules[0].ID != rule.ID {
+		t.Errorf("rules = %+v", rules)
+	}
+
+	if rr := do("DELETE", "/api/v1/finance/rules/"+rule.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("delete: expected status 200, got %d", rr.Code)
+	}
+	if rr := do("DELETE", "/api/v1/finance/rules/"+rule.ID, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("delete again: expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestAPI_Budgets(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/finance/budgets", f.srv.handleGetBudgets)
+	r.Put("/api/v1/finance/budgets/period", f.srv.handleSetBudgetPeriod)
+	r.Post("/api/v1/finance/budgets/transfers", f.srv.handleTransferBudget)
+	r.Put("/api/v1/finance/budgets/{category}", f.srv.handleSetBudget)
+	r.Delete("/api/v1/finance/budgets/{category}", f.srv.handleDeleteBudget)
+	r.Get("/api/v1/finance/budgets/{category}/history", f.srv.handleGetBudgetHistory)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("PUT", "/api/v1/finance/budgets/groceries", `{"amount":400,"rollover":true}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var env finance.Envelope
+	json.NewDecoder(rr.Body).Decode(&env)
+	if env.Category != finance.CategoryGroceries || env.Amount.Decimal() != "400.00" || !env.Rollover {
+		t.Errorf("envelope = %+v", env)
+	}
+	if rr := do("PUT", "/api/v1/finance/budgets/snacks", `{"amount":10}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown category: expected status 400, got %d", rr.Code)
+	}
+
+	if rr := do("POST", "/api/v1/finance/budgets/transfers", `{"from":"dining","to":"groceries","amount":40}`); rr.Code != http.StatusNotFound {
+		t.Errorf("transfer from missing envelope: expected status 404, got %d", rr.Code)
+	}
+	do("PUT", "/api/v1/finance/budgets/dining", `{"amount":100}`)
+	if rr := do("POST", "/api/v1/finance/budgets/transfers", `{"from":"dining","to":"groceries","amount":150}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("transfer more than available: expected status 400, got %d", rr.Code)
+	}
+	if rr := do("POST", "/api/v1/finance/budgets/transfers", `{"from":"dining","to":"groceries","amount":40,"note":"Cooking more"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("transfer: expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var report finance.BudgetReport
+	json.NewDecoder(do("GET", "/api/v1/finance/budgets", "").Body).Decode(&report)
+	if len(report.Envelopes) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, e := range report.Envelopes {
+		want := map[finance.Category]string{finance.CategoryGroceries: "440.00", finance.CategoryDining: "60.00"}[e.Category]
+		if e.Allowance().Decimal() != want {
+			t.Errorf("%s allowance = %s, want %s", e.Category, e.Allowance().Decimal(), want)
+		}
+	}
+	if rr := do("GET", "/api/v1/finance/budgets?date=October", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad date: expected status 400, got %d", rr.Code)
+	}
+
+	var history struct {
+		Periods []finance.EnvelopeStatus `json:"periods"`
+	}
+	json.NewDecoder(do("GET", "/api/v1/finance/budgets/groceries/history", "").Body).Decode(&history)
+	if len(history.Periods) != 1 || history.Periods[0].Transferred.Decimal() != "40.00" {
+		t.Errorf("history = %+v", history)
+	}
+
+	if rr := do("PUT", "/api/v1/finance/budgets/period", `{"kind":"custom","days":14}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("period without anchor: expected status 400, got %d", rr.Code)
+	}
+	if rr := do("PUT", "/api/v1/finance/budgets/period", `{"kind":"monthly","start_day":15}`); rr.Code != http.StatusOK {
+		t.Errorf("period: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := do("DELETE", "/api/v1/finance/budgets/dining", ""); rr.Code != http.StatusOK {
+		t.Errorf("delete: expected status 200, got %d", rr.Code)
+	}
+	if rr := do("GET", "/api/v1/finance/budgets/dining/history", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("history of deleted envelope: expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestAPI_Forecast(t *testing.T) {
+	f := newPlaidWebhookFixture(t)
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/finance/forecast", f.srv.handleGetForecast)
+	r.Get("/api/v1/finance/planned", f.srv.handleGetPlannedExpenses)
+	r.Post("/api/v1/finance/planned", f.srv.handleCreatePlannedExpense)
+	r.Delete("/api/v1/finance/planned/{plannedID}", f.srv.handleDeletePlannedExpense)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	due := time.Now().AddDate(0, 0, 5).Format("2006-01-02")
+	rr := do("POST", "/api/v1/finance/planned", `{"name":"Car repair","amount":250,"date":"`+due+`","category":"transport"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var planned finance.PlannedExpense
+	json.NewDecoder(rr.Body).Decode(&planned)
+	if planned.ID == "" || planned.Amount != (finance.Money{Minor: 25000, Currency: "USD"}) {
+		t.Errorf("planned = %+v", planned)
+	}
+	if rr := do("POST", "/api/v1/finance/planned", `{"name":"Gift","amount":50,"date":"soon"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("bad date: expected status 400, got %d", rr.Code)
+	}
+
+	var forecast finance.Forecast
+	rr = do("GET", "/api/v1/finance/forecast?days=30", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	json.NewDecoder(rr.Body).Decode(&forecast)
+	if len(forecast.Accounts) != 1 || len(forecast.Accounts[0].Days) != 30 {
+		t.Fatalf("forecast = %+v", forecast)
+	}
+	if len(forecast.Warnings) != 1 || forecast.Warnings[0].Date != due || forecast.Warnings[0].Balance.Minor != -15000 ||
+		len(forecast.Warnings[0].Bills) != 1 || forecast.Warnings[0].Bills[0] != "Car repair" {
+		t.Errorf("warnings = %+v", forecast.Warnings)
+	}
+	if rr := do("GET", "/api/v1/finance/forecast?days=365", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("long forecast: expected status 400, got %d", rr.Code)
+	}
+
+	if rr := do("DELETE", "/api/v1/finance/planned/"+planned.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("delete: expected status 200, got %d", rr.Code)
+	}
+	if rr := do("DELETE", "/api/v1/finance/planned/"+planned.ID, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("delete again: expected status 404, got %d", rr.Code)
+	}
+	var list struct {
+		Count int `json:"count"`
+	}
+	json.NewDecoder(do("GET", "/api/v1/finance/planned", "").Body).Decode(&list)
+	if list.Count != 0 {
+		t.Errorf("planned count = %d, want 0", list.Count)
+	}
+}
+
+func TestAPI_Subscriptions(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+
+	identityStore := storage.NewIdentityStore(db)
+	mgr := identity.NewManager(identityStore)
+	id, err := mgr.CreateIdentity("Test", "passphrase")
+	if err != nil {
+		t.Fatalf("CreateIdentity() error = %v", err)
+	}
+	space := finance.NewSpace(finance.SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(finance.NewStore(db, mgr, id.You.ID))
+	srv.financeSpace = space
+	srv.statementImporter = finance.NewImporter()
+
+	now := time.Now()
+	csv := "Date,Description,Amount\n"
+	for months, amount := range []string{"-16.99", "-16.99", "-15.49", "-15.49"} {
+		csv += now.AddDate(0, -months, 0).Format("2006-01-02") + ",Netflix," + amount + "\n"
+	}
+	if rr := uploadStatement(t, srv, "export.csv", csv, map[string]string{"account": "checking"}); rr.Code != http.StatusOK {
+		t.Fatalf("import: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/finance/subscriptions", nil)
+	rr := httptest.NewRecorder()
+	srv.handleGetSubscriptions(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var report finance.SubscriptionReport
+	if err := json.NewDecoder(rr.Body).Decode(&report); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(report.Subscriptions) != 1 || len(report.Subscriptions[0].PriceHistory) != 2 ||
+		report.MonthlyTotal != (finance.Money{Minor: 1699, Currency: "USD"}) {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(report.Alerts) != 1 || report.Alerts[0].Type != finance.SubscriptionPriceIncrease {
+		t.Errorf("alerts = %+v", report.Alerts)
+	}
+}
+
+func TestAPI_SplitsAndReimbursements(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+
+	identityStore := storage.NewIdentityStore(db)
+	mgr := identity.NewManager(identityStore)
+	id, err := mgr.CreateIdentity("Test", "passphrase")
+	if err != nil {
+		t.Fatalf("CreateIdentity() error = %v", err)
+	}
+	space := finance.NewSpace(finance.SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(finance.NewStore(db, mgr, id.You.ID))
+	srv.financeSpace = space
+	srv.statementImporter = finance.NewImporter()
+
+	csv := "Date,Description,Amount\n2026-02-03,Costco,-200.00\n2026-03-01,Grand Hotel,-300.00\n2026-03-20,ACME EXPENSES,300.00\n"
+	if rr := uploadStatement(t, srv, "export.csv", csv, map[string]string{"account": "checking"}); rr.Code != http.StatusOK {
+		t.Fatalf("import: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	ids := make(map[string]string)
+	for _, tx := range space.GetTransactions(finance.TransactionFilter{}) {
+		ids[tx.Name] = tx.TransactionID
+	}
+
+	r := chi.NewRouter()
+	r.Put("/api/v1/finance/transactions/{transactionID}/splits", srv.handleSplitTransaction)
+	r.Get("/api/v1/finance/reimbursements", srv.handleGetReimbursements)
+	r.Post("/api/v1/finance/reimbursements", srv.handleCreateReimbursement)
+	r.Delete("/api/v1/finance/reimbursements/{reimbursementID}", srv.handleDeleteReimbursement)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	splitPath := "/api/v1/finance/transactions/" + ids["Costco"] + "/splits"
+	rr := do("PUT", splitPath, `{"splits":[{"amount":{"amount":"120","currency":"USD"},"category":"groceries","hat_id":"home"},{"amount":{"amount":"80","currency":"USD"},"category":"shopping","hat_id":"parent"}]}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("split: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var split finance.CategorizedTransaction
+	json.NewDecoder(rr.Body).Decode(&split)
+	if len(split.Splits) != 2 || split.Splits[1].HatID != "parent" {
+		t.Errorf("split = %+v", split.Splits)
+	}
+	if rr := do("PUT", splitPath, `{"splits":[{"amount":{"amount":"120","currency":"USD"},"category":"groceries"},{"amount":{"amount":"70","currency":"USD"},"category":"shopping"}]}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("uneven split: expected status 400, got %d", rr.Code)
+	}
+	if rr := do("PUT", "/api/v1/finance/transactions/missing/splits", `{"splits":[]}`); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown transaction: expected status 404, got %d", rr.Code)
+	}
+
+	rr = do("POST", "/api/v1/finance/reimbursements", `{"transaction_id":"`+ids["Grand Hotel"]+`","payer":"Acme"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("reimbursement: expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var reimbursement finance.Reimbursement
+	json.NewDecoder(rr.Body).Decode(&reimbursement)
+	if reimbursement.Status != finance.ReimbursementReceived || reimbursement.CreditID != ids["ACME EXPENSES"] {
+		t.Errorf("reimbursement = %+v", reimbursement)
+	}
+	if rr := do("POST", "/api/v1/finance/reimbursements", `{"transaction_id":"`+ids["ACME EXPENSES"]+`"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("reimbursing a credit: expected status 400, got %d", rr.Code)
+	}
+
+	var report finance.ReimbursementReport
+	json.NewDecoder(do("GET", "/api/v1/finance/reimbursements", "").Body).Decode(&report)
+	if len(report.Reimbursements) != 1 || !report.Outstanding.IsZero() {
+		t.Errorf("report = %+v", report)
+	}
+
+	if rr := do("DELETE", "/api/v1/finance/reimbursements/"+reimbursement.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("delete: expected status 200, got %d", rr.Code)
+	}
+	if rr := do("DELETE", "/api/v1/finance/reimbursements/"+reimbursement.ID, ""); rr.Code != http.StatusNotFound {
+		t.Errorf("second delete: expected status 404, got %d", rr.Code)
+	}
+}
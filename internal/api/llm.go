@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// handleLLMStats returns per-provider requests, tokens, cost, latency and
+// circuit state, with spend against the budget
+func (s *Server) handleLLMStats(w http.ResponseWriter, r *http.Request) {
+	s.respondJSON(w, http.StatusOK, s.llmRouter.GetStats())
+}
+
+// handleLLMUsage returns daily usage per provider for the last days
+// (default 30), with the spend this month
+func (s *Server) handleLLMUsage(w http.ResponseWriter, r *http.Request) {
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 || days > 366 {
+		days = 30
+	}
+
+	now := time.Now()
+	usage, err := s.llmUsageStore.List(now.AddDate(0, 0, -(days - 1)).Format("2006-01-02"))
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	monthSpend, err := s.llmUsageStore.SpendSince(now.Format("2006-01") + "-01")
+	if err != nil {
+		s.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"days":            days,
+		"usage":           usage,
+		"month_spend_usd": monthSpend,
+		"budget":          s.llmRouter.GetStats().Budget,
+	})
+}
@@ -3,6 +3,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"sync"
 
@@ -16,6 +17,7 @@ import (
 type MCPAPI struct {
 	client  *mcp.Client
 	servers map[string]*mcpserver.Server
+	policy  *mcpserver.Policy
 	mu      sync.RWMutex
 }
 
@@ -31,9 +33,22 @@ func NewMCPAPI() *MCPAPI {
 func (m *MCPAPI) RegisterServer(name string, server *mcpserver.Server) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.policy != nil {
+		server.SetPolicy(m.policy)
+	}
 	m.servers[name] = server
 }
 
+// SetPolicy applies an authorization policy to all current and future servers
+func (m *MCPAPI) SetPolicy(policy *mcpserver.Policy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policy = policy
+	for _, server := range m.servers {
+		server.SetPolicy(policy)
+	}
+}
+
 // GetServer returns a registered MCP server
 func (m *MCPAPI) GetServer(name string) *mcpserver.Server {
 	m.mu.RLock()
@@ -126,21 +141,16 @@ func (m *MCPAPI) handleCallTool(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get tool handler
-	_, handler, ok := server.Registry().GetTool(toolName)
-	if !ok {
-		respondJSON(w, http.StatusNotFound, map[string]string{
-			"error": "tool not found: " + toolName,
-		})
+	ctx, err := server.AuthenticateRequest(r)
+	if err != nil {
+		respondMCPPolicyError(w, err)
 		return
 	}
 
-	// Execute tool
-	result, err := handler(r.Context(), args)
+	// Execute tool (policy is enforced by the server)
+	result, err := server.CallTool(ctx, toolName, args)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		respondMCPPolicyError(w, err)
 		return
 	}
 
@@ -159,7 +169,13 @@ func (m *MCPAPI) handleListResources(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resources := server.Registry().ListResources()
+	ctx, err := server.AuthenticateRequest(r)
+	if err != nil {
+		respondMCPPolicyError(w, err)
+		return
+	}
+
+	resources := server.ListResources(ctx)
 	respondJSON(w, http.StatusOK, map[string]interface{}{
 		"server":    name,
 		"resources": resources,
@@ -180,19 +196,16 @@ func (m *MCPAPI) handleReadResource(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, handler, ok := server.Registry().GetResource(uri)
-	if !ok {
-		respondJSON(w, http.StatusNotFound, map[string]string{
-			"error": "resource not found: " + uri,
-		})
+	ctx, err := server.AuthenticateRequest(r)
+	if err != nil {
+		respondMCPPolicyError(w, err)
 		return
 	}
 
-	content, err := handler(r.Context(), uri)
+	// Policy is enforced by the server
+	content, err := server.ReadResource(ctx, uri)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		respondMCPPolicyError(w, err)
 		return
 	}
 
@@ -222,30 +235,34 @@ func (m *MCPAPI) handleDirectCall(w http.ResponseWriter, r *http.Request) {
 
 	// Find tool across all servers
 	m.mu.RLock()
-	var handler mcpserver.ToolHandler
+	var server *mcpserver.Server
 	var serverName string
 	for name, srv := range m.servers {
-		if _, h, ok := srv.Registry().GetTool(req.Tool); ok {
-			handler = h
+		if _, _, ok := srv.Registry().GetTool(req.Tool); ok {
+			server = srv
 			serverName = name
 			break
 		}
 	}
 	m.mu.RUnlock()
 
-	if handler == nil {
+	if server == nil {
 		respondJSON(w, http.StatusNotFound, map[string]string{
 			"error": "tool not found: " + req.Tool,
 		})
 		return
 	}
 
-	// Execute tool
-	result, err := handler(r.Context(), req.Arguments)
+	ctx, err := server.AuthenticateRequest(r)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		respondMCPPolicyError(w, err)
+		return
+	}
+
+	// Execute tool (policy is enforced by the server)
+	result, err := server.CallTool(ctx, req.Tool, req.Arguments)
+	if err != nil {
+		respondMCPPolicyError(w, err)
 		return
 	}
 
@@ -256,6 +273,26 @@ func (m *MCPAPI) handleDirectCall(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// respondMCPPolicyError maps tool call and resource read errors to HTTP
+// status codes
+func respondMCPPolicyError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, mcpserver.ErrToolNotFound), errors.Is(err, mcpserver.ErrResourceNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, mcpserver.ErrUnauthenticated):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+		status = http.StatusUnauthorized
+	case errors.Is(err, mcpserver.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, mcpserver.ErrRateLimited):
+		status = http.StatusTooManyRequests
+	}
+	respondJSON(w, status, map[string]string{
+		"error": err.Error(),
+	})
+}
+
 // GetAllTools returns all tools across all servers
 func (m *MCPAPI) GetAllTools() []mcpserver.Tool {
 	m.mu.RLock()
@@ -565,3 +565,43 @@ func TestMCPAPI_ListResources_ServerNotFound(t *testing.T) {
 		t.Errorf("expected status 404, got %d", rr.Code)
 	}
 }
+
+func TestMCPAPI_CallTool_PolicyEnforced(t *testing.T) {
+	api := NewMCPAPI()
+	api.RegisterServer("test", createTestMCPServer())
+
+	policy := server.NewPolicy()
+	policy.AddClient(server.ClientPolicy{ID: "reader", Token: "read-token"})
+	policy.AddClient(server.ClientPolicy{ID: "writer", Token: "write-token", MaxAccess: server.AccessWrite})
+	api.SetPolicy(policy)
+
+	r := chi.NewRouter()
+	r.Post("/mcp/call", api.handleDirectCall)
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"read-only client calling untagged tool", "read-token", http.StatusForbidden},
+		{"write client", "write-token", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := bytes.NewBufferString(`{"tool": "test.echo", "arguments": {"message": "hi"}}`)
+			req := httptest.NewRequest("POST", "/mcp/call", body)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
@@ -17,16 +17,21 @@ import (
 
 	"github.com/quantumlife/quantumlife/internal/agent"
 	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/dav"
 	"github.com/quantumlife/quantumlife/internal/discovery"
+	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/identity"
 	"github.com/quantumlife/quantumlife/internal/learning"
 	"github.com/quantumlife/quantumlife/internal/ledger"
+	"github.com/quantumlife/quantumlife/internal/llm"
+	mcpserver "github.com/quantumlife/quantumlife/internal/mcp/server"
 	"github.com/quantumlife/quantumlife/internal/memory"
 	"github.com/quantumlife/quantumlife/internal/mesh"
 	"github.com/quantumlife/quantumlife/internal/notifications"
 	"github.com/quantumlife/quantumlife/internal/proactive"
 	"github.com/quantumlife/quantumlife/internal/nango"
 	"github.com/quantumlife/quantumlife/internal/spaces/calendar"
+	"github.com/quantumlife/quantumlife/internal/spaces/files"
 	"github.com/quantumlife/quantumlife/internal/spaces/gmail"
 	"github.com/quantumlife/quantumlife/internal/storage"
 	"github.com/quantumlife/quantumlife/internal/trust"
@@ -45,11 +50,20 @@ type Server struct {
 	db    *storage.DB
 	wsHub *WebSocketHub
 
+	// In-flight agent chats, cancellable over the WebSocket
+	chatsMu sync.Mutex
+	chats   map[string]context.CancelFunc
+
+	// LLM routing, cost and budget
+	llmRouter     *llm.Router
+	llmUsageStore *storage.LLMUsageStore
+
 	// Stores
 	hatStore      *storage.HatStore
 	itemStore     *storage.ItemStore
 	spaceStore    *storage.SpaceStore
 	identityStore *storage.IdentityStore
+	entityStore   *storage.EntityStore
 
 	// Memory manager
 	memoryMgr *memory.Manager
@@ -89,6 +103,17 @@ type Server struct {
 	gmailSpace    *gmail.Space
 	calendarSpace *calendar.Space
 
+	// CalDAV/CardDAV (Fastmail, Nextcloud, iCloud)
+	calDAV  *dav.CalDAVClient
+	cardDAV *dav.CardDAVClient
+
+	// Local Markdown vault
+	notesVault *files.Vault
+
+	// Finance space and statement import
+	financeSpace      *finance.Space
+	statementImporter *finance.Importer
+
 	// Nango client (OAuth/token lifecycle for 500+ APIs)
 	// ARCHITECTURAL PRINCIPLE: Auth infrastructure (Nango) is separate from
 	// authorization/agency (QuantumLife). OAuth/token possession ≠ permission-to-act.
@@ -115,12 +140,19 @@ type Config struct {
 	ExecutionEngine     *discovery.ExecutionEngine
 	NotificationService *notifications.Service
 	MCPAPI              *MCPAPI
+	MCPPolicy           *mcpserver.Policy
 	MeshHub             *mesh.Hub
 	LedgerStore         *ledger.Store
 	TrustStore          *trust.Store
 	MeshTrust           *trust.MeshTrust
 	GmailSpace          *gmail.Space
 	CalendarSpace       *calendar.Space
+	CalDAVClient        *dav.CalDAVClient
+	CardDAVClient       *dav.CardDAVClient
+	NotesVault          *files.Vault
+	FinanceSpace        *finance.Space
+	StatementImporter   *finance.Importer // Defaults to the built-in CSV profiles
+	LLMRouter           *llm.Router
 }
 
 // New creates a new API server
@@ -142,6 +174,14 @@ func New(cfg Config) *Server {
 		ledgerRecorder = ledger.NewRecorder(ledgerStore)
 	}
 
+	// Enforce MCP tool policy, auditing write-tool calls to the ledger
+	if cfg.MCPPolicy != nil {
+		if ledgerRecorder != nil {
+			cfg.MCPPolicy.SetAuditLogger(ledgerRecorder)
+		}
+		mcpAPI.SetPolicy(cfg.MCPPolicy)
+	}
+
 	// Create trust store and mesh trust
 	var trustStore *trust.Store
 	var meshTrust *trust.MeshTrust
@@ -167,6 +207,11 @@ func New(cfg Config) *Server {
 	// Initialize Nango client for OAuth/token lifecycle
 	nangoClient := nango.NewClient()
 
+	statementImporter := cfg.StatementImporter
+	if statementImporter == nil {
+		statementImporter = finance.NewImporter()
+	}
+
 	s := &Server{
 		agent:               cfg.Agent,
 		db:                  cfg.DB,
@@ -177,6 +222,7 @@ func New(cfg Config) *Server {
 		itemStore:           storage.NewItemStore(cfg.DB),
 		spaceStore:          storage.NewSpaceStore(cfg.DB),
 		identityStore:       storage.NewIdentityStore(cfg.DB),
+		entityStore:         storage.NewEntityStore(cfg.DB),
 		learningService:     cfg.LearningService,
 		proactiveService:    cfg.ProactiveService,
 		discoveryRegistry:   cfg.DiscoveryRegistry,
@@ -191,6 +237,13 @@ func New(cfg Config) *Server {
 		meshTrust:           meshTrust,
 		gmailSpace:          cfg.GmailSpace,
 		calendarSpace:       cfg.CalendarSpace,
+		calDAV:              cfg.CalDAVClient,
+		cardDAV:             cfg.CardDAVClient,
+		notesVault:          cfg.NotesVault,
+		financeSpace:        cfg.FinanceSpace,
+		statementImporter:   statementImporter,
+		llmRouter:           cfg.LLMRouter,
+		llmUsageStore:       storage.NewLLMUsageStore(cfg.DB),
 		nangoClient:         nangoClient,
 		wsHub:               NewWebSocketHub(),
 	}
@@ -218,6 +271,15 @@ func (s *Server) registerConnectedMCPServers() {
 
 	// Register Calendar MCP server if connected
 	s.registerCalendarMCPServer()
+
+	// Register CalDAV/CardDAV MCP servers if configured
+	s.registerDAVMCPServers()
+
+	// Register Notes MCP server if a vault is configured
+	s.registerNotesMCPServer()
+
+	// Register Finance MCP server if a finance space is configured
+	s.registerFinanceMCPServer()
 }
 
 // MCPAPI returns the MCP API handler for registering servers
@@ -267,6 +329,13 @@ func (s *Server) setupRouter() {
 		r.Get("/items/{itemID}", s.handleGetItem)
 		r.Put("/items/{itemID}", s.handleUpdateItem)
 
+		// Entities (people, organizations, places)
+		r.Get("/entities", s.handleSearchEntities)
+		r.Get("/entities/about", s.handleEntityAbout)
+		r.Get("/entities/{entityID}", s.handleGetEntity)
+		r.Post("/entities/{entityID}/merge", s.handleMergeEntities)
+		r.Post("/entities/{entityID}/split", s.handleSplitEntity)
+
 		// Memories
 		r.Get("/memories", s.handleGetMemories)
 		r.Post("/memories", s.handleCreateMemory)
@@ -304,6 +373,38 @@ func (s *Server) setupRouter() {
 		r.Post("/waitlist", s.handleJoinWaitlist)
 		r.Get("/waitlist/count", s.handleGetWaitlistCount)
 
+		// LLM usage and spend (if router configured)
+		if s.llmRouter != nil {
+			r.Get("/llm/stats", s.handleLLMStats)
+			r.Get("/llm/usage", s.handleLLMUsage)
+		}
+
+		// Finance statement import, Plaid webhooks and categorization
+		// rules (if space configured)
+		if s.financeSpace != nil {
+			r.Post("/finance/import", s.handleFinanceImport)
+			r.Post("/finance/webhooks/plaid", s.handlePlaidWebhook)
+			r.Put("/finance/transactions/{transactionID}/category", s.handleRecategorizeTransaction)
+			r.Put("/finance/transactions/{transactionID}/splits", s.handleSplitTransaction)
+			r.Get("/finance/rules", s.handleGetCategoryRules)
+			r.Post("/finance/rules", s.handleCreateCategoryRule)
+			r.Delete("/finance/rules/{ruleID}", s.handleDeleteCategoryRule)
+			r.Get("/finance/budgets", s.handleGetBudgets)
+			r.Put("/finance/budgets/period", s.handleSetBudgetPeriod)
+			r.Post("/finance/budgets/transfers", s.handleTransferBudget)
+			r.Put("/finance/budgets/{category}", s.handleSetBudget)
+			r.Delete("/finance/budgets/{category}", s.handleDeleteBudget)
+			r.Get("/finance/budgets/{category}/history", s.handleGetBudgetHistory)
+			r.Get("/finance/forecast", s.handleGetForecast)
+			r.Get("/finance/planned", s.handleGetPlannedExpenses)
+			r.Post("/finance/planned", s.handleCreatePlannedExpense)
+			r.Delete("/finance/planned/{plannedID}", s.handleDeletePlannedExpense)
+			r.Get("/finance/subscriptions", s.handleGetSubscriptions)
+			r.Get("/finance/reimbursements", s.handleGetReimbursements)
+			r.Post("/finance/reimbursements", s.handleCreateReimbursement)
+			r.Delete("/finance/reimbursements/{reimbursementID}", s.handleDeleteReimbursement)
+		}
+
 		// Notifications (if service configured)
 		if s.notificationService != nil {
 			notifAPI := NewNotificationsAPI(s.notificationService)
@@ -545,12 +646,17 @@ func (s *Server) handleUpdateHat(w http.ResponseWriter, r *http.Request) {
 		Description string `json:"description"`
 		Color       string `json:"color"`
 		IsActive    *bool  `json:"is_active"`
+		Sensitivity string `json:"sensitivity"` // standard, confidential or restricted
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
 		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
+	if updates.Sensitivity != "" && !core.Sensitivity(updates.Sensitivity).Valid() {
+		s.respondError(w, http.StatusBadRequest, "sensitivity must be standard, confidential or restricted")
+		return
+	}
 
 	hat, err := s.hatStore.GetByID(core.HatID(hatID))
 	if err != nil {
@@ -570,6 +676,9 @@ func (s *Server) handleUpdateHat(w http.ResponseWriter, r *http.Request) {
 	if updates.IsActive != nil {
 		hat.IsActive = *updates.IsActive
 	}
+	if updates.Sensitivity != "" {
+		hat.Sensitivity = core.Sensitivity(updates.Sensitivity)
+	}
 
 	if err := s.hatStore.Update(hat); err != nil {
 		s.respondError(w, http.StatusInternalServerError, err.Error())
@@ -655,6 +764,12 @@ func (s *Server) handleUpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rerouted := updates.HatID != "" && core.HatID(updates.HatID) != item.HatID
+	reprioritized := updates.Priority > 0 && updates.Priority != item.Priority
+	override := learning.TriageOverride{OriginalHatID: item.HatID, OriginalPriority: item.Priority}
+	if reprioritized {
+		override.Priority = updates.Priority
+	}
 	if updates.HatID != "" {
 		item.HatID = core.HatID(updates.HatID)
 	}
@@ -670,6 +785,20 @@ func (s *Server) handleUpdateItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep the hats of the people involved current
+	if rerouted {
+		if err := s.entityStore.LinkItem(item, nil); err != nil {
+			fmt.Printf("Warning: failed to relink entities: %v\n", err)
+		}
+	}
+
+	// A correction of the triage decision is a learning signal
+	if (rerouted || reprioritized) && s.learningService != nil {
+		if err := s.learningService.Collector().CaptureTriageOverride(r.Context(), item, item.HatID, override); err != nil {
+			fmt.Printf("Warning: failed to record triage override: %v\n", err)
+		}
+	}
+
 	s.Broadcast("item.updated", item)
 	s.respondJSON(w, http.StatusOK, item)
 }
@@ -709,6 +838,7 @@ func (s *Server) handleSearchMemories(w http.ResponseWriter, r *http.Request) {
 		Query string `json:"query"`
 		HatID string `json:"hat_id"`
 		Limit int    `json:"limit"`
+		Mode  string `json:"mode"` // hybrid (default), semantic or lexical
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -721,7 +851,24 @@ func (s *Server) handleSearchMemories(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	memories, err := s.agent.Remember(r.Context(), input.Query, core.HatID(input.HatID))
+	mode := memory.RetrievalMode(input.Mode)
+	switch mode {
+	case "", memory.RetrievalHybrid, memory.RetrievalSemantic, memory.RetrievalLexical:
+	default:
+		s.respondError(w, http.StatusBadRequest, "Invalid mode: must be hybrid, semantic or lexical")
+		return
+	}
+
+	limit := input.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	memories, err := s.agent.SearchMemories(r.Context(), input.Query, memory.RetrieveOptions{
+		HatID: core.HatID(input.HatID),
+		Limit: limit,
+		Mode:  mode,
+	})
 	if err != nil {
 		s.respondError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -753,33 +900,6 @@ func (s *Server) handleGetAgentStatus(w http.ResponseWriter, r *http.Request) {
 	s.respondJSON(w, http.StatusOK, stats)
 }
 
-func (s *Server) handleAgentChat(w http.ResponseWriter, r *http.Request) {
-	var input struct {
-		Message string `json:"message"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		s.respondError(w, http.StatusBadRequest, "Invalid JSON")
-		return
-	}
-
-	if input.Message == "" {
-		s.respondError(w, http.StatusBadRequest, "Message required")
-		return
-	}
-
-	// Use the agent's chat function
-	response, err := s.agent.Chat(r.Context(), input.Message, nil)
-	if err != nil {
-		s.respondError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	s.respondJSON(w, http.StatusOK, map[string]string{
-		"response": response,
-	})
-}
-
 func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	itemCount, _ := s.itemStore.Count()
 	hats, _ := s.hatStore.GetAll()
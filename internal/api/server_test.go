@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,7 +12,10 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/quantumlife/quantumlife/internal/agent"
 	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/learning"
+	"github.com/quantumlife/quantumlife/internal/llm"
 	"github.com/quantumlife/quantumlife/internal/storage"
 )
 
@@ -29,12 +33,13 @@ func testServer(t *testing.T) (*Server, *storage.DB) {
 	}
 
 	srv := &Server{
-		db:         db,
-		hatStore:   storage.NewHatStore(db),
-		itemStore:  storage.NewItemStore(db),
-		spaceStore: storage.NewSpaceStore(db),
-		mcpAPI:     NewMCPAPI(),
-		wsHub:      NewWebSocketHub(),
+		db:          db,
+		hatStore:    storage.NewHatStore(db),
+		itemStore:   storage.NewItemStore(db),
+		spaceStore:  storage.NewSpaceStore(db),
+		entityStore: storage.NewEntityStore(db),
+		mcpAPI:      NewMCPAPI(),
+		wsHub:       NewWebSocketHub(),
 	}
 
 	return srv, db
@@ -143,6 +148,33 @@ func TestAPI_UpdateHat_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestAPI_UpdateHat_Sensitivity(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+
+	r := chi.NewRouter()
+	r.Put("/api/v1/hats/{hatID}", srv.handleUpdateHat)
+
+	req := httptest.NewRequest("PUT", "/api/v1/hats/partner", bytes.NewBufferString(`{"sensitivity": "secret"}`))
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("unknown sensitivity: expected status 400, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest("PUT", "/api/v1/hats/partner", bytes.NewBufferString(`{"sensitivity": "restricted"}`))
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	hat, err := srv.hatStore.GetByID(core.HatPartner)
+	if err != nil || hat.Sensitivity != core.SensitivityRestricted {
+		t.Errorf("partner hat = %+v, %v, want restricted", hat, err)
+	}
+}
+
 // --- Items Tests ---
 
 func TestAPI_GetItems_Empty(t *testing.T) {
@@ -216,6 +248,101 @@ func TestAPI_UpdateItem_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestAPI_UpdateItem_RecordsTriageOverride(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+	srv.learningService = learning.NewService(db, learning.DefaultServiceConfig())
+
+	item := &core.Item{ID: "item-1", Type: core.ItemTypeEmail, Status: core.ItemStatusPending,
+		HatID: core.HatPersonal, Priority: 2, From: "billing@cityenergy.com", Subject: "Invoice overdue"}
+	srv.itemStore.Create(item)
+
+	r := chi.NewRouter()
+	r.Put("/api/v1/items/{itemID}", srv.handleUpdateItem)
+
+	update := func(body string) {
+		req := httptest.NewRequest("PUT", "/api/v1/items/item-1", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+	update(`{"status": "read"}`)
+	update(`{"hat_id": "finance", "priority": 3}`)
+
+	signals, err := srv.learningService.Collector().GetRecentSignals(context.Background(),
+		time.Now().Add(-time.Hour), learning.SignalTriageOverride)
+	if err != nil {
+		t.Fatalf("GetRecentSignals() error = %v", err)
+	}
+	if len(signals) != 1 {
+		t.Fatalf("expected 1 override, got %d", len(signals))
+	}
+	sig := signals[0]
+	if sig.HatID != core.HatFinance || sig.Value["original_hat_id"] != "personal" || sig.Value["priority"] != float64(3) {
+		t.Errorf("override = %+v", sig)
+	}
+}
+
+// --- Entities Tests ---
+
+func TestAPI_Entities_MergeAndSplit(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+
+	for i, from := range []string{"Alice Smith <alice@acme.io>", "alice@home.net"} {
+		item := &core.Item{ID: core.ItemID(fmt.Sprintf("item-%d", i)), Type: core.ItemTypeEmail,
+			Status: core.ItemStatusPending, HatID: core.HatPersonal, From: from}
+		srv.itemStore.Create(item)
+		srv.entityStore.LinkItem(item, nil)
+	}
+	alice, _ := srv.entityStore.Resolve("alice@acme.io")
+	home, _ := srv.entityStore.Resolve("alice@home.net")
+
+	r := chi.NewRouter()
+	r.Get("/api/v1/entities/about", srv.handleEntityAbout)
+	r.Get("/api/v1/entities/{entityID}", srv.handleGetEntity)
+	r.Post("/api/v1/entities/{entityID}/merge", srv.handleMergeEntities)
+	r.Post("/api/v1/entities/{entityID}/split", srv.handleSplitEntity)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("POST", "/api/v1/entities/"+alice.ID+"/merge", fmt.Sprintf(`{"source_id": %q}`, home.ID))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("merge: expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = do("GET", "/api/v1/entities/about?ref=alice@home.net", "")
+	var profile storage.EntityProfile
+	json.Unmarshal(rr.Body.Bytes(), &profile)
+	if rr.Code != http.StatusOK || profile.Entity.ID != alice.ID || profile.ItemCount != 2 {
+		t.Errorf("about: got %d %+v", rr.Code, profile)
+	}
+
+	rr = do("POST", "/api/v1/entities/"+alice.ID+"/split", `{"aliases": ["alice@home.net"], "name": "Alice Jones"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("split: expected status 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = do("POST", "/api/v1/entities/"+alice.ID+"/split", `{"aliases": ["nobody@example.com"]}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("split unknown alias: expected status 400, got %d", rr.Code)
+	}
+
+	rr = do("GET", "/api/v1/entities/missing", "")
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("get missing: expected status 404, got %d", rr.Code)
+	}
+}
+
 // --- Memories Tests ---
 
 func TestAPI_GetMemories(t *testing.T) {
@@ -286,6 +413,22 @@ func TestAPI_SearchMemories_EmptyQuery(t *testing.T) {
 	}
 }
 
+func TestAPI_SearchMemories_InvalidMode(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+
+	body := bytes.NewBufferString(`{"query": "invoice", "mode": "fuzzy"}`)
+	req := httptest.NewRequest("POST", "/api/v1/memories/search", body)
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	srv.handleSearchMemories(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", rr.Code)
+	}
+}
+
 // --- Spaces Tests ---
 
 func TestAPI_GetSpaces(t *testing.T) {
@@ -435,6 +578,150 @@ func TestAPI_AgentChat_EmptyMessage(t *testing.T) {
 	}
 }
 
+// streamingAgent returns an agent whose LLM streams the given words. With
+// hold set the stream stays open after the words until the request ends.
+func streamingAgent(t *testing.T, db *storage.DB, words []string, hold bool) *agent.Agent {
+	t.Helper()
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, word := range words {
+			data, _ := json.Marshal(map[string]interface{}{
+				"type":  "content_block_delta",
+				"delta": map[string]string{"type": "text_delta", "text": word},
+			})
+			fmt.Fprintf(w, "event: content_block_delta\ndata: %s\n\n", data)
+			w.(http.Flusher).Flush()
+		}
+		if hold {
+			<-r.Context().Done()
+		}
+	}))
+	t.Cleanup(llmServer.Close)
+
+	return agent.New(agent.Config{
+		DB:        db,
+		LLMClient: llm.NewClient(llm.Config{APIKey: "test", BaseURL: llmServer.URL}),
+	})
+}
+
+// watchBroadcasts registers a WebSocket client on a running hub
+func watchBroadcasts(srv *Server) chan WebSocketMessage {
+	go srv.wsHub.Run()
+	client := &WebSocketClient{hub: srv.wsHub, send: make(chan WebSocketMessage, 256)}
+	srv.wsHub.register <- client
+	return client.send
+}
+
+func TestAPI_AgentChat_StreamsOverWebSocket(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+	srv.agent = streamingAgent(t, db, []string{"Good ", "morning"}, false)
+	messages := watchBroadcasts(srv)
+
+	req := httptest.NewRequest("POST", "/api/v1/agent/chat", bytes.NewBufferString(`{"message": "hi", "chat_id": "c1"}`))
+	rr := httptest.NewRecorder()
+	srv.handleAgentChat(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var result ChatResult
+	json.NewDecoder(rr.Body).Decode(&result)
+	if result.ChatID != "c1" || result.Response != "Good morning" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	var deltas []string
+	for msg := range messages {
+		if msg.Type == "chat.delta" {
+			deltas = append(deltas, msg.Data.(ChatDelta).Delta)
+		}
+		if msg.Type == "chat.done" {
+			break
+		}
+	}
+	if len(deltas) != 2 || deltas[1] != "morning" {
+		t.Errorf("deltas = %q", deltas)
+	}
+}
+
+func TestAPI_AgentChat_CancelOverWebSocket(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+	srv.agent = streamingAgent(t, db, []string{"Partial"}, true)
+	messages := watchBroadcasts(srv)
+
+	req := httptest.NewRequest("POST", "/api/v1/agent/chat", bytes.NewBufferString(`{"message": "hi", "chat_id": "c2"}`))
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		srv.handleAgentChat(rr, req)
+		close(done)
+	}()
+
+	// Cancel once the first token arrives
+	for msg := range messages {
+		if msg.Type == "chat.delta" {
+			break
+		}
+	}
+	srv.handleWebSocketMessage("chat.cancel", json.RawMessage(`{"chat_id": "c2"}`))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("chat not cancelled")
+	}
+
+	var result ChatResult
+	json.NewDecoder(rr.Body).Decode(&result)
+	if !result.Cancelled || result.Response != "Partial" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if srv.cancelChat("c2") {
+		t.Error("cancelled chat still tracked")
+	}
+}
+
+func TestAPI_LLMStats(t *testing.T) {
+	srv, db := testServer(t)
+	defer db.Close()
+
+	llmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"usage":{"input_tokens":1000000,"output_tokens":0}}`))
+	}))
+	defer llmServer.Close()
+
+	srv.llmUsageStore = storage.NewLLMUsageStore(db)
+	srv.llmRouter = llm.NewRouter(llm.RouterConfig{
+		Claude:           llm.NewClient(llm.Config{APIKey: "test", BaseURL: llmServer.URL}),
+		MonthlyBudgetUSD: 100,
+	})
+	srv.llmRouter.SetUsageStore(srv.llmUsageStore)
+	if _, err := srv.llmRouter.Reason(context.Background(), "", "hi"); err != nil {
+		t.Fatalf("Reason() error = %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.handleLLMStats(rr, httptest.NewRequest("GET", "/api/v1/llm/stats", nil))
+	var stats llm.RouterStats
+	json.NewDecoder(rr.Body).Decode(&stats)
+	if stats.Providers[llm.ProviderClaude].CostUSD != 3 || stats.Budget.MonthlySpentUSD != 3 || stats.Budget.MonthlyLimitUSD != 100 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleLLMUsage(rr, httptest.NewRequest("GET", "/api/v1/llm/usage?days=7", nil))
+	var usage struct {
+		Usage         []storage.LLMUsage `json:"usage"`
+		MonthSpendUSD float64            `json:"month_spend_usd"`
+	}
+	json.NewDecoder(rr.Body).Decode(&usage)
+	if len(usage.Usage) != 1 || usage.Usage[0].Provider != "claude" || usage.MonthSpendUSD != 3 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
 // --- Create Item Tests ---
 
 func TestAPI_CreateItem_InvalidJSON(t *testing.T) {
@@ -11,7 +11,10 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	mcpcalendar "github.com/quantumlife/quantumlife/internal/mcp/servers/calendar"
+	mcpcontacts "github.com/quantumlife/quantumlife/internal/mcp/servers/contacts"
+	mcpfinance "github.com/quantumlife/quantumlife/internal/mcp/servers/finance"
 	mcpgmail "github.com/quantumlife/quantumlife/internal/mcp/servers/gmail"
+	mcpnotes "github.com/quantumlife/quantumlife/internal/mcp/servers/notes"
 )
 
 // SetupStatus represents the current setup progress
@@ -241,8 +244,9 @@ func (s *Server) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
 		if s.calendarSpace != nil {
 			err = s.calendarSpace.CompleteOAuth(r.Context(), code)
 			if err == nil {
-				// Register Calendar MCP server
+				// Register Calendar MCP server, moving CalDAV aside
 				s.registerCalendarMCPServer()
+				s.registerDAVMCPServers()
 			}
 		}
 	default:
@@ -310,6 +314,54 @@ func (s *Server) registerCalendarMCPServer() {
 	}
 }
 
+// registerDAVMCPServers registers MCP servers for CalDAV and CardDAV. CalDAV
+// serves the calendar.* tools as "calendar" unless Google Calendar is
+// connected, in which case it is registered as "caldav".
+func (s *Server) registerDAVMCPServers() {
+	if s.mcpAPI == nil {
+		return
+	}
+
+	if s.calDAV != nil {
+		name := "calendar"
+		if s.calendarSpace != nil && s.calendarSpace.IsConnected() {
+			name = "caldav"
+		}
+		if server := mcpcalendar.NewWithClient(s.calDAV); server != nil {
+			s.mcpAPI.RegisterServer(name, server.Server)
+		}
+	}
+
+	if s.cardDAV != nil {
+		if server := mcpcontacts.New(s.cardDAV); server != nil {
+			s.mcpAPI.RegisterServer("contacts", server.Server)
+		}
+	}
+}
+
+// registerNotesMCPServer registers the Notes MCP server for the local vault
+func (s *Server) registerNotesMCPServer() {
+	if s.mcpAPI == nil || s.notesVault == nil {
+		return
+	}
+
+	if server := mcpnotes.New(s.notesVault); server != nil {
+		s.mcpAPI.RegisterServer("notes", server.Server)
+	}
+}
+
+// registerFinanceMCPServer registers the Finance MCP server if a finance
+// space is configured
+func (s *Server) registerFinanceMCPServer() {
+	if s.mcpAPI == nil || s.financeSpace == nil {
+		return
+	}
+
+	if server := mcpfinance.New(s.financeSpace); server != nil {
+		s.mcpAPI.RegisterServer("finance", server.Server)
+	}
+}
+
 // Waitlist handlers
 
 // WaitlistEntry represents a waitlist signup
@@ -27,9 +27,10 @@ type WebSocketMessage struct {
 
 // WebSocketClient represents a connected client
 type WebSocketClient struct {
-	hub  *WebSocketHub
-	conn *websocket.Conn
-	send chan WebSocketMessage
+	hub    *WebSocketHub
+	conn   *websocket.Conn
+	send   chan WebSocketMessage
+	handle func(msgType string, data json.RawMessage) // Incoming messages
 }
 
 // WebSocketHub manages all WebSocket connections
@@ -103,9 +104,10 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &WebSocketClient{
-		hub:  s.wsHub,
-		conn: conn,
-		send: make(chan WebSocketMessage, 256),
+		hub:    s.wsHub,
+		conn:   conn,
+		send:   make(chan WebSocketMessage, 256),
+		handle: s.handleWebSocketMessage,
 	}
 
 	s.wsHub.register <- client
@@ -137,11 +139,19 @@ func (c *WebSocketClient) readPump() {
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
 			break
 		}
-		// We don't process incoming messages for now
+
+		var msg struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if json.Unmarshal(data, &msg) != nil || c.handle == nil {
+			continue
+		}
+		c.handle(msg.Type, msg.Data)
 	}
 }
 
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/llm"
 	"github.com/quantumlife/quantumlife/internal/storage"
 )
@@ -18,9 +19,17 @@ type Generator struct {
 	router    *llm.Router
 	itemStore *storage.ItemStore
 	hatStore  *storage.HatStore
+	entities  *storage.EntityStore
+	subs      SubscriptionSource
 	config    Config
 }
 
+// SubscriptionSource reports the user's subscriptions, e.g. the finance
+// space
+type SubscriptionSource interface {
+	GetSubscriptions() *finance.SubscriptionReport
+}
+
 // Config configures the briefing generator
 type Config struct {
 	MaxItemsPerHat   int           // Max items to include per hat
@@ -60,15 +69,26 @@ func NewGenerator(router *llm.Router, itemStore *storage.ItemStore, hatStore *st
 	}
 }
 
+// SetEntityStore groups senders by the person behind them, across addresses
+func (g *Generator) SetEntityStore(entities *storage.EntityStore) {
+	g.entities = entities
+}
+
+// SetSubscriptionSource adds a subscriptions digest to briefings
+func (g *Generator) SetSubscriptionSource(subs SubscriptionSource) {
+	g.subs = subs
+}
+
 // Briefing contains the generated briefing
 type Briefing struct {
-	Date        time.Time            `json:"date"`
-	Summary     string               `json:"summary"`
-	Sections    []Section            `json:"sections"`
-	Stats       *Stats               `json:"stats,omitempty"`
-	Priorities  []PriorityItem       `json:"priorities"`
-	GeneratedAt time.Time            `json:"generated_at"`
-	Format      Format               `json:"format"`
+	Date          time.Time           `json:"date"`
+	Summary       string              `json:"summary"`
+	Sections      []Section           `json:"sections"`
+	Stats         *Stats              `json:"stats,omitempty"`
+	Priorities    []PriorityItem      `json:"priorities"`
+	Subscriptions *SubscriptionDigest `json:"subscriptions,omitempty"`
+	GeneratedAt   time.Time           `json:"generated_at"`
+	Format        Format              `json:"format"`
 }
 
 // Section represents a briefing section (one per hat)
@@ -103,8 +123,18 @@ type Stats struct {
 
 // SenderStat tracks sender frequency
 type SenderStat struct {
-	Email  string `json:"email"`
-	Count  int    `json:"count"`
+	Email      string `json:"email"`
+	Count      int    `json:"count"`
+	EntityID   string `json:"entity_id,omitempty"`   // Person behind the sender, if known
+	Name       string `json:"name,omitempty"`        // Their canonical name
+	TotalItems int    `json:"total_items,omitempty"` // Items from or about them, all time
+}
+
+// SubscriptionDigest sums up subscriptions and what needs a look
+type SubscriptionDigest struct {
+	Count        int      `json:"count"`
+	MonthlyTotal string   `json:"monthly_total"`
+	Alerts       []string `json:"alerts,omitempty"` // Price increases, duplicates, trials turned paid
 }
 
 // PriorityItem is a high-priority item requiring attention
@@ -158,6 +188,8 @@ func (g *Generator) Generate(ctx context.Context) (*Briefing, error) {
 		stats = g.buildStats(recentItems, hatValues)
 	}
 
+	subscriptions := g.buildSubscriptions()
+
 	// Generate summary using AI
 	summary, err := g.generateSummary(ctx, sections, priorities)
 	if err != nil {
@@ -165,13 +197,14 @@ func (g *Generator) Generate(ctx context.Context) (*Briefing, error) {
 	}
 
 	return &Briefing{
-		Date:        now,
-		Summary:     summary,
-		Sections:    sections,
-		Stats:       stats,
-		Priorities:  priorities,
-		GeneratedAt: time.Now(),
-		Format:      g.config.BriefingFormat,
+		Date:          now,
+		Summary:       summary,
+		Sections:      sections,
+		Stats:         stats,
+		Priorities:    priorities,
+		Subscriptions: subscriptions,
+		GeneratedAt:   time.Now(),
+		Format:        g.config.BriefingFormat,
 	}, nil
 }
 
@@ -234,6 +267,27 @@ func (g *Generator) buildSections(ctx context.Context, items []*core.Item, hats
 	return sections
 }
 
+// buildSubscriptions sums up subscriptions, if there is a source and any
+// subscriptions
+func (g *Generator) buildSubscriptions() *SubscriptionDigest {
+	if g.subs == nil {
+		return nil
+	}
+	report := g.subs.GetSubscriptions()
+	if report == nil || len(report.Subscriptions) == 0 {
+		return nil
+	}
+
+	digest := &SubscriptionDigest{
+		Count:        len(report.Subscriptions),
+		MonthlyTotal: report.MonthlyTotal.String(),
+	}
+	for _, alert := range report.Alerts {
+		digest.Alerts = append(digest.Alerts, alert.Message)
+	}
+	return digest
+}
+
 // buildPriorities identifies high-priority items
 func (g *Generator) buildPriorities(items []*core.Item) []PriorityItem {
 	var priorities []PriorityItem
@@ -292,7 +346,9 @@ func (g *Generator) buildStats(items []*core.Item, hats []core.Hat) *Stats {
 		ItemsByHat:  make(map[string]int),
 	}
 
-	senderCount := make(map[string]int)
+	senders := make(map[string]*SenderStat)
+	var order []string
+	people := make(map[string]*storage.EntityProfile) // By sender address
 
 	for _, item := range items {
 		// Count by hat
@@ -307,24 +363,40 @@ func (g *Generator) buildStats(items []*core.Item, hats []core.Hat) *Stats {
 			stats.ProcessedItems++
 		}
 
-		// Count senders
+		// Count senders, as people when the entity graph knows them
 		if item.From != "" {
-			senderCount[item.From]++
+			key, stat := item.From, SenderStat{Email: item.From}
+			profile, looked := people[item.From]
+			if !looked && g.entities != nil {
+				profile, _ = g.entities.About(item.From, 0)
+				people[item.From] = profile
+			}
+			if profile != nil {
+				key = profile.Entity.ID
+				stat.EntityID = profile.Entity.ID
+				stat.Name = profile.Entity.Name
+				stat.TotalItems = profile.ItemCount
+			}
+			if _, ok := senders[key]; !ok {
+				senders[key] = &stat
+				order = append(order, key)
+			}
+			senders[key].Count++
 		}
 	}
 
 	// Top senders
-	var senders []SenderStat
-	for email, count := range senderCount {
-		senders = append(senders, SenderStat{Email: email, Count: count})
+	var top []SenderStat
+	for _, key := range order {
+		top = append(top, *senders[key])
 	}
-	sort.Slice(senders, func(i, j int) bool {
-		return senders[i].Count > senders[j].Count
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Count > top[j].Count
 	})
-	if len(senders) > 5 {
-		senders = senders[:5]
+	if len(top) > 5 {
+		top = top[:5]
 	}
-	stats.TopSenders = senders
+	stats.TopSenders = top
 
 	return stats
 }
@@ -355,10 +427,25 @@ func (g *Generator) generateSummary(ctx context.Context, sections []Section, pri
 
 	system := "You are a helpful assistant creating daily briefing summaries. Be concise, friendly, and actionable."
 
+	// Section counts are harmless; priority subjects carry their hat's
+	// sensitivity
+	sensitivity := core.SensitivityStandard
+	if g.hatStore != nil {
+		hatIDs := make([]core.HatID, 0, len(priorities))
+		for _, p := range priorities {
+			hatIDs = append(hatIDs, p.HatID)
+		}
+		var err error
+		if sensitivity, err = g.hatStore.Sensitivity(hatIDs...); err != nil {
+			return "", err
+		}
+	}
+
 	response, err := g.router.Route(ctx, llm.RouteRequest{
 		System:        system,
 		Prompt:        sb.String(),
 		MinComplexity: llm.ComplexityLow,
+		Sensitivity:   sensitivity,
 	})
 	if err != nil {
 		return "", err
@@ -416,6 +503,16 @@ func (b *Briefing) RenderText() string {
 		sb.WriteString("\n")
 	}
 
+	if b.Subscriptions != nil {
+		sb.WriteString("SUBSCRIPTIONS\n")
+		sb.WriteString(strings.Repeat("-", 20) + "\n")
+		sb.WriteString(fmt.Sprintf("%d subscriptions, %s a month\n", b.Subscriptions.Count, b.Subscriptions.MonthlyTotal))
+		for _, alert := range b.Subscriptions.Alerts {
+			sb.WriteString(fmt.Sprintf("! %s\n", alert))
+		}
+		sb.WriteString("\n")
+	}
+
 	if b.Stats != nil {
 		sb.WriteString("STATISTICS\n")
 		sb.WriteString(strings.Repeat("-", 20) + "\n")
@@ -464,6 +561,17 @@ func (b *Briefing) RenderMarkdown() string {
 		sb.WriteString("\n")
 	}
 
+	if b.Subscriptions != nil {
+		sb.WriteString("## Subscriptions\n\n")
+		sb.WriteString(fmt.Sprintf("%d subscriptions, **%s** a month\n\n", b.Subscriptions.Count, b.Subscriptions.MonthlyTotal))
+		for _, alert := range b.Subscriptions.Alerts {
+			sb.WriteString(fmt.Sprintf("- ⚠️ %s\n", alert))
+		}
+		if len(b.Subscriptions.Alerts) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+
 	if b.Stats != nil {
 		sb.WriteString("## Statistics\n\n")
 		sb.WriteString(fmt.Sprintf("| Metric | Count |\n"))
@@ -476,7 +584,11 @@ func (b *Briefing) RenderMarkdown() string {
 		if len(b.Stats.TopSenders) > 0 {
 			sb.WriteString("**Top Senders:**\n")
 			for _, s := range b.Stats.TopSenders {
-				sb.WriteString(fmt.Sprintf("- %s (%d)\n", s.Email, s.Count))
+				if s.Name != "" {
+					sb.WriteString(fmt.Sprintf("- %s (%d, %d all time)\n", s.Name, s.Count, s.TotalItems))
+				} else {
+					sb.WriteString(fmt.Sprintf("- %s (%d)\n", s.Email, s.Count))
+				}
 			}
 		}
 	}
@@ -530,6 +642,15 @@ func (b *Briefing) RenderHTML() string {
 		sb.WriteString("</div>\n")
 	}
 
+	if b.Subscriptions != nil {
+		sb.WriteString("<h2>Subscriptions</h2>\n")
+		sb.WriteString(fmt.Sprintf("<p>%d subscriptions, <strong>%s</strong> a month</p>\n",
+			b.Subscriptions.Count, b.Subscriptions.MonthlyTotal))
+		for _, alert := range b.Subscriptions.Alerts {
+			sb.WriteString(fmt.Sprintf("<div class=\"priority\">%s</div>\n", alert))
+		}
+	}
+
 	if b.Stats != nil {
 		sb.WriteString("<h2>Statistics</h2>\n<div class=\"stats\">\n")
 		sb.WriteString(fmt.Sprintf("<div class=\"stat\"><div class=\"stat-value\">%d</div>Total</div>\n", b.Stats.TotalItems))
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/finance"
+	"github.com/quantumlife/quantumlife/internal/storage"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -202,6 +204,46 @@ func TestBuildStats_EmptyFrom(t *testing.T) {
 	}
 }
 
+func TestBuildStats_GroupsSendersByEntity(t *testing.T) {
+	db, err := storage.Open(storage.Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	itemStore := storage.NewItemStore(db)
+	entities := storage.NewEntityStore(db)
+	items := []*core.Item{
+		{ID: "1", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatProfessional, From: "Alice Smith <alice@acme.io>"},
+		{ID: "2", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatPersonal, From: "alice@home.net"},
+		{ID: "3", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatPersonal, From: "alice@home.net"},
+		{ID: "4", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatPersonal, From: "bob@example.com"},
+		{ID: "5", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatPersonal, From: "bob@example.com"},
+	}
+	for _, item := range items {
+		itemStore.Create(item)
+		entities.LinkItem(item, nil)
+	}
+	alice, _ := entities.Resolve("alice@acme.io")
+	home, _ := entities.Resolve("alice@home.net")
+	entities.Merge(alice.ID, home.ID)
+
+	gen := NewGenerator(nil, nil, nil, DefaultConfig())
+	gen.SetEntityStore(entities)
+	stats := gen.buildStats(items, nil)
+
+	if len(stats.TopSenders) != 2 {
+		t.Fatalf("TopSenders = %+v, want Alice and Bob", stats.TopSenders)
+	}
+	top := stats.TopSenders[0]
+	if top.Name != "Alice Smith" || top.Count != 3 || top.TotalItems != 3 || top.EntityID != alice.ID {
+		t.Errorf("TopSenders[0] = %+v, want Alice Smith with 3 items", top)
+	}
+}
+
 func TestBuildSections(t *testing.T) {
 	cfg := DefaultConfig()
 	cfg.MaxItemsPerHat = 2
@@ -263,6 +305,45 @@ func TestBuildSections_UnknownHat(t *testing.T) {
 	}
 }
 
+type subscriptionSource func() *finance.SubscriptionReport
+
+func (f subscriptionSource) GetSubscriptions() *finance.SubscriptionReport { return f() }
+
+func TestBuildSubscriptions(t *testing.T) {
+	gen := NewGenerator(nil, nil, nil, DefaultConfig())
+	if digest := gen.buildSubscriptions(); digest != nil {
+		t.Errorf("without a source, digest = %+v", digest)
+	}
+
+	report := &finance.SubscriptionReport{Currency: "USD"}
+	gen.SetSubscriptionSource(subscriptionSource(func() *finance.SubscriptionReport { return report }))
+	if digest := gen.buildSubscriptions(); digest != nil {
+		t.Errorf("without subscriptions, digest = %+v", digest)
+	}
+
+	report.Subscriptions = []*finance.Subscription{{ID: "rec_netflix"}, {ID: "rec_hulu"}}
+	report.MonthlyTotal = finance.NewMoney(25.98, "USD")
+	report.Alerts = []finance.SubscriptionAlert{{
+		Type:    finance.SubscriptionPriceIncrease,
+		Message: "Netflix went up from $15.49 to $17.99 on 2026-10-05",
+	}}
+	digest := gen.buildSubscriptions()
+	if digest == nil || digest.Count != 2 || digest.MonthlyTotal != "$25.98" || len(digest.Alerts) != 1 {
+		t.Fatalf("digest = %+v", digest)
+	}
+
+	briefing := &Briefing{Date: time.Now(), Subscriptions: digest, GeneratedAt: time.Now()}
+	for name, out := range map[string]string{
+		"text":     briefing.RenderText(),
+		"markdown": briefing.RenderMarkdown(),
+		"html":     briefing.RenderHTML(),
+	} {
+		if !strings.Contains(out, "$25.98") || !strings.Contains(out, "Netflix went up") {
+			t.Errorf("%s briefing is missing the subscriptions:\n%s", name, out)
+		}
+	}
+}
+
 func TestFallbackSummary(t *testing.T) {
 	gen := NewGenerator(nil, nil, nil, DefaultConfig())
 
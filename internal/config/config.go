@@ -16,12 +16,28 @@ type Config struct {
 	Server ServerConfig `json:"server"`
 
 	// Services
-	Qdrant QdrantConfig `json:"qdrant"`
-	Ollama OllamaConfig `json:"ollama"`
-	Claude ClaudeConfig `json:"claude"`
+	Qdrant  QdrantConfig  `json:"qdrant"`
+	Vectors VectorsConfig `json:"vectors"`
+	Ollama  OllamaConfig  `json:"ollama"`
+	Claude  ClaudeConfig  `json:"claude"`
+
+	// LLM routing and cloud spend
+	LLM LLMConfig `json:"llm"`
 
 	// Features
 	Features FeatureConfig `json:"features"`
+
+	// MCP tool access policy
+	MCP MCPConfig `json:"mcp"`
+
+	// CalDAV/CardDAV account (Fastmail, Nextcloud, iCloud)
+	DAV DAVConfig `json:"dav"`
+
+	// Local Markdown (Obsidian) vault
+	Notes NotesConfig `json:"notes"`
+
+	// Bank statement import
+	Finance FinanceConfig `json:"finance"`
 }
 
 // ServerConfig for HTTP server
@@ -36,6 +52,12 @@ type QdrantConfig struct {
 	Port int    `json:"port"`
 }
 
+// VectorsConfig selects the vector index. "auto" uses Qdrant when it is
+// reachable and otherwise the embedded index stored in the data directory.
+type VectorsConfig struct {
+	Backend string `json:"backend"` // "auto", "qdrant" or "embedded"
+}
+
 // OllamaConfig for local LLM
 type OllamaConfig struct {
 	URL   string `json:"url"`
@@ -48,6 +70,26 @@ type ClaudeConfig struct {
 	Model  string `json:"model"`
 }
 
+// LLMConfig caps cloud LLM spend and tunes provider fallback. Budgets of
+// 0 are unlimited; once one is spent requests go to the local model.
+type LLMConfig struct {
+	DailyBudgetUSD   float64               `json:"daily_budget_usd,omitempty"`
+	MonthlyBudgetUSD float64               `json:"monthly_budget_usd,omitempty"`
+	TimeoutSeconds   int                   `json:"timeout_seconds,omitempty"` // Per provider attempt; until the first token when streaming
+	FallbackChain    []string              `json:"fallback_chain,omitempty"`  // e.g. ["claude", "azure", "ollama"]
+	Pricing          map[string]LLMPricing `json:"pricing,omitempty"`         // Per provider, overrides list prices
+
+	// Confidential hats (health, finance and partner by default) go to cloud
+	// models with personal data redacted. Set to keep them on Ollama instead.
+	ConfidentialLocalOnly bool `json:"confidential_local_only,omitempty"`
+}
+
+// LLMPricing is a provider's price in USD per million tokens
+type LLMPricing struct {
+	InputPerMTok  float64 `json:"input_per_mtok"`
+	OutputPerMTok float64 `json:"output_per_mtok"`
+}
+
 // FeatureConfig for feature flags
 type FeatureConfig struct {
 	EnableSync   bool `json:"enable_sync"`
@@ -56,6 +98,80 @@ type FeatureConfig struct {
 	DebugMode    bool `json:"debug_mode"`
 }
 
+// MCPConfig controls who may call MCP tools. When no clients are
+// configured every tool call is rejected unless AllowUnauthenticated is set.
+type MCPConfig struct {
+	Clients    []MCPClientConfig       `json:"clients,omitempty"`
+	RateLimits map[string]MCPRateLimit `json:"rate_limits,omitempty"` // tool name or "read"/"write"/"sensitive"
+	// AllowUnauthenticated leaves the MCP endpoints open when no clients are
+	// configured. Only use this when the daemon is reachable from localhost.
+	AllowUnauthenticated bool `json:"allow_unauthenticated,omitempty"`
+}
+
+// MCPClientConfig identifies an MCP client and what it may call
+type MCPClientConfig struct {
+	ID           string   `json:"id"`
+	Token        string   `json:"token"`
+	AllowedTools []string `json:"allowed_tools,omitempty"` // tool names, resource scopes like "finance.report" or globs like "gmail.*"
+	MaxAccess    string   `json:"max_access,omitempty"`    // "read" (default), "write" or "sensitive"
+}
+
+// MCPRateLimit caps calls per client within a window
+type MCPRateLimit struct {
+	Requests      int `json:"requests"`
+	WindowSeconds int `json:"window_seconds"`
+}
+
+// DAVConfig for a CalDAV/CardDAV account. The password can also be supplied
+// via QL_DAV_PASSWORD and is never written back to the config file.
+type DAVConfig struct {
+	URL                string `json:"url"`
+	Username           string `json:"username"`
+	Password           string `json:"password,omitempty"`
+	DefaultCalendar    string `json:"default_calendar,omitempty"`
+	DefaultAddressBook string `json:"default_address_book,omitempty"`
+}
+
+// NotesConfig for a local Markdown vault. Leave VaultPath empty to disable.
+type NotesConfig struct {
+	VaultPath string `json:"vault_path,omitempty"`
+	HatID     string `json:"hat_id,omitempty"` // Hat for notes without a "hat" in front-matter
+}
+
+// FinanceConfig for the finance space
+type FinanceConfig struct {
+	CSVProfiles  []CSVProfile `json:"csv_profiles,omitempty"`  // Tried before the built-in layouts
+	HomeCurrency string       `json:"home_currency,omitempty"` // Currency totals are reported in, default USD
+	FXRatesURL   string       `json:"fx_rates_url,omitempty"`  // Frankfurter-compatible rates API
+
+	// Cash-flow forecasts warn when an account is projected below this, in
+	// the home currency
+	LowBalanceThreshold float64 `json:"low_balance_threshold,omitempty"`
+
+	// Categories listed as deductible in tax-year reports, besides
+	// charitable giving, medical spending and business expenses
+	DeductibleCategories []string `json:"deductible_categories,omitempty"`
+}
+
+// CSVProfile maps the columns of a bank's CSV export, named by header.
+// Spending is negative in Amount unless OutflowPositive is set.
+type CSVProfile struct {
+	Name            string `json:"name"`
+	Delimiter       string `json:"delimiter,omitempty"`
+	SkipLines       int    `json:"skip_lines,omitempty"`
+	Date            string `json:"date"`
+	DateFormat      string `json:"date_format,omitempty"` // Go layout, e.g. "02.01.2006"
+	Description     string `json:"description"`
+	Merchant        string `json:"merchant,omitempty"`
+	Amount          string `json:"amount,omitempty"`
+	Debit           string `json:"debit,omitempty"`
+	Credit          string `json:"credit,omitempty"`
+	Currency        string `json:"currency,omitempty"`
+	ID              string `json:"id,omitempty"`
+	OutflowPositive bool   `json:"outflow_positive,omitempty"`
+	DecimalComma    bool   `json:"decimal_comma,omitempty"`
+}
+
 // Default returns default configuration
 func Default() *Config {
 	home, _ := os.UserHomeDir()
@@ -70,6 +186,9 @@ func Default() *Config {
 			Host: "localhost",
 			Port: 6334,
 		},
+		Vectors: VectorsConfig{
+			Backend: "auto",
+		},
 		Ollama: OllamaConfig{
 			URL:   "http://localhost:11434",
 			Model: "nomic-embed-text",
@@ -111,6 +230,9 @@ func Load(path string) (*Config, error) {
 	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
 		cfg.Claude.APIKey = apiKey
 	}
+	if password := os.Getenv("QL_DAV_PASSWORD"); password != "" {
+		cfg.DAV.Password = password
+	}
 
 	return cfg, nil
 }
@@ -129,6 +251,7 @@ func (c *Config) Save(path string) error {
 	// Don't save API key to file
 	safeCfg := *c
 	safeCfg.Claude.APIKey = ""
+	safeCfg.DAV.Password = ""
 
 	data, err := json.MarshalIndent(safeCfg, "", "  ")
 	if err != nil {
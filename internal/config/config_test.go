@@ -38,6 +38,9 @@ func TestDefault(t *testing.T) {
 	if cfg.Qdrant.Port != 6334 {
 		t.Errorf("Qdrant.Port = %d, want 6334", cfg.Qdrant.Port)
 	}
+	if cfg.Vectors.Backend != "auto" {
+		t.Errorf("Vectors.Backend = %q, want %q", cfg.Vectors.Backend, "auto")
+	}
 
 	// Verify Ollama defaults
 	if cfg.Ollama.URL != "http://localhost:11434" {
@@ -39,6 +39,9 @@ var (
 	ErrEmbeddingFailed = errors.New("failed to generate embedding")
 	ErrRetrievalFailed = errors.New("memory retrieval failed")
 
+	// Entity errors
+	ErrEntityNotFound = errors.New("entity not found")
+
 	// Agent errors
 	ErrAgentNotRunning = errors.New("agent is not running")
 	ErrLLMUnavailable  = errors.New("LLM service unavailable")
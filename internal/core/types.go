@@ -66,6 +66,70 @@ type Hat struct {
 	AutoRespond    bool   `json:"auto_respond"`    // Can agent respond automatically?
 	AutoPrioritize bool   `json:"auto_prioritize"` // Can agent prioritize items?
 	Personality    string `json:"personality"`     // Agent tone for this hat
+
+	// Privacy
+	Sensitivity Sensitivity `json:"sensitivity,omitempty"` // Empty uses DefaultSensitivity
+}
+
+// Sensitivity labels how carefully a hat's data must be handled when it is
+// sent to a language model
+type Sensitivity string
+
+const (
+	SensitivityStandard     Sensitivity = "standard"     // May go to cloud models
+	SensitivityConfidential Sensitivity = "confidential" // Cloud only after personal data is redacted
+	SensitivityRestricted   Sensitivity = "restricted"   // Local models only
+)
+
+// UnclassifiedSensitivity is the least care taken with an item that has not
+// been routed to a hat yet. Until then its hat is only the space default,
+// and the content may still be health or finance mail.
+const UnclassifiedSensitivity = SensitivityConfidential
+
+// DefaultSensitivity returns the sensitivity of a hat that has not been
+// configured. Health, finance and partner data is confidential.
+func DefaultSensitivity(id HatID) Sensitivity {
+	switch id {
+	case HatHealth, HatFinance, HatPartner:
+		return SensitivityConfidential
+	}
+	return SensitivityStandard
+}
+
+// EffectiveSensitivity returns the hat's configured sensitivity, or the
+// default for its ID
+func (h *Hat) EffectiveSensitivity() Sensitivity {
+	if h.Sensitivity != "" {
+		return h.Sensitivity
+	}
+	return DefaultSensitivity(h.ID)
+}
+
+// Valid reports whether s is a known sensitivity
+func (s Sensitivity) Valid() bool {
+	switch s {
+	case SensitivityStandard, SensitivityConfidential, SensitivityRestricted:
+		return true
+	}
+	return false
+}
+
+// Max returns the stricter of s and other
+func (s Sensitivity) Max(other Sensitivity) Sensitivity {
+	if other.rank() > s.rank() {
+		return other
+	}
+	return s
+}
+
+func (s Sensitivity) rank() int {
+	switch s {
+	case SensitivityConfidential:
+		return 1
+	case SensitivityRestricted:
+		return 2
+	}
+	return 0
 }
 
 // -----------------------------------------------------------------------------
@@ -228,6 +292,65 @@ type Memory struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// -----------------------------------------------------------------------------
+// ENTITY - People, organizations and places in your life
+// -----------------------------------------------------------------------------
+
+// EntityKind represents what an entity is
+type EntityKind string
+
+const (
+	EntityKindPerson       EntityKind = "person"
+	EntityKindOrganization EntityKind = "organization"
+	EntityKindPlace        EntityKind = "place"
+	EntityKindOther        EntityKind = "other" // Mentioned, but not classified
+)
+
+// AliasType represents how an entity is referred to
+type AliasType string
+
+const (
+	AliasTypeEmail AliasType = "email"
+	AliasTypeName  AliasType = "name"
+)
+
+// EntityAlias is one way an entity is referred to
+type EntityAlias struct {
+	Type  AliasType `json:"type"`
+	Value string    `json:"value"` // Normalized: lowercase, trimmed
+}
+
+// Entity is a canonical node in the entity graph
+type Entity struct {
+	ID      string        `json:"id"`
+	Kind    EntityKind    `json:"kind"`
+	Name    string        `json:"name"`    // Display name
+	Aliases []EntityAlias `json:"aliases"` // Email addresses and names
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EntityTarget is what an entity edge points at
+type EntityTarget string
+
+const (
+	EntityTargetItem  EntityTarget = "item"
+	EntityTargetHat   EntityTarget = "hat"
+	EntityTargetEvent EntityTarget = "event"
+)
+
+// EntityRelation describes how an entity relates to its target
+type EntityRelation string
+
+const (
+	RelationSender    EntityRelation = "sender"    // Sent the item
+	RelationRecipient EntityRelation = "recipient" // Received the item
+	RelationMentioned EntityRelation = "mentioned" // Named in the item
+	RelationAttendee  EntityRelation = "attendee"  // Attends the event
+	RelationActiveIn  EntityRelation = "active_in" // Has items in the hat
+)
+
 // -----------------------------------------------------------------------------
 // LEDGER - Audit trail
 // -----------------------------------------------------------------------------
@@ -0,0 +1,451 @@
+package dav
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	calclient "github.com/quantumlife/quantumlife/internal/spaces/calendar"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+// CalDAVClient provides calendar operations against a CalDAV server. It
+// satisfies the calendar MCP server's CalendarClient interface, so CalDAV
+// calendars are exposed through the same calendar.* tools as Google Calendar.
+//
+// Calendar IDs are collection hrefs; "primary" (or empty) refers to the
+// configured default collection or the first calendar discovered. Event IDs
+// are iCalendar UIDs.
+type CalDAVClient struct {
+	*Client
+	now func() time.Time
+}
+
+// NewCalDAVClient creates a CalDAV client
+func NewCalDAVClient(cfg Config) (*CalDAVClient, error) {
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &CalDAVClient{Client: client, now: time.Now}, nil
+}
+
+// ListCalendars returns the calendars in the user's calendar home
+func (c *CalDAVClient) ListCalendars(ctx context.Context) ([]calclient.CalendarInfo, error) {
+	collections, err := c.eventCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	primary, _ := c.calendarHref(ctx, "primary")
+	calendars := make([]calclient.CalendarInfo, 0, len(collections))
+	for _, col := range collections {
+		p := col.okProp()
+		name := p.DisplayName
+		if name == "" {
+			name = col.Href
+		}
+		calendars = append(calendars, calclient.CalendarInfo{
+			ID:          col.Href,
+			Summary:     name,
+			Description: p.CalendarDescription,
+			TimeZone:    calendarTimezoneID(p.CalendarTimezone),
+			Primary:     col.Href == primary,
+			AccessRole:  "owner",
+		})
+	}
+	return calendars, nil
+}
+
+// eventCollections returns calendar collections that can hold events,
+// skipping task-only lists
+func (c *CalDAVClient) eventCollections(ctx context.Context) ([]davResponse, error) {
+	collections, err := c.collections(ctx, "caldav")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]davResponse, 0, len(collections))
+	for _, col := range collections {
+		comps := col.okProp().SupportedComponents
+		if len(comps) == 0 {
+			result = append(result, col)
+			continue
+		}
+		for _, comp := range comps {
+			if strings.EqualFold(comp.Name, "VEVENT") {
+				result = append(result, col)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// calendarHref resolves a calendar ID to a collection href
+func (c *CalDAVClient) calendarHref(ctx context.Context, calendarID string) (string, error) {
+	if calendarID != "" && calendarID != "primary" {
+		return calendarID, nil
+	}
+	if c.defaultCalendar != "" {
+		return c.defaultCalendar, nil
+	}
+
+	collections, err := c.eventCollections(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(collections) == 0 {
+		return "", fmt.Errorf("%w: no calendars found", ErrNotFound)
+	}
+	return collections[0].Href, nil
+}
+
+// GetEvents retrieves events from a calendar within a time range.
+// Recurring events are expanded by the server where supported.
+func (c *CalDAVClient) GetEvents(ctx context.Context, calendarID string, start, end time.Time) ([]calclient.Event, error) {
+	href, err := c.calendarHref(ctx, calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := c.report(ctx, href, calendarQuery(start, end))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+
+	events := make([]calclient.Event, 0, len(objects))
+	for _, obj := range objects {
+		for _, e := range parseEvents(obj.Data, href) {
+			// Servers are not required to honor the time-range filter
+			if e.Status == "cancelled" || !overlaps(e, start, end) {
+				continue
+			}
+			e.Metadata["href"] = obj.Href
+			e.Metadata["etag"] = obj.ETag
+			events = append(events, e)
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Start.Before(events[j].Start)
+	})
+	return events, nil
+}
+
+func overlaps(e calclient.Event, start, end time.Time) bool {
+	if !e.Start.Before(end) {
+		return false
+	}
+	if e.End.Equal(e.Start) {
+		return !e.Start.Before(start)
+	}
+	return e.End.After(start)
+}
+
+// GetUpcomingEvents retrieves events for the next N days
+func (c *CalDAVClient) GetUpcomingEvents(ctx context.Context, days int) ([]calclient.Event, error) {
+	now := c.now()
+	end := now.AddDate(0, 0, days)
+	return c.GetEvents(ctx, "primary", now, end)
+}
+
+// GetTodayEvents retrieves today's events
+func (c *CalDAVClient) GetTodayEvents(ctx context.Context) ([]calclient.Event, error) {
+	now := c.now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+	return c.GetEvents(ctx, "primary", startOfDay, endOfDay)
+}
+
+// GetEvent retrieves a single event by UID
+func (c *CalDAVClient) GetEvent(ctx context.Context, calendarID, eventID string) (*calclient.Event, error) {
+	href, err := c.calendarHref(ctx, calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, event, err := c.findEvent(ctx, href, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+	return event, nil
+}
+
+// findEvent locates the object resource holding an event. For recurring
+// events the master component is returned.
+func (c *CalDAVClient) findEvent(ctx context.Context, calendarHref, uid string) (*object, *calclient.Event, error) {
+	objects, err := c.report(ctx, calendarHref, calendarUIDQuery(uid))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range objects {
+		obj := &objects[i]
+		for _, e := range parseEvents(obj.Data, calendarHref) {
+			if e.ID != uid || e.Metadata["recurrence_id"] != "" {
+				continue
+			}
+			e.Metadata["href"] = obj.Href
+			e.Metadata["etag"] = obj.ETag
+			return obj, &e, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: event %s", ErrNotFound, uid)
+}
+
+// CreateEvent creates a new calendar event
+func (c *CalDAVClient) CreateEvent(ctx context.Context, req calclient.CreateEventRequest) (*calclient.Event, error) {
+	href, err := c.calendarHref(ctx, req.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.now()
+	event := calclient.Event{
+		ID:          uuid.New().String(),
+		Summary:     req.Summary,
+		Description: req.Description,
+		Location:    req.Location,
+		Start:       req.Start,
+		End:         req.End,
+		AllDay:      req.AllDay,
+		Status:      "confirmed",
+		CalendarID:  href,
+		Reminders:   req.Reminders,
+		Metadata:    make(map[string]string),
+		Created:     now,
+		Updated:     now,
+	}
+	if event.End.IsZero() {
+		if event.AllDay {
+			event.End = event.Start.AddDate(0, 0, 1)
+		} else {
+			event.End = event.Start.Add(time.Hour)
+		}
+	}
+	for _, email := range req.Attendees {
+		event.Attendees = append(event.Attendees, calclient.Attendee{Email: email, ResponseStatus: "needsAction"})
+	}
+
+	objHref := childHref(href, event.ID+".ics")
+	etag, err := c.put(ctx, objHref, calendarContentType, encodeEvent(event), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event: %w", err)
+	}
+
+	event.Metadata["href"] = objHref
+	event.Metadata["etag"] = etag
+	return &event, nil
+}
+
+// UpdateEvent updates an existing event. Only the requested properties are
+// rewritten; everything else in the stored object is preserved.
+func (c *CalDAVClient) UpdateEvent(ctx context.Context, req calclient.UpdateEventRequest) (*calclient.Event, error) {
+	href, err := c.calendarHref(ctx, req.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, existing, err := c.findEvent(ctx, href, req.EventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing event: %w", err)
+	}
+
+	props := make(map[string][]string)
+	setText := func(name string, value *string) {
+		if value == nil {
+			return
+		}
+		if *value == "" {
+			props[name] = nil
+			return
+		}
+		props[name] = []string{name + ":" + escapeText(*value)}
+	}
+	setText("SUMMARY", req.Summary)
+	setText("DESCRIPTION", req.Description)
+	setText("LOCATION", req.Location)
+
+	end := req.End
+	if req.Start != nil {
+		props["DTSTART"] = []string{"DTSTART" + formatICalDate(*req.Start, false)}
+		if end == nil {
+			// Keep the event's length when only the start moves
+			shifted := req.Start.Add(existing.End.Sub(existing.Start))
+			end = &shifted
+		}
+	}
+	if end != nil {
+		props["DTEND"] = []string{"DTEND" + formatICalDate(*end, false)}
+		props["DURATION"] = nil
+	}
+
+	stamp := c.now().UTC().Format("20060102T150405Z")
+	sequence, _ := strconv.Atoi(existing.Metadata["sequence"])
+	props["DTSTAMP"] = []string{"DTSTAMP:" + stamp}
+	props["LAST-MODIFIED"] = []string{"LAST-MODIFIED:" + stamp}
+	props["SEQUENCE"] = []string{"SEQUENCE:" + strconv.Itoa(sequence+1)}
+
+	data := patchComponent(obj.Data, "VEVENT", props)
+	etag, err := c.put(ctx, obj.Href, calendarContentType, data, obj.ETag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update event: %w", err)
+	}
+
+	for _, e := range parseEvents(data, href) {
+		if e.ID == req.EventID && e.Metadata["recurrence_id"] == "" {
+			e.Metadata["href"] = obj.Href
+			e.Metadata["etag"] = etag
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to parse updated event")
+}
+
+// DeleteEvent deletes an event and all of its recurrences
+func (c *CalDAVClient) DeleteEvent(ctx context.Context, calendarID, eventID string) error {
+	href, err := c.calendarHref(ctx, calendarID)
+	if err != nil {
+		return err
+	}
+
+	obj, _, err := c.findEvent(ctx, href, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+	if err := c.remove(ctx, obj.Href, obj.ETag); err != nil {
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+	return nil
+}
+
+// QuickAdd creates an event from a short description such as
+// "Lunch with Sam tomorrow at 12:30 for 45 minutes". CalDAV has no server-side
+// natural language parsing, so only day, time and duration phrases are understood.
+func (c *CalDAVClient) QuickAdd(ctx context.Context, calendarID, text string) (*calclient.Event, error) {
+	summary, start, end := parseQuickAdd(text, c.now())
+	if summary == "" {
+		return nil, fmt.Errorf("event text is required")
+	}
+
+	return c.CreateEvent(ctx, calclient.CreateEventRequest{
+		Summary:    summary,
+		Start:      start,
+		End:        end,
+		CalendarID: calendarID,
+	})
+}
+
+// FindFreeTime finds free time slots in the primary calendar
+func (c *CalDAVClient) FindFreeTime(ctx context.Context, start, end time.Time, durationMinutes int) ([]calclient.TimeSlot, error) {
+	events, err := c.GetEvents(ctx, "primary", start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return calclient.FreeSlots(events, start, end, time.Duration(durationMinutes)*time.Minute), nil
+}
+
+var (
+	quickAddDay      = regexp.MustCompile(`(?i)\b(today|tomorrow)\b`)
+	quickAddTime     = regexp.MustCompile(`(?i)\bat\s+(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\b`)
+	quickAddDuration = regexp.MustCompile(`(?i)\bfor\s+(\d+)\s*(minutes|minute|mins|min|m|hours|hour|hrs|hr|h)\b`)
+	spaceRun         = regexp.MustCompile(`\s+`)
+)
+
+// parseQuickAdd extracts the summary, start and end from quick-add text.
+// Without an explicit time the event starts at the next full hour and lasts
+// one hour.
+func parseQuickAdd(text string, now time.Time) (summary string, start, end time.Time) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	explicitDay, tomorrow := false, false
+	if m := quickAddDay.FindStringSubmatch(text); m != nil {
+		explicitDay = true
+		if strings.EqualFold(m[1], "tomorrow") {
+			tomorrow = true
+			day = day.AddDate(0, 0, 1)
+		}
+		text = strings.Replace(text, m[0], "", 1)
+	}
+
+	duration := time.Hour
+	if m := quickAddDuration.FindStringSubmatch(text); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if strings.HasPrefix(strings.ToLower(m[2]), "h") {
+			duration = time.Duration(n) * time.Hour
+		} else {
+			duration = time.Duration(n) * time.Minute
+		}
+		text = strings.Replace(text, m[0], "", 1)
+	}
+
+	if m := quickAddTime.FindStringSubmatch(text); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		switch strings.ToLower(m[3]) {
+		case "pm":
+			if hour < 12 {
+				hour += 12
+			}
+		case "am":
+			if hour == 12 {
+				hour = 0
+			}
+		}
+		start = day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		if !explicitDay && start.Before(now) {
+			start = start.AddDate(0, 0, 1)
+		}
+		text = strings.Replace(text, m[0], "", 1)
+	} else {
+		start = now.Truncate(time.Hour).Add(time.Hour)
+		if tomorrow {
+			start = start.AddDate(0, 0, 1)
+		}
+	}
+
+	summary = strings.TrimSpace(spaceRun.ReplaceAllString(text, " "))
+	return summary, start, start.Add(duration)
+}
+
+// calendarTimezoneID extracts the TZID from a calendar-timezone VTIMEZONE
+func calendarTimezoneID(vtimezone string) string {
+	for _, l := range parseContentLines(vtimezone) {
+		if l.Name == "TZID" {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+// calendarQuery builds a calendar-query REPORT for events in a time range
+func calendarQuery(start, end time.Time) string {
+	from := start.UTC().Format("20060102T150405Z")
+	to := end.UTC().Format("20060102T150405Z")
+	return `<?xml version="1.0" encoding="utf-8"?>` +
+		`<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+		`<D:prop><D:getetag/><C:calendar-data><C:expand start="` + from + `" end="` + to + `"/></C:calendar-data></D:prop>` +
+		`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT">` +
+		`<C:time-range start="` + from + `" end="` + to + `"/>` +
+		`</C:comp-filter></C:comp-filter></C:filter>` +
+		`</C:calendar-query>`
+}
+
+// calendarUIDQuery builds a calendar-query REPORT matching an event UID
+func calendarUIDQuery(uid string) string {
+	return `<?xml version="1.0" encoding="utf-8"?>` +
+		`<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+		`<D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+		`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT">` +
+		`<C:prop-filter name="UID"><C:text-match collation="i;octet">` + xmlEscape(uid) + `</C:text-match></C:prop-filter>` +
+		`</C:comp-filter></C:comp-filter></C:filter>` +
+		`</C:calendar-query>`
+}
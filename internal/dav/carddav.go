@@ -0,0 +1,340 @@
+package dav
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const vcardContentType = "text/vcard; charset=utf-8"
+
+// AddressBook is a CardDAV address book collection
+type AddressBook struct {
+	ID          string `json:"id"` // collection href
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default"`
+}
+
+// UpdateContactRequest contains the fields to change on a contact.
+// Nil fields are left unchanged; an empty string clears the field.
+type UpdateContactRequest struct {
+	ContactID    string
+	FullName     *string
+	GivenName    *string
+	FamilyName   *string
+	Organization *string
+	Title        *string
+	Birthday     *string
+	Note         *string
+	Emails       []ContactField // replaces all emails when non-nil
+	Phones       []ContactField // replaces all phones when non-nil
+}
+
+// CardDAVClient provides contact operations against a CardDAV server.
+// Contact IDs are vCard UIDs.
+type CardDAVClient struct {
+	*Client
+}
+
+// NewCardDAVClient creates a CardDAV client
+func NewCardDAVClient(cfg Config) (*CardDAVClient, error) {
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &CardDAVClient{Client: client}, nil
+}
+
+// ListAddressBooks returns the address books in the user's home set
+func (c *CardDAVClient) ListAddressBooks(ctx context.Context) ([]AddressBook, error) {
+	collections, err := c.collections(ctx, "carddav")
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]AddressBook, 0, len(collections))
+	for i, col := range collections {
+		p := col.okProp()
+		name := p.DisplayName
+		if name == "" {
+			name = col.Href
+		}
+		isDefault := col.Href == c.defaultAddressBook || (c.defaultAddressBook == "" && i == 0)
+		books = append(books, AddressBook{
+			ID:          col.Href,
+			Name:        name,
+			Description: p.AddressBookDesc,
+			Default:     isDefault,
+		})
+	}
+	return books, nil
+}
+
+// addressBookHref resolves an address book ID to a collection href
+func (c *CardDAVClient) addressBookHref(ctx context.Context, addressBookID string) (string, error) {
+	if addressBookID != "" && addressBookID != "default" {
+		return addressBookID, nil
+	}
+	if c.defaultAddressBook != "" {
+		return c.defaultAddressBook, nil
+	}
+
+	collections, err := c.collections(ctx, "carddav")
+	if err != nil {
+		return "", err
+	}
+	if len(collections) == 0 {
+		return "", fmt.Errorf("%w: no address books found", ErrNotFound)
+	}
+	return collections[0].Href, nil
+}
+
+// ListContacts returns every contact in an address book, sorted by name
+func (c *CardDAVClient) ListContacts(ctx context.Context, addressBookID string) ([]Contact, error) {
+	href, err := c.addressBookHref(ctx, addressBookID)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := c.query(ctx, href, addressBookQuery(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	sortContacts(contacts)
+	return contacts, nil
+}
+
+// SearchContacts searches all address books by name, email, phone or
+// organization
+func (c *CardDAVClient) SearchContacts(ctx context.Context, query string, limit int) ([]Contact, error) {
+	books, err := c.ListAddressBooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Contact
+	for _, book := range books {
+		contacts, err := c.query(ctx, book.ID, addressBookQuery(query))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search contacts: %w", err)
+		}
+		// Servers differ in how they collate text-match, so filter locally too
+		for _, contact := range contacts {
+			if contact.Matches(query) {
+				matches = append(matches, contact)
+			}
+		}
+	}
+
+	sortContacts(matches)
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// GetContact retrieves a contact by UID from any address book
+func (c *CardDAVClient) GetContact(ctx context.Context, contactID string) (*Contact, error) {
+	_, contact, err := c.findContact(ctx, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	return contact, nil
+}
+
+// findContact locates the object resource holding a contact
+func (c *CardDAVClient) findContact(ctx context.Context, contactID string) (*object, *Contact, error) {
+	books, err := c.ListAddressBooks(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, book := range books {
+		objects, err := c.report(ctx, book.ID, addressBookUIDQuery(contactID))
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range objects {
+			contact := parseVCard(objects[i].Data)
+			if contact == nil || contact.ID != contactID {
+				continue
+			}
+			contact.AddressBookID = book.ID
+			contact.Href = objects[i].Href
+			contact.ETag = objects[i].ETag
+			return &objects[i], contact, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: contact %s", ErrNotFound, contactID)
+}
+
+// query runs an addressbook-query and parses the returned vCards
+func (c *CardDAVClient) query(ctx context.Context, href, body string) ([]Contact, error) {
+	objects, err := c.report(ctx, href, body)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts := make([]Contact, 0, len(objects))
+	for _, obj := range objects {
+		contact := parseVCard(obj.Data)
+		if contact == nil {
+			continue
+		}
+		contact.AddressBookID = href
+		contact.Href = obj.Href
+		contact.ETag = obj.ETag
+		contacts = append(contacts, *contact)
+	}
+	return contacts, nil
+}
+
+// CreateContact adds a contact to an address book
+func (c *CardDAVClient) CreateContact(ctx context.Context, addressBookID string, contact Contact) (*Contact, error) {
+	if contact.FullName == "" && contact.GivenName == "" && contact.FamilyName == "" {
+		return nil, fmt.Errorf("contact name is required")
+	}
+
+	href, err := c.addressBookHref(ctx, addressBookID)
+	if err != nil {
+		return nil, err
+	}
+
+	contact.ID = uuid.New().String()
+	if contact.FullName == "" {
+		contact.FullName = strings.TrimSpace(contact.GivenName + " " + contact.FamilyName)
+	}
+	contact.AddressBookID = href
+	contact.Href = childHref(href, contact.ID+".vcf")
+
+	etag, err := c.put(ctx, contact.Href, vcardContentType, encodeVCard(contact), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contact: %w", err)
+	}
+	contact.ETag = etag
+	return &contact, nil
+}
+
+// UpdateContact changes fields on an existing contact. Properties that
+// aren't modeled here (photos, custom fields) are preserved.
+func (c *CardDAVClient) UpdateContact(ctx context.Context, req UpdateContactRequest) (*Contact, error) {
+	obj, existing, err := c.findContact(ctx, req.ContactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing contact: %w", err)
+	}
+
+	props := make(map[string][]string)
+	setText := func(name string, value *string, encode func(string) string) {
+		if value == nil {
+			return
+		}
+		if *value == "" {
+			props[name] = nil
+			return
+		}
+		props[name] = []string{name + ":" + encode(*value)}
+	}
+	raw := func(s string) string { return s }
+
+	setText("FN", req.FullName, escapeText)
+	setText("ORG", req.Organization, escapeText)
+	setText("TITLE", req.Title, escapeText)
+	setText("BDAY", req.Birthday, raw)
+	setText("NOTE", req.Note, escapeText)
+
+	if req.GivenName != nil || req.FamilyName != nil {
+		given, family := existing.GivenName, existing.FamilyName
+		if req.GivenName != nil {
+			given = *req.GivenName
+		}
+		if req.FamilyName != nil {
+			family = *req.FamilyName
+		}
+		props["N"] = []string{"N:" + escapeText(family) + ";" + escapeText(given) + ";;;"}
+		if req.FullName == nil && existing.FullName == strings.TrimSpace(existing.GivenName+" "+existing.FamilyName) {
+			// Keep a derived display name in step with the structured name
+			props["FN"] = []string{"FN:" + escapeText(strings.TrimSpace(given+" "+family))}
+		}
+	}
+	if req.Emails != nil {
+		props["EMAIL"] = []string{}
+		for _, e := range req.Emails {
+			props["EMAIL"] = append(props["EMAIL"], "EMAIL"+typeParam(e.Type, "INTERNET")+":"+e.Value)
+		}
+	}
+	if req.Phones != nil {
+		props["TEL"] = []string{}
+		for _, p := range req.Phones {
+			props["TEL"] = append(props["TEL"], "TEL"+typeParam(p.Type, "")+":"+p.Value)
+		}
+	}
+	if len(props) == 0 {
+		return existing, nil
+	}
+
+	data := patchComponent(obj.Data, "VCARD", props)
+	etag, err := c.put(ctx, obj.Href, vcardContentType, data, obj.ETag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update contact: %w", err)
+	}
+
+	updated := parseVCard(data)
+	if updated == nil {
+		return nil, fmt.Errorf("failed to parse updated contact")
+	}
+	updated.AddressBookID = existing.AddressBookID
+	updated.Href = obj.Href
+	updated.ETag = etag
+	return updated, nil
+}
+
+// DeleteContact removes a contact
+func (c *CardDAVClient) DeleteContact(ctx context.Context, contactID string) error {
+	obj, _, err := c.findContact(ctx, contactID)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+	if err := c.remove(ctx, obj.Href, obj.ETag); err != nil {
+		return fmt.Errorf("failed to delete contact: %w", err)
+	}
+	return nil
+}
+
+func sortContacts(contacts []Contact) {
+	sort.SliceStable(contacts, func(i, j int) bool {
+		return strings.ToLower(contacts[i].FullName) < strings.ToLower(contacts[j].FullName)
+	})
+}
+
+// addressBookQuery builds an addressbook-query REPORT. An empty search term
+// matches every contact.
+func addressBookQuery(search string) string {
+	filter := `<CR:filter/>`
+	if search != "" {
+		term := xmlEscape(search)
+		filter = `<CR:filter test="anyof">`
+		for _, prop := range []string{"FN", "EMAIL", "TEL", "ORG"} {
+			filter += `<CR:prop-filter name="` + prop + `"><CR:text-match collation="i;unicode-casemap" match-type="contains">` +
+				term + `</CR:text-match></CR:prop-filter>`
+		}
+		filter += `</CR:filter>`
+	}
+	return `<?xml version="1.0" encoding="utf-8"?>` +
+		`<CR:addressbook-query xmlns:D="DAV:" xmlns:CR="urn:ietf:params:xml:ns:carddav">` +
+		`<D:prop><D:getetag/><CR:address-data/></D:prop>` + filter +
+		`</CR:addressbook-query>`
+}
+
+// addressBookUIDQuery builds an addressbook-query REPORT matching a vCard UID
+func addressBookUIDQuery(uid string) string {
+	return `<?xml version="1.0" encoding="utf-8"?>` +
+		`<CR:addressbook-query xmlns:D="DAV:" xmlns:CR="urn:ietf:params:xml:ns:carddav">` +
+		`<D:prop><D:getetag/><CR:address-data/></D:prop>` +
+		`<CR:filter><CR:prop-filter name="UID"><CR:text-match collation="i;octet" match-type="equals">` +
+		xmlEscape(uid) + `</CR:text-match></CR:prop-filter></CR:filter>` +
+		`</CR:addressbook-query>`
+}
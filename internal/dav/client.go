@@ -0,0 +1,473 @@
+// Package dav implements CalDAV (RFC 4791) and CardDAV (RFC 6352) clients.
+// It gives Fastmail, Nextcloud, iCloud and other standards-based providers
+// the same calendar and contacts capabilities as the Google integrations.
+package dav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// XML namespaces
+const (
+	nsDAV     = "DAV:"
+	nsCalDAV  = "urn:ietf:params:xml:ns:caldav"
+	nsCardDAV = "urn:ietf:params:xml:ns:carddav"
+)
+
+// ErrNotFound is returned when an event, contact or collection does not exist
+var ErrNotFound = errors.New("dav: not found")
+
+// ErrConflict is returned when a resource changed on the server since it was read
+var ErrConflict = errors.New("dav: resource was modified concurrently")
+
+// Config for a DAV client
+type Config struct {
+	// URL is the server root or a well-known URL, e.g.
+	// https://caldav.fastmail.com/ or https://cloud.example.com/remote.php/dav/
+	URL      string
+	Username string
+	Password string // app-specific password
+	Timeout  time.Duration
+
+	// DefaultCalendar and DefaultAddressBook are collection hrefs used when
+	// no collection is specified. Empty means the first one discovered.
+	DefaultCalendar    string
+	DefaultAddressBook string
+}
+
+// Client is a WebDAV transport with collection discovery, shared by the
+// CalDAV and CardDAV clients
+type Client struct {
+	base               *url.URL
+	username           string
+	password           string
+	httpClient         *http.Client
+	defaultCalendar    string
+	defaultAddressBook string
+
+	homeSets map[string]string // kind -> home set href
+	mu       sync.Mutex
+}
+
+// NewClient creates a WebDAV client
+func NewClient(cfg Config) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("DAV server URL is required")
+	}
+	base, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DAV URL: %w", err)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &Client{
+		base:     base,
+		username: cfg.Username,
+		password: cfg.Password,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+			// Redirects are followed manually so PROPFIND/REPORT keep their method
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		defaultCalendar:    cfg.DefaultCalendar,
+		defaultAddressBook: cfg.DefaultAddressBook,
+		homeSets:           make(map[string]string),
+	}, nil
+}
+
+// multistatus is a WebDAV 207 response body
+type multistatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href      string     `xml:"DAV: href"`
+	Status    string     `xml:"DAV: status"`
+	Propstats []propstat `xml:"DAV: propstat"`
+}
+
+type propstat struct {
+	Prop   davProp `xml:"DAV: prop"`
+	Status string  `xml:"DAV: status"`
+}
+
+type davProp struct {
+	DisplayName          string       `xml:"DAV: displayname"`
+	ResourceType         resourceType `xml:"DAV: resourcetype"`
+	ETag                 string       `xml:"DAV: getetag"`
+	CurrentUserPrincipal hrefProp     `xml:"DAV: current-user-principal"`
+	CalendarHomeSet      hrefProp     `xml:"urn:ietf:params:xml:ns:caldav calendar-home-set"`
+	AddressBookHomeSet   hrefProp     `xml:"urn:ietf:params:xml:ns:carddav addressbook-home-set"`
+	CalendarDescription  string       `xml:"urn:ietf:params:xml:ns:caldav calendar-description"`
+	AddressBookDesc      string       `xml:"urn:ietf:params:xml:ns:carddav addressbook-description"`
+	CalendarTimezone     string       `xml:"urn:ietf:params:xml:ns:caldav calendar-timezone"`
+	SupportedComponents  []compName   `xml:"urn:ietf:params:xml:ns:caldav supported-calendar-component-set>comp"`
+	CalendarData         string       `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+	AddressData          string       `xml:"urn:ietf:params:xml:ns:carddav address-data"`
+}
+
+type resourceType struct {
+	Collection  *struct{} `xml:"DAV: collection"`
+	Calendar    *struct{} `xml:"urn:ietf:params:xml:ns:caldav calendar"`
+	AddressBook *struct{} `xml:"urn:ietf:params:xml:ns:carddav addressbook"`
+}
+
+type hrefProp struct {
+	Href string `xml:"DAV: href"`
+}
+
+type compName struct {
+	Name string `xml:"name,attr"`
+}
+
+// okProp returns the merged properties from 200 propstats
+func (r davResponse) okProp() davProp {
+	var merged davProp
+	for _, ps := range r.Propstats {
+		if ps.Status != "" && !strings.Contains(ps.Status, " 200") {
+			continue
+		}
+		p := ps.Prop
+		if p.DisplayName != "" {
+			merged.DisplayName = p.DisplayName
+		}
+		if p.ResourceType.Calendar != nil || p.ResourceType.AddressBook != nil || p.ResourceType.Collection != nil {
+			merged.ResourceType = p.ResourceType
+		}
+		if p.ETag != "" {
+			merged.ETag = p.ETag
+		}
+		if p.CurrentUserPrincipal.Href != "" {
+			merged.CurrentUserPrincipal = p.CurrentUserPrincipal
+		}
+		if p.CalendarHomeSet.Href != "" {
+			merged.CalendarHomeSet = p.CalendarHomeSet
+		}
+		if p.AddressBookHomeSet.Href != "" {
+			merged.AddressBookHomeSet = p.AddressBookHomeSet
+		}
+		if p.CalendarDescription != "" {
+			merged.CalendarDescription = p.CalendarDescription
+		}
+		if p.AddressBookDesc != "" {
+			merged.AddressBookDesc = p.AddressBookDesc
+		}
+		if p.CalendarTimezone != "" {
+			merged.CalendarTimezone = p.CalendarTimezone
+		}
+		if len(p.SupportedComponents) > 0 {
+			merged.SupportedComponents = p.SupportedComponents
+		}
+		if p.CalendarData != "" {
+			merged.CalendarData = p.CalendarData
+		}
+		if p.AddressData != "" {
+			merged.AddressData = p.AddressData
+		}
+	}
+	return merged
+}
+
+// object is a calendar or address object resource
+type object struct {
+	Href string
+	ETag string
+	Data string
+}
+
+// resolve turns an href (absolute path or URL) into a full URL
+func (c *Client) resolve(href string) string {
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return c.base.ResolveReference(ref).String()
+}
+
+// do sends a WebDAV request, following redirects while preserving the
+// method. Credentials only follow redirects to the same host that don't
+// drop from https to http.
+func (c *Client) do(ctx context.Context, method, href string, headers map[string]string, body []byte) (*http.Response, error) {
+	target := c.resolve(href)
+
+	var origin *url.URL
+	for redirects := 0; redirects < 5; redirects++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if origin == nil {
+			origin = req.URL
+		}
+		if (c.username != "" || c.password != "") && sendsCredentials(origin, req.URL) {
+			req.SetBasicAuth(c.username, c.password)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s failed: %w", method, target, err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+			location := resp.Header.Get("Location")
+			resp.Body.Close()
+			if location == "" {
+				return nil, fmt.Errorf("%s %s: redirect without location", method, target)
+			}
+			next, err := req.URL.Parse(location)
+			if err != nil {
+				return nil, fmt.Errorf("invalid redirect location: %w", err)
+			}
+			target = next.String()
+			continue
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("%s %s: too many redirects", method, href)
+}
+
+// sendsCredentials reports whether a request to target, reached by
+// redirects from origin, may carry the user's credentials
+func sendsCredentials(origin, target *url.URL) bool {
+	if !strings.EqualFold(origin.Host, target.Host) {
+		return false
+	}
+	return origin.Scheme != "https" || target.Scheme == "https"
+}
+
+// statusError converts unexpected HTTP statuses into errors
+func statusError(method, href string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, href)
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%w: %s", ErrConflict, href)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%s %s: access denied (%d)", method, href, resp.StatusCode)
+	}
+	return fmt.Errorf("%s %s: unexpected status %d: %s", method, href, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// multi sends PROPFIND or REPORT and parses the 207 response
+func (c *Client) multi(ctx context.Context, method, href, depth, body string) (*multistatus, error) {
+	resp, err := c.do(ctx, method, href, map[string]string{
+		"Depth":        depth,
+		"Content-Type": "application/xml; charset=utf-8",
+	}, []byte(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError(method, href, resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	return &ms, nil
+}
+
+// propfind requests properties of a resource or collection
+func (c *Client) propfind(ctx context.Context, href, depth, props string) (*multistatus, error) {
+	body := `<?xml version="1.0" encoding="utf-8"?>` +
+		`<D:propfind xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav" xmlns:CR="urn:ietf:params:xml:ns:carddav">` +
+		`<D:prop>` + props + `</D:prop></D:propfind>`
+	return c.multi(ctx, "PROPFIND", href, depth, body)
+}
+
+// homeSet discovers the calendar or address book home collection (RFC 6764)
+func (c *Client) homeSet(ctx context.Context, kind string) (string, error) {
+	c.mu.Lock()
+	if home, ok := c.homeSets[kind]; ok {
+		c.mu.Unlock()
+		return home, nil
+	}
+	c.mu.Unlock()
+
+	principal, err := c.principal(ctx, c.base.Path)
+	if err != nil || principal == "" {
+		// Fall back to the well-known URL, which redirects to the DAV root
+		principal, err = c.principal(ctx, "/.well-known/"+kind)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to discover principal: %w", err)
+	}
+	if principal == "" {
+		return "", fmt.Errorf("server did not report a current-user-principal")
+	}
+
+	prop := "<C:calendar-home-set/>"
+	if kind == "carddav" {
+		prop = "<CR:addressbook-home-set/>"
+	}
+	ms, err := c.propfind(ctx, principal, "0", prop)
+	if err != nil {
+		return "", fmt.Errorf("failed to read home set: %w", err)
+	}
+
+	var home string
+	for _, r := range ms.Responses {
+		p := r.okProp()
+		if kind == "carddav" {
+			home = p.AddressBookHomeSet.Href
+		} else {
+			home = p.CalendarHomeSet.Href
+		}
+		if home != "" {
+			break
+		}
+	}
+	if home == "" {
+		return "", fmt.Errorf("server did not report a %s home set", kind)
+	}
+
+	c.mu.Lock()
+	c.homeSets[kind] = home
+	c.mu.Unlock()
+	return home, nil
+}
+
+func (c *Client) principal(ctx context.Context, href string) (string, error) {
+	ms, err := c.propfind(ctx, href, "0", "<D:current-user-principal/>")
+	if err != nil {
+		return "", err
+	}
+	for _, r := range ms.Responses {
+		if p := r.okProp().CurrentUserPrincipal.Href; p != "" {
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+// collections lists calendar or address book collections in a home set
+func (c *Client) collections(ctx context.Context, kind string) ([]davResponse, error) {
+	home, err := c.homeSet(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	props := "<D:displayname/><D:resourcetype/>"
+	if kind == "carddav" {
+		props += "<CR:addressbook-description/>"
+	} else {
+		props += "<C:calendar-description/><C:calendar-timezone/><C:supported-calendar-component-set/>"
+	}
+	ms, err := c.propfind(ctx, home, "1", props)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	var result []davResponse
+	for _, r := range ms.Responses {
+		rt := r.okProp().ResourceType
+		if (kind == "carddav" && rt.AddressBook != nil) || (kind == "caldav" && rt.Calendar != nil) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// report runs a REPORT query and returns the matching objects
+func (c *Client) report(ctx context.Context, href, body string) ([]object, error) {
+	ms, err := c.multi(ctx, "REPORT", href, "1", body)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]object, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		p := r.okProp()
+		data := p.CalendarData
+		if data == "" {
+			data = p.AddressData
+		}
+		if data == "" {
+			continue
+		}
+		objects = append(objects, object{Href: r.Href, ETag: p.ETag, Data: data})
+	}
+	return objects, nil
+}
+
+// put writes an object. An empty etag creates the object and fails if it
+// already exists; otherwise the write only succeeds if the etag still matches.
+func (c *Client) put(ctx context.Context, href, contentType, data, etag string) (string, error) {
+	headers := map[string]string{"Content-Type": contentType}
+	if etag == "" {
+		headers["If-None-Match"] = "*"
+	} else {
+		headers["If-Match"] = etag
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, href, headers, []byte(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return "", statusError("PUT", href, resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// remove deletes an object, optionally guarded by its etag
+func (c *Client) remove(ctx context.Context, href, etag string) error {
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+
+	resp, err := c.do(ctx, http.MethodDelete, href, headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return statusError("DELETE", href, resp)
+	}
+	return nil
+}
+
+// childHref builds the href of a new object inside a collection
+func childHref(collection, name string) string {
+	if !strings.HasSuffix(collection, "/") {
+		collection += "/"
+	}
+	return collection + url.PathEscape(name)
+}
+
+// xmlEscape escapes text for inclusion in an XML request body
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,519 @@
+package dav
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	calclient "github.com/quantumlife/quantumlife/internal/spaces/calendar"
+	"github.com/quantumlife/quantumlife/internal/testutil/mockservers"
+)
+
+func newTestClients(t *testing.T) (*CalDAVClient, *CardDAVClient, *mockservers.DAVMockServer) {
+	t.Helper()
+
+	mock := mockservers.NewDAVMockServer(t)
+	cfg := Config{URL: mock.Server.URL, Username: mock.Username, Password: mock.Password}
+
+	cal, err := NewCalDAVClient(cfg)
+	if err != nil {
+		t.Fatalf("NewCalDAVClient failed: %v", err)
+	}
+	cal.now = func() time.Time { return time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC) }
+
+	card, err := NewCardDAVClient(cfg)
+	if err != nil {
+		t.Fatalf("NewCardDAVClient failed: %v", err)
+	}
+	return cal, card, mock
+}
+
+func TestNewClient_RequiresURL(t *testing.T) {
+	if _, err := NewClient(Config{}); err == nil {
+		t.Error("expected error for missing URL")
+	}
+}
+
+func TestEncodeEvent_RoundTrip(t *testing.T) {
+	start := time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC)
+	event := calclient.Event{
+		ID:          "evt-1",
+		Summary:     "Review; budget, Q1",
+		Description: "Line one\nLine two",
+		Location:    "HQ",
+		Start:       start,
+		End:         start.Add(90 * time.Minute),
+		Status:      "tentative",
+		Attendees:   []calclient.Attendee{{Email: "bob@example.com", DisplayName: "Bob", ResponseStatus: "accepted"}},
+		Reminders:   []calclient.Reminder{{Method: "popup", Minutes: 15}},
+		Metadata:    map[string]string{"rrule": "FREQ=WEEKLY;BYDAY=FR"},
+	}
+
+	events := parseEvents(encodeEvent(event), "cal")
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	got := events[0]
+
+	if got.ID != event.ID || got.Summary != event.Summary || got.Description != event.Description {
+		t.Errorf("text fields not preserved: %+v", got)
+	}
+	if !got.Start.Equal(event.Start) || !got.End.Equal(event.End) {
+		t.Errorf("times not preserved: %v - %v", got.Start, got.End)
+	}
+	if got.Status != "tentative" || got.CalendarID != "cal" {
+		t.Errorf("unexpected status/calendar: %s %s", got.Status, got.CalendarID)
+	}
+	if len(got.Attendees) != 1 || got.Attendees[0].Email != "bob@example.com" || got.Attendees[0].ResponseStatus != "accepted" {
+		t.Errorf("attendees not preserved: %+v", got.Attendees)
+	}
+	if len(got.Reminders) != 1 || got.Reminders[0].Minutes != 15 {
+		t.Errorf("reminders not preserved: %+v", got.Reminders)
+	}
+	if got.Metadata["rrule"] != "FREQ=WEEKLY;BYDAY=FR" {
+		t.Errorf("rrule not preserved: %q", got.Metadata["rrule"])
+	}
+}
+
+func TestParseEvents_AllDayAndDuration(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nDTSTART;VALUE=DATE:20240301\r\nSUMMARY:Holiday\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:b\r\nDTSTART;TZID=America/New_York:20240301T090000\r\nDURATION:PT45M\r\nSUMMARY:Long summary that is\r\n  folded\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	events := parseEvents(data, "cal")
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if !events[0].AllDay || events[0].End.Sub(events[0].Start) != 24*time.Hour {
+		t.Errorf("expected all-day event lasting one day, got %+v", events[0])
+	}
+	if events[1].End.Sub(events[1].Start) != 45*time.Minute {
+		t.Errorf("expected 45 minute duration, got %v", events[1].End.Sub(events[1].Start))
+	}
+	if events[1].Summary != "Long summary that is folded" {
+		t.Errorf("expected unfolded summary, got %q", events[1].Summary)
+	}
+}
+
+func TestEncodeVCard_RoundTrip(t *testing.T) {
+	contact := Contact{
+		ID:           "c-1",
+		GivenName:    "Carol",
+		FamilyName:   "Jones",
+		Emails:       []ContactField{{Type: "work", Value: "carol@example.com"}},
+		Phones:       []ContactField{{Type: "cell", Value: "+1 555 0199"}},
+		Organization: "Acme, Inc.",
+		Note:         "Met at conference",
+	}
+
+	got := parseVCard(encodeVCard(contact))
+	if got == nil {
+		t.Fatal("expected contact")
+	}
+	if got.FullName != "Carol Jones" || got.GivenName != "Carol" || got.FamilyName != "Jones" {
+		t.Errorf("names not preserved: %+v", got)
+	}
+	if got.PrimaryEmail() != "carol@example.com" || got.Emails[0].Type != "work" {
+		t.Errorf("emails not preserved: %+v", got.Emails)
+	}
+	if len(got.Phones) != 1 || got.Phones[0].Type != "cell" {
+		t.Errorf("phones not preserved: %+v", got.Phones)
+	}
+	if got.Organization != "Acme, Inc." {
+		t.Errorf("expected escaped organization to round-trip, got %q", got.Organization)
+	}
+}
+
+func TestPatchComponent_PreservesUnknownProperties(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Old\r\nX-CUSTOM:keep\r\n" +
+		"BEGIN:VALARM\r\nDESCRIPTION:alarm\r\nEND:VALARM\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	patched := patchComponent(data, "VEVENT", map[string][]string{
+		"SUMMARY":     {"SUMMARY:New"},
+		"DESCRIPTION": {"DESCRIPTION:added"},
+		"LOCATION":    nil,
+	})
+
+	for _, want := range []string{"SUMMARY:New", "X-CUSTOM:keep", "DESCRIPTION:alarm", "DESCRIPTION:added"} {
+		if !strings.Contains(patched, want) {
+			t.Errorf("expected %q in patched data:\n%s", want, patched)
+		}
+	}
+	if strings.Contains(patched, "SUMMARY:Old") {
+		t.Error("expected old summary to be replaced")
+	}
+	// The alarm's DESCRIPTION is a sub-component property and must be untouched
+	if strings.Count(patched, "DESCRIPTION:") != 2 {
+		t.Errorf("expected 2 DESCRIPTION lines, got:\n%s", patched)
+	}
+}
+
+func TestParseQuickAdd(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 20, 0, 0, time.UTC)
+
+	tests := []struct {
+		text     string
+		summary  string
+		start    time.Time
+		duration time.Duration
+	}{
+		{"Dentist tomorrow at 3pm", "Dentist", time.Date(2024, 1, 16, 15, 0, 0, 0, time.UTC), time.Hour},
+		{"Lunch with Sam at 12:30 for 45 minutes", "Lunch with Sam", time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC), 45 * time.Minute},
+		{"Call mom", "Call mom", time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), time.Hour},
+		{"Breakfast at 8am", "Breakfast", time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC), time.Hour},
+		{"Workshop today for 2 hours", "Workshop", time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), 2 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			summary, start, end := parseQuickAdd(tt.text, now)
+			if summary != tt.summary {
+				t.Errorf("summary = %q, want %q", summary, tt.summary)
+			}
+			if !start.Equal(tt.start) {
+				t.Errorf("start = %v, want %v", start, tt.start)
+			}
+			if end.Sub(start) != tt.duration {
+				t.Errorf("duration = %v, want %v", end.Sub(start), tt.duration)
+			}
+		})
+	}
+}
+
+func TestCalDAVClient_ListCalendars(t *testing.T) {
+	cal, _, _ := newTestClients(t)
+
+	calendars, err := cal.ListCalendars(context.Background())
+	if err != nil {
+		t.Fatalf("ListCalendars failed: %v", err)
+	}
+	// The task-only list is skipped
+	if len(calendars) != 1 {
+		t.Fatalf("expected 1 calendar, got %d: %+v", len(calendars), calendars)
+	}
+	if calendars[0].ID != mockservers.DAVDefaultCalendar || calendars[0].Summary != "Personal" || !calendars[0].Primary {
+		t.Errorf("unexpected calendar: %+v", calendars[0])
+	}
+}
+
+func TestCalDAVClient_GetEvents(t *testing.T) {
+	cal, _, _ := newTestClients(t)
+	ctx := context.Background()
+
+	events, err := cal.GetTodayEvents(ctx)
+	if err != nil {
+		t.Fatalf("GetTodayEvents failed: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.ID != "standup-001" || e.Summary != "Team Standup" || e.Location != "Room 4" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if len(e.Attendees) != 1 || e.Attendees[0].DisplayName != "Alice Example" {
+		t.Errorf("unexpected attendees: %+v", e.Attendees)
+	}
+	if e.Metadata["etag"] == "" || e.Metadata["href"] == "" {
+		t.Errorf("expected href and etag metadata, got %v", e.Metadata)
+	}
+
+	// Outside the range
+	events, err = cal.GetEvents(ctx, "primary", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("expected no events, got %d", len(events))
+	}
+}
+
+func TestCalDAVClient_CreateUpdateDelete(t *testing.T) {
+	cal, _, mock := newTestClients(t)
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+	created, err := cal.CreateEvent(ctx, calclient.CreateEventRequest{
+		Summary:   "Planning",
+		Start:     start,
+		End:       start.Add(time.Hour),
+		Attendees: []string{"bob@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if mock.ObjectCount(mockservers.DAVDefaultCalendar) != 2 {
+		t.Fatalf("expected event to be stored")
+	}
+
+	fetched, err := cal.GetEvent(ctx, "", created.ID)
+	if err != nil {
+		t.Fatalf("GetEvent failed: %v", err)
+	}
+	if fetched.Summary != "Planning" || len(fetched.Attendees) != 1 {
+		t.Errorf("unexpected fetched event: %+v", fetched)
+	}
+
+	newStart := start.Add(2 * time.Hour)
+	summary := "Planning (moved)"
+	updated, err := cal.UpdateEvent(ctx, calclient.UpdateEventRequest{
+		EventID: created.ID,
+		Summary: &summary,
+		Start:   &newStart,
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+	if updated.Summary != summary || !updated.Start.Equal(newStart) || updated.End.Sub(updated.Start) != time.Hour {
+		t.Errorf("unexpected updated event: %+v", updated)
+	}
+	if updated.Metadata["sequence"] != "1" {
+		t.Errorf("expected sequence to be bumped, got %q", updated.Metadata["sequence"])
+	}
+
+	if err := cal.DeleteEvent(ctx, "primary", created.ID); err != nil {
+		t.Fatalf("DeleteEvent failed: %v", err)
+	}
+	if _, err := cal.GetEvent(ctx, "primary", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestCalDAVClient_UpdatePreservesUnknownProperties(t *testing.T) {
+	cal, _, mock := newTestClients(t)
+
+	location := "Room 7"
+	if _, err := cal.UpdateEvent(context.Background(), calclient.UpdateEventRequest{
+		EventID:  "standup-001",
+		Location: &location,
+	}); err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+
+	data, _ := mock.Object(mockservers.DAVDefaultCalendar + "standup.ics")
+	if !strings.Contains(data, "X-MOCK-CUSTOM:keep me") || !strings.Contains(data, "LOCATION:Room 7") {
+		t.Errorf("expected custom property preserved and location updated:\n%s", data)
+	}
+}
+
+func TestCalDAVClient_UpdateConflict(t *testing.T) {
+	cal, _, mock := newTestClients(t)
+	ctx := context.Background()
+
+	obj, _, err := cal.findEvent(ctx, mockservers.DAVDefaultCalendar, "standup-001")
+	if err != nil {
+		t.Fatalf("findEvent failed: %v", err)
+	}
+
+	// Another client modifies the event
+	mock.PutObject(obj.Href, strings.Replace(obj.Data, "Team Standup", "Changed elsewhere", 1))
+
+	_, err = cal.put(ctx, obj.Href, calendarContentType, obj.Data, obj.ETag)
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestCalDAVClient_FindFreeTime(t *testing.T) {
+	cal, _, _ := newTestClients(t)
+
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	slots, err := cal.FindFreeTime(context.Background(), start, start.Add(3*time.Hour), 30)
+	if err != nil {
+		t.Fatalf("FindFreeTime failed: %v", err)
+	}
+	// Standup is 10:00-10:30
+	if len(slots) != 2 {
+		t.Fatalf("expected 2 slots, got %+v", slots)
+	}
+	if slots[0].Duration != time.Hour || slots[1].Duration != 90*time.Minute {
+		t.Errorf("unexpected slots: %+v", slots)
+	}
+}
+
+func TestCalDAVClient_QuickAdd(t *testing.T) {
+	cal, _, _ := newTestClients(t)
+
+	event, err := cal.QuickAdd(context.Background(), "primary", "Coffee with Dana tomorrow at 9am")
+	if err != nil {
+		t.Fatalf("QuickAdd failed: %v", err)
+	}
+	if event.Summary != "Coffee with Dana" || !event.Start.Equal(time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected event: %+v", event)
+	}
+}
+
+func TestClient_AuthFailure(t *testing.T) {
+	mock := mockservers.NewDAVMockServer(t)
+	cal, err := NewCalDAVClient(Config{URL: mock.Server.URL, Username: "user", Password: "wrong"})
+	if err != nil {
+		t.Fatalf("NewCalDAVClient failed: %v", err)
+	}
+
+	if _, err := cal.ListCalendars(context.Background()); err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Errorf("expected access denied error, got %v", err)
+	}
+}
+
+func TestClient_WellKnownDiscovery(t *testing.T) {
+	mock := mockservers.NewDAVMockServer(t)
+	cal, err := NewCalDAVClient(Config{URL: mock.Server.URL + "/.well-known/caldav", Username: mock.Username, Password: mock.Password})
+	if err != nil {
+		t.Fatalf("NewCalDAVClient failed: %v", err)
+	}
+
+	calendars, err := cal.ListCalendars(context.Background())
+	if err != nil {
+		t.Fatalf("ListCalendars failed: %v", err)
+	}
+	if len(calendars) != 1 {
+		t.Errorf("expected 1 calendar, got %d", len(calendars))
+	}
+}
+
+func TestClient_RedirectCredentials(t *testing.T) {
+	var elsewhereAuth string
+	elsewhere := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		elsewhereAuth = r.Header.Get("Authorization")
+	}))
+	defer elsewhere.Close()
+
+	var movedAuth string
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/moved", http.StatusTemporaryRedirect)
+		case "/moved":
+			movedAuth = r.Header.Get("Authorization")
+		case "/other":
+			http.Redirect(w, r, elsewhere.URL+"/dav/", http.StatusTemporaryRedirect)
+		}
+	}))
+	defer origin.Close()
+
+	client, err := NewClient(Config{URL: origin.URL, Username: "user", Password: "secret"})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	for _, href := range []string{"/same", "/other"} {
+		resp, err := client.do(context.Background(), "PROPFIND", href, nil, nil)
+		if err != nil {
+			t.Fatalf("PROPFIND %s failed: %v", href, err)
+		}
+		resp.Body.Close()
+	}
+	if movedAuth == "" {
+		t.Error("credentials were dropped on a redirect within the server")
+	}
+	if elsewhereAuth != "" {
+		t.Errorf("credentials were sent to another host: %q", elsewhereAuth)
+	}
+
+	for _, tt := range []struct {
+		origin, target string
+		want           bool
+	}{
+		{"https://dav.example.com/", "https://dav.example.com/cal/", true},
+		{"http://dav.example.com/", "https://dav.example.com/cal/", true},
+		{"https://dav.example.com/", "http://dav.example.com/cal/", false},
+		{"https://dav.example.com/", "https://evil.example.com/cal/", false},
+	} {
+		origin, _ := url.Parse(tt.origin)
+		target, _ := url.Parse(tt.target)
+		if got := sendsCredentials(origin, target); got != tt.want {
+			t.Errorf("sendsCredentials(%s, %s) = %v, want %v", tt.origin, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestCardDAVClient_ListAndSearch(t *testing.T) {
+	_, card, _ := newTestClients(t)
+	ctx := context.Background()
+
+	books, err := card.ListAddressBooks(ctx)
+	if err != nil {
+		t.Fatalf("ListAddressBooks failed: %v", err)
+	}
+	if len(books) != 1 || books[0].ID != mockservers.DAVAddressBook || !books[0].Default {
+		t.Fatalf("unexpected address books: %+v", books)
+	}
+
+	contacts, err := card.ListContacts(ctx, "")
+	if err != nil {
+		t.Fatalf("ListContacts failed: %v", err)
+	}
+	if len(contacts) != 1 || contacts[0].FullName != "Alice Example" {
+		t.Fatalf("unexpected contacts: %+v", contacts)
+	}
+
+	matches, err := card.SearchContacts(ctx, "example corp", 10)
+	if err != nil {
+		t.Fatalf("SearchContacts failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Errorf("expected 1 match, got %d", len(matches))
+	}
+
+	matches, err = card.SearchContacts(ctx, "nobody", 10)
+	if err != nil {
+		t.Fatalf("SearchContacts failed: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("expected no matches, got %d", len(matches))
+	}
+}
+
+func TestCardDAVClient_CreateUpdateDelete(t *testing.T) {
+	_, card, mock := newTestClients(t)
+	ctx := context.Background()
+
+	created, err := card.CreateContact(ctx, "", Contact{
+		GivenName:  "Dana",
+		FamilyName: "Lee",
+		Emails:     []ContactField{{Value: "dana@example.com"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateContact failed: %v", err)
+	}
+	if created.FullName != "Dana Lee" || created.ID == "" {
+		t.Errorf("unexpected created contact: %+v", created)
+	}
+
+	family := "Lee-Park"
+	updated, err := card.UpdateContact(ctx, UpdateContactRequest{
+		ContactID:  created.ID,
+		FamilyName: &family,
+		Phones:     []ContactField{{Type: "home", Value: "+1 555 0123"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateContact failed: %v", err)
+	}
+	if updated.FullName != "Dana Lee-Park" || len(updated.Phones) != 1 || updated.PrimaryEmail() != "dana@example.com" {
+		t.Errorf("unexpected updated contact: %+v", updated)
+	}
+
+	if err := card.DeleteContact(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteContact failed: %v", err)
+	}
+	if mock.ObjectCount(mockservers.DAVAddressBook) != 1 {
+		t.Errorf("expected contact to be removed")
+	}
+}
+
+func TestCardDAVClient_UpdatePreservesPhoto(t *testing.T) {
+	_, card, mock := newTestClients(t)
+
+	title := "CTO"
+	if _, err := card.UpdateContact(context.Background(), UpdateContactRequest{ContactID: "alice-001", Title: &title}); err != nil {
+		t.Fatalf("UpdateContact failed: %v", err)
+	}
+
+	data, _ := mock.Object(mockservers.DAVAddressBook + "alice.vcf")
+	if !strings.Contains(data, "PHOTO;VALUE=URI:https://example.com/alice.jpg") || !strings.Contains(data, "TITLE:CTO") {
+		t.Errorf("expected photo preserved and title added:\n%s", data)
+	}
+}
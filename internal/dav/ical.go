@@ -0,0 +1,473 @@
+package dav
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	calclient "github.com/quantumlife/quantumlife/internal/spaces/calendar"
+)
+
+// contentLine is one unfolded iCalendar/vCard property line
+type contentLine struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// param returns a parameter value (case-insensitive name)
+func (l contentLine) param(name string) string {
+	return l.Params[strings.ToUpper(name)]
+}
+
+// parseContentLines unfolds and splits RFC 5545 / RFC 6350 content lines
+func parseContentLines(data string) []contentLine {
+	unfolded := unfoldLines(data)
+
+	lines := make([]contentLine, 0, len(unfolded))
+	for _, raw := range unfolded {
+		colon := valueSeparator(raw)
+		if colon < 0 {
+			continue
+		}
+		head, value := raw[:colon], raw[colon+1:]
+
+		parts := splitParams(head)
+		name := propertyName(parts[0])
+
+		line := contentLine{Name: name, Params: make(map[string]string), Value: value}
+		for _, p := range parts[1:] {
+			k, v, ok := strings.Cut(p, "=")
+			if !ok {
+				// vCard 2.1 style bare type ("EMAIL;WORK:")
+				line.Params["TYPE"] = appendParam(line.Params["TYPE"], p)
+				continue
+			}
+			line.Params[strings.ToUpper(k)] = appendParam(line.Params[strings.ToUpper(k)], strings.Trim(v, `"`))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// unfoldLines splits data into logical lines. A line starting with space or
+// tab continues the previous line.
+func unfoldLines(data string) []string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+
+	var unfolded []string
+	for _, raw := range strings.Split(data, "\n") {
+		if raw == "" {
+			continue
+		}
+		if (raw[0] == ' ' || raw[0] == '\t') && len(unfolded) > 0 {
+			unfolded[len(unfolded)-1] += raw[1:]
+			continue
+		}
+		unfolded = append(unfolded, raw)
+	}
+	return unfolded
+}
+
+// propertyName normalizes a property name. vCard property groups
+// ("item1.EMAIL") are ignored.
+func propertyName(name string) string {
+	name = strings.ToUpper(name)
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return name
+}
+
+// patchComponent rewrites properties of the first component of the given
+// kind (VEVENT, VCARD) and keeps every other line, including properties and
+// sub-components this package doesn't model. Each entry in props replaces all
+// lines of that property with the given content lines; an empty slice removes
+// the property. Properties not present are added at the end of the component.
+func patchComponent(data, component string, props map[string][]string) string {
+	var b strings.Builder
+	written := make(map[string]bool)
+	inside, done := false, false
+	nested := 0
+
+	for _, raw := range unfoldLines(data) {
+		colon := valueSeparator(raw)
+		if colon < 0 || done {
+			foldLine(&b, raw)
+			continue
+		}
+		name := propertyName(splitParams(raw[:colon])[0])
+		value := strings.ToUpper(raw[colon+1:])
+
+		switch {
+		case !inside:
+			if name == "BEGIN" && value == component {
+				inside = true
+			}
+		case name == "BEGIN":
+			nested++
+		case name == "END" && nested > 0:
+			nested--
+		case name == "END" && value == component:
+			// Add properties the component didn't have
+			for _, prop := range sortedKeys(props) {
+				if !written[prop] {
+					for _, line := range props[prop] {
+						foldLine(&b, line)
+					}
+				}
+			}
+			inside, done = false, true
+		case nested == 0:
+			if replacement, ok := props[name]; ok {
+				if !written[name] {
+					for _, line := range replacement {
+						foldLine(&b, line)
+					}
+					written[name] = true
+				}
+				continue
+			}
+		}
+		foldLine(&b, raw)
+	}
+	return b.String()
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// valueSeparator finds the colon separating name/params from the value,
+// skipping colons inside quoted parameter values
+func valueSeparator(line string) int {
+	inQuotes := false
+	for i, r := range line {
+		switch r {
+		case '"':
+			inQuotes = !inQuotes
+		case ':':
+			if !inQuotes {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitParams splits "NAME;A=1;B=\"x;y\"" on semicolons outside quotes
+func splitParams(head string) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, r := range head {
+		switch r {
+		case '"':
+			inQuotes = !inQuotes
+		case ';':
+			if !inQuotes {
+				parts = append(parts, head[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, head[start:])
+}
+
+func appendParam(existing, value string) string {
+	if existing == "" {
+		return value
+	}
+	return existing + "," + value
+}
+
+// unescapeText reverses RFC 5545 TEXT escaping
+func unescapeText(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return r.Replace(s)
+}
+
+// escapeText applies RFC 5545 TEXT escaping
+func escapeText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "\n", `\n`, ",", `\,`, ";", `\;`)
+	return r.Replace(s)
+}
+
+// foldLine writes a content line folded at 75 octets
+func foldLine(b *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		// Don't split a UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// parseICalTime parses DATE and DATE-TIME values, honoring TZID and VALUE=DATE
+func parseICalTime(l contentLine) (t time.Time, allDay bool, err error) {
+	value := l.Value
+	if l.param("VALUE") == "DATE" || len(value) == 8 {
+		t, err = time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+
+	loc := time.Local
+	if tzid := l.param("TZID"); tzid != "" {
+		if tz, tzErr := time.LoadLocation(tzid); tzErr == nil {
+			loc = tz
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseICalDuration parses RFC 5545 durations like "PT1H30M" or "-PT15M"
+func parseICalDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// formatICalDuration formats a duration as an RFC 5545 duration
+func formatICalDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	return fmt.Sprintf("%sPT%dM", sign, int(d.Minutes()))
+}
+
+// parseEvents extracts VEVENTs from an iCalendar object. Recurrence
+// overrides (RECURRENCE-ID) are skipped unless the server expanded them.
+func parseEvents(data, calendarID string) []calclient.Event {
+	var events []calclient.Event
+	var current *calclient.Event
+	var duration time.Duration
+	var hasEnd bool
+	inAlarm := false
+	var alarm calclient.Reminder
+
+	for _, l := range parseContentLines(data) {
+		switch {
+		case l.Name == "BEGIN" && strings.EqualFold(l.Value, "VEVENT"):
+			current = &calclient.Event{CalendarID: calendarID, Status: "confirmed", Metadata: make(map[string]string)}
+			duration, hasEnd = 0, false
+			continue
+		case l.Name == "END" && strings.EqualFold(l.Value, "VEVENT") && current != nil:
+			if !hasEnd {
+				switch {
+				case duration > 0:
+					current.End = current.Start.Add(duration)
+				case current.AllDay:
+					current.End = current.Start.AddDate(0, 0, 1)
+				default:
+					current.End = current.Start
+				}
+			}
+			events = append(events, *current)
+			current = nil
+			continue
+		case current == nil:
+			continue
+		case l.Name == "BEGIN" && strings.EqualFold(l.Value, "VALARM"):
+			inAlarm = true
+			alarm = calclient.Reminder{Method: "popup"}
+			continue
+		case l.Name == "END" && strings.EqualFold(l.Value, "VALARM"):
+			inAlarm = false
+			if alarm.Minutes > 0 {
+				current.Reminders = append(current.Reminders, alarm)
+			}
+			continue
+		}
+
+		if inAlarm {
+			switch l.Name {
+			case "ACTION":
+				if strings.EqualFold(l.Value, "EMAIL") {
+					alarm.Method = "email"
+				}
+			case "TRIGGER":
+				if d, err := parseICalDuration(l.Value); err == nil && d <= 0 {
+					alarm.Minutes = int(-d.Minutes())
+				}
+			}
+			continue
+		}
+
+		switch l.Name {
+		case "UID":
+			current.ID = l.Value
+		case "SUMMARY":
+			current.Summary = unescapeText(l.Value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(l.Value)
+		case "LOCATION":
+			current.Location = unescapeText(l.Value)
+		case "STATUS":
+			current.Status = strings.ToLower(l.Value)
+		case "URL":
+			current.Link = l.Value
+		case "DTSTART":
+			current.Start, current.AllDay, _ = parseICalTime(l)
+		case "DTEND":
+			current.End, _, _ = parseICalTime(l)
+			hasEnd = true
+		case "DURATION":
+			duration, _ = parseICalDuration(l.Value)
+		case "CREATED":
+			current.Created, _, _ = parseICalTime(l)
+		case "LAST-MODIFIED", "DTSTAMP":
+			if t, _, err := parseICalTime(l); err == nil && (l.Name == "LAST-MODIFIED" || current.Updated.IsZero()) {
+				current.Updated = t
+			}
+		case "ORGANIZER":
+			current.Organizer = mailtoAddress(l.Value)
+		case "ATTENDEE":
+			current.Attendees = append(current.Attendees, calclient.Attendee{
+				Email:          mailtoAddress(l.Value),
+				DisplayName:    l.param("CN"),
+				ResponseStatus: partstatToResponse(l.param("PARTSTAT")),
+				Organizer:      l.param("ROLE") == "CHAIR",
+			})
+		case "RRULE":
+			current.Metadata["rrule"] = l.Value
+		case "RECURRENCE-ID":
+			current.Metadata["recurrence_id"] = l.Value
+		case "SEQUENCE":
+			current.Metadata["sequence"] = l.Value
+		}
+	}
+
+	return events
+}
+
+func mailtoAddress(v string) string {
+	if len(v) > 7 && strings.EqualFold(v[:7], "mailto:") {
+		return v[7:]
+	}
+	return v
+}
+
+// partstatToResponse maps iCalendar PARTSTAT to Google-style response status
+func partstatToResponse(partstat string) string {
+	switch strings.ToUpper(partstat) {
+	case "ACCEPTED":
+		return "accepted"
+	case "DECLINED":
+		return "declined"
+	case "TENTATIVE":
+		return "tentative"
+	default:
+		return "needsAction"
+	}
+}
+
+func formatICalDate(t time.Time, allDay bool) string {
+	if allDay {
+		return ";VALUE=DATE:" + t.Format("20060102")
+	}
+	return ":" + t.UTC().Format("20060102T150405Z")
+}
+
+// encodeEvent serializes an event as a VCALENDAR object
+func encodeEvent(e calclient.Event) string {
+	var b strings.Builder
+	foldLine(&b, "BEGIN:VCALENDAR")
+	foldLine(&b, "VERSION:2.0")
+	foldLine(&b, "PRODID:-//QuantumLife//CalDAV//EN")
+	foldLine(&b, "BEGIN:VEVENT")
+	foldLine(&b, "UID:"+e.ID)
+	foldLine(&b, "DTSTAMP:"+time.Now().UTC().Format("20060102T150405Z"))
+	foldLine(&b, "DTSTART"+formatICalDate(e.Start, e.AllDay))
+	if !e.End.IsZero() {
+		foldLine(&b, "DTEND"+formatICalDate(e.End, e.AllDay))
+	}
+	foldLine(&b, "SUMMARY:"+escapeText(e.Summary))
+	if e.Description != "" {
+		foldLine(&b, "DESCRIPTION:"+escapeText(e.Description))
+	}
+	if e.Location != "" {
+		foldLine(&b, "LOCATION:"+escapeText(e.Location))
+	}
+	if e.Status != "" {
+		foldLine(&b, "STATUS:"+strings.ToUpper(e.Status))
+	}
+	if rrule := e.Metadata["rrule"]; rrule != "" {
+		foldLine(&b, "RRULE:"+rrule)
+	}
+	if e.Organizer != "" {
+		foldLine(&b, "ORGANIZER:mailto:"+e.Organizer)
+	}
+	for _, a := range e.Attendees {
+		line := "ATTENDEE"
+		if a.DisplayName != "" {
+			line += `;CN="` + a.DisplayName + `"`
+		}
+		line += ";PARTSTAT=" + responseToPartstat(a.ResponseStatus)
+		foldLine(&b, line+":mailto:"+a.Email)
+	}
+	for _, r := range e.Reminders {
+		foldLine(&b, "BEGIN:VALARM")
+		if r.Method == "email" {
+			foldLine(&b, "ACTION:EMAIL")
+		} else {
+			foldLine(&b, "ACTION:DISPLAY")
+		}
+		foldLine(&b, "DESCRIPTION:"+escapeText(e.Summary))
+		foldLine(&b, "TRIGGER:"+formatICalDuration(-time.Duration(r.Minutes)*time.Minute))
+		foldLine(&b, "END:VALARM")
+	}
+	foldLine(&b, "END:VEVENT")
+	foldLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func responseToPartstat(status string) string {
+	switch status {
+	case "accepted":
+		return "ACCEPTED"
+	case "declined":
+		return "DECLINED"
+	case "tentative":
+		return "TENTATIVE"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
@@ -0,0 +1,182 @@
+package dav
+
+import (
+	"strings"
+)
+
+// Contact is a provider-neutral address book entry
+type Contact struct {
+	ID            string         `json:"id"` // vCard UID
+	AddressBookID string         `json:"address_book_id"`
+	FullName      string         `json:"full_name"`
+	GivenName     string         `json:"given_name,omitempty"`
+	FamilyName    string         `json:"family_name,omitempty"`
+	Emails        []ContactField `json:"emails,omitempty"`
+	Phones        []ContactField `json:"phones,omitempty"`
+	Organization  string         `json:"organization,omitempty"`
+	Title         string         `json:"title,omitempty"`
+	Birthday      string         `json:"birthday,omitempty"` // as written in the vCard (e.g. 1985-04-12)
+	Address       string         `json:"address,omitempty"`
+	Note          string         `json:"note,omitempty"`
+	ETag          string         `json:"-"`
+	Href          string         `json:"-"`
+}
+
+// ContactField is a typed value such as a work email or mobile phone
+type ContactField struct {
+	Type  string `json:"type,omitempty"` // home, work, cell, ...
+	Value string `json:"value"`
+}
+
+// PrimaryEmail returns the first email address, if any
+func (c *Contact) PrimaryEmail() string {
+	if len(c.Emails) == 0 {
+		return ""
+	}
+	return c.Emails[0].Value
+}
+
+// Matches reports whether the contact matches a case-insensitive search term
+// on name, email, phone or organization
+func (c *Contact) Matches(query string) bool {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return true
+	}
+
+	fields := []string{c.FullName, c.GivenName, c.FamilyName, c.Organization}
+	for _, e := range c.Emails {
+		fields = append(fields, e.Value)
+	}
+	for _, p := range c.Phones {
+		fields = append(fields, p.Value)
+	}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVCard parses a single vCard (3.0 or 4.0)
+func parseVCard(data string) *Contact {
+	var c *Contact
+	for _, l := range parseContentLines(data) {
+		switch l.Name {
+		case "BEGIN":
+			if strings.EqualFold(l.Value, "VCARD") {
+				c = &Contact{}
+			}
+			continue
+		case "END":
+			if strings.EqualFold(l.Value, "VCARD") {
+				return c
+			}
+			continue
+		}
+		if c == nil {
+			continue
+		}
+
+		switch l.Name {
+		case "UID":
+			c.ID = strings.TrimPrefix(l.Value, "urn:uuid:")
+		case "FN":
+			c.FullName = unescapeText(l.Value)
+		case "N":
+			parts := strings.Split(l.Value, ";")
+			c.FamilyName = unescapeText(parts[0])
+			if len(parts) > 1 {
+				c.GivenName = unescapeText(parts[1])
+			}
+		case "EMAIL":
+			c.Emails = append(c.Emails, ContactField{Type: fieldType(l), Value: l.Value})
+		case "TEL":
+			c.Phones = append(c.Phones, ContactField{Type: fieldType(l), Value: strings.TrimPrefix(l.Value, "tel:")})
+		case "ORG":
+			c.Organization = unescapeText(strings.Split(l.Value, ";")[0])
+		case "TITLE":
+			c.Title = unescapeText(l.Value)
+		case "BDAY":
+			c.Birthday = l.Value
+		case "ADR":
+			var parts []string
+			for _, p := range strings.Split(l.Value, ";") {
+				if p = strings.TrimSpace(unescapeText(p)); p != "" {
+					parts = append(parts, p)
+				}
+			}
+			c.Address = strings.Join(parts, ", ")
+		case "NOTE":
+			c.Note = unescapeText(l.Value)
+		}
+	}
+	return c
+}
+
+// fieldType returns the first meaningful TYPE parameter, lowercased
+func fieldType(l contentLine) string {
+	for _, t := range strings.Split(l.param("TYPE"), ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "" && t != "internet" && t != "pref" && t != "voice" {
+			return t
+		}
+	}
+	return ""
+}
+
+// encodeVCard serializes a contact as a vCard 3.0 object
+func encodeVCard(c Contact) string {
+	var b strings.Builder
+	foldLine(&b, "BEGIN:VCARD")
+	foldLine(&b, "VERSION:3.0")
+	foldLine(&b, "PRODID:-//QuantumLife//CardDAV//EN")
+	foldLine(&b, "UID:"+c.ID)
+
+	fullName := c.FullName
+	if fullName == "" {
+		fullName = strings.TrimSpace(c.GivenName + " " + c.FamilyName)
+	}
+	foldLine(&b, "FN:"+escapeText(fullName))
+	foldLine(&b, "N:"+escapeText(c.FamilyName)+";"+escapeText(c.GivenName)+";;;")
+
+	for _, e := range c.Emails {
+		foldLine(&b, "EMAIL"+typeParam(e.Type, "INTERNET")+":"+e.Value)
+	}
+	for _, p := range c.Phones {
+		foldLine(&b, "TEL"+typeParam(p.Type, "")+":"+p.Value)
+	}
+	if c.Organization != "" {
+		foldLine(&b, "ORG:"+escapeText(c.Organization))
+	}
+	if c.Title != "" {
+		foldLine(&b, "TITLE:"+escapeText(c.Title))
+	}
+	if c.Birthday != "" {
+		foldLine(&b, "BDAY:"+c.Birthday)
+	}
+	if c.Address != "" {
+		// Stored as a single line in the street component
+		foldLine(&b, "ADR:;;"+escapeText(c.Address)+";;;;")
+	}
+	if c.Note != "" {
+		foldLine(&b, "NOTE:"+escapeText(c.Note))
+	}
+	foldLine(&b, "END:VCARD")
+	return b.String()
+}
+
+func typeParam(fieldType, base string) string {
+	types := make([]string, 0, 2)
+	if base != "" {
+		types = append(types, base)
+	}
+	if fieldType != "" {
+		types = append(types, strings.ToUpper(fieldType))
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	return ";TYPE=" + strings.Join(types, ",")
+}
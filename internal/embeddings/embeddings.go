@@ -9,14 +9,16 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sync/atomic"
 	"time"
 )
 
 // Service handles embedding generation
 type Service struct {
-	baseURL string
-	model   string
-	client  *http.Client
+	baseURL   string
+	model     string
+	client    *http.Client
+	dimension atomic.Uint64 // Set by DetectDimension
 }
 
 // Config for embedding service
@@ -112,10 +114,22 @@ func (s *Service) Embed(ctx context.Context, text string) ([]float32, error) {
 	return embedResp.Embedding, nil
 }
 
-// EmbedBatch generates embeddings for multiple texts
+// EmbedBatch generates embeddings for multiple texts in one request, falling
+// back to one request per text on Ollama versions without /api/embed
 func (s *Service) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
-	embeddings := make([][]float32, len(texts))
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	embeddings, err := s.embedMany(ctx, texts)
+	if err == nil {
+		return embeddings, nil
+	}
+	if err != errBatchUnsupported {
+		return nil, err
+	}
 
+	embeddings = make([][]float32, len(texts))
 	for i, text := range texts {
 		emb, err := s.Embed(ctx, text)
 		if err != nil {
@@ -127,10 +141,75 @@ func (s *Service) EmbedBatch(ctx context.Context, texts []string) ([][]float32,
 	return embeddings, nil
 }
 
-// Dimension returns the embedding dimension (for nomic-embed-text: 768)
+var errBatchUnsupported = fmt.Errorf("batch embedding not supported")
+
+// embedMany calls the batch endpoint (/api/embed)
+func (s *Service) embedMany(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": s.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/api/embed", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errBatchUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("embedding failed: %s - %s", resp.Status, string(respBody))
+	}
+
+	var embedResp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(embedResp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embedding failed: got %d embeddings for %d texts", len(embedResp.Embeddings), len(texts))
+	}
+
+	return embedResp.Embeddings, nil
+}
+
+// DefaultDimension is the dimension of nomic-embed-text, assumed until
+// DetectDimension has run
+const DefaultDimension = 768
+
+// DetectDimension embeds a probe text to learn the model's real dimension,
+// which Dimension reports from then on
+func (s *Service) DetectDimension(ctx context.Context) (uint64, error) {
+	emb, err := s.Embed(ctx, "dimension probe")
+	if err != nil {
+		return 0, err
+	}
+	if len(emb) == 0 {
+		return 0, fmt.Errorf("model %s returned an empty embedding", s.model)
+	}
+	s.dimension.Store(uint64(len(emb)))
+	return uint64(len(emb)), nil
+}
+
+// Dimension returns the embedding dimension
 func (s *Service) Dimension() uint64 {
-	// nomic-embed-text produces 768-dimensional vectors
-	return 768
+	if d := s.dimension.Load(); d > 0 {
+		return d
+	}
+	return DefaultDimension
 }
 
 // ModelName returns the model being used
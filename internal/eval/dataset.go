@@ -0,0 +1,218 @@
+// Package eval measures triage quality offline by replaying a golden
+// dataset of labelled items against a router configuration.
+package eval
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/learning"
+	"github.com/quantumlife/quantumlife/internal/storage"
+	"github.com/quantumlife/quantumlife/internal/testutil"
+	"github.com/quantumlife/quantumlife/internal/triage"
+)
+
+// Where a case came from
+const (
+	SourceFixture  = "fixture"
+	SourceOverride = "override"
+)
+
+// Case is one labelled item in the golden dataset
+type Case struct {
+	ID     string   `json:"id"`
+	Source string   `json:"source,omitempty"`
+	Item   CaseItem `json:"item"`
+
+	// Expected triage. A zero priority or empty actions are not scored.
+	HatID    core.HatID          `json:"hat_id"`
+	Priority triage.Priority     `json:"priority,omitempty"`
+	Actions  []triage.ActionType `json:"actions,omitempty"`
+}
+
+// CaseItem is the content triage sees
+type CaseItem struct {
+	Type      core.ItemType `json:"type"`
+	HatID     core.HatID    `json:"hat_id,omitempty"` // Hat before triage, such as its space's default
+	From      string        `json:"from"`
+	Subject   string        `json:"subject"`
+	Body      string        `json:"body"`
+	Timestamp time.Time     `json:"timestamp,omitempty"`
+}
+
+// item builds the item handed to triage. It carries the hat it had before
+// triage, never the expected one, which only decides where the prompt may
+// be sent.
+func (c *Case) item() *core.Item {
+	return &core.Item{
+		ID:        core.ItemID(c.ID),
+		Type:      c.Item.Type,
+		Status:    core.ItemStatusPending,
+		HatID:     c.Item.HatID,
+		From:      c.Item.From,
+		Subject:   c.Item.Subject,
+		Body:      c.Item.Body,
+		Timestamp: c.Item.Timestamp,
+	}
+}
+
+// Load reads a dataset stored as JSON lines, one case per line
+func Load(path string) ([]Case, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cases []Case
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var c Case
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if c.ID == "" || c.HatID == "" {
+			return nil, fmt.Errorf("%s:%d: case needs an id and a hat_id", path, line)
+		}
+		cases = append(cases, c)
+	}
+	return cases, scanner.Err()
+}
+
+// Save writes cases as JSON lines, readable only by the user
+func Save(path string, cases []Case) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i := range cases {
+		if err := enc.Encode(&cases[i]); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Merge adds cases to a dataset. A case with the ID of an existing one
+// replaces it, so re-seeding picks up newer corrections.
+func Merge(dataset []Case, cases ...Case) []Case {
+	index := make(map[string]int, len(dataset))
+	for i, c := range dataset {
+		index[c.ID] = i
+	}
+	for _, c := range cases {
+		if i, ok := index[c.ID]; ok {
+			dataset[i] = c
+			continue
+		}
+		index[c.ID] = len(dataset)
+		dataset = append(dataset, c)
+	}
+	return dataset
+}
+
+// FromFixtures returns the labelled emails in testutil as cases
+func FromFixtures() []Case {
+	fixtures := testutil.TriageFixtures()
+	cases := make([]Case, 0, len(fixtures))
+	for i, f := range fixtures {
+		c := Case{
+			ID:     fmt.Sprintf("fixture-%02d", i+1),
+			Source: SourceFixture,
+			Item: CaseItem{
+				Type:      core.ItemTypeEmail,
+				From:      f.Email.From,
+				Subject:   f.Email.Subject,
+				Body:      f.Email.Body,
+				Timestamp: f.Email.Date.UTC().Truncate(time.Second),
+			},
+			HatID:    core.HatID(f.HatID),
+			Priority: triage.Priority(f.Priority),
+		}
+		for _, a := range f.Actions {
+			c.Actions = append(c.Actions, triage.ActionType(a))
+		}
+		cases = append(cases, c)
+	}
+	return cases
+}
+
+// FromOverrides turns triage corrections made since the given time into
+// cases. The corrected hat and priority are the expected answer; the
+// latest correction of an item wins. Items that no longer exist are
+// rebuilt from the sender and subject kept with the signal.
+//
+// Unlike fixtures these are real items, so the dataset holds personal
+// data and should stay in the data directory.
+func FromOverrides(ctx context.Context, collector *learning.Collector, items *storage.ItemStore, since time.Time) ([]Case, error) {
+	signals, err := collector.GetRecentSignals(ctx, since, learning.SignalTriageOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []Case
+	seen := make(map[core.ItemID]bool)
+	for _, sig := range signals { // Newest first
+		if sig.ItemID == "" || sig.HatID == "" || seen[sig.ItemID] {
+			continue
+		}
+		seen[sig.ItemID] = true
+
+		c := Case{
+			ID:     "override-" + string(sig.ItemID),
+			Source: SourceOverride,
+			HatID:  sig.HatID,
+			Item: CaseItem{
+				Type:      core.ItemType(sig.Context.ItemType),
+				From:      sig.Context.Sender,
+				Subject:   sig.Context.ItemSubject,
+				Timestamp: sig.CreatedAt.UTC(),
+			},
+		}
+		if item, err := items.GetByID(sig.ItemID); err == nil {
+			c.Item = CaseItem{
+				Type:      item.Type,
+				From:      item.From,
+				Subject:   item.Subject,
+				Body:      item.Body,
+				Timestamp: item.Timestamp.UTC(),
+			}
+		}
+		if original, ok := sig.Value["original_hat_id"].(string); ok {
+			c.Item.HatID = core.HatID(original)
+		}
+		if p, ok := sig.Value["priority"].(float64); ok {
+			c.Priority = triage.Priority(p)
+		}
+		if actions, ok := sig.Value["actions"].([]interface{}); ok {
+			for _, a := range actions {
+				if s, ok := a.(string); ok {
+					c.Actions = append(c.Actions, triage.ActionType(s))
+				}
+			}
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
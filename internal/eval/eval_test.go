@@ -0,0 +1,288 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/learning"
+	"github.com/quantumlife/quantumlife/internal/llm"
+	"github.com/quantumlife/quantumlife/internal/storage"
+	"github.com/quantumlife/quantumlife/internal/testutil"
+	"github.com/quantumlife/quantumlife/internal/triage"
+)
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "triage.jsonl")
+	cases := FromFixtures()[:2]
+	if err := Save(path, cases); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded, cases) {
+		t.Errorf("Load() = %+v, want %+v", loaded, cases)
+	}
+}
+
+func TestMerge_ReplacesByID(t *testing.T) {
+	dataset := []Case{{ID: "a", HatID: core.HatHome}, {ID: "b", HatID: core.HatHealth}}
+	dataset = Merge(dataset, Case{ID: "b", HatID: core.HatFinance}, Case{ID: "c", HatID: core.HatSocial})
+
+	var got []string
+	for _, c := range dataset {
+		got = append(got, c.ID+":"+string(c.HatID))
+	}
+	if want := []string{"a:home", "b:finance", "c:social"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+}
+
+func TestFromFixtures(t *testing.T) {
+	cases := FromFixtures()
+	if len(cases) != len(testutil.TriageFixtures()) {
+		t.Fatalf("FromFixtures() = %d cases", len(cases))
+	}
+
+	hats := make(map[core.HatID]bool)
+	for _, id := range []core.HatID{core.HatParent, core.HatProfessional, core.HatPartner, core.HatHealth,
+		core.HatFinance, core.HatLearner, core.HatSocial, core.HatHome, core.HatCitizen,
+		core.HatSpiritual, core.HatCreative, core.HatPersonal} {
+		hats[id] = true
+	}
+	for _, c := range cases {
+		if !hats[c.HatID] {
+			t.Errorf("%s: unknown hat %q", c.ID, c.HatID)
+		}
+		if c.Priority < triage.PriorityLow || c.Priority > triage.PriorityCritical {
+			t.Errorf("%s: priority %d out of range", c.ID, c.Priority)
+		}
+		if c.Item.HatID != "" {
+			t.Errorf("%s: item hat should be empty, got %q", c.ID, c.Item.HatID)
+		}
+	}
+}
+
+func TestFromOverrides(t *testing.T) {
+	db := testutil.TestDB(t)
+	ctx := context.Background()
+	items := storage.NewItemStore(db)
+	collector := learning.NewCollector(db)
+
+	item := &core.Item{ID: "item-1", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatPersonal,
+		From: "billing@cityenergy.com", Subject: "Invoice overdue", Body: "Please pay", Timestamp: time.Now()}
+	if err := items.Create(item); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Moved to professional, then corrected again to finance
+	collector.CaptureTriageOverride(ctx, item, core.HatProfessional, learning.TriageOverride{OriginalHatID: core.HatPersonal})
+	time.Sleep(time.Millisecond)
+	collector.CaptureTriageOverride(ctx, item, core.HatFinance, learning.TriageOverride{
+		OriginalHatID: core.HatPersonal, OriginalPriority: 2, Priority: 3, Actions: []string{"remind"},
+	})
+
+	// An item that has since been deleted keeps its sender and subject
+	gone := &core.Item{ID: "item-2", Type: core.ItemTypeEmail, From: "dr@clinic.org", Subject: "Results"}
+	collector.CaptureTriageOverride(ctx, gone, core.HatHealth, learning.TriageOverride{OriginalHatID: core.HatProfessional})
+
+	cases, err := FromOverrides(ctx, collector, items, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("FromOverrides() error = %v", err)
+	}
+	if len(cases) != 2 {
+		t.Fatalf("FromOverrides() = %d cases, want 2: %+v", len(cases), cases)
+	}
+
+	byID := map[string]Case{cases[0].ID: cases[0], cases[1].ID: cases[1]}
+	invoice := byID["override-item-1"]
+	if invoice.HatID != core.HatFinance || invoice.Priority != triage.PriorityHigh ||
+		!reflect.DeepEqual(invoice.Actions, []triage.ActionType{triage.ActionRemind}) {
+		t.Errorf("latest correction not used: %+v", invoice)
+	}
+	if invoice.Item.Body != "Please pay" || invoice.Item.HatID != core.HatPersonal || invoice.Source != SourceOverride {
+		t.Errorf("item content = %+v", invoice.Item)
+	}
+
+	results := byID["override-item-2"]
+	if results.HatID != core.HatHealth || results.Item.From != "dr@clinic.org" || results.Item.Subject != "Results" {
+		t.Errorf("deleted item case = %+v", results)
+	}
+}
+
+// fakeTriager answers from a table keyed by subject
+type fakeTriager map[string]*triage.TriageResult
+
+func (f fakeTriager) Triage(ctx context.Context, item *core.Item) (*triage.TriageResult, error) {
+	if item.HatID != "" {
+		return nil, errors.New("expected hat leaked into the item")
+	}
+	result, ok := f[item.Subject]
+	if !ok {
+		return nil, errors.New("model unavailable")
+	}
+	return result, nil
+}
+
+func TestRunner_Run(t *testing.T) {
+	cases := []Case{
+		{ID: "1", Item: CaseItem{Subject: "invoice"}, HatID: core.HatFinance, Priority: triage.PriorityHigh,
+			Actions: []triage.ActionType{triage.ActionRemind, triage.ActionFlag}},
+		{ID: "2", Item: CaseItem{Subject: "standup"}, HatID: core.HatProfessional, Priority: triage.PriorityMedium},
+		{ID: "3", Item: CaseItem{Subject: "gym"}, HatID: core.HatHealth},
+		{ID: "4", Item: CaseItem{Subject: "outage"}, HatID: core.HatProfessional},
+	}
+	triager := fakeTriager{
+		"invoice": {HatID: core.HatFinance, Priority: triage.PriorityHigh,
+			Actions: []triage.SuggestedAction{{Type: triage.ActionRemind}}},
+		"standup": {HatID: core.HatProfessional, Priority: triage.PriorityLow},
+		"gym":     {HatID: core.HatProfessional, Fallback: true},
+	}
+
+	runner := NewRunner(triager)
+	var progress []int
+	runner.OnCase = func(done, total int, result Result) { progress = append(progress, done) }
+
+	report, err := runner.Run(context.Background(), cases)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !reflect.DeepEqual(progress, []int{1, 2, 3, 4}) {
+		t.Errorf("progress = %v", progress)
+	}
+
+	if report.Cases != 4 || report.Errors != 1 || report.Fallbacks != 1 {
+		t.Errorf("counts = %d cases, %d errors, %d fallbacks", report.Cases, report.Errors, report.Fallbacks)
+	}
+	if report.HatAccuracy != 0.5 {
+		t.Errorf("HatAccuracy = %v, want 0.5", report.HatAccuracy)
+	}
+	if report.PriorityAccuracy != 0.5 || report.PriorityScored != 2 {
+		t.Errorf("PriorityAccuracy = %v of %d, want 0.5 of 2", report.PriorityAccuracy, report.PriorityScored)
+	}
+	if report.ActionRecall != 0.5 || report.ActionsScored != 1 {
+		t.Errorf("ActionRecall = %v of %d, want 0.5 of 1", report.ActionRecall, report.ActionsScored)
+	}
+
+	wantConfusion := map[core.HatID]map[core.HatID]int{
+		core.HatFinance:      {core.HatFinance: 1},
+		core.HatProfessional: {core.HatProfessional: 1, "": 1},
+		core.HatHealth:       {core.HatProfessional: 1},
+	}
+	if !reflect.DeepEqual(report.Confusion, wantConfusion) {
+		t.Errorf("Confusion = %v, want %v", report.Confusion, wantConfusion)
+	}
+
+	professional := report.Hats[core.HatProfessional]
+	want := HatScore{TruePositives: 1, FalsePositives: 1, FalseNegatives: 1, TrueNegatives: 1,
+		Precision: 0.5, Recall: 0.5, F1: 0.5}
+	if professional != want {
+		t.Errorf("professional = %+v, want %+v", professional, want)
+	}
+	if _, ok := report.Hats[""]; ok {
+		t.Error("errors should not get a hat score")
+	}
+}
+
+func TestRunner_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runner := NewRunner(fakeTriager{"a": {HatID: core.HatHome}})
+	runner.OnCase = func(done, total int, result Result) { cancel() }
+
+	cases := []Case{{ID: "1", Item: CaseItem{Subject: "a"}, HatID: core.HatHome}, {ID: "2", HatID: core.HatHome}}
+	report, err := runner.Run(ctx, cases)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if report.Cases != 1 || report.HatAccuracy != 1 {
+		t.Errorf("partial report = %+v", report)
+	}
+}
+
+func TestSummarize_Latency(t *testing.T) {
+	var results []Result
+	for i := 1; i <= 20; i++ {
+		results = append(results, Result{Latency: time.Duration(i) * time.Millisecond})
+	}
+
+	got := Summarize(results).Latency
+	want := LatencyStats{Mean: 10500 * time.Microsecond, P50: 10 * time.Millisecond, P95: 19 * time.Millisecond, Max: 20 * time.Millisecond}
+	if got != want {
+		t.Errorf("Latency = %+v, want %+v", got, want)
+	}
+}
+
+func TestReport_Print(t *testing.T) {
+	report := Summarize([]Result{
+		{CaseID: "1", ExpectedHat: core.HatFinance, PredictedHat: core.HatFinance},
+		{CaseID: "2", ExpectedHat: core.HatHealth, PredictedHat: core.HatFinance, Fallback: true},
+		{CaseID: "3", ExpectedHat: core.HatHealth, Error: "timeout"},
+	})
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		"Hat accuracy:       33.3%",
+		"Confusion (rows expected, columns predicted)",
+		"2: hat finance (want health) [fallback]",
+		"3: error: timeout",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Print() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestRunner_Engine replays fixtures through the real triage engine
+// against a local model that always answers finance
+func TestRunner_Engine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+			return
+		}
+		json.NewEncoder(w).Encode(llm.OllamaChatResponse{Message: llm.OllamaChatMessage{
+			Content: `{"hat_id": "finance", "confidence": 0.9, "priority": 3, "urgency": 2, "reasoning": "Money",
+				"actions": [{"type": "remind", "description": "Pay it"}]}`,
+		}, Done: true})
+	}))
+	defer server.Close()
+
+	router := llm.NewRouter(llm.RouterConfig{
+		Ollama:      llm.NewOllamaClient(llm.OllamaConfig{BaseURL: server.URL}),
+		PreferLocal: true,
+	})
+	engine := triage.NewEngine(router, nil, triage.EngineConfig{})
+
+	cases := FromFixtures()
+	report, err := NewRunner(engine).Run(context.Background(), cases)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	finance := 0
+	for _, c := range cases {
+		if c.HatID == core.HatFinance {
+			finance++
+		}
+	}
+	if want := float64(finance) / float64(len(cases)); report.HatAccuracy != want {
+		t.Errorf("HatAccuracy = %v, want %v", report.HatAccuracy, want)
+	}
+	if report.Hats[core.HatFinance].Recall != 1 || report.Errors != 0 || report.Fallbacks != 0 {
+		t.Errorf("report = %+v", report)
+	}
+}
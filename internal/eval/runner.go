@@ -0,0 +1,366 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/triage"
+)
+
+// Triager triages one item. triage.Engine implements this interface.
+type Triager interface {
+	Triage(ctx context.Context, item *core.Item) (*triage.TriageResult, error)
+}
+
+// Runner replays cases through a triager
+type Runner struct {
+	triager Triager
+
+	// OnCase, if set, is called after each case is triaged
+	OnCase func(done, total int, result Result)
+}
+
+// NewRunner creates a runner for triager
+func NewRunner(triager Triager) *Runner {
+	return &Runner{triager: triager}
+}
+
+// Result is the outcome of one case
+type Result struct {
+	CaseID            string              `json:"case_id"`
+	ExpectedHat       core.HatID          `json:"expected_hat"`
+	PredictedHat      core.HatID          `json:"predicted_hat,omitempty"`
+	ExpectedPriority  triage.Priority     `json:"expected_priority,omitempty"`
+	PredictedPriority triage.Priority     `json:"predicted_priority,omitempty"`
+	ExpectedActions   []triage.ActionType `json:"expected_actions,omitempty"`
+	MissingActions    []triage.ActionType `json:"missing_actions,omitempty"` // Expected but not suggested
+	Fallback          bool                `json:"fallback,omitempty"`
+	Latency           time.Duration       `json:"latency"`
+	Error             string              `json:"error,omitempty"`
+}
+
+// HatCorrect reports whether the hat matched
+func (r *Result) HatCorrect() bool {
+	return r.PredictedHat == r.ExpectedHat
+}
+
+// PriorityCorrect reports whether the priority matched. Cases without an
+// expected priority are not scored.
+func (r *Result) PriorityCorrect() bool {
+	return r.ExpectedPriority == 0 || r.PredictedPriority == r.ExpectedPriority
+}
+
+// Report summarizes a run
+type Report struct {
+	Cases     int `json:"cases"`
+	Errors    int `json:"errors"`    // Triage returned an error
+	Fallbacks int `json:"fallbacks"` // The model gave no usable answer
+
+	HatAccuracy      float64 `json:"hat_accuracy"`
+	PriorityAccuracy float64 `json:"priority_accuracy"` // Over cases with an expected priority
+	PriorityScored   int     `json:"priority_scored"`
+	ActionRecall     float64 `json:"action_recall"` // Share of expected actions suggested
+	ActionsScored    int     `json:"actions_scored"`
+
+	// Confusion counts cases by expected hat, then predicted hat. Errors
+	// are counted under an empty predicted hat.
+	Confusion map[core.HatID]map[core.HatID]int `json:"confusion"`
+	Hats      map[core.HatID]HatScore           `json:"hats"`
+
+	Latency LatencyStats `json:"latency"`
+	Results []Result     `json:"results"`
+}
+
+// HatScore is the one-vs-rest confusion matrix of a hat
+type HatScore struct {
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	TrueNegatives  int     `json:"true_negatives"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+}
+
+// LatencyStats summarizes triage latency across cases
+type LatencyStats struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P95  time.Duration `json:"p95"`
+	Max  time.Duration `json:"max"`
+}
+
+// Run triages every case in order. If ctx is cancelled the report covers
+// the cases done so far and the context's error is returned with it.
+func (r *Runner) Run(ctx context.Context, cases []Case) (*Report, error) {
+	results := make([]Result, 0, len(cases))
+	for i := range cases {
+		if err := ctx.Err(); err != nil {
+			return Summarize(results), err
+		}
+
+		c := &cases[i]
+		result := Result{
+			CaseID:           c.ID,
+			ExpectedHat:      c.HatID,
+			ExpectedPriority: c.Priority,
+			ExpectedActions:  c.Actions,
+		}
+
+		start := time.Now()
+		decision, err := r.triager.Triage(ctx, c.item())
+		result.Latency = time.Since(start)
+
+		if err != nil {
+			result.Error = err.Error()
+			result.MissingActions = c.Actions
+		} else {
+			result.PredictedHat = decision.HatID
+			result.PredictedPriority = decision.Priority
+			result.Fallback = decision.Fallback
+			result.MissingActions = missingActions(c.Actions, decision.Actions)
+		}
+
+		results = append(results, result)
+		if r.OnCase != nil {
+			r.OnCase(i+1, len(cases), result)
+		}
+	}
+	return Summarize(results), nil
+}
+
+func missingActions(expected []triage.ActionType, suggested []triage.SuggestedAction) []triage.ActionType {
+	var missing []triage.ActionType
+	for _, want := range expected {
+		found := false
+		for _, a := range suggested {
+			if a.Type == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, want)
+		}
+	}
+	return missing
+}
+
+// Summarize computes a report from case results
+func Summarize(results []Result) *Report {
+	report := &Report{
+		Cases:     len(results),
+		Confusion: make(map[core.HatID]map[core.HatID]int),
+		Hats:      make(map[core.HatID]HatScore),
+		Results:   results,
+	}
+	if len(results) == 0 {
+		return report
+	}
+
+	var hatsCorrect, prioritiesCorrect, actionsExpected, actionsFound int
+	latencies := make([]time.Duration, 0, len(results))
+	var total time.Duration
+
+	for i := range results {
+		res := &results[i]
+		if res.Error != "" {
+			report.Errors++
+		}
+		if res.Fallback {
+			report.Fallbacks++
+		}
+		if res.HatCorrect() {
+			hatsCorrect++
+		}
+		if res.ExpectedPriority != 0 {
+			report.PriorityScored++
+			if res.PriorityCorrect() {
+				prioritiesCorrect++
+			}
+		}
+		if len(res.ExpectedActions) > 0 {
+			report.ActionsScored++
+			actionsExpected += len(res.ExpectedActions)
+			actionsFound += len(res.ExpectedActions) - len(res.MissingActions)
+		}
+
+		row := report.Confusion[res.ExpectedHat]
+		if row == nil {
+			row = make(map[core.HatID]int)
+			report.Confusion[res.ExpectedHat] = row
+		}
+		row[res.PredictedHat]++
+
+		latencies = append(latencies, res.Latency)
+		total += res.Latency
+	}
+
+	report.HatAccuracy = ratio(hatsCorrect, len(results))
+	report.PriorityAccuracy = ratio(prioritiesCorrect, report.PriorityScored)
+	report.ActionRecall = ratio(actionsFound, actionsExpected)
+
+	for _, hat := range report.hatIDs() {
+		if hat == "" {
+			continue
+		}
+		var score HatScore
+		for i := range results {
+			expected := results[i].ExpectedHat == hat
+			predicted := results[i].PredictedHat == hat
+			switch {
+			case expected && predicted:
+				score.TruePositives++
+			case predicted:
+				score.FalsePositives++
+			case expected:
+				score.FalseNegatives++
+			default:
+				score.TrueNegatives++
+			}
+		}
+		score.Precision = ratio(score.TruePositives, score.TruePositives+score.FalsePositives)
+		score.Recall = ratio(score.TruePositives, score.TruePositives+score.FalseNegatives)
+		if score.Precision+score.Recall > 0 {
+			score.F1 = 2 * score.Precision * score.Recall / (score.Precision + score.Recall)
+		}
+		report.Hats[hat] = score
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.Latency = LatencyStats{
+		Mean: total / time.Duration(len(latencies)),
+		P50:  percentile(latencies, 50),
+		P95:  percentile(latencies, 95),
+		Max:  latencies[len(latencies)-1],
+	}
+	return report
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// hatIDs returns every hat that was expected or predicted, sorted
+func (r *Report) hatIDs() []core.HatID {
+	seen := make(map[core.HatID]bool)
+	for expected, row := range r.Confusion {
+		seen[expected] = true
+		for predicted := range row {
+			seen[predicted] = true
+		}
+	}
+	ids := make([]core.HatID, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Print writes the report as text: overall scores, per-hat scores, the
+// hat confusion matrix and the cases that were wrong
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Cases: %d (errors: %d, fallbacks: %d)\n", r.Cases, r.Errors, r.Fallbacks)
+	fmt.Fprintf(w, "Hat accuracy:      %5.1f%%\n", 100*r.HatAccuracy)
+	fmt.Fprintf(w, "Priority accuracy: %5.1f%% of %d\n", 100*r.PriorityAccuracy, r.PriorityScored)
+	fmt.Fprintf(w, "Action recall:     %5.1f%% of %d\n", 100*r.ActionRecall, r.ActionsScored)
+	fmt.Fprintf(w, "Latency: mean %s, p50 %s, p95 %s, max %s\n",
+		r.Latency.Mean.Round(time.Millisecond), r.Latency.P50.Round(time.Millisecond),
+		r.Latency.P95.Round(time.Millisecond), r.Latency.Max.Round(time.Millisecond))
+	if r.Cases == 0 {
+		return
+	}
+
+	ids := r.hatIDs()
+
+	fmt.Fprintln(w, "\nPer hat:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "hat\tTP\tFP\tFN\tTN\tprecision\trecall\tF1\t")
+	for _, id := range ids {
+		s, ok := r.Hats[id]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.2f\t%.2f\t%.2f\t\n", id,
+			s.TruePositives, s.FalsePositives, s.FalseNegatives, s.TrueNegatives, s.Precision, s.Recall, s.F1)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nConfusion (rows expected, columns predicted):")
+	tw = tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.AlignRight)
+	header := []string{""}
+	for _, id := range ids {
+		header = append(header, abbreviate(id))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	for _, expected := range ids {
+		row, ok := r.Confusion[expected]
+		if !ok {
+			continue
+		}
+		cells := []string{string(expected)}
+		for _, predicted := range ids {
+			if n := row[predicted]; n > 0 {
+				cells = append(cells, fmt.Sprint(n))
+			} else {
+				cells = append(cells, ".")
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t")+"\t")
+	}
+	tw.Flush()
+
+	var wrong []string
+	for _, res := range r.Results {
+		switch {
+		case res.Error != "":
+			wrong = append(wrong, fmt.Sprintf("  %s: error: %s", res.CaseID, res.Error))
+		case !res.HatCorrect() || !res.PriorityCorrect() || len(res.MissingActions) > 0:
+			line := fmt.Sprintf("  %s: hat %s (want %s)", res.CaseID, res.PredictedHat, res.ExpectedHat)
+			if res.ExpectedPriority != 0 {
+				line += fmt.Sprintf(", priority %d (want %d)", res.PredictedPriority, res.ExpectedPriority)
+			}
+			if len(res.MissingActions) > 0 {
+				line += fmt.Sprintf(", missing actions %v", res.MissingActions)
+			}
+			if res.Fallback {
+				line += " [fallback]"
+			}
+			wrong = append(wrong, line)
+		}
+	}
+	if len(wrong) > 0 {
+		fmt.Fprintf(w, "\nMisses (%d):\n%s\n", len(wrong), strings.Join(wrong, "\n"))
+	}
+}
+
+// abbreviate shortens a hat name for a matrix column; errors show as "err"
+func abbreviate(id core.HatID) string {
+	if id == "" {
+		return "err"
+	}
+	if len(id) > 5 {
+		return string(id[:5])
+	}
+	return string(id)
+}
@@ -0,0 +1,571 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BudgetPeriodKind says how budget periods repeat
+type BudgetPeriodKind string
+
+const (
+	BudgetPeriodMonthly BudgetPeriodKind = "monthly"
+	BudgetPeriodCustom  BudgetPeriodKind = "custom"
+)
+
+var (
+	// ErrBudgetNotFound is returned for a category without a budget
+	ErrBudgetNotFound = errors.New("budget not found")
+
+	// ErrInvalidBudget is returned for a budget change that can't be made
+	ErrInvalidBudget = errors.New("invalid budget")
+)
+
+// BudgetPeriod says when budget periods start. Every envelope shares it.
+type BudgetPeriod struct {
+	Kind     BudgetPeriodKind `json:"kind"`
+	StartDay int              `json:"start_day,omitempty"` // Monthly: day of the month periods start on, 1-28
+	Anchor   string           `json:"anchor,omitempty"`    // Custom: any day a period starts on, YYYY-MM-DD
+	Days     int              `json:"days,omitempty"`      // Custom: length of each period
+}
+
+// DefaultBudgetPeriod is calendar months
+func DefaultBudgetPeriod() BudgetPeriod {
+	return BudgetPeriod{Kind: BudgetPeriodMonthly, StartDay: 1}
+}
+
+// Validate checks the period
+func (p BudgetPeriod) Validate() error {
+	switch p.Kind {
+	case BudgetPeriodMonthly:
+		if p.StartDay < 1 || p.StartDay > 28 {
+			return fmt.Errorf("%w: start day must be between 1 and 28", ErrInvalidBudget)
+		}
+	case BudgetPeriodCustom:
+		if p.Days < 1 {
+			return fmt.Errorf("%w: custom periods need a length in days", ErrInvalidBudget)
+		}
+		if _, err := time.Parse("2006-01-02", p.Anchor); err != nil {
+			return fmt.Errorf("%w: invalid anchor date %q", ErrInvalidBudget, p.Anchor)
+		}
+	default:
+		return fmt.Errorf("%w: unknown period kind %q", ErrInvalidBudget, p.Kind)
+	}
+	return nil
+}
+
+// Bounds returns the first day of the period containing day and the first
+// day of the period after it
+func (p BudgetPeriod) Bounds(day time.Time) (start, next time.Time) {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	if p.Kind == BudgetPeriodCustom && p.Days > 0 {
+		anchor, _ := time.Parse("2006-01-02", p.Anchor)
+		days := int(day.Sub(anchor).Hours() / 24)
+		n := days / p.Days
+		if days%p.Days < 0 {
+			n-- // Round down for days before the anchor
+		}
+		start = anchor.AddDate(0, 0, n*p.Days)
+		return start, start.AddDate(0, 0, p.Days)
+	}
+
+	startDay := p.StartDay
+	if startDay < 1 || startDay > 28 {
+		startDay = 1
+	}
+	start = time.Date(day.Year(), day.Month(), startDay, 0, 0, 0, 0, time.UTC)
+	if day.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start, start.AddDate(0, 1, 0)
+}
+
+// Envelope is the amount budgeted for a category each period
+type Envelope struct {
+	Category  Category  `json:"category"`
+	Amount    Money     `json:"amount"`    // Each period, in the home currency
+	Rollover  bool      `json:"rollover"`  // Carry unspent and overspent amounts into the next period
+	StartsOn  string    `json:"starts_on"` // Start of the first period tracked
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BudgetTransfer moves money between two envelopes for one period
+type BudgetTransfer struct {
+	ID          string    `json:"id"`
+	PeriodStart string    `json:"period_start"`
+	From        Category  `json:"from"`
+	To          Category  `json:"to"`
+	Amount      Money     `json:"amount"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// EnvelopeStatus is an envelope's budget against actual spending for one
+// period. Available is what is budgeted, carried over and transferred in,
+// less what was spent; rollover envelopes carry it to the next period.
+type EnvelopeStatus struct {
+	Category    Category `json:"category"`
+	PeriodStart string   `json:"period_start"`
+	PeriodEnd   string   `json:"period_end"` // Last day of the period
+	Budgeted    Money    `json:"budgeted"`
+	CarriedOver Money    `json:"carried_over"`
+	Transferred Money    `json:"transferred"`
+	Spent       Money    `json:"spent"`
+	Available   Money    `json:"available"`
+	PercentUsed float64  `json:"percent_used"`
+	Rollover    bool     `json:"rollover"`
+}
+
+// Allowance is what the envelope may spend in the period
+func (s EnvelopeStatus) Allowance() Money {
+	return Money{Minor: s.Budgeted.Minor + s.CarriedOver.Minor + s.Transferred.Minor, Currency: s.Budgeted.Currency}
+}
+
+// BudgetReport is every envelope's status in one period. Zero-based
+// budgeting gives all income to envelopes, leaving nothing unassigned.
+type BudgetReport struct {
+	Period       BudgetPeriod     `json:"period"`
+	PeriodStart  string           `json:"period_start"`
+	PeriodEnd    string           `json:"period_end"`
+	Currency     string           `json:"currency"`
+	Income       Money            `json:"income"`
+	Budgeted     Money            `json:"budgeted"`
+	Spent        Money            `json:"spent"`
+	Unassigned   Money            `json:"unassigned"` // Income not given to an envelope
+	Envelopes    []EnvelopeStatus `json:"envelopes"`
+	StaleRates   []string         `json:"stale_rates,omitempty"`
+	MissingRates []string         `json:"missing_rates,omitempty"`
+}
+
+// budgetBook holds the envelopes and what each period gave them
+type budgetBook struct {
+	period    BudgetPeriod
+	envelopes map[Category]*Envelope
+	transfers []*BudgetTransfer
+	budgeted  map[Category]map[string]int64 // Minor units each recorded period was given, by period start
+}
+
+func newBudgetBook() *budgetBook {
+	return &budgetBook{
+		period:    DefaultBudgetPeriod(),
+		envelopes: make(map[Category]*Envelope),
+		budgeted:  make(map[Category]map[string]int64),
+	}
+}
+
+// setBudgeted records what category was given in the period starting on
+// start
+func (b *budgetBook) setBudgeted(category Category, start string, minor int64) {
+	if b.budgeted[category] == nil {
+		b.budgeted[category] = make(map[string]int64)
+	}
+	b.budgeted[category][start] = minor
+}
+
+// record keeps what each period was given, so later changes to an
+// envelope's amount don't rewrite its history
+func (b *budgetBook) record(history []EnvelopeStatus) {
+	for _, st := range history {
+		if _, ok := b.budgeted[st.Category][st.PeriodStart]; !ok {
+			b.setBudgeted(st.Category, st.PeriodStart, st.Budgeted.Minor)
+		}
+	}
+}
+
+// budgetTally is spending by envelope and income, in minor units of the
+// home currency, by period start
+type budgetTally struct {
+	spent  map[Category]map[string]int64
+	income map[string]int64
+}
+
+// tallyBudgets totals the transactions by budget period. Refunds reduce
+// an envelope's spending, split transactions count towards each part's
+// envelope and transfers between the user's accounts count for none. The
+// caller must hold the lock.
+func (s *Space) tallyBudgets(conv *converter) budgetTally {
+	tally := budgetTally{
+		spent:  make(map[Category]map[string]int64),
+		income: make(map[string]int64),
+	}
+	for _, tx := range s.transactions {
+		if tx.TransferID != "" || tx.ReimbursementID != "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", tx.Date)
+		if err != nil {
+			continue
+		}
+		amount, ok := conv.convert(conv.money(tx.Amount, tx.IsoCurrencyCode))
+		if !ok {
+			continue
+		}
+
+		start, _ := s.budgets.period.Bounds(day)
+		key := start.Format("2006-01-02")
+		for _, sh := range shares(tx, amount) {
+			if sh.category == CategoryIncome {
+				tally.income[key] -= sh.minor
+				continue
+			}
+			if s.budgets.envelopes[sh.category] == nil {
+				continue
+			}
+			if tally.spent[sh.category] == nil {
+				tally.spent[sh.category] = make(map[string]int64)
+			}
+			tally.spent[sh.category][key] += sh.minor
+		}
+	}
+	return tally
+}
+
+// envelopeHistory works out an envelope's status in every period from its
+// first to the one containing until. The caller must hold the lock.
+func (s *Space) envelopeHistory(env *Envelope, until time.Time, tally budgetTally, conv *converter) []EnvelopeStatus {
+	b := s.budgets
+	home := conv.zero()
+
+	amount, _ := conv.convert(env.Amount)
+	transferred := make(map[string]int64)
+	for _, t := range b.transfers {
+		if t.To == env.Category {
+			transferred[t.PeriodStart] += t.Amount.Minor
+		}
+		if t.From == env.Category {
+			transferred[t.PeriodStart] -= t.Amount.Minor
+		}
+	}
+
+	first, err := time.Parse("2006-01-02", env.StartsOn)
+	if err != nil {
+		first = until
+	}
+	start, _ := b.period.Bounds(first)
+	until, _ = b.period.Bounds(until)
+
+	var history []EnvelopeStatus
+	var carried int64
+	for !start.After(until) {
+		_, next := b.period.Bounds(start)
+		key := start.Format("2006-01-02")
+
+		budgeted, ok := b.budgeted[env.Category][key]
+		if !ok {
+			budgeted = amount.Minor
+		}
+		spent := tally.spent[env.Category][key]
+		money := func(minor int64) Money { return Money{Minor: minor, Currency: home.Currency} }
+
+		st := EnvelopeStatus{
+			Category:    env.Category,
+			PeriodStart: key,
+			PeriodEnd:   next.AddDate(0, 0, -1).Format("2006-01-02"),
+			Budgeted:    money(budgeted),
+			CarriedOver: money(carried),
+			Transferred: money(transferred[key]),
+			Spent:       money(spent),
+			Rollover:    env.Rollover,
+		}
+		allowance := st.Allowance().Minor
+		st.Available = money(allowance - spent)
+		if allowance > 0 {
+			st.PercentUsed = float64(spent) / float64(allowance) * 100
+		}
+		history = append(history, st)
+
+		carried = 0
+		if env.Rollover {
+			carried = st.Available.Minor
+		}
+		start = next
+	}
+	return history
+}
+
+// budgetReport works out the report for the period containing at, and
+// every envelope's history up to it. The caller must hold the lock.
+func (s *Space) budgetReport(at time.Time) (*BudgetReport, []EnvelopeStatus) {
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	tally := s.tallyBudgets(conv)
+
+	start, next := s.budgets.period.Bounds(at)
+	key := start.Format("2006-01-02")
+	report := &BudgetReport{
+		Period:      s.budgets.period,
+		PeriodStart: key,
+		PeriodEnd:   next.AddDate(0, 0, -1).Format("2006-01-02"),
+		Currency:    conv.home,
+		Envelopes:   []EnvelopeStatus{},
+	}
+
+	var all []EnvelopeStatus
+	var budgeted, spent int64
+	for _, category := range AllCategories() {
+		env := s.budgets.envelopes[category]
+		if env == nil {
+			continue
+		}
+		history := s.envelopeHistory(env, at, tally, conv)
+		all = append(all, history...)
+		if len(history) == 0 {
+			continue // Starts after the period
+		}
+		current := history[len(history)-1]
+		report.Envelopes = append(report.Envelopes, current)
+		budgeted += current.Budgeted.Minor
+		spent += current.Spent.Minor
+	}
+
+	income := tally.income[key]
+	report.Income = Money{Minor: income, Currency: conv.home}
+	report.Budgeted = Money{Minor: budgeted, Currency: conv.home}
+	report.Spent = Money{Minor: spent, Currency: conv.home}
+	report.Unassigned = Money{Minor: income - budgeted, Currency: conv.home}
+	report.StaleRates = conv.staleRates()
+	report.MissingRates = conv.missingRates()
+	return report, all
+}
+
+// GetBudgets reports every envelope in the current period
+func (s *Space) GetBudgets() (*BudgetReport, error) {
+	return s.BudgetReport(time.Now())
+}
+
+// BudgetReport reports every envelope in the period containing at. Each
+// envelope's budget against actual spending is recorded for every period
+// up to it.
+func (s *Space) BudgetReport(at time.Time) (*BudgetReport, error) {
+	s.mu.Lock()
+	report, history := s.budgetReport(at)
+	s.budgets.record(history)
+	store := s.store
+	s.mu.Unlock()
+
+	if store != nil {
+		if err := store.SaveBudgetHistory(history); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// GetBudgetHistory returns an envelope's budget against actual spending
+// in every period up to the current one, oldest first
+func (s *Space) GetBudgetHistory(category Category) ([]EnvelopeStatus, error) {
+	s.mu.Lock()
+	env := s.budgets.envelopes[category]
+	if env == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrBudgetNotFound, category)
+	}
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	history := s.envelopeHistory(env, time.Now(), s.tallyBudgets(conv), conv)
+	s.budgets.record(history)
+	store := s.store
+	s.mu.Unlock()
+
+	if store != nil {
+		if err := store.SaveBudgetHistory(history); err != nil {
+			return history, err
+		}
+	}
+	return history, nil
+}
+
+// GetBudgetPeriod returns how budget periods repeat
+func (s *Space) GetBudgetPeriod() BudgetPeriod {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.budgets.period
+}
+
+// SetBudget gives category an envelope of amount, in the home currency,
+// each period. The change applies from the current period on; earlier
+// periods keep what they were given.
+func (s *Space) SetBudget(category Category, amount float64, rollover bool) (*Envelope, error) {
+	if !IsValidCategory(category) {
+		return nil, fmt.Errorf("%w: unknown category %s", ErrInvalidBudget, category)
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: amount can't be negative", ErrInvalidBudget)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	start, _ := s.budgets.period.Bounds(now)
+	env := &Envelope{Category: category, StartsOn: start.Format("2006-01-02")}
+	if existing := s.budgets.envelopes[category]; existing != nil {
+		env.StartsOn = existing.StartsOn
+	}
+	env.Amount = NewMoney(amount, s.insightsEngine.HomeCurrency())
+	env.Rollover = rollover
+	env.UpdatedAt = now
+
+	if s.store != nil {
+		if err := s.store.SaveEnvelope(env); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record the periods before this one at what they were given, then
+	// give this one the new amount
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	if existing := s.budgets.envelopes[category]; existing != nil {
+		s.budgets.record(s.envelopeHistory(existing, start.AddDate(0, 0, -1), s.tallyBudgets(conv), conv))
+	}
+	s.budgets.envelopes[category] = env
+	s.budgets.setBudgeted(category, start.Format("2006-01-02"), env.Amount.Minor)
+	s.insightsEngine.SetBudget(category, amount)
+
+	if s.store != nil {
+		history := s.envelopeHistory(env, now, s.tallyBudgets(conv), conv)
+		if err := s.store.SaveBudgetHistory(history); err != nil {
+			return env, err
+		}
+	}
+	return env, nil
+}
+
+// DeleteBudget removes category's envelope and its history
+func (s *Space) DeleteBudget(category Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.budgets.envelopes[category] == nil {
+		return fmt.Errorf("%w: %s", ErrBudgetNotFound, category)
+	}
+	if s.store != nil {
+		if err := s.store.DeleteEnvelope(category); err != nil {
+			return err
+		}
+	}
+	delete(s.budgets.envelopes, category)
+	delete(s.budgets.budgeted, category)
+	s.insightsEngine.DeleteBudget(category)
+	return nil
+}
+
+// TransferBudget moves amount, in the home currency, from one envelope to
+// another for the current period. The source envelope must have that much
+// available.
+func (s *Space) TransferBudget(from, to Category, amount float64, note string) (*BudgetTransfer, error) {
+	if from == to {
+		return nil, fmt.Errorf("%w: can't transfer an envelope to itself", ErrInvalidBudget)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	home := s.insightsEngine.HomeCurrency()
+	transfer := &BudgetTransfer{
+		ID:        "btr_" + uuid.New().String(),
+		From:      from,
+		To:        to,
+		Amount:    NewMoney(amount, home),
+		Note:      note,
+		CreatedAt: time.Now(),
+	}
+	if transfer.Amount.Minor <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", ErrInvalidBudget)
+	}
+	for _, category := range []Category{from, to} {
+		if s.budgets.envelopes[category] == nil {
+			return nil, fmt.Errorf("%w: %s", ErrBudgetNotFound, category)
+		}
+	}
+
+	conv := newConverter(home, s.exchange)
+	history := s.envelopeHistory(s.budgets.envelopes[from], transfer.CreatedAt, s.tallyBudgets(conv), conv)
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%w: %s has no budget this period", ErrInvalidBudget, from)
+	}
+	current := history[len(history)-1]
+	if current.Available.Minor < transfer.Amount.Minor {
+		return nil, fmt.Errorf("%w: only %s available in %s", ErrInvalidBudget, current.Available, from)
+	}
+	transfer.PeriodStart = current.PeriodStart
+
+	if s.store != nil {
+		if err := s.store.SaveBudgetTransfer(transfer); err != nil {
+			return nil, err
+		}
+	}
+	s.budgets.transfers = append(s.budgets.transfers, transfer)
+	return transfer, nil
+}
+
+// SetBudgetPeriod changes how budget periods repeat. Envelopes start
+// afresh from the new period containing today.
+func (s *Space) SetBudgetPeriod(period BudgetPeriod) error {
+	if err := period.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	start, _ := period.Bounds(time.Now())
+	envelopes := make(map[Category]*Envelope, len(s.budgets.envelopes))
+	for category, env := range s.budgets.envelopes {
+		moved := *env
+		moved.StartsOn = start.Format("2006-01-02")
+		envelopes[category] = &moved
+	}
+
+	if s.store != nil {
+		if err := s.store.SaveBudgetPeriod(period); err != nil {
+			return err
+		}
+		for _, env := range envelopes {
+			if err := s.store.SaveEnvelope(env); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.budgets.period = period
+	s.budgets.envelopes = envelopes
+	s.budgets.budgeted = make(map[Category]map[string]int64)
+	return nil
+}
+
+// loadBudgets restores the budget period, envelopes, transfers and what
+// each recorded period was given. Envelopes from the space's config are
+// kept unless stored ones replace them. The caller must hold the lock.
+func (s *Space) loadBudgets() error {
+	period, err := s.store.BudgetPeriod()
+	if err != nil {
+		return err
+	}
+	envelopes, err := s.store.Envelopes()
+	if err != nil {
+		return err
+	}
+	transfers, err := s.store.BudgetTransfers()
+	if err != nil {
+		return err
+	}
+	history, err := s.store.BudgetHistory()
+	if err != nil {
+		return err
+	}
+
+	s.budgets.period = period
+	for _, env := range envelopes {
+		s.budgets.envelopes[env.Category] = env
+	}
+	for _, env := range s.budgets.envelopes {
+		s.insightsEngine.SetBudget(env.Category, env.Amount.Float64())
+	}
+	s.budgets.transfers = transfers
+	s.budgets.budgeted = make(map[Category]map[string]int64)
+	s.budgets.record(history)
+	return nil
+}
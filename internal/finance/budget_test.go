@@ -0,0 +1,119 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetPeriod_Bounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    BudgetPeriod
+		day       string
+		wantStart string
+		wantNext  string
+	}{
+		{"calendar month", DefaultBudgetPeriod(), "2026-03-15", "2026-03-01", "2026-04-01"},
+		{"after start day", BudgetPeriod{Kind: BudgetPeriodMonthly, StartDay: 25}, "2026-03-27", "2026-03-25", "2026-04-25"},
+		{"before start day", BudgetPeriod{Kind: BudgetPeriodMonthly, StartDay: 25}, "2026-01-10", "2025-12-25", "2026-01-25"},
+		{"custom", BudgetPeriod{Kind: BudgetPeriodCustom, Anchor: "2026-01-02", Days: 14}, "2026-01-20", "2026-01-16", "2026-01-30"},
+		{"custom on anchor", BudgetPeriod{Kind: BudgetPeriodCustom, Anchor: "2026-01-02", Days: 14}, "2026-01-02", "2026-01-02", "2026-01-16"},
+		{"custom before anchor", BudgetPeriod{Kind: BudgetPeriodCustom, Anchor: "2026-01-02", Days: 14}, "2025-12-25", "2025-12-19", "2026-01-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, _ := time.Parse("2006-01-02", tt.day)
+			start, next := tt.period.Bounds(day)
+			if got := start.Format("2006-01-02"); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := next.Format("2006-01-02"); got != tt.wantNext {
+				t.Errorf("next = %s, want %s", got, tt.wantNext)
+			}
+		})
+	}
+
+	if err := (BudgetPeriod{Kind: BudgetPeriodMonthly, StartDay: 31}).Validate(); err == nil {
+		t.Error("expected start day 31 to be invalid")
+	}
+}
+
+func TestSpace_BudgetRollover(t *testing.T) {
+	store, _ := newTestStore(t)
+	for _, env := range []*Envelope{
+		{Category: CategoryDining, Amount: Money{10000, "USD"}, Rollover: true, StartsOn: "2026-01-01"},
+		{Category: CategoryGroceries, Amount: Money{20000, "USD"}, StartsOn: "2026-01-01"},
+	} {
+		if err := store.SaveEnvelope(env); err != nil {
+			t.Fatalf("SaveEnvelope: %v", err)
+		}
+	}
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(store)
+	if err := space.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	space.transactions = []*CategorizedTransaction{
+		{Transaction: Transaction{TransactionID: "t1", Amount: 60, Date: "2026-01-10"}, QLCategory: CategoryDining},
+		{Transaction: Transaction{TransactionID: "t2", Amount: 250, Date: "2026-01-12"}, QLCategory: CategoryGroceries},
+		{Transaction: Transaction{TransactionID: "t3", Amount: 145, Date: "2026-02-03"}, QLCategory: CategoryDining},
+		{Transaction: Transaction{TransactionID: "t4", Amount: -15, Date: "2026-02-04"}, QLCategory: CategoryDining},
+		{Transaction: Transaction{TransactionID: "t5", Amount: -1000, Date: "2026-03-01"}, QLCategory: CategoryIncome},
+	}
+
+	report, err := space.BudgetReport(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("BudgetReport: %v", err)
+	}
+	if report.PeriodStart != "2026-03-01" || report.PeriodEnd != "2026-03-31" || len(report.Envelopes) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+	if report.Income.Minor != 100000 || report.Budgeted.Minor != 30000 || report.Unassigned.Minor != 70000 {
+		t.Errorf("income = %s, budgeted = %s, unassigned = %s", report.Income, report.Budgeted, report.Unassigned)
+	}
+
+	// Dining has 40 left in January and overspends by 30 in February,
+	// less the refund; groceries' overspending isn't carried
+	for _, st := range report.Envelopes {
+		want := map[Category]int64{CategoryDining: 11000, CategoryGroceries: 20000}[st.Category]
+		if st.Allowance().Minor != want {
+			t.Errorf("%s allowance = %s, want %d", st.Category, st.Allowance(), want)
+		}
+	}
+
+	history, err := store.BudgetHistory()
+	if err != nil {
+		t.Fatalf("BudgetHistory: %v", err)
+	}
+	if len(history) != 6 {
+		t.Errorf("recorded %d periods, want 6", len(history))
+	}
+
+	// A new amount applies from the current period; earlier ones keep
+	// what they were given, including after a reload
+	if _, err := space.SetBudget(CategoryDining, 150, true); err != nil {
+		t.Fatalf("SetBudget: %v", err)
+	}
+	reloaded := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	reloaded.SetStore(store)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	periods, err := reloaded.GetBudgetHistory(CategoryDining)
+	if err != nil {
+		t.Fatalf("GetBudgetHistory: %v", err)
+	}
+	if first, last := periods[0], periods[len(periods)-1]; first.PeriodStart != "2026-01-01" ||
+		first.Budgeted.Minor != 10000 || last.Budgeted.Minor != 15000 || !last.Rollover {
+		t.Errorf("first = %+v, last = %+v", first, last)
+	}
+
+	if err := reloaded.DeleteBudget(CategoryDining); err != nil {
+		t.Fatalf("DeleteBudget: %v", err)
+	}
+	if _, err := reloaded.GetBudgetHistory(CategoryDining); err == nil {
+		t.Error("expected deleted envelope to have no history")
+	}
+}
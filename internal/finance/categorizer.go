@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/quantumlife/quantumlife/internal/llm"
@@ -30,6 +31,7 @@ const (
 	CategoryTransfer       Category = "transfer"
 	CategoryInvestment     Category = "investment"
 	CategoryFees           Category = "fees"
+	CategoryCharity        Category = "charity"
 	CategoryOther          Category = "other"
 )
 
@@ -50,6 +52,7 @@ func AllCategories() []Category {
 		CategoryTransfer,
 		CategoryInvestment,
 		CategoryFees,
+		CategoryCharity,
 		CategoryOther,
 	}
 }
@@ -57,13 +60,20 @@ func AllCategories() []Category {
 // CategorizedTransaction extends Transaction with our categorization
 type CategorizedTransaction struct {
 	Transaction
-	QLCategory    Category  `json:"ql_category"`
-	Subcategory   string    `json:"subcategory,omitempty"`
-	IsRecurring   bool      `json:"is_recurring"`
-	RecurringID   string    `json:"recurring_id,omitempty"`
-	Confidence    float64   `json:"confidence"`
-	Tags          []string  `json:"tags,omitempty"`
-	CategorizedAt time.Time `json:"categorized_at"`
+	QLCategory      Category  `json:"ql_category"`
+	Subcategory     string    `json:"subcategory,omitempty"`
+	IsRecurring     bool      `json:"is_recurring"`
+	RecurringID     string    `json:"recurring_id,omitempty"`
+	Confidence      float64   `json:"confidence"`
+	RuleID          string    `json:"rule_id,omitempty"`          // User rule that set the category
+	UserCategorized bool      `json:"user_categorized,omitempty"` // Category chosen by the user
+	Tags            []string  `json:"tags,omitempty"`
+	CategorizedAt   time.Time `json:"categorized_at"`
+
+	Splits          []Split `json:"splits,omitempty"`           // Parts in other categories or hats
+	TransferID      string  `json:"transfer_id,omitempty"`      // Other side of a transfer between the user's accounts
+	Reimbursed      Money   `json:"reimbursed,omitzero"`        // Part of the expense paid back
+	ReimbursementID string  `json:"reimbursement_id,omitempty"` // Reimbursement this credit paid back
 }
 
 // Categorizer handles transaction categorization
@@ -72,6 +82,10 @@ type Categorizer struct {
 	keywordRules  map[Category][]string
 	regexRules    map[Category][]*regexp.Regexp
 	merchantCache map[string]Category
+
+	// User rules, in the order they are tried
+	rules   []*CategoryRule
+	rulesMu sync.RWMutex
 }
 
 // CategorizerConfig for the categorizer
@@ -159,6 +173,10 @@ func (c *Categorizer) initRules() {
 			"fee", "overdraft", "service charge", "atm fee", "foreign transaction",
 			"late fee", "annual fee",
 		},
+		CategoryCharity: {
+			"donation", "donate", "charity", "charitable", "red cross", "unicef",
+			"united way", "salvation army", "gofundme", "tithe", "givewell",
+		},
 	}
 
 	// Compile regex patterns for more complex matching
@@ -171,18 +189,59 @@ func (c *Categorizer) initRules() {
 	}
 }
 
-// Categorize categorizes a transaction
+// SetRules replaces the user rules. Every rule must be valid.
+func (c *Categorizer) SetRules(rules []*CategoryRule) error {
+	sorted := make([]*CategoryRule, len(rules))
+	copy(sorted, rules)
+	for _, rule := range sorted {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+	}
+	sortRules(sorted)
+
+	c.rulesMu.Lock()
+	defer c.rulesMu.Unlock()
+	c.rules = sorted
+	return nil
+}
+
+// Rules returns the user rules in the order they are tried
+func (c *Categorizer) Rules() []*CategoryRule {
+	c.rulesMu.RLock()
+	defer c.rulesMu.RUnlock()
+	return append([]*CategoryRule(nil), c.rules...)
+}
+
+// matchRule returns the first user rule that matches tx
+func (c *Categorizer) matchRule(tx Transaction) *CategoryRule {
+	c.rulesMu.RLock()
+	defer c.rulesMu.RUnlock()
+	for _, rule := range c.rules {
+		if rule.Matches(tx) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Categorize categorizes a transaction. User rules come first, then
+// Plaid's category, then keywords; RuleID reports the rule that fired.
 func (c *Categorizer) Categorize(tx Transaction) *CategorizedTransaction {
 	result := &CategorizedTransaction{
 		Transaction:   tx,
 		CategorizedAt: time.Now(),
 	}
 
-	// Check cache first
-	merchantKey := strings.ToLower(tx.MerchantName)
-	if merchantKey == "" {
-		merchantKey = strings.ToLower(tx.Name)
+	if rule := c.matchRule(tx); rule != nil {
+		result.QLCategory = rule.Category
+		result.RuleID = rule.ID
+		result.Confidence = 1.0
+		return result
 	}
+
+	// Check cache first
+	merchantKey := merchantKeyOf(tx)
 	if cached, ok := c.merchantCache[merchantKey]; ok {
 		result.QLCategory = cached
 		result.Confidence = 0.95
@@ -251,6 +310,10 @@ func (c *Categorizer) mapPlaidCategory(plaidCategories []string, pfc PersonalFin
 			return CategoryBills
 		case "rent_and_utilities":
 			return CategoryUtilities
+		case "government_and_non_profit":
+			if strings.Contains(strings.ToLower(pfc.Detailed), "donations") {
+				return CategoryCharity
+			}
 		}
 	}
 
@@ -337,7 +400,7 @@ func (c *Categorizer) CategorizeWithAI(ctx context.Context, tx Transaction) (*Ca
 
 	system := "You are a transaction categorizer. Respond with only the category name, nothing else."
 	prompt := `Categorize this transaction into exactly one of these categories:
-groceries, dining, transport, utilities, entertainment, shopping, health, travel, subscription, bills, income, transfer, investment, fees, other
+groceries, dining, transport, utilities, entertainment, shopping, health, travel, subscription, bills, income, transfer, investment, fees, charity, other
 
 Transaction:
 - Name: ` + tx.Name + `
@@ -569,11 +632,17 @@ func (d *RecurringDetector) predictNext(lastDate time.Time, frequency string, da
 	case "biweekly":
 		return lastDate.AddDate(0, 0, 14)
 	case "monthly":
-		next := lastDate.AddDate(0, 1, 0)
-		if dayOfMonth > 0 {
-			next = time.Date(next.Year(), next.Month(), dayOfMonth, 0, 0, 0, 0, next.Location())
+		// Step from the first of the month so a bill on the 31st doesn't
+		// skip short months
+		first := time.Date(lastDate.Year(), lastDate.Month()+1, 1, 0, 0, 0, 0, lastDate.Location())
+		day := dayOfMonth
+		if day <= 0 {
+			day = lastDate.Day()
+		}
+		if last := first.AddDate(0, 1, -1).Day(); day > last {
+			day = last
 		}
-		return next
+		return first.AddDate(0, 0, day-1)
 	case "quarterly":
 		return lastDate.AddDate(0, 3, 0)
 	case "annual":
@@ -0,0 +1,409 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Forecast horizons, in days
+const (
+	MinForecastDays     = 30
+	MaxForecastDays     = 90
+	DefaultForecastDays = 60
+)
+
+var (
+	// ErrInvalidForecast is returned for a forecast horizon out of range
+	ErrInvalidForecast = errors.New("invalid forecast")
+
+	// ErrInvalidPlannedExpense is returned for an incomplete planned expense
+	ErrInvalidPlannedExpense = errors.New("invalid planned expense")
+
+	// ErrPlannedExpenseNotFound is returned for an unknown planned expense
+	ErrPlannedExpenseNotFound = errors.New("planned expense not found")
+)
+
+// PlannedExpense is a one-off payment the user expects to make or, with a
+// negative amount, to receive
+type PlannedExpense struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Amount    Money     `json:"amount"`               // Positive is money out, as with transactions
+	Date      string    `json:"date"`                 // YYYY-MM-DD
+	AccountID string    `json:"account_id,omitempty"` // The main checking account if empty
+	Category  Category  `json:"category,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate checks the planned expense has what a forecast needs
+func (p *PlannedExpense) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("%w: a name is required", ErrInvalidPlannedExpense)
+	}
+	if p.Amount.IsZero() {
+		return fmt.Errorf("%w: an amount is required", ErrInvalidPlannedExpense)
+	}
+	if _, err := time.Parse("2006-01-02", p.Date); err != nil {
+		return fmt.Errorf("%w: invalid date %q", ErrInvalidPlannedExpense, p.Date)
+	}
+	if p.Category != "" && !IsValidCategory(p.Category) {
+		return fmt.Errorf("%w: unknown category %s", ErrInvalidPlannedExpense, p.Category)
+	}
+	return nil
+}
+
+// ForecastEntry is a payment expected on a forecast day
+type ForecastEntry struct {
+	Name     string   `json:"name"`
+	Amount   Money    `json:"amount"`    // Positive is money out
+	Source   string   `json:"source"`    // recurring, planned
+	SourceID string   `json:"source_id"` // Recurring transaction or planned expense
+	Category Category `json:"category,omitempty"`
+}
+
+// ForecastDay is an account's projected balance at the end of a day
+type ForecastDay struct {
+	Date    string          `json:"date"`
+	Balance Money           `json:"balance"`
+	Entries []ForecastEntry `json:"entries,omitempty"`
+}
+
+// AccountForecast projects one cash account's balance
+type AccountForecast struct {
+	AccountID  string        `json:"account_id"`
+	Name       string        `json:"name"`
+	Balance    Money         `json:"balance"` // Current balance the forecast starts from
+	Lowest     Money         `json:"lowest"`  // Lowest balance at the end of a day
+	LowestOn   string        `json:"lowest_on"`
+	NextPayday string        `json:"next_payday,omitempty"`
+	Days       []ForecastDay `json:"days"`
+}
+
+// ForecastWarning is an account projected to drop below the low balance
+// threshold before money next comes in
+type ForecastWarning struct {
+	AccountID   string   `json:"account_id"`
+	AccountName string   `json:"account_name"`
+	Date        string   `json:"date"`    // First day below the threshold
+	Balance     Money    `json:"balance"` // Balance at the end of that day
+	Threshold   Money    `json:"threshold"`
+	Payday      string   `json:"payday,omitempty"` // Next income after Date, if any is forecast
+	Bills       []string `json:"bills"`            // Payments since the last income that take it there
+}
+
+// Forecast projects cash account balances day by day from current
+// balances, recurring income and expenses, and planned expenses
+type Forecast struct {
+	From         string            `json:"from"`
+	To           string            `json:"to"`
+	Days         int               `json:"days"`
+	Threshold    Money             `json:"threshold"` // In the home currency
+	Accounts     []AccountForecast `json:"accounts"`
+	Warnings     []ForecastWarning `json:"warnings"`
+	MissingRates []string          `json:"missing_rates,omitempty"`
+}
+
+// Forecast projects each cash account's balance for the next days days,
+// DefaultForecastDays if zero
+func (s *Space) Forecast(days int) (*Forecast, error) {
+	if days == 0 {
+		days = DefaultForecastDays
+	}
+	if days < MinForecastDays || days > MaxForecastDays {
+		return nil, fmt.Errorf("%w: forecasts cover %d to %d days", ErrInvalidForecast, MinForecastDays, MaxForecastDays)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.forecast(time.Now(), days), nil
+}
+
+// forecast projects balances for days days starting on at's day. The
+// caller must hold the lock.
+func (s *Space) forecast(at time.Time, days int) *Forecast {
+	today := dayOf(at)
+	end := today.AddDate(0, 0, days)
+	home := s.insightsEngine.HomeCurrency()
+	missing := make(map[string]bool)
+
+	fc := &Forecast{
+		From:      today.Format("2006-01-02"),
+		To:        end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Days:      days,
+		Threshold: NewMoney(s.lowBalance, home),
+		Accounts:  []AccountForecast{},
+		Warnings:  []ForecastWarning{},
+	}
+
+	// Only cash accounts are forecast; card spending is paid off from them
+	accounts := make(map[string]*Account)
+	var cash []*Account
+	for i := range s.accounts {
+		acct := &s.accounts[i]
+		if acct.Type == "depository" {
+			accounts[acct.AccountID] = acct
+			cash = append(cash, acct)
+		}
+	}
+	if len(cash) == 0 {
+		return fc
+	}
+	main := cash[0]
+	for _, acct := range cash {
+		if acct.Subtype == "checking" {
+			main = acct
+			break
+		}
+	}
+
+	entries := make(map[string]map[string][]ForecastEntry)
+	add := func(accountID string, day time.Time, entry ForecastEntry) {
+		if entries[accountID] == nil {
+			entries[accountID] = make(map[string][]ForecastEntry)
+		}
+		key := day.Format("2006-01-02")
+		entries[accountID][key] = append(entries[accountID][key], entry)
+	}
+
+	// Recurring transactions are paid from the account their latest
+	// payment came from
+	byID := make(map[string]*CategorizedTransaction, len(s.transactions))
+	for _, tx := range s.transactions {
+		byID[tx.TransactionID] = tx
+	}
+	for _, rec := range s.recurring {
+		if !rec.IsActive || len(rec.Transactions) == 0 {
+			continue
+		}
+		last := byID[rec.Transactions[len(rec.Transactions)-1]]
+		if last == nil || accounts[last.AccountID] == nil {
+			continue
+		}
+		name := rec.MerchantName
+		if name == "" {
+			name = last.Name
+		}
+		currency := normalizeCurrency(accounts[last.AccountID].Balances.IsoCurrencyCode)
+		for _, day := range s.recurringDetector.occurrences(rec, today, end) {
+			add(last.AccountID, day, ForecastEntry{
+				Name:     name,
+				Amount:   NewMoney(rec.Amount, currency),
+				Source:   "recurring",
+				SourceID: rec.ID,
+				Category: rec.Category,
+			})
+		}
+	}
+
+	for _, p := range s.planned {
+		day, err := time.Parse("2006-01-02", p.Date)
+		if err != nil || day.Before(today) || !day.Before(end) {
+			continue
+		}
+		acct := main
+		if p.AccountID != "" {
+			if acct = accounts[p.AccountID]; acct == nil {
+				continue
+			}
+		}
+		amount, ok := s.exchangeTo(p.Amount, normalizeCurrency(acct.Balances.IsoCurrencyCode))
+		if !ok {
+			missing[p.Amount.Currency] = true
+			continue
+		}
+		add(acct.AccountID, day, ForecastEntry{
+			Name:     p.Name,
+			Amount:   amount,
+			Source:   "planned",
+			SourceID: p.ID,
+			Category: p.Category,
+		})
+	}
+
+	for _, acct := range cash {
+		currency := normalizeCurrency(acct.Balances.IsoCurrencyCode)
+		threshold, ok := s.exchangeTo(fc.Threshold, currency)
+		if !ok {
+			missing[currency] = true
+		}
+		af, warning := projectAccount(acct, currency, today, end, entries[acct.AccountID], threshold, ok)
+		fc.Accounts = append(fc.Accounts, af)
+		if warning != nil {
+			fc.Warnings = append(fc.Warnings, *warning)
+		}
+	}
+
+	fc.MissingRates = sortedKeys(missing)
+	return fc
+}
+
+// projectAccount walks an account's balance through each day's entries,
+// noting the first day it ends below threshold if warn is set
+func projectAccount(acct *Account, currency string, today, end time.Time, entries map[string][]ForecastEntry,
+	threshold Money, warn bool) (AccountForecast, *ForecastWarning) {
+	balance := NewMoney(acct.Balances.Current, currency)
+	af := AccountForecast{
+		AccountID: acct.AccountID,
+		Name:      acct.Name,
+		Balance:   balance,
+	}
+
+	var warning *ForecastWarning
+	bills := []string{}
+	for day := today; day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		dayEntries := entries[key]
+
+		// Income first, so a payday covers the bills due the same day
+		sort.SliceStable(dayEntries, func(i, j int) bool {
+			if (dayEntries[i].Amount.Minor < 0) != (dayEntries[j].Amount.Minor < 0) {
+				return dayEntries[i].Amount.Minor < 0
+			}
+			return dayEntries[i].Name < dayEntries[j].Name
+		})
+
+		for _, e := range dayEntries {
+			balance.Minor -= e.Amount.Minor
+			if e.Amount.Minor > 0 {
+				bills = append(bills, e.Name)
+				continue
+			}
+			if af.NextPayday == "" {
+				af.NextPayday = key
+			}
+			if warning != nil && warning.Payday == "" {
+				warning.Payday = key
+			}
+			bills = []string{}
+		}
+
+		af.Days = append(af.Days, ForecastDay{Date: key, Balance: balance, Entries: dayEntries})
+		if af.LowestOn == "" || balance.Minor < af.Lowest.Minor {
+			af.Lowest = balance
+			af.LowestOn = key
+		}
+		if warn && warning == nil && balance.Minor < threshold.Minor {
+			warning = &ForecastWarning{
+				AccountID:   acct.AccountID,
+				AccountName: acct.Name,
+				Date:        key,
+				Balance:     balance,
+				Threshold:   threshold,
+				Bills:       append([]string{}, bills...),
+			}
+		}
+	}
+	return af, warning
+}
+
+// exchangeTo converts m to currency at the cached rate, reporting false
+// if there is none. The caller must hold the lock.
+func (s *Space) exchangeTo(m Money, currency string) (Money, bool) {
+	if m.Currency == currency {
+		return m, true
+	}
+	if s.exchange == nil {
+		return Money{}, false
+	}
+	converted, _, err := s.exchange.Convert(m, currency)
+	return converted, err == nil
+}
+
+// occurrences lists the days rec is expected on from from up to, not
+// including, until. A payment a few days overdue is expected on from.
+func (d *RecurringDetector) occurrences(rec *RecurringTransaction, from, until time.Time) []time.Time {
+	if rec.NextExpected.IsZero() {
+		return nil
+	}
+
+	var days []time.Time
+	next := dayOf(rec.NextExpected)
+	if next.Before(from) && !next.Before(from.AddDate(0, 0, -d.varianceDays)) {
+		days = append(days, from)
+		next = d.predictNext(next, rec.Frequency, rec.DayOfMonth)
+	}
+	for next.Before(from) {
+		next = d.predictNext(next, rec.Frequency, rec.DayOfMonth)
+	}
+	for next.Before(until) {
+		days = append(days, next)
+		next = d.predictNext(next, rec.Frequency, rec.DayOfMonth)
+	}
+	return days
+}
+
+// dayOf is midnight UTC on t's calendar day
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// AddPlannedExpense validates and saves a planned expense. Its currency
+// defaults to the home currency.
+func (s *Space) AddPlannedExpense(p *PlannedExpense) error {
+	if p.ID == "" {
+		p.ID = "plan_" + uuid.New().String()
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if p.Amount.Currency == "" {
+		p.Amount.Currency = s.insightsEngine.HomeCurrency()
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if s.store != nil {
+		if err := s.store.SavePlannedExpense(p); err != nil {
+			return err
+		}
+	}
+
+	for i, existing := range s.planned {
+		if existing.ID == p.ID {
+			s.planned[i] = p
+			return nil
+		}
+	}
+	s.planned = append(s.planned, p)
+	sort.SliceStable(s.planned, func(i, j int) bool { return s.planned[i].Date < s.planned[j].Date })
+	return nil
+}
+
+// GetPlannedExpenses returns the planned expenses, soonest first
+func (s *Space) GetPlannedExpenses() []*PlannedExpense {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*PlannedExpense(nil), s.planned...)
+}
+
+// DeletePlannedExpense removes a planned expense
+func (s *Space) DeletePlannedExpense(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store != nil {
+		if err := s.store.DeletePlannedExpense(id); err != nil {
+			return err
+		}
+	}
+	for i, p := range s.planned {
+		if p.ID == id {
+			s.planned = append(s.planned[:i], s.planned[i+1:]...)
+			return nil
+		}
+	}
+	if s.store == nil {
+		return fmt.Errorf("%w: %s", ErrPlannedExpenseNotFound, id)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package finance
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSpace_Forecast(t *testing.T) {
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", LowBalanceThreshold: 1000})
+	space.connections = []*Connection{{
+		ID:     "conn_1",
+		Status: ConnectionStatusActive,
+		Accounts: []Account{
+			{AccountID: "sav", Name: "Savings", Type: "depository", Subtype: "savings", Balances: AccountBalance{Current: 5000, IsoCurrencyCode: "EUR"}},
+			{AccountID: "chk", Name: "Checking", Type: "depository", Subtype: "checking", Balances: AccountBalance{Current: 500, IsoCurrencyCode: "USD"}},
+			{AccountID: "card", Name: "Card", Type: "credit", Balances: AccountBalance{Current: 300, IsoCurrencyCode: "USD"}},
+		},
+	}}
+	space.refreshAccounts()
+	space.transactions = []*CategorizedTransaction{
+		{Transaction: Transaction{TransactionID: "t_rent", AccountID: "chk", Name: "Rent"}},
+		{Transaction: Transaction{TransactionID: "t_pay", AccountID: "chk", Name: "Payroll"}},
+		{Transaction: Transaction{TransactionID: "t_gym", AccountID: "chk", Name: "Gym"}},
+		{Transaction: Transaction{TransactionID: "t_stream", AccountID: "card", Name: "Streaming"}},
+	}
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	space.recurring = []*RecurringTransaction{
+		// Due on the 31st, so the 30th in April
+		{ID: "rec_rent", MerchantName: "Rent", Amount: 1200, Frequency: "monthly", DayOfMonth: 31,
+			NextExpected: day("2026-03-31"), Transactions: []string{"t_rent"}, IsActive: true},
+		// Four days late, so expected today
+		{ID: "rec_pay", MerchantName: "Payroll", Category: CategoryIncome, Amount: -1000, Frequency: "biweekly",
+			NextExpected: day("2026-03-06"), Transactions: []string{"t_pay"}, IsActive: true},
+		// Long past, so the schedule moves on
+		{ID: "rec_gym", MerchantName: "Gym", Amount: 50, Frequency: "monthly", DayOfMonth: 1,
+			NextExpected: day("2026-02-01"), Transactions: []string{"t_gym"}, IsActive: true},
+		{ID: "rec_old", MerchantName: "Old", Amount: 10, Frequency: "monthly",
+			NextExpected: day("2026-03-15"), Transactions: []string{"t_gym"}},
+		{ID: "rec_card", MerchantName: "Streaming", Amount: 15, Frequency: "monthly",
+			NextExpected: day("2026-03-15"), Transactions: []string{"t_stream"}, IsActive: true},
+	}
+	space.planned = []*PlannedExpense{
+		{ID: "plan_past", Name: "Flowers", Amount: Money{3000, "USD"}, Date: "2026-03-01"},
+		{ID: "plan_car", Name: "Car repair", Amount: Money{70000, "USD"}, Date: "2026-03-25"},
+	}
+
+	fc := space.forecast(day("2026-03-10"), 60)
+	if fc.From != "2026-03-10" || fc.To != "2026-05-08" || len(fc.Accounts) != 2 {
+		t.Fatalf("forecast = %+v", fc)
+	}
+
+	checking := fc.Accounts[1]
+	if len(checking.Days) != 60 {
+		t.Fatalf("checking has %d days, want 60", len(checking.Days))
+	}
+	entries := make(map[string][]string)
+	for _, d := range checking.Days {
+		for _, e := range d.Entries {
+			entries[d.Date] = append(entries[d.Date], e.SourceID)
+		}
+	}
+	want := map[string][]string{
+		"2026-03-10": {"rec_pay"},
+		"2026-03-20": {"rec_pay"},
+		"2026-03-25": {"plan_car"},
+		"2026-03-31": {"rec_rent"},
+		"2026-04-01": {"rec_gym"},
+		"2026-04-03": {"rec_pay"},
+		"2026-04-17": {"rec_pay"},
+		"2026-04-30": {"rec_rent"},
+		"2026-05-01": {"rec_pay", "rec_gym"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %v, want %v", entries, want)
+	}
+	if end := checking.Days[59].Balance; end != (Money{230000, "USD"}) {
+		t.Errorf("ending balance = %s, want $2300.00", end)
+	}
+	if checking.Lowest != (Money{55000, "USD"}) || checking.LowestOn != "2026-04-01" || checking.NextPayday != "2026-03-10" {
+		t.Errorf("checking = lowest %s on %s, payday %s", checking.Lowest, checking.LowestOn, checking.NextPayday)
+	}
+
+	// Savings can't be compared with the threshold without a rate
+	if !reflect.DeepEqual(fc.MissingRates, []string{"EUR"}) {
+		t.Errorf("missing rates = %v", fc.MissingRates)
+	}
+	if len(fc.Warnings) != 1 {
+		t.Fatalf("warnings = %+v", fc.Warnings)
+	}
+	w := fc.Warnings[0]
+	if w.AccountID != "chk" || w.Date != "2026-03-31" || w.Balance != (Money{60000, "USD"}) || w.Payday != "2026-04-03" ||
+		!reflect.DeepEqual(w.Bills, []string{"Car repair", "Rent"}) {
+		t.Errorf("warning = %+v", w)
+	}
+}
+
+func TestSpace_PlannedExpenses(t *testing.T) {
+	store, _ := newTestStore(t)
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", HomeCurrency: "EUR"})
+	space.SetStore(store)
+
+	if _, err := space.Forecast(10); !errors.Is(err, ErrInvalidForecast) {
+		t.Errorf("Forecast(10) error = %v, want ErrInvalidForecast", err)
+	}
+
+	if err := space.AddPlannedExpense(&PlannedExpense{Name: "Gift", Date: "2026-12-20"}); !errors.Is(err, ErrInvalidPlannedExpense) {
+		t.Errorf("expected a planned expense without an amount to be invalid, got %v", err)
+	}
+	later := &PlannedExpense{Name: "Holiday", Amount: Money{Minor: 90000}, Date: "2026-12-20"}
+	sooner := &PlannedExpense{Name: "Tax refund", Amount: Money{Minor: -40000}, Date: "2026-11-02", Note: "Last year's return"}
+	for _, p := range []*PlannedExpense{later, sooner} {
+		if err := space.AddPlannedExpense(p); err != nil {
+			t.Fatalf("AddPlannedExpense: %v", err)
+		}
+	}
+	if later.ID == "" || later.Amount.Currency != "EUR" {
+		t.Errorf("planned = %+v", later)
+	}
+
+	reloaded := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	reloaded.SetStore(store)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	planned := reloaded.GetPlannedExpenses()
+	if len(planned) != 2 || planned[0].ID != sooner.ID || planned[0].Note != "Last year's return" ||
+		planned[1].Amount != (Money{90000, "EUR"}) {
+		t.Errorf("planned = %+v", planned)
+	}
+
+	if err := reloaded.DeletePlannedExpense(sooner.ID); err != nil {
+		t.Fatalf("DeletePlannedExpense: %v", err)
+	}
+	if err := reloaded.DeletePlannedExpense(sooner.ID); !errors.Is(err, ErrPlannedExpenseNotFound) {
+		t.Errorf("second delete error = %v, want ErrPlannedExpenseNotFound", err)
+	}
+	if got := reloaded.GetPlannedExpenses(); len(got) != 1 || got[0].ID != later.ID {
+		t.Errorf("planned after delete = %+v", got)
+	}
+}
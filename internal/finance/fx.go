@@ -0,0 +1,358 @@
+package finance
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+// DefaultRatesURL serves daily ECB reference rates
+const DefaultRatesURL = "https://api.frankfurter.app"
+
+// maxRateAge is how old cached rates may get before they are refreshed
+// and, until that succeeds, reported as stale
+const maxRateAge = 24 * time.Hour
+
+// ErrNoRate is returned when no cached rate links two currencies
+var ErrNoRate = errors.New("no exchange rate")
+
+// RateSet is what one unit of Base buys in each quote currency, as
+// decimal strings
+type RateSet struct {
+	Base  string
+	Date  string // Day the rates are for, YYYY-MM-DD
+	Rates map[string]string
+}
+
+// RateProvider fetches current exchange rates
+type RateProvider interface {
+	LatestRates(ctx context.Context, base string) (*RateSet, error)
+}
+
+// HTTPRateProvider fetches rates from a Frankfurter-compatible API
+type HTTPRateProvider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPRateProvider creates a provider for the API at baseURL, or
+// DefaultRatesURL if it is empty
+func NewHTTPRateProvider(baseURL string) *HTTPRateProvider {
+	if baseURL == "" {
+		baseURL = DefaultRatesURL
+	}
+	return &HTTPRateProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// LatestRates fetches the latest rates for base
+func (p *HTTPRateProvider) LatestRates(ctx context.Context, base string) (*RateSet, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.baseURL+"/latest?from="+url.QueryEscape(base), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch rates: %s", resp.Status)
+	}
+
+	// Keep the rates as written rather than round-tripping them through
+	// float64
+	var body struct {
+		Base  string                 `json:"base"`
+		Date  string                 `json:"date"`
+		Rates map[string]json.Number `json:"rates"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode rates: %w", err)
+	}
+
+	set := &RateSet{Base: body.Base, Date: body.Date, Rates: make(map[string]string, len(body.Rates))}
+	for quote, rate := range body.Rates {
+		set.Rates[quote] = rate.String()
+	}
+	return set, nil
+}
+
+// Rate converts From to To
+type Rate struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Value     string    `json:"rate"`  // Units of To per unit of From
+	AsOf      string    `json:"as_of"` // Day the provider quoted the rate for
+	FetchedAt time.Time `json:"fetched_at"`
+	Stale     bool      `json:"stale"`
+
+	value *big.Rat
+}
+
+// Convert converts m, which must be in r.From, to r.To
+func (r *Rate) Convert(m Money) (Money, error) {
+	return moneyFromRat(new(big.Rat).Mul(m.rat(), r.value), r.To)
+}
+
+// Exchange converts between currencies with rates cached in the fx_rates
+// table. Rates are only fetched by Refresh, so conversions never wait on
+// the network.
+type Exchange struct {
+	db       *storage.DB
+	provider RateProvider
+	now      func() time.Time
+}
+
+// NewExchange creates an exchange that refreshes rates from provider
+func NewExchange(db *storage.DB, provider RateProvider) *Exchange {
+	return &Exchange{
+		db:       db,
+		provider: provider,
+		now:      time.Now,
+	}
+}
+
+// NeedsRefresh reports whether the cached rates for base are missing or
+// older than a day
+func (e *Exchange) NeedsRefresh(base string) bool {
+	var fetched time.Time
+	err := e.db.Conn().QueryRow(`
+		SELECT fetched_at FROM fx_rates WHERE base = ? ORDER BY fetched_at LIMIT 1
+	`, base).Scan(&fetched)
+	return err != nil || e.now().Sub(fetched) > maxRateAge
+}
+
+// Refresh fetches the latest rates for base and caches them
+func (e *Exchange) Refresh(ctx context.Context, base string) error {
+	set, err := e.provider.LatestRates(ctx, base)
+	if err != nil {
+		return err
+	}
+	if set.Base != "" && !strings.EqualFold(set.Base, base) {
+		return fmt.Errorf("asked for %s rates, got %s", base, set.Base)
+	}
+
+	now := e.now().UTC()
+	return e.db.Transaction(func(tx *sql.Tx) error {
+		for quote, rate := range set.Rates {
+			if _, ok := new(big.Rat).SetString(rate); !ok {
+				return fmt.Errorf("invalid %s rate: %q", quote, rate)
+			}
+			_, err := tx.Exec(`
+				INSERT INTO fx_rates (base, quote, rate, as_of, fetched_at)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT(base, quote) DO UPDATE SET
+					rate = excluded.rate,
+					as_of = excluded.as_of,
+					fetched_at = excluded.fetched_at
+			`, base, strings.ToUpper(quote), rate, set.Date, now)
+			if err != nil {
+				return fmt.Errorf("save %s rate: %w", quote, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Rate returns the cached rate from one currency to another, inverting
+// or crossing stored rates as needed
+func (e *Exchange) Rate(from, to string) (*Rate, error) {
+	if from == to {
+		return &Rate{From: from, To: to, Value: "1", FetchedAt: e.now(), value: big.NewRat(1, 1)}, nil
+	}
+
+	rates, err := e.ratesFor(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Direct, inverse, then crossed through a shared base
+	if r, ok := rates[[2]string{from, to}]; ok {
+		return e.rate(from, to, r.value, r), nil
+	}
+	if r, ok := rates[[2]string{to, from}]; ok {
+		return e.rate(from, to, new(big.Rat).Inv(r.value), r), nil
+	}
+	// Try the shared bases in order, so the same rates always give the
+	// same answer
+	var bases []string
+	for pair := range rates {
+		if pair[1] == from {
+			bases = append(bases, pair[0])
+		}
+	}
+	sort.Strings(bases)
+	for _, base := range bases {
+		a := rates[[2]string{base, from}]
+		b, ok := rates[[2]string{base, to}]
+		if !ok {
+			continue
+		}
+		oldest := a
+		if b.FetchedAt.Before(a.FetchedAt) {
+			oldest = b
+		}
+		return e.rate(from, to, new(big.Rat).Quo(b.value, a.value), oldest), nil
+	}
+
+	return nil, fmt.Errorf("%w from %s to %s", ErrNoRate, from, to)
+}
+
+// ratesFor loads the stored rates that involve either currency
+func (e *Exchange) ratesFor(from, to string) (map[[2]string]*Rate, error) {
+	rows, err := e.db.Conn().Query(`
+		SELECT base, quote, rate, COALESCE(as_of, ''), fetched_at
+		FROM fx_rates
+		WHERE quote IN (?, ?) OR base IN (?, ?)
+	`, from, to, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("query rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := make(map[[2]string]*Rate)
+	for rows.Next() {
+		r := &Rate{}
+		if err := rows.Scan(&r.From, &r.To, &r.Value, &r.AsOf, &r.FetchedAt); err != nil {
+			return nil, fmt.Errorf("scan rate: %w", err)
+		}
+		value, ok := new(big.Rat).SetString(r.Value)
+		if !ok || value.Sign() == 0 {
+			continue
+		}
+		r.value = value
+		rates[[2]string{r.From, r.To}] = r
+	}
+	return rates, rows.Err()
+}
+
+// rate builds the Rate from one currency to another out of value, dated
+// by the stored rate it came from
+func (e *Exchange) rate(from, to string, value *big.Rat, source *Rate) *Rate {
+	return &Rate{
+		From:      from,
+		To:        to,
+		Value:     value.FloatString(8),
+		AsOf:      source.AsOf,
+		FetchedAt: source.FetchedAt,
+		Stale:     e.now().Sub(source.FetchedAt) > maxRateAge,
+		value:     value,
+	}
+}
+
+// Convert converts m to the to currency at the cached rate
+func (e *Exchange) Convert(m Money, to string) (Money, *Rate, error) {
+	rate, err := e.Rate(m.Currency, to)
+	if err != nil {
+		return Money{}, nil, err
+	}
+	converted, err := rate.Convert(m)
+	if err != nil {
+		return Money{}, nil, err
+	}
+	return converted, rate, nil
+}
+
+// converter totals amounts in the home currency for one report, looking
+// each rate up once and noting the stale and missing ones
+type converter struct {
+	home     string
+	exchange *Exchange
+	rates    map[string]*Rate
+	stale    map[string]bool
+	missing  map[string]bool
+}
+
+func newConverter(home string, exchange *Exchange) *converter {
+	return &converter{
+		home:     normalizeCurrency(home),
+		exchange: exchange,
+		rates:    make(map[string]*Rate),
+		stale:    make(map[string]bool),
+		missing:  make(map[string]bool),
+	}
+}
+
+// convert converts m, taken to be in the home currency if it has no
+// currency, to the home currency. It reports false if there is no rate.
+func (c *converter) convert(m Money) (Money, bool) {
+	if m.Currency == "" {
+		m.Currency = c.home
+	}
+	if m.Currency == c.home {
+		return m, true
+	}
+
+	rate, ok := c.rates[m.Currency]
+	if !ok {
+		if c.exchange != nil {
+			rate, _ = c.exchange.Rate(m.Currency, c.home)
+		}
+		c.rates[m.Currency] = rate
+	}
+	if rate == nil {
+		c.missing[m.Currency] = true
+		return Money{Currency: c.home}, false
+	}
+	converted, err := rate.Convert(m)
+	if err != nil {
+		return Money{Currency: c.home}, false
+	}
+	if rate.Stale {
+		c.stale[m.Currency+"/"+c.home] = true
+	}
+	return converted, true
+}
+
+// money is amount, as Plaid reports it, in currency or the home currency
+// if that is empty
+func (c *converter) money(amount float64, currency string) Money {
+	if currency == "" {
+		currency = c.home
+	}
+	return NewMoney(amount, currency)
+}
+
+// zero is nothing in the home currency
+func (c *converter) zero() Money {
+	return Money{Currency: c.home}
+}
+
+// staleRates lists the pairs that were converted at stale rates
+func (c *converter) staleRates() []string {
+	return sortedKeys(c.stale)
+}
+
+// missingRates lists the currencies that had no rate and were left out
+func (c *converter) missingRates() []string {
+	return sortedKeys(c.missing)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
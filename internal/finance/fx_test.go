@@ -0,0 +1,184 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRatesServer serves EUR-based rates the way Frankfurter does
+func newRatesServer(t *testing.T, fetches *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(fetches, 1)
+		if r.URL.Path != "/latest" || r.URL.Query().Get("from") != "EUR" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"amount":1.0,"base":"EUR","date":"2026-10-16","rates":{"USD":1.25,"GBP":0.85,"JPY":160.5}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExchange_Rates(t *testing.T) {
+	_, db := newTestStore(t)
+	var fetches int32
+	server := newRatesServer(t, &fetches)
+
+	ex := NewExchange(db, NewHTTPRateProvider(server.URL))
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	ex.now = func() time.Time { return now }
+
+	if !ex.NeedsRefresh("EUR") {
+		t.Error("NeedsRefresh before any fetch = false")
+	}
+	if _, err := ex.Rate("USD", "EUR"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Rate before fetch error = %v, want ErrNoRate", err)
+	}
+	if err := ex.Refresh(context.Background(), "EUR"); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if ex.NeedsRefresh("EUR") {
+		t.Error("NeedsRefresh after fetch = true")
+	}
+
+	tests := []struct {
+		from, to string
+		amount   Money
+		want     Money
+	}{
+		{"EUR", "USD", Money{10000, "EUR"}, Money{12500, "USD"}}, // Direct
+		{"USD", "EUR", Money{12500, "USD"}, Money{10000, "EUR"}}, // Inverse
+		{"GBP", "USD", Money{8500, "GBP"}, Money{12500, "USD"}},  // Crossed through EUR
+		{"USD", "JPY", Money{125, "USD"}, Money{161, "JPY"}},     // 160.5 rounds half up
+	}
+	for _, tt := range tests {
+		got, rate, err := ex.Convert(tt.amount, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%s to %s): %v", tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.want || rate.Stale || rate.AsOf != "2026-10-16" {
+			t.Errorf("Convert(%s to %s) = %+v at %+v, want %+v", tt.from, tt.to, got, rate, tt.want)
+		}
+	}
+
+	// Rates turn stale after a day until refreshed
+	now = now.Add(25 * time.Hour)
+	if rate, _ := ex.Rate("USD", "EUR"); rate == nil || !rate.Stale {
+		t.Errorf("Rate after a day = %+v, want stale", rate)
+	}
+	if !ex.NeedsRefresh("EUR") {
+		t.Error("NeedsRefresh after a day = false")
+	}
+	if _, err := ex.Rate("USD", "CHF"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Rate(USD, CHF) error = %v, want ErrNoRate", err)
+	}
+}
+
+func TestExchange_CrossRateIsStable(t *testing.T) {
+	_, db := newTestStore(t)
+	ex := NewExchange(db, nil)
+
+	// GBP to JPY crosses through EUR or USD, which disagree slightly
+	now := time.Now().UTC()
+	for _, r := range [][3]string{{"EUR", "GBP", "0.85"}, {"EUR", "JPY", "160.5"}, {"USD", "GBP", "0.8"}, {"USD", "JPY", "150"}} {
+		if _, err := db.Conn().Exec(`INSERT INTO fx_rates (base, quote, rate, as_of, fetched_at) VALUES (?, ?, ?, ?, ?)`,
+			r[0], r[1], r[2], "2026-10-16", now); err != nil {
+			t.Fatalf("insert rate: %v", err)
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		rate, err := ex.Rate("GBP", "JPY")
+		if err != nil {
+			t.Fatalf("Rate: %v", err)
+		}
+		if rate.Value != "188.82352941" {
+			t.Fatalf("Rate(GBP, JPY) = %s, want the EUR cross rate every time", rate.Value)
+		}
+	}
+}
+
+func TestSpace_HomeCurrency(t *testing.T) {
+	_, db := newTestStore(t)
+	var fetches int32
+	server := newRatesServer(t, &fetches)
+
+	ex := NewExchange(db, NewHTTPRateProvider(server.URL))
+	now := time.Now()
+	ex.now = func() time.Time { return now }
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", HomeCurrency: "eur", Budgets: map[Category]float64{CategoryDining: 100}})
+	space.connections = []*Connection{{
+		ID:     "conn_1",
+		Status: ConnectionStatusActive,
+		Accounts: []Account{
+			{AccountID: "eur", Type: "depository", Balances: AccountBalance{Current: 1000, IsoCurrencyCode: "EUR"}},
+			{AccountID: "usd", Type: "depository", Balances: AccountBalance{Current: 250, IsoCurrencyCode: "USD"}},
+			{AccountID: "card", Type: "credit", Balances: AccountBalance{Current: 85, IsoCurrencyCode: "GBP"}},
+		},
+	}}
+	space.refreshAccounts()
+	space.connected = true
+	space.transactions = []*CategorizedTransaction{
+		{Transaction: Transaction{TransactionID: "t1", Name: "Bistro", Amount: 50, IsoCurrencyCode: "EUR"}, QLCategory: CategoryDining},
+		{Transaction: Transaction{TransactionID: "t2", Name: "Diner", Amount: 75, IsoCurrencyCode: "USD"}, QLCategory: CategoryDining},
+		{Transaction: Transaction{TransactionID: "t3", Name: "Salary", Amount: -2000}, QLCategory: CategoryIncome},
+	}
+
+	// Without rates foreign amounts are left out and reported missing
+	if worth := space.GetNetWorthReport(); worth.NetWorth != (Money{100000, "EUR"}) ||
+		!reflect.DeepEqual(worth.MissingRates, []string{"GBP", "USD"}) {
+		t.Errorf("net worth without rates = %+v", worth)
+	}
+
+	space.SetExchange(ex)
+	// Sync has nothing to sync but still refreshes the rates
+	if _, err := space.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("rate fetches = %d, want 1", fetches)
+	}
+
+	worth := space.GetNetWorthReport()
+	if worth.Currency != "EUR" || worth.Assets != (Money{120000, "EUR"}) || worth.Liabilities != (Money{10000, "EUR"}) ||
+		worth.NetWorth != (Money{110000, "EUR"}) || worth.StaleRates != nil || worth.MissingRates != nil {
+		t.Errorf("net worth = %+v", worth)
+	}
+	if balance := space.GetTotalBalance(); balance != 1300 {
+		t.Errorf("GetTotalBalance = %v, want 1300", balance)
+	}
+
+	summary := space.GetSpendingSummary("month")
+	if summary.Currency != "EUR" || summary.TotalSpent != 110 || summary.TotalIncome != 2000 ||
+		summary.NetCashFlow != 1890 || summary.ByCategory[CategoryDining] != 110 {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	insights := space.insightsEngine.GenerateCategoryInsights(space.transactions)
+	if len(insights) != 1 || insights[0].ID != "budget_over_dining" || !strings.Contains(insights[0].Description, "€110.00") {
+		t.Fatalf("budget insights = %+v", insights)
+	}
+
+	// A day later the rates are flagged until the next sync refreshes them
+	now = now.Add(25 * time.Hour)
+	if worth := space.GetNetWorthReport(); !reflect.DeepEqual(worth.StaleRates, []string{"GBP/EUR", "USD/EUR"}) {
+		t.Errorf("stale rates = %v", worth.StaleRates)
+	}
+	if summary := space.GetSpendingSummary("month"); !reflect.DeepEqual(summary.StaleRates, []string{"USD/EUR"}) {
+		t.Errorf("summary stale rates = %v", summary.StaleRates)
+	}
+	insights = space.insightsEngine.GenerateCategoryInsights(space.transactions)
+	if len(insights) != 1 || !strings.Contains(insights[0].Description, "stale USD/EUR") {
+		t.Errorf("budget insight = %+v", insights)
+	}
+}
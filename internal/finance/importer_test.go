@@ -0,0 +1,364 @@
+package finance
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/quantumlife/quantumlife/internal/identity"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+const testOFXSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<DTSERVER>20260201120000<LANGUAGE>ENG<FI><ORG>First Test Bank<FID>1234</FI></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>1<STATUS><CODE>0<SEVERITY>INFO</STATUS>
+<STMTRS><CURDEF>USD
+<BANKACCTFROM><BANKID>123456789<ACCTID>000111222333<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST><DTSTART>20260101<DTEND>20260131
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20260105120000.000[-5:EST]<TRNAMT>-4.50<FITID>T1<NAME>STARBUCKS #1234<MEMO></STMTTRN>
+<STMTTRN><TRNTYPE>CREDIT<DTPOSTED>20260115<TRNAMT>2500.00<FITID>T2<NAME>ACME PAYROLL</STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT<DTPOSTED>20260120<TRNAMT>-1,200.00<FITID>T3<NAME>RENT &amp; FEES</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>1295.50<DTASOF>20260131</LEDGERBAL>
+<AVAILBAL><BALAMT>1000.00<DTASOF>20260131</AVAILBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const testOFXXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <CCSTMTRS>
+        <CURDEF>EUR</CURDEF>
+        <CCACCTFROM><ACCTID>4111222233334444</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20260203</DTPOSTED>
+            <TRNAMT>-30.00</TRNAMT>
+            <FITID>X1</FITID>
+            <PAYEE><NAME>Bookshop</NAME><CITY>Berlin</CITY></PAYEE>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>-30.00</BALAMT><DTASOF>20260210</DTASOF></LEDGERBAL>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFX_SGML(t *testing.T) {
+	stmt, err := ParseOFX(strings.NewReader(testOFXSGML))
+	if err != nil {
+		t.Fatalf("ParseOFX: %v", err)
+	}
+
+	if stmt.Institution != "First Test Bank" {
+		t.Errorf("Institution = %q", stmt.Institution)
+	}
+	if a := stmt.Account; a.AccountID != "000111222333" || a.Mask != "2333" || a.Subtype != "checking" || a.Type != "depository" {
+		t.Errorf("Account = %+v", a)
+	}
+	if !stmt.HasBalance || stmt.Account.Balances.Current != 1295.50 || stmt.Account.Balances.IsoCurrencyCode != "USD" {
+		t.Errorf("Balances = %+v", stmt.Account.Balances)
+	}
+	if len(stmt.Transactions) != 3 {
+		t.Fatalf("Transactions = %d, want 3", len(stmt.Transactions))
+	}
+
+	tests := []struct {
+		id, date, name string
+		amount         float64
+	}{
+		{"T1", "2026-01-05", "STARBUCKS #1234", 4.50},
+		{"T2", "2026-01-15", "ACME PAYROLL", -2500},
+		{"T3", "2026-01-20", "RENT & FEES", 1200},
+	}
+	for i, tt := range tests {
+		tx := stmt.Transactions[i]
+		if tx.TransactionID != tt.id || tx.Date != tt.date || tx.Name != tt.name || tx.Amount != tt.amount || tx.IsoCurrencyCode != "USD" {
+			t.Errorf("transaction %d = %+v, want %+v", i, tx, tt)
+		}
+	}
+}
+
+func TestParseOFX_XMLCreditCard(t *testing.T) {
+	stmt, err := ParseOFX(strings.NewReader(testOFXXML))
+	if err != nil {
+		t.Fatalf("ParseOFX: %v", err)
+	}
+
+	if stmt.Account.Type != "credit" || stmt.Account.AccountID != "4111222233334444" {
+		t.Errorf("Account = %+v", stmt.Account)
+	}
+	// Owed card balances are positive, as Plaid reports them
+	if stmt.Account.Balances.Current != 30 {
+		t.Errorf("Current = %v, want 30", stmt.Account.Balances.Current)
+	}
+	if len(stmt.Transactions) != 1 {
+		t.Fatalf("Transactions = %d, want 1", len(stmt.Transactions))
+	}
+	if tx := stmt.Transactions[0]; tx.Name != "Bookshop" || tx.Amount != 30 || tx.IsoCurrencyCode != "EUR" {
+		t.Errorf("transaction = %+v", tx)
+	}
+}
+
+func TestParseOFX_NoAccount(t *testing.T) {
+	if _, err := ParseOFX(strings.NewReader("not a statement")); err == nil {
+		t.Error("expected error for non-OFX input")
+	}
+	if _, err := ParseOFX(strings.NewReader("<OFX><BANKTRANLIST></BANKTRANLIST></OFX>")); err == nil {
+		t.Error("expected error for statement without account")
+	}
+}
+
+func TestImporter_Parse_CSV(t *testing.T) {
+	im := NewImporter()
+
+	tests := []struct {
+		name    string
+		data    string
+		profile string
+		want    []float64
+	}{
+		{
+			name:    "generic",
+			data:    "Date,Description,Amount\n2026-01-05,Coffee,-4.50\n2026-01-06,Refund,\"1,000.00\"\n",
+			profile: "generic",
+			want:    []float64{4.50, -1000},
+		},
+		{
+			name:    "chase",
+			data:    "Transaction Date,Post Date,Description,Category,Type,Amount,Memo\n01/05/2026,01/06/2026,AMAZON MKTP,Shopping,Sale,-23.99,\n",
+			profile: "chase",
+			want:    []float64{23.99},
+		},
+		{
+			name:    "debit and credit",
+			data:    "Date,Description,Debit,Credit\n2026-01-05,Coffee,4.50,\n2026-01-06,Salary,,2500\n",
+			profile: "debit_credit",
+			want:    []float64{4.50, -2500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := im.Parse("statement.csv", strings.NewReader(tt.data), "")
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if stmt.Format != "csv" || len(stmt.Transactions) != len(tt.want) {
+				t.Fatalf("statement = %+v", stmt)
+			}
+			for i, want := range tt.want {
+				if got := stmt.Transactions[i].Amount; got != want {
+					t.Errorf("amount %d = %v, want %v", i, got, want)
+				}
+			}
+
+			// Naming the profile gives the same result as detecting it
+			named, err := im.Parse("statement.csv", strings.NewReader(tt.data), tt.profile)
+			if err != nil {
+				t.Fatalf("Parse with profile: %v", err)
+			}
+			if len(named.Transactions) != len(stmt.Transactions) {
+				t.Errorf("named profile parsed %d transactions, want %d", len(named.Transactions), len(stmt.Transactions))
+			}
+		})
+	}
+}
+
+func TestImporter_Parse_CustomProfile(t *testing.T) {
+	im := NewImporter(CSVProfile{
+		Name:            "mybank",
+		Delimiter:       ";",
+		SkipLines:       2,
+		Date:            "Buchungstag",
+		DateFormat:      "02.01.2006",
+		Description:     "Verwendungszweck",
+		Amount:          "Betrag",
+		Currency:        "Waehrung",
+		OutflowPositive: true,
+		DecimalComma:    true,
+	})
+
+	data := "Kontoauszug\nKonto 1234\nBuchungstag;Verwendungszweck;Betrag;Waehrung\n05.01.2026;Bäckerei;1.234,50;eur\n05.01.2026;Bäckerei;1.234,50;eur\n"
+	stmt, err := im.Parse("export.txt", strings.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(stmt.Transactions) != 2 {
+		t.Fatalf("Transactions = %d, want 2", len(stmt.Transactions))
+	}
+	tx := stmt.Transactions[0]
+	if tx.Date != "2026-01-05" || tx.Amount != 1234.50 || tx.IsoCurrencyCode != "EUR" || tx.Name != "Bäckerei" {
+		t.Errorf("transaction = %+v", tx)
+	}
+	// Identical rows without a bank ID still get distinct IDs
+	if stmt.Transactions[0].TransactionID == stmt.Transactions[1].TransactionID {
+		t.Error("identical rows share a transaction ID")
+	}
+}
+
+func TestImporter_Parse_Errors(t *testing.T) {
+	im := NewImporter()
+
+	if _, err := im.Parse("x.csv", strings.NewReader("Foo,Bar\n1,2\n"), ""); err == nil {
+		t.Error("expected error when no profile matches")
+	}
+	if _, err := im.Parse("x.csv", strings.NewReader("Date,Description,Amount\n"), "nope"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+	if _, err := im.Parse("x.csv", strings.NewReader("Date,Description,Amount\nyesterday,Coffee,1\n"), ""); err == nil {
+		t.Error("expected error for invalid date")
+	}
+	if _, err := im.Parse("x.csv", strings.NewReader("Date,Description,Amount\n2026-01-05,Coffee,abc\n"), ""); err == nil {
+		t.Error("expected error for invalid amount")
+	}
+
+	// Content is sniffed when the extension says nothing
+	stmt, err := im.Parse("download", strings.NewReader(testOFXSGML), "")
+	if err != nil || stmt.Format != "ofx" {
+		t.Errorf("Parse(OFX without extension) = %v, %v", stmt, err)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in           string
+		decimalComma bool
+		want         float64
+	}{
+		{"", false, 0},
+		{"12.34", false, 12.34},
+		{"-1,234.56", false, -1234.56},
+		{"$1,000", false, 1000},
+		{"(5.00)", false, -5},
+		{"1.234,56", true, 1234.56},
+		{"-0,99 €", true, -0.99},
+	}
+	for _, tt := range tests {
+		got, err := parseAmount(tt.in, tt.decimalComma)
+		if err != nil || got != tt.want {
+			t.Errorf("parseAmount(%q, %v) = %v, %v, want %v", tt.in, tt.decimalComma, got, err, tt.want)
+		}
+	}
+}
+
+func TestSpace_Import(t *testing.T) {
+	store, _ := newTestStore(t)
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(store)
+	ctx := context.Background()
+
+	// A transaction already synced from Plaid for the same purchase
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+	synced := &CategorizedTransaction{Transaction: testTransaction("plaid_1", "Starbucks", 4.50, "2026-01-06"), QLCategory: CategoryDining}
+	if err := store.ApplySync("conn_1", SyncChanges{Upserted: []*CategorizedTransaction{synced}}); err != nil {
+		t.Fatalf("ApplySync: %v", err)
+	}
+
+	stmt, err := ParseOFX(strings.NewReader(testOFXSGML))
+	if err != nil {
+		t.Fatalf("ParseOFX: %v", err)
+	}
+	result, err := space.Import(ctx, stmt)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if result.Parsed != 3 || result.Imported != 2 || result.Duplicates != 1 {
+		t.Errorf("result = %+v, want 3 parsed, 2 imported, 1 duplicate", result)
+	}
+	for _, tx := range result.Transactions {
+		if tx.QLCategory == "" || !strings.HasPrefix(tx.TransactionID, "imp_") || tx.AccountID != "000111222333" {
+			t.Errorf("imported transaction = %+v", tx)
+		}
+	}
+	if got := len(space.GetTransactions(TransactionFilter{})); got != 3 {
+		t.Errorf("transactions = %d, want 3", got)
+	}
+	if balance := space.GetTotalBalance(); balance != 100+1295.50 {
+		t.Errorf("GetTotalBalance = %v", balance)
+	}
+
+	// Importing the same statement again adds nothing
+	stmt, _ = ParseOFX(strings.NewReader(testOFXSGML))
+	result, err = space.Import(ctx, stmt)
+	if err != nil {
+		t.Fatalf("Import again: %v", err)
+	}
+	if result.Imported != 0 || result.Duplicates != 3 {
+		t.Errorf("re-import result = %+v, want 0 imported, 3 duplicates", result)
+	}
+
+	stored, err := store.Transactions()
+	if err != nil {
+		t.Fatalf("Transactions: %v", err)
+	}
+	if len(stored) != 3 {
+		t.Errorf("stored transactions = %d, want 3", len(stored))
+	}
+
+	// Imported accounts are never synced with Plaid
+	var imported *Connection
+	for _, conn := range space.GetConnections() {
+		if conn.InstitutionID == "import" {
+			imported = conn
+		}
+	}
+	if imported == nil || imported.AccessToken != "" || imported.InstitutionName != "First Test Bank" {
+		t.Errorf("imported connection = %+v", imported)
+	}
+}
+
+func TestSpace_Import_LockedIdentity(t *testing.T) {
+	db, err := storage.Open(storage.Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	mgr := identity.NewManager(storage.NewIdentityStore(db))
+	id, err := mgr.CreateIdentity("Test", "passphrase")
+	if err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+
+	// Statements carry no access token, so the identity may stay locked
+	store := NewStore(db, mgr, id.You.ID)
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(store)
+
+	data := "Date,Description,Amount\n2026-01-05,Coffee,-4.50\n"
+	stmt, err := NewImporter().Parse("s.csv", strings.NewReader(data), "")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	stmt.Account.AccountID = "savings"
+	if _, err := space.Import(context.Background(), stmt); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	reloaded := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	reloaded.SetStore(store)
+	if err := reloaded.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	txs := reloaded.GetTransactions(TransactionFilter{AccountID: "savings"})
+	if len(txs) != 1 || txs[0].Amount != 4.50 {
+		t.Errorf("reloaded transactions = %+v", txs)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/llm"
 )
 
@@ -51,14 +53,18 @@ type Insight struct {
 
 // SpendingSummary holds spending analysis
 type SpendingSummary struct {
-	Period       string              `json:"period"`
-	TotalSpent   float64             `json:"total_spent"`
-	TotalIncome  float64             `json:"total_income"`
-	NetCashFlow  float64             `json:"net_cash_flow"`
-	ByCategory   map[Category]float64 `json:"by_category"`
-	TopMerchants []MerchantSpend     `json:"top_merchants"`
-	DailyAverage float64             `json:"daily_average"`
-	Transactions int                 `json:"transactions"`
+	Period       string                 `json:"period"`
+	TotalSpent   float64                `json:"total_spent"`
+	TotalIncome  float64                `json:"total_income"`
+	NetCashFlow  float64                `json:"net_cash_flow"`
+	ByCategory   map[Category]float64   `json:"by_category"`
+	ByHat        map[core.HatID]float64 `json:"by_hat,omitempty"` // Spending split out to hats
+	TopMerchants []MerchantSpend        `json:"top_merchants"`
+	DailyAverage float64                `json:"daily_average"`
+	Transactions int                    `json:"transactions"`
+	Currency     string                 `json:"currency"`                // Home currency every amount is in
+	StaleRates   []string               `json:"stale_rates,omitempty"`   // Pairs converted at rates over a day old
+	MissingRates []string               `json:"missing_rates,omitempty"` // Currencies left out for want of a rate
 }
 
 // MerchantSpend tracks spending per merchant
@@ -86,6 +92,8 @@ type InsightsEngine struct {
 	llmClient     *llm.OllamaClient
 	budgets       map[Category]float64
 	alertRules    []AlertRule
+	homeCurrency  string
+	exchange      *Exchange
 }
 
 // AlertRule defines when to trigger an alert
@@ -96,15 +104,17 @@ type AlertRule struct {
 
 // InsightsConfig for the engine
 type InsightsConfig struct {
-	LLMClient *llm.OllamaClient
-	Budgets   map[Category]float64
+	LLMClient    *llm.OllamaClient
+	Budgets      map[Category]float64 // In the home currency
+	HomeCurrency string               // Defaults to DefaultCurrency
 }
 
 // NewInsightsEngine creates a new insights engine
 func NewInsightsEngine(cfg InsightsConfig) *InsightsEngine {
 	e := &InsightsEngine{
-		llmClient: cfg.LLMClient,
-		budgets:   cfg.Budgets,
+		llmClient:    cfg.LLMClient,
+		budgets:      cfg.Budgets,
+		homeCurrency: normalizeCurrency(cfg.HomeCurrency),
 	}
 	if e.budgets == nil {
 		e.budgets = make(map[Category]float64)
@@ -113,37 +123,82 @@ func NewInsightsEngine(cfg InsightsConfig) *InsightsEngine {
 	return e
 }
 
+// SetExchange sets the exchange used to convert foreign-currency
+// transactions to the home currency. Without one they are left out of
+// totals and reported as missing rates.
+func (e *InsightsEngine) SetExchange(exchange *Exchange) {
+	e.exchange = exchange
+}
+
+// HomeCurrency returns the currency summaries and budgets are in
+func (e *InsightsEngine) HomeCurrency() string {
+	return e.homeCurrency
+}
+
 // SetBudget sets a budget for a category
 func (e *InsightsEngine) SetBudget(category Category, amount float64) {
 	e.budgets[category] = amount
 }
 
+// DeleteBudget removes the budget for a category
+func (e *InsightsEngine) DeleteBudget(category Category) {
+	delete(e.budgets, category)
+}
+
 // GetBudget returns the budget for a category
 func (e *InsightsEngine) GetBudget(category Category) (float64, bool) {
 	amount, ok := e.budgets[category]
 	return amount, ok
 }
 
-// GenerateSpendingSummary creates a spending summary
+// GenerateSpendingSummary creates a spending summary. Amounts are
+// converted to the home currency and totalled exactly before being
+// reported as floats.
 func (e *InsightsEngine) GenerateSpendingSummary(transactions []*CategorizedTransaction, period string) *SpendingSummary {
 	summary := &SpendingSummary{
 		Period:       period,
 		ByCategory:   make(map[Category]float64),
+		ByHat:        make(map[core.HatID]float64),
 		Transactions: len(transactions),
+		Currency:     e.homeCurrency,
 	}
 
+	conv := newConverter(e.homeCurrency, e.exchange)
+	spent, income := conv.zero(), conv.zero()
+	byCategory := make(map[Category]Money)
+	byHat := make(map[core.HatID]int64)
 	merchantSpend := make(map[string]*MerchantSpend)
+	merchantTotals := make(map[string]Money)
 
 	for _, tx := range transactions {
-		amount := tx.Amount
+		// Money moved between the user's accounts, or paid back to them,
+		// is neither spent nor earned
+		if tx.TransferID != "" || tx.ReimbursementID != "" {
+			continue
+		}
+		amount, ok := conv.convert(conv.money(tx.Amount, tx.IsoCurrencyCode))
+		if !ok {
+			continue
+		}
 
-		if amount < 0 {
+		if amount.Minor < 0 {
 			// Income (Plaid uses negative for credits)
-			summary.TotalIncome += -amount
+			income.Minor -= amount.Minor
 		} else {
-			// Expense
-			summary.TotalSpent += amount
-			summary.ByCategory[tx.QLCategory] += amount
+			// Expense, less what was paid back, in each split's category
+			parts := shares(tx, amount)
+			amount.Minor = 0
+			for _, sh := range parts {
+				amount.Minor += sh.minor
+				total := byCategory[sh.category]
+				total.Currency = amount.Currency
+				total.Minor += sh.minor
+				byCategory[sh.category] = total
+				if sh.hatID != "" {
+					byHat[sh.hatID] += sh.minor
+				}
+			}
+			spent.Minor += amount.Minor
 
 			// Track merchant
 			merchant := tx.MerchantName
@@ -151,19 +206,28 @@ func (e *InsightsEngine) GenerateSpendingSummary(transactions []*CategorizedTran
 				merchant = tx.Name
 			}
 			if ms, ok := merchantSpend[merchant]; ok {
-				ms.Amount += amount
 				ms.Count++
 			} else {
-				merchantSpend[merchant] = &MerchantSpend{
-					Name:   merchant,
-					Amount: amount,
-					Count:  1,
-				}
+				merchantSpend[merchant] = &MerchantSpend{Name: merchant, Count: 1}
 			}
+			total := merchantTotals[merchant]
+			total.Currency = amount.Currency
+			total.Minor += amount.Minor
+			merchantTotals[merchant] = total
 		}
 	}
 
-	summary.NetCashFlow = summary.TotalIncome - summary.TotalSpent
+	summary.TotalSpent = spent.Float64()
+	summary.TotalIncome = income.Float64()
+	summary.NetCashFlow = Money{Minor: income.Minor - spent.Minor, Currency: e.homeCurrency}.Float64()
+	for category, total := range byCategory {
+		summary.ByCategory[category] = total.Float64()
+	}
+	for hat, total := range byHat {
+		summary.ByHat[hat] = Money{Minor: total, Currency: e.homeCurrency}.Float64()
+	}
+	summary.StaleRates = conv.staleRates()
+	summary.MissingRates = conv.missingRates()
 
 	// Calculate daily average based on period
 	days := e.periodToDays(period)
@@ -172,7 +236,8 @@ func (e *InsightsEngine) GenerateSpendingSummary(transactions []*CategorizedTran
 	}
 
 	// Top merchants
-	for _, ms := range merchantSpend {
+	for name, ms := range merchantSpend {
+		ms.Amount = merchantTotals[name].Float64()
 		summary.TopMerchants = append(summary.TopMerchants, *ms)
 	}
 	sort.Slice(summary.TopMerchants, func(i, j int) bool {
@@ -201,35 +266,51 @@ func (e *InsightsEngine) periodToDays(period string) int {
 	}
 }
 
-// GenerateCategoryInsights creates category-specific insights
+// GenerateCategoryInsights creates category-specific insights. Spending
+// is converted to the home currency the budgets are in.
 func (e *InsightsEngine) GenerateCategoryInsights(transactions []*CategorizedTransaction) []*Insight {
 	var insights []*Insight
 
-	// Group by category
-	byCategory := make(map[Category][]*CategorizedTransaction)
+	// Total expenses by category
+	conv := newConverter(e.homeCurrency, e.exchange)
+	byCategory := make(map[Category]Money)
 	for _, tx := range transactions {
-		if tx.Amount > 0 { // Only expenses
-			byCategory[tx.QLCategory] = append(byCategory[tx.QLCategory], tx)
+		if tx.Amount <= 0 || tx.TransferID != "" { // Only expenses
+			continue
+		}
+		amount, ok := conv.convert(conv.money(tx.Amount, tx.IsoCurrencyCode))
+		if !ok {
+			continue
 		}
+		for _, sh := range shares(tx, amount) {
+			total := byCategory[sh.category]
+			total.Currency = amount.Currency
+			total.Minor += sh.minor
+			byCategory[sh.category] = total
+		}
+	}
+
+	staleNote := ""
+	if stale := conv.staleRates(); len(stale) > 0 {
+		staleNote = fmt.Sprintf(" (converted at stale %s rates)", strings.Join(stale, ", "))
 	}
 
 	// Generate insights for each category
-	for category, txs := range byCategory {
-		var total float64
-		for _, tx := range txs {
-			total += tx.Amount
-		}
+	for category, spent := range byCategory {
+		total := spent.Float64()
 
 		// Check against budget
-		if budget, ok := e.budgets[category]; ok {
-			percentUsed := (total / budget) * 100
+		if budgetAmount, ok := e.budgets[category]; ok {
+			budget := NewMoney(budgetAmount, e.homeCurrency)
+			over := Money{Minor: spent.Minor - budget.Minor, Currency: e.homeCurrency}
+			percentUsed := (total / budgetAmount) * 100
 
 			if percentUsed >= 100 {
 				insights = append(insights, &Insight{
 					ID:          fmt.Sprintf("budget_over_%s", category),
 					Type:        InsightTypeBudgetAlert,
 					Title:       fmt.Sprintf("%s Budget Exceeded", capitalizeCategory(category)),
-					Description: fmt.Sprintf("You've spent $%.2f on %s, exceeding your $%.2f budget by $%.2f", total, category, budget, total-budget),
+					Description: fmt.Sprintf("You've spent %s on %s, exceeding your %s budget by %s%s", spent, category, budget, over, staleNote),
 					Severity:    SeverityAlert,
 					Amount:      total,
 					Category:    category,
@@ -240,7 +321,7 @@ func (e *InsightsEngine) GenerateCategoryInsights(transactions []*CategorizedTra
 					ID:          fmt.Sprintf("budget_warning_%s", category),
 					Type:        InsightTypeBudgetAlert,
 					Title:       fmt.Sprintf("%s Budget Warning", capitalizeCategory(category)),
-					Description: fmt.Sprintf("You've used %.0f%% of your %s budget ($%.2f of $%.2f)", percentUsed, category, total, budget),
+					Description: fmt.Sprintf("You've used %.0f%% of your %s budget (%s of %s)%s", percentUsed, category, spent, budget, staleNote),
 					Severity:    SeverityWarning,
 					Amount:      total,
 					Category:    category,
@@ -260,7 +341,7 @@ func (e *InsightsEngine) DetectAnomalies(transactions []*CategorizedTransaction)
 	// Group by category
 	byCategory := make(map[Category][]float64)
 	for _, tx := range transactions {
-		if tx.Amount > 0 {
+		if tx.Amount > 0 && tx.TransferID == "" {
 			byCategory[tx.QLCategory] = append(byCategory[tx.QLCategory], tx.Amount)
 		}
 	}
@@ -275,7 +356,7 @@ func (e *InsightsEngine) DetectAnomalies(transactions []*CategorizedTransaction)
 		threshold := avg * 2
 
 		for i, tx := range transactions {
-			if tx.QLCategory == category && tx.Amount > threshold && tx.Amount > 100 {
+			if tx.QLCategory == category && tx.Amount > threshold && tx.Amount > 100 && tx.TransferID == "" {
 				insights = append(insights, &Insight{
 					ID:          fmt.Sprintf("anomaly_%d", i),
 					Type:        InsightTypeAnomaly,
@@ -329,30 +410,18 @@ func (e *InsightsEngine) FindSavingsOpportunities(transactions []*CategorizedTra
 	var insights []*Insight
 
 	// Find overlapping subscriptions
-	subscriptionCategories := []Category{CategorySubscription, CategoryEntertainment}
 	var subs []*RecurringTransaction
 	for _, rec := range recurring {
-		for _, cat := range subscriptionCategories {
-			if rec.Category == cat {
-				subs = append(subs, rec)
-				break
-			}
+		if isSubscriptionCategory(rec.Category) {
+			subs = append(subs, rec)
 		}
 	}
 
 	if len(subs) >= 3 {
 		var monthlyTotal float64
 		for _, sub := range subs {
-			switch sub.Frequency {
-			case "weekly":
-				monthlyTotal += sub.Amount * 4.33
-			case "biweekly":
-				monthlyTotal += sub.Amount * 2.17
-			case "monthly":
-				monthlyTotal += sub.Amount
-			case "annual":
-				monthlyTotal += sub.Amount / 12
-			}
+			factor, _ := monthlyFactor(sub.Frequency).Float64()
+			monthlyTotal += sub.Amount * factor
 		}
 
 		insights = append(insights, &Insight{
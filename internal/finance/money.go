@@ -0,0 +1,185 @@
+package finance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the home currency when none is configured
+const DefaultCurrency = "USD"
+
+// Money is an exact amount in a currency, held in the currency's minor
+// units (cents for USD) so that sums don't accumulate float error
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// minorDigits lists currencies whose minor unit isn't a hundredth
+var minorDigits = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// currencySymbols are used when formatting amounts for people
+var currencySymbols = map[string]string{
+	"USD": "$", "EUR": "€", "GBP": "£", "JPY": "¥", "INR": "₹",
+}
+
+// CurrencyDigits returns how many decimal places currency has
+func CurrencyDigits(currency string) int {
+	if d, ok := minorDigits[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// NewMoney converts a float amount, as Plaid reports it, to Money. The
+// shortest decimal that round-trips is what Plaid sent, so it is rounded
+// from that rather than from the binary value.
+func NewMoney(amount float64, currency string) Money {
+	currency = normalizeCurrency(currency)
+	if r, ok := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64)); ok {
+		if m, err := moneyFromRat(r, currency); err == nil {
+			return m
+		}
+	}
+	scale := math.Pow10(CurrencyDigits(currency))
+	return Money{Minor: int64(math.Round(amount * scale)), Currency: currency}
+}
+
+// ParseMoney parses a decimal amount such as "-12.34"
+func ParseMoney(amount, currency string) (Money, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return Money{}, fmt.Errorf("invalid amount: %q", amount)
+	}
+	currency = normalizeCurrency(currency)
+	return moneyFromRat(r, currency)
+}
+
+// ErrAmountOverflow is returned for amounts too large to hold in minor units
+var ErrAmountOverflow = errors.New("amount out of range")
+
+// moneyFromRat rounds r, in major units, to the nearest minor unit,
+// halves away from zero
+func moneyFromRat(r *big.Rat, currency string) (Money, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(CurrencyDigits(currency))), nil)))
+	num, den := scaled.Num(), scaled.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s %s", ErrAmountOverflow, r.FloatString(CurrencyDigits(currency)), currency)
+	}
+	return Money{Minor: q.Int64(), Currency: currency}, nil
+}
+
+// mul returns m times f, rounded to the nearest minor unit
+func (m Money) mul(f *big.Rat) (Money, error) {
+	return moneyFromRat(new(big.Rat).Mul(m.rat(), f), m.Currency)
+}
+
+// rat returns the amount in major units
+func (m Money) rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Minor), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(CurrencyDigits(m.Currency))), nil))
+}
+
+// Add returns m + o. Both must be in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return m, fmt.Errorf("cannot add %s to %s", o.Currency, m.Currency)
+	}
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}, nil
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// Float64 returns the amount in major units, for display and for the
+// float-based APIs that predate Money
+func (m Money) Float64() float64 {
+	return float64(m.Minor) / math.Pow10(CurrencyDigits(m.Currency))
+}
+
+// Decimal formats the amount without currency, e.g. "-12.34"
+func (m Money) Decimal() string {
+	digits := CurrencyDigits(m.Currency)
+	minor := m.Minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if digits == 0 {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+	scale := int64(math.Pow10(digits))
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, digits, minor%scale)
+}
+
+// String formats the amount with its currency, e.g. "$12.34" or
+// "12.34 CHF"
+func (m Money) String() string {
+	if symbol, ok := currencySymbols[m.Currency]; ok {
+		if m.Minor < 0 {
+			return "-" + symbol + m.Neg().Decimal()
+		}
+		return symbol + m.Decimal()
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// MarshalJSON encodes the amount as a decimal string so no precision is
+// lost, e.g. {"amount":"12.34","currency":"USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Currency})
+}
+
+// UnmarshalJSON decodes the form MarshalJSON writes
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	parsed, err := ParseMoney(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// normalizeCurrency upper-cases a currency code, defaulting to
+// DefaultCurrency
+func normalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
@@ -0,0 +1,94 @@
+package finance
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     Money
+		str      string
+	}{
+		{12.34, "USD", Money{1234, "USD"}, "$12.34"},
+		{-0.1 - 0.2, "eur", Money{-30, "EUR"}, "-€0.30"},
+		{1500, "JPY", Money{1500, "JPY"}, "¥1500"},
+		{1.2345, "KWD", Money{1235, "KWD"}, "1.235 KWD"},
+		{1.005, "USD", Money{101, "USD"}, "$1.01"}, // 100.49999999999999 cents as a float
+		{5, "", Money{500, DefaultCurrency}, "$5.00"},
+	}
+
+	for _, tt := range tests {
+		got := NewMoney(tt.amount, tt.currency)
+		if got != tt.want {
+			t.Errorf("NewMoney(%v, %q) = %+v, want %+v", tt.amount, tt.currency, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("%+v.String() = %q, want %q", got, got.String(), tt.str)
+		}
+	}
+}
+
+func TestMoney_Sum(t *testing.T) {
+	// A float sum of ten dimes is 0.9999999999999999
+	total := NewMoney(0, "USD")
+	for i := 0; i < 10; i++ {
+		var err error
+		if total, err = total.Add(NewMoney(0.10, "USD")); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if total.Float64() != 1 || total.Decimal() != "1.00" {
+		t.Errorf("total = %s, want 1.00", total.Decimal())
+	}
+
+	if _, err := total.Add(NewMoney(1, "EUR")); err == nil {
+		t.Error("Add mixed currencies without error")
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"12.345", 1235},
+		{"-12.345", -1235},
+		{"0.004", 0},
+		{"7", 700},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.in, "USD")
+		if err != nil || got.Minor != tt.want {
+			t.Errorf("ParseMoney(%q) = %+v, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseMoney("12,34", "USD"); err == nil {
+		t.Error("ParseMoney accepted a comma")
+	}
+	if _, err := ParseMoney("1e20", "USD"); !errors.Is(err, ErrAmountOverflow) {
+		t.Errorf("ParseMoney(1e20) error = %v, want ErrAmountOverflow", err)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(NewMoney(-1234.5, "GBP"))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `{"amount":"-1234.50","currency":"GBP"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+
+	var m Money
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if m != (Money{-123450, "GBP"}) {
+		t.Errorf("Unmarshal = %+v", m)
+	}
+}
@@ -30,6 +30,8 @@ type PlaidConfig struct {
 	CountryCodes []string
 	Products     []string
 	Language     string
+	BaseURL      string // Overrides the environment's API host if set
+	WebhookURL   string // Where Plaid sends item and transaction webhooks
 }
 
 // DefaultPlaidConfig returns sandbox configuration
@@ -42,6 +44,7 @@ func DefaultPlaidConfig() PlaidConfig {
 		CountryCodes: []string{"US"},
 		Products:     []string{"transactions"},
 		Language:     "en",
+		WebhookURL:   os.Getenv("PLAID_WEBHOOK_URL"),
 	}
 }
 
@@ -70,6 +73,9 @@ func NewPlaidClient(cfg PlaidConfig) *PlaidClient {
 	default:
 		baseURL = "https://sandbox.plaid.com"
 	}
+	if cfg.BaseURL != "" {
+		baseURL = cfg.BaseURL
+	}
 
 	return &PlaidClient{
 		config: cfg,
@@ -167,6 +173,9 @@ func (c *PlaidClient) CreateLinkToken(ctx context.Context, userID string) (*Link
 		"country_codes": c.config.CountryCodes,
 		"language":      c.config.Language,
 	}
+	if c.config.WebhookURL != "" {
+		req["webhook"] = c.config.WebhookURL
+	}
 
 	var resp LinkTokenResponse
 	if err := c.request(ctx, "/link/token/create", req, &resp); err != nil {
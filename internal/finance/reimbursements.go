@@ -0,0 +1,282 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidReimbursement is returned for reimbursements that aren't
+	// part of an expense
+	ErrInvalidReimbursement = errors.New("invalid reimbursement")
+
+	// ErrReimbursementNotFound is returned for unknown reimbursement IDs
+	ErrReimbursementNotFound = errors.New("reimbursement not found")
+)
+
+// reimbursementWindow is how many days after an expense its
+// reimbursement may arrive
+const reimbursementWindow = 90
+
+// ReimbursementStatus says whether a reimbursement has been paid
+type ReimbursementStatus string
+
+const (
+	ReimbursementPending  ReimbursementStatus = "pending"
+	ReimbursementReceived ReimbursementStatus = "received"
+)
+
+// Reimbursement is money the user expects back for an expense, such as a
+// work trip or a shop for someone else
+type Reimbursement struct {
+	ID            string              `json:"id"`
+	TransactionID string              `json:"transaction_id"` // The expense
+	Amount        Money               `json:"amount"`         // Expected back, in the expense's currency
+	Payer         string              `json:"payer,omitempty"`
+	Note          string              `json:"note,omitempty"`
+	Status        ReimbursementStatus `json:"status"`
+	CreditID      string              `json:"credit_id,omitempty"` // The credit that paid it back
+	ReceivedOn    string              `json:"received_on,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+// ReimbursementReport lists reimbursements, oldest expense first, with
+// the total still owed
+type ReimbursementReport struct {
+	Currency       string           `json:"currency"`
+	Reimbursements []*Reimbursement `json:"reimbursements"`
+	Outstanding    Money            `json:"outstanding"`
+	MissingRates   []string         `json:"missing_rates,omitempty"`
+}
+
+// ExpectReimbursement marks amount of an expense as owed back by payer,
+// replacing any earlier expectation for it. Zero expects the whole
+// expense back. The reimbursement is matched to a later credit of the
+// same amount, preferring credits that name the payer.
+func (s *Space) ExpectReimbursement(transactionID string, amount float64, payer, note string) (*Reimbursement, error) {
+	s.mu.Lock()
+	var tx *CategorizedTransaction
+	for _, t := range s.transactions {
+		if t.TransactionID == transactionID {
+			tx = t
+			break
+		}
+	}
+	if tx == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+	if tx.Amount <= 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is not an expense", ErrInvalidReimbursement, transactionID)
+	}
+	if amount == 0 {
+		amount = tx.Amount
+	}
+	if amount < 0 || amount > tx.Amount {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: amount must be between 0 and %.2f", ErrInvalidReimbursement, tx.Amount)
+	}
+
+	r := &Reimbursement{
+		ID:            "rmb_" + uuid.New().String(),
+		TransactionID: transactionID,
+		Amount:        NewMoney(amount, tx.IsoCurrencyCode),
+		Payer:         strings.TrimSpace(payer),
+		Note:          note,
+		Status:        ReimbursementPending,
+		CreatedAt:     time.Now(),
+	}
+	index := -1
+	for i, old := range s.reimbursements {
+		if old.TransactionID == transactionID {
+			index = i
+			r.ID, r.CreatedAt = old.ID, old.CreatedAt
+		}
+	}
+
+	store := s.store
+	if store != nil {
+		if err := store.SaveReimbursement(r); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+	if index >= 0 {
+		s.reimbursements[index] = r
+	} else {
+		s.reimbursements = append(s.reimbursements, r)
+	}
+	recurring, insights := s.analyze()
+	s.mu.Unlock()
+
+	for _, err := range saveAnalysis(store, recurring, insights) {
+		fmt.Printf("Warning: failed to save analysis: %v\n", err)
+	}
+	return r, nil
+}
+
+// GetReimbursements reports the expected reimbursements and what is
+// still owed, in the home currency
+func (s *Space) GetReimbursements() *ReimbursementReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	report := &ReimbursementReport{
+		Currency:       conv.home,
+		Reimbursements: make([]*Reimbursement, 0, len(s.reimbursements)),
+		Outstanding:    conv.zero(),
+	}
+	for _, r := range s.reimbursements {
+		copied := *r
+		report.Reimbursements = append(report.Reimbursements, &copied)
+		if r.Status != ReimbursementPending {
+			continue
+		}
+		if owed, ok := conv.convert(r.Amount); ok {
+			report.Outstanding.Minor += owed.Minor
+		}
+	}
+	report.MissingRates = conv.missingRates()
+	return report
+}
+
+// DeleteReimbursement stops expecting a reimbursement
+func (s *Space) DeleteReimbursement(id string) error {
+	s.mu.Lock()
+	index := -1
+	for i, r := range s.reimbursements {
+		if r.ID == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrReimbursementNotFound, id)
+	}
+
+	store := s.store
+	if store != nil {
+		if err := store.DeleteReimbursement(id); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+	s.reimbursements = append(s.reimbursements[:index], s.reimbursements[index+1:]...)
+	recurring, insights := s.analyze()
+	s.mu.Unlock()
+
+	for _, err := range saveAnalysis(store, recurring, insights) {
+		fmt.Printf("Warning: failed to save analysis: %v\n", err)
+	}
+	return nil
+}
+
+// matchReimbursements links expenses with the credits that paid them
+// back, and matches pending reimbursements to new credits. It returns
+// the reimbursements whose match changed. The caller must hold the lock.
+func (s *Space) matchReimbursements() []*Reimbursement {
+	byID := make(map[string]*CategorizedTransaction, len(s.transactions))
+	for _, tx := range s.transactions {
+		tx.Reimbursed = Money{}
+		tx.ReimbursementID = ""
+		byID[tx.TransactionID] = tx
+	}
+
+	// Oldest expense first, so earlier reimbursements get earlier credits
+	sort.SliceStable(s.reimbursements, func(i, j int) bool {
+		a, b := byID[s.reimbursements[i].TransactionID], byID[s.reimbursements[j].TransactionID]
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Date < b.Date
+	})
+
+	var changed []*Reimbursement
+	link := func(r *Reimbursement, expense, credit *CategorizedTransaction) {
+		expense.Reimbursed = r.Amount
+		credit.ReimbursementID = r.ID
+		if r.CreditID != credit.TransactionID {
+			r.CreditID = credit.TransactionID
+			r.ReceivedOn = credit.Date
+			r.Status = ReimbursementReceived
+			changed = append(changed, r)
+		}
+	}
+
+	// Keep earlier matches whose credit is still there
+	for _, r := range s.reimbursements {
+		expense, credit := byID[r.TransactionID], byID[r.CreditID]
+		if expense != nil && credit != nil && r.CreditID != "" {
+			link(r, expense, credit)
+			continue
+		}
+		if r.CreditID != "" {
+			r.CreditID, r.ReceivedOn, r.Status = "", "", ReimbursementPending
+			changed = append(changed, r)
+		}
+	}
+
+	for _, r := range s.reimbursements {
+		expense := byID[r.TransactionID]
+		if r.CreditID != "" || expense == nil {
+			continue
+		}
+		if credit := findReimbursement(r, expense, s.transactions); credit != nil {
+			link(r, expense, credit)
+		}
+	}
+	return changed
+}
+
+// findReimbursement returns the earliest unclaimed credit of r's amount
+// in the window after its expense, preferring credits that name the payer
+func findReimbursement(r *Reimbursement, expense *CategorizedTransaction, transactions []*CategorizedTransaction) *CategorizedTransaction {
+	day, err := time.Parse("2006-01-02", expense.Date)
+	if err != nil {
+		return nil
+	}
+	until := day.AddDate(0, 0, reimbursementWindow).Format("2006-01-02")
+	payer := strings.ToLower(r.Payer)
+
+	var best *CategorizedTransaction
+	bestNamed := false
+	for _, tx := range transactions {
+		if tx.Amount >= 0 || tx.TransferID != "" || tx.ReimbursementID != "" ||
+			tx.Date < expense.Date || tx.Date > until ||
+			NewMoney(-tx.Amount, tx.IsoCurrencyCode) != r.Amount {
+			continue
+		}
+		named := payer != "" && strings.Contains(strings.ToLower(tx.Name+" "+tx.MerchantName), payer)
+		switch {
+		case best == nil, named && !bestNamed:
+		case named == bestNamed && (tx.Date < best.Date || (tx.Date == best.Date && tx.TransactionID < best.TransactionID)):
+		default:
+			continue
+		}
+		best, bestNamed = tx, named
+	}
+	return best
+}
+
+// linkTransactions pairs transfers between the user's accounts and
+// matches reimbursements, saving changed matches. The caller must hold
+// the lock.
+func (s *Space) linkTransactions() {
+	matchTransfers(s.transactions)
+	for _, r := range s.matchReimbursements() {
+		if s.store == nil {
+			break
+		}
+		if err := s.store.SaveReimbursement(r); err != nil {
+			fmt.Printf("Warning: failed to save reimbursement %s: %v\n", r.ID, err)
+		}
+	}
+}
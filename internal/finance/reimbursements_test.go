@@ -0,0 +1,89 @@
+package finance
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSpace_Reimbursements(t *testing.T) {
+	space, store := newLinkedSpace(t,
+		categorized("hotel", "acc_2", "Grand Hotel", 300, "2026-03-01", CategoryTravel),
+		categorized("dinner", "acc_1", "Bistro", 90, "2026-03-05", CategoryDining),
+		categorized("early", "acc_1", "Cash deposit", -300, "2026-02-25", CategoryIncome),
+		categorized("zelle", "acc_1", "Zelle from Sam", -300, "2026-03-10", CategoryIncome),
+		categorized("acme", "acc_1", "ACME CORP EXPENSES", -300, "2026-03-20", CategoryIncome),
+	)
+
+	if _, err := space.ExpectReimbursement("zelle", 0, "", ""); !errors.Is(err, ErrInvalidReimbursement) {
+		t.Errorf("reimbursing a credit: error = %v, want ErrInvalidReimbursement", err)
+	}
+	if _, err := space.ExpectReimbursement("dinner", 120, "", ""); !errors.Is(err, ErrInvalidReimbursement) {
+		t.Errorf("reimbursing more than the expense: error = %v, want ErrInvalidReimbursement", err)
+	}
+
+	// The credit from the payer wins over an earlier one of the same amount
+	hotel, err := space.ExpectReimbursement("hotel", 0, "Acme", "Conference")
+	if err != nil {
+		t.Fatalf("ExpectReimbursement: %v", err)
+	}
+	if hotel.Status != ReimbursementReceived || hotel.CreditID != "acme" || hotel.ReceivedOn != "2026-03-20" ||
+		hotel.Amount != (Money{30000, "USD"}) {
+		t.Errorf("hotel reimbursement = %+v", hotel)
+	}
+	dinner, err := space.ExpectReimbursement("dinner", 45, "Sam", "")
+	if err != nil {
+		t.Fatalf("ExpectReimbursement: %v", err)
+	}
+	if dinner.Status != ReimbursementPending {
+		t.Errorf("dinner reimbursement = %+v, want pending", dinner)
+	}
+
+	report := space.GetReimbursements()
+	if len(report.Reimbursements) != 2 || report.Outstanding != (Money{4500, "USD"}) {
+		t.Errorf("report = %+v", report)
+	}
+
+	// The hotel was paid back, so is not spending, and the pay back is not income
+	summary := space.insightsEngine.GenerateSpendingSummary(space.transactions, "month")
+	if summary.TotalSpent != 90 || summary.TotalIncome != 600 || summary.ByCategory[CategoryTravel] != 0 {
+		t.Errorf("spent %.2f, income %.2f, travel %.2f; want 90, 600, 0",
+			summary.TotalSpent, summary.TotalIncome, summary.ByCategory[CategoryTravel])
+	}
+
+	// Matches are stored
+	reloaded := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	reloaded.SetStore(store)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	byID := transactionIDs(reloaded.GetTransactions(TransactionFilter{}))
+	if byID["hotel"].Reimbursed != (Money{30000, "USD"}) || byID["acme"].ReimbursementID != hotel.ID || byID["zelle"].ReimbursementID != "" {
+		t.Errorf("links after reload: hotel %+v, acme %+v", byID["hotel"], byID["acme"])
+	}
+
+	// Expecting a different amount replaces the expectation
+	again, err := reloaded.ExpectReimbursement("hotel", 150, "", "")
+	if err != nil {
+		t.Fatalf("ExpectReimbursement: %v", err)
+	}
+	if again.ID != hotel.ID || again.Status != ReimbursementPending {
+		t.Errorf("replaced reimbursement = %+v", again)
+	}
+	if report := reloaded.GetReimbursements(); len(report.Reimbursements) != 2 || report.Outstanding != (Money{19500, "USD"}) {
+		t.Errorf("report after replacing = %+v", report)
+	}
+
+	if err := reloaded.DeleteReimbursement(dinner.ID); err != nil {
+		t.Fatalf("DeleteReimbursement: %v", err)
+	}
+	if err := reloaded.DeleteReimbursement(dinner.ID); !errors.Is(err, ErrReimbursementNotFound) {
+		t.Errorf("second delete error = %v, want ErrReimbursementNotFound", err)
+	}
+	stored, err := store.Reimbursements()
+	if err != nil {
+		t.Fatalf("Reimbursements: %v", err)
+	}
+	if len(stored) != 1 || stored[0].ID != hotel.ID || stored[0].Amount != (Money{15000, "USD"}) {
+		t.Errorf("stored = %+v", stored)
+	}
+}
@@ -0,0 +1,309 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RuleSource says who made a categorization rule
+type RuleSource string
+
+const (
+	RuleSourceUser    RuleSource = "user"
+	RuleSourceLearned RuleSource = "learned"
+)
+
+var (
+	// ErrTransactionNotFound is returned for an unknown transaction ID
+	ErrTransactionNotFound = errors.New("transaction not found")
+
+	// ErrRuleNotFound is returned for an unknown rule ID
+	ErrRuleNotFound = errors.New("rule not found")
+)
+
+// learnAfterCorrections is how many transactions from one merchant the
+// user must move to the same category before a rule is learned
+const learnAfterCorrections = 2
+
+// CategoryRule assigns a category to transactions that match all of its
+// set conditions. Rules are tried by descending priority, user rules
+// before learned ones, then oldest first.
+type CategoryRule struct {
+	ID        string     `json:"id"`
+	Merchant  string     `json:"merchant,omitempty"`   // Case-insensitive merchant or name
+	Pattern   string     `json:"pattern,omitempty"`    // Regular expression over merchant and name
+	MinAmount *float64   `json:"min_amount,omitempty"` // Inclusive, in Plaid's sign convention
+	MaxAmount *float64   `json:"max_amount,omitempty"`
+	Category  Category   `json:"category"`
+	Priority  int        `json:"priority"`
+	Source    RuleSource `json:"source"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	re *regexp.Regexp
+}
+
+// Validate checks the rule and compiles its pattern
+func (r *CategoryRule) Validate() error {
+	if !IsValidCategory(r.Category) {
+		return fmt.Errorf("unknown category: %s", r.Category)
+	}
+	if r.Merchant == "" && r.Pattern == "" && r.MinAmount == nil && r.MaxAmount == nil {
+		return fmt.Errorf("rule needs a merchant, pattern or amount range")
+	}
+	if r.MinAmount != nil && r.MaxAmount != nil && *r.MinAmount > *r.MaxAmount {
+		return fmt.Errorf("min amount is above max amount")
+	}
+
+	r.re = nil
+	if r.Pattern != "" {
+		re, err := regexp.Compile("(?i)" + r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		r.re = re
+	}
+	if r.Source == "" {
+		r.Source = RuleSourceUser
+	}
+	return nil
+}
+
+// Matches reports whether tx meets every condition of the rule. The rule
+// must have been validated.
+func (r *CategoryRule) Matches(tx Transaction) bool {
+	if r.Merchant != "" && !strings.EqualFold(r.Merchant, strings.TrimSpace(tx.MerchantName)) &&
+		!strings.EqualFold(r.Merchant, strings.TrimSpace(tx.Name)) {
+		return false
+	}
+	if r.re != nil && !r.re.MatchString(tx.MerchantName+" "+tx.Name) {
+		return false
+	}
+	if r.MinAmount != nil && tx.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && tx.Amount > *r.MaxAmount {
+		return false
+	}
+	return true
+}
+
+// sortRules orders rules by the order they are tried in
+func sortRules(rules []*CategoryRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.Source != b.Source {
+			return a.Source == RuleSourceUser
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	})
+}
+
+// IsValidCategory reports whether category is one of AllCategories
+func IsValidCategory(category Category) bool {
+	for _, c := range AllCategories() {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// merchantKeyOf is the lowercased merchant, or name if there is none, that
+// transactions from the same merchant share
+func merchantKeyOf(tx Transaction) string {
+	if tx.MerchantName != "" {
+		return strings.ToLower(strings.TrimSpace(tx.MerchantName))
+	}
+	return strings.ToLower(strings.TrimSpace(tx.Name))
+}
+
+// Recategorization is the result of the user moving a transaction to
+// another category
+type Recategorization struct {
+	Transaction *CategorizedTransaction `json:"transaction"`
+	Previous    Category                `json:"previous"`
+	LearnedRule *CategoryRule           `json:"learned_rule,omitempty"` // Rule this correction created or changed
+}
+
+// Recategorize moves a transaction to category. Once the user has moved
+// two transactions from a merchant to the same category, a learned rule
+// categorizes the merchant's future transactions the same way.
+func (s *Space) Recategorize(transactionID string, category Category) (*Recategorization, error) {
+	if !IsValidCategory(category) {
+		return nil, fmt.Errorf("unknown category: %s", category)
+	}
+
+	s.mu.Lock()
+	var tx *CategorizedTransaction
+	for _, t := range s.transactions {
+		if t.TransactionID == transactionID {
+			tx = t
+			break
+		}
+	}
+	if tx == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+
+	store := s.store
+	if store != nil {
+		if err := store.SetTransactionCategory(transactionID, category); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	result := &Recategorization{Transaction: tx, Previous: tx.QLCategory}
+	merchant := merchantKeyOf(tx.Transaction)
+	tx.QLCategory = category
+	tx.Confidence = 1.0
+	tx.RuleID = ""
+	tx.UserCategorized = true
+	recurring, insights := s.analyze()
+	s.mu.Unlock()
+
+	if store == nil {
+		return result, nil
+	}
+	for _, err := range saveAnalysis(store, recurring, insights) {
+		fmt.Printf("Warning: failed to save analysis: %v\n", err)
+	}
+
+	if merchant == "" {
+		return result, nil
+	}
+	count, err := store.RecordCorrection(transactionID, merchant, category)
+	if err != nil {
+		return result, err
+	}
+	if count >= learnAfterCorrections {
+		rule, err := s.learnRule(merchant, category)
+		if err != nil {
+			return result, err
+		}
+		result.LearnedRule = rule
+	}
+	return result, nil
+}
+
+// learnRule makes the user's repeated correction of merchant a rule,
+// changing the merchant's learned rule if it has one. It returns nil if
+// the rule already exists.
+func (s *Space) learnRule(merchant string, category Category) (*CategoryRule, error) {
+	var rule *CategoryRule
+	for _, r := range s.categorizer.Rules() {
+		if r.Source != RuleSourceLearned || !strings.EqualFold(r.Merchant, merchant) ||
+			r.Pattern != "" || r.MinAmount != nil || r.MaxAmount != nil {
+			continue
+		}
+		if r.Category == category {
+			return nil, nil
+		}
+		changed := *r
+		changed.Category = category
+		rule = &changed
+		break
+	}
+
+	if rule == nil {
+		rule = &CategoryRule{
+			ID:        newRuleID(),
+			Merchant:  merchant,
+			Category:  category,
+			Source:    RuleSourceLearned,
+			CreatedAt: time.Now(),
+		}
+	}
+	if err := s.saveRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// AddRule validates and saves a user rule. It applies to transactions
+// categorized from now on.
+func (s *Space) AddRule(rule *CategoryRule) error {
+	if rule.ID == "" {
+		rule.ID = newRuleID()
+	}
+	if rule.Source == "" {
+		rule.Source = RuleSourceUser
+	}
+	if rule.CreatedAt.IsZero() {
+		rule.CreatedAt = time.Now()
+	}
+	return s.saveRule(rule)
+}
+
+// saveRule persists rule and puts it in the categorizer, replacing any
+// rule with the same ID
+func (s *Space) saveRule(rule *CategoryRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	store := s.store
+	s.mu.RUnlock()
+	if store != nil {
+		if err := store.SaveRule(rule); err != nil {
+			return err
+		}
+	}
+
+	rules := s.categorizer.Rules()
+	replaced := false
+	for i, r := range rules {
+		if r.ID == rule.ID {
+			rules[i] = rule
+			replaced = true
+		}
+	}
+	if !replaced {
+		rules = append(rules, rule)
+	}
+	return s.categorizer.SetRules(rules)
+}
+
+// GetRules returns the categorization rules in the order they are tried
+func (s *Space) GetRules() []*CategoryRule {
+	return s.categorizer.Rules()
+}
+
+// DeleteRule removes a categorization rule
+func (s *Space) DeleteRule(id string) error {
+	s.mu.RLock()
+	store := s.store
+	s.mu.RUnlock()
+	if store != nil {
+		if err := store.DeleteRule(id); err != nil {
+			return err
+		}
+	}
+
+	rules := s.categorizer.Rules()
+	kept := rules[:0]
+	for _, r := range rules {
+		if r.ID != id {
+			kept = append(kept, r)
+		}
+	}
+	if store == nil && len(kept) == len(rules) {
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, id)
+	}
+	return s.categorizer.SetRules(kept)
+}
+
+func newRuleID() string {
+	return "rule_" + uuid.New().String()
+}
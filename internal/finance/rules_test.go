@@ -0,0 +1,232 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func amount(v float64) *float64 {
+	return &v
+}
+
+func TestCategoryRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    CategoryRule
+		wantErr bool
+	}{
+		{"merchant", CategoryRule{Merchant: "Corner Cafe", Category: CategoryDining}, false},
+		{"pattern", CategoryRule{Pattern: `^uber\s`, Category: CategoryTransport}, false},
+		{"amount range", CategoryRule{MinAmount: amount(1000), MaxAmount: amount(2000), Category: CategoryBills}, false},
+		{"no conditions", CategoryRule{Category: CategoryDining}, true},
+		{"unknown category", CategoryRule{Merchant: "Corner Cafe", Category: "snacks"}, true},
+		{"bad pattern", CategoryRule{Pattern: `(`, Category: CategoryDining}, true},
+		{"inverted range", CategoryRule{MinAmount: amount(20), MaxAmount: amount(10), Category: CategoryDining}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCategorizer_Rules(t *testing.T) {
+	c := NewCategorizer(CategorizerConfig{})
+	now := time.Now()
+	err := c.SetRules([]*CategoryRule{
+		{ID: "learned", Merchant: "Landlord LLC", Category: CategoryTransfer, Source: RuleSourceLearned, CreatedAt: now},
+		{ID: "rent", Merchant: "Landlord LLC", MinAmount: amount(1000), Category: CategoryBills, Source: RuleSourceUser, CreatedAt: now.Add(time.Second)},
+		{ID: "uber", Pattern: `\buber\b`, MaxAmount: amount(100), Category: CategoryTransport, Priority: 5, CreatedAt: now},
+	})
+	if err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		tx       Transaction
+		category Category
+		ruleID   string
+	}{
+		{"user rule before learned at equal priority", Transaction{Name: "LANDLORD LLC", Amount: 1500}, CategoryBills, "rent"},
+		{"amount range excludes", Transaction{Name: "Landlord LLC", Amount: 50}, CategoryTransfer, "learned"},
+		{"pattern beats plaid category", Transaction{Name: "UBER TRIP", Amount: 20, Category: []string{"Food and Drink"}}, CategoryTransport, "uber"},
+		{"no rule falls back to plaid", Transaction{Name: "Uber Eats", Amount: 150, Category: []string{"Food and Drink"}}, CategoryDining, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Categorize(tt.tx)
+			if got.QLCategory != tt.category || got.RuleID != tt.ruleID {
+				t.Errorf("Categorize() = %s via %q, want %s via %q", got.QLCategory, got.RuleID, tt.category, tt.ruleID)
+			}
+		})
+	}
+
+	if err := c.SetRules([]*CategoryRule{{ID: "bad", Category: CategoryDining}}); err == nil {
+		t.Error("SetRules() accepted an invalid rule")
+	}
+}
+
+func TestStore_Rules(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	rule := &CategoryRule{ID: "rule_1", Merchant: "Gym", MaxAmount: amount(80), Category: CategoryHealth, Priority: 2, Source: RuleSourceUser, CreatedAt: time.Now()}
+	if err := store.SaveRule(rule); err != nil {
+		t.Fatalf("SaveRule: %v", err)
+	}
+
+	rules, err := store.Rules()
+	if err != nil {
+		t.Fatalf("Rules: %v", err)
+	}
+	if len(rules) != 1 {
+		t.Fatalf("rules = %d, want 1", len(rules))
+	}
+	got := rules[0]
+	if got.Merchant != "Gym" || got.Category != CategoryHealth || got.Priority != 2 || got.MinAmount != nil || got.MaxAmount == nil || *got.MaxAmount != 80 {
+		t.Errorf("rule = %+v", got)
+	}
+
+	if err := store.DeleteRule("rule_1"); err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if err := store.DeleteRule("rule_1"); !errors.Is(err, ErrRuleNotFound) {
+		t.Errorf("DeleteRule(deleted) error = %v, want ErrRuleNotFound", err)
+	}
+}
+
+func TestSpace_Recategorize_LearnsRule(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	mock := &mockPlaid{
+		accounts: testConnection().Accounts,
+		pages: map[string]TransactionsSyncResponse{
+			"": {
+				Added: []Transaction{
+					testTransaction("tx_1", "Corner Cafe", 4.50, "2026-01-02"),
+					testTransaction("tx_2", "Corner Cafe", 5.00, "2026-01-09"),
+				},
+				NextCursor: "c1",
+			},
+			"c1": {
+				Added:      []Transaction{testTransaction("tx_3", "Corner Cafe", 4.75, "2026-01-16")},
+				Modified:   []Transaction{testTransaction("tx_1", "Corner Cafe", 4.60, "2026-01-02")},
+				NextCursor: "c2",
+			},
+		},
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	newSpace := func() *Space {
+		space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", PlaidConfig: PlaidConfig{BaseURL: server.URL}})
+		space.SetStore(store)
+		if err := space.Load(); err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return space
+	}
+
+	ctx := context.Background()
+	space := newSpace()
+	if _, err := space.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if tx := transactionIDs(space.GetTransactions(TransactionFilter{}))["tx_1"]; tx.QLCategory != CategoryShopping {
+		t.Fatalf("tx_1 category = %s, want shopping from Plaid", tx.QLCategory)
+	}
+
+	// One correction is not enough to learn from
+	result, err := space.Recategorize("tx_1", CategoryDining)
+	if err != nil {
+		t.Fatalf("Recategorize: %v", err)
+	}
+	if result.Previous != CategoryShopping || result.LearnedRule != nil {
+		t.Errorf("first correction = %+v", result)
+	}
+
+	// Correcting the same transaction again doesn't count twice
+	if result, _ := space.Recategorize("tx_1", CategoryDining); result.LearnedRule != nil {
+		t.Errorf("repeated correction learned %+v", result.LearnedRule)
+	}
+
+	result, err = space.Recategorize("tx_2", CategoryDining)
+	if err != nil {
+		t.Fatalf("Recategorize: %v", err)
+	}
+	rule := result.LearnedRule
+	if rule == nil || rule.Merchant != "corner cafe" || rule.Category != CategoryDining || rule.Source != RuleSourceLearned {
+		t.Fatalf("learned rule = %+v", rule)
+	}
+
+	// The rule categorizes new transactions, and the bank's update of a
+	// corrected one keeps the user's category
+	if _, err := space.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	for _, s := range []*Space{space, newSpace()} {
+		txs := transactionIDs(s.GetTransactions(TransactionFilter{}))
+		if tx := txs["tx_3"]; tx.QLCategory != CategoryDining || tx.RuleID != rule.ID {
+			t.Errorf("tx_3 = %s via %q, want dining via %q", tx.QLCategory, tx.RuleID, rule.ID)
+		}
+		if tx := txs["tx_1"]; tx.QLCategory != CategoryDining || !tx.UserCategorized || tx.Amount != 4.60 {
+			t.Errorf("tx_1 = %+v", tx)
+		}
+		if rules := s.GetRules(); len(rules) != 1 || rules[0].ID != rule.ID {
+			t.Errorf("rules = %+v", rules)
+		}
+	}
+
+	// Two corrections the other way change the learned rule
+	space.Recategorize("tx_1", CategoryGroceries)
+	result, _ = space.Recategorize("tx_2", CategoryGroceries)
+	if result.LearnedRule == nil || result.LearnedRule.ID != rule.ID || result.LearnedRule.Category != CategoryGroceries {
+		t.Errorf("relearned rule = %+v", result.LearnedRule)
+	}
+
+	if _, err := space.Recategorize("tx_404", CategoryDining); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("Recategorize(unknown) error = %v, want ErrTransactionNotFound", err)
+	}
+	if _, err := space.Recategorize("tx_1", "snacks"); err == nil {
+		t.Error("Recategorize accepted an unknown category")
+	}
+}
+
+func TestSpace_AddRule(t *testing.T) {
+	store, _ := newTestStore(t)
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(store)
+
+	rule := &CategoryRule{Pattern: "netflix|hulu", Category: CategorySubscription}
+	if err := space.AddRule(rule); err != nil {
+		t.Fatalf("AddRule: %v", err)
+	}
+	if rule.ID == "" || rule.Source != RuleSourceUser {
+		t.Errorf("rule = %+v", rule)
+	}
+	if err := space.AddRule(&CategoryRule{Category: CategoryDining}); err == nil {
+		t.Error("AddRule accepted a rule with no conditions")
+	}
+
+	if rules, _ := store.Rules(); len(rules) != 1 {
+		t.Errorf("stored rules = %d, want 1", len(rules))
+	}
+
+	if err := space.DeleteRule(rule.ID); err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if rules := space.GetRules(); len(rules) != 0 {
+		t.Errorf("rules after delete = %+v", rules)
+	}
+}
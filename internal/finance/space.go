@@ -3,6 +3,7 @@ package finance
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/llm"
 	"github.com/quantumlife/quantumlife/internal/spaces"
+	"github.com/quantumlife/quantumlife/internal/storage"
 )
 
 // Space implements the Finance data source
@@ -20,8 +22,31 @@ type Space struct {
 
 	// Plaid
 	plaidClient *PlaidClient
+	verifier    *WebhookVerifier
 	connections []*Connection
 
+	// Persistence, optional
+	store *Store
+
+	// Currency conversion, optional
+	exchange *Exchange
+
+	// Budget envelopes
+	budgets *budgetBook
+
+	// Forecasting
+	planned    []*PlannedExpense
+	lowBalance float64
+
+	// Expenses the user expects to be paid back
+	reimbursements []*Reimbursement
+
+	// Categories tax-year reports list as deductible
+	deductible []Category
+
+	// Email receipts for subscriptions and tax reports, optional
+	items *storage.ItemStore
+
 	// Processing
 	categorizer       *Categorizer
 	recurringDetector *RecurringDetector
@@ -36,7 +61,12 @@ type Space struct {
 	// State
 	connected  bool
 	syncStatus spaces.SyncStatus
-	syncCursor string
+
+	// Serializes syncs of each connection, keyed by connection ID
+	syncLocks map[string]*sync.Mutex
+
+	// Background syncs queued by webhooks, keyed by Plaid item ID
+	queuedSyncs map[string]*queuedSync
 
 	mu sync.RWMutex
 }
@@ -49,6 +79,14 @@ type SpaceConfig struct {
 	PlaidConfig  PlaidConfig
 	LLMClient    *llm.OllamaClient
 	Budgets      map[Category]float64
+	HomeCurrency string // Currency summaries, budgets and net worth are in
+
+	// Forecasts warn when a cash account is projected to drop below this,
+	// in the home currency
+	LowBalanceThreshold float64
+
+	// Tax-year reports list spending in these categories as deductible
+	DeductibleCategories []Category
 }
 
 // NewSpace creates a new Finance space
@@ -59,25 +97,67 @@ func NewSpace(cfg SpaceConfig) *Space {
 	})
 
 	insightsEngine := NewInsightsEngine(InsightsConfig{
-		LLMClient: cfg.LLMClient,
-		Budgets:   cfg.Budgets,
+		LLMClient:    cfg.LLMClient,
+		Budgets:      cfg.Budgets,
+		HomeCurrency: cfg.HomeCurrency,
 	})
 
+	plaidClient := NewPlaidClient(cfg.PlaidConfig)
+
+	// Budgets from the config start with the current period
+	budgets := newBudgetBook()
+	start, _ := budgets.period.Bounds(time.Now())
+	for category, amount := range cfg.Budgets {
+		budgets.envelopes[category] = &Envelope{
+			Category: category,
+			Amount:   NewMoney(amount, insightsEngine.HomeCurrency()),
+			StartsOn: start.Format("2006-01-02"),
+		}
+	}
+
 	return &Space{
 		id:                cfg.ID,
 		name:              cfg.Name,
 		defaultHatID:      cfg.DefaultHatID,
-		plaidClient:       NewPlaidClient(cfg.PlaidConfig),
+		plaidClient:       plaidClient,
+		verifier:          NewWebhookVerifier(plaidClient),
 		categorizer:       categorizer,
 		recurringDetector: NewRecurringDetector(),
 		insightsEngine:    insightsEngine,
+		budgets:           budgets,
+		lowBalance:        cfg.LowBalanceThreshold,
+		deductible:        cfg.DeductibleCategories,
 		connections:       make([]*Connection, 0),
+		syncLocks:         make(map[string]*sync.Mutex),
+		queuedSyncs:       make(map[string]*queuedSync),
 		syncStatus: spaces.SyncStatus{
 			Status: "idle",
 		},
 	}
 }
 
+// SetStore persists connections, transactions, recurring transactions
+// and insights in store
+func (s *Space) SetStore(store *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+}
+
+// SetExchange converts foreign-currency amounts to the home currency
+// with exchange, whose rates are refreshed on sync
+func (s *Space) SetExchange(exchange *Exchange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exchange = exchange
+	s.insightsEngine.SetExchange(exchange)
+}
+
+// HomeCurrency returns the currency summaries and net worth are in
+func (s *Space) HomeCurrency() string {
+	return s.insightsEngine.HomeCurrency()
+}
+
 // ID returns the space ID
 func (s *Space) ID() core.SpaceID {
 	return s.id
@@ -149,10 +229,17 @@ func (s *Space) LinkAccount(ctx context.Context, publicToken, institutionID stri
 	}
 
 	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store != nil {
+		if err := s.store.SaveConnection(connection); err != nil {
+			return nil, err
+		}
+	}
+
 	s.connections = append(s.connections, connection)
 	s.accounts = append(s.accounts, accountsResp.Accounts...)
 	s.connected = true
-	s.mu.Unlock()
 
 	return connection, nil
 }
@@ -162,19 +249,30 @@ func (s *Space) Connect(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if len(s.connections) == 0 && s.store != nil {
+		if err := s.load(); err != nil {
+			return err
+		}
+	}
+
 	if len(s.connections) == 0 {
 		return fmt.Errorf("no connections configured - use LinkAccount first")
 	}
 
 	// Verify connections are still valid
 	for _, conn := range s.connections {
-		if conn.Status != ConnectionStatusActive {
+		if conn.Status != ConnectionStatusActive || conn.AccessToken == "" {
 			continue
 		}
 
 		_, err := s.plaidClient.GetAccounts(ctx, conn.AccessToken)
 		if err != nil {
 			conn.Status = ConnectionStatusError
+			if s.store != nil {
+				if err := s.store.UpdateConnectionStatus(conn.ID, conn.Status); err != nil {
+					fmt.Printf("Warning: failed to save status of %s: %v\n", conn.InstitutionName, err)
+				}
+			}
 			continue
 		}
 	}
@@ -184,6 +282,86 @@ func (s *Space) Connect(ctx context.Context) error {
 	return nil
 }
 
+// Load restores connections, transactions, recurring transactions and
+// insights from the store, so they are available without connecting
+func (s *Space) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store == nil {
+		return fmt.Errorf("no store configured")
+	}
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.connected = len(s.connections) > 0
+	return nil
+}
+
+// load restores connections and cached data from the store. The caller
+// must hold the lock.
+func (s *Space) load() error {
+	connections, err := s.store.Connections()
+	if err != nil {
+		return fmt.Errorf("load connections: %w", err)
+	}
+	transactions, err := s.store.Transactions()
+	if err != nil {
+		return fmt.Errorf("load transactions: %w", err)
+	}
+	recurring, err := s.store.Recurring()
+	if err != nil {
+		return fmt.Errorf("load recurring: %w", err)
+	}
+	insights, err := s.store.Insights()
+	if err != nil {
+		return fmt.Errorf("load insights: %w", err)
+	}
+	rules, err := s.store.Rules()
+	if err != nil {
+		return fmt.Errorf("load rules: %w", err)
+	}
+	if err := s.categorizer.SetRules(rules); err != nil {
+		return fmt.Errorf("load rules: %w", err)
+	}
+	if err := s.loadBudgets(); err != nil {
+		return fmt.Errorf("load budgets: %w", err)
+	}
+	planned, err := s.store.PlannedExpenses()
+	if err != nil {
+		return fmt.Errorf("load planned expenses: %w", err)
+	}
+	reimbursements, err := s.store.Reimbursements()
+	if err != nil {
+		return fmt.Errorf("load reimbursements: %w", err)
+	}
+
+	s.connections = connections
+	s.transactions = transactions
+	s.recurring = recurring
+	s.insights = insights
+	s.planned = planned
+	s.reimbursements = reimbursements
+	s.refreshAccounts()
+	s.linkTransactions()
+	s.syncStatus.ItemCount = len(transactions)
+	for _, conn := range connections {
+		if conn.LastSync.After(s.syncStatus.LastSync) {
+			s.syncStatus.LastSync = conn.LastSync
+		}
+	}
+	return nil
+}
+
+// refreshAccounts rebuilds the account list from the connections. The
+// caller must hold the lock.
+func (s *Space) refreshAccounts() {
+	s.accounts = nil
+	for _, conn := range s.connections {
+		s.accounts = append(s.accounts, conn.Accounts...)
+	}
+}
+
 // Disconnect removes all connections
 func (s *Space) Disconnect(ctx context.Context) error {
 	s.mu.Lock()
@@ -193,17 +371,39 @@ func (s *Space) Disconnect(ctx context.Context) error {
 		s.plaidClient.RemoveItem(ctx, conn.AccessToken)
 	}
 
+	if s.store != nil {
+		if err := s.store.DeleteAll(); err != nil {
+			return err
+		}
+	}
+
 	s.connections = nil
 	s.accounts = nil
 	s.transactions = nil
 	s.recurring = nil
+	s.insights = nil
 	s.connected = false
 	s.syncStatus.Status = "disconnected"
 
 	return nil
 }
 
-// Sync fetches latest transactions
+const (
+	// maxSyncRestarts bounds how often a sync restarts because the item
+	// changed while it was paging
+	maxSyncRestarts = 3
+
+	// queuedSyncTimeout bounds a background sync queued by a webhook
+	queuedSyncTimeout = 5 * time.Minute
+)
+
+// queuedSync is a background sync of one item. again is set when another
+// sync is requested while it runs.
+type queuedSync struct {
+	again bool
+}
+
+// Sync fetches transaction changes since each connection's cursor
 func (s *Space) Sync(ctx context.Context) (*spaces.SyncResult, error) {
 	s.mu.Lock()
 	if !s.connected {
@@ -214,61 +414,340 @@ func (s *Space) Sync(ctx context.Context) (*spaces.SyncResult, error) {
 	connections := s.connections
 	s.mu.Unlock()
 
+	return s.syncConnections(ctx, connections), nil
+}
+
+// SyncItem syncs only the connection for a Plaid item, as when Plaid
+// reports that it has new transactions
+func (s *Space) SyncItem(ctx context.Context, itemID string) (*spaces.SyncResult, error) {
+	s.mu.Lock()
+	conn := s.connectionForItem(itemID)
+	if conn == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrUnknownItem, itemID)
+	}
+	s.syncStatus.Status = "syncing"
+	s.mu.Unlock()
+
+	return s.syncConnections(ctx, []*Connection{conn}), nil
+}
+
+// QueueItemSync syncs an item in the background, so a webhook can be
+// acknowledged right away. Requests while the item is syncing are coalesced
+// into one more sync once it finishes.
+func (s *Space) QueueItemSync(itemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.connectionForItem(itemID) == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownItem, itemID)
+	}
+	if queued, ok := s.queuedSyncs[itemID]; ok {
+		queued.again = true
+		return nil
+	}
+
+	queued := &queuedSync{}
+	s.queuedSyncs[itemID] = queued
+	go s.runQueuedSync(itemID, queued)
+	return nil
+}
+
+func (s *Space) runQueuedSync(itemID string, queued *queuedSync) {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), queuedSyncTimeout)
+		result, err := s.SyncItem(ctx, itemID)
+		cancel()
+		if err != nil {
+			fmt.Printf("Warning: sync of item %s: %v\n", itemID, err)
+		} else {
+			for _, err := range result.Errors {
+				fmt.Printf("Warning: sync of item %s: %v\n", itemID, err)
+			}
+		}
+
+		s.mu.Lock()
+		if !queued.again {
+			delete(s.queuedSyncs, itemID)
+			s.mu.Unlock()
+			return
+		}
+		queued.again = false
+		s.mu.Unlock()
+	}
+}
+
+// syncConnections syncs connections, persists the changes and refreshes
+// the cached data. Per-connection failures are reported in the result.
+func (s *Space) syncConnections(ctx context.Context, connections []*Connection) *spaces.SyncResult {
+	s.mu.RLock()
+	store := s.store
+	s.mu.RUnlock()
+
 	start := time.Now()
 	result := &spaces.SyncResult{}
 
-	// Sync each connection
-	var allTransactions []Transaction
-	for _, conn := range connections {
-		if conn.Status != ConnectionStatusActive {
-			continue
-		}
+	var upserted []*CategorizedTransaction
+	var removed []string
 
-		// Use sync API for incremental updates
-		syncResp, err := s.plaidClient.SyncTransactions(ctx, conn.AccessToken, conn.SyncCursor)
+	for _, conn := range connections {
+		changes, added, err := s.syncAndApply(ctx, store, conn)
 		if err != nil {
-			result.Errors = append(result.Errors, fmt.Errorf("sync %s: %w", conn.InstitutionName, err))
+			result.Errors = append(result.Errors, err)
 			continue
 		}
-
-		allTransactions = append(allTransactions, syncResp.Added...)
-		allTransactions = append(allTransactions, syncResp.Modified...)
-		conn.SyncCursor = syncResp.NextCursor
-		conn.LastSync = time.Now()
-
-		// Update accounts
-		accountsResp, err := s.plaidClient.Balance(ctx, conn.AccessToken)
-		if err == nil {
-			conn.Accounts = accountsResp.Accounts
+		if changes == nil {
+			continue
 		}
 
-		result.NewItems += len(syncResp.Added)
-		result.UpdatedItems += len(syncResp.Modified)
+		upserted = append(upserted, changes.Upserted...)
+		removed = append(removed, changes.Removed...)
+		result.NewItems += added
+		result.UpdatedItems += len(changes.Upserted) - added
 	}
 
-	// Categorize transactions
-	categorized := s.categorizer.BatchCategorize(allTransactions)
-
-	// Detect recurring transactions
-	recurring := s.recurringDetector.DetectRecurring(categorized)
-
-	// Generate insights
-	insights := s.generateInsights(categorized, recurring)
+	if err := s.refreshRates(ctx, upserted); err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("refresh exchange rates: %w", err))
+	}
 
 	// Update cached data
 	s.mu.Lock()
-	s.transactions = append(s.transactions, categorized...)
-	s.recurring = recurring
-	s.insights = insights
+	s.transactions = mergeTransactions(s.transactions, upserted, removed)
+	s.refreshAccounts()
+	recurring, insights := s.analyze()
 	s.syncStatus.Status = "idle"
 	s.syncStatus.LastSync = time.Now()
 	s.syncStatus.ItemCount = len(s.transactions)
 	s.mu.Unlock()
 
+	result.Errors = append(result.Errors, saveAnalysis(store, recurring, insights)...)
+	if _, err := s.GetBudgets(); err != nil {
+		result.Errors = append(result.Errors, fmt.Errorf("record budgets: %w", err))
+	}
+
 	result.Duration = time.Since(start)
 	result.Cursor = time.Now().Format(time.RFC3339)
 
-	return result, nil
+	return result
+}
+
+// syncAndApply syncs one connection, saves the changes and moves its
+// cursor. Syncs of the same connection, such as a scheduled sync and a
+// webhook, run one at a time so each starts from the cursor the last one
+// saved. Imported statements, and Plaid items while the identity is locked,
+// have no token to sync with and return no changes.
+func (s *Space) syncAndApply(ctx context.Context, store *Store, conn *Connection) (*SyncChanges, int, error) {
+	lock := s.syncLock(conn.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	s.mu.RLock()
+	status, token, cursor := conn.Status, conn.AccessToken, conn.SyncCursor
+	s.mu.RUnlock()
+	if status != ConnectionStatusActive || token == "" {
+		return nil, 0, nil
+	}
+
+	changes, added, err := s.syncConnection(ctx, token, cursor)
+	if err != nil {
+		return nil, 0, fmt.Errorf("sync %s: %w", conn.InstitutionName, err)
+	}
+
+	if store != nil {
+		if err := store.ApplySync(conn.ID, *changes); err != nil {
+			return nil, 0, fmt.Errorf("save %s: %w", conn.InstitutionName, err)
+		}
+	}
+
+	s.mu.Lock()
+	conn.SyncCursor = changes.Cursor
+	conn.LastSync = changes.SyncedAt
+	if len(changes.Accounts) > 0 {
+		conn.Accounts = changes.Accounts
+	}
+	s.mu.Unlock()
+
+	return changes, added, nil
+}
+
+// syncLock returns the lock that serializes syncs of a connection
+func (s *Space) syncLock(connectionID string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, ok := s.syncLocks[connectionID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.syncLocks[connectionID] = lock
+	}
+	return lock
+}
+
+// syncConnection pages through the changes since cursor and categorizes
+// them. It also returns how many transactions were added.
+func (s *Space) syncConnection(ctx context.Context, accessToken, cursor string) (*SyncChanges, int, error) {
+	var added, modified []Transaction
+	var removed []string
+	start := cursor
+
+	for restarts := 0; ; {
+		resp, err := s.plaidClient.SyncTransactions(ctx, accessToken, cursor)
+		if err != nil {
+			// The item changed between pages; start over from the stored cursor
+			var plaidErr *PlaidError
+			if errors.As(err, &plaidErr) && plaidErr.ErrorCode == "TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION" && restarts < maxSyncRestarts {
+				restarts++
+				added, modified, removed = nil, nil, nil
+				cursor = start
+				continue
+			}
+			return nil, 0, err
+		}
+
+		added = append(added, resp.Added...)
+		modified = append(modified, resp.Modified...)
+		for _, r := range resp.Removed {
+			removed = append(removed, r.TransactionID)
+		}
+		cursor = resp.NextCursor
+
+		if !resp.HasMore {
+			break
+		}
+	}
+
+	changes := &SyncChanges{
+		Upserted: s.categorizer.BatchCategorize(append(added, modified...)),
+		Removed:  removed,
+		Cursor:   cursor,
+		SyncedAt: time.Now(),
+	}
+
+	// Update accounts
+	accountsResp, err := s.plaidClient.Balance(ctx, accessToken)
+	if err == nil {
+		changes.Accounts = accountsResp.Accounts
+	}
+
+	return changes, len(added), nil
+}
+
+// mergeTransactions applies upserts and removals to transactions, keyed
+// by transaction ID
+func mergeTransactions(transactions, upserted []*CategorizedTransaction, removed []string) []*CategorizedTransaction {
+	index := make(map[string]int, len(transactions))
+	for i, tx := range transactions {
+		index[tx.TransactionID] = i
+	}
+
+	for _, tx := range upserted {
+		if i, ok := index[tx.TransactionID]; ok {
+			// Keep the category the user chose, and their splits
+			old := transactions[i]
+			if old.UserCategorized {
+				tx.QLCategory = old.QLCategory
+				tx.Confidence = old.Confidence
+				tx.RuleID = old.RuleID
+				tx.UserCategorized = true
+			}
+			tx.Splits = old.Splits
+			transactions[i] = tx
+			continue
+		}
+		index[tx.TransactionID] = len(transactions)
+		transactions = append(transactions, tx)
+	}
+
+	if len(removed) == 0 {
+		return transactions
+	}
+	gone := make(map[string]bool, len(removed))
+	for _, id := range removed {
+		gone[id] = true
+	}
+	kept := transactions[:0]
+	for _, tx := range transactions {
+		if !gone[tx.TransactionID] {
+			kept = append(kept, tx)
+		}
+	}
+	return kept
+}
+
+// refreshRates refreshes the exchange rates if any account or
+// transaction, including the just-synced ones, is in a foreign currency
+// and the cached rates are over a day old
+func (s *Space) refreshRates(ctx context.Context, synced []*CategorizedTransaction) error {
+	s.mu.RLock()
+	exchange := s.exchange
+	home := s.insightsEngine.HomeCurrency()
+	foreign := false
+	isForeign := func(currency string) bool {
+		return currency != "" && normalizeCurrency(currency) != home
+	}
+	for _, conn := range s.connections {
+		for _, account := range conn.Accounts {
+			foreign = foreign || isForeign(account.Balances.IsoCurrencyCode)
+		}
+	}
+	for _, tx := range s.transactions {
+		foreign = foreign || isForeign(tx.IsoCurrencyCode)
+	}
+	s.mu.RUnlock()
+	for _, tx := range synced {
+		foreign = foreign || isForeign(tx.IsoCurrencyCode)
+	}
+
+	if exchange == nil || !foreign || !exchange.NeedsRefresh(home) {
+		return nil
+	}
+	return exchange.Refresh(ctx, home)
+}
+
+// analyze links transfers and reimbursements, redetects recurring
+// transactions and regenerates insights over the full history. The caller
+// must hold the lock.
+func (s *Space) analyze() ([]*RecurringTransaction, []*Insight) {
+	s.linkTransactions()
+	for _, tx := range s.transactions {
+		tx.IsRecurring = false
+		tx.RecurringID = ""
+	}
+	s.recurring = s.recurringDetector.DetectRecurring(s.transactions)
+	markRecurring(s.transactions, s.recurring)
+	s.insights = s.generateInsights(s.transactions, s.recurring)
+	return s.recurring, s.insights
+}
+
+// saveAnalysis persists the results of analyze, if the space has a store
+func saveAnalysis(store *Store, recurring []*RecurringTransaction, insights []*Insight) []error {
+	if store == nil {
+		return nil
+	}
+	var errs []error
+	if err := store.SaveRecurring(recurring); err != nil {
+		errs = append(errs, err)
+	}
+	if err := store.SaveInsights(insights); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// markRecurring links transactions to the recurring series they belong to
+func markRecurring(transactions []*CategorizedTransaction, recurring []*RecurringTransaction) {
+	series := make(map[string]string)
+	for _, rec := range recurring {
+		for _, id := range rec.Transactions {
+			series[id] = rec.ID
+		}
+	}
+	for _, tx := range transactions {
+		if id, ok := series[tx.TransactionID]; ok {
+			tx.IsRecurring = true
+			tx.RecurringID = id
+		}
+	}
 }
 
 // generateInsights creates financial insights
@@ -335,8 +814,10 @@ func (f TransactionFilter) Matches(tx *CategorizedTransaction) bool {
 	if len(f.Categories) > 0 {
 		found := false
 		for _, cat := range f.Categories {
-			if tx.QLCategory == cat {
-				found = true
+			for _, txCat := range tx.categories() {
+				found = found || txCat == cat
+			}
+			if found {
 				break
 			}
 		}
@@ -398,25 +879,6 @@ func (s *Space) GetSpendingSummary(period string) *SpendingSummary {
 	return s.insightsEngine.GenerateSpendingSummary(transactions, period)
 }
 
-// SetBudget sets a budget for a category
-func (s *Space) SetBudget(category Category, amount float64) {
-	s.insightsEngine.SetBudget(category, amount)
-}
-
-// GetBudgets returns all configured budgets
-func (s *Space) GetBudgets() map[Category]float64 {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	budgets := make(map[Category]float64)
-	for _, cat := range AllCategories() {
-		if amount, ok := s.insightsEngine.GetBudget(cat); ok {
-			budgets[cat] = amount
-		}
-	}
-	return budgets
-}
-
 // GetConnections returns all bank connections
 func (s *Space) GetConnections() []*Connection {
 	s.mu.RLock()
@@ -432,7 +894,13 @@ func (s *Space) RemoveConnection(ctx context.Context, connectionID string) error
 	for i, conn := range s.connections {
 		if conn.ID == connectionID {
 			s.plaidClient.RemoveItem(ctx, conn.AccessToken)
+			if s.store != nil {
+				if err := s.store.DeleteConnection(conn.ID); err != nil {
+					return err
+				}
+			}
 			s.connections = append(s.connections[:i], s.connections[i+1:]...)
+			s.refreshAccounts()
 			return nil
 		}
 	}
@@ -454,46 +922,134 @@ func (s *Space) RefreshConnection(ctx context.Context, connectionID string) erro
 		accountsResp, err := s.plaidClient.Balance(ctx, conn.AccessToken)
 		if err != nil {
 			conn.Status = ConnectionStatusError
+			if s.store != nil {
+				s.store.UpdateConnectionStatus(conn.ID, conn.Status)
+			}
 			return fmt.Errorf("refresh accounts: %w", err)
 		}
 
 		conn.Accounts = accountsResp.Accounts
 		conn.UpdatedAt = time.Now()
 		conn.Status = ConnectionStatusActive
+		s.refreshAccounts()
+
+		if s.store != nil {
+			if err := s.store.SaveAccounts(conn.ID, conn.Accounts); err != nil {
+				return err
+			}
+			return s.store.UpdateConnectionStatus(conn.ID, conn.Status)
+		}
 		return nil
 	}
 
 	return fmt.Errorf("connection not found: %s", connectionID)
 }
 
-// GetTotalBalance calculates total balance across all accounts
-func (s *Space) GetTotalBalance() float64 {
+// VerifyWebhook checks the Plaid-Verification token sent with a webhook
+// body
+func (s *Space) VerifyWebhook(ctx context.Context, token string, body []byte) error {
+	return s.verifier.Verify(ctx, token, body)
+}
+
+// SetItemStatus sets and persists the status of the connection for a
+// Plaid item, returning the connection
+func (s *Space) SetItemStatus(itemID, status string) (*Connection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn := s.connectionForItem(itemID)
+	if conn == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownItem, itemID)
+	}
+
+	conn.Status = status
+	conn.UpdatedAt = time.Now()
+	if s.store != nil {
+		if err := s.store.UpdateConnectionStatus(conn.ID, status); err != nil {
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// GetItemConnection returns the connection for a Plaid item, or nil
+func (s *Space) GetItemConnection(itemID string) *Connection {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.connectionForItem(itemID)
+}
 
-	var total float64
-	for _, account := range s.accounts {
-		total += account.Balances.Current
+// connectionForItem finds the connection for a Plaid item. The caller
+// must hold the lock.
+func (s *Space) connectionForItem(itemID string) *Connection {
+	for _, conn := range s.connections {
+		if conn.ItemID == itemID {
+			return conn
+		}
 	}
-	return total
+	return nil
 }
 
-// GetNetWorth calculates net worth (assets - liabilities)
-func (s *Space) GetNetWorth() (assets, liabilities, netWorth float64) {
+// NetWorth is assets less liabilities, converted to the home currency
+type NetWorth struct {
+	Currency     string   `json:"currency"`
+	Assets       Money    `json:"assets"`
+	Liabilities  Money    `json:"liabilities"`
+	NetWorth     Money    `json:"net_worth"`
+	StaleRates   []string `json:"stale_rates,omitempty"`   // Pairs converted at rates over a day old
+	MissingRates []string `json:"missing_rates,omitempty"` // Currencies left out for want of a rate
+}
+
+// GetNetWorthReport converts every account balance to the home currency
+// and totals assets and liabilities
+func (s *Space) GetNetWorthReport() *NetWorth {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	report := &NetWorth{Currency: conv.home, Assets: conv.zero(), Liabilities: conv.zero()}
 	for _, account := range s.accounts {
+		var total *Money
 		switch account.Type {
 		case "depository", "investment":
-			assets += account.Balances.Current
+			total = &report.Assets
 		case "credit", "loan":
-			liabilities += account.Balances.Current
+			total = &report.Liabilities
+		default:
+			continue
+		}
+		if balance, ok := conv.convert(conv.money(account.Balances.Current, account.Balances.IsoCurrencyCode)); ok {
+			total.Minor += balance.Minor
+		}
+	}
+
+	report.NetWorth = Money{Minor: report.Assets.Minor - report.Liabilities.Minor, Currency: conv.home}
+	report.StaleRates = conv.staleRates()
+	report.MissingRates = conv.missingRates()
+	return report
+}
+
+// GetTotalBalance calculates total balance across all accounts, in the
+// home currency
+func (s *Space) GetTotalBalance() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	total := conv.zero()
+	for _, account := range s.accounts {
+		if balance, ok := conv.convert(conv.money(account.Balances.Current, account.Balances.IsoCurrencyCode)); ok {
+			total.Minor += balance.Minor
 		}
 	}
+	return total.Float64()
+}
 
-	netWorth = assets - liabilities
-	return
+// GetNetWorth calculates net worth (assets - liabilities) in the home
+// currency. GetNetWorthReport also says which rates were stale.
+func (s *Space) GetNetWorth() (assets, liabilities, netWorth float64) {
+	report := s.GetNetWorthReport()
+	return report.Assets.Float64(), report.Liabilities.Float64(), report.NetWorth.Float64()
 }
 
 // TransactionToItem converts a transaction to a core.Item
@@ -0,0 +1,248 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+)
+
+// ErrInvalidSplit is returned for splits that don't add up to their
+// transaction
+var ErrInvalidSplit = errors.New("invalid split")
+
+// transferWindow is how many days apart the two sides of a transfer
+// between the user's accounts may post
+const transferWindow = 3
+
+// transferKeywords mark a transaction as one side of a transfer, along
+// with the transfer categories. Plain "payment" and "deposit" are left
+// out as they name bills and paychecks too.
+var transferKeywords = []string{"transfer", "xfer", "autopay", "card payment", "payment to", "payment thank you"}
+
+// transferCategories are Plaid's categories for money moving between
+// accounts, including card payments
+var transferCategories = []string{"TRANSFER_IN", "TRANSFER_OUT", "LOAN_PAYMENTS_CREDIT_CARD_PAYMENT"}
+
+// Split is part of a transaction in its own category and hat, such as
+// the household and the parent's share of a shop
+type Split struct {
+	Amount   Money      `json:"amount"` // In the transaction's currency, positive for spending
+	Category Category   `json:"category"`
+	HatID    core.HatID `json:"hat_id,omitempty"`
+	Note     string     `json:"note,omitempty"`
+}
+
+// share is the part of a transaction's cost in one category and hat, in
+// minor units of the currency it was converted to
+type share struct {
+	category Category
+	hatID    core.HatID
+	minor    int64
+}
+
+// shares divides amount, a transaction's converted amount, across its
+// splits in proportion, less whatever was reimbursed. The last part
+// takes the rounding.
+func shares(tx *CategorizedTransaction, amount Money) []share {
+	net := amount.Minor
+	if paid := NewMoney(tx.Amount, tx.IsoCurrencyCode); tx.Reimbursed.Minor > 0 && paid.Minor > 0 {
+		net = 0
+		if tx.Reimbursed.Minor < paid.Minor {
+			net = proportion(amount.Minor, paid.Minor-tx.Reimbursed.Minor, paid.Minor)
+		}
+	}
+	if len(tx.Splits) == 0 {
+		return []share{{category: tx.QLCategory, minor: net}}
+	}
+
+	var total int64
+	for _, sp := range tx.Splits {
+		total += sp.Amount.Minor
+	}
+	result := make([]share, len(tx.Splits))
+	left := net
+	for i, sp := range tx.Splits {
+		result[i] = share{category: sp.Category, hatID: sp.HatID}
+		if i == len(tx.Splits)-1 || total == 0 {
+			result[i].minor = left
+			break
+		}
+		result[i].minor = proportion(net, sp.Amount.Minor, total)
+		left -= result[i].minor
+	}
+	return result
+}
+
+// proportion returns part/whole of minor, rounded to the nearest unit.
+// part is no larger than whole, so the result can't overflow.
+func proportion(minor, part, whole int64) int64 {
+	scaled, err := Money{Minor: minor}.mul(big.NewRat(part, whole))
+	if err != nil {
+		return minor
+	}
+	return scaled.Minor
+}
+
+// categories returns the categories a transaction is in, one per split
+func (tx *CategorizedTransaction) categories() []Category {
+	if len(tx.Splits) == 0 {
+		return []Category{tx.QLCategory}
+	}
+	categories := make([]Category, len(tx.Splits))
+	for i, sp := range tx.Splits {
+		categories[i] = sp.Category
+	}
+	return categories
+}
+
+// validateSplits checks that splits are in known categories, share the
+// transaction's currency and sign and add up to its amount. A single part must put
+// the whole transaction in a hat.
+func validateSplits(tx *CategorizedTransaction, splits []Split) error {
+	if len(splits) < 2 && (len(splits) == 0 || splits[0].HatID == "") {
+		return fmt.Errorf("%w: a split needs at least two parts, or one in a hat", ErrInvalidSplit)
+	}
+	total := NewMoney(tx.Amount, tx.IsoCurrencyCode)
+	var sum int64
+	for _, sp := range splits {
+		if !IsValidCategory(sp.Category) {
+			return fmt.Errorf("%w: unknown category: %s", ErrInvalidSplit, sp.Category)
+		}
+		if sp.Amount.Currency != total.Currency {
+			return fmt.Errorf("%w: parts must be in %s", ErrInvalidSplit, total.Currency)
+		}
+		if sp.Amount.Minor == 0 || (sp.Amount.Minor > 0) != (total.Minor > 0) {
+			return fmt.Errorf("%w: parts must have the sign of the transaction", ErrInvalidSplit)
+		}
+		sum += sp.Amount.Minor
+	}
+	if sum != total.Minor {
+		return fmt.Errorf("%w: parts add up to %s, not %s", ErrInvalidSplit,
+			Money{Minor: sum, Currency: total.Currency}, total)
+	}
+	return nil
+}
+
+// SplitTransaction divides a transaction across categories and hats, or
+// puts all of it in a hat with a single part. No splits puts the whole
+// transaction back in its category.
+func (s *Space) SplitTransaction(transactionID string, splits []Split) (*CategorizedTransaction, error) {
+	s.mu.Lock()
+	var tx *CategorizedTransaction
+	for _, t := range s.transactions {
+		if t.TransactionID == transactionID {
+			tx = t
+			break
+		}
+	}
+	if tx == nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+	if len(splits) > 0 {
+		if err := validateSplits(tx, splits); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	store := s.store
+	if store != nil {
+		if err := store.SetTransactionSplits(transactionID, splits); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+	tx.Splits = splits
+	recurring, insights := s.analyze()
+	s.mu.Unlock()
+
+	for _, err := range saveAnalysis(store, recurring, insights) {
+		fmt.Printf("Warning: failed to save analysis: %v\n", err)
+	}
+	return tx, nil
+}
+
+// isTransferLike reports whether a transaction says it moves money, by
+// category or name
+func isTransferLike(tx *CategorizedTransaction) bool {
+	if tx.QLCategory == CategoryTransfer {
+		return true
+	}
+	for _, category := range transferCategories {
+		if tx.PersonalFinanceCategory.Primary == category || tx.PersonalFinanceCategory.Detailed == category {
+			return true
+		}
+	}
+	name := strings.ToLower(tx.Name + " " + tx.MerchantName)
+	for _, keyword := range transferKeywords {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTransfers pairs money leaving one of the user's accounts with the
+// same amount arriving in another within a few days, such as a card
+// payment from checking. Both sides must look like a transfer, so a rent
+// payment and a paycheck of the same amount aren't paired.
+// Each pair is linked through TransferID.
+func matchTransfers(transactions []*CategorizedTransaction) {
+	type credit struct {
+		tx  *CategorizedTransaction
+		day time.Time
+	}
+	credits := make(map[Money][]credit)
+	var debits []*CategorizedTransaction
+	for _, tx := range transactions {
+		tx.TransferID = ""
+		if tx.Amount > 0 {
+			debits = append(debits, tx)
+			continue
+		}
+		if tx.Amount < 0 {
+			day, err := time.Parse("2006-01-02", tx.Date)
+			if err != nil {
+				continue
+			}
+			amount := NewMoney(-tx.Amount, tx.IsoCurrencyCode)
+			credits[amount] = append(credits[amount], credit{tx, day})
+		}
+	}
+	sort.SliceStable(debits, func(i, j int) bool {
+		if debits[i].Date != debits[j].Date {
+			return debits[i].Date < debits[j].Date
+		}
+		return debits[i].TransactionID < debits[j].TransactionID
+	})
+
+	for _, debit := range debits {
+		day, err := time.Parse("2006-01-02", debit.Date)
+		if err != nil {
+			continue
+		}
+		var best *CategorizedTransaction
+		bestGap := transferWindow + 1
+		for _, c := range credits[NewMoney(debit.Amount, debit.IsoCurrencyCode)] {
+			if c.tx.TransferID != "" || c.tx.AccountID == debit.AccountID ||
+				!isTransferLike(debit) || !isTransferLike(c.tx) {
+				continue
+			}
+			gap := int(math.Abs(c.day.Sub(day).Hours()) / 24)
+			if gap < bestGap || (gap == bestGap && best != nil && c.tx.TransactionID < best.TransactionID) {
+				best, bestGap = c.tx, gap
+			}
+		}
+		if best != nil {
+			debit.TransferID = best.TransactionID
+			best.TransferID = debit.TransactionID
+		}
+	}
+}
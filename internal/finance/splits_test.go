@@ -0,0 +1,155 @@
+package finance
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+)
+
+// newLinkedSpace returns a space with a checking account and a card,
+// loaded from a store holding txs
+func newLinkedSpace(t *testing.T, txs ...*CategorizedTransaction) (*Space, *Store) {
+	t.Helper()
+
+	store, _ := newTestStore(t)
+	conn := testConnection()
+	conn.Accounts = append(conn.Accounts, Account{AccountID: "acc_2", Name: "Card", Type: "credit"})
+	if err := store.SaveConnection(conn); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+	if err := store.ApplySync(conn.ID, SyncChanges{Upserted: txs, Cursor: "c1", SyncedAt: time.Now()}); err != nil {
+		t.Fatalf("ApplySync: %v", err)
+	}
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(store)
+	if err := space.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return space, store
+}
+
+func categorized(id, account, name string, amount float64, date string, category Category) *CategorizedTransaction {
+	tx := testTransaction(id, name, amount, date)
+	tx.AccountID = account
+	return &CategorizedTransaction{Transaction: tx, QLCategory: category}
+}
+
+// usd returns amount in dollars
+func usd(amount float64) Money {
+	return NewMoney(amount, "USD")
+}
+
+func TestSpace_SplitsAndTransfers(t *testing.T) {
+	space, store := newLinkedSpace(t,
+		categorized("pay", "acc_1", "Payroll", -3000, "2026-02-01", CategoryIncome),
+		categorized("costco", "acc_1", "Costco", 200, "2026-02-03", CategoryGroceries),
+		// A card payment, posted a day apart
+		categorized("card_out", "acc_1", "Payment to Visa", 500, "2026-02-05", CategoryOther),
+		categorized("card_in", "acc_2", "Payment Thank You", -500, "2026-02-06", CategoryOther),
+		// The same amount both ways, but not a transfer
+		categorized("sofa", "acc_2", "Furniture Barn", 500, "2026-02-10", CategoryShopping),
+		categorized("refund", "acc_1", "Tax refund", -500, "2026-02-11", CategoryIncome),
+	)
+
+	byID := transactionIDs(space.GetTransactions(TransactionFilter{}))
+	if byID["card_out"].TransferID != "card_in" || byID["card_in"].TransferID != "card_out" {
+		t.Errorf("card payment transfer = %q, %q", byID["card_out"].TransferID, byID["card_in"].TransferID)
+	}
+	if byID["sofa"].TransferID != "" || byID["refund"].TransferID != "" {
+		t.Errorf("sofa and refund were matched as a transfer")
+	}
+
+	if _, err := space.SplitTransaction("costco", []Split{
+		{Amount: usd(150), Category: CategoryGroceries},
+		{Amount: usd(40), Category: CategoryShopping},
+	}); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("split short of the amount: error = %v, want ErrInvalidSplit", err)
+	}
+	if _, err := space.SplitTransaction("costco", []Split{{Amount: usd(200), Category: CategoryShopping}}); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("one part without a hat: error = %v, want ErrInvalidSplit", err)
+	}
+	if _, err := space.SplitTransaction("costco", []Split{
+		{Amount: usd(120), Category: CategoryGroceries},
+		{Amount: NewMoney(80, "EUR"), Category: CategoryShopping},
+	}); !errors.Is(err, ErrInvalidSplit) {
+		t.Errorf("part in another currency: error = %v, want ErrInvalidSplit", err)
+	}
+	if _, err := space.SplitTransaction("missing", nil); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("unknown transaction: error = %v, want ErrTransactionNotFound", err)
+	}
+	splits := []Split{
+		{Amount: usd(120), Category: CategoryGroceries, HatID: core.HatHome},
+		{Amount: usd(80), Category: CategoryShopping, HatID: "parent", Note: "Mum's shopping"},
+	}
+	if _, err := space.SplitTransaction("costco", splits); err != nil {
+		t.Fatalf("SplitTransaction: %v", err)
+	}
+
+	summary := space.insightsEngine.GenerateSpendingSummary(space.transactions, "month")
+	if summary.TotalSpent != 700 || summary.TotalIncome != 3500 {
+		t.Errorf("spent %.2f, income %.2f; want 700, 3500 without the card payment", summary.TotalSpent, summary.TotalIncome)
+	}
+	wantCategories := map[Category]float64{CategoryGroceries: 120, CategoryShopping: 580}
+	if !reflect.DeepEqual(summary.ByCategory, wantCategories) {
+		t.Errorf("by category = %v, want %v", summary.ByCategory, wantCategories)
+	}
+	wantHats := map[core.HatID]float64{core.HatHome: 120, "parent": 80}
+	if !reflect.DeepEqual(summary.ByHat, wantHats) {
+		t.Errorf("by hat = %v, want %v", summary.ByHat, wantHats)
+	}
+	shopping := space.GetTransactions(TransactionFilter{Categories: []Category{CategoryShopping}})
+	if ids := transactionIDs(shopping); len(ids) != 2 || ids["costco"] == nil {
+		t.Errorf("shopping transactions = %v", ids)
+	}
+
+	// Splits are stored, and survive updates from the bank
+	reloaded := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	reloaded.SetStore(store)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	costco := transactionIDs(reloaded.GetTransactions(TransactionFilter{}))["costco"]
+	if !reflect.DeepEqual(costco.Splits, splits) {
+		t.Errorf("reloaded splits = %+v", costco.Splits)
+	}
+	updated := categorized("costco", "acc_1", "COSTCO WHOLESALE", 200, "2026-02-03", CategoryGroceries)
+	reloaded.transactions = mergeTransactions(reloaded.transactions, []*CategorizedTransaction{updated}, nil)
+	if !reflect.DeepEqual(updated.Splits, splits) {
+		t.Errorf("splits after a bank update = %+v", updated.Splits)
+	}
+
+	if _, err := reloaded.SplitTransaction("costco", nil); err != nil {
+		t.Fatalf("SplitTransaction(nil): %v", err)
+	}
+	txs, err := store.Transactions()
+	if err != nil {
+		t.Fatalf("Transactions: %v", err)
+	}
+	if got := transactionIDs(txs)["costco"].Splits; got != nil {
+		t.Errorf("stored splits after removal = %+v", got)
+	}
+}
+
+func TestMatchTransfers_BothSidesMustLookLikeTransfers(t *testing.T) {
+	rent := categorized("rent", "acc_1", "Rent payment", 2000, "2026-03-01", CategoryBills)
+	paycheck := categorized("paycheck", "acc_2", "ACME DIRECT DEPOSIT", -2000, "2026-03-01", CategoryIncome)
+	toSavings := categorized("to_savings", "acc_1", "Online transfer to savings", 2000, "2026-03-02", CategoryOther)
+	matchTransfers([]*CategorizedTransaction{rent, paycheck, toSavings})
+	if rent.TransferID != "" || paycheck.TransferID != "" || toSavings.TransferID != "" {
+		t.Errorf("rent %q, paycheck %q, transfer %q; want no transfers", rent.TransferID, paycheck.TransferID, toSavings.TransferID)
+	}
+
+	// Plaid's category marks a card payment even without the words
+	fromChecking := categorized("card_out", "acc_1", "VISA 1234", 2000, "2026-03-01", CategoryBills)
+	fromChecking.PersonalFinanceCategory = PersonalFinanceCategory{Primary: "LOAN_PAYMENTS", Detailed: "LOAN_PAYMENTS_CREDIT_CARD_PAYMENT"}
+	toCard := categorized("card_in", "acc_2", "THANK YOU", -2000, "2026-03-02", CategoryOther)
+	toCard.PersonalFinanceCategory = PersonalFinanceCategory{Primary: "TRANSFER_IN", Detailed: "TRANSFER_IN_ACCOUNT_TRANSFER"}
+	matchTransfers([]*CategorizedTransaction{rent, paycheck, fromChecking, toCard})
+	if fromChecking.TransferID != "card_in" || toCard.TransferID != "card_out" || paycheck.TransferID != "" {
+		t.Errorf("card payment transfer = %q, %q; paycheck %q", fromChecking.TransferID, toCard.TransferID, paycheck.TransferID)
+	}
+}
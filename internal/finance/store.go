@@ -0,0 +1,1036 @@
+package finance
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/identity"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+// Store persists connections, accounts, transactions, recurring
+// transactions and insights. Access tokens are encrypted with the
+// identity's key, so the identity must be unlocked.
+type Store struct {
+	db       *storage.DB
+	identity *identity.Manager
+	userID   string
+}
+
+// NewStore creates a finance store for the identity userID
+func NewStore(db *storage.DB, identity *identity.Manager, userID string) *Store {
+	return &Store{
+		db:       db,
+		identity: identity,
+		userID:   userID,
+	}
+}
+
+// SyncChanges are the changes of one connection's sync, stored together
+// with the cursor that follows them
+type SyncChanges struct {
+	Accounts []Account                 // Refreshed balances, may be empty
+	Upserted []*CategorizedTransaction // Added or modified
+	Removed  []string                  // Plaid transaction IDs
+	Cursor   string
+	SyncedAt time.Time
+}
+
+// accountKey is the bank_accounts row ID of a Plaid account
+func accountKey(connectionID, accountID string) string {
+	return connectionID + ":" + accountID
+}
+
+// SaveConnection inserts or updates a connection and its accounts
+func (s *Store) SaveConnection(conn *Connection) error {
+	// Imported statements have no token, and so need no unlocked identity
+	var token string
+	if conn.AccessToken != "" {
+		encrypted, err := s.identity.Encrypt([]byte(conn.AccessToken))
+		if err != nil {
+			return fmt.Errorf("encrypt access token: %w", err)
+		}
+		token = base64.StdEncoding.EncodeToString(encrypted)
+	}
+
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO bank_connections (
+				id, user_id, item_id, institution_id, institution_name,
+				access_token_encrypted, status, sync_cursor, last_sync,
+				created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				institution_name = excluded.institution_name,
+				access_token_encrypted = excluded.access_token_encrypted,
+				status = excluded.status,
+				sync_cursor = excluded.sync_cursor,
+				last_sync = excluded.last_sync,
+				updated_at = excluded.updated_at
+		`,
+			conn.ID, s.userID, conn.ItemID, conn.InstitutionID, conn.InstitutionName,
+			token, conn.Status, conn.SyncCursor, nullTime(conn.LastSync),
+			conn.CreatedAt.UTC(), time.Now().UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("save connection: %w", err)
+		}
+		return saveAccounts(tx, conn.ID, conn.Accounts)
+	})
+}
+
+// UpdateConnectionStatus records a connection's status
+func (s *Store) UpdateConnectionStatus(connectionID, status string) error {
+	_, err := s.db.Conn().Exec(`
+		UPDATE bank_connections SET status = ?, updated_at = ? WHERE id = ?
+	`, status, time.Now().UTC(), connectionID)
+	if err != nil {
+		return fmt.Errorf("update connection status: %w", err)
+	}
+	return nil
+}
+
+// SaveAccounts stores refreshed accounts of a connection
+func (s *Store) SaveAccounts(connectionID string, accounts []Account) error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		return saveAccounts(tx, connectionID, accounts)
+	})
+}
+
+func saveAccounts(tx *sql.Tx, connectionID string, accounts []Account) error {
+	now := time.Now().UTC()
+	for _, a := range accounts {
+		_, err := tx.Exec(`
+			INSERT INTO bank_accounts (
+				id, connection_id, account_id, name, official_name, type, subtype, mask,
+				current_balance, available_balance, credit_limit, currency_code,
+				created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET
+				name = excluded.name,
+				official_name = excluded.official_name,
+				type = excluded.type,
+				subtype = excluded.subtype,
+				mask = excluded.mask,
+				current_balance = excluded.current_balance,
+				available_balance = excluded.available_balance,
+				credit_limit = excluded.credit_limit,
+				currency_code = excluded.currency_code,
+				updated_at = excluded.updated_at
+		`,
+			accountKey(connectionID, a.AccountID), connectionID, a.AccountID, a.Name, a.OfficialName,
+			a.Type, a.Subtype, a.Mask,
+			a.Balances.Current, a.Balances.Available, a.Balances.Limit, a.Balances.IsoCurrencyCode,
+			now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("save account %s: %w", a.AccountID, err)
+		}
+	}
+	return nil
+}
+
+// Connections loads every connection with its accounts and decrypted
+// access token. While the identity is locked tokens are left empty, so
+// stored data can be read but connections cannot be synced.
+func (s *Store) Connections() ([]*Connection, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT id, item_id, institution_id, institution_name, access_token_encrypted,
+			status, COALESCE(sync_cursor, ''), last_sync, created_at, updated_at
+		FROM bank_connections
+		WHERE user_id = ?
+		ORDER BY created_at
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query connections: %w", err)
+	}
+
+	var connections []*Connection
+	var tokens []string
+	for rows.Next() {
+		conn := &Connection{UserID: s.userID}
+		var token string
+		var lastSync sql.NullTime
+		if err := rows.Scan(&conn.ID, &conn.ItemID, &conn.InstitutionID, &conn.InstitutionName, &token,
+			&conn.Status, &conn.SyncCursor, &lastSync, &conn.CreatedAt, &conn.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan connection: %w", err)
+		}
+		if lastSync.Valid {
+			conn.LastSync = lastSync.Time
+		}
+		connections = append(connections, conn)
+		tokens = append(tokens, token)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate connections: %w", err)
+	}
+
+	for i, conn := range connections {
+		if tokens[i] != "" && s.identity.IsUnlocked() {
+			encrypted, err := base64.StdEncoding.DecodeString(tokens[i])
+			if err != nil {
+				return nil, fmt.Errorf("decode access token of %s: %w", conn.ID, err)
+			}
+			token, err := s.identity.Decrypt(encrypted)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt access token of %s: %w", conn.ID, err)
+			}
+			conn.AccessToken = string(token)
+		}
+
+		var err error
+		if conn.Accounts, err = s.accounts(conn.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return connections, nil
+}
+
+func (s *Store) accounts(connectionID string) ([]Account, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT account_id, name, COALESCE(official_name, ''), type, COALESCE(subtype, ''),
+			COALESCE(mask, ''), COALESCE(current_balance, 0), COALESCE(available_balance, 0),
+			COALESCE(credit_limit, 0), COALESCE(currency_code, '')
+		FROM bank_accounts
+		WHERE connection_id = ?
+		ORDER BY account_id
+	`, connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("query accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var a Account
+		if err := rows.Scan(&a.AccountID, &a.Name, &a.OfficialName, &a.Type, &a.Subtype, &a.Mask,
+			&a.Balances.Current, &a.Balances.Available, &a.Balances.Limit, &a.Balances.IsoCurrencyCode); err != nil {
+			return nil, fmt.Errorf("scan account: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+// DeleteConnection removes a connection along with its accounts and
+// transactions
+func (s *Store) DeleteConnection(connectionID string) error {
+	_, err := s.db.Conn().Exec(`DELETE FROM bank_connections WHERE id = ?`, connectionID)
+	if err != nil {
+		return fmt.Errorf("delete connection: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes every connection, recurring transaction, insight and
+// reimbursement of the user
+func (s *Store) DeleteAll() error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		for _, table := range []string{"bank_connections", "recurring_transactions", "financial_insights", "reimbursements"} {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE user_id = ?`, s.userID); err != nil {
+				return fmt.Errorf("clear %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// ApplySync stores one sync of a connection: refreshed accounts, added
+// and modified transactions, removals and the new cursor. Either all of
+// it is stored or none, so a failed sync resumes from the old cursor.
+func (s *Store) ApplySync(connectionID string, changes SyncChanges) error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		if err := saveAccounts(tx, connectionID, changes.Accounts); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		for _, t := range changes.Upserted {
+			if err := upsertTransaction(tx, connectionID, t, now); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range changes.Removed {
+			_, err := tx.Exec(`
+				DELETE FROM transactions
+				WHERE transaction_id = ?
+				  AND account_id IN (SELECT id FROM bank_accounts WHERE connection_id = ?)
+			`, id, connectionID)
+			if err != nil {
+				return fmt.Errorf("remove transaction %s: %w", id, err)
+			}
+		}
+
+		_, err := tx.Exec(`
+			UPDATE bank_connections SET sync_cursor = ?, last_sync = ?, updated_at = ? WHERE id = ?
+		`, changes.Cursor, changes.SyncedAt.UTC(), now, connectionID)
+		if err != nil {
+			return fmt.Errorf("update cursor: %w", err)
+		}
+		return nil
+	})
+}
+
+func upsertTransaction(tx *sql.Tx, connectionID string, t *CategorizedTransaction, now time.Time) error {
+	accountID := accountKey(connectionID, t.AccountID)
+
+	// Transactions can name an account that was opened after the last
+	// balance refresh
+	_, err := tx.Exec(`
+		INSERT OR IGNORE INTO bank_accounts (id, connection_id, account_id, name, type, created_at, updated_at)
+		VALUES (?, ?, ?, ?, '', ?, ?)
+	`, accountID, connectionID, t.AccountID, t.AccountID, now, now)
+	if err != nil {
+		return fmt.Errorf("save account %s: %w", t.AccountID, err)
+	}
+
+	category, _ := json.Marshal(t.Category)
+	pfc, _ := json.Marshal(t.PersonalFinanceCategory)
+	location, _ := json.Marshal(t.Location)
+	tags, _ := json.Marshal(t.Tags)
+
+	_, err = tx.Exec(`
+		INSERT INTO transactions (
+			id, account_id, transaction_id, amount, date, authorized_date, name, merchant_name,
+			plaid_category, plaid_category_id, personal_finance_category,
+			ql_category, subcategory, confidence, payment_channel, pending, location_json,
+			is_recurring, recurring_id, tags, iso_currency_code, rule_id, user_categorized,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			account_id = excluded.account_id,
+			amount = excluded.amount,
+			date = excluded.date,
+			authorized_date = excluded.authorized_date,
+			name = excluded.name,
+			merchant_name = excluded.merchant_name,
+			plaid_category = excluded.plaid_category,
+			plaid_category_id = excluded.plaid_category_id,
+			personal_finance_category = excluded.personal_finance_category,
+			-- A category the user chose survives updates from the bank
+			ql_category = CASE WHEN transactions.user_categorized THEN transactions.ql_category ELSE excluded.ql_category END,
+			subcategory = excluded.subcategory,
+			confidence = CASE WHEN transactions.user_categorized THEN transactions.confidence ELSE excluded.confidence END,
+			rule_id = CASE WHEN transactions.user_categorized THEN transactions.rule_id ELSE excluded.rule_id END,
+			user_categorized = transactions.user_categorized OR excluded.user_categorized,
+			payment_channel = excluded.payment_channel,
+			pending = excluded.pending,
+			location_json = excluded.location_json,
+			is_recurring = excluded.is_recurring,
+			recurring_id = excluded.recurring_id,
+			tags = excluded.tags,
+			iso_currency_code = excluded.iso_currency_code,
+			updated_at = excluded.updated_at
+	`,
+		t.TransactionID, accountID, t.TransactionID, t.Amount, t.Date, t.AuthorizedDate, t.Name, t.MerchantName,
+		string(category), t.CategoryID, string(pfc),
+		string(t.QLCategory), t.Subcategory, t.Confidence, t.PaymentChannel, t.Pending, string(location),
+		t.IsRecurring, t.RecurringID, string(tags), t.IsoCurrencyCode, nullString(t.RuleID), t.UserCategorized,
+		now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("save transaction %s: %w", t.TransactionID, err)
+	}
+	return nil
+}
+
+// Transactions loads every stored transaction, newest first
+func (s *Store) Transactions() ([]*CategorizedTransaction, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT t.transaction_id, a.account_id, t.amount, t.date, COALESCE(t.authorized_date, ''),
+			t.name, COALESCE(t.merchant_name, ''), COALESCE(t.plaid_category, ''),
+			COALESCE(t.plaid_category_id, ''), COALESCE(t.personal_finance_category, ''),
+			t.ql_category, COALESCE(t.subcategory, ''), COALESCE(t.confidence, 0),
+			COALESCE(t.payment_channel, ''), COALESCE(t.pending, 0), COALESCE(t.location_json, ''),
+			COALESCE(t.is_recurring, 0), COALESCE(t.recurring_id, ''), COALESCE(t.tags, ''),
+			COALESCE(t.iso_currency_code, ''), COALESCE(t.rule_id, ''), COALESCE(t.user_categorized, 0),
+			COALESCE(t.splits, ''), t.updated_at
+		FROM transactions t
+		JOIN bank_accounts a ON a.id = t.account_id
+		JOIN bank_connections c ON c.id = a.connection_id
+		WHERE c.user_id = ?
+		ORDER BY t.date DESC, t.transaction_id
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []*CategorizedTransaction
+	for rows.Next() {
+		t := &CategorizedTransaction{}
+		var category, pfc, location, tags, qlCategory, splits string
+		if err := rows.Scan(&t.TransactionID, &t.AccountID, &t.Amount, &t.Date, &t.AuthorizedDate,
+			&t.Name, &t.MerchantName, &category, &t.CategoryID, &pfc,
+			&qlCategory, &t.Subcategory, &t.Confidence,
+			&t.PaymentChannel, &t.Pending, &location,
+			&t.IsRecurring, &t.RecurringID, &tags,
+			&t.IsoCurrencyCode, &t.RuleID, &t.UserCategorized,
+			&splits, &t.CategorizedAt); err != nil {
+			return nil, fmt.Errorf("scan transaction: %w", err)
+		}
+		t.QLCategory = Category(qlCategory)
+		unmarshalJSON(category, &t.Category)
+		unmarshalJSON(pfc, &t.PersonalFinanceCategory)
+		unmarshalJSON(location, &t.Location)
+		unmarshalJSON(tags, &t.Tags)
+		unmarshalJSON(splits, &t.Splits)
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+// SaveRecurring replaces the stored recurring transactions and marks the
+// transactions that belong to them
+func (s *Store) SaveRecurring(recurring []*RecurringTransaction) error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM recurring_transactions WHERE user_id = ?`, s.userID); err != nil {
+			return fmt.Errorf("clear recurring: %w", err)
+		}
+		_, err := tx.Exec(`
+			UPDATE transactions SET is_recurring = FALSE, recurring_id = NULL
+			WHERE account_id IN (`+userAccounts+`)
+		`, s.userID)
+		if err != nil {
+			return fmt.Errorf("clear recurring flags: %w", err)
+		}
+
+		now := time.Now().UTC()
+		for _, r := range recurring {
+			ids, _ := json.Marshal(r.Transactions)
+			_, err := tx.Exec(`
+				INSERT OR REPLACE INTO recurring_transactions (
+					id, user_id, merchant_name, category, average_amount, frequency, day_of_month,
+					next_expected, last_seen, is_active, transaction_ids, created_at, updated_at
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`,
+				r.ID, s.userID, r.MerchantName, string(r.Category), r.Amount, r.Frequency, r.DayOfMonth,
+				nullTime(r.NextExpected), nullTime(r.LastSeen), r.IsActive, string(ids), now, now,
+			)
+			if err != nil {
+				return fmt.Errorf("save recurring %s: %w", r.ID, err)
+			}
+
+			for _, id := range r.Transactions {
+				_, err := tx.Exec(`
+					UPDATE transactions SET is_recurring = TRUE, recurring_id = ?
+					WHERE transaction_id = ? AND account_id IN (`+userAccounts+`)
+				`, r.ID, id, s.userID)
+				if err != nil {
+					return fmt.Errorf("mark recurring %s: %w", id, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// userAccounts selects the bank_accounts IDs of a user
+const userAccounts = `
+	SELECT a.id FROM bank_accounts a
+	JOIN bank_connections c ON c.id = a.connection_id
+	WHERE c.user_id = ?`
+
+// Recurring loads the stored recurring transactions
+func (s *Store) Recurring() ([]*RecurringTransaction, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT id, merchant_name, category, average_amount, frequency, COALESCE(day_of_month, 0),
+			next_expected, last_seen, COALESCE(is_active, 0), COALESCE(transaction_ids, '')
+		FROM recurring_transactions
+		WHERE user_id = ?
+		ORDER BY merchant_name, id
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query recurring: %w", err)
+	}
+	defer rows.Close()
+
+	var recurring []*RecurringTransaction
+	for rows.Next() {
+		r := &RecurringTransaction{}
+		var category, ids string
+		var next, last sql.NullTime
+		if err := rows.Scan(&r.ID, &r.MerchantName, &category, &r.Amount, &r.Frequency, &r.DayOfMonth,
+			&next, &last, &r.IsActive, &ids); err != nil {
+			return nil, fmt.Errorf("scan recurring: %w", err)
+		}
+		r.Category = Category(category)
+		r.NextExpected = next.Time
+		r.LastSeen = last.Time
+		unmarshalJSON(ids, &r.Transactions)
+		recurring = append(recurring, r)
+	}
+	return recurring, rows.Err()
+}
+
+// SaveInsights replaces the stored insights
+func (s *Store) SaveInsights(insights []*Insight) error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM financial_insights WHERE user_id = ?`, s.userID); err != nil {
+			return fmt.Errorf("clear insights: %w", err)
+		}
+
+		for _, in := range insights {
+			var data []byte
+			if in.Data != nil {
+				data, _ = json.Marshal(in.Data)
+			}
+			createdAt := in.CreatedAt
+			if createdAt.IsZero() {
+				createdAt = time.Now()
+			}
+			_, err := tx.Exec(`
+				INSERT OR REPLACE INTO financial_insights (
+					id, user_id, type, title, description, severity, amount, category,
+					period, trend, data_json, expires_at, created_at
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`,
+				in.ID, s.userID, string(in.Type), in.Title, in.Description, string(in.Severity), in.Amount,
+				string(in.Category), in.Period, in.Trend, string(data), nullTime(in.ExpiresAt), createdAt.UTC(),
+			)
+			if err != nil {
+				return fmt.Errorf("save insight %s: %w", in.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Insights loads the stored insights that have not been dismissed
+func (s *Store) Insights() ([]*Insight, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT id, type, title, description, COALESCE(severity, ''), COALESCE(amount, 0),
+			COALESCE(category, ''), COALESCE(period, ''), COALESCE(trend, ''), COALESCE(data_json, ''),
+			expires_at, created_at
+		FROM financial_insights
+		WHERE user_id = ? AND NOT COALESCE(dismissed, 0)
+		ORDER BY created_at, id
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query insights: %w", err)
+	}
+	defer rows.Close()
+
+	var insights []*Insight
+	for rows.Next() {
+		in := &Insight{}
+		var typ, severity, category, data string
+		var expires sql.NullTime
+		if err := rows.Scan(&in.ID, &typ, &in.Title, &in.Description, &severity, &in.Amount,
+			&category, &in.Period, &in.Trend, &data, &expires, &in.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan insight: %w", err)
+		}
+		in.Type = InsightType(typ)
+		in.Severity = Severity(severity)
+		in.Category = Category(category)
+		in.ExpiresAt = expires.Time
+		unmarshalJSON(data, &in.Data)
+		insights = append(insights, in)
+	}
+	return insights, rows.Err()
+}
+
+// SetTransactionCategory records the category the user chose for a
+// transaction
+func (s *Store) SetTransactionCategory(transactionID string, category Category) error {
+	result, err := s.db.Conn().Exec(`
+		UPDATE transactions
+		SET ql_category = ?, confidence = 1.0, rule_id = NULL, user_categorized = TRUE, updated_at = ?
+		WHERE transaction_id = ? AND account_id IN (`+userAccounts+`)
+	`, string(category), time.Now().UTC(), transactionID, s.userID)
+	if err != nil {
+		return fmt.Errorf("update category: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+	return nil
+}
+
+// SetTransactionSplits records how the user split a transaction. No
+// splits removes them.
+func (s *Store) SetTransactionSplits(transactionID string, splits []Split) error {
+	var data interface{}
+	if len(splits) > 0 {
+		encoded, _ := json.Marshal(splits)
+		data = string(encoded)
+	}
+	result, err := s.db.Conn().Exec(`
+		UPDATE transactions SET splits = ?, updated_at = ?
+		WHERE transaction_id = ? AND account_id IN (`+userAccounts+`)
+	`, data, time.Now().UTC(), transactionID, s.userID)
+	if err != nil {
+		return fmt.Errorf("update splits: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionID)
+	}
+	return nil
+}
+
+// RecordCorrection remembers that the user moved a transaction from
+// merchant to category, and returns how many of the merchant's
+// transactions the user has moved there
+func (s *Store) RecordCorrection(transactionID, merchant string, category Category) (int, error) {
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO category_corrections (user_id, transaction_id, merchant, category, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, transaction_id) DO UPDATE SET
+			merchant = excluded.merchant,
+			category = excluded.category,
+			created_at = excluded.created_at
+	`, s.userID, transactionID, merchant, string(category), time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("save correction: %w", err)
+	}
+
+	var count int
+	err = s.db.Conn().QueryRow(`
+		SELECT COUNT(*) FROM category_corrections
+		WHERE user_id = ? AND merchant = ? AND category = ?
+	`, s.userID, merchant, string(category)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count corrections: %w", err)
+	}
+	return count, nil
+}
+
+// SaveRule creates or updates a categorization rule
+func (s *Store) SaveRule(rule *CategoryRule) error {
+	now := time.Now().UTC()
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO category_rules (
+			id, user_id, merchant, pattern, min_amount, max_amount, category, priority, source,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			merchant = excluded.merchant,
+			pattern = excluded.pattern,
+			min_amount = excluded.min_amount,
+			max_amount = excluded.max_amount,
+			category = excluded.category,
+			priority = excluded.priority,
+			source = excluded.source,
+			updated_at = excluded.updated_at
+	`,
+		rule.ID, s.userID, nullString(rule.Merchant), nullString(rule.Pattern), rule.MinAmount, rule.MaxAmount,
+		string(rule.Category), rule.Priority, string(rule.Source), rule.CreatedAt.UTC(), now,
+	)
+	if err != nil {
+		return fmt.Errorf("save rule %s: %w", rule.ID, err)
+	}
+	return nil
+}
+
+// Rules loads the stored categorization rules
+func (s *Store) Rules() ([]*CategoryRule, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT id, COALESCE(merchant, ''), COALESCE(pattern, ''), min_amount, max_amount,
+			category, priority, source, created_at
+		FROM category_rules
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*CategoryRule
+	for rows.Next() {
+		r := &CategoryRule{}
+		var category, source string
+		var minAmount, maxAmount sql.NullFloat64
+		if err := rows.Scan(&r.ID, &r.Merchant, &r.Pattern, &minAmount, &maxAmount,
+			&category, &r.Priority, &source, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan rule: %w", err)
+		}
+		r.Category = Category(category)
+		r.Source = RuleSource(source)
+		if minAmount.Valid {
+			r.MinAmount = &minAmount.Float64
+		}
+		if maxAmount.Valid {
+			r.MaxAmount = &maxAmount.Float64
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteRule removes a categorization rule
+func (s *Store) DeleteRule(id string) error {
+	result, err := s.db.Conn().Exec(`DELETE FROM category_rules WHERE id = ? AND user_id = ?`, id, s.userID)
+	if err != nil {
+		return fmt.Errorf("delete rule: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, id)
+	}
+	return nil
+}
+
+// BudgetPeriod loads how budget periods repeat, or the default if the
+// user hasn't chosen
+func (s *Store) BudgetPeriod() (BudgetPeriod, error) {
+	var p BudgetPeriod
+	var kind string
+	var startDay, days sql.NullInt64
+	var anchor sql.NullString
+	err := s.db.Conn().QueryRow(`
+		SELECT period_kind, start_day, anchor, days FROM budget_settings WHERE user_id = ?
+	`, s.userID).Scan(&kind, &startDay, &anchor, &days)
+	if err == sql.ErrNoRows {
+		return DefaultBudgetPeriod(), nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("query budget period: %w", err)
+	}
+	p.Kind = BudgetPeriodKind(kind)
+	p.StartDay = int(startDay.Int64)
+	p.Anchor = anchor.String
+	p.Days = int(days.Int64)
+	return p, nil
+}
+
+// SaveBudgetPeriod stores how budget periods repeat
+func (s *Store) SaveBudgetPeriod(p BudgetPeriod) error {
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO budget_settings (user_id, period_kind, start_day, anchor, days, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			period_kind = excluded.period_kind,
+			start_day = excluded.start_day,
+			anchor = excluded.anchor,
+			days = excluded.days,
+			updated_at = excluded.updated_at
+	`, s.userID, string(p.Kind), p.StartDay, nullString(p.Anchor), p.Days, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("save budget period: %w", err)
+	}
+	return nil
+}
+
+// SaveEnvelope creates or updates a category's budget envelope
+func (s *Store) SaveEnvelope(env *Envelope) error {
+	now := time.Now().UTC()
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO budgets (user_id, category, amount_minor, currency, rollover, starts_on, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, category) DO UPDATE SET
+			amount_minor = excluded.amount_minor,
+			currency = excluded.currency,
+			rollover = excluded.rollover,
+			starts_on = excluded.starts_on,
+			updated_at = excluded.updated_at
+	`, s.userID, string(env.Category), env.Amount.Minor, env.Amount.Currency, env.Rollover, env.StartsOn, now, now)
+	if err != nil {
+		return fmt.Errorf("save budget %s: %w", env.Category, err)
+	}
+	return nil
+}
+
+// Envelopes loads the budget envelopes
+func (s *Store) Envelopes() ([]*Envelope, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT category, amount_minor, currency, rollover, starts_on, updated_at
+		FROM budgets
+		WHERE user_id = ?
+		ORDER BY category
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var envelopes []*Envelope
+	for rows.Next() {
+		env := &Envelope{}
+		var category string
+		if err := rows.Scan(&category, &env.Amount.Minor, &env.Amount.Currency, &env.Rollover,
+			&env.StartsOn, &env.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan budget: %w", err)
+		}
+		env.Category = Category(category)
+		envelopes = append(envelopes, env)
+	}
+	return envelopes, rows.Err()
+}
+
+// DeleteEnvelope removes a category's envelope and its recorded periods
+func (s *Store) DeleteEnvelope(category Category) error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`DELETE FROM budgets WHERE user_id = ? AND category = ?`, s.userID, string(category))
+		if err != nil {
+			return fmt.Errorf("delete budget: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return fmt.Errorf("%w: %s", ErrBudgetNotFound, category)
+		}
+		if _, err := tx.Exec(`DELETE FROM budget_periods WHERE user_id = ? AND category = ?`, s.userID, string(category)); err != nil {
+			return fmt.Errorf("delete budget history: %w", err)
+		}
+		return nil
+	})
+}
+
+// SaveBudgetHistory records envelopes' budget against actual spending,
+// replacing what was recorded for the same periods
+func (s *Store) SaveBudgetHistory(history []EnvelopeStatus) error {
+	if len(history) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		for _, st := range history {
+			_, err := tx.Exec(`
+				INSERT INTO budget_periods (
+					user_id, category, period_start, period_end, currency, budgeted_minor,
+					carried_minor, transferred_minor, spent_minor, available_minor, updated_at
+				) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(user_id, category, period_start) DO UPDATE SET
+					period_end = excluded.period_end,
+					currency = excluded.currency,
+					budgeted_minor = excluded.budgeted_minor,
+					carried_minor = excluded.carried_minor,
+					transferred_minor = excluded.transferred_minor,
+					spent_minor = excluded.spent_minor,
+					available_minor = excluded.available_minor,
+					updated_at = excluded.updated_at
+			`,
+				s.userID, string(st.Category), st.PeriodStart, st.PeriodEnd, st.Budgeted.Currency, st.Budgeted.Minor,
+				st.CarriedOver.Minor, st.Transferred.Minor, st.Spent.Minor, st.Available.Minor, now,
+			)
+			if err != nil {
+				return fmt.Errorf("save budget period %s %s: %w", st.Category, st.PeriodStart, err)
+			}
+		}
+		return nil
+	})
+}
+
+// BudgetHistory loads the recorded budget periods, oldest first
+func (s *Store) BudgetHistory() ([]EnvelopeStatus, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT category, period_start, period_end, currency, budgeted_minor,
+			carried_minor, transferred_minor, spent_minor, available_minor
+		FROM budget_periods
+		WHERE user_id = ?
+		ORDER BY period_start, category
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query budget history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []EnvelopeStatus
+	for rows.Next() {
+		var st EnvelopeStatus
+		var category, currency string
+		if err := rows.Scan(&category, &st.PeriodStart, &st.PeriodEnd, &currency, &st.Budgeted.Minor,
+			&st.CarriedOver.Minor, &st.Transferred.Minor, &st.Spent.Minor, &st.Available.Minor); err != nil {
+			return nil, fmt.Errorf("scan budget period: %w", err)
+		}
+		st.Category = Category(category)
+		for _, m := range []*Money{&st.Budgeted, &st.CarriedOver, &st.Transferred, &st.Spent, &st.Available} {
+			m.Currency = currency
+		}
+		history = append(history, st)
+	}
+	return history, rows.Err()
+}
+
+// SaveBudgetTransfer records money moved between envelopes
+func (s *Store) SaveBudgetTransfer(t *BudgetTransfer) error {
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO budget_transfers (id, user_id, period_start, from_category, to_category, amount_minor, currency, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, t.ID, s.userID, t.PeriodStart, string(t.From), string(t.To), t.Amount.Minor, t.Amount.Currency,
+		nullString(t.Note), t.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save budget transfer: %w", err)
+	}
+	return nil
+}
+
+// BudgetTransfers loads the money moved between envelopes, oldest first
+func (s *Store) BudgetTransfers() ([]*BudgetTransfer, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT id, period_start, from_category, to_category, amount_minor, currency, COALESCE(note, ''), created_at
+		FROM budget_transfers
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query budget transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []*BudgetTransfer
+	for rows.Next() {
+		t := &BudgetTransfer{}
+		var from, to string
+		if err := rows.Scan(&t.ID, &t.PeriodStart, &from, &to, &t.Amount.Minor, &t.Amount.Currency,
+			&t.Note, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan budget transfer: %w", err)
+		}
+		t.From, t.To = Category(from), Category(to)
+		transfers = append(transfers, t)
+	}
+	return transfers, rows.Err()
+}
+
+// SavePlannedExpense creates or updates a planned expense
+func (s *Store) SavePlannedExpense(p *PlannedExpense) error {
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO planned_expenses (id, user_id, name, amount_minor, currency, date, account_id, category, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			amount_minor = excluded.amount_minor,
+			currency = excluded.currency,
+			date = excluded.date,
+			account_id = excluded.account_id,
+			category = excluded.category,
+			note = excluded.note
+	`, p.ID, s.userID, p.Name, p.Amount.Minor, p.Amount.Currency, p.Date, nullString(p.AccountID),
+		nullString(string(p.Category)), nullString(p.Note), p.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save planned expense %s: %w", p.ID, err)
+	}
+	return nil
+}
+
+// PlannedExpenses loads the planned expenses, soonest first
+func (s *Store) PlannedExpenses() ([]*PlannedExpense, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT id, name, amount_minor, currency, date, COALESCE(account_id, ''), COALESCE(category, ''),
+			COALESCE(note, ''), created_at
+		FROM planned_expenses
+		WHERE user_id = ?
+		ORDER BY date, id
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query planned expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var planned []*PlannedExpense
+	for rows.Next() {
+		p := &PlannedExpense{}
+		var category string
+		if err := rows.Scan(&p.ID, &p.Name, &p.Amount.Minor, &p.Amount.Currency, &p.Date, &p.AccountID,
+			&category, &p.Note, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan planned expense: %w", err)
+		}
+		p.Category = Category(category)
+		planned = append(planned, p)
+	}
+	return planned, rows.Err()
+}
+
+// DeletePlannedExpense removes a planned expense
+func (s *Store) DeletePlannedExpense(id string) error {
+	result, err := s.db.Conn().Exec(`DELETE FROM planned_expenses WHERE id = ? AND user_id = ?`, id, s.userID)
+	if err != nil {
+		return fmt.Errorf("delete planned expense: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrPlannedExpenseNotFound, id)
+	}
+	return nil
+}
+
+// SaveReimbursement creates or updates the reimbursement expected for an
+// expense
+func (s *Store) SaveReimbursement(r *Reimbursement) error {
+	_, err := s.db.Conn().Exec(`
+		INSERT INTO reimbursements (id, user_id, transaction_id, amount_minor, currency, payer, note, credit_id, received_on, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, transaction_id) DO UPDATE SET
+			amount_minor = excluded.amount_minor,
+			currency = excluded.currency,
+			payer = excluded.payer,
+			note = excluded.note,
+			credit_id = excluded.credit_id,
+			received_on = excluded.received_on
+	`, r.ID, s.userID, r.TransactionID, r.Amount.Minor, r.Amount.Currency, nullString(r.Payer), nullString(r.Note),
+		nullString(r.CreditID), nullString(r.ReceivedOn), r.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save reimbursement %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// Reimbursements loads the expected reimbursements, oldest first
+func (s *Store) Reimbursements() ([]*Reimbursement, error) {
+	rows, err := s.db.Conn().Query(`
+		SELECT id, transaction_id, amount_minor, currency, COALESCE(payer, ''), COALESCE(note, ''),
+			COALESCE(credit_id, ''), COALESCE(received_on, ''), created_at
+		FROM reimbursements
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`, s.userID)
+	if err != nil {
+		return nil, fmt.Errorf("query reimbursements: %w", err)
+	}
+	defer rows.Close()
+
+	var reimbursements []*Reimbursement
+	for rows.Next() {
+		r := &Reimbursement{Status: ReimbursementPending}
+		if err := rows.Scan(&r.ID, &r.TransactionID, &r.Amount.Minor, &r.Amount.Currency, &r.Payer, &r.Note,
+			&r.CreditID, &r.ReceivedOn, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan reimbursement: %w", err)
+		}
+		if r.CreditID != "" {
+			r.Status = ReimbursementReceived
+		}
+		reimbursements = append(reimbursements, r)
+	}
+	return reimbursements, rows.Err()
+}
+
+// DeleteReimbursement removes an expected reimbursement
+func (s *Store) DeleteReimbursement(id string) error {
+	result, err := s.db.Conn().Exec(`DELETE FROM reimbursements WHERE id = ? AND user_id = ?`, id, s.userID)
+	if err != nil {
+		return fmt.Errorf("delete reimbursement: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrReimbursementNotFound, id)
+	}
+	return nil
+}
+
+// nullString stores empty strings as NULL
+func nullString(v string) interface{} {
+	if v == "" {
+		return nil
+	}
+	return v
+}
+
+// nullTime stores zero times as NULL
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// unmarshalJSON decodes a stored JSON column, leaving v unset if the
+// column is empty or malformed
+func unmarshalJSON(data string, v interface{}) {
+	if data == "" || data == "null" {
+		return
+	}
+	json.Unmarshal([]byte(data), v)
+}
@@ -0,0 +1,439 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/identity"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+func newTestStore(t *testing.T) (*Store, *storage.DB) {
+	t.Helper()
+
+	db, err := storage.Open(storage.Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	identityStore := storage.NewIdentityStore(db)
+	mgr := identity.NewManager(identityStore)
+	if _, err := mgr.CreateIdentity("Test", "passphrase"); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+	you, keys, err := identityStore.LoadIdentity()
+	if err != nil {
+		t.Fatalf("load identity: %v", err)
+	}
+	if err := mgr.Unlock(you, keys, "passphrase"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+
+	return NewStore(db, mgr, you.ID), db
+}
+
+// mockPlaid serves transaction sync pages keyed by request cursor
+type mockPlaid struct {
+	mu       sync.Mutex
+	pages    map[string]TransactionsSyncResponse
+	failOnce map[string]string // Cursor to error code, returned once
+	accounts []Account
+	cursors  []string // Cursors requested, in order
+	keys     map[string]WebhookVerificationKey
+	keyGets  int
+}
+
+func (m *mockPlaid) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var req map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	switch r.URL.Path {
+	case "/transactions/sync":
+		cursor, _ := req["cursor"].(string)
+		m.cursors = append(m.cursors, cursor)
+		if code, ok := m.failOnce[cursor]; ok {
+			delete(m.failOnce, cursor)
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(PlaidError{ErrorType: "TRANSACTIONS_ERROR", ErrorCode: code})
+			return
+		}
+		page, ok := m.pages[cursor]
+		if !ok {
+			page = TransactionsSyncResponse{NextCursor: cursor}
+		}
+		json.NewEncoder(w).Encode(page)
+	case "/accounts/get", "/accounts/balance/get":
+		json.NewEncoder(w).Encode(AccountsResponse{Accounts: m.accounts})
+	case "/item/remove":
+		w.Write([]byte(`{}`))
+	case "/webhook_verification_key/get":
+		m.keyGets++
+		key, ok := m.keys[req["key_id"].(string)]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(PlaidError{ErrorType: "INVALID_INPUT", ErrorCode: "INVALID_WEBHOOK_VERIFICATION_KEY_ID"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"key": key})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (m *mockPlaid) requested() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.cursors...)
+}
+
+func testTransaction(id, name string, amount float64, date string) Transaction {
+	return Transaction{
+		TransactionID:   id,
+		AccountID:       "acc_1",
+		Amount:          amount,
+		Date:            date,
+		Name:            name,
+		MerchantName:    name,
+		Category:        []string{"Shops"},
+		IsoCurrencyCode: "USD",
+	}
+}
+
+func testConnection() *Connection {
+	return &Connection{
+		ID:              "conn_1",
+		ItemID:          "item_1",
+		InstitutionID:   "ins_1",
+		InstitutionName: "Test Bank",
+		AccessToken:     "access-sandbox-secret",
+		Status:          ConnectionStatusActive,
+		Accounts: []Account{{
+			AccountID: "acc_1",
+			Name:      "Checking",
+			Type:      "depository",
+			Subtype:   "checking",
+			Balances:  AccountBalance{Current: 100, IsoCurrencyCode: "USD"},
+		}},
+		CreatedAt: time.Now(),
+	}
+}
+
+func transactionIDs(txs []*CategorizedTransaction) map[string]*CategorizedTransaction {
+	ids := make(map[string]*CategorizedTransaction)
+	for _, tx := range txs {
+		ids[tx.TransactionID] = tx
+	}
+	return ids
+}
+
+func TestStore_SaveConnection_EncryptsToken(t *testing.T) {
+	store, db := newTestStore(t)
+	conn := testConnection()
+
+	if err := store.SaveConnection(conn); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	var stored string
+	if err := db.Conn().QueryRow(`SELECT access_token_encrypted FROM bank_connections WHERE id = ?`, conn.ID).Scan(&stored); err != nil {
+		t.Fatalf("query token: %v", err)
+	}
+	if stored == "" || strings.Contains(stored, conn.AccessToken) {
+		t.Errorf("access token stored in plaintext: %q", stored)
+	}
+
+	connections, err := store.Connections()
+	if err != nil {
+		t.Fatalf("Connections: %v", err)
+	}
+	if len(connections) != 1 {
+		t.Fatalf("Connections = %d, want 1", len(connections))
+	}
+	got := connections[0]
+	if got.AccessToken != conn.AccessToken {
+		t.Errorf("AccessToken = %q, want %q", got.AccessToken, conn.AccessToken)
+	}
+	if len(got.Accounts) != 1 || got.Accounts[0].Name != "Checking" || got.Accounts[0].Balances.Current != 100 {
+		t.Errorf("Accounts = %+v", got.Accounts)
+	}
+
+	if err := store.DeleteConnection(conn.ID); err != nil {
+		t.Fatalf("DeleteConnection: %v", err)
+	}
+	if connections, _ := store.Connections(); len(connections) != 0 {
+		t.Errorf("Connections after delete = %d, want 0", len(connections))
+	}
+}
+
+func TestStore_RecurringAndInsights(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+	tx := &CategorizedTransaction{Transaction: testTransaction("tx_1", "Netflix", 15.99, "2026-01-05"), QLCategory: CategorySubscription}
+	if err := store.ApplySync("conn_1", SyncChanges{Upserted: []*CategorizedTransaction{tx}, Cursor: "c1", SyncedAt: time.Now()}); err != nil {
+		t.Fatalf("ApplySync: %v", err)
+	}
+
+	recurring := []*RecurringTransaction{{
+		ID:           "rec_netflix",
+		MerchantName: "Netflix",
+		Category:     CategorySubscription,
+		Amount:       15.99,
+		Frequency:    "monthly",
+		DayOfMonth:   5,
+		Transactions: []string{"tx_1"},
+		LastSeen:     time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		IsActive:     true,
+	}}
+	if err := store.SaveRecurring(recurring); err != nil {
+		t.Fatalf("SaveRecurring: %v", err)
+	}
+	insights := []*Insight{{ID: "subscriptions_review", Type: InsightTypeSavingsOpportunity, Title: "Review", Description: "d", Trend: "up", Data: map[string]interface{}{"count": 3.0}}}
+	if err := store.SaveInsights(insights); err != nil {
+		t.Fatalf("SaveInsights: %v", err)
+	}
+
+	gotRecurring, err := store.Recurring()
+	if err != nil || len(gotRecurring) != 1 {
+		t.Fatalf("Recurring = %v, %v", gotRecurring, err)
+	}
+	if r := gotRecurring[0]; r.Frequency != "monthly" || len(r.Transactions) != 1 || !r.LastSeen.Equal(recurring[0].LastSeen) {
+		t.Errorf("Recurring = %+v", r)
+	}
+
+	txs, err := store.Transactions()
+	if err != nil || len(txs) != 1 {
+		t.Fatalf("Transactions = %v, %v", txs, err)
+	}
+	if !txs[0].IsRecurring || txs[0].RecurringID != "rec_netflix" {
+		t.Errorf("transaction not marked recurring: %+v", txs[0])
+	}
+
+	gotInsights, err := store.Insights()
+	if err != nil || len(gotInsights) != 1 {
+		t.Fatalf("Insights = %v, %v", gotInsights, err)
+	}
+	if in := gotInsights[0]; in.Trend != "up" || in.Data.(map[string]interface{})["count"] != 3.0 {
+		t.Errorf("Insight = %+v", in)
+	}
+
+	// Saving replaces the previous set
+	if err := store.SaveRecurring(nil); err != nil {
+		t.Fatalf("SaveRecurring: %v", err)
+	}
+	if got, _ := store.Recurring(); len(got) != 0 {
+		t.Errorf("Recurring after replace = %d, want 0", len(got))
+	}
+	if txs, _ := store.Transactions(); txs[0].IsRecurring {
+		t.Error("transaction still marked recurring")
+	}
+}
+
+func TestSpace_Sync_Incremental(t *testing.T) {
+	store, _ := newTestStore(t)
+	conn := testConnection()
+	if err := store.SaveConnection(conn); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	mock := &mockPlaid{
+		accounts: []Account{{AccountID: "acc_1", Name: "Checking", Type: "depository", Balances: AccountBalance{Current: 250}}},
+		pages: map[string]TransactionsSyncResponse{
+			"": {
+				Added:      []Transaction{testTransaction("tx_1", "Coffee", 4.50, "2026-01-02"), testTransaction("tx_2", "Books", 20, "2026-01-03")},
+				NextCursor: "c1",
+				HasMore:    true,
+			},
+			"c1": {
+				Added:      []Transaction{testTransaction("tx_3", "Groceries", 60, "2026-01-04")},
+				NextCursor: "c2",
+			},
+			"c2": {
+				Modified:   []Transaction{testTransaction("tx_1", "Coffee", 5.25, "2026-01-02")},
+				Removed:    []RemovedTransaction{{TransactionID: "tx_2"}},
+				NextCursor: "c3",
+			},
+		},
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	newSpace := func() *Space {
+		space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", PlaidConfig: PlaidConfig{BaseURL: server.URL}})
+		space.SetStore(store)
+		return space
+	}
+
+	ctx := context.Background()
+	space := newSpace()
+	if err := space.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	// First sync pages through to the end
+	result, err := space.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("Sync errors: %v", result.Errors)
+	}
+	if result.NewItems != 3 {
+		t.Errorf("NewItems = %d, want 3", result.NewItems)
+	}
+	if got := len(space.GetTransactions(TransactionFilter{})); got != 3 {
+		t.Errorf("transactions = %d, want 3", got)
+	}
+	if balance := space.GetTotalBalance(); balance != 250 {
+		t.Errorf("GetTotalBalance = %v, want 250", balance)
+	}
+
+	// Second sync applies modifications and removals without duplicates
+	result, err = space.Sync(ctx)
+	if err != nil || len(result.Errors) > 0 {
+		t.Fatalf("Sync: %v %v", err, result.Errors)
+	}
+	if result.NewItems != 0 || result.UpdatedItems != 1 {
+		t.Errorf("NewItems, UpdatedItems = %d, %d, want 0, 1", result.NewItems, result.UpdatedItems)
+	}
+	txs := transactionIDs(space.GetTransactions(TransactionFilter{}))
+	if len(txs) != 2 || txs["tx_2"] != nil || txs["tx_1"] == nil || txs["tx_1"].Amount != 5.25 {
+		t.Errorf("transactions after second sync = %v", txs)
+	}
+
+	stored, err := store.Transactions()
+	if err != nil {
+		t.Fatalf("Transactions: %v", err)
+	}
+	storedIDs := transactionIDs(stored)
+	if len(storedIDs) != 2 || storedIDs["tx_1"].Amount != 5.25 || storedIDs["tx_3"].AccountID != "acc_1" {
+		t.Errorf("stored transactions = %v", storedIDs)
+	}
+
+	// A new space resumes from the stored cursor
+	restarted := newSpace()
+	if err := restarted.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if got := len(restarted.GetTransactions(TransactionFilter{})); got != 2 {
+		t.Errorf("loaded transactions = %d, want 2", got)
+	}
+	if conns := restarted.GetConnections(); len(conns) != 1 || conns[0].SyncCursor != "c3" || conns[0].AccessToken != conn.AccessToken {
+		t.Errorf("loaded connections = %+v", conns)
+	}
+	if _, err := restarted.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	want := []string{"", "c1", "c2", "c3"}
+	got := mock.requested()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("requested cursors = %q, want %q", got, want)
+	}
+}
+
+func TestSpace_Sync_RestartsOnMutation(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	mock := &mockPlaid{
+		pages: map[string]TransactionsSyncResponse{
+			"": {
+				Added:      []Transaction{testTransaction("tx_1", "Coffee", 4.50, "2026-01-02")},
+				NextCursor: "c1",
+				HasMore:    true,
+			},
+			"c1": {
+				Added:      []Transaction{testTransaction("tx_2", "Books", 20, "2026-01-03")},
+				NextCursor: "c2",
+			},
+		},
+		failOnce: map[string]string{"c1": "TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION"},
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", PlaidConfig: PlaidConfig{BaseURL: server.URL}})
+	space.SetStore(store)
+
+	ctx := context.Background()
+	if err := space.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	result, err := space.Sync(ctx)
+	if err != nil || len(result.Errors) > 0 {
+		t.Fatalf("Sync: %v %v", err, result.Errors)
+	}
+
+	if got := mock.requested(); strings.Join(got, ",") != ",c1,,c1" {
+		t.Errorf("requested cursors = %q, want restart from the stored cursor", got)
+	}
+	if result.NewItems != 2 {
+		t.Errorf("NewItems = %d, want 2", result.NewItems)
+	}
+	if got := len(space.GetTransactions(TransactionFilter{})); got != 2 {
+		t.Errorf("transactions = %d, want 2", got)
+	}
+	if conns, _ := store.Connections(); conns[0].SyncCursor != "c2" {
+		t.Errorf("stored cursor = %q, want c2", conns[0].SyncCursor)
+	}
+}
+
+func TestSpace_Sync_FailureKeepsCursor(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	mock := &mockPlaid{
+		pages: map[string]TransactionsSyncResponse{
+			"": {
+				Added:      []Transaction{testTransaction("tx_1", "Coffee", 4.50, "2026-01-02")},
+				NextCursor: "c1",
+				HasMore:    true,
+			},
+		},
+		failOnce: map[string]string{"c1": "INTERNAL_SERVER_ERROR"},
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", PlaidConfig: PlaidConfig{BaseURL: server.URL}})
+	space.SetStore(store)
+
+	ctx := context.Background()
+	if err := space.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	result, err := space.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("Sync errors = %v, want 1", result.Errors)
+	}
+
+	// Nothing from the partial sync is kept
+	if txs, _ := store.Transactions(); len(txs) != 0 {
+		t.Errorf("stored transactions = %d, want 0", len(txs))
+	}
+	if conns, _ := store.Connections(); conns[0].SyncCursor != "" {
+		t.Errorf("stored cursor = %q, want empty", conns[0].SyncCursor)
+	}
+}
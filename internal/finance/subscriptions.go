@@ -0,0 +1,480 @@
+package finance
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+// SubscriptionAlertType says what a subscription alert is about
+type SubscriptionAlertType string
+
+const (
+	SubscriptionPriceIncrease   SubscriptionAlertType = "price_increase"
+	SubscriptionDuplicate       SubscriptionAlertType = "duplicate"
+	SubscriptionTrialConversion SubscriptionAlertType = "trial_conversion"
+)
+
+const (
+	// subscriptionAlertDays is how long price increases and trial
+	// conversions are flagged for
+	subscriptionAlertDays = 60
+
+	// trialMaxAmount is the most a free or nominal trial sign-up charges
+	trialMaxAmount = 1.00
+
+	// receiptWindow is how far a receipt may be from the charge it is for
+	receiptWindow = 7 * 24 * time.Hour
+)
+
+// subscriptionCategories are the categories recurring charges count as
+// subscriptions in
+var subscriptionCategories = []Category{CategorySubscription, CategoryEntertainment}
+
+func isSubscriptionCategory(category Category) bool {
+	for _, c := range subscriptionCategories {
+		if category == c {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionKinds groups services that do the same job, by keywords in
+// their names. Paying for two of a kind is flagged as a duplicate.
+var subscriptionKinds = []struct {
+	kind     string
+	keywords []string
+}{
+	{"video streaming", []string{"netflix", "hulu", "disney", "hbo", "paramount", "peacock", "apple tv", "prime video", "crunchyroll"}},
+	{"music streaming", []string{"spotify", "apple music", "tidal", "deezer", "pandora", "youtube music", "amazon music"}},
+	{"cloud storage", []string{"dropbox", "icloud", "google one", "onedrive"}},
+	{"news", []string{"new york times", "nytimes", "washington post", "wall street journal", "wsj", "economist"}},
+	{"fitness", []string{"peloton", "classpass", "strava", "fitbit"}},
+}
+
+// subscriptionKind returns the kind of service name is, if known
+func subscriptionKind(name string) string {
+	name = strings.ToLower(name)
+	for _, k := range subscriptionKinds {
+		for _, keyword := range k.keywords {
+			if strings.Contains(name, keyword) {
+				return k.kind
+			}
+		}
+	}
+	return ""
+}
+
+// receiptKeywords mark an email as a receipt or sign-up confirmation
+var receiptKeywords = []string{"receipt", "invoice", "subscription", "payment", "trial", "membership", "welcome", "order"}
+
+// monthlyFactor is how many times a month a charge of frequency recurs
+func monthlyFactor(frequency string) *big.Rat {
+	switch frequency {
+	case "weekly":
+		return big.NewRat(433, 100)
+	case "biweekly":
+		return big.NewRat(217, 100)
+	case "monthly":
+		return big.NewRat(1, 1)
+	case "quarterly":
+		return big.NewRat(1, 3)
+	case "annual":
+		return big.NewRat(1, 12)
+	default:
+		return new(big.Rat)
+	}
+}
+
+// PricePoint is a subscription's price from a day on
+type PricePoint struct {
+	Date   string `json:"date"`
+	Amount Money  `json:"amount"`
+}
+
+// Subscription is a recurring charge for a service, from one account
+type Subscription struct {
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	Category      Category     `json:"category"`
+	Kind          string       `json:"kind,omitempty"` // Known services only, e.g. video streaming
+	AccountID     string       `json:"account_id,omitempty"`
+	Frequency     string       `json:"frequency"`
+	Amount        Money        `json:"amount"`       // Latest charge
+	MonthlyCost   Money        `json:"monthly_cost"` // In the home currency
+	Since         string       `json:"since"`        // First charge, including any trial
+	LastCharged   string       `json:"last_charged"`
+	NextCharge    string       `json:"next_charge,omitempty"`
+	PriceHistory  []PricePoint `json:"price_history"`          // Each price paid, oldest first
+	Trial         bool         `json:"trial"`                  // Started with a free or nominal trial
+	ConvertedOn   string       `json:"converted_on,omitempty"` // First full-price charge after a trial
+	RecurringID   string       `json:"recurring_id,omitempty"`
+	ReceiptItemID core.ItemID  `json:"receipt_item_id,omitempty"` // Email receipt for the first charge
+}
+
+// SubscriptionAlert flags a subscription worth a look
+type SubscriptionAlert struct {
+	Type            SubscriptionAlertType `json:"type"`
+	SubscriptionIDs []string              `json:"subscription_ids"`
+	Message         string                `json:"message"`
+	Date            string                `json:"date,omitempty"`
+	Amount          Money                 `json:"amount"` // Price rise, new charge or monthly cost of the duplicates
+}
+
+// SubscriptionReport lists the active subscriptions, costliest first
+type SubscriptionReport struct {
+	Currency      string              `json:"currency"`
+	Subscriptions []*Subscription     `json:"subscriptions"`
+	MonthlyTotal  Money               `json:"monthly_total"`
+	AnnualTotal   Money               `json:"annual_total"`
+	Alerts        []SubscriptionAlert `json:"alerts"`
+	MissingRates  []string            `json:"missing_rates,omitempty"`
+}
+
+// SetItemStore lets subscriptions and tax reports link to email receipts
+func (s *Space) SetItemStore(items *storage.ItemStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = items
+}
+
+// GetSubscriptions reports the active subscriptions with their price
+// history, and flags price increases, duplicates and trials that have
+// turned into paid subscriptions
+func (s *Space) GetSubscriptions() *SubscriptionReport {
+	return s.subscriptionReport(time.Now())
+}
+
+func (s *Space) subscriptionReport(now time.Time) *SubscriptionReport {
+	s.mu.RLock()
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	subs := s.subscriptions(now, conv)
+	items := s.items
+	s.mu.RUnlock()
+
+	if items != nil {
+		linkReceipts(items, subs)
+	}
+
+	report := &SubscriptionReport{
+		Currency:      conv.home,
+		Subscriptions: subs,
+		MonthlyTotal:  conv.zero(),
+		Alerts:        subscriptionAlerts(subs, now, conv.home),
+		MissingRates:  conv.missingRates(),
+	}
+	for _, sub := range subs {
+		report.MonthlyTotal.Minor += sub.MonthlyCost.Minor
+	}
+	report.AnnualTotal = Money{Minor: report.MonthlyTotal.Minor * 12, Currency: conv.home}
+	return report
+}
+
+// subscriptions builds a subscription for each active recurring charge in
+// a subscription category, and for each recent trial that has turned
+// into a paid charge but not recurred yet. The caller must hold the lock.
+func (s *Space) subscriptions(now time.Time, conv *converter) []*Subscription {
+	// Every charge, by merchant and account
+	charges := make(map[[2]string][]*CategorizedTransaction)
+	byID := make(map[string]*CategorizedTransaction, len(s.transactions))
+	for _, tx := range s.transactions {
+		byID[tx.TransactionID] = tx
+		if tx.Amount < 0 {
+			continue // Refunds
+		}
+		key := [2]string{merchantKeyOf(tx.Transaction), tx.AccountID}
+		charges[key] = append(charges[key], tx)
+	}
+	for _, txs := range charges {
+		sort.SliceStable(txs, func(i, j int) bool { return txs[i].Date < txs[j].Date })
+	}
+
+	subs := []*Subscription{}
+	seen := make(map[[2]string]bool)
+	for _, rec := range s.recurring {
+		if !rec.IsActive || rec.Amount <= 0 || !isSubscriptionCategory(rec.Category) {
+			continue
+		}
+
+		// Series are detected by merchant, so one service paid from two
+		// accounts is one series. Each account is its own subscription.
+		var keys [][2]string
+		for _, id := range rec.Transactions {
+			tx := byID[id]
+			if tx == nil {
+				continue
+			}
+			key := [2]string{merchantKeyOf(tx.Transaction), tx.AccountID}
+			if !seen[key] {
+				seen[key] = true // A price change may split the charges into two series
+				keys = append(keys, key)
+			}
+		}
+
+		for _, key := range keys {
+			id := rec.ID
+			if len(keys) > 1 {
+				id += "_" + key[1]
+			}
+			sub := newSubscription(id, charges[key], rec.Frequency, conv)
+			if sub == nil {
+				continue
+			}
+			sub.Category = rec.Category
+			sub.RecurringID = rec.ID
+			if len(keys) == 1 && !rec.NextExpected.IsZero() {
+				sub.NextCharge = rec.NextExpected.Format("2006-01-02")
+			} else if last, err := time.Parse("2006-01-02", sub.LastCharged); err == nil {
+				sub.NextCharge = s.recurringDetector.predictNext(last, rec.Frequency, rec.DayOfMonth).Format("2006-01-02")
+			}
+			subs = append(subs, sub)
+		}
+	}
+
+	// A trial converted this month has only one paid charge, too few to
+	// be detected as recurring
+	keys := make([][2]string, 0, len(charges))
+	for key := range charges {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i][0]+keys[i][1] < keys[j][0]+keys[j][1] })
+	cutoff := dayOf(now).AddDate(0, 0, -subscriptionAlertDays).Format("2006-01-02")
+	for _, key := range keys {
+		txs := charges[key]
+		if seen[key] || len(txs) < 2 || txs[0].Amount > trialMaxAmount {
+			continue
+		}
+		paid := txs[len(txs)-1]
+		if paid.Amount <= trialMaxAmount || !isSubscriptionCategory(paid.QLCategory) || paid.Date < cutoff {
+			continue
+		}
+		sub := newSubscription("trial_"+txs[0].TransactionID, txs, "monthly", conv)
+		if sub == nil || len(sub.PriceHistory) != 1 {
+			continue
+		}
+		sub.Category = paid.QLCategory
+		if date, err := time.Parse("2006-01-02", paid.Date); err == nil {
+			sub.NextCharge = s.recurringDetector.predictNext(date, "monthly", date.Day()).Format("2006-01-02")
+		}
+		subs = append(subs, sub)
+	}
+
+	sort.SliceStable(subs, func(i, j int) bool {
+		if subs[i].MonthlyCost.Minor != subs[j].MonthlyCost.Minor {
+			return subs[i].MonthlyCost.Minor > subs[j].MonthlyCost.Minor
+		}
+		return subs[i].Name < subs[j].Name
+	})
+	return subs
+}
+
+// newSubscription builds a subscription from its charges, oldest first,
+// or returns nil if none was paid
+func newSubscription(id string, txs []*CategorizedTransaction, frequency string, conv *converter) *Subscription {
+	if len(txs) == 0 {
+		return nil
+	}
+	first := txs[0]
+	name := first.MerchantName
+	if name == "" {
+		name = first.Name
+	}
+	sub := &Subscription{
+		ID:           id,
+		Name:         name,
+		Kind:         subscriptionKind(name),
+		AccountID:    first.AccountID,
+		Frequency:    frequency,
+		Since:        first.Date,
+		PriceHistory: []PricePoint{},
+	}
+
+	var latest *CategorizedTransaction
+	for _, tx := range txs {
+		if tx.Amount <= trialMaxAmount {
+			if latest == nil {
+				sub.Trial = true
+			}
+			continue
+		}
+		currency := tx.IsoCurrencyCode
+		if currency == "" {
+			currency = conv.home
+		}
+		price := NewMoney(tx.Amount, currency)
+		if n := len(sub.PriceHistory); n == 0 || sub.PriceHistory[n-1].Amount != price {
+			sub.PriceHistory = append(sub.PriceHistory, PricePoint{Date: tx.Date, Amount: price})
+		}
+		if latest == nil && sub.Trial {
+			sub.ConvertedOn = tx.Date
+		}
+		latest = tx
+	}
+	if latest == nil {
+		return nil
+	}
+
+	sub.Amount = sub.PriceHistory[len(sub.PriceHistory)-1].Amount
+	sub.LastCharged = latest.Date
+	if price, ok := conv.convert(sub.Amount); ok {
+		sub.MonthlyCost, _ = price.mul(monthlyFactor(frequency))
+	} else {
+		sub.MonthlyCost = conv.zero()
+	}
+	return sub
+}
+
+// linkReceipts links each subscription to the email closest to its first
+// charge that looks like a receipt from the merchant. A receipt that
+// mentions a trial marks the subscription as one. Linking is best effort;
+// search failures leave subscriptions unlinked.
+func linkReceipts(items *storage.ItemStore, subs []*Subscription) {
+	for _, sub := range subs {
+		since, err := time.Parse("2006-01-02", sub.Since)
+		if err != nil {
+			continue
+		}
+		receipt := findReceipt(items, sub.Name, since)
+		if receipt == nil {
+			continue
+		}
+
+		sub.ReceiptItemID = receipt.ID
+		text := strings.ToLower(receipt.Subject + " " + receipt.Body)
+		if !sub.Trial && strings.Contains(text, "trial") {
+			sub.Trial = true
+			sub.ConvertedOn = sub.PriceHistory[0].Date
+		}
+	}
+}
+
+// findReceipt returns the email receipt mentioning name that is closest
+// to day, within the receipt window
+func findReceipt(items *storage.ItemStore, name string, day time.Time) *core.Item {
+	found, err := items.SearchText(name, "", 20)
+	if err != nil {
+		return nil
+	}
+
+	var best *core.Item
+	var bestGap time.Duration
+	for _, item := range found {
+		if item.Type != core.ItemTypeEmail || !isReceipt(item) {
+			continue
+		}
+		gap := item.Timestamp.Sub(day)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > receiptWindow {
+			continue
+		}
+		if best == nil || gap < bestGap {
+			best, bestGap = item, gap
+		}
+	}
+	return best
+}
+
+func isReceipt(item *core.Item) bool {
+	text := strings.ToLower(item.Subject + " " + item.Body)
+	for _, keyword := range receiptKeywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriptionAlerts flags recent price increases and trial conversions,
+// services billed on more than one account and services of the same kind
+func subscriptionAlerts(subs []*Subscription, now time.Time, home string) []SubscriptionAlert {
+	alerts := []SubscriptionAlert{}
+	cutoff := dayOf(now).AddDate(0, 0, -subscriptionAlertDays).Format("2006-01-02")
+
+	for _, sub := range subs {
+		if n := len(sub.PriceHistory); n >= 2 {
+			prev, cur := sub.PriceHistory[n-2], sub.PriceHistory[n-1]
+			if cur.Date >= cutoff && cur.Amount.Currency == prev.Amount.Currency && cur.Amount.Minor > prev.Amount.Minor {
+				alerts = append(alerts, SubscriptionAlert{
+					Type:            SubscriptionPriceIncrease,
+					SubscriptionIDs: []string{sub.ID},
+					Message:         fmt.Sprintf("%s went up from %s to %s on %s", sub.Name, prev.Amount, cur.Amount, cur.Date),
+					Date:            cur.Date,
+					Amount:          Money{Minor: cur.Amount.Minor - prev.Amount.Minor, Currency: cur.Amount.Currency},
+				})
+			}
+		}
+		if sub.Trial && sub.ConvertedOn >= cutoff {
+			alerts = append(alerts, SubscriptionAlert{
+				Type:            SubscriptionTrialConversion,
+				SubscriptionIDs: []string{sub.ID},
+				Message:         fmt.Sprintf("Your %s trial turned into a paid subscription of %s on %s", sub.Name, sub.PriceHistory[0].Amount, sub.ConvertedOn),
+				Date:            sub.ConvertedOn,
+				Amount:          sub.PriceHistory[0].Amount,
+			})
+		}
+	}
+
+	// The same service on more than one account
+	byName := make(map[string][]*Subscription)
+	var names []string
+	for _, sub := range subs {
+		name := strings.ToLower(sub.Name)
+		if byName[name] == nil {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], sub)
+	}
+	for _, name := range names {
+		if group := byName[name]; len(group) > 1 {
+			alerts = append(alerts, duplicateAlert(group, home,
+				fmt.Sprintf("%s is billed on %d accounts", group[0].Name, len(group))))
+		}
+	}
+
+	// Services of the same kind, one per name
+	byKind := make(map[string][]*Subscription)
+	for _, name := range names {
+		sub := byName[name][0]
+		if sub.Kind != "" {
+			byKind[sub.Kind] = append(byKind[sub.Kind], sub)
+		}
+	}
+	for _, k := range subscriptionKinds {
+		group := byKind[k.kind]
+		if len(group) < 2 {
+			continue
+		}
+		labels := make([]string, len(group))
+		for i, sub := range group {
+			labels[i] = sub.Name
+		}
+		alerts = append(alerts, duplicateAlert(group, home,
+			fmt.Sprintf("You pay for %d %s services: %s", len(group), k.kind, strings.Join(labels, ", "))))
+	}
+	return alerts
+}
+
+// duplicateAlert flags group, costliest first, as overlapping. Its
+// amount is what all but the costliest cost a month.
+func duplicateAlert(group []*Subscription, home, message string) SubscriptionAlert {
+	alert := SubscriptionAlert{
+		Type:    SubscriptionDuplicate,
+		Message: message,
+		Amount:  Money{Currency: home},
+	}
+	for i, sub := range group {
+		alert.SubscriptionIDs = append(alert.SubscriptionIDs, sub.ID)
+		if i > 0 {
+			alert.Amount.Minor += sub.MonthlyCost.Minor
+		}
+	}
+	return alert
+}
@@ -0,0 +1,133 @@
+package finance
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+func TestSpace_Subscriptions(t *testing.T) {
+	_, db := newTestStore(t)
+	items := storage.NewItemStore(db)
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	for _, item := range []*core.Item{
+		{ID: "mail_hulu", Subject: "Your Hulu free trial has started", Body: "Welcome to Hulu", Timestamp: day("2026-01-02")},
+		{ID: "mail_netflix_old", Subject: "Netflix payment receipt", Timestamp: day("2025-06-01")},
+		{ID: "mail_netflix", Subject: "Netflix payment receipt", Body: "Thanks for your payment", Timestamp: day("2025-12-05")},
+		{ID: "mail_spotify", Subject: "Spotify is hiring", Timestamp: day("2026-01-15")},
+	} {
+		item.Type = core.ItemTypeEmail
+		item.Status = core.ItemStatusPending
+		item.HatID = core.HatPersonal
+		if err := items.Create(item); err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+	}
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetItemStore(items)
+	charge := func(id, account, merchant, date string, amount float64, category Category) *CategorizedTransaction {
+		return &CategorizedTransaction{
+			Transaction: Transaction{TransactionID: id, AccountID: account, MerchantName: merchant, Date: date, Amount: amount},
+			QLCategory:  category,
+		}
+	}
+	space.transactions = []*CategorizedTransaction{
+		charge("n1", "chk", "Netflix", "2025-12-05", 15.49, CategoryEntertainment),
+		charge("n2", "chk", "Netflix", "2026-01-05", 15.49, CategoryEntertainment),
+		charge("n3", "chk", "Netflix", "2026-02-05", 17.99, CategoryEntertainment),
+		charge("n4", "chk", "Netflix", "2026-03-05", 17.99, CategoryEntertainment),
+		charge("h1", "card", "Hulu", "2026-01-03", 7.99, CategorySubscription),
+		charge("h2", "card", "Hulu", "2026-02-03", 7.99, CategorySubscription),
+		charge("h3", "card", "Hulu", "2026-03-03", 7.99, CategorySubscription),
+		charge("s1", "chk", "Spotify", "2026-02-12", 10.99, CategorySubscription),
+		charge("s2", "chk", "Spotify", "2026-03-12", 10.99, CategorySubscription),
+		charge("s3", "card", "Spotify", "2026-02-14", 10.99, CategorySubscription),
+		charge("s4", "card", "Spotify", "2026-03-14", 10.99, CategorySubscription),
+		// A free month, then paid but not recurring yet
+		charge("d1", "card", "Disney Plus", "2026-02-10", 0, CategorySubscription),
+		charge("d2", "card", "Disney Plus", "2026-03-10", 13.99, CategorySubscription),
+		// Recurring, but not a subscription
+		charge("g1", "chk", "City Gym", "2026-02-01", 40, CategoryHealth),
+		charge("g2", "chk", "City Gym", "2026-03-01", 40, CategoryHealth),
+	}
+	space.recurring = []*RecurringTransaction{
+		{ID: "rec_netflix", MerchantName: "Netflix", Category: CategoryEntertainment, Amount: 17.99, Frequency: "monthly",
+			NextExpected: day("2026-04-05"), Transactions: []string{"n3", "n4"}, IsActive: true},
+		{ID: "rec_netflix_old", MerchantName: "Netflix", Category: CategoryEntertainment, Amount: 15.49, Frequency: "monthly",
+			NextExpected: day("2026-02-05"), Transactions: []string{"n1", "n2"}, IsActive: true},
+		{ID: "rec_hulu", MerchantName: "Hulu", Category: CategorySubscription, Amount: 7.99, Frequency: "monthly",
+			NextExpected: day("2026-04-03"), Transactions: []string{"h1", "h2", "h3"}, IsActive: true},
+		// One series over both accounts
+		{ID: "rec_spotify", MerchantName: "Spotify", Category: CategorySubscription, Amount: 10.99, Frequency: "monthly",
+			NextExpected: day("2026-04-14"), Transactions: []string{"s1", "s3", "s2", "s4"}, IsActive: true},
+		{ID: "rec_gym", MerchantName: "City Gym", Category: CategoryHealth, Amount: 40, Frequency: "monthly",
+			NextExpected: day("2026-04-01"), Transactions: []string{"g1", "g2"}, IsActive: true},
+	}
+
+	report := space.subscriptionReport(day("2026-03-20"))
+
+	var ids []string
+	byID := make(map[string]*Subscription)
+	for _, sub := range report.Subscriptions {
+		ids = append(ids, sub.ID)
+		byID[sub.ID] = sub
+	}
+	wantIDs := []string{"rec_netflix", "trial_d1", "rec_spotify_chk", "rec_spotify_card", "rec_hulu"}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Fatalf("subscriptions = %v, want %v", ids, wantIDs)
+	}
+	if report.MonthlyTotal != (Money{6195, "USD"}) || report.AnnualTotal != (Money{74340, "USD"}) {
+		t.Errorf("totals = %s a month, %s a year", report.MonthlyTotal, report.AnnualTotal)
+	}
+
+	netflix := byID["rec_netflix"]
+	wantHistory := []PricePoint{{"2025-12-05", Money{1549, "USD"}}, {"2026-02-05", Money{1799, "USD"}}}
+	if !reflect.DeepEqual(netflix.PriceHistory, wantHistory) || netflix.Since != "2025-12-05" ||
+		netflix.Kind != "video streaming" || netflix.NextCharge != "2026-04-05" || netflix.ReceiptItemID != "mail_netflix" {
+		t.Errorf("netflix = %+v", netflix)
+	}
+	disney := byID["trial_d1"]
+	if !disney.Trial || disney.ConvertedOn != "2026-03-10" || disney.NextCharge != "2026-04-10" || disney.Amount != (Money{1399, "USD"}) {
+		t.Errorf("disney = %+v", disney)
+	}
+	// The sign-up email says Hulu started as a trial
+	hulu := byID["rec_hulu"]
+	if !hulu.Trial || hulu.ConvertedOn != "2026-01-03" || hulu.ReceiptItemID != "mail_hulu" {
+		t.Errorf("hulu = %+v", hulu)
+	}
+	if spotify := byID["rec_spotify_chk"]; spotify.RecurringID != "rec_spotify" || spotify.NextCharge != "2026-04-12" {
+		t.Errorf("spotify = %+v", spotify)
+	}
+	if byID["rec_spotify_chk"].ReceiptItemID != "" {
+		t.Errorf("spotify linked to %s, which is not a receipt", byID["rec_spotify_chk"].ReceiptItemID)
+	}
+
+	type alert struct {
+		Type   SubscriptionAlertType
+		IDs    []string
+		Amount Money
+	}
+	var alerts []alert
+	for _, a := range report.Alerts {
+		alerts = append(alerts, alert{a.Type, a.SubscriptionIDs, a.Amount})
+	}
+	wantAlerts := []alert{
+		{SubscriptionPriceIncrease, []string{"rec_netflix"}, Money{250, "USD"}},
+		{SubscriptionTrialConversion, []string{"trial_d1"}, Money{1399, "USD"}},
+		{SubscriptionDuplicate, []string{"rec_spotify_chk", "rec_spotify_card"}, Money{1099, "USD"}},
+		{SubscriptionDuplicate, []string{"rec_netflix", "trial_d1", "rec_hulu"}, Money{2198, "USD"}},
+	}
+	if !reflect.DeepEqual(alerts, wantAlerts) {
+		t.Errorf("alerts = %+v, want %+v", alerts, wantAlerts)
+	}
+	if msg := report.Alerts[3].Message; msg != "You pay for 3 video streaming services: Netflix, Disney Plus, Hulu" {
+		t.Errorf("duplicate message = %q", msg)
+	}
+}
@@ -0,0 +1,288 @@
+package finance
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+// ErrInvalidTaxYear is returned for tax years that haven't started
+var ErrInvalidTaxYear = errors.New("invalid tax year")
+
+// TaxSectionKind groups spending in a tax-year report
+type TaxSectionKind string
+
+const (
+	TaxBusiness   TaxSectionKind = "business"   // Spending in the professional hat
+	TaxCharitable TaxSectionKind = "charitable" // Charitable giving
+	TaxMedical    TaxSectionKind = "medical"    // Health spending, or spending in the health hat
+	TaxDeductible TaxSectionKind = "deductible" // Other categories configured as deductible
+)
+
+// taxSections are the report's sections in order, with their titles.
+// Spending goes in the first section it qualifies for.
+var taxSections = []struct {
+	kind  TaxSectionKind
+	title string
+}{
+	{TaxBusiness, "Business expenses"},
+	{TaxCharitable, "Charitable giving"},
+	{TaxMedical, "Medical expenses"},
+	{TaxDeductible, "Other deductible expenses"},
+}
+
+// TaxEntry is a transaction, or its part in one category and hat, in a
+// tax-year report
+type TaxEntry struct {
+	TransactionID  string      `json:"transaction_id"`
+	Date           string      `json:"date"`
+	Name           string      `json:"name"`
+	AccountID      string      `json:"account_id,omitempty"`
+	Category       Category    `json:"category"`
+	HatID          core.HatID  `json:"hat_id,omitempty"`
+	Amount         Money       `json:"amount"`   // In the home currency, less reimbursements
+	Original       Money       `json:"original"` // In the transaction's currency
+	ReceiptItemID  core.ItemID `json:"receipt_item_id,omitempty"`
+	ReceiptSubject string      `json:"receipt_subject,omitempty"`
+}
+
+// TaxSection totals the entries of one kind of spending
+type TaxSection struct {
+	Kind    TaxSectionKind `json:"kind"`
+	Title   string         `json:"title"`
+	Total   Money          `json:"total"`
+	Entries []*TaxEntry    `json:"entries"`
+}
+
+// TaxReport lists a calendar year's spending that matters at tax time,
+// in the home currency
+type TaxReport struct {
+	Year         int           `json:"year"`
+	Currency     string        `json:"currency"`
+	Sections     []*TaxSection `json:"sections"`
+	Total        Money         `json:"total"`
+	MissingRates []string      `json:"missing_rates,omitempty"`
+	GeneratedAt  time.Time     `json:"generated_at"`
+}
+
+// TaxReport builds the report for a calendar year from the stored
+// transactions. Transfers are left out and reimbursed spending is net of
+// what was paid back. Entries link to the closest email receipt when an
+// item store is set.
+func (s *Space) TaxReport(year int) (*TaxReport, error) {
+	now := time.Now()
+	if year < 1900 || year > now.Year() {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidTaxYear, year)
+	}
+	from, to := fmt.Sprintf("%04d-01-01", year), fmt.Sprintf("%04d-12-31", year)
+
+	s.mu.RLock()
+	conv := newConverter(s.insightsEngine.HomeCurrency(), s.exchange)
+	deductible := make(map[Category]bool, len(s.deductible))
+	for _, category := range s.deductible {
+		deductible[category] = true
+	}
+	report := &TaxReport{
+		Year:        year,
+		Currency:    conv.home,
+		Total:       conv.zero(),
+		GeneratedAt: now,
+	}
+	sections := make(map[TaxSectionKind]*TaxSection, len(taxSections))
+	for _, ts := range taxSections {
+		section := &TaxSection{Kind: ts.kind, Title: ts.title, Total: conv.zero(), Entries: []*TaxEntry{}}
+		sections[ts.kind] = section
+		report.Sections = append(report.Sections, section)
+	}
+
+	for _, tx := range s.transactions {
+		if tx.Amount <= 0 || tx.Pending || tx.TransferID != "" || tx.Date < from || tx.Date > to {
+			continue
+		}
+		paid := conv.money(tx.Amount, tx.IsoCurrencyCode)
+		amount, ok := conv.convert(paid)
+		if !ok {
+			continue
+		}
+		// The parts in both currencies come out in the same order
+		home, original := shares(tx, amount), shares(tx, paid)
+		for i, part := range home {
+			kind := taxSectionOf(part, deductible)
+			if kind == "" || part.minor <= 0 {
+				continue
+			}
+			section := sections[kind]
+			section.Entries = append(section.Entries, &TaxEntry{
+				TransactionID: tx.TransactionID,
+				Date:          tx.Date,
+				Name:          transactionName(tx),
+				AccountID:     tx.AccountID,
+				Category:      part.category,
+				HatID:         part.hatID,
+				Amount:        Money{Minor: part.minor, Currency: conv.home},
+				Original:      Money{Minor: original[i].minor, Currency: paid.Currency},
+			})
+			section.Total.Minor += part.minor
+			report.Total.Minor += part.minor
+		}
+	}
+	report.MissingRates = conv.missingRates()
+	items := s.items
+	s.mu.RUnlock()
+
+	for _, section := range report.Sections {
+		sort.SliceStable(section.Entries, func(i, j int) bool {
+			a, b := section.Entries[i], section.Entries[j]
+			if a.Date != b.Date {
+				return a.Date < b.Date
+			}
+			return a.TransactionID < b.TransactionID
+		})
+	}
+	if items != nil {
+		linkTaxReceipts(items, report)
+	}
+	return report, nil
+}
+
+// taxSectionOf returns the section a part of a transaction belongs in,
+// or "" when it doesn't matter for taxes
+func taxSectionOf(part share, deductible map[Category]bool) TaxSectionKind {
+	switch {
+	case part.hatID == core.HatProfessional:
+		return TaxBusiness
+	case part.category == CategoryCharity:
+		return TaxCharitable
+	case part.category == CategoryHealth || part.hatID == core.HatHealth:
+		return TaxMedical
+	case deductible[part.category]:
+		return TaxDeductible
+	default:
+		return ""
+	}
+}
+
+// transactionName prefers the merchant's name over the bank's description
+func transactionName(tx *CategorizedTransaction) string {
+	if tx.MerchantName != "" {
+		return tx.MerchantName
+	}
+	return tx.Name
+}
+
+// linkTaxReceipts links each entry to the email receipt closest to its
+// transaction. Parts of a split share the receipt. Linking is best
+// effort; search failures leave entries unlinked.
+func linkTaxReceipts(items *storage.ItemStore, report *TaxReport) {
+	receipts := make(map[string]*core.Item)
+	for _, section := range report.Sections {
+		for _, entry := range section.Entries {
+			receipt, seen := receipts[entry.TransactionID]
+			if !seen {
+				if day, err := time.Parse("2006-01-02", entry.Date); err == nil {
+					receipt = findReceipt(items, entry.Name, day)
+				}
+				receipts[entry.TransactionID] = receipt
+			}
+			if receipt != nil {
+				entry.ReceiptItemID = receipt.ID
+				entry.ReceiptSubject = receipt.Subject
+			}
+		}
+	}
+}
+
+// WriteCSV writes one row per entry, for a spreadsheet or an accountant
+func (r *TaxReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{
+		"section", "date", "transaction_id", "name", "category", "hat",
+		"amount", "currency", "original_amount", "original_currency",
+		"receipt_item_id", "receipt_subject",
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, section := range r.Sections {
+		for _, e := range section.Entries {
+			row := []string{
+				string(section.Kind), e.Date, e.TransactionID, e.Name, string(e.Category), string(e.HatID),
+				e.Amount.Decimal(), e.Amount.Currency,
+				e.Original.Decimal(), e.Original.Currency,
+				string(e.ReceiptItemID), e.ReceiptSubject,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RenderHTML renders the report as a page that prints cleanly, so it can
+// be saved as a PDF from a browser
+func (r *TaxReport) RenderHTML() string {
+	var sb strings.Builder
+
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	sb.WriteString("<meta charset=\"UTF-8\">\n")
+	sb.WriteString(fmt.Sprintf("<title>Tax Report %d</title>\n", r.Year))
+	sb.WriteString("<style>\n")
+	sb.WriteString("body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; max-width: 900px; margin: 0 auto; padding: 20px; }\n")
+	sb.WriteString("h1 { color: #1a1a2e; }\n")
+	sb.WriteString(".summary { background: #f0f0f5; padding: 15px; border-radius: 8px; margin-bottom: 20px; }\n")
+	sb.WriteString("table { width: 100%; border-collapse: collapse; margin-bottom: 20px; }\n")
+	sb.WriteString("th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #e8e8e8; }\n")
+	sb.WriteString(".amount { text-align: right; white-space: nowrap; }\n")
+	sb.WriteString(".total td { font-weight: bold; border-bottom: none; }\n")
+	sb.WriteString("@media print { body { max-width: none; padding: 0; } h2 { break-after: avoid; } tr { break-inside: avoid; } }\n")
+	sb.WriteString("</style>\n</head>\n<body>\n")
+
+	sb.WriteString(fmt.Sprintf("<h1>Tax Report %d</h1>\n", r.Year))
+	sb.WriteString("<div class=\"summary\">\n<table>\n")
+	for _, section := range r.Sections {
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td class=\"amount\">%s</td></tr>\n", section.Title, section.Total))
+	}
+	sb.WriteString(fmt.Sprintf("<tr class=\"total\"><td>Total</td><td class=\"amount\">%s</td></tr>\n", r.Total))
+	sb.WriteString("</table>\n</div>\n")
+	if len(r.MissingRates) > 0 {
+		sb.WriteString(fmt.Sprintf("<p><em>Left out for want of exchange rates: %s</em></p>\n",
+			html.EscapeString(strings.Join(r.MissingRates, ", "))))
+	}
+
+	for _, section := range r.Sections {
+		if len(section.Entries) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("<h2>%s</h2>\n<table>\n", section.Title))
+		sb.WriteString("<tr><th>Date</th><th>Name</th><th>Category</th><th>Receipt</th><th class=\"amount\">Amount</th></tr>\n")
+		for _, e := range section.Entries {
+			category := string(e.Category)
+			if e.HatID != "" {
+				category += " (" + string(e.HatID) + ")"
+			}
+			amount := e.Amount.String()
+			if e.Original.Currency != e.Amount.Currency {
+				amount += " <small>(" + e.Original.String() + ")</small>"
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td class=\"amount\">%s</td></tr>\n",
+				e.Date, html.EscapeString(e.Name), html.EscapeString(category), html.EscapeString(e.ReceiptSubject), amount))
+		}
+		sb.WriteString(fmt.Sprintf("<tr class=\"total\"><td colspan=\"4\">Total</td><td class=\"amount\">%s</td></tr>\n", section.Total))
+		sb.WriteString("</table>\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("<hr><p><small>Generated at %s</small></p>\n", r.GeneratedAt.Format(time.RFC3339)))
+	sb.WriteString("</body>\n</html>")
+
+	return sb.String()
+}
@@ -0,0 +1,116 @@
+package finance
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+func TestSpace_TaxReport(t *testing.T) {
+	space, store := newLinkedSpace(t,
+		categorized("laptop", "acc_2", "Apple Store", 1500, "2025-03-10", CategoryShopping),
+		categorized("redcross", "acc_1", "Red Cross", 100, "2025-05-01", CategoryCharity),
+		categorized("cvs", "acc_1", "CVS", 40, "2025-06-01", CategoryHealth),
+		categorized("costco", "acc_1", "Costco", 200, "2025-07-01", CategoryGroceries),
+		categorized("rent", "acc_1", "Office <Rent>", 1000, "2025-08-01", CategoryBills),
+		categorized("clinic", "acc_1", "Clinic", 300, "2025-09-01", CategoryHealth),
+		categorized("claim", "acc_1", "Aetna claim", -300, "2025-09-20", CategoryIncome),
+		categorized("dinner", "acc_1", "Bistro", 60, "2025-10-01", CategoryDining),
+		categorized("old", "acc_1", "CVS", 20, "2024-12-31", CategoryHealth),
+	)
+	space.deductible = []Category{CategoryBills}
+
+	items := storage.NewItemStore(store.db)
+	receipt := &core.Item{
+		ID:        "mail_apple",
+		Type:      core.ItemTypeEmail,
+		Status:    core.ItemStatusPending,
+		HatID:     core.HatPersonal,
+		Subject:   "Your receipt from Apple Store",
+		Timestamp: time.Date(2025, 3, 11, 9, 0, 0, 0, time.UTC),
+	}
+	if err := items.Create(receipt); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	space.SetItemStore(items)
+
+	if _, err := space.SplitTransaction("laptop", []Split{{Amount: usd(1500), Category: CategoryShopping, HatID: core.HatProfessional}}); err != nil {
+		t.Fatalf("SplitTransaction: %v", err)
+	}
+	if _, err := space.SplitTransaction("costco", []Split{
+		{Amount: usd(150), Category: CategoryGroceries},
+		{Amount: usd(50), Category: CategoryHealth},
+	}); err != nil {
+		t.Fatalf("SplitTransaction: %v", err)
+	}
+	// Paid back by the insurer, so not an expense
+	if _, err := space.ExpectReimbursement("clinic", 0, "Aetna", ""); err != nil {
+		t.Fatalf("ExpectReimbursement: %v", err)
+	}
+
+	if _, err := space.TaxReport(time.Now().Year() + 1); !errors.Is(err, ErrInvalidTaxYear) {
+		t.Errorf("future year: error = %v, want ErrInvalidTaxYear", err)
+	}
+	report, err := space.TaxReport(2025)
+	if err != nil {
+		t.Fatalf("TaxReport: %v", err)
+	}
+
+	want := map[TaxSectionKind]struct {
+		total int64
+		ids   string
+	}{
+		TaxBusiness:   {150000, "laptop"},
+		TaxCharitable: {10000, "redcross"},
+		TaxMedical:    {9000, "cvs,costco"},
+		TaxDeductible: {100000, "rent"},
+	}
+	if len(report.Sections) != len(want) {
+		t.Fatalf("sections = %d, want %d", len(report.Sections), len(want))
+	}
+	for _, section := range report.Sections {
+		var ids []string
+		for _, e := range section.Entries {
+			ids = append(ids, e.TransactionID)
+		}
+		w := want[section.Kind]
+		if section.Total.Minor != w.total || strings.Join(ids, ",") != w.ids {
+			t.Errorf("%s: total %s, entries %v; want %d minor, %s", section.Kind, section.Total, ids, w.total, w.ids)
+		}
+	}
+	if report.Total != (Money{269000, "USD"}) {
+		t.Errorf("total = %s, want $2690.00", report.Total)
+	}
+	laptop := report.Sections[0].Entries[0]
+	if laptop.ReceiptItemID != "mail_apple" || laptop.HatID != core.HatProfessional {
+		t.Errorf("laptop entry = %+v", laptop)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(rows) != 6 {
+		t.Fatalf("CSV rows = %d, want a header and 5 entries", len(rows))
+	}
+	if got := strings.Join(rows[1], "|"); got != "business|2025-03-10|laptop|Apple Store|shopping|professional|1500.00|USD|1500.00|USD|mail_apple|Your receipt from Apple Store" {
+		t.Errorf("first row = %s", got)
+	}
+
+	page := report.RenderHTML()
+	for _, s := range []string{"Tax Report 2025", "Charitable giving", "Office &lt;Rent&gt;", "$2690.00"} {
+		if !strings.Contains(page, s) {
+			t.Errorf("HTML is missing %q", s)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package finance
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook types and codes handled by the finance space
+const (
+	WebhookTypeTransactions = "TRANSACTIONS"
+	WebhookTypeItem         = "ITEM"
+
+	WebhookCodeSyncUpdatesAvailable = "SYNC_UPDATES_AVAILABLE"
+	WebhookCodeError                = "ERROR"
+	WebhookCodePendingExpiration    = "PENDING_EXPIRATION"
+)
+
+// maxWebhookAge is how old a webhook's signature may be before it is
+// rejected as a possible replay
+const maxWebhookAge = 5 * time.Minute
+
+const (
+	// keyRefreshInterval is how long a verification key is cached before it
+	// is fetched again, so keys Plaid has since expired are noticed
+	keyRefreshInterval = time.Hour
+
+	// keyMissTTL is how long a key ID Plaid couldn't provide is remembered,
+	// so made-up key IDs don't each cost a lookup
+	keyMissTTL = time.Minute
+)
+
+var (
+	// ErrInvalidWebhook is returned when a webhook fails verification
+	ErrInvalidWebhook = errors.New("invalid webhook signature")
+
+	// ErrUnknownItem is returned for a Plaid item with no connection
+	ErrUnknownItem = errors.New("unknown item")
+)
+
+// Webhook is the body of a Plaid webhook
+type Webhook struct {
+	WebhookType           string      `json:"webhook_type"`
+	WebhookCode           string      `json:"webhook_code"`
+	ItemID                string      `json:"item_id"`
+	Error                 *PlaidError `json:"error"`
+	ConsentExpirationTime *time.Time  `json:"consent_expiration_time"`
+	Environment           string      `json:"environment"`
+}
+
+// WebhookVerificationKey is the JWK Plaid signs webhooks with
+type WebhookVerificationKey struct {
+	Alg       string `json:"alg"`
+	Crv       string `json:"crv"`
+	Kid       string `json:"kid"`
+	Kty       string `json:"kty"`
+	Use       string `json:"use"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	CreatedAt int64  `json:"created_at"`
+	ExpiredAt *int64 `json:"expired_at"`
+}
+
+// GetWebhookVerificationKey retrieves the public key for a webhook key ID
+func (c *PlaidClient) GetWebhookVerificationKey(ctx context.Context, keyID string) (*WebhookVerificationKey, error) {
+	req := map[string]string{
+		"key_id": keyID,
+	}
+
+	var resp struct {
+		Key       WebhookVerificationKey `json:"key"`
+		RequestID string                 `json:"request_id"`
+	}
+	if err := c.request(ctx, "/webhook_verification_key/get", req, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp.Key, nil
+}
+
+// WebhookVerifier checks the Plaid-Verification header of incoming
+// webhooks. Keys are fetched from Plaid on first use and cached by ID.
+type WebhookVerifier struct {
+	client   *PlaidClient
+	keys     map[string]*cachedKey
+	fetching map[string]chan struct{} // Closed when the fetch for a key ID ends
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// cachedKey is the outcome of fetching a key ID
+type cachedKey struct {
+	key       *WebhookVerificationKey
+	err       error
+	fetchedAt time.Time
+}
+
+// fresh reports whether the outcome can be used without fetching again
+func (c *cachedKey) fresh(now time.Time) bool {
+	ttl := keyRefreshInterval
+	if c.err != nil {
+		ttl = keyMissTTL
+	}
+	return now.Sub(c.fetchedAt) < ttl
+}
+
+// NewWebhookVerifier creates a verifier that fetches keys with client
+func NewWebhookVerifier(client *PlaidClient) *WebhookVerifier {
+	return &WebhookVerifier{
+		client:   client,
+		keys:     make(map[string]*cachedKey),
+		fetching: make(map[string]chan struct{}),
+		now:      time.Now,
+	}
+}
+
+// Verify checks that token is an ES256 JWT signed by Plaid, issued in the
+// last five minutes, over a body with the given contents
+func (v *WebhookVerifier) Verify(ctx context.Context, token string, body []byte) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", ErrInvalidWebhook)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("%w: header: %v", ErrInvalidWebhook, err)
+	}
+	if header.Alg != "ES256" {
+		return fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidWebhook, header.Alg)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+	if key.ExpiredAt != nil {
+		return fmt.Errorf("%w: key %s has expired", ErrInvalidWebhook, key.Kid)
+	}
+
+	pub, err := key.publicKey()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidWebhook)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(pub, digest[:], r, s) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidWebhook)
+	}
+
+	var claims struct {
+		IssuedAt          int64  `json:"iat"`
+		RequestBodySHA256 string `json:"request_body_sha256"`
+	}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("%w: claims: %v", ErrInvalidWebhook, err)
+	}
+	issuedAt, now := time.Unix(claims.IssuedAt, 0), v.now()
+	if issuedAt.After(now) {
+		return fmt.Errorf("%w: token is issued in the future", ErrInvalidWebhook)
+	}
+	if now.Sub(issuedAt) > maxWebhookAge {
+		return fmt.Errorf("%w: token is too old", ErrInvalidWebhook)
+	}
+
+	bodyHash := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(bodyHash[:])), []byte(claims.RequestBodySHA256)) != 1 {
+		return fmt.Errorf("%w: body does not match", ErrInvalidWebhook)
+	}
+
+	return nil
+}
+
+// key returns the cached key for kid, fetching it from Plaid if needed.
+// Only one fetch per key ID runs at a time, and the lock isn't held while
+// it does, so other webhooks aren't held up.
+func (v *WebhookVerifier) key(ctx context.Context, kid string) (*WebhookVerificationKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("%w: missing key ID", ErrInvalidWebhook)
+	}
+
+	for {
+		v.mu.Lock()
+		cached := v.keys[kid]
+		if cached != nil && cached.fresh(v.now()) {
+			v.mu.Unlock()
+			return cached.key, cached.err
+		}
+		wait, busy := v.fetching[kid]
+		if !busy {
+			done := make(chan struct{})
+			v.fetching[kid] = done
+			v.mu.Unlock()
+			return v.fetch(ctx, kid, cached, done)
+		}
+		v.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// fetch gets kid from Plaid and caches the outcome. A key that can't be
+// refreshed stays in use; cancelled fetches aren't cached.
+func (v *WebhookVerifier) fetch(ctx context.Context, kid string, cached *cachedKey, done chan struct{}) (*WebhookVerificationKey, error) {
+	key, err := v.client.GetWebhookVerificationKey(ctx, kid)
+	if err != nil {
+		err = fmt.Errorf("get verification key: %w", err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.fetching, kid)
+	close(done)
+
+	if err != nil && cached != nil && cached.key != nil {
+		return cached.key, nil
+	}
+	if ctx.Err() != nil {
+		return key, err
+	}
+
+	now := v.now()
+	if err != nil {
+		// Forget old misses so made-up key IDs can't grow the cache
+		for id, c := range v.keys {
+			if c.err != nil && !c.fresh(now) {
+				delete(v.keys, id)
+			}
+		}
+	}
+	v.keys[kid] = &cachedKey{key: key, err: err, fetchedAt: now}
+	return key, err
+}
+
+// publicKey converts the JWK to an ECDSA P-256 public key
+func (k *WebhookVerificationKey) publicKey() (*ecdsa.PublicKey, error) {
+	if k.Kty != "EC" || k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported key type %s/%s", k.Kty, k.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("decode x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("decode y: %w", err)
+	}
+
+	if len(x) != 32 || len(y) != 32 {
+		return nil, fmt.Errorf("malformed key coordinates")
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
+
+// decodeSegment decodes a base64url JWT segment into v
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
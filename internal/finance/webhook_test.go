@@ -0,0 +1,359 @@
+package finance
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/spaces"
+)
+
+func newWebhookKey(t *testing.T, kid string) (*ecdsa.PrivateKey, WebhookVerificationKey) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return priv, WebhookVerificationKey{
+		Alg: "ES256",
+		Crv: "P-256",
+		Kid: kid,
+		Kty: "EC",
+		Use: "sig",
+		X:   base64.RawURLEncoding.EncodeToString(priv.PublicKey.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(priv.PublicKey.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// signWebhook builds a Plaid-Verification token for body
+func signWebhook(t *testing.T, priv *ecdsa.PrivateKey, kid, alg string, issuedAt time.Time, body []byte) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	bodyHash := sha256.Sum256(body)
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat":                 issuedAt.Unix(),
+		"request_body_sha256": hex.EncodeToString(bodyHash[:]),
+	})
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestWebhookVerifier_Verify(t *testing.T) {
+	priv, key := newWebhookKey(t, "key_1")
+	_, otherKey := newWebhookKey(t, "key_2")
+	expired := time.Now().Add(-time.Hour).Unix()
+	expiredPriv, expiredKey := newWebhookKey(t, "key_old")
+	expiredKey.ExpiredAt = &expired
+
+	mock := &mockPlaid{keys: map[string]WebhookVerificationKey{
+		"key_1":   key,
+		"key_2":   otherKey,
+		"key_old": expiredKey,
+	}}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	verifier := NewWebhookVerifier(NewPlaidClient(PlaidConfig{BaseURL: server.URL}))
+	body := []byte(`{"webhook_type":"TRANSACTIONS","webhook_code":"SYNC_UPDATES_AVAILABLE","item_id":"item_1"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   string
+		body    []byte
+		wantErr bool
+	}{
+		{"valid", signWebhook(t, priv, "key_1", "ES256", now, body), body, false},
+		{"tampered body", signWebhook(t, priv, "key_1", "ES256", now, body), []byte(`{"item_id":"item_2"}`), true},
+		{"stale", signWebhook(t, priv, "key_1", "ES256", now.Add(-10*time.Minute), body), body, true},
+		{"issued in the future", signWebhook(t, priv, "key_1", "ES256", now.Add(10*time.Minute), body), body, true},
+		{"wrong algorithm", signWebhook(t, priv, "key_1", "HS256", now, body), body, true},
+		{"wrong key", signWebhook(t, priv, "key_2", "ES256", now, body), body, true},
+		{"expired key", signWebhook(t, expiredPriv, "key_old", "ES256", now, body), body, true},
+		{"malformed", "not-a-jwt", body, true},
+		{"missing", "", body, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifier.Verify(context.Background(), tt.token, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidWebhook) {
+				t.Errorf("Verify() error = %v, want ErrInvalidWebhook", err)
+			}
+		})
+	}
+
+	// Keys are fetched once per ID
+	verifier.Verify(context.Background(), signWebhook(t, priv, "key_1", "ES256", now, body), body)
+	if mock.keyGets != 3 {
+		t.Errorf("key fetches = %d, want 3", mock.keyGets)
+	}
+}
+
+func TestWebhookVerifier_UnknownKey(t *testing.T) {
+	priv, key := newWebhookKey(t, "key_1")
+	mock := &mockPlaid{}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	verifier := NewWebhookVerifier(NewPlaidClient(PlaidConfig{BaseURL: server.URL}))
+	clock := time.Now()
+	verifier.now = func() time.Time { return clock }
+	verify := func() error {
+		body := []byte(`{}`)
+		return verifier.Verify(context.Background(), signWebhook(t, priv, "key_1", "ES256", clock, body), body)
+	}
+
+	if err := verify(); err == nil {
+		t.Fatal("Verify() succeeded with an unknown key")
+	}
+	// Misses are remembered for a while
+	verify()
+	if mock.keyGets != 1 {
+		t.Errorf("key fetches = %d, want 1", mock.keyGets)
+	}
+
+	// Once the miss is forgotten, a key Plaid now has is fetched
+	mock.keys = map[string]WebhookVerificationKey{"key_1": key}
+	clock = clock.Add(keyMissTTL)
+	if err := verify(); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// Cached keys are fetched again, so a key expired since is rejected
+	expired := clock.Unix()
+	key.ExpiredAt = &expired
+	mock.keys["key_1"] = key
+	if err := verify(); err != nil {
+		t.Fatalf("Verify() before refresh error = %v", err)
+	}
+	clock = clock.Add(keyRefreshInterval)
+	if err := verify(); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("Verify() with an expired key error = %v, want ErrInvalidWebhook", err)
+	}
+	if mock.keyGets != 3 {
+		t.Errorf("key fetches = %d, want 3", mock.keyGets)
+	}
+}
+
+func TestSpace_SyncItem(t *testing.T) {
+	store, _ := newTestStore(t)
+	first := testConnection()
+	second := testConnection()
+	second.ID, second.ItemID, second.InstitutionName = "conn_2", "item_2", "Other Bank"
+	second.Accounts[0].AccountID = "acc_2"
+	second.SyncCursor = "other"
+	for _, conn := range []*Connection{first, second} {
+		if err := store.SaveConnection(conn); err != nil {
+			t.Fatalf("SaveConnection: %v", err)
+		}
+	}
+
+	mock := &mockPlaid{
+		accounts: first.Accounts,
+		pages: map[string]TransactionsSyncResponse{
+			"": {Added: []Transaction{testTransaction("tx_1", "Coffee", 4.50, "2026-01-02")}, NextCursor: "c1"},
+		},
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", PlaidConfig: PlaidConfig{BaseURL: server.URL}})
+	space.SetStore(store)
+	if err := space.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	ctx := context.Background()
+	result, err := space.SyncItem(ctx, "item_1")
+	if err != nil {
+		t.Fatalf("SyncItem: %v", err)
+	}
+	if result.NewItems != 1 || len(result.Errors) != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if cursors := mock.requested(); len(cursors) != 1 || cursors[0] != "" {
+		t.Errorf("requested cursors = %v, want only item_1's", cursors)
+	}
+
+	connections, err := store.Connections()
+	if err != nil {
+		t.Fatalf("Connections: %v", err)
+	}
+	for _, conn := range connections {
+		want := map[string]string{"conn_1": "c1", "conn_2": "other"}[conn.ID]
+		if conn.SyncCursor != want {
+			t.Errorf("%s cursor = %q, want %q", conn.ID, conn.SyncCursor, want)
+		}
+	}
+
+	if _, err := space.SyncItem(ctx, "item_unknown"); !errors.Is(err, ErrUnknownItem) {
+		t.Errorf("SyncItem(unknown) error = %v, want ErrUnknownItem", err)
+	}
+}
+
+func TestSpace_SyncItem_ConcurrentWithSync(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	mock := &mockPlaid{
+		accounts: testConnection().Accounts,
+		pages: map[string]TransactionsSyncResponse{
+			"": {Added: []Transaction{testTransaction("tx_1", "Coffee", 4.50, "2026-01-02")}, NextCursor: "c1"},
+		},
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", PlaidConfig: PlaidConfig{BaseURL: server.URL}})
+	space.SetStore(store)
+	ctx := context.Background()
+	if err := space.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	// A scheduled sync and a webhook sync of the same item
+	var wg sync.WaitGroup
+	results := make([]*spaces.SyncResult, 2)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		results[0], _ = space.Sync(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		results[1], _ = space.SyncItem(ctx, "item_1")
+	}()
+	wg.Wait()
+
+	for i, result := range results {
+		if result == nil || len(result.Errors) > 0 {
+			t.Fatalf("sync %d: %+v", i, result)
+		}
+	}
+	if added := results[0].NewItems + results[1].NewItems; added != 1 {
+		t.Errorf("NewItems = %d, want 1 across both syncs", added)
+	}
+
+	// The second sync starts from the cursor the first one saved
+	if cursors := mock.requested(); strings.Join(cursors, ",") != ",c1" {
+		t.Errorf("requested cursors = %q, want [\"\" c1]", cursors)
+	}
+	connections, err := store.Connections()
+	if err != nil {
+		t.Fatalf("Connections: %v", err)
+	}
+	if len(connections) != 1 || connections[0].SyncCursor != "c1" {
+		t.Errorf("stored connections = %+v", connections)
+	}
+}
+
+func TestSpace_QueueItemSync_Coalesces(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	mock := &mockPlaid{
+		accounts: testConnection().Accounts,
+		pages: map[string]TransactionsSyncResponse{
+			"": {Added: []Transaction{testTransaction("tx_1", "Coffee", 4.50, "2026-01-02")}, NextCursor: "c1"},
+		},
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance", PlaidConfig: PlaidConfig{BaseURL: server.URL}})
+	space.SetStore(store)
+	if err := space.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	// Plaid retries and repeats webhooks; they share one follow-up sync
+	for i := 0; i < 5; i++ {
+		if err := space.QueueItemSync("item_1"); err != nil {
+			t.Fatalf("QueueItemSync: %v", err)
+		}
+	}
+	if err := space.QueueItemSync("item_unknown"); !errors.Is(err, ErrUnknownItem) {
+		t.Errorf("QueueItemSync(unknown) error = %v, want ErrUnknownItem", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		space.mu.RLock()
+		pending := len(space.queuedSyncs)
+		space.mu.RUnlock()
+		if pending == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if cursors := mock.requested(); len(cursors) == 0 || len(cursors) > 2 || cursors[0] != "" {
+		t.Errorf("requested cursors = %q, want at most one sync plus one follow-up", cursors)
+	}
+	if got := len(space.GetTransactions(TransactionFilter{})); got != 1 {
+		t.Errorf("transactions = %d, want 1", got)
+	}
+}
+
+func TestSpace_SetItemStatus(t *testing.T) {
+	store, _ := newTestStore(t)
+	if err := store.SaveConnection(testConnection()); err != nil {
+		t.Fatalf("SaveConnection: %v", err)
+	}
+
+	space := NewSpace(SpaceConfig{ID: "finance", Name: "Finance"})
+	space.SetStore(store)
+	if err := space.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	conn, err := space.SetItemStatus("item_1", ConnectionStatusError)
+	if err != nil {
+		t.Fatalf("SetItemStatus: %v", err)
+	}
+	if conn.ID != "conn_1" || conn.Status != ConnectionStatusError {
+		t.Errorf("connection = %+v", conn)
+	}
+
+	connections, _ := store.Connections()
+	if len(connections) != 1 || connections[0].Status != ConnectionStatusError {
+		t.Errorf("stored connections = %+v", connections)
+	}
+
+	// An errored item is not synced until it is re-linked
+	result, err := space.SyncItem(context.Background(), "item_1")
+	if err != nil {
+		t.Fatalf("SyncItem: %v", err)
+	}
+	if result.NewItems != 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
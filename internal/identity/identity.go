@@ -147,6 +147,11 @@ func (m *Manager) Unlock(you *core.You, serialized *SerializedKeyBundle, passphr
 	return nil
 }
 
+// IsUnlocked reports whether Unlock has been called
+func (m *Manager) IsUnlocked() bool {
+	return m.keys != nil
+}
+
 // Encrypt encrypts data using the identity's encryption key
 func (m *Manager) Encrypt(data []byte) ([]byte, error) {
 	if m.keys == nil {
@@ -4,6 +4,7 @@ package intelligence
 import (
 	"context"
 	"fmt"
+	"net/mail"
 	"regexp"
 	"sort"
 	"strings"
@@ -20,6 +21,7 @@ type CrossDomainEngine struct {
 	router        *llm.Router
 	itemStore     *storage.ItemStore
 	calendarSpace *calendar.Space
+	entities      *storage.EntityStore
 	config        Config
 }
 
@@ -58,6 +60,21 @@ func NewCrossDomainEngine(router *llm.Router, itemStore *storage.ItemStore, cale
 	}
 }
 
+// SetEntityStore lets the engine recognize people across their addresses and
+// record who attends which events
+func (e *CrossDomainEngine) SetEntityStore(entities *storage.EntityStore) {
+	e.entities = entities
+}
+
+// About returns everything known about a person, organization or place:
+// their aliases, recent items, hats and events
+func (e *CrossDomainEngine) About(ref string, limit int) (*storage.EntityProfile, error) {
+	if e.entities == nil {
+		return nil, fmt.Errorf("entity graph not available")
+	}
+	return e.entities.About(ref, limit)
+}
+
 // Insight represents a cross-domain insight
 type Insight struct {
 	ID          string       `json:"id"`
@@ -113,6 +130,16 @@ func (e *CrossDomainEngine) AnalyzeUpcoming(ctx context.Context, days int) ([]In
 		return nil, fmt.Errorf("get upcoming events: %w", err)
 	}
 
+	// Record attendees in the entity graph. This is bookkeeping, so a
+	// failure doesn't hold up the analysis.
+	if e.entities != nil {
+		for _, event := range events {
+			if err := e.entities.LinkEvent(event.ID, event.Start, eventAttendees(event)); err != nil {
+				fmt.Printf("Warning: failed to link attendees of event %s: %v\n", event.ID, err)
+			}
+		}
+	}
+
 	// Get recent emails
 	items, err := e.itemStore.GetRecent(500)
 	if err != nil {
@@ -214,6 +241,10 @@ func (e *CrossDomainEngine) correlateEventWithItems(event calendar.Event, items
 				eventTerms[term] = true
 			}
 		}
+		// Attendees also write from their other addresses
+		for _, email := range e.otherAddresses(att.Email) {
+			attendeeEmails[email] = true
+		}
 	}
 
 	for _, item := range items {
@@ -454,8 +485,38 @@ func (e *CrossDomainEngine) GetInsightsForItem(ctx context.Context, itemID core.
 	return insights, nil
 }
 
+// otherAddresses returns every email address of the person behind an address
+func (e *CrossDomainEngine) otherAddresses(email string) []string {
+	if e.entities == nil || email == "" {
+		return nil
+	}
+	entity, err := e.entities.Resolve(email)
+	if err != nil {
+		return nil
+	}
+	var addresses []string
+	for _, alias := range entity.Aliases {
+		if alias.Type == core.AliasTypeEmail {
+			addresses = append(addresses, alias.Value)
+		}
+	}
+	return addresses
+}
+
 // Helper functions
 
+func eventAttendees(event calendar.Event) []*mail.Address {
+	attendees := make([]*mail.Address, 0, len(event.Attendees))
+	for _, att := range event.Attendees {
+		// The calendar owner attends everything; linking them says nothing
+		if att.Self {
+			continue
+		}
+		attendees = append(attendees, &mail.Address{Name: att.DisplayName, Address: att.Email})
+	}
+	return attendees
+}
+
 func extractTerms(text string) map[string]bool {
 	terms := make(map[string]bool)
 	// Simple word extraction
@@ -0,0 +1,74 @@
+package intelligence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/spaces/calendar"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+func TestCorrelateEventWithItems_EntityAliases(t *testing.T) {
+	db, err := storage.Open(storage.Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	// Alice writes from home but is invited with her work address
+	itemStore := storage.NewItemStore(db)
+	entities := storage.NewEntityStore(db)
+	work := &core.Item{ID: "work", Type: core.ItemTypeEmail, Status: core.ItemStatusPending,
+		HatID: core.HatProfessional, From: "Alice Smith <alice@acme.io>", Timestamp: time.Now().Add(-30 * 24 * time.Hour)}
+	home := &core.Item{ID: "home", Type: core.ItemTypeEmail, Status: core.ItemStatusPending,
+		HatID: core.HatPersonal, From: "alice@home.net", Subject: "Weekly sync agenda", Timestamp: time.Now()}
+	for _, item := range []*core.Item{work, home} {
+		itemStore.Create(item)
+		entities.LinkItem(item, nil)
+	}
+
+	event := calendar.Event{
+		ID:        "sync",
+		Summary:   "Weekly sync",
+		Start:     time.Now().Add(time.Hour),
+		End:       time.Now().Add(2 * time.Hour),
+		Attendees: []calendar.Attendee{{Email: "alice@acme.io", DisplayName: "Alice Smith"}, {Email: "me@example.com", Self: true}},
+	}
+
+	engine := NewCrossDomainEngine(nil, itemStore, nil, DefaultConfig())
+	engine.SetEntityStore(entities)
+
+	if got := engine.correlateEventWithItems(event, []*core.Item{home}); len(got) != 0 {
+		t.Fatalf("correlated before the addresses were merged: %+v", got)
+	}
+
+	alice, _ := entities.Resolve("alice@acme.io")
+	other, _ := entities.Resolve("alice@home.net")
+	if _, err := entities.Merge(alice.ID, other.ID); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	got := engine.correlateEventWithItems(event, []*core.Item{home})
+	if len(got) != 1 || got[0].ItemID != "home" {
+		t.Fatalf("correlateEventWithItems() = %+v, want the home email", got)
+	}
+
+	// Attendees are recorded, apart from the calendar owner
+	if err := entities.LinkEvent(event.ID, event.Start, eventAttendees(event)); err != nil {
+		t.Fatalf("LinkEvent() error = %v", err)
+	}
+	profile, err := engine.About("Alice Smith", 10)
+	if err != nil {
+		t.Fatalf("About() error = %v", err)
+	}
+	if len(profile.Events) != 1 || profile.ItemCount != 2 {
+		t.Errorf("unexpected profile: %+v", profile)
+	}
+	if _, err := entities.Resolve("me@example.com"); err != core.ErrEntityNotFound {
+		t.Errorf("calendar owner linked: %v", err)
+	}
+}
@@ -186,6 +186,55 @@ func (c *Collector) CaptureTriageSignal(ctx context.Context, item *core.Item, tr
 	return c.CaptureSignal(ctx, SignalEmailOpened, item.ID, item.HatID, triageResult, extraContext)
 }
 
+// TriageOverride is a user's correction of a triage decision. Zero fields
+// were not corrected.
+type TriageOverride struct {
+	OriginalHatID    core.HatID
+	OriginalPriority int
+	Priority         int      // Corrected priority
+	Actions          []string // Actions the user says should have been suggested
+}
+
+// CaptureTriageOverride records that the user moved item to hatID or
+// otherwise corrected its triage. These signals seed the triage
+// evaluation dataset.
+func (c *Collector) CaptureTriageOverride(ctx context.Context, item *core.Item, hatID core.HatID, override TriageOverride) error {
+	value := map[string]interface{}{
+		"original_hat_id": string(override.OriginalHatID),
+	}
+	if override.OriginalPriority > 0 {
+		value["original_priority"] = override.OriginalPriority
+	}
+	if override.Priority > 0 {
+		value["priority"] = override.Priority
+	}
+	if len(override.Actions) > 0 {
+		value["actions"] = override.Actions
+	}
+
+	extraContext := SignalContext{
+		Sender:      item.From,
+		ItemType:    string(item.Type),
+		ItemSubject: item.Subject,
+		Confidence:  item.Confidence,
+	}
+
+	return c.CaptureSignal(ctx, SignalTriageOverride, item.ID, hatID, value, extraContext)
+}
+
+// CaptureTransactionCategorized records that the user moved a
+// transaction from one spending category to another
+func (c *Collector) CaptureTransactionCategorized(ctx context.Context, transactionID, merchant, from, to string) error {
+	value := map[string]interface{}{
+		"transaction_id": transactionID,
+		"merchant":       merchant,
+		"from_category":  from,
+		"to_category":    to,
+	}
+
+	return c.CaptureSignal(ctx, SignalTransactionCategorized, "", core.HatFinance, value, SignalContext{ItemType: "transaction"})
+}
+
 // CaptureResponseTimeSignal captures how long user took to respond
 func (c *Collector) CaptureResponseTimeSignal(ctx context.Context, item *core.Item, responseTime time.Duration) error {
 	value := map[string]interface{}{
@@ -58,6 +58,8 @@ const (
 	ActionSettingsChanged  = "settings.changed"
 	ActionUserLogin        = "user.login"
 	ActionUserLogout       = "user.logout"
+	ActionToolInvoked      = "mcp.tool_invoked"
+	ActionLLMCloudCall     = "llm.cloud_call"
 )
 
 // ActorType constants
@@ -537,3 +539,16 @@ func (r *Recorder) RecordMeshEvent(action, actor, agentID string, details map[st
 	_, err := r.store.Append(action, actor, "mesh", agentID, details)
 	return err
 }
+
+// RecordToolInvocation records an MCP tool invocation by a client
+func (r *Recorder) RecordToolInvocation(actor, tool string, details map[string]interface{}) error {
+	_, err := r.store.Append(ActionToolInvoked, actor, "tool", tool, details)
+	return err
+}
+
+// RecordCloudLLMCall records a request sent to a cloud LLM provider, with
+// the privacy decision made for it
+func (r *Recorder) RecordCloudLLMCall(provider string, details map[string]interface{}) error {
+	_, err := r.store.Append(ActionLLMCloudCall, ActorAgent, "llm_provider", provider, details)
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -76,13 +77,28 @@ type AzureMessage struct {
 
 // AzureChatRequest is the Azure OpenAI chat request
 type AzureChatRequest struct {
-	Messages         []AzureMessage `json:"messages"`
-	MaxTokens        int            `json:"max_tokens,omitempty"`
-	Temperature      float64        `json:"temperature,omitempty"`
-	TopP             float64        `json:"top_p,omitempty"`
-	FrequencyPenalty float64        `json:"frequency_penalty,omitempty"`
-	PresencePenalty  float64        `json:"presence_penalty,omitempty"`
-	Stop             []string       `json:"stop,omitempty"`
+	Messages         []AzureMessage       `json:"messages"`
+	MaxTokens        int                  `json:"max_tokens,omitempty"`
+	Temperature      float64              `json:"temperature,omitempty"`
+	TopP             float64              `json:"top_p,omitempty"`
+	FrequencyPenalty float64              `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64              `json:"presence_penalty,omitempty"`
+	Stop             []string             `json:"stop,omitempty"`
+	Stream           bool                 `json:"stream,omitempty"`
+	ResponseFormat   *AzureResponseFormat `json:"response_format,omitempty"`
+}
+
+// AzureResponseFormat constrains the response to JSON, optionally matching
+// a schema
+type AzureResponseFormat struct {
+	Type       string           `json:"type"` // json_object or json_schema
+	JSONSchema *AzureJSONSchema `json:"json_schema,omitempty"`
+}
+
+// AzureJSONSchema is a named schema for json_schema responses
+type AzureJSONSchema struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
 }
 
 // AzureChatResponse is the Azure OpenAI chat response
@@ -169,6 +185,97 @@ func (c *AzureClient) Complete(ctx context.Context, req AzureChatRequest) (*Azur
 	return &azureResp, nil
 }
 
+// Stream sends a chat completion request and passes text to onDelta as it
+// is generated. It returns the full text once the response is complete;
+// streamed responses carry no token usage.
+func (c *AzureClient) Stream(ctx context.Context, req AzureChatRequest, onDelta StreamFunc) (*Completion, error) {
+	if req.MaxTokens == 0 {
+		req.MaxTokens = 4096
+	}
+	req.Stream = true
+
+	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		c.endpoint, c.deployment, c.apiVersion)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("api-key", c.apiKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Azure API error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var text strings.Builder
+	err = readSSE(resp.Body, func(event, data string) error {
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		// Content filter results arrive as chunks without choices
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+		delta := chunk.Choices[0].Delta.Content
+		text.WriteString(delta)
+		if onDelta != nil {
+			onDelta(delta)
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("stream failed: %w", err)
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("empty response from Azure OpenAI")
+	}
+
+	// Streamed responses carry no usage
+	return &Completion{Text: text.String()}, nil
+}
+
+// ChatStream handles multi-turn conversation with a streamed response
+func (c *AzureClient) ChatStream(ctx context.Context, system string, messages []AzureMessage, onDelta StreamFunc) (string, error) {
+	allMessages := make([]AzureMessage, 0, len(messages)+1)
+	allMessages = append(allMessages, AzureMessage{Role: "system", Content: system})
+	allMessages = append(allMessages, messages...)
+
+	resp, err := c.Stream(ctx, AzureChatRequest{Messages: allMessages}, onDelta)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
 // Chat is a convenience method for simple chat
 func (c *AzureClient) Chat(ctx context.Context, system, userMessage string) (string, error) {
 	messages := []AzureMessage{
@@ -0,0 +1,77 @@
+package llm
+
+import "time"
+
+// CircuitState is the state of a provider's circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Requests flow normally
+	CircuitOpen     CircuitState = "open"      // Provider is skipped until the cooldown ends
+	CircuitHalfOpen CircuitState = "half_open" // One trial request is let through
+)
+
+// circuitBreaker stops requests to a provider after repeated failures and
+// lets a single trial through once the cooldown has passed
+type circuitBreaker struct {
+	threshold int           // Consecutive failures that open the circuit
+	cooldown  time.Duration // How long the circuit stays open
+
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trial    bool // A half-open trial is in flight
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, state: CircuitClosed}
+}
+
+// allow reports whether a request may be sent now
+func (b *circuitBreaker) allow(now time.Time) bool {
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.trial = true
+		return true
+	case CircuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	}
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *circuitBreaker) failure(now time.Time) {
+	b.failures++
+	b.trial = false
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = now
+	}
+}
+
+// release ends a trial that finished without a verdict, such as one
+// cancelled by the caller
+func (b *circuitBreaker) release() {
+	b.trial = false
+}
+
+// current returns the state as seen at now
+func (b *circuitBreaker) current(now time.Time) CircuitState {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
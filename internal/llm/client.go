@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -69,11 +70,27 @@ type Message struct {
 
 // Request is the API request structure
 type Request struct {
-	Model       string    `json:"model"`
-	MaxTokens   int       `json:"max_tokens"`
-	System      string    `json:"system,omitempty"`
-	Messages    []Message `json:"messages"`
-	Temperature float64   `json:"temperature,omitempty"`
+	Model       string      `json:"model"`
+	MaxTokens   int         `json:"max_tokens"`
+	System      string      `json:"system,omitempty"`
+	Messages    []Message   `json:"messages"`
+	Temperature float64     `json:"temperature,omitempty"`
+	Stream      bool        `json:"stream,omitempty"`
+	Tools       []Tool      `json:"tools,omitempty"`
+	ToolChoice  *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// Tool describes a tool the model may call
+type Tool struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	InputSchema *Schema `json:"input_schema"`
+}
+
+// ToolChoice controls whether and which tool the model calls
+type ToolChoice struct {
+	Type string `json:"type"` // auto, any or tool
+	Name string `json:"name,omitempty"`
 }
 
 // Response is the API response structure
@@ -96,6 +113,73 @@ type Response struct {
 
 // Complete sends a completion request
 func (c *Client) Complete(ctx context.Context, req Request) (*Response, error) {
+	respBody, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var llmResp Response
+	if err := json.Unmarshal(respBody, &llmResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &llmResp, nil
+}
+
+// CompleteJSON sends a completion request whose response must match schema.
+// The model is made to answer by calling a tool with the schema as its
+// input, and the tool input is returned as the completion text.
+func (c *Client) CompleteJSON(ctx context.Context, req Request, schema *Schema) (*Completion, error) {
+	req.Tools = []Tool{{
+		Name:        schema.name(),
+		Description: "Record the response. " + schema.Description,
+		InputSchema: schema,
+	}}
+	req.ToolChoice = &ToolChoice{Type: "tool", Name: schema.name()}
+
+	respBody, err := c.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var llmResp struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &llmResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Fall back to text if the model answered without the tool
+	var text string
+	for _, block := range llmResp.Content {
+		if block.Type == "tool_use" {
+			text = string(block.Input)
+			break
+		}
+		if block.Type == "text" && text == "" {
+			text = block.Text
+		}
+	}
+	if text == "" {
+		return nil, fmt.Errorf("empty response")
+	}
+
+	return &Completion{
+		Text:  text,
+		Usage: Usage{InputTokens: llmResp.Usage.InputTokens, OutputTokens: llmResp.Usage.OutputTokens},
+	}, nil
+}
+
+// post sends a messages request and returns the response body
+func (c *Client) post(ctx context.Context, req Request) ([]byte, error) {
 	if req.Model == "" {
 		req.Model = c.model
 	}
@@ -132,12 +216,120 @@ func (c *Client) Complete(ctx context.Context, req Request) (*Response, error) {
 		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	var llmResp Response
-	if err := json.Unmarshal(respBody, &llmResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	return respBody, nil
+}
+
+// Stream sends a completion request and passes text to onDelta as it is
+// generated. It returns the full text once the response is complete.
+func (c *Client) Stream(ctx context.Context, req Request, onDelta StreamFunc) (*Completion, error) {
+	if req.Model == "" {
+		req.Model = c.model
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = 4096
 	}
+	req.Stream = true
 
-	return &llmResp, nil
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var text strings.Builder
+	var usage Usage
+	err = readSSE(resp.Body, func(event, data string) error {
+		switch event {
+		case "message_start":
+			var payload struct {
+				Message struct {
+					Usage Usage `json:"usage"`
+				} `json:"message"`
+			}
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				return fmt.Errorf("failed to decode stream event: %w", err)
+			}
+			usage.InputTokens = payload.Message.Usage.InputTokens
+		case "message_delta":
+			var payload struct {
+				Usage Usage `json:"usage"`
+			}
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				return fmt.Errorf("failed to decode stream event: %w", err)
+			}
+			usage.OutputTokens = payload.Usage.OutputTokens
+		case "content_block_delta":
+			var payload struct {
+				Delta struct {
+					Type string `json:"type"`
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			if err := json.Unmarshal([]byte(data), &payload); err != nil {
+				return fmt.Errorf("failed to decode stream event: %w", err)
+			}
+			if payload.Delta.Type == "text_delta" && payload.Delta.Text != "" {
+				text.WriteString(payload.Delta.Text)
+				if onDelta != nil {
+					onDelta(payload.Delta.Text)
+				}
+			}
+		case "error":
+			var payload struct {
+				Error struct {
+					Type    string `json:"type"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			json.Unmarshal([]byte(data), &payload)
+			return fmt.Errorf("API error %s: %s", payload.Error.Type, payload.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("stream failed: %w", err)
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("empty response")
+	}
+
+	return &Completion{Text: text.String(), Usage: usage}, nil
+}
+
+// ChatStream handles multi-turn conversation with a streamed response
+func (c *Client) ChatStream(ctx context.Context, system string, messages []Message, onDelta StreamFunc) (string, error) {
+	resp, err := c.Stream(ctx, Request{
+		System:   system,
+		Messages: messages,
+	}, onDelta)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
 }
 
 // Chat is a convenience method for simple chat
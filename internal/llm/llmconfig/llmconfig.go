@@ -0,0 +1,37 @@
+// Package llmconfig turns the user's LLM settings into router config, so
+// the CLI and the daemon set up routing alike without the llm package
+// depending on config.
+package llmconfig
+
+import (
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/config"
+	"github.com/quantumlife/quantumlife/internal/llm"
+)
+
+// RouterConfig builds the routing, fallback and budget part of a router
+// config from the user's LLM settings: local models first, falling back
+// along the configured chain. Callers set the clients.
+func RouterConfig(cfg config.LLMConfig) llm.RouterConfig {
+	routerCfg := llm.RouterConfig{
+		PreferLocal:      true,
+		EnableFallback:   true,
+		Timeout:          time.Duration(cfg.TimeoutSeconds) * time.Second,
+		DailyBudgetUSD:   cfg.DailyBudgetUSD,
+		MonthlyBudgetUSD: cfg.MonthlyBudgetUSD,
+	}
+	for _, p := range cfg.FallbackChain {
+		routerCfg.FallbackChain = append(routerCfg.FallbackChain, llm.Provider(p))
+	}
+	if len(cfg.Pricing) > 0 {
+		routerCfg.Pricing = make(map[llm.Provider]llm.Pricing, len(cfg.Pricing))
+		for p, price := range cfg.Pricing {
+			routerCfg.Pricing[llm.Provider(p)] = llm.Pricing{
+				InputPerMTok:  price.InputPerMTok,
+				OutputPerMTok: price.OutputPerMTok,
+			}
+		}
+	}
+	return routerCfg
+}
@@ -0,0 +1,32 @@
+package llmconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/config"
+	"github.com/quantumlife/quantumlife/internal/llm"
+)
+
+func TestRouterConfig(t *testing.T) {
+	cfg := RouterConfig(config.LLMConfig{
+		DailyBudgetUSD:   1,
+		MonthlyBudgetUSD: 20,
+		TimeoutSeconds:   30,
+		FallbackChain:    []string{"azure", "ollama"},
+		Pricing:          map[string]config.LLMPricing{"claude": {InputPerMTok: 3, OutputPerMTok: 15}},
+	})
+
+	if cfg.DailyBudgetUSD != 1 || cfg.MonthlyBudgetUSD != 20 {
+		t.Errorf("budgets = %v/%v, want 1/20", cfg.DailyBudgetUSD, cfg.MonthlyBudgetUSD)
+	}
+	if cfg.Timeout != 30*time.Second || !cfg.PreferLocal || !cfg.EnableFallback {
+		t.Errorf("config = %+v", cfg)
+	}
+	if len(cfg.FallbackChain) != 2 || cfg.FallbackChain[0] != llm.ProviderAzure {
+		t.Errorf("FallbackChain = %v", cfg.FallbackChain)
+	}
+	if cfg.Pricing[llm.ProviderClaude] != (llm.Pricing{InputPerMTok: 3, OutputPerMTok: 15}) {
+		t.Errorf("Pricing = %+v", cfg.Pricing)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -84,6 +85,7 @@ type OllamaChatRequest struct {
 	Messages []OllamaChatMessage  `json:"messages"`
 	Stream   bool                 `json:"stream"`
 	Options  *OllamaOptions       `json:"options,omitempty"`
+	Format   *Schema              `json:"format,omitempty"` // Constrains the response to JSON matching the schema
 }
 
 // OllamaChatMessage represents a chat message
@@ -229,6 +231,89 @@ func (c *OllamaClient) ChatWithHistory(ctx context.Context, system string, messa
 	return resp.Message.Content, nil
 }
 
+// ChatCompleteStream sends a chat request with streaming enabled and passes
+// text to onDelta as it is generated. It returns the full text once Ollama
+// reports the response done.
+func (c *OllamaClient) ChatCompleteStream(ctx context.Context, req OllamaChatRequest, onDelta StreamFunc) (*Completion, error) {
+	if req.Model == "" {
+		req.Model = c.model
+	}
+	req.Stream = true
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("Ollama API error %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	// The stream is one JSON object per line
+	var text strings.Builder
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var chunk struct {
+			OllamaChatResponse
+			Error string `json:"error"`
+		}
+		if err := decoder.Decode(&chunk); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err == io.EOF {
+				return nil, fmt.Errorf("stream ended before completion")
+			}
+			return nil, fmt.Errorf("stream failed: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("Ollama error: %s", chunk.Error)
+		}
+		if delta := chunk.Message.Content; delta != "" {
+			text.WriteString(delta)
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		}
+		if chunk.Done {
+			return &Completion{
+				Text:  text.String(),
+				Usage: Usage{InputTokens: chunk.PromptEvalCount, OutputTokens: chunk.EvalCount},
+			}, nil
+		}
+	}
+}
+
+// ChatStream handles multi-turn conversation with a streamed response
+func (c *OllamaClient) ChatStream(ctx context.Context, system string, messages []OllamaChatMessage, onDelta StreamFunc) (string, error) {
+	allMessages := make([]OllamaChatMessage, 0, len(messages)+1)
+	allMessages = append(allMessages, OllamaChatMessage{Role: "system", Content: system})
+	allMessages = append(allMessages, messages...)
+
+	resp, err := c.ChatCompleteStream(ctx, OllamaChatRequest{
+		Model:    c.model,
+		Messages: allMessages,
+	}, onDelta)
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
 // Generate sends a simple generation request
 func (c *OllamaClient) Generate(ctx context.Context, prompt string, options *OllamaOptions) (string, error) {
 	req := OllamaGenerateRequest{
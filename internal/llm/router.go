@@ -3,10 +3,14 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
 )
 
 // Provider represents an LLM provider
@@ -35,11 +39,39 @@ type RouterConfig struct {
 	Ollama *OllamaClient
 
 	// Routing preferences
-	PreferLocal       bool    // Prefer local models when possible
+	PreferLocal         bool    // Prefer local models when possible
 	ComplexityThreshold float64 // Threshold for complexity scoring (0-1)
 
 	// Fallback behavior
-	EnableFallback bool // Enable fallback to other providers
+	EnableFallback bool          // Enable fallback to other providers
+	FallbackChain  []Provider    // Order of providers tried after the first choice (default Claude, Azure, Ollama)
+	Timeout        time.Duration // Per attempt, or until the first token when streaming; 0 for none
+
+	// Cost control
+	Pricing          map[Provider]Pricing // USD per million tokens (default DefaultPricing)
+	DailyBudgetUSD   float64              // Cloud spend per day, 0 for no limit
+	MonthlyBudgetUSD float64              // Cloud spend per calendar month, 0 for no limit
+
+	// Circuit breakers
+	BreakerThreshold int           // Consecutive failures that open a provider's circuit (default 3)
+	BreakerCooldown  time.Duration // How long an open circuit skips the provider (default 30s)
+
+	// Structured output
+	MaxRepairs int // Times an invalid JSON response is sent back for repair (default 2, -1 for none)
+}
+
+// ErrCircuitOpen is returned for a provider that has been failing and is
+// being skipped until its cooldown ends
+var ErrCircuitOpen = errors.New("provider circuit open")
+
+// ErrLocalOnly is returned when sensitive content may only go to a local
+// model and none is configured
+var ErrLocalOnly = errors.New("sensitive content requires a local model")
+
+// CloudAuditLogger records every request sent to a cloud provider.
+// ledger.Recorder implements this interface.
+type CloudAuditLogger interface {
+	RecordCloudLLMCall(provider string, details map[string]interface{}) error
 }
 
 // Router manages routing requests to different LLM providers
@@ -51,32 +83,145 @@ type Router struct {
 	preferLocal         bool
 	complexityThreshold float64
 	enableFallback      bool
+	fallbackChain       []Provider
+	timeout             time.Duration
+	pricing             map[Provider]Pricing
+	maxRepairs          int
 
 	// Stats
-	mu    sync.RWMutex
-	stats RouterStats
+	mu         sync.RWMutex
+	stats      RouterStats
+	providers  map[Provider]*ProviderStats
+	latencies  map[Provider]*latencyWindow
+	breakers   map[Provider]*circuitBreaker
+	budget     budget
+	usageStore UsageStore
+	now        func() time.Time
+
+	// Privacy
+	scrubber *Scrubber
+	audit    CloudAuditLogger
 }
 
 // RouterStats tracks router usage
 type RouterStats struct {
-	ClaudeRequests   int64
-	AzureRequests    int64
-	OllamaRequests   int64
-	FallbackCount    int64
-	TotalTokensUsed  int64
-	AverageLatencyMs int64
+	ClaudeRequests   int64 `json:"claude_requests"`
+	AzureRequests    int64 `json:"azure_requests"`
+	OllamaRequests   int64 `json:"ollama_requests"`
+	FallbackCount    int64 `json:"fallback_count"`
+	TotalTokensUsed  int64 `json:"total_tokens_used"`
+	AverageLatencyMs int64 `json:"average_latency_ms"`
+
+	TotalCostUSD float64                    `json:"total_cost_usd"`
+	Providers    map[Provider]ProviderStats `json:"providers"`
+	Budget       BudgetStatus               `json:"budget"`
+}
+
+// ProviderStats tracks one provider since the router started
+type ProviderStats struct {
+	Requests     int64        `json:"requests"` // Attempts, including failed ones
+	Failures     int64        `json:"failures"`
+	InputTokens  int64        `json:"input_tokens"`
+	OutputTokens int64        `json:"output_tokens"`
+	CostUSD      float64      `json:"cost_usd"`
+	P50LatencyMs int64        `json:"p50_latency_ms"`
+	P95LatencyMs int64        `json:"p95_latency_ms"`
+	Circuit      CircuitState `json:"circuit"`
+
+	// Structured output: responses checked against a schema, and how many
+	// of them failed validation
+	StructuredResponses int64   `json:"structured_responses"`
+	ParseFailures       int64   `json:"parse_failures"`
+	ParseFailureRate    float64 `json:"parse_failure_rate"`
 }
 
 // NewRouter creates a new hybrid AI router
 func NewRouter(cfg RouterConfig) *Router {
-	return &Router{
+	chain := cfg.FallbackChain
+	if len(chain) == 0 {
+		chain = []Provider{ProviderClaude, ProviderAzure, ProviderOllama}
+	}
+	pricing := DefaultPricing()
+	for p, price := range cfg.Pricing {
+		pricing[p] = price
+	}
+	threshold := cfg.BreakerThreshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+	cooldown := cfg.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	maxRepairs := cfg.MaxRepairs
+	if maxRepairs == 0 {
+		maxRepairs = 2
+	} else if maxRepairs < 0 {
+		maxRepairs = 0
+	}
+
+	r := &Router{
 		claude:              cfg.Claude,
 		azure:               cfg.Azure,
 		ollama:              cfg.Ollama,
 		preferLocal:         cfg.PreferLocal,
 		complexityThreshold: cfg.ComplexityThreshold,
 		enableFallback:      cfg.EnableFallback,
+		fallbackChain:       chain,
+		timeout:             cfg.Timeout,
+		pricing:             pricing,
+		maxRepairs:          maxRepairs,
+		providers:           make(map[Provider]*ProviderStats),
+		latencies:           make(map[Provider]*latencyWindow),
+		breakers:            make(map[Provider]*circuitBreaker),
+		budget:              budget{dailyLimit: cfg.DailyBudgetUSD, monthlyLimit: cfg.MonthlyBudgetUSD},
+		now:                 time.Now,
+	}
+	for _, p := range []Provider{ProviderClaude, ProviderAzure, ProviderOllama} {
+		r.providers[p] = &ProviderStats{}
+		r.latencies[p] = &latencyWindow{}
+		r.breakers[p] = newCircuitBreaker(threshold, cooldown)
+	}
+	return r
+}
+
+// SetUsageStore persists usage to store and loads this month's spend from
+// it, so budgets hold across restarts
+func (r *Router) SetUsageStore(store UsageStore) error {
+	now := r.now()
+	spentDay, err := store.SpendSince(dayKey(now))
+	if err != nil {
+		return fmt.Errorf("failed to load LLM spend: %w", err)
 	}
+	spentMonth, err := store.SpendSince(monthKey(now) + "-01")
+	if err != nil {
+		return fmt.Errorf("failed to load LLM spend: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.usageStore = store
+	r.budget.roll(now)
+	r.budget.spentDay = spentDay
+	r.budget.spentMonth = spentMonth
+	return nil
+}
+
+// SetScrubber lets confidential content go to cloud providers once personal
+// data has been replaced with placeholders. Without a scrubber it stays on
+// the local model.
+func (r *Router) SetScrubber(scrubber *Scrubber) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scrubber = scrubber
+}
+
+// SetAuditLogger records every cloud request, with its sensitivity and
+// whether it was redacted, to logger
+func (r *Router) SetAuditLogger(logger CloudAuditLogger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.audit = logger
 }
 
 // RouteRequest represents a request to be routed
@@ -85,26 +230,98 @@ type RouteRequest struct {
 	Prompt      string
 	MaxTokens   int
 	Temperature float64
+	History     []Message // Earlier turns of a conversation, oldest first
 
 	// Routing hints
 	PreferredProvider Provider       // If set, use this provider
 	MinComplexity     TaskComplexity // Minimum complexity level
 	RequireCloud      bool           // Must use cloud provider
+
+	// Sensitivity of the data in the request, usually that of the hats it
+	// draws on. Restricted data only goes to Ollama; confidential data goes
+	// to the cloud only after redaction. Empty is standard.
+	Sensitivity core.Sensitivity
+
+	schema *Schema // Set by RouteJSON
 }
 
 // RouteResponse contains the response and metadata
 type RouteResponse struct {
-	Content      string
-	Provider     Provider
-	LatencyMs    int64
-	TokensUsed   int
-	WasFallback  bool
+	Content     string
+	Provider    Provider
+	LatencyMs   int64
+	TokensUsed  int
+	Usage       Usage
+	CostUSD     float64
+	WasFallback bool
+	Repairs     int // Responses rejected by RouteJSON before this one
 }
 
 // Route sends a request to the appropriate provider
 func (r *Router) Route(ctx context.Context, req RouteRequest) (*RouteResponse, error) {
+	return r.route(ctx, req, nil)
+}
+
+// RouteStream sends a request to the appropriate provider and passes the
+// response to onDelta as it is generated. Fallback only happens before the
+// first delta; once text has reached the caller a failure is returned as is.
+func (r *Router) RouteStream(ctx context.Context, req RouteRequest, onDelta StreamFunc) (*RouteResponse, error) {
+	if onDelta == nil {
+		onDelta = func(string) {}
+	}
+	return r.route(ctx, req, onDelta)
+}
+
+// RouteJSON sends a request whose response must be JSON matching schema,
+// using each provider's native JSON mode. A response that fails validation
+// is sent back to the same provider along with the problems, up to
+// MaxRepairs times. The content of the response is the validated JSON; if
+// no valid response was produced the last one is returned with an error
+// wrapping ErrInvalidOutput.
+func (r *Router) RouteJSON(ctx context.Context, req RouteRequest, schema *Schema) (*RouteResponse, error) {
+	req.schema = schema
+
+	var invalid error
+	for repairs := 0; ; repairs++ {
+		resp, err := r.route(ctx, req, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp.Repairs = repairs
+
+		text := extractJSON(resp.Content)
+		invalid = schema.Validate([]byte(text))
+		r.recordStructured(resp.Provider, invalid == nil)
+		if invalid == nil {
+			resp.Content = text
+			return resp, nil
+		}
+		if repairs == r.maxRepairs {
+			return resp, invalid
+		}
+
+		// Show the model what it said and what is wrong with it
+		req.History = append(append([]Message{}, req.History...),
+			Message{Role: "user", Content: req.Prompt},
+			Message{Role: "assistant", Content: resp.Content},
+		)
+		req.Prompt = fmt.Sprintf("Your response does not match the required schema (%v). Reply with only the corrected JSON.", invalid)
+		req.PreferredProvider = resp.Provider
+	}
+}
+
+func (r *Router) route(ctx context.Context, req RouteRequest, onDelta StreamFunc) (*RouteResponse, error) {
 	start := time.Now()
 
+	// Sensitive content that cannot leave the machine ignores other hints
+	if r.localOnly(req) {
+		if err := r.configured(ProviderOllama); err != nil {
+			return nil, fmt.Errorf("%w: %s data", ErrLocalOnly, req.Sensitivity)
+		}
+		req.PreferredProvider = ProviderOllama
+		req.RequireCloud = false
+	}
+
 	// Determine complexity
 	complexity := r.assessComplexity(req.Prompt)
 	if req.MinComplexity > complexity {
@@ -114,14 +331,33 @@ func (r *Router) Route(ctx context.Context, req RouteRequest) (*RouteResponse, e
 	// Choose provider
 	provider := r.selectProvider(req, complexity)
 
+	// Track whether any text was streamed, since it cannot be taken back
+	streamed := false
+	if onDelta != nil {
+		forward := onDelta
+		onDelta = func(delta string) {
+			streamed = true
+			forward(delta)
+		}
+	}
+
 	// Execute request
-	response, usedProvider, err := r.executeRequest(ctx, req, provider)
+	completion, err := r.attempt(ctx, req, provider, onDelta)
+	usedProvider := provider
 	if err != nil {
-		// Try fallback if enabled
-		if r.enableFallback {
-			response, usedProvider, err = r.executeFallback(ctx, req, provider)
-			if err != nil {
-				return nil, fmt.Errorf("all providers failed: %w", err)
+		if ctx.Err() != nil || streamed {
+			return nil, err
+		}
+		// Try fallback if enabled. Requests over budget always move on, so
+		// they can still be served locally.
+		if r.enableFallback || errors.Is(err, ErrBudgetExceeded) {
+			var fallbackErr error
+			completion, usedProvider, fallbackErr = r.executeFallback(ctx, req, provider, onDelta)
+			if fallbackErr != nil {
+				if ctx.Err() != nil {
+					return nil, fallbackErr
+				}
+				return nil, fmt.Errorf("all providers failed: %w; %w", err, fallbackErr)
 			}
 			r.mu.Lock()
 			r.stats.FallbackCount++
@@ -137,9 +373,12 @@ func (r *Router) Route(ctx context.Context, req RouteRequest) (*RouteResponse, e
 	r.updateStats(usedProvider, latency)
 
 	return &RouteResponse{
-		Content:     response,
+		Content:     completion.Text,
 		Provider:    usedProvider,
 		LatencyMs:   latency,
+		TokensUsed:  completion.Usage.InputTokens + completion.Usage.OutputTokens,
+		Usage:       completion.Usage,
+		CostUSD:     r.pricing[usedProvider].Cost(completion.Usage),
 		WasFallback: usedProvider != provider,
 	}, nil
 }
@@ -251,51 +490,371 @@ func (r *Router) selectProvider(req RouteRequest, complexity TaskComplexity) Pro
 	return ProviderClaude // Default
 }
 
-// executeRequest executes the request with the specified provider
-func (r *Router) executeRequest(ctx context.Context, req RouteRequest, provider Provider) (string, Provider, error) {
+// localOnly reports whether a request must stay on the local model:
+// restricted data always does, and confidential data does when there is no
+// scrubber to redact it
+func (r *Router) localOnly(req RouteRequest) bool {
+	switch req.Sensitivity {
+	case core.SensitivityRestricted:
+		return true
+	case core.SensitivityConfidential:
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return r.scrubber == nil
+	}
+	return false
+}
+
+// attempt sends the request to one provider, subject to the budget and the
+// provider's circuit breaker, and accounts for the outcome. The response is
+// streamed when onDelta is set.
+func (r *Router) attempt(ctx context.Context, req RouteRequest, provider Provider, onDelta StreamFunc) (*Completion, error) {
+	if err := r.configured(provider); err != nil {
+		return nil, err
+	}
+	if isCloud(provider) && r.localOnly(req) {
+		return nil, fmt.Errorf("%w: %s data not sent to %s", ErrLocalOnly, req.Sensitivity, provider)
+	}
+	if err := r.admit(provider); err != nil {
+		return nil, err
+	}
+
+	// Confidential content is redacted before it leaves, and the response
+	// mapped back
+	var redaction *Redaction
+	var flush func()
+	if isCloud(provider) {
+		if req.Sensitivity == core.SensitivityConfidential {
+			r.mu.RLock()
+			redaction = r.scrubber.Begin()
+			r.mu.RUnlock()
+			req = redactRequest(req, redaction)
+			if onDelta != nil {
+				onDelta, flush = redaction.RestoreStream(onDelta)
+			}
+		}
+		r.recordCloudCall(provider, req, redaction)
+	}
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// A stream only has to start within the timeout
+	var timedOut atomic.Bool
+	if r.timeout > 0 {
+		timer := time.AfterFunc(r.timeout, func() {
+			timedOut.Store(true)
+			cancel()
+		})
+		defer timer.Stop()
+		if onDelta != nil {
+			forward := onDelta
+			onDelta = func(delta string) {
+				timer.Stop()
+				forward(delta)
+			}
+		}
+	}
+
+	start := time.Now()
+	completion, err := r.call(attemptCtx, req, provider, onDelta)
+	latency := time.Since(start).Milliseconds()
+
+	switch {
+	case err == nil:
+		r.recordSuccess(provider, req, completion, latency)
+		if redaction != nil && req.schema != nil {
+			completion.Text = redaction.RestoreJSON(completion.Text)
+		} else if redaction != nil {
+			completion.Text = redaction.Restore(completion.Text)
+			if flush != nil {
+				flush()
+			}
+		}
+	case ctx.Err() != nil:
+		// The caller gave up, which says nothing about the provider
+		r.mu.Lock()
+		r.breakers[provider].release()
+		r.mu.Unlock()
+	default:
+		if timedOut.Load() {
+			err = fmt.Errorf("%s timed out after %s: %w", provider, r.timeout, err)
+		}
+		r.recordFailure(provider)
+	}
+	return completion, err
+}
+
+// redactRequest returns a copy of req with personal data replaced by the
+// redaction's placeholders
+func redactRequest(req RouteRequest, redaction *Redaction) RouteRequest {
+	history := make([]Message, len(req.History))
+	for i, m := range req.History {
+		history[i] = Message{Role: m.Role, Content: redaction.Scrub(m.Content)}
+	}
+	req.History = history
+	req.System = redaction.Scrub(req.System)
+	req.Prompt = redaction.Scrub(req.Prompt)
+	if redaction.Count() > 0 {
+		req.System = strings.TrimSpace(req.System + "\n\nSome personal details have been replaced with placeholders such as [NAME_1] or [EMAIL_1]. Keep placeholders exactly as written when you refer to them.")
+	}
+	return req
+}
+
+// recordCloudCall logs a request about to go to a cloud provider. Only the
+// privacy decision is logged, never the content.
+func (r *Router) recordCloudCall(provider Provider, req RouteRequest, redaction *Redaction) {
+	r.mu.RLock()
+	audit := r.audit
+	r.mu.RUnlock()
+	if audit == nil {
+		return
+	}
+
+	sensitivity := req.Sensitivity
+	if sensitivity == "" {
+		sensitivity = core.SensitivityStandard
+	}
+	details := map[string]interface{}{
+		"sensitivity": string(sensitivity),
+		"decision":    "sent",
+	}
+	if redaction != nil {
+		details["decision"] = "redacted"
+		details["redactions"] = redaction.Counts()
+	}
+	if err := audit.RecordCloudLLMCall(string(provider), details); err != nil {
+		fmt.Printf("Warning: failed to record cloud LLM call: %v\n", err)
+	}
+}
+
+// configured reports why a provider cannot take requests, if it cannot
+func (r *Router) configured(provider Provider) error {
 	switch provider {
 	case ProviderClaude:
 		if r.claude == nil || !r.claude.IsConfigured() {
-			return "", provider, fmt.Errorf("Claude not configured")
+			return fmt.Errorf("Claude not configured")
 		}
-		resp, err := r.claude.Chat(ctx, req.System, req.Prompt)
-		return resp, provider, err
-
 	case ProviderAzure:
 		if r.azure == nil || !r.azure.IsConfigured() {
-			return "", provider, fmt.Errorf("Azure OpenAI not configured")
+			return fmt.Errorf("Azure OpenAI not configured")
+		}
+	case ProviderOllama:
+		if r.ollama == nil {
+			return fmt.Errorf("Ollama not configured")
+		}
+	default:
+		return fmt.Errorf("unknown provider: %s", provider)
+	}
+	return nil
+}
+
+// admit checks the budget and the circuit breaker before a request
+func (r *Router) admit(provider Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if isCloud(provider) && r.budget.exceeded(now) {
+		return fmt.Errorf("%w: %s skipped", ErrBudgetExceeded, provider)
+	}
+	if !r.breakers[provider].allow(now) {
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, provider)
+	}
+	return nil
+}
+
+// recordSuccess accounts for a completed request. Usage is estimated when
+// the provider did not report it.
+func (r *Router) recordSuccess(provider Provider, req RouteRequest, completion *Completion, latencyMs int64) {
+	if completion.Usage.InputTokens == 0 && completion.Usage.OutputTokens == 0 {
+		input := len(req.System) + len(req.Prompt)
+		for _, m := range req.History {
+			input += len(m.Content)
+		}
+		completion.Usage = Usage{
+			InputTokens:  estimateTokens(input),
+			OutputTokens: estimateTokens(len(completion.Text)),
+		}
+	}
+	cost := r.pricing[provider].Cost(completion.Usage)
+
+	r.mu.Lock()
+	now := r.now()
+	stats := r.providers[provider]
+	stats.Requests++
+	stats.InputTokens += int64(completion.Usage.InputTokens)
+	stats.OutputTokens += int64(completion.Usage.OutputTokens)
+	stats.CostUSD += cost
+	r.latencies[provider].add(latencyMs)
+	r.breakers[provider].success()
+	r.stats.TotalTokensUsed += int64(completion.Usage.InputTokens + completion.Usage.OutputTokens)
+	r.stats.TotalCostUSD += cost
+	if isCloud(provider) {
+		r.budget.add(now, cost)
+	}
+	store := r.usageStore
+	r.mu.Unlock()
+
+	if store != nil {
+		if err := store.Add(dayKey(now), string(provider), completion.Usage.InputTokens, completion.Usage.OutputTokens, cost, false); err != nil {
+			fmt.Printf("Warning: failed to record LLM usage: %v\n", err)
+		}
+	}
+}
+
+// recordStructured counts a structured response and whether it was valid
+func (r *Router) recordStructured(provider Provider, valid bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := r.providers[provider]
+	stats.StructuredResponses++
+	if !valid {
+		stats.ParseFailures++
+	}
+}
+
+// recordFailure accounts for a failed request
+func (r *Router) recordFailure(provider Provider) {
+	r.mu.Lock()
+	now := r.now()
+	stats := r.providers[provider]
+	stats.Requests++
+	stats.Failures++
+	r.breakers[provider].failure(now)
+	store := r.usageStore
+	r.mu.Unlock()
+
+	if store != nil {
+		if err := store.Add(dayKey(now), string(provider), 0, 0, 0, true); err != nil {
+			fmt.Printf("Warning: failed to record LLM usage: %v\n", err)
 		}
-		resp, err := r.azure.Chat(ctx, req.System, req.Prompt)
-		return resp, provider, err
+	}
+}
+
+// call sends the request to a configured provider, streaming the response
+// when onDelta is set
+func (r *Router) call(ctx context.Context, req RouteRequest, provider Provider, onDelta StreamFunc) (*Completion, error) {
+	messages := append(append([]Message{}, req.History...), Message{Role: "user", Content: req.Prompt})
+
+	switch provider {
+	case ProviderClaude:
+		claudeReq := Request{
+			System:      req.System,
+			Messages:    messages,
+			MaxTokens:   req.MaxTokens,
+			Temperature: req.Temperature,
+		}
+		if onDelta != nil {
+			return r.claude.Stream(ctx, claudeReq, onDelta)
+		}
+		if req.schema != nil {
+			return r.claude.CompleteJSON(ctx, claudeReq, req.schema)
+		}
+		resp, err := r.claude.Complete(ctx, claudeReq)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Content) == 0 {
+			return nil, fmt.Errorf("empty response")
+		}
+		return &Completion{
+			Text:  resp.Content[0].Text,
+			Usage: Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens},
+		}, nil
+
+	case ProviderAzure:
+		azureMessages := make([]AzureMessage, 0, len(messages)+1)
+		azureMessages = append(azureMessages, AzureMessage{Role: "system", Content: req.System})
+		for _, m := range messages {
+			azureMessages = append(azureMessages, AzureMessage{Role: m.Role, Content: m.Content})
+		}
+		azureReq := AzureChatRequest{
+			Messages:    azureMessages,
+			MaxTokens:   req.MaxTokens,
+			Temperature: req.Temperature,
+		}
+		if req.schema != nil {
+			azureReq.ResponseFormat = &AzureResponseFormat{
+				Type:       "json_schema",
+				JSONSchema: &AzureJSONSchema{Name: req.schema.name(), Schema: req.schema},
+			}
+		}
+		if onDelta != nil {
+			return r.azure.Stream(ctx, azureReq, onDelta)
+		}
+		resp, err := r.azure.Complete(ctx, azureReq)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("empty response from Azure OpenAI")
+		}
+		return &Completion{
+			Text:  resp.Choices[0].Message.Content,
+			Usage: Usage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens},
+		}, nil
 
 	case ProviderOllama:
-		if r.ollama == nil || !r.ollama.IsConfigured() {
-			return "", provider, fmt.Errorf("Ollama not configured")
+		ollamaMessages := make([]OllamaChatMessage, 0, len(messages)+1)
+		ollamaMessages = append(ollamaMessages, OllamaChatMessage{Role: "system", Content: req.System})
+		for _, m := range messages {
+			ollamaMessages = append(ollamaMessages, OllamaChatMessage{Role: m.Role, Content: m.Content})
+		}
+		ollamaReq := OllamaChatRequest{Messages: ollamaMessages, Format: req.schema}
+		if req.MaxTokens > 0 || req.Temperature > 0 {
+			ollamaReq.Options = &OllamaOptions{Temperature: req.Temperature, NumPredict: req.MaxTokens}
 		}
-		resp, err := r.ollama.Chat(ctx, req.System, req.Prompt)
-		return resp, provider, err
+		if onDelta != nil {
+			return r.ollama.ChatCompleteStream(ctx, ollamaReq, onDelta)
+		}
+		resp, err := r.ollama.ChatComplete(ctx, ollamaReq)
+		if err != nil {
+			return nil, err
+		}
+		return &Completion{
+			Text:  resp.Message.Content,
+			Usage: Usage{InputTokens: resp.PromptEvalCount, OutputTokens: resp.EvalCount},
+		}, nil
 
 	default:
-		return "", provider, fmt.Errorf("unknown provider: %s", provider)
+		return nil, fmt.Errorf("unknown provider: %s", provider)
 	}
 }
 
-// executeFallback tries other providers when the primary fails
-func (r *Router) executeFallback(ctx context.Context, req RouteRequest, failedProvider Provider) (string, Provider, error) {
-	providers := []Provider{ProviderClaude, ProviderAzure, ProviderOllama}
-
-	for _, p := range providers {
-		if p == failedProvider {
+// executeFallback works through the fallback chain when the first choice
+// fails. Providers without a client, local ones for requests that require
+// the cloud, and cloud ones for content that must stay local are left out.
+func (r *Router) executeFallback(ctx context.Context, req RouteRequest, failedProvider Provider, onDelta StreamFunc) (*Completion, Provider, error) {
+	var lastErr error
+	for _, p := range r.fallbackChain {
+		if p == failedProvider || r.configured(p) != nil || (req.RequireCloud && !isCloud(p)) || (isCloud(p) && r.localOnly(req)) {
 			continue
 		}
 
-		resp, usedProvider, err := r.executeRequest(ctx, req, p)
+		// A provider that fails part way through a stream ends the attempt
+		emit, streamed := onDelta, false
+		if onDelta != nil {
+			emit = func(delta string) {
+				streamed = true
+				onDelta(delta)
+			}
+		}
+
+		completion, err := r.attempt(ctx, req, p, emit)
 		if err == nil {
-			return resp, usedProvider, nil
+			return completion, p, nil
+		}
+		if streamed || ctx.Err() != nil {
+			return nil, p, err
 		}
+		lastErr = err
 	}
 
-	return "", "", fmt.Errorf("all fallback providers failed")
+	if lastErr == nil {
+		return nil, "", fmt.Errorf("no fallback providers configured")
+	}
+	return nil, "", fmt.Errorf("all fallback providers failed: %w", lastErr)
 }
 
 // updateStats updates router statistics
@@ -319,9 +878,24 @@ func (r *Router) updateStats(provider Provider, latencyMs int64) {
 
 // GetStats returns router statistics
 func (r *Router) GetStats() RouterStats {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	return r.stats
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	stats := r.stats
+	stats.Providers = make(map[Provider]ProviderStats, len(r.providers))
+	for p, ps := range r.providers {
+		provider := *ps
+		provider.P50LatencyMs = r.latencies[p].percentile(50)
+		provider.P95LatencyMs = r.latencies[p].percentile(95)
+		provider.Circuit = r.breakers[p].current(now)
+		if provider.StructuredResponses > 0 {
+			provider.ParseFailureRate = float64(provider.ParseFailures) / float64(provider.StructuredResponses)
+		}
+		stats.Providers[p] = provider
+	}
+	stats.Budget = r.budget.status(now)
+	return stats
 }
 
 // HealthCheck checks the health of all configured providers
@@ -3,10 +3,17 @@ package llm
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
 )
 
 // =============================================================================
@@ -354,7 +361,7 @@ func TestRouter_Route_NoFallback(t *testing.T) {
 	}
 }
 
-func TestRouter_executeRequest(t *testing.T) {
+func TestRouter_Route_PreferredProvider(t *testing.T) {
 	claudeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(Response{
@@ -413,42 +420,45 @@ func TestRouter_executeRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.provider), func(t *testing.T) {
-			resp, usedProvider, err := router.executeRequest(context.Background(), RouteRequest{
-				Prompt: "test",
-			}, tt.provider)
+			resp, err := router.Route(context.Background(), RouteRequest{
+				Prompt:            "test",
+				PreferredProvider: tt.provider,
+			})
 			if err != nil {
-				t.Fatalf("executeRequest() error = %v", err)
+				t.Fatalf("Route() error = %v", err)
 			}
-			if resp != tt.wantText {
-				t.Errorf("response = %q, want %q", resp, tt.wantText)
+			if resp.Content != tt.wantText {
+				t.Errorf("Content = %q, want %q", resp.Content, tt.wantText)
 			}
-			if usedProvider != tt.provider {
-				t.Errorf("usedProvider = %v, want %v", usedProvider, tt.provider)
+			if resp.Provider != tt.provider {
+				t.Errorf("Provider = %v, want %v", resp.Provider, tt.provider)
 			}
 		})
 	}
 }
 
-func TestRouter_executeRequest_UnknownProvider(t *testing.T) {
+func TestRouter_Route_UnknownProvider(t *testing.T) {
 	router := NewRouter(RouterConfig{})
 
-	_, _, err := router.executeRequest(context.Background(), RouteRequest{
-		Prompt: "test",
-	}, Provider("unknown"))
+	_, err := router.Route(context.Background(), RouteRequest{
+		Prompt:            "test",
+		PreferredProvider: Provider("unknown"),
+	})
 	if err == nil {
 		t.Error("expected error for unknown provider")
 	}
 }
 
-func TestRouter_executeRequest_NotConfigured(t *testing.T) {
+func TestRouter_Route_NotConfigured(t *testing.T) {
 	router := NewRouter(RouterConfig{})
 
 	tests := []Provider{ProviderClaude, ProviderAzure, ProviderOllama}
 	for _, p := range tests {
 		t.Run(string(p), func(t *testing.T) {
-			_, _, err := router.executeRequest(context.Background(), RouteRequest{
-				Prompt: "test",
-			}, p)
+			_, err := router.RouteStream(context.Background(), RouteRequest{
+				Prompt:            "test",
+				PreferredProvider: p,
+			}, nil)
 			if err == nil {
 				t.Errorf("expected error for unconfigured provider %s", p)
 			}
@@ -588,6 +598,384 @@ func TestRouter_updateStats(t *testing.T) {
 	}
 }
 
+// memoryUsageStore is a UsageStore that keeps usage in memory
+type memoryUsageStore struct {
+	spent float64
+	adds  int
+	fails int
+}
+
+func (s *memoryUsageStore) Add(day, provider string, inputTokens, outputTokens int, costUSD float64, failed bool) error {
+	s.spent += costUSD
+	s.adds++
+	if failed {
+		s.fails++
+	}
+	return nil
+}
+
+func (s *memoryUsageStore) SpendSince(day string) (float64, error) {
+	return s.spent, nil
+}
+
+// claudeUsageServer answers like Claude, reporting token usage
+func claudeUsageServer(t *testing.T, hits *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.Write([]byte(`{"content":[{"type":"text","text":"from claude"}],"usage":{"input_tokens":1000,"output_tokens":500}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// ollamaChatServer answers Ollama chat requests
+func ollamaChatServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaChatResponse{
+			Message: OllamaChatMessage{Role: "assistant", Content: "from ollama"}, Done: true,
+			PromptEvalCount: 20, EvalCount: 10,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRouter_Route_CostAccounting(t *testing.T) {
+	var hits int32
+	store := &memoryUsageStore{spent: 2}
+	router := NewRouter(RouterConfig{
+		Claude:           NewClient(Config{APIKey: "test", BaseURL: claudeUsageServer(t, &hits).URL}),
+		MonthlyBudgetUSD: 10,
+	})
+	if err := router.SetUsageStore(store); err != nil {
+		t.Fatalf("SetUsageStore() error = %v", err)
+	}
+
+	resp, err := router.Route(context.Background(), RouteRequest{Prompt: "hi", PreferredProvider: ProviderClaude})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+
+	// 1000 input tokens at $3/M and 500 output tokens at $15/M
+	const want = 0.0105
+	if resp.TokensUsed != 1500 || math.Abs(resp.CostUSD-want) > 1e-9 {
+		t.Errorf("Route() tokens = %d, cost = %v, want 1500, %v", resp.TokensUsed, resp.CostUSD, want)
+	}
+
+	stats := router.GetStats()
+	claude := stats.Providers[ProviderClaude]
+	if claude.Requests != 1 || claude.InputTokens != 1000 || claude.OutputTokens != 500 || claude.Circuit != CircuitClosed {
+		t.Errorf("Claude stats = %+v", claude)
+	}
+	if math.Abs(stats.Budget.MonthlySpentUSD-(2+want)) > 1e-9 || stats.Budget.Exceeded {
+		t.Errorf("Budget = %+v", stats.Budget)
+	}
+	if store.adds != 1 {
+		t.Errorf("usage recorded %d times, want 1", store.adds)
+	}
+}
+
+func TestRouter_Route_BudgetExceeded(t *testing.T) {
+	var hits int32
+	claudeURL := claudeUsageServer(t, &hits).URL
+
+	// Spent elsewhere this month: cloud requests go to Ollama even without
+	// fallback enabled
+	router := NewRouter(RouterConfig{
+		Claude:           NewClient(Config{APIKey: "test", BaseURL: claudeURL}),
+		Ollama:           NewOllamaClient(OllamaConfig{BaseURL: ollamaChatServer(t).URL}),
+		MonthlyBudgetUSD: 5,
+	})
+	router.SetUsageStore(&memoryUsageStore{spent: 5})
+
+	resp, err := router.Route(context.Background(), RouteRequest{Prompt: "hi", PreferredProvider: ProviderClaude})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if resp.Provider != ProviderOllama || !resp.WasFallback || resp.CostUSD != 0 {
+		t.Errorf("Route() = %+v, want a free Ollama response", resp)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Error("Claude called over budget")
+	}
+
+	// Without a local model the request fails
+	router = NewRouter(RouterConfig{
+		Claude:         NewClient(Config{APIKey: "test", BaseURL: claudeURL}),
+		DailyBudgetUSD: 1,
+	})
+	router.SetUsageStore(&memoryUsageStore{spent: 1})
+	if _, err := router.Route(context.Background(), RouteRequest{Prompt: "hi"}); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Route() error = %v, want ErrBudgetExceeded", err)
+	}
+}
+
+func TestRouter_CircuitBreaker(t *testing.T) {
+	var hits int32
+	var healthy atomic.Bool
+	claudeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":"back"}]}`))
+	}))
+	defer claudeServer.Close()
+
+	router := NewRouter(RouterConfig{
+		Claude:           NewClient(Config{APIKey: "test", BaseURL: claudeServer.URL}),
+		Ollama:           NewOllamaClient(OllamaConfig{BaseURL: ollamaChatServer(t).URL}),
+		EnableFallback:   true,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	})
+	now := time.Now()
+	router.now = func() time.Time { return now }
+	req := RouteRequest{Prompt: "hi", PreferredProvider: ProviderClaude}
+
+	// Two failures open the circuit; the third request skips Claude
+	for i := 0; i < 3; i++ {
+		resp, err := router.Route(context.Background(), req)
+		if err != nil || resp.Provider != ProviderOllama {
+			t.Fatalf("Route() #%d = %+v, %v", i, resp, err)
+		}
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("Claude called %d times, want 2", got)
+	}
+	stats := router.GetStats()
+	if c := stats.Providers[ProviderClaude]; c.Circuit != CircuitOpen || c.Failures != 2 {
+		t.Errorf("Claude stats = %+v, want open after 2 failures", c)
+	}
+
+	// After the cooldown one trial goes through and closes the circuit
+	healthy.Store(true)
+	now = now.Add(time.Minute)
+	resp, err := router.Route(context.Background(), req)
+	if err != nil || resp.Provider != ProviderClaude {
+		t.Fatalf("Route() after cooldown = %+v, %v", resp, err)
+	}
+	if c := router.GetStats().Providers[ProviderClaude]; c.Circuit != CircuitClosed {
+		t.Errorf("Circuit = %s, want closed", c.Circuit)
+	}
+}
+
+func TestRouter_Route_Timeout(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body) // Disconnects are only noticed once the body is read
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	router := NewRouter(RouterConfig{
+		Claude:         NewClient(Config{APIKey: "test", BaseURL: slow.URL}),
+		Ollama:         NewOllamaClient(OllamaConfig{BaseURL: ollamaChatServer(t).URL}),
+		EnableFallback: true,
+		Timeout:        50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	resp, err := router.Route(context.Background(), RouteRequest{Prompt: "hi", PreferredProvider: ProviderClaude})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if resp.Provider != ProviderOllama || time.Since(start) > 2*time.Second {
+		t.Errorf("Route() = %+v after %s, want a quick fallback", resp, time.Since(start))
+	}
+	if f := router.GetStats().Providers[ProviderClaude].Failures; f != 1 {
+		t.Errorf("Claude failures = %d, want 1", f)
+	}
+}
+
+func TestLatencyWindow_Percentile(t *testing.T) {
+	var w latencyWindow
+	if got := w.percentile(95); got != 0 {
+		t.Errorf("empty percentile = %d, want 0", got)
+	}
+	for ms := int64(100); ms >= 1; ms-- {
+		w.add(ms)
+	}
+	if p50, p95 := w.percentile(50), w.percentile(95); p50 != 50 || p95 != 95 {
+		t.Errorf("percentiles = %d, %d, want 50, 95", p50, p95)
+	}
+
+	// Old samples drop out of the window
+	for i := 0; i < latencyWindowSize; i++ {
+		w.add(7)
+	}
+	if got := w.percentile(95); got != 7 {
+		t.Errorf("percentile after refill = %d, want 7", got)
+	}
+}
+
+// auditLog is a CloudAuditLogger that keeps calls in memory
+type auditLog struct {
+	calls []map[string]interface{}
+}
+
+func (a *auditLog) RecordCloudLLMCall(provider string, details map[string]interface{}) error {
+	entry := map[string]interface{}{"provider": provider}
+	for k, v := range details {
+		entry[k] = v
+	}
+	a.calls = append(a.calls, entry)
+	return nil
+}
+
+func TestRouter_Route_RestrictedStaysLocal(t *testing.T) {
+	var hits int32
+	audit := &auditLog{}
+	router := NewRouter(RouterConfig{
+		Claude:         NewClient(Config{APIKey: "test", BaseURL: claudeUsageServer(t, &hits).URL}),
+		Ollama:         NewOllamaClient(OllamaConfig{BaseURL: ollamaChatServer(t).URL}),
+		EnableFallback: true,
+	})
+	router.SetScrubber(NewScrubber(nil))
+	router.SetAuditLogger(audit)
+
+	resp, err := router.Route(context.Background(), RouteRequest{
+		Prompt:            "my diagnosis",
+		MinComplexity:     ComplexityHigh,
+		PreferredProvider: ProviderClaude,
+		RequireCloud:      true,
+		Sensitivity:       core.SensitivityRestricted,
+	})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if resp.Provider != ProviderOllama || atomic.LoadInt32(&hits) != 0 || len(audit.calls) != 0 {
+		t.Errorf("Route() provider = %s, claude hits = %d, audit = %v", resp.Provider, hits, audit.calls)
+	}
+
+	// Without a local model the request is refused rather than sent out
+	router = NewRouter(RouterConfig{
+		Claude: NewClient(Config{APIKey: "test", BaseURL: claudeUsageServer(t, &hits).URL}),
+	})
+	_, err = router.Route(context.Background(), RouteRequest{Prompt: "my diagnosis", Sensitivity: core.SensitivityRestricted})
+	if !errors.Is(err, ErrLocalOnly) || atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("Route() error = %v, claude hits = %d, want ErrLocalOnly", err, hits)
+	}
+}
+
+func TestRouter_Route_ConfidentialWithoutScrubberStaysLocal(t *testing.T) {
+	var hits int32
+	router := NewRouter(RouterConfig{
+		Claude: NewClient(Config{APIKey: "test", BaseURL: claudeUsageServer(t, &hits).URL}),
+		Ollama: NewOllamaClient(OllamaConfig{BaseURL: ollamaChatServer(t).URL}),
+	})
+
+	resp, err := router.Route(context.Background(), RouteRequest{
+		Prompt:        "balance on 12345678",
+		MinComplexity: ComplexityHigh,
+		Sensitivity:   core.SensitivityConfidential,
+	})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if resp.Provider != ProviderOllama || atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("Route() provider = %s, claude hits = %d", resp.Provider, hits)
+	}
+}
+
+func TestRouter_Route_ConfidentialRedacted(t *testing.T) {
+	var received string
+	claudeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		var req Request
+		json.Unmarshal(body, &req)
+		// Echo the placeholders back, as a model would
+		reply := "Reply to " + req.Messages[len(req.Messages)-1].Content
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": reply}},
+		})
+	}))
+	defer claudeServer.Close()
+
+	audit := &auditLog{}
+	router := NewRouter(RouterConfig{Claude: NewClient(Config{APIKey: "test", BaseURL: claudeServer.URL})})
+	router.SetScrubber(NewScrubber(func() []string { return []string{"Dana Whitfield"} }))
+	router.SetAuditLogger(audit)
+
+	prompt := "Dana Whitfield (dana@example.com) paid into 12345678"
+	resp, err := router.Route(context.Background(), RouteRequest{
+		System:        "You help Dana.",
+		Prompt:        prompt,
+		History:       []Message{{Role: "user", Content: "Dana's bank is 87654321"}},
+		MinComplexity: ComplexityHigh,
+		Sensitivity:   core.SensitivityConfidential,
+	})
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+
+	for _, secret := range []string{"Dana", "Whitfield", "dana@example.com", "12345678", "87654321"} {
+		if strings.Contains(received, secret) {
+			t.Errorf("Claude received %q", secret)
+		}
+	}
+	if resp.Content != "Reply to "+prompt {
+		t.Errorf("Route() content = %q, want placeholders restored", resp.Content)
+	}
+
+	if len(audit.calls) != 1 {
+		t.Fatalf("audit calls = %d, want 1", len(audit.calls))
+	}
+	call := audit.calls[0]
+	if call["provider"] != "claude" || call["sensitivity"] != "confidential" || call["decision"] != "redacted" {
+		t.Errorf("audit entry = %v", call)
+	}
+	if counts := call["redactions"].(map[string]int); counts["name"] != 2 || counts["email"] != 1 || counts["account"] != 2 {
+		t.Errorf("redaction counts = %v", counts)
+	}
+}
+
+func TestRouter_RouteStream_ConfidentialRedacted(t *testing.T) {
+	claudeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		writeEvents(w, claudeDelta("Ask [NA"), claudeDelta("ME_1] at [EMAIL_1]"), claudeDelta("."))
+	}))
+	defer claudeServer.Close()
+
+	router := NewRouter(RouterConfig{Claude: NewClient(Config{APIKey: "test", BaseURL: claudeServer.URL})})
+	router.SetScrubber(NewScrubber(func() []string { return []string{"Dana Whitfield"} }))
+
+	var streamed strings.Builder
+	resp, err := router.RouteStream(context.Background(), RouteRequest{
+		Prompt:        "Who is Dana Whitfield? Her address is dana@example.com",
+		MinComplexity: ComplexityHigh,
+		Sensitivity:   core.SensitivityConfidential,
+	}, func(delta string) { streamed.WriteString(delta) })
+	if err != nil {
+		t.Fatalf("RouteStream() error = %v", err)
+	}
+
+	const want = "Ask Dana Whitfield at dana@example.com."
+	if resp.Content != want || streamed.String() != want {
+		t.Errorf("RouteStream() = %q, streamed %q, want %q", resp.Content, streamed.String(), want)
+	}
+}
+
+func TestRouter_Route_StandardAudited(t *testing.T) {
+	var hits int32
+	audit := &auditLog{}
+	router := NewRouter(RouterConfig{Claude: NewClient(Config{APIKey: "test", BaseURL: claudeUsageServer(t, &hits).URL})})
+	router.SetAuditLogger(audit)
+
+	if _, err := router.Route(context.Background(), RouteRequest{Prompt: "hi", MinComplexity: ComplexityHigh}); err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(audit.calls) != 1 || audit.calls[0]["sensitivity"] != "standard" || audit.calls[0]["decision"] != "sent" {
+		t.Errorf("audit = %v", audit.calls)
+	}
+}
+
 // =============================================================================
 // Benchmarks
 // =============================================================================
@@ -801,7 +1189,7 @@ func TestRouter_Route_AllFallbacksFail(t *testing.T) {
 func TestRouter_executeFallback_AllFail(t *testing.T) {
 	router := NewRouter(RouterConfig{})
 
-	_, _, err := router.executeFallback(context.Background(), RouteRequest{Prompt: "test"}, ProviderOllama)
+	_, _, err := router.executeFallback(context.Background(), RouteRequest{Prompt: "test"}, ProviderOllama, nil)
 	if err == nil {
 		t.Error("expected error when all fallbacks fail")
 	}
@@ -0,0 +1,182 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidOutput is returned when a model's response does not match the
+// requested schema, even after repair attempts
+var ErrInvalidOutput = errors.New("model output does not match schema")
+
+// Schema is the subset of JSON Schema used to describe structured output.
+// It is sent to providers as is, so it must stay valid JSON Schema.
+type Schema struct {
+	Title                string             `json:"title,omitempty"` // Names the output in provider requests
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"` // object, array, string, number, integer or boolean
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"` // Schema of map values
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// ObjectSchema returns an object schema with the given properties
+func ObjectSchema(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// StringSchema returns a string schema, limited to enum if any values are given
+func StringSchema(enum ...string) *Schema {
+	return &Schema{Type: "string", Enum: enum}
+}
+
+// NumberSchema returns a number schema between min and max
+func NumberSchema(min, max float64) *Schema {
+	return &Schema{Type: "number", Minimum: &min, Maximum: &max}
+}
+
+// IntegerSchema returns an integer schema between min and max
+func IntegerSchema(min, max float64) *Schema {
+	return &Schema{Type: "integer", Minimum: &min, Maximum: &max}
+}
+
+// ArraySchema returns an array schema of items
+func ArraySchema(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+// name returns the name used for the schema in provider requests
+func (s *Schema) name() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return "response"
+}
+
+// Validate checks that data is JSON matching the schema. All problems are
+// reported, each with the path of the offending value.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("%w: invalid JSON: %v", ErrInvalidOutput, err)
+	}
+	if dec.More() {
+		return fmt.Errorf("%w: unexpected data after JSON value", ErrInvalidOutput)
+	}
+
+	var problems []string
+	s.validate("$", value, &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOutput, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (s *Schema) validate(path string, value interface{}, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, key := range s.Required {
+			if _, ok := obj[key]; !ok {
+				fail("missing required property %q", key)
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if prop, ok := s.Properties[key]; ok {
+				prop.validate(path+"."+key, obj[key], problems)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(path+"."+key, obj[key], problems)
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		if s.Items != nil {
+			for i, v := range arr {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), v, problems)
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			fail("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+
+	case "number", "integer":
+		num, ok := value.(json.Number)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if s.Type == "integer" {
+			if _, err := num.Int64(); err != nil {
+				fail("must be an integer")
+				return
+			}
+		}
+		f, _ := num.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// extractJSON returns the JSON object in a response, without markdown fences
+// or text around it
+func extractJSON(text string) string {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start == -1 || end < start {
+		return strings.TrimSpace(text)
+	}
+	return text[start : end+1]
+}
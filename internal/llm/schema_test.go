@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testSchema() *Schema {
+	return ObjectSchema(map[string]*Schema{
+		"hat_id":     StringSchema("health", "finance"),
+		"confidence": NumberSchema(0, 1),
+		"priority":   IntegerSchema(1, 5),
+		"tags":       ArraySchema(StringSchema()),
+		"kinds":      {Type: "object", AdditionalProperties: StringSchema("person", "place")},
+	}, "hat_id", "confidence")
+}
+
+func TestSchema_Validate(t *testing.T) {
+	schema := testSchema()
+
+	tests := []struct {
+		name     string
+		data     string
+		problems []string
+	}{
+		{"valid", `{"hat_id": "health", "confidence": 0.9, "priority": 2, "tags": ["a"], "kinds": {"Bob": "person"}}`, nil},
+		{"minimal", `{"hat_id": "finance", "confidence": 1}`, nil},
+		{"not json", `hat_id: health`, []string{"invalid JSON"}},
+		{"trailing data", `{"hat_id": "health", "confidence": 0.5} {}`, []string{"unexpected data"}},
+		{"missing required", `{"hat_id": "health"}`, []string{`$: missing required property "confidence"`}},
+		{"wrong enum", `{"hat_id": "travel", "confidence": 0.5}`, []string{"$.hat_id: must be one of health, finance"}},
+		{"out of range", `{"hat_id": "health", "confidence": 1.5, "priority": 0}`, []string{"$.confidence: must be at most 1", "$.priority: must be at least 1"}},
+		{"not integer", `{"hat_id": "health", "confidence": 0.5, "priority": 2.5}`, []string{"$.priority: must be an integer"}},
+		{"nested", `{"hat_id": "health", "confidence": 0.5, "tags": [1], "kinds": {"Bob": "dog"}}`, []string{"$.tags[0]: must be a string", "$.kinds.Bob: must be one of person, place"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate([]byte(tt.data))
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidOutput) {
+				t.Fatalf("Validate() error = %v, want ErrInvalidOutput", err)
+			}
+			for _, p := range tt.problems {
+				if !strings.Contains(err.Error(), p) {
+					t.Errorf("Validate() error = %v, want it to mention %q", err, p)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	got := extractJSON("Here you go:\n```json\n{\"a\": {\"b\": 1}}\n```")
+	if got != `{"a": {"b": 1}}` {
+		t.Errorf("extractJSON() = %q", got)
+	}
+}
+
+func TestRouter_RouteJSON_Repair(t *testing.T) {
+	var requests []OllamaChatRequest
+	replies := []string{
+		`Sure! {"hat_id": "travel", "confidence": 0.7}`,
+		`{"hat_id": "health", "confidence": 0.7}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		json.NewEncoder(w).Encode(OllamaChatResponse{
+			Message: OllamaChatMessage{Role: "assistant", Content: replies[len(requests)-1]}, Done: true,
+		})
+	}))
+	defer server.Close()
+
+	router := NewRouter(RouterConfig{Ollama: NewOllamaClient(OllamaConfig{BaseURL: server.URL})})
+	resp, err := router.RouteJSON(context.Background(), RouteRequest{Prompt: "classify", PreferredProvider: ProviderOllama}, testSchema())
+	if err != nil {
+		t.Fatalf("RouteJSON() error = %v", err)
+	}
+	if resp.Content != replies[1] || resp.Repairs != 1 {
+		t.Errorf("RouteJSON() = %+v", resp)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	if requests[0].Format == nil || requests[0].Format.Properties["hat_id"] == nil {
+		t.Error("schema not sent as the Ollama format")
+	}
+	// The repair request carries the rejected answer and what was wrong
+	repair := requests[1].Messages
+	if len(repair) != 4 || repair[2].Content != replies[0] || !strings.Contains(repair[3].Content, "$.hat_id") {
+		t.Errorf("repair messages = %+v", repair)
+	}
+
+	stats := router.GetStats().Providers[ProviderOllama]
+	if stats.StructuredResponses != 2 || stats.ParseFailures != 1 || stats.ParseFailureRate != 0.5 {
+		t.Errorf("Ollama stats = %+v", stats)
+	}
+}
+
+func TestRouter_RouteJSON_GivesUp(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaChatMessage{Content: "no idea"}, Done: true})
+	}))
+	defer server.Close()
+
+	router := NewRouter(RouterConfig{Ollama: NewOllamaClient(OllamaConfig{BaseURL: server.URL}), MaxRepairs: 1})
+	resp, err := router.RouteJSON(context.Background(), RouteRequest{Prompt: "classify", PreferredProvider: ProviderOllama}, testSchema())
+	if !errors.Is(err, ErrInvalidOutput) {
+		t.Fatalf("RouteJSON() error = %v, want ErrInvalidOutput", err)
+	}
+	if resp == nil || resp.Content != "no idea" || calls != 2 {
+		t.Errorf("RouteJSON() = %+v after %d calls, want the last response after 2", resp, calls)
+	}
+}
+
+func TestRouter_RouteJSON_ClaudeToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Tools) != 1 || req.Tools[0].Name != "classification" || req.ToolChoice == nil || req.ToolChoice.Name != "classification" {
+			t.Errorf("tool not forced: %+v, %+v", req.Tools, req.ToolChoice)
+		}
+		w.Write([]byte(`{"content":[{"type":"tool_use","id":"tu_1","name":"classification","input":{"hat_id":"finance","confidence":0.8}}],
+			"usage":{"input_tokens":10,"output_tokens":5}}`))
+	}))
+	defer server.Close()
+
+	schema := testSchema()
+	schema.Title = "classification"
+	router := NewRouter(RouterConfig{Claude: NewClient(Config{APIKey: "test", BaseURL: server.URL})})
+	resp, err := router.RouteJSON(context.Background(), RouteRequest{Prompt: "classify", MinComplexity: ComplexityHigh}, schema)
+	if err != nil {
+		t.Fatalf("RouteJSON() error = %v", err)
+	}
+	if resp.Content != `{"hat_id":"finance","confidence":0.8}` || resp.TokensUsed != 15 {
+		t.Errorf("RouteJSON() = %+v", resp)
+	}
+}
+
+func TestRouter_RouteJSON_AzureResponseFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req AzureChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ResponseFormat == nil || req.ResponseFormat.Type != "json_schema" || req.ResponseFormat.JSONSchema.Schema == nil {
+			t.Errorf("response_format = %+v", req.ResponseFormat)
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"hat_id\":\"health\",\"confidence\":0.6}"}}]}`))
+	}))
+	defer server.Close()
+
+	router := NewRouter(RouterConfig{Azure: NewAzureClient(AzureConfig{Endpoint: server.URL, APIKey: "test", Deployment: "gpt"})})
+	resp, err := router.RouteJSON(context.Background(), RouteRequest{Prompt: "classify", PreferredProvider: ProviderAzure}, testSchema())
+	if err != nil {
+		t.Fatalf("RouteJSON() error = %v", err)
+	}
+	if resp.Provider != ProviderAzure || resp.Repairs != 0 {
+		t.Errorf("RouteJSON() = %+v", resp)
+	}
+}
@@ -0,0 +1,224 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Placeholder kinds used by the scrubber
+const (
+	placeholderEmail   = "EMAIL"
+	placeholderAccount = "ACCOUNT"
+	placeholderName    = "NAME"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// A display name in front of an address, as in "Jane Doe <jane@example.com>"
+	displayNamePattern = regexp.MustCompile(`"?([A-Z][\p{L}'.\-]*(?:\s+[A-Z][\p{L}'.\-]*)+)"?\s*<[^<>@\s]+@`)
+
+	accountPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,4})?\b`), // IBAN
+		regexp.MustCompile(`\b\d{4}(?:[ \-]\d{4}){2,3}(?:[ \-]\d{1,3})?\b`),               // Card numbers in groups
+		regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),                                       // SSN
+		regexp.MustCompile(`\b\d{8,19}\b`),                                                // Account numbers
+	}
+
+	placeholderPattern = regexp.MustCompile(`\[(?:EMAIL|ACCOUNT|NAME)_\d+\]`)
+)
+
+// maxPlaceholderLen bounds a placeholder, so a streaming restorer knows how
+// much text it may have to hold back
+const maxPlaceholderLen = len("[ACCOUNT_9999]")
+
+// Scrubber replaces emails, account numbers and names with placeholders
+// before text leaves for a cloud model. Each request gets its own
+// Redaction, which maps the placeholders in the response back.
+type Scrubber struct {
+	names func() []string
+}
+
+// NewScrubber creates a scrubber. names returns the names of known people,
+// such as contacts; it is called for every redaction so new contacts are
+// covered. It may be nil.
+func NewScrubber(names func() []string) *Scrubber {
+	return &Scrubber{names: names}
+}
+
+// Begin starts a redaction. Text scrubbed with the same redaction shares
+// placeholders, so a name is the same placeholder in the system prompt and
+// in the conversation.
+func (s *Scrubber) Begin() *Redaction {
+	red := &Redaction{
+		byValue: make(map[string]string),
+		byToken: make(map[string]string),
+		counts:  make(map[string]int),
+	}
+	if s.names == nil {
+		return red
+	}
+
+	// Full names first, then first names on their own
+	seen := make(map[string]bool)
+	var full, first []string
+	for _, name := range s.names() {
+		name = strings.TrimSpace(name)
+		if len(name) < 3 || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		full = append(full, name)
+		if parts := strings.Fields(name); len(parts) > 1 && len(parts[0]) >= 3 {
+			first = append(first, parts[0])
+		}
+	}
+	red.names = namePattern(full, true)
+	red.firstNames = namePattern(first, false)
+	return red
+}
+
+// namePattern matches any of names as whole words, longest first. Single
+// first names are matched case-sensitively so that names which are also
+// common words are only caught when capitalized.
+func namePattern(names []string, ignoreCase bool) *regexp.Regexp {
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = regexp.QuoteMeta(name)
+	}
+	expr := `\b(?:` + strings.Join(quoted, "|") + `)\b`
+	if ignoreCase {
+		expr = `(?i)` + expr
+	}
+	return regexp.MustCompile(expr)
+}
+
+// Redaction holds the placeholders of one request
+type Redaction struct {
+	names      *regexp.Regexp
+	firstNames *regexp.Regexp
+
+	byValue map[string]string // Original text to placeholder
+	byToken map[string]string // Placeholder to original text
+	counts  map[string]int    // Placeholders issued per kind
+}
+
+// Scrub replaces personal data in text with placeholders
+func (r *Redaction) Scrub(text string) string {
+	if text == "" {
+		return text
+	}
+
+	// Display names are only recognizable next to their address
+	for _, m := range displayNamePattern.FindAllStringSubmatch(text, -1) {
+		text = strings.ReplaceAll(text, m[1], r.token(placeholderName, m[1]))
+	}
+
+	text = emailPattern.ReplaceAllStringFunc(text, func(s string) string {
+		return r.token(placeholderEmail, s)
+	})
+	for _, pattern := range accountPatterns {
+		text = pattern.ReplaceAllStringFunc(text, func(s string) string {
+			return r.token(placeholderAccount, s)
+		})
+	}
+	for _, pattern := range []*regexp.Regexp{r.names, r.firstNames} {
+		if pattern != nil {
+			text = pattern.ReplaceAllStringFunc(text, func(s string) string {
+				return r.token(placeholderName, s)
+			})
+		}
+	}
+	return text
+}
+
+// token returns the placeholder for value, issuing a new one the first
+// time value is seen
+func (r *Redaction) token(kind, value string) string {
+	key := kind + ":" + value
+	if kind == placeholderName || kind == placeholderEmail {
+		key = kind + ":" + strings.ToLower(value)
+	}
+	if tok, ok := r.byValue[key]; ok {
+		return tok
+	}
+	r.counts[kind]++
+	tok := fmt.Sprintf("[%s_%d]", kind, r.counts[kind])
+	r.byValue[key] = tok
+	r.byToken[tok] = value
+	return tok
+}
+
+// Restore puts the original text back in place of placeholders
+func (r *Redaction) Restore(text string) string {
+	return r.restore(text, false)
+}
+
+// RestoreJSON is Restore for JSON text. Original values are escaped, so
+// quotes in a name cannot break the strings they are restored into.
+func (r *Redaction) RestoreJSON(text string) string {
+	return r.restore(text, true)
+}
+
+func (r *Redaction) restore(text string, escape bool) string {
+	if len(r.byToken) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(tok string) string {
+		value, ok := r.byToken[tok]
+		if !ok {
+			return tok
+		}
+		if escape {
+			quoted, _ := json.Marshal(value)
+			return string(quoted[1 : len(quoted)-1])
+		}
+		return value
+	})
+}
+
+// Count returns the number of distinct values redacted
+func (r *Redaction) Count() int {
+	return len(r.byToken)
+}
+
+// Counts returns the number of distinct values redacted per kind
+func (r *Redaction) Counts() map[string]int {
+	counts := make(map[string]int, len(r.counts))
+	for kind, n := range r.counts {
+		counts[strings.ToLower(kind)] = n
+	}
+	return counts
+}
+
+// RestoreStream wraps onDelta so placeholders in streamed text are
+// restored. A placeholder split across deltas is held back until it is
+// complete; flush emits whatever is left once the stream ends.
+func (r *Redaction) RestoreStream(onDelta StreamFunc) (stream StreamFunc, flush func()) {
+	var pending string
+	stream = func(delta string) {
+		text := pending + delta
+		cut := len(text)
+		if i := strings.LastIndexByte(text, '['); i >= 0 && !strings.Contains(text[i:], "]") && len(text)-i < maxPlaceholderLen {
+			cut = i
+		}
+		pending = text[cut:]
+		if cut > 0 {
+			onDelta(r.Restore(text[:cut]))
+		}
+	}
+	flush = func() {
+		if pending != "" {
+			onDelta(r.Restore(pending))
+			pending = ""
+		}
+	}
+	return stream, flush
+}
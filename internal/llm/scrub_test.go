@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedaction_ScrubRestore(t *testing.T) {
+	scrubber := NewScrubber(func() []string { return []string{"Alice Johnson", "Bob"} })
+	red := scrubber.Begin()
+
+	original := "Alice Johnson <alice.j@example.com> asked Alice to move £200 from account 12345678 " +
+		"to GB29 NWBK 6016 1331 9268 19 and card 4111 1111 1111 1111. Bob is cc'd at alice.j@example.com."
+	scrubbed := red.Scrub(original)
+
+	for _, secret := range []string{"Alice", "Johnson", "alice.j@example.com", "12345678", "GB29", "4111", "Bob"} {
+		if strings.Contains(scrubbed, secret) {
+			t.Errorf("scrubbed text still contains %q: %s", secret, scrubbed)
+		}
+	}
+	if !strings.Contains(scrubbed, "£200") {
+		t.Errorf("scrubbed too much: %s", scrubbed)
+	}
+	if strings.Count(scrubbed, "[EMAIL_1]") != 2 {
+		t.Errorf("repeated email should share a placeholder: %s", scrubbed)
+	}
+	if got := red.Restore(scrubbed); got != original {
+		t.Errorf("Restore() = %q, want %q", got, original)
+	}
+	if red.Counts()["account"] != 3 {
+		t.Errorf("Counts() = %v, want 3 accounts", red.Counts())
+	}
+}
+
+func TestRedaction_LeavesOrdinaryText(t *testing.T) {
+	red := NewScrubber(func() []string { return []string{"Will Smith"} }).Begin()
+
+	text := "Meeting on 2026-10-18 at 14:30, I will call 3 times. Order #4521."
+	if got := red.Scrub(text); got != text {
+		t.Errorf("Scrub() = %q, want text unchanged", got)
+	}
+	if red.Count() != 0 {
+		t.Errorf("Count() = %d, want 0", red.Count())
+	}
+}
+
+func TestRedaction_RestoreStream(t *testing.T) {
+	red := NewScrubber(nil).Begin()
+	red.Scrub("write to jane@example.com")
+
+	var out strings.Builder
+	stream, flush := red.RestoreStream(func(delta string) { out.WriteString(delta) })
+	for _, delta := range []string{"Sent to [EM", "AIL_1", "] and [", "unknown"} {
+		stream(delta)
+	}
+	if got := out.String(); got != "Sent to jane@example.com and " {
+		t.Errorf("streamed before flush = %q", got)
+	}
+	flush()
+	if got := out.String(); got != "Sent to jane@example.com and [unknown" {
+		t.Errorf("streamed = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// StreamFunc receives response text as it is generated
+type StreamFunc func(delta string)
+
+// maxStreamLine bounds a single line of a streamed response
+const maxStreamLine = 1024 * 1024
+
+// readSSE reads a server-sent event stream, calling fn with the event name
+// and data of each event. Events without a name are reported as "message".
+func readSSE(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+
+	event := ""
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		if event == "" {
+			event = "message"
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event, data = "", nil
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, used as keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return dispatch()
+}
@@ -0,0 +1,307 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeEvents writes server-sent events, flushing after each one
+func writeEvents(w http.ResponseWriter, events ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range events {
+		fmt.Fprint(w, event+"\n\n")
+		w.(http.Flusher).Flush()
+	}
+}
+
+func claudeDelta(text string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":  "content_block_delta",
+		"index": 0,
+		"delta": map[string]string{"type": "text_delta", "text": text},
+	})
+	return "event: content_block_delta\ndata: " + string(data)
+}
+
+func azureDelta(text string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"choices": []map[string]interface{}{{"index": 0, "delta": map[string]string{"content": text}}},
+	})
+	return "data: " + string(data)
+}
+
+func TestReadSSE(t *testing.T) {
+	stream := ": keep-alive\n\nevent: ping\ndata: {}\n\ndata: line one\ndata: line two\n\ndata: last"
+
+	var got []string
+	err := readSSE(strings.NewReader(stream), func(event, data string) error {
+		got = append(got, event+"="+data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readSSE() error = %v", err)
+	}
+
+	want := []string{"ping={}", "message=line one\nline two", "message=last"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("readSSE() events = %q, want %q", got, want)
+	}
+}
+
+func TestClient_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("stream not requested")
+		}
+		writeEvents(w,
+			`event: message_start`+"\n"+`data: {"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[]}}`,
+			`event: content_block_start`+"\n"+`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			claudeDelta("Hello"),
+			`event: ping`+"\n"+`data: {"type":"ping"}`,
+			claudeDelta(", world"),
+			`event: message_delta`+"\n"+`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":4}}`,
+			`event: message_stop`+"\n"+`data: {"type":"message_stop"}`,
+		)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{APIKey: "test-key", BaseURL: server.URL})
+
+	var deltas []string
+	text, err := client.ChatStream(context.Background(), "system", []Message{{Role: "user", Content: "hi"}}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("ChatStream() = %q, want %q", text, "Hello, world")
+	}
+	if len(deltas) != 2 || deltas[0] != "Hello" {
+		t.Errorf("deltas = %q", deltas)
+	}
+}
+
+func TestClient_Stream_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			claudeDelta("Partial"),
+			`event: error`+"\n"+`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+		)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{APIKey: "test-key", BaseURL: server.URL})
+	_, err := client.ChatStream(context.Background(), "system", []Message{{Role: "user", Content: "hi"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("ChatStream() error = %v, want the stream error", err)
+	}
+}
+
+func TestAzureClient_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req AzureChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("stream not requested")
+		}
+		if req.Messages[0].Role != "system" {
+			t.Errorf("first message role = %q, want system", req.Messages[0].Role)
+		}
+		writeEvents(w,
+			`data: {"choices":[],"prompt_filter_results":[]}`,
+			azureDelta("Hi"),
+			azureDelta(" there"),
+			`data: {"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			"data: [DONE]",
+		)
+	}))
+	defer server.Close()
+
+	client := NewAzureClient(AzureConfig{Endpoint: server.URL, APIKey: "test-key", Deployment: "gpt-4"})
+
+	var streamed strings.Builder
+	text, err := client.ChatStream(context.Background(), "system", []AzureMessage{{Role: "user", Content: "hi"}}, func(delta string) {
+		streamed.WriteString(delta)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if text != "Hi there" || streamed.String() != text {
+		t.Errorf("ChatStream() = %q, streamed %q", text, streamed.String())
+	}
+}
+
+func TestOllamaClient_ChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OllamaChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("stream not requested")
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, chunk := range []string{"Local", " reply"} {
+			json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaChatMessage{Role: "assistant", Content: chunk}})
+			w.(http.Flusher).Flush()
+		}
+		json.NewEncoder(w).Encode(OllamaChatResponse{Done: true, EvalCount: 2})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(OllamaConfig{BaseURL: server.URL})
+
+	var deltas []string
+	text, err := client.ChatStream(context.Background(), "system", []OllamaChatMessage{{Role: "user", Content: "hi"}}, func(delta string) {
+		deltas = append(deltas, delta)
+	})
+	if err != nil {
+		t.Fatalf("ChatStream() error = %v", err)
+	}
+	if text != "Local reply" || len(deltas) != 2 {
+		t.Errorf("ChatStream() = %q, deltas %q", text, deltas)
+	}
+}
+
+func TestOllamaClient_ChatStream_Truncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaChatMessage{Content: "cut"}})
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(OllamaConfig{BaseURL: server.URL})
+	if _, err := client.ChatStream(context.Background(), "", nil, nil); err == nil {
+		t.Error("expected error when the stream ends before done")
+	}
+}
+
+func TestClient_Stream_Cancellation(t *testing.T) {
+	released := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, claudeDelta("first"))
+		// Hold the stream open until the client goes away
+		<-r.Context().Done()
+		close(released)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{APIKey: "test-key", BaseURL: server.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err := client.ChatStream(ctx, "", []Message{{Role: "user", Content: "hi"}}, func(string) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ChatStream() error = %v, want context.Canceled", err)
+	}
+
+	select {
+	case <-released:
+	case <-time.After(2 * time.Second):
+		t.Error("provider request was not cancelled")
+	}
+}
+
+func TestRouter_RouteStream(t *testing.T) {
+	claudeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		json.NewDecoder(r.Body).Decode(&req)
+		if len(req.Messages) != 3 || req.Messages[2].Content != "and now?" {
+			t.Errorf("history not sent: %+v", req.Messages)
+		}
+		writeEvents(w, claudeDelta("Streamed"), claudeDelta(" answer"))
+	}))
+	defer claudeServer.Close()
+
+	router := NewRouter(RouterConfig{
+		Claude: NewClient(Config{APIKey: "test", BaseURL: claudeServer.URL}),
+	})
+
+	var streamed strings.Builder
+	resp, err := router.RouteStream(context.Background(), RouteRequest{
+		Prompt:        "and now?",
+		History:       []Message{{Role: "user", Content: "hello"}, {Role: "assistant", Content: "hi"}},
+		MinComplexity: ComplexityHigh,
+	}, func(delta string) { streamed.WriteString(delta) })
+	if err != nil {
+		t.Fatalf("RouteStream() error = %v", err)
+	}
+	if resp.Content != "Streamed answer" || streamed.String() != resp.Content || resp.Provider != ProviderClaude {
+		t.Errorf("RouteStream() = %+v, streamed %q", resp, streamed.String())
+	}
+}
+
+func TestRouter_RouteStream_Fallback(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/tags" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+			return
+		}
+		json.NewEncoder(w).Encode(OllamaChatResponse{Message: OllamaChatMessage{Content: "from ollama"}})
+		json.NewEncoder(w).Encode(OllamaChatResponse{Done: true})
+	}))
+	defer ollamaServer.Close()
+
+	router := NewRouter(RouterConfig{
+		Claude:         NewClient(Config{APIKey: "test", BaseURL: failing.URL}),
+		Ollama:         NewOllamaClient(OllamaConfig{BaseURL: ollamaServer.URL}),
+		EnableFallback: true,
+	})
+
+	var streamed strings.Builder
+	resp, err := router.RouteStream(context.Background(), RouteRequest{
+		Prompt:        "hi",
+		MinComplexity: ComplexityHigh,
+	}, func(delta string) { streamed.WriteString(delta) })
+	if err != nil {
+		t.Fatalf("RouteStream() error = %v", err)
+	}
+	if !resp.WasFallback || resp.Provider != ProviderOllama || streamed.String() != "from ollama" {
+		t.Errorf("RouteStream() = %+v, streamed %q", resp, streamed.String())
+	}
+}
+
+func TestRouter_RouteStream_NoFallbackAfterDelta(t *testing.T) {
+	claudeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w,
+			claudeDelta("Half an"),
+			`event: error`+"\n"+`data: {"type":"error","error":{"type":"api_error","message":"boom"}}`,
+		)
+	}))
+	defer claudeServer.Close()
+
+	ollamaCalled := false
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/chat" {
+			ollamaCalled = true
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+	}))
+	defer ollamaServer.Close()
+
+	router := NewRouter(RouterConfig{
+		Claude:         NewClient(Config{APIKey: "test", BaseURL: claudeServer.URL}),
+		Ollama:         NewOllamaClient(OllamaConfig{BaseURL: ollamaServer.URL}),
+		EnableFallback: true,
+	})
+
+	_, err := router.RouteStream(context.Background(), RouteRequest{Prompt: "hi", MinComplexity: ComplexityHigh}, func(string) {})
+	if err == nil {
+		t.Fatal("expected the mid-stream error")
+	}
+	if ollamaCalled {
+		t.Error("fell back after text was streamed")
+	}
+}
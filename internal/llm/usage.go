@@ -0,0 +1,150 @@
+package llm
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrBudgetExceeded is returned when a request could only go to a cloud
+// provider and the daily or monthly budget is spent
+var ErrBudgetExceeded = errors.New("LLM budget exceeded")
+
+// Usage counts the tokens of one request
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Completion is a provider's response text with its token usage
+type Completion struct {
+	Text  string
+	Usage Usage
+}
+
+// Pricing is what a provider charges, in USD per million tokens
+type Pricing struct {
+	InputPerMTok  float64 `json:"input_per_mtok"`
+	OutputPerMTok float64 `json:"output_per_mtok"`
+}
+
+// Cost returns the USD cost of usage
+func (p Pricing) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*p.InputPerMTok + float64(u.OutputTokens)*p.OutputPerMTok) / 1e6
+}
+
+// DefaultPricing returns list prices for the default models. Local models
+// cost nothing.
+func DefaultPricing() map[Provider]Pricing {
+	return map[Provider]Pricing{
+		ProviderClaude: {InputPerMTok: 3, OutputPerMTok: 15},
+		ProviderAzure:  {InputPerMTok: 2.5, OutputPerMTok: 10},
+		ProviderOllama: {},
+	}
+}
+
+// UsageStore persists daily usage so spend survives restarts
+type UsageStore interface {
+	Add(day, provider string, inputTokens, outputTokens int, costUSD float64, failed bool) error
+	SpendSince(day string) (float64, error)
+}
+
+// estimateTokens approximates the tokens in chars characters of text, for
+// providers that do not report usage
+func estimateTokens(chars int) int {
+	return (chars + 3) / 4
+}
+
+// isCloud reports whether a provider is billed
+func isCloud(p Provider) bool {
+	return p == ProviderClaude || p == ProviderAzure
+}
+
+// latencyWindow keeps the most recent latencies of a provider
+type latencyWindow struct {
+	samples []int64
+	next    int
+}
+
+const latencyWindowSize = 200
+
+func (w *latencyWindow) add(ms int64) {
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, ms)
+		return
+	}
+	w.samples[w.next] = ms
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+// percentile returns the latency below which p percent of samples fall
+func (w *latencyWindow) percentile(p float64) int64 {
+	if len(w.samples) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(float64(len(sorted))*p/100+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// budget tracks cloud spend against daily and monthly limits
+type budget struct {
+	dailyLimit   float64 // USD, 0 for no limit
+	monthlyLimit float64
+
+	day        string
+	month      string
+	spentDay   float64
+	spentMonth float64
+}
+
+func dayKey(t time.Time) string   { return t.Format("2006-01-02") }
+func monthKey(t time.Time) string { return t.Format("2006-01") }
+
+// roll resets the running totals when the day or month changes
+func (b *budget) roll(now time.Time) {
+	if d := dayKey(now); d != b.day {
+		b.day, b.spentDay = d, 0
+	}
+	if m := monthKey(now); m != b.month {
+		b.month, b.spentMonth = m, 0
+	}
+}
+
+func (b *budget) add(now time.Time, cost float64) {
+	b.roll(now)
+	b.spentDay += cost
+	b.spentMonth += cost
+}
+
+func (b *budget) exceeded(now time.Time) bool {
+	b.roll(now)
+	return (b.dailyLimit > 0 && b.spentDay >= b.dailyLimit) ||
+		(b.monthlyLimit > 0 && b.spentMonth >= b.monthlyLimit)
+}
+
+// BudgetStatus reports cloud spend against the configured limits
+type BudgetStatus struct {
+	DailyLimitUSD   float64 `json:"daily_limit_usd"`
+	DailySpentUSD   float64 `json:"daily_spent_usd"`
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+	MonthlySpentUSD float64 `json:"monthly_spent_usd"`
+	Exceeded        bool    `json:"exceeded"`
+}
+
+func (b *budget) status(now time.Time) BudgetStatus {
+	return BudgetStatus{
+		DailyLimitUSD:   b.dailyLimit,
+		DailySpentUSD:   b.spentDay,
+		MonthlyLimitUSD: b.monthlyLimit,
+		MonthlySpentUSD: b.spentMonth,
+		Exceeded:        b.exceeded(now),
+	}
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheConfig enables TTL caching of read-only tool results and resources.
+// Caching is opt-in: only tools listed in ToolTTLs are cached.
+type CacheConfig struct {
+	// ToolTTLs maps tool names to cache lifetimes. The key "*" applies to
+	// every tool the server advertises as read-only.
+	ToolTTLs map[string]time.Duration
+
+	// ResourceTTL caches resources/read results when non-zero.
+	ResourceTTL time.Duration
+
+	// MaxEntries bounds the cache size (default 1000). Expired entries are
+	// evicted first, then the entries closest to expiry.
+	MaxEntries int
+}
+
+// CacheStats reports cache effectiveness
+type CacheStats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Coalesced     int64 `json:"coalesced"`
+	Invalidations int64 `json:"invalidations"`
+	Entries       int   `json:"entries"`
+}
+
+// HitRate returns the fraction of lookups served from cache or a shared in-flight call
+func (s CacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses + s.Coalesced
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits+s.Coalesced) / float64(total)
+}
+
+// resultCache caches tool and resource results per server and coalesces
+// identical in-flight requests
+type resultCache struct {
+	cfg         CacheConfig
+	entries     map[string]*cacheEntry
+	inflight    map[string]*inflightCall
+	generations map[string]uint64 // per server, bumped on invalidation
+	timeout     time.Duration     // bounds a shared call once its callers stop waiting
+	now         func() time.Time
+	mu          sync.Mutex
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	coalesced     atomic.Int64
+	invalidations atomic.Int64
+}
+
+type cacheEntry struct {
+	serverID  string
+	value     any
+	expiresAt time.Time
+}
+
+type inflightCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+func newResultCache(cfg CacheConfig, timeout time.Duration) *resultCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 1000
+	}
+	return &resultCache{
+		cfg:         cfg,
+		entries:     make(map[string]*cacheEntry),
+		inflight:    make(map[string]*inflightCall),
+		generations: make(map[string]uint64),
+		timeout:     timeout,
+		now:         time.Now,
+	}
+}
+
+// toolTTL returns how long results of a tool may be cached (0 = not cacheable)
+func (c *resultCache) toolTTL(name string, readOnly bool) time.Duration {
+	if ttl, ok := c.cfg.ToolTTLs[name]; ok {
+		return ttl
+	}
+	if readOnly {
+		return c.cfg.ToolTTLs["*"]
+	}
+	return 0
+}
+
+// toolKey builds a cache key from the server, tool and normalized arguments.
+// encoding/json sorts map keys, so equal argument maps produce equal keys.
+func toolKey(serverID, tool string, args map[string]interface{}) string {
+	normalized, err := json.Marshal(args)
+	if err != nil || len(args) == 0 {
+		normalized = []byte("{}")
+	}
+	return serverID + "\x00tool\x00" + tool + "\x00" + string(normalized)
+}
+
+// resourceKey builds a cache key for a resource URI
+func resourceKey(serverID, uri string) string {
+	return serverID + "\x00resource\x00" + uri
+}
+
+// do returns a cached value for key, joins an identical in-flight call, or
+// starts fn. Successful results are cached for ttl unless store rejects them or
+// the server was invalidated while fn was running.
+//
+// fn runs detached from any one caller, so a caller that gives up doesn't
+// fail the others; each caller stops waiting when its own ctx is done.
+func (c *resultCache) do(ctx context.Context, serverID, key string, ttl time.Duration, fn func(context.Context) (any, error), store func(any) bool) (any, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		if c.now().Before(entry.expiresAt) {
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return entry.value, nil
+		}
+		delete(c.entries, key)
+	}
+	call, ok := c.inflight[key]
+	if ok {
+		c.mu.Unlock()
+		c.coalesced.Add(1)
+	} else {
+		call = &inflightCall{done: make(chan struct{})}
+		c.inflight[key] = call
+		generation := c.generations[serverID]
+		c.mu.Unlock()
+		c.misses.Add(1)
+
+		go c.run(ctx, serverID, key, ttl, generation, call, fn, store)
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run executes a shared call and caches its result
+func (c *resultCache) run(ctx context.Context, serverID, key string, ttl time.Duration, generation uint64, call *inflightCall, fn func(context.Context) (any, error), store func(any) bool) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+
+	call.value, call.err = fn(ctx)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil && store(call.value) && c.generations[serverID] == generation {
+		c.evictLocked()
+		c.entries[key] = &cacheEntry{
+			serverID:  serverID,
+			value:     call.value,
+			expiresAt: c.now().Add(ttl),
+		}
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// evictLocked makes room for one new entry
+func (c *resultCache) evictLocked() {
+	if len(c.entries) < c.cfg.MaxEntries {
+		return
+	}
+
+	now := c.now()
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= c.cfg.MaxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// invalidateServer drops every cached result for a server
+func (c *resultCache) invalidateServer(serverID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generations[serverID]++
+	for key, entry := range c.entries {
+		if entry.serverID == serverID {
+			delete(c.entries, key)
+		}
+	}
+	c.invalidations.Add(1)
+}
+
+func (c *resultCache) stats() CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Coalesced:     c.coalesced.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       entries,
+	}
+}
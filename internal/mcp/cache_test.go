@@ -0,0 +1,214 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/mcp/server"
+)
+
+// newCachingTestClient starts an MCP server with a slow read tool and a write
+// tool, and returns a connected client plus the read tool's call counter
+func newCachingTestClient(t *testing.T, cache *CacheConfig) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := server.New(server.Config{Name: "calendar"})
+	srv.RegisterTool(
+		server.NewTool("calendar.today").Access(server.AccessRead).Build(),
+		server.WrapHandler(func(ctx context.Context, args *server.Args) (string, error) {
+			calls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			return "3 events", nil
+		}),
+	)
+	srv.RegisterTool(
+		server.NewTool("calendar.create_event").Access(server.AccessWrite).Build(),
+		server.WrapHandler(func(ctx context.Context, args *server.Args) (string, error) {
+			return "created", nil
+		}),
+	)
+
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+
+	client := NewClient(Config{Timeout: 5 * time.Second, Cache: cache})
+	client.RegisterServer(&Server{ID: "calendar", URL: ts.URL, Protocol: "http"})
+	if err := client.Connect(context.Background(), "calendar"); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	return client, &calls
+}
+
+func TestClient_CallTool_CachesReadTools(t *testing.T) {
+	client, calls := newCachingTestClient(t, &CacheConfig{
+		ToolTTLs: map[string]time.Duration{"*": time.Minute},
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today"})
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		if resp.Content[0].Text != "3 events" {
+			t.Errorf("unexpected content %q", resp.Content[0].Text)
+		}
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 upstream call, got %d", got)
+	}
+	stats := client.CacheStats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("expected 2 hits / 1 miss, got %+v", stats)
+	}
+}
+
+func TestClient_CallTool_NormalizesArguments(t *testing.T) {
+	client, calls := newCachingTestClient(t, &CacheConfig{
+		ToolTTLs: map[string]time.Duration{"calendar.today": time.Minute},
+	})
+	ctx := context.Background()
+
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today", Arguments: map[string]interface{}{"a": 1, "b": "x"}})
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today", Arguments: map[string]interface{}{"b": "x", "a": 1.0}})
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today", Arguments: map[string]interface{}{"a": 2}})
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 upstream calls, got %d", got)
+	}
+}
+
+func TestClient_CallTool_CoalescesInFlight(t *testing.T) {
+	client, calls := newCachingTestClient(t, &CacheConfig{
+		ToolTTLs: map[string]time.Duration{"*": time.Minute},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.CallTool(context.Background(), "calendar", ToolCallRequest{Name: "calendar.today"}); err != nil {
+				t.Errorf("CallTool failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 upstream call, got %d", got)
+	}
+	stats := client.CacheStats()
+	if stats.Misses != 1 || stats.Hits+stats.Coalesced != 4 {
+		t.Errorf("expected 1 miss and 4 shared results, got %+v", stats)
+	}
+}
+
+func TestClient_CallTool_CoalescedCallerCancels(t *testing.T) {
+	client, calls := newCachingTestClient(t, &CacheConfig{
+		ToolTTLs: map[string]time.Duration{"*": time.Minute},
+	})
+
+	// The tool takes 20ms; one caller gives up after 5ms
+	impatient, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var impatientErr, patientErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, impatientErr = client.CallTool(impatient, "calendar", ToolCallRequest{Name: "calendar.today"})
+	}()
+	go func() {
+		defer wg.Done()
+		_, patientErr = client.CallTool(context.Background(), "calendar", ToolCallRequest{Name: "calendar.today"})
+	}()
+	wg.Wait()
+
+	if !errors.Is(impatientErr, context.DeadlineExceeded) {
+		t.Errorf("expected the impatient caller to time out, got %v", impatientErr)
+	}
+	if patientErr != nil {
+		t.Errorf("expected the other caller to get the result, got %v", patientErr)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected 1 upstream call, got %d", got)
+	}
+}
+
+func TestClient_CallTool_WriteInvalidates(t *testing.T) {
+	client, calls := newCachingTestClient(t, &CacheConfig{
+		ToolTTLs: map[string]time.Duration{"*": time.Minute},
+	})
+	ctx := context.Background()
+
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today"})
+	if _, err := client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.create_event"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today"})
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected cache to be invalidated by write, got %d upstream calls", got)
+	}
+	if stats := client.CacheStats(); stats.Invalidations != 1 {
+		t.Errorf("expected 1 invalidation, got %d", stats.Invalidations)
+	}
+}
+
+func TestClient_UnregisterServer_DropsCache(t *testing.T) {
+	client, _ := newCachingTestClient(t, &CacheConfig{
+		ToolTTLs: map[string]time.Duration{"*": time.Minute},
+	})
+
+	client.CallTool(context.Background(), "calendar", ToolCallRequest{Name: "calendar.today"})
+	client.UnregisterServer("calendar")
+
+	// The lock is released, so later calls don't block
+	if _, ok := client.GetServer("calendar"); ok {
+		t.Error("expected server to be removed")
+	}
+	if stats := client.CacheStats(); stats.Entries != 0 || stats.Invalidations != 1 {
+		t.Errorf("expected the server's results dropped, got %+v", stats)
+	}
+}
+
+func TestClient_CallTool_TTLExpiry(t *testing.T) {
+	client, calls := newCachingTestClient(t, &CacheConfig{
+		ToolTTLs: map[string]time.Duration{"*": time.Minute},
+	})
+	now := time.Now()
+	client.cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today"})
+	now = now.Add(2 * time.Minute)
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today"})
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected expired entry to be refetched, got %d upstream calls", got)
+	}
+}
+
+func TestClient_CallTool_CacheDisabledByDefault(t *testing.T) {
+	client, calls := newCachingTestClient(t, nil)
+	ctx := context.Background()
+
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today"})
+	client.CallTool(ctx, "calendar", ToolCallRequest{Name: "calendar.today"})
+
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected 2 upstream calls without cache, got %d", got)
+	}
+	if stats := client.CacheStats(); stats != (CacheStats{}) {
+		t.Errorf("expected zero stats, got %+v", stats)
+	}
+}
@@ -17,6 +17,7 @@ import (
 type Client struct {
 	httpClient *http.Client
 	servers    map[string]*Server
+	cache      *resultCache // nil unless caching is enabled
 	mu         sync.RWMutex
 }
 
@@ -45,6 +46,7 @@ type Tool struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	InputSchema map[string]interface{} `json:"inputSchema"`
+	ReadOnly    bool                   `json:"readOnly"` // from annotations.readOnlyHint
 }
 
 // Resource represents an MCP resource
@@ -103,6 +105,10 @@ type ContentBlock struct {
 // Config for MCP client
 type Config struct {
 	Timeout time.Duration
+
+	// Cache enables TTL caching and request coalescing of read-only tool
+	// calls and resource reads. Nil disables caching.
+	Cache *CacheConfig
 }
 
 // DefaultConfig returns default MCP client config
@@ -118,10 +124,29 @@ func NewClient(cfg Config) *Client {
 		cfg.Timeout = 30 * time.Second
 	}
 
-	return &Client{
+	c := &Client{
 		httpClient: &http.Client{Timeout: cfg.Timeout},
 		servers:    make(map[string]*Server),
 	}
+	if cfg.Cache != nil {
+		c.cache = newResultCache(*cfg.Cache, cfg.Timeout)
+	}
+	return c
+}
+
+// CacheStats returns cache hit/miss metrics (zero if caching is disabled)
+func (c *Client) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.stats()
+}
+
+// InvalidateCache drops all cached results for a server
+func (c *Client) InvalidateCache(serverID string) {
+	if c.cache != nil {
+		c.cache.invalidateServer(serverID)
+	}
 }
 
 // RegisterServer registers an MCP server
@@ -137,11 +162,12 @@ func (c *Client) RegisterServer(server *Server) error {
 	return nil
 }
 
-// UnregisterServer removes an MCP server
+// UnregisterServer removes an MCP server and its cached results
 func (c *Client) UnregisterServer(serverID string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	delete(c.servers, serverID)
+	c.InvalidateCache(serverID)
 }
 
 // GetServer returns a registered server
@@ -263,6 +289,9 @@ func (c *Client) ListTools(ctx context.Context, serverID string) ([]Tool, error)
 			if schema, ok := toolMap["inputSchema"].(map[string]interface{}); ok {
 				tool.InputSchema = schema
 			}
+			if annotations, ok := toolMap["annotations"].(map[string]interface{}); ok {
+				tool.ReadOnly = getBool(annotations, "readOnlyHint")
+			}
 			tools = append(tools, tool)
 		}
 	}
@@ -270,16 +299,59 @@ func (c *Client) ListTools(ctx context.Context, serverID string) ([]Tool, error)
 	return tools, nil
 }
 
-// CallTool executes a tool on an MCP server
+// CallTool executes a tool on an MCP server.
+// With caching enabled, results of cacheable tools are served from cache and
+// identical concurrent calls share one request. A successful call to any
+// other tool on the server invalidates that server's cache.
 func (c *Client) CallTool(ctx context.Context, serverID string, req ToolCallRequest) (*ToolCallResponse, error) {
 	c.mu.RLock()
 	server, ok := c.servers[serverID]
+	readOnly := false
+	if ok {
+		for _, tool := range server.Tools {
+			if tool.Name == req.Name {
+				readOnly = tool.ReadOnly
+				break
+			}
+		}
+	}
 	c.mu.RUnlock()
 
 	if !ok {
 		return nil, fmt.Errorf("server %s not found", serverID)
 	}
 
+	if c.cache == nil {
+		return c.callTool(ctx, server, req)
+	}
+
+	ttl := c.cache.toolTTL(req.Name, readOnly)
+	if ttl <= 0 {
+		resp, err := c.callTool(ctx, server, req)
+		if err == nil && !resp.IsError && !readOnly {
+			c.cache.invalidateServer(serverID)
+		}
+		return resp, err
+	}
+
+	value, err := c.cache.do(ctx, serverID, toolKey(serverID, req.Name, req.Arguments), ttl,
+		func(ctx context.Context) (any, error) { return c.callTool(ctx, server, req) },
+		func(v any) bool { return !v.(*ToolCallResponse).IsError },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers get their own copy so cached content cannot be mutated
+	shared := value.(*ToolCallResponse)
+	return &ToolCallResponse{
+		Content: append([]ContentBlock(nil), shared.Content...),
+		IsError: shared.IsError,
+	}, nil
+}
+
+// callTool sends a tools/call request without caching
+func (c *Client) callTool(ctx context.Context, server *Server, req ToolCallRequest) (*ToolCallResponse, error) {
 	resp, err := c.sendRequest(ctx, server, "tools/call", map[string]interface{}{
 		"name":      req.Name,
 		"arguments": req.Arguments,
@@ -362,7 +434,8 @@ func (c *Client) ListResources(ctx context.Context, serverID string) ([]Resource
 	return resources, nil
 }
 
-// ReadResource reads a resource from an MCP server
+// ReadResource reads a resource from an MCP server, using the cache when
+// ResourceTTL is configured
 func (c *Client) ReadResource(ctx context.Context, serverID, uri string) ([]ContentBlock, error) {
 	c.mu.RLock()
 	server, ok := c.servers[serverID]
@@ -372,6 +445,22 @@ func (c *Client) ReadResource(ctx context.Context, serverID, uri string) ([]Cont
 		return nil, fmt.Errorf("server %s not found", serverID)
 	}
 
+	if c.cache == nil || c.cache.cfg.ResourceTTL <= 0 {
+		return c.readResource(ctx, server, uri)
+	}
+
+	value, err := c.cache.do(ctx, serverID, resourceKey(serverID, uri), c.cache.cfg.ResourceTTL,
+		func(ctx context.Context) (any, error) { return c.readResource(ctx, server, uri) },
+		func(any) bool { return true },
+	)
+	if err != nil {
+		return nil, err
+	}
+	return append([]ContentBlock(nil), value.([]ContentBlock)...), nil
+}
+
+// readResource sends a resources/read request without caching
+func (c *Client) readResource(ctx context.Context, server *Server, uri string) ([]ContentBlock, error) {
 	resp, err := c.sendRequest(ctx, server, "resources/read", map[string]string{
 		"uri": uri,
 	})
@@ -12,6 +12,7 @@ type ToolBuilder struct {
 	description string
 	properties  map[string]Property
 	required    []string
+	access      ToolAccess
 }
 
 // NewTool creates a new tool builder
@@ -28,6 +29,12 @@ func (b *ToolBuilder) Description(desc string) *ToolBuilder {
 	return b
 }
 
+// Access sets the tool's access class (read, write or sensitive)
+func (b *ToolBuilder) Access(access ToolAccess) *ToolBuilder {
+	b.access = access
+	return b
+}
+
 // String adds a string parameter
 func (b *ToolBuilder) String(name, description string, required bool) *ToolBuilder {
 	b.properties[name] = Property{Type: "string", Description: description}
@@ -75,6 +82,11 @@ func (b *ToolBuilder) Enum(name, description string, choices []string, required
 
 // Build creates the Tool definition
 func (b *ToolBuilder) Build() Tool {
+	var annotations *ToolAnnotations
+	if b.access == AccessRead {
+		annotations = &ToolAnnotations{ReadOnlyHint: true}
+	}
+
 	return Tool{
 		Name:        b.name,
 		Description: b.description,
@@ -83,6 +95,8 @@ func (b *ToolBuilder) Build() Tool {
 			Properties: b.properties,
 			Required:   b.required,
 		},
+		Annotations: annotations,
+		Access:      b.access,
 	}
 }
 
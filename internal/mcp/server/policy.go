@@ -0,0 +1,431 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ToolAccess classifies what a tool is allowed to do
+type ToolAccess string
+
+const (
+	// AccessRead tools only read data from a space
+	AccessRead ToolAccess = "read"
+	// AccessWrite tools modify data or act on the user's behalf
+	AccessWrite ToolAccess = "write"
+	// AccessSensitive tools expose or modify highly sensitive data (finance, credentials)
+	AccessSensitive ToolAccess = "sensitive"
+)
+
+// level orders access classes so a client's ceiling can be compared to a tool.
+// Untagged tools are treated as write tools so new tools fail closed.
+func (a ToolAccess) level() int {
+	switch a {
+	case AccessRead:
+		return 1
+	case AccessSensitive:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// Audited reports whether invocations of this access class are written to the audit log
+func (a ToolAccess) Audited() bool {
+	return a.level() >= AccessWrite.level()
+}
+
+// Policy errors
+var (
+	ErrUnauthenticated  = errors.New("mcp: missing or invalid client credentials")
+	ErrForbidden        = errors.New("mcp: tool not permitted for client")
+	ErrRateLimited      = errors.New("mcp: rate limit exceeded")
+	ErrToolNotFound     = errors.New("mcp: unknown tool")
+	ErrResourceNotFound = errors.New("mcp: unknown resource")
+)
+
+// Policy error codes (JSON-RPC server-defined range)
+const (
+	ErrCodeUnauthenticated = -32001
+	ErrCodeForbidden       = -32003
+	ErrCodeRateLimited     = -32029
+)
+
+// ClientPolicy describes a client allowed to call tools
+type ClientPolicy struct {
+	ID string `json:"id"`
+	// Token is the bearer token presented by HTTP callers. Clients without a
+	// token can only be used by in-process callers via WithClientID.
+	Token string `json:"-"`
+	// AllowedTools is an allowlist of tool names, resource scopes
+	// ("finance.report" for finance://report) or glob patterns ("gmail.*").
+	// An empty allowlist permits every tool and resource up to MaxAccess.
+	AllowedTools []string `json:"allowed_tools,omitempty"`
+	// MaxAccess is the highest tool access class the client may invoke.
+	// Defaults to AccessRead.
+	MaxAccess ToolAccess `json:"max_access"`
+}
+
+// allows reports whether the client may call the given tool
+func (c *ClientPolicy) allows(tool string, access ToolAccess) bool {
+	maxAccess := c.MaxAccess
+	if maxAccess == "" {
+		maxAccess = AccessRead
+	}
+	if access.level() > maxAccess.level() {
+		return false
+	}
+	if len(c.AllowedTools) == 0 {
+		return true
+	}
+	for _, pattern := range c.AllowedTools {
+		if pattern == tool {
+			return true
+		}
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimit caps the number of calls in a window, per client and tool
+type RateLimit struct {
+	Requests int           `json:"requests"`
+	Window   time.Duration `json:"window"`
+}
+
+// AuditLogger records invocations of write and sensitive tools.
+// ledger.Recorder implements this interface.
+type AuditLogger interface {
+	RecordToolInvocation(actor, tool string, details map[string]interface{}) error
+}
+
+// Policy enforces client authentication, tool allowlists and rate limits
+type Policy struct {
+	clients     map[string]*ClientPolicy
+	tokens      map[string]string // token -> client ID
+	toolAccess  map[string]ToolAccess
+	toolLimits  map[string]RateLimit
+	limitByTier map[ToolAccess]RateLimit
+	windows     map[string]*rateWindow
+	lastPrune   time.Time
+	audit       AuditLogger
+	now         func() time.Time
+	mu          sync.Mutex
+}
+
+// windowPruneInterval is how often expired rate windows are swept
+const windowPruneInterval = time.Minute
+
+type rateWindow struct {
+	start  time.Time
+	length time.Duration
+	count  int
+}
+
+func (w *rateWindow) expired(now time.Time) bool {
+	return now.Sub(w.start) >= w.length
+}
+
+// NewPolicy creates an empty policy. With no clients configured every
+// request is rejected, so callers should add at least one client.
+func NewPolicy() *Policy {
+	return &Policy{
+		clients:     make(map[string]*ClientPolicy),
+		tokens:      make(map[string]string),
+		toolAccess:  make(map[string]ToolAccess),
+		toolLimits:  make(map[string]RateLimit),
+		limitByTier: make(map[ToolAccess]RateLimit),
+		windows:     make(map[string]*rateWindow),
+		now:         time.Now,
+	}
+}
+
+// AddClient registers a client identity
+func (p *Policy) AddClient(client ClientPolicy) error {
+	if client.ID == "" {
+		return errors.New("client ID is required")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if old, ok := p.clients[client.ID]; ok && old.Token != "" {
+		delete(p.tokens, old.Token)
+	}
+	if client.Token != "" {
+		if owner, ok := p.tokens[client.Token]; ok && owner != client.ID {
+			return errors.New("token already assigned to client " + owner)
+		}
+		p.tokens[client.Token] = client.ID
+	}
+	p.clients[client.ID] = &client
+	return nil
+}
+
+// RemoveClient revokes a client identity
+func (p *Policy) RemoveClient(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[id]; ok {
+		delete(p.tokens, client.Token)
+		delete(p.clients, id)
+	}
+	for key := range p.windows {
+		if strings.HasPrefix(key, id+"|") {
+			delete(p.windows, key)
+		}
+	}
+}
+
+// SetToolAccess overrides the access class declared by a tool definition
+func (p *Policy) SetToolAccess(tool string, access ToolAccess) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.toolAccess[tool] = access
+}
+
+// SetRateLimit sets the rate limit for a single tool
+func (p *Policy) SetRateLimit(tool string, limit RateLimit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.toolLimits[tool] = limit
+}
+
+// SetDefaultRateLimit sets the rate limit for all tools of an access class
+// that have no tool-specific limit
+func (p *Policy) SetDefaultRateLimit(access ToolAccess, limit RateLimit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limitByTier[access] = limit
+}
+
+// SetAuditLogger sets where write-tool invocations are recorded
+func (p *Policy) SetAuditLogger(audit AuditLogger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.audit = audit
+}
+
+// Authenticate resolves the client for an HTTP request from its bearer token
+func (p *Policy) Authenticate(r *http.Request) (*ClientPolicy, error) {
+	auth := r.Header.Get("Authorization")
+	token, ok := strings.CutPrefix(auth, "Bearer ")
+	if !ok || token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for known, id := range p.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return p.clients[id], nil
+		}
+	}
+	return nil, ErrUnauthenticated
+}
+
+// Client returns a registered client by ID
+func (p *Policy) Client(id string) (*ClientPolicy, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	client, ok := p.clients[id]
+	return client, ok
+}
+
+// Access returns the effective access class of a tool
+func (p *Policy) Access(tool Tool) ToolAccess {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.accessLocked(tool.Name, tool.Access)
+}
+
+// accessLocked returns the access class of a tool or resource scope,
+// given the class it declares
+func (p *Policy) accessLocked(name string, declared ToolAccess) ToolAccess {
+	if access, ok := p.toolAccess[name]; ok {
+		return access
+	}
+	if declared != "" {
+		return declared
+	}
+	return AccessWrite
+}
+
+// resourceScope is the name a resource goes by in allowlists, rate limits
+// and the audit log: its scheme and path joined by dots, so
+// "finance://report" is "finance.report" and "finance.*" covers it
+func resourceScope(uri string) string {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return uri
+	}
+	rest, _, _ = strings.Cut(rest, "?")
+	return scheme + "." + strings.ReplaceAll(strings.Trim(rest, "/"), "/", ".")
+}
+
+// Authorize checks that the client in ctx may call the tool and consumes
+// one unit of its rate limit
+func (p *Policy) Authorize(ctx context.Context, tool Tool) (*ClientPolicy, ToolAccess, error) {
+	return p.authorize(ctx, tool.Name, tool.Access)
+}
+
+// AuthorizeResource checks that the client in ctx may read the resource,
+// under its scope, and consumes one unit of its rate limit
+func (p *Policy) AuthorizeResource(ctx context.Context, resource Resource) (*ClientPolicy, ToolAccess, error) {
+	return p.authorize(ctx, resourceScope(resource.URI), resource.Access)
+}
+
+func (p *Policy) authorize(ctx context.Context, name string, declared ToolAccess) (*ClientPolicy, ToolAccess, error) {
+	id, ok := ClientIDFromContext(ctx)
+	if !ok {
+		return nil, "", ErrUnauthenticated
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client, ok := p.clients[id]
+	if !ok {
+		return nil, "", ErrUnauthenticated
+	}
+
+	access := p.accessLocked(name, declared)
+	if !client.allows(name, access) {
+		return client, access, ErrForbidden
+	}
+
+	limit, ok := p.toolLimits[name]
+	if !ok {
+		limit, ok = p.limitByTier[access]
+	}
+	if ok && limit.Requests > 0 && limit.Window > 0 {
+		now := p.now()
+		p.pruneWindowsLocked(now)
+		key := client.ID + "|" + name
+		w := p.windows[key]
+		if w == nil || w.expired(now) || w.length != limit.Window {
+			w = &rateWindow{start: now, length: limit.Window}
+			p.windows[key] = w
+		}
+		if w.count >= limit.Requests {
+			return client, access, ErrRateLimited
+		}
+		w.count++
+	}
+
+	return client, access, nil
+}
+
+// pruneWindowsLocked drops expired rate windows so clients and tools that
+// are no longer called don't accumulate entries
+func (p *Policy) pruneWindowsLocked(now time.Time) {
+	if now.Sub(p.lastPrune) < windowPruneInterval {
+		return
+	}
+	p.lastPrune = now
+	for key, w := range p.windows {
+		if w.expired(now) {
+			delete(p.windows, key)
+		}
+	}
+}
+
+// Visible reports whether a tool should be listed to the client in ctx
+func (p *Policy) Visible(ctx context.Context, tool Tool) bool {
+	return p.visible(ctx, tool.Name, tool.Access)
+}
+
+// VisibleResource reports whether a resource should be listed to the
+// client in ctx
+func (p *Policy) VisibleResource(ctx context.Context, resource Resource) bool {
+	return p.visible(ctx, resourceScope(resource.URI), resource.Access)
+}
+
+func (p *Policy) visible(ctx context.Context, name string, declared ToolAccess) bool {
+	id, ok := ClientIDFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client, ok := p.clients[id]
+	if !ok {
+		return false
+	}
+	return client.allows(name, p.accessLocked(name, declared))
+}
+
+// recordInvocation writes an audit entry for write and sensitive tools
+func (p *Policy) recordInvocation(server string, client *ClientPolicy, tool string, access ToolAccess, args json.RawMessage, succeeded bool) error {
+	p.mu.Lock()
+	audit := p.audit
+	p.mu.Unlock()
+
+	if audit == nil || !access.Audited() {
+		return nil
+	}
+
+	// Argument values may contain message bodies or account data, so only
+	// their names and a digest are recorded.
+	var argNames []string
+	var parsed map[string]any
+	if len(args) > 0 && json.Unmarshal(args, &parsed) == nil {
+		for name := range parsed {
+			argNames = append(argNames, name)
+		}
+		sort.Strings(argNames)
+	}
+	digest := sha256.Sum256(args)
+
+	return audit.RecordToolInvocation(client.ID, tool, map[string]interface{}{
+		"server":      server,
+		"access":      access,
+		"success":     succeeded,
+		"arguments":   argNames,
+		"args_sha256": hex.EncodeToString(digest[:]),
+	})
+}
+
+type clientIDKey struct{}
+
+// WithClientID attaches a client identity to ctx. In-process callers use this
+// instead of a bearer token.
+func WithClientID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, id)
+}
+
+// ClientIDFromContext returns the client identity attached to ctx
+func ClientIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(clientIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// policyError converts a policy error into a JSON-RPC error
+func policyError(err error) *Error {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return &Error{Code: ErrCodeUnauthenticated, Message: err.Error()}
+	case errors.Is(err, ErrForbidden):
+		return &Error{Code: ErrCodeForbidden, Message: err.Error()}
+	case errors.Is(err, ErrRateLimited):
+		return &Error{Code: ErrCodeRateLimited, Message: err.Error()}
+	default:
+		return &Error{Code: ErrCodeInternal, Message: err.Error()}
+	}
+}
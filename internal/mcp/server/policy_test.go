@@ -0,0 +1,375 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recordedInvocation struct {
+	actor   string
+	tool    string
+	details map[string]interface{}
+}
+
+type mockAuditLogger struct {
+	entries []recordedInvocation
+}
+
+func (m *mockAuditLogger) RecordToolInvocation(actor, tool string, details map[string]interface{}) error {
+	m.entries = append(m.entries, recordedInvocation{actor: actor, tool: tool, details: details})
+	return nil
+}
+
+func newPolicyTestServer(t *testing.T) (*Server, *Policy, *mockAuditLogger) {
+	t.Helper()
+
+	policy := NewPolicy()
+	audit := &mockAuditLogger{}
+	policy.SetAuditLogger(audit)
+
+	srv := New(Config{Name: "test", Version: "1.0.0", Policy: policy})
+	echo := WrapHandler(func(ctx context.Context, args *Args) (string, error) {
+		return "ok", nil
+	})
+	srv.RegisterTool(NewTool("mail.list").Access(AccessRead).Build(), echo)
+	srv.RegisterTool(NewTool("mail.send").Access(AccessWrite).String("to", "Recipient", true).Build(), echo)
+	srv.RegisterTool(NewTool("bank.transactions").Access(AccessSensitive).Build(), echo)
+	srv.RegisterTool(NewTool("misc.untagged").Build(), echo)
+
+	return srv, policy, audit
+}
+
+func TestPolicy_Authenticate(t *testing.T) {
+	policy := NewPolicy()
+	if err := policy.AddClient(ClientPolicy{ID: "cli", Token: "secret"}); err != nil {
+		t.Fatalf("AddClient failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr bool
+	}{
+		{"valid token", "Bearer secret", false},
+		{"wrong token", "Bearer nope", true},
+		{"missing header", "", true},
+		{"wrong scheme", "Basic secret", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			client, err := policy.Authenticate(req)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnauthenticated) {
+					t.Errorf("expected ErrUnauthenticated, got %v", err)
+				}
+				return
+			}
+			if err != nil || client.ID != "cli" {
+				t.Errorf("expected client 'cli', got %v (err %v)", client, err)
+			}
+		})
+	}
+}
+
+func TestPolicy_AddClient_DuplicateToken(t *testing.T) {
+	policy := NewPolicy()
+	policy.AddClient(ClientPolicy{ID: "a", Token: "shared"})
+	if err := policy.AddClient(ClientPolicy{ID: "b", Token: "shared"}); err == nil {
+		t.Error("expected error when reusing a token")
+	}
+}
+
+func TestServer_CallTool_AccessLevels(t *testing.T) {
+	srv, policy, _ := newPolicyTestServer(t)
+	policy.AddClient(ClientPolicy{ID: "reader"})
+	policy.AddClient(ClientPolicy{ID: "writer", MaxAccess: AccessWrite})
+	policy.AddClient(ClientPolicy{ID: "admin", MaxAccess: AccessSensitive})
+
+	tests := []struct {
+		client string
+		tool   string
+		want   error
+	}{
+		{"reader", "mail.list", nil},
+		{"reader", "mail.send", ErrForbidden},
+		{"reader", "misc.untagged", ErrForbidden},
+		{"writer", "mail.send", nil},
+		{"writer", "bank.transactions", ErrForbidden},
+		{"admin", "bank.transactions", nil},
+		{"unknown", "mail.list", ErrUnauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.client+"/"+tt.tool, func(t *testing.T) {
+			ctx := WithClientID(context.Background(), tt.client)
+			_, err := srv.CallTool(ctx, tt.tool, json.RawMessage(`{}`))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_CallTool_NoClientIdentity(t *testing.T) {
+	srv, _, _ := newPolicyTestServer(t)
+
+	_, err := srv.CallTool(context.Background(), "mail.list", nil)
+	if !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+}
+
+func TestServer_CallTool_Allowlist(t *testing.T) {
+	srv, policy, _ := newPolicyTestServer(t)
+	policy.AddClient(ClientPolicy{ID: "mailbot", MaxAccess: AccessSensitive, AllowedTools: []string{"mail.*"}})
+	ctx := WithClientID(context.Background(), "mailbot")
+
+	if _, err := srv.CallTool(ctx, "mail.send", nil); err != nil {
+		t.Errorf("expected mail.send to be allowed, got %v", err)
+	}
+	if _, err := srv.CallTool(ctx, "bank.transactions", nil); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden for bank.transactions, got %v", err)
+	}
+}
+
+func TestServer_CallTool_RateLimit(t *testing.T) {
+	srv, policy, _ := newPolicyTestServer(t)
+	policy.AddClient(ClientPolicy{ID: "a"})
+	policy.AddClient(ClientPolicy{ID: "b"})
+	policy.SetRateLimit("mail.list", RateLimit{Requests: 2, Window: time.Minute})
+
+	now := time.Now()
+	policy.now = func() time.Time { return now }
+
+	ctxA := WithClientID(context.Background(), "a")
+	for i := 0; i < 2; i++ {
+		if _, err := srv.CallTool(ctxA, "mail.list", nil); err != nil {
+			t.Fatalf("call %d: unexpected error %v", i, err)
+		}
+	}
+	if _, err := srv.CallTool(ctxA, "mail.list", nil); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited, got %v", err)
+	}
+
+	// Limits are tracked per client
+	if _, err := srv.CallTool(WithClientID(context.Background(), "b"), "mail.list", nil); err != nil {
+		t.Errorf("expected client b to be unaffected, got %v", err)
+	}
+
+	// Window resets
+	now = now.Add(time.Minute)
+	if _, err := srv.CallTool(ctxA, "mail.list", nil); err != nil {
+		t.Errorf("expected call after window reset to succeed, got %v", err)
+	}
+}
+
+func TestServer_CallTool_RateLimitWindowsPruned(t *testing.T) {
+	srv, policy, _ := newPolicyTestServer(t)
+	policy.AddClient(ClientPolicy{ID: "a"})
+	policy.AddClient(ClientPolicy{ID: "b"})
+	policy.SetRateLimit("mail.list", RateLimit{Requests: 2, Window: time.Second})
+
+	now := time.Now()
+	policy.now = func() time.Time { return now }
+
+	for _, id := range []string{"a", "b"} {
+		if _, err := srv.CallTool(WithClientID(context.Background(), id), "mail.list", nil); err != nil {
+			t.Fatalf("client %s: unexpected error %v", id, err)
+		}
+	}
+	if len(policy.windows) != 2 {
+		t.Fatalf("expected 2 rate windows, got %d", len(policy.windows))
+	}
+
+	// Client b stops calling; its window is swept once it has expired
+	now = now.Add(windowPruneInterval)
+	if _, err := srv.CallTool(WithClientID(context.Background(), "a"), "mail.list", nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, ok := policy.windows["b|mail.list"]; ok {
+		t.Error("expected expired window for client b to be pruned")
+	}
+	if len(policy.windows) != 1 {
+		t.Errorf("expected 1 rate window, got %d", len(policy.windows))
+	}
+
+	// Removing a client drops its windows
+	policy.RemoveClient("a")
+	if len(policy.windows) != 0 {
+		t.Errorf("expected no rate windows after removing client, got %d", len(policy.windows))
+	}
+}
+
+func TestServer_CallTool_AuditsWriteTools(t *testing.T) {
+	srv, policy, audit := newPolicyTestServer(t)
+	policy.AddClient(ClientPolicy{ID: "writer", MaxAccess: AccessWrite})
+	ctx := WithClientID(context.Background(), "writer")
+
+	srv.CallTool(ctx, "mail.list", nil)
+	srv.CallTool(ctx, "mail.send", json.RawMessage(`{"to": "alice@example.com"}`))
+
+	if len(audit.entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(audit.entries))
+	}
+
+	entry := audit.entries[0]
+	if entry.actor != "writer" || entry.tool != "mail.send" {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+	if entry.details["success"] != true {
+		t.Errorf("expected success=true, got %v", entry.details["success"])
+	}
+	args, _ := entry.details["arguments"].([]string)
+	if len(args) != 1 || args[0] != "to" {
+		t.Errorf("expected argument names [to], got %v", entry.details["arguments"])
+	}
+}
+
+func TestServer_ServeHTTP_RequiresToken(t *testing.T) {
+	srv, policy, _ := newPolicyTestServer(t)
+	policy.AddClient(ClientPolicy{ID: "cli", Token: "secret"})
+
+	body, _ := json.Marshal(Request{JSONRPC: "2.0", ID: 1, Method: "tools/list"})
+
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without token, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rr = httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 with token, got %d", rr.Code)
+	}
+
+	var resp struct {
+		Result ToolsListResult `json:"result"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+
+	// A read-only client only sees read tools
+	if len(resp.Result.Tools) != 1 || resp.Result.Tools[0].Name != "mail.list" {
+		t.Errorf("expected only mail.list to be visible, got %+v", resp.Result.Tools)
+	}
+}
+
+func TestServer_ServeHTTP_ForbiddenToolCall(t *testing.T) {
+	srv, policy, _ := newPolicyTestServer(t)
+	policy.AddClient(ClientPolicy{ID: "cli", Token: "secret"})
+
+	body, _ := json.Marshal(Request{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  json.RawMessage(`{"name": "mail.send", "arguments": {"to": "x"}}`),
+	})
+	req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer secret")
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	var resp Response
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal response: %v", err)
+	}
+	if resp.Error == nil || resp.Error.Code != ErrCodeForbidden {
+		t.Errorf("expected forbidden error, got %+v", resp.Error)
+	}
+}
+
+func TestServer_ReadResource_Policy(t *testing.T) {
+	srv, policy, audit := newPolicyTestServer(t)
+	read := WrapResourceHandler("text/plain", func(ctx context.Context, uri string) (string, error) {
+		return "data", nil
+	})
+	srv.RegisterResource(Resource{URI: "mail://inbox", Name: "Inbox", Access: AccessRead}, read)
+	srv.RegisterResource(Resource{URI: "bank://report", Name: "Tax report", Access: AccessSensitive}, read)
+	policy.AddClient(ClientPolicy{ID: "reader"})
+	policy.AddClient(ClientPolicy{ID: "accountant", MaxAccess: AccessSensitive, AllowedTools: []string{"bank.report"}})
+
+	tests := []struct {
+		client string
+		uri    string
+		want   error
+	}{
+		{"reader", "mail://inbox", nil},
+		{"reader", "bank://report?year=2025", ErrForbidden},
+		{"accountant", "bank://report?year=2025", nil},
+		{"accountant", "mail://inbox", ErrForbidden},
+		{"unknown", "mail://inbox", ErrUnauthenticated},
+		{"reader", "mail://outbox", ErrResourceNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.client+"/"+tt.uri, func(t *testing.T) {
+			ctx := WithClientID(context.Background(), tt.client)
+			if _, err := srv.ReadResource(ctx, tt.uri); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Only sensitive reads are audited, under the resource's scope
+	if len(audit.entries) != 1 || audit.entries[0].actor != "accountant" || audit.entries[0].tool != "bank.report" {
+		t.Errorf("audit entries = %+v", audit.entries)
+	}
+
+	resources := srv.ListResources(WithClientID(context.Background(), "reader"))
+	if len(resources) != 1 || resources[0].URI != "mail://inbox" {
+		t.Errorf("resources listed to reader = %+v", resources)
+	}
+}
+
+func TestServer_ServeHTTP_ForbiddenResourceRead(t *testing.T) {
+	srv, policy, _ := newPolicyTestServer(t)
+	srv.RegisterResource(Resource{URI: "bank://report", Name: "Tax report", Access: AccessSensitive},
+		WrapResourceHandler("text/plain", func(ctx context.Context, uri string) (string, error) {
+			return "data", nil
+		}))
+	policy.AddClient(ClientPolicy{ID: "cli", Token: "secret"})
+
+	for method, params := range map[string]string{
+		"resources/read": `{"uri": "bank://report"}`,
+		"resources/list": `{}`,
+	} {
+		body, _ := json.Marshal(Request{JSONRPC: "2.0", ID: 1, Method: method, Params: json.RawMessage(params)})
+		req := httptest.NewRequest("POST", "/mcp", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+
+		var resp struct {
+			Result *ResourcesListResult `json:"result"`
+			Error  *Error               `json:"error"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: unmarshal response: %v", method, err)
+		}
+		switch method {
+		case "resources/read":
+			if resp.Error == nil || resp.Error.Code != ErrCodeForbidden {
+				t.Errorf("read: expected forbidden error, got %+v", resp.Error)
+			}
+		case "resources/list":
+			if resp.Error != nil || resp.Result == nil || len(resp.Result.Resources) != 0 {
+				t.Errorf("list: expected no resources, got %+v, %+v", resp.Result, resp.Error)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -118,7 +119,9 @@ func (r *Registry) UnregisterResource(uri string) {
 	delete(r.resources, uri)
 }
 
-// GetResource returns a resource by URI
+// GetResource returns a resource by URI. A URI with a query, such as
+// "finance://report?year=2025", finds the resource without it; the
+// handler gets the full URI.
 func (r *Registry) GetResource(uri string) (Resource, ResourceHandler, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -126,6 +129,11 @@ func (r *Registry) GetResource(uri string) (Resource, ResourceHandler, bool) {
 	if rr, ok := r.resources[uri]; ok {
 		return rr.definition, rr.handler, true
 	}
+	if base, _, ok := strings.Cut(uri, "?"); ok {
+		if rr, ok := r.resources[base]; ok {
+			return rr.definition, rr.handler, true
+		}
+	}
 	return Resource{}, nil, false
 }
 
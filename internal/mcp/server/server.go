@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -14,6 +15,7 @@ import (
 type Server struct {
 	info        ServerInfo
 	registry    *Registry
+	policy      *Policy
 	initialized bool
 	mu          sync.RWMutex
 }
@@ -22,6 +24,11 @@ type Server struct {
 type Config struct {
 	Name    string
 	Version string
+
+	// Policy enforces authentication, allowlists and rate limits on tool
+	// calls and resource reads. A nil policy leaves the server open (for
+	// local-only use).
+	Policy *Policy
 }
 
 // New creates a new MCP server
@@ -39,7 +46,112 @@ func New(cfg Config) *Server {
 			Version: cfg.Version,
 		},
 		registry: NewRegistry(),
+		policy:   cfg.Policy,
+	}
+}
+
+// SetPolicy sets the authorization policy for tool calls and resource reads
+func (s *Server) SetPolicy(policy *Policy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+}
+
+// Policy returns the server's authorization policy, if any
+func (s *Server) Policy() *Policy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// AuthenticateRequest resolves the calling client of an HTTP request and
+// attaches it to the returned context. Without a policy the request context
+// is returned unchanged.
+func (s *Server) AuthenticateRequest(r *http.Request) (context.Context, error) {
+	policy := s.Policy()
+	if policy == nil {
+		return r.Context(), nil
+	}
+	if _, ok := ClientIDFromContext(r.Context()); ok {
+		return r.Context(), nil
+	}
+
+	client, err := policy.Authenticate(r)
+	if err != nil {
+		return nil, err
 	}
+	return WithClientID(r.Context(), client.ID), nil
+}
+
+// CallTool executes a tool after enforcing the server's policy.
+// Write and sensitive tool invocations are recorded in the audit log.
+func (s *Server) CallTool(ctx context.Context, name string, args json.RawMessage) (*ToolResult, error) {
+	tool, handler, ok := s.registry.GetTool(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrToolNotFound, name)
+	}
+
+	policy := s.Policy()
+	if policy == nil {
+		return handler(ctx, args)
+	}
+
+	client, access, err := policy.Authorize(ctx, tool)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := handler(ctx, args)
+	succeeded := err == nil && result != nil && !result.IsError
+	if auditErr := policy.recordInvocation(s.info.Name, client, name, access, args, succeeded); auditErr != nil {
+		log.Printf("MCP audit for tool %s failed: %v", name, auditErr)
+	}
+	return result, err
+}
+
+// ReadResource reads a resource after enforcing the server's policy,
+// which checks it under its scope such as "finance.report". Sensitive
+// resource reads are recorded in the audit log.
+func (s *Server) ReadResource(ctx context.Context, uri string) (*ResourceContent, error) {
+	resource, handler, ok := s.registry.GetResource(uri)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+
+	policy := s.Policy()
+	if policy == nil {
+		return handler(ctx, uri)
+	}
+
+	client, access, err := policy.AuthorizeResource(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := handler(ctx, uri)
+	args, _ := json.Marshal(map[string]string{"uri": uri})
+	scope := resourceScope(resource.URI)
+	if auditErr := policy.recordInvocation(s.info.Name, client, scope, access, args, err == nil); auditErr != nil {
+		log.Printf("MCP audit for resource %s failed: %v", scope, auditErr)
+	}
+	return content, err
+}
+
+// ListResources returns the resources the client in ctx may read
+func (s *Server) ListResources(ctx context.Context) []Resource {
+	resources := s.registry.ListResources()
+	policy := s.Policy()
+	if policy == nil {
+		return resources
+	}
+
+	visible := resources[:0]
+	for _, resource := range resources {
+		if policy.VisibleResource(ctx, resource) {
+			visible = append(visible, resource)
+		}
+	}
+	return visible
 }
 
 // Registry returns the server's tool/resource registry
@@ -82,7 +194,15 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx := r.Context()
+	ctx, err := s.AuthenticateRequest(r)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mcp"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		s.writeError(w, req.ID, ErrCodeUnauthenticated, err.Error())
+		return
+	}
+
 	result, err := s.handleMethod(ctx, req.Method, req.Params)
 	if err != nil {
 		if mcpErr, ok := err.(*Error); ok {
@@ -104,11 +224,11 @@ func (s *Server) handleMethod(ctx context.Context, method string, params json.Ra
 	case "notifications/initialized":
 		return s.handleInitialized()
 	case "tools/list":
-		return s.handleToolsList()
+		return s.handleToolsList(ctx)
 	case "tools/call":
 		return s.handleToolsCall(ctx, params)
 	case "resources/list":
-		return s.handleResourcesList()
+		return s.handleResourcesList(ctx)
 	case "resources/read":
 		return s.handleResourcesRead(ctx, params)
 	case "ping":
@@ -149,9 +269,22 @@ func (s *Server) handleInitialized() (any, error) {
 	return map[string]any{}, nil
 }
 
-func (s *Server) handleToolsList() (*ToolsListResult, error) {
+func (s *Server) handleToolsList(ctx context.Context) (*ToolsListResult, error) {
+	tools := s.registry.ListTools()
+
+	// Only list tools the client is allowed to call
+	if policy := s.Policy(); policy != nil {
+		visible := tools[:0]
+		for _, tool := range tools {
+			if policy.Visible(ctx, tool) {
+				visible = append(visible, tool)
+			}
+		}
+		tools = visible
+	}
+
 	return &ToolsListResult{
-		Tools: s.registry.ListTools(),
+		Tools: tools,
 	}, nil
 }
 
@@ -161,12 +294,13 @@ func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (*
 		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid tools/call params"}
 	}
 
-	_, handler, ok := s.registry.GetTool(callParams.Name)
-	if !ok {
+	result, err := s.CallTool(ctx, callParams.Name, callParams.Arguments)
+	if errors.Is(err, ErrToolNotFound) {
 		return ErrorResult(fmt.Sprintf("Unknown tool: %s", callParams.Name)), nil
 	}
-
-	result, err := handler(ctx, callParams.Arguments)
+	if errors.Is(err, ErrUnauthenticated) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrRateLimited) {
+		return nil, policyError(err)
+	}
 	if err != nil {
 		log.Printf("MCP tool %s error: %v", callParams.Name, err)
 		return ErrorResult(err.Error()), nil
@@ -175,9 +309,9 @@ func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (*
 	return result, nil
 }
 
-func (s *Server) handleResourcesList() (*ResourcesListResult, error) {
+func (s *Server) handleResourcesList(ctx context.Context) (*ResourcesListResult, error) {
 	return &ResourcesListResult{
-		Resources: s.registry.ListResources(),
+		Resources: s.ListResources(ctx),
 	}, nil
 }
 
@@ -187,12 +321,13 @@ func (s *Server) handleResourcesRead(ctx context.Context, params json.RawMessage
 		return nil, &Error{Code: ErrCodeInvalidParams, Message: "Invalid resources/read params"}
 	}
 
-	_, handler, ok := s.registry.GetResource(readParams.URI)
-	if !ok {
+	content, err := s.ReadResource(ctx, readParams.URI)
+	if errors.Is(err, ErrResourceNotFound) {
 		return nil, &Error{Code: ErrCodeInvalidParams, Message: fmt.Sprintf("Unknown resource: %s", readParams.URI)}
 	}
-
-	content, err := handler(ctx, readParams.URI)
+	if errors.Is(err, ErrUnauthenticated) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrRateLimited) {
+		return nil, policyError(err)
+	}
 	if err != nil {
 		return nil, &Error{Code: ErrCodeInternal, Message: err.Error()}
 	}
@@ -87,6 +87,14 @@ func TestServer_RegisterResource(t *testing.T) {
 	if resources[0].URI != "test://data" {
 		t.Errorf("expected resource URI 'test://data', got %q", resources[0].URI)
 	}
+
+	// A query string still finds the resource
+	if _, _, ok := srv.Registry().GetResource("test://data?year=2025"); !ok {
+		t.Error("expected resource found with a query string")
+	}
+	if _, _, ok := srv.Registry().GetResource("test://other?year=2025"); ok {
+		t.Error("expected no resource for an unknown URI")
+	}
 }
 
 func TestServer_HandleInitialize(t *testing.T) {
@@ -15,9 +15,16 @@ type ResourceHandler func(ctx context.Context, uri string) (*ResourceContent, er
 
 // Tool represents an MCP tool definition
 type Tool struct {
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	InputSchema InputSchema `json:"inputSchema"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	InputSchema InputSchema      `json:"inputSchema"`
+	Annotations *ToolAnnotations `json:"annotations,omitempty"`
+	Access      ToolAccess       `json:"-"` // read/write/sensitive classification for policies
+}
+
+// ToolAnnotations are behavioral hints advertised to MCP clients
+type ToolAnnotations struct {
+	ReadOnlyHint bool `json:"readOnlyHint,omitempty"`
 }
 
 // InputSchema defines the JSON Schema for tool inputs
@@ -51,10 +58,11 @@ type ContentBlock struct {
 
 // Resource represents an MCP resource definition
 type Resource struct {
-	URI         string `json:"uri"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	MimeType    string `json:"mimeType,omitempty"`
+	URI         string     `json:"uri"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	MimeType    string     `json:"mimeType,omitempty"`
+	Access      ToolAccess `json:"-"` // read/sensitive classification for policies
 }
 
 // ResourceContent is the content read from a resource
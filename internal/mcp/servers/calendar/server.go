@@ -1,4 +1,5 @@
-// Package calendar provides an MCP server for Google Calendar operations.
+// Package calendar provides an MCP server for calendar operations, backed by
+// Google Calendar or any CalendarClient such as a CalDAV client.
 package calendar
 
 import (
@@ -41,6 +42,15 @@ func New(client *calclient.Client) *Server {
 	return newServer(client)
 }
 
+// NewWithClient creates a new Calendar MCP server from any CalendarClient,
+// such as a CalDAV client
+func NewWithClient(client CalendarClient) *Server {
+	if client == nil {
+		return nil
+	}
+	return newServer(client)
+}
+
 // NewWithMockClient creates a new Calendar MCP server with a mock client for testing.
 func NewWithMockClient(client CalendarClient) *Server {
 	return newServer(client)
@@ -67,6 +77,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.list_events").
 			Description("List calendar events within a date range").
+			Access(server.AccessRead).
 			String("start", "Start date (YYYY-MM-DD or 'today')", false).
 			String("end", "End date (YYYY-MM-DD or days from start like '+7')", false).
 			String("calendar_id", "Calendar ID (default: primary)", false).
@@ -78,6 +89,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.today").
 			Description("Get today's calendar events").
+			Access(server.AccessRead).
 			Build(),
 		s.handleToday,
 	)
@@ -86,6 +98,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.upcoming").
 			Description("Get upcoming events for the next N days").
+			Access(server.AccessRead).
 			Integer("days", "Number of days to look ahead (default: 7)", false).
 			Build(),
 		s.handleUpcoming,
@@ -95,6 +108,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.get_event").
 			Description("Get details of a specific event").
+			Access(server.AccessRead).
 			String("event_id", "The event ID", true).
 			String("calendar_id", "Calendar ID (default: primary)", false).
 			Build(),
@@ -105,6 +119,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.create_event").
 			Description("Create a new calendar event").
+			Access(server.AccessWrite).
 			String("summary", "Event title/summary", true).
 			String("start", "Start date/time (YYYY-MM-DD HH:MM or YYYY-MM-DD for all-day)", true).
 			String("end", "End date/time (YYYY-MM-DD HH:MM or YYYY-MM-DD for all-day)", false).
@@ -120,6 +135,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.quick_add").
 			Description("Create an event using natural language (e.g., 'Meeting with John tomorrow at 3pm')").
+			Access(server.AccessWrite).
 			String("text", "Natural language event description", true).
 			Build(),
 		s.handleQuickAdd,
@@ -129,6 +145,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.update_event").
 			Description("Update an existing calendar event").
+			Access(server.AccessWrite).
 			String("event_id", "The event ID to update", true).
 			String("summary", "New event title", false).
 			String("description", "New description", false).
@@ -143,6 +160,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.delete_event").
 			Description("Delete a calendar event").
+			Access(server.AccessWrite).
 			String("event_id", "The event ID to delete", true).
 			String("calendar_id", "Calendar ID (default: primary)", false).
 			Build(),
@@ -153,6 +171,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.find_free_time").
 			Description("Find available time slots in the calendar").
+			Access(server.AccessRead).
 			String("start", "Start date (YYYY-MM-DD)", true).
 			String("end", "End date (YYYY-MM-DD)", true).
 			Integer("duration_minutes", "Minimum duration in minutes (default: 30)", false).
@@ -164,6 +183,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("calendar.list_calendars").
 			Description("List all available calendars").
+			Access(server.AccessRead).
 			Build(),
 		s.handleListCalendars,
 	)
@@ -177,6 +197,7 @@ func (s *Server) registerResources() {
 			Name:        "Today's Schedule",
 			Description: "Today's calendar events",
 			MimeType:    "application/json",
+			Access:      server.AccessRead,
 		},
 		s.handleTodayResource,
 	)
@@ -188,6 +209,7 @@ func (s *Server) registerResources() {
 			Name:        "This Week's Schedule",
 			Description: "Calendar events for the next 7 days",
 			MimeType:    "application/json",
+			Access:      server.AccessRead,
 		},
 		s.handleWeekResource,
 	)
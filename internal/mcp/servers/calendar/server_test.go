@@ -24,6 +24,24 @@ func TestNew(t *testing.T) {
 	})
 }
 
+func TestNewWithClient(t *testing.T) {
+	t.Run("nil client returns nil", func(t *testing.T) {
+		if srv := NewWithClient(nil); srv != nil {
+			t.Error("expected nil server for nil client")
+		}
+	})
+
+	t.Run("creates server with any calendar client", func(t *testing.T) {
+		srv := NewWithClient(&MockCalendarClient{})
+		if srv == nil {
+			t.Fatal("NewWithClient returned nil")
+		}
+		if srv.Info().Name != "calendar" {
+			t.Errorf("expected name 'calendar', got %q", srv.Info().Name)
+		}
+	})
+}
+
 func TestNewWithMockClient(t *testing.T) {
 	t.Run("creates server with mock client", func(t *testing.T) {
 		mock := &MockCalendarClient{}
@@ -0,0 +1,375 @@
+// Package contacts provides an MCP server for address book operations over CardDAV.
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quantumlife/quantumlife/internal/dav"
+	"github.com/quantumlife/quantumlife/internal/mcp/server"
+)
+
+// ContactsClient defines the interface for contact operations used by the server.
+// This interface allows for mocking in unit tests.
+type ContactsClient interface {
+	ListAddressBooks(ctx context.Context) ([]dav.AddressBook, error)
+	ListContacts(ctx context.Context, addressBookID string) ([]dav.Contact, error)
+	SearchContacts(ctx context.Context, query string, limit int) ([]dav.Contact, error)
+	GetContact(ctx context.Context, contactID string) (*dav.Contact, error)
+	CreateContact(ctx context.Context, addressBookID string, contact dav.Contact) (*dav.Contact, error)
+	UpdateContact(ctx context.Context, req dav.UpdateContactRequest) (*dav.Contact, error)
+	DeleteContact(ctx context.Context, contactID string) error
+}
+
+// Server is the Contacts MCP server
+type Server struct {
+	*server.Server
+	client ContactsClient
+}
+
+// New creates a new Contacts MCP server from a CardDAV client
+func New(client *dav.CardDAVClient) *Server {
+	if client == nil {
+		return nil
+	}
+	return newServer(client)
+}
+
+// NewWithMockClient creates a new Contacts MCP server with a mock client for testing.
+func NewWithMockClient(client ContactsClient) *Server {
+	return newServer(client)
+}
+
+// newServer creates a new Contacts MCP server with the given client.
+func newServer(client ContactsClient) *Server {
+	s := &Server{
+		Server: server.New(server.Config{
+			Name:    "contacts",
+			Version: "1.0.0",
+		}),
+		client: client,
+	}
+
+	s.registerTools()
+	s.registerResources()
+
+	return s
+}
+
+func (s *Server) registerTools() {
+	// List address books
+	s.RegisterTool(
+		server.NewTool("contacts.list_address_books").
+			Description("List the user's address books").
+			Access(server.AccessRead).
+			Build(),
+		s.handleListAddressBooks,
+	)
+
+	// List contacts
+	s.RegisterTool(
+		server.NewTool("contacts.list").
+			Description("List contacts in an address book").
+			Access(server.AccessRead).
+			String("address_book_id", "Address book ID (default: the default address book)", false).
+			Integer("limit", "Maximum number of contacts to return (default: 100)", false).
+			Build(),
+		s.handleList,
+	)
+
+	// Search contacts
+	s.RegisterTool(
+		server.NewTool("contacts.search").
+			Description("Search contacts by name, email, phone or organization").
+			Access(server.AccessRead).
+			String("query", "Search term", true).
+			Integer("limit", "Maximum number of results (default: 20)", false).
+			Build(),
+		s.handleSearch,
+	)
+
+	// Get contact
+	s.RegisterTool(
+		server.NewTool("contacts.get").
+			Description("Get full details of a contact").
+			Access(server.AccessRead).
+			String("contact_id", "Contact ID", true).
+			Build(),
+		s.handleGet,
+	)
+
+	// Create contact
+	s.RegisterTool(
+		server.NewTool("contacts.create").
+			Description("Create a new contact").
+			Access(server.AccessWrite).
+			String("full_name", "Display name (derived from given/family name if omitted)", false).
+			String("given_name", "Given (first) name", false).
+			String("family_name", "Family (last) name", false).
+			String("emails", "Comma-separated email addresses, optionally typed (e.g. work:ann@example.com)", false).
+			String("phones", "Comma-separated phone numbers, optionally typed (e.g. cell:+1 555 0100)", false).
+			String("organization", "Organization", false).
+			String("title", "Job title", false).
+			String("birthday", "Birthday (YYYY-MM-DD)", false).
+			String("note", "Note", false).
+			String("address_book_id", "Address book ID (default: the default address book)", false).
+			Build(),
+		s.handleCreate,
+	)
+
+	// Update contact
+	s.RegisterTool(
+		server.NewTool("contacts.update").
+			Description("Update fields of an existing contact. Omitted fields are left unchanged.").
+			Access(server.AccessWrite).
+			String("contact_id", "Contact ID", true).
+			String("full_name", "New display name", false).
+			String("given_name", "New given name", false).
+			String("family_name", "New family name", false).
+			String("emails", "Comma-separated email addresses (replaces existing)", false).
+			String("phones", "Comma-separated phone numbers (replaces existing)", false).
+			String("organization", "New organization", false).
+			String("title", "New job title", false).
+			String("birthday", "New birthday (YYYY-MM-DD)", false).
+			String("note", "New note", false).
+			Build(),
+		s.handleUpdate,
+	)
+
+	// Delete contact
+	s.RegisterTool(
+		server.NewTool("contacts.delete").
+			Description("Delete a contact").
+			Access(server.AccessWrite).
+			String("contact_id", "Contact ID", true).
+			Build(),
+		s.handleDelete,
+	)
+}
+
+func (s *Server) registerResources() {
+	s.RegisterResource(
+		server.Resource{
+			URI:         "contacts://all",
+			Name:        "All Contacts",
+			Description: "Contacts in the default address book",
+			MimeType:    "application/json",
+			Access:      server.AccessRead,
+		},
+		s.handleAllResource,
+	)
+}
+
+// Tool handlers
+
+func (s *Server) handleListAddressBooks(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	books, err := s.client.ListAddressBooks(ctx)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	if len(books) == 0 {
+		return server.SuccessResult("No address books found."), nil
+	}
+
+	return server.JSONResult(books)
+}
+
+func (s *Server) handleList(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	limit := args.IntDefault("limit", 100)
+
+	contacts, err := s.client.ListContacts(ctx, args.String("address_book_id"))
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	if len(contacts) == 0 {
+		return server.SuccessResult("No contacts found."), nil
+	}
+	if limit > 0 && len(contacts) > limit {
+		contacts = contacts[:limit]
+	}
+
+	return server.JSONResult(formatContacts(contacts))
+}
+
+func (s *Server) handleSearch(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	query, err := args.RequireString("query")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	limit := args.IntDefault("limit", 20)
+
+	contacts, err := s.client.SearchContacts(ctx, query, limit)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	if len(contacts) == 0 {
+		return server.SuccessResult(fmt.Sprintf("No contacts matching %q.", query)), nil
+	}
+
+	return server.JSONResult(formatContacts(contacts))
+}
+
+func (s *Server) handleGet(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	contactID, err := args.RequireString("contact_id")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	contact, err := s.client.GetContact(ctx, contactID)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	return server.JSONResult(contact)
+}
+
+func (s *Server) handleCreate(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+
+	contact := dav.Contact{
+		FullName:     args.String("full_name"),
+		GivenName:    args.String("given_name"),
+		FamilyName:   args.String("family_name"),
+		Emails:       parseFields(args.String("emails")),
+		Phones:       parseFields(args.String("phones")),
+		Organization: args.String("organization"),
+		Title:        args.String("title"),
+		Birthday:     args.String("birthday"),
+		Note:         args.String("note"),
+	}
+	if contact.FullName == "" && contact.GivenName == "" && contact.FamilyName == "" {
+		return server.ErrorResult("full_name, given_name or family_name is required"), nil
+	}
+
+	created, err := s.client.CreateContact(ctx, args.String("address_book_id"), contact)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	return server.SuccessResult(fmt.Sprintf("Contact created: %s\nID: %s", created.FullName, created.ID)), nil
+}
+
+func (s *Server) handleUpdate(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	contactID, err := args.RequireString("contact_id")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	req := dav.UpdateContactRequest{ContactID: contactID}
+	optional := func(name string) *string {
+		if !args.Has(name) {
+			return nil
+		}
+		v := args.String(name)
+		return &v
+	}
+	req.FullName = optional("full_name")
+	req.GivenName = optional("given_name")
+	req.FamilyName = optional("family_name")
+	req.Organization = optional("organization")
+	req.Title = optional("title")
+	req.Birthday = optional("birthday")
+	req.Note = optional("note")
+	if args.Has("emails") {
+		req.Emails = parseFields(args.String("emails"))
+		if req.Emails == nil {
+			req.Emails = []dav.ContactField{}
+		}
+	}
+	if args.Has("phones") {
+		req.Phones = parseFields(args.String("phones"))
+		if req.Phones == nil {
+			req.Phones = []dav.ContactField{}
+		}
+	}
+
+	contact, err := s.client.UpdateContact(ctx, req)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	return server.SuccessResult(fmt.Sprintf("Contact updated: %s", contact.FullName)), nil
+}
+
+func (s *Server) handleDelete(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	contactID, err := args.RequireString("contact_id")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	if err := s.client.DeleteContact(ctx, contactID); err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	return server.SuccessResult("Contact deleted."), nil
+}
+
+// Resource handlers
+
+func (s *Server) handleAllResource(ctx context.Context, uri string) (*server.ResourceContent, error) {
+	contacts, err := s.client.ListContacts(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data, _ := json.MarshalIndent(formatContacts(contacts), "", "  ")
+	return &server.ResourceContent{
+		URI:      uri,
+		MimeType: "application/json",
+		Text:     string(data),
+	}, nil
+}
+
+// Helper functions
+
+type contactInfo struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+func formatContacts(contacts []dav.Contact) []contactInfo {
+	result := make([]contactInfo, 0, len(contacts))
+	for _, c := range contacts {
+		info := contactInfo{
+			ID:           c.ID,
+			Name:         c.FullName,
+			Email:        c.PrimaryEmail(),
+			Organization: c.Organization,
+		}
+		if len(c.Phones) > 0 {
+			info.Phone = c.Phones[0].Value
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+// parseFields parses "a@x.com, work:b@y.com" into typed contact fields
+func parseFields(s string) []dav.ContactField {
+	var fields []dav.ContactField
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := dav.ContactField{Value: part}
+		if typ, value, ok := strings.Cut(part, ":"); ok && !strings.ContainsAny(typ, "@+ ") && typ != "" {
+			field = dav.ContactField{Type: strings.ToLower(typ), Value: strings.TrimSpace(value)}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
@@ -0,0 +1,329 @@
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/quantumlife/quantumlife/internal/dav"
+	"github.com/quantumlife/quantumlife/internal/mcp/server"
+)
+
+// MockContactsClient implements a mock contacts client for testing.
+type MockContactsClient struct {
+	ListAddressBooksFunc func(ctx context.Context) ([]dav.AddressBook, error)
+	ListContactsFunc     func(ctx context.Context, addressBookID string) ([]dav.Contact, error)
+	SearchContactsFunc   func(ctx context.Context, query string, limit int) ([]dav.Contact, error)
+	GetContactFunc       func(ctx context.Context, contactID string) (*dav.Contact, error)
+	CreateContactFunc    func(ctx context.Context, addressBookID string, contact dav.Contact) (*dav.Contact, error)
+	UpdateContactFunc    func(ctx context.Context, req dav.UpdateContactRequest) (*dav.Contact, error)
+	DeleteContactFunc    func(ctx context.Context, contactID string) error
+}
+
+func (m *MockContactsClient) ListAddressBooks(ctx context.Context) ([]dav.AddressBook, error) {
+	if m.ListAddressBooksFunc != nil {
+		return m.ListAddressBooksFunc(ctx)
+	}
+	return []dav.AddressBook{{ID: "/addressbooks/user/contacts/", Name: "Contacts", Default: true}}, nil
+}
+
+func (m *MockContactsClient) ListContacts(ctx context.Context, addressBookID string) ([]dav.Contact, error) {
+	if m.ListContactsFunc != nil {
+		return m.ListContactsFunc(ctx, addressBookID)
+	}
+	return sampleContacts(), nil
+}
+
+func (m *MockContactsClient) SearchContacts(ctx context.Context, query string, limit int) ([]dav.Contact, error) {
+	if m.SearchContactsFunc != nil {
+		return m.SearchContactsFunc(ctx, query, limit)
+	}
+	var matches []dav.Contact
+	for _, c := range sampleContacts() {
+		if c.Matches(query) {
+			matches = append(matches, c)
+		}
+	}
+	return matches, nil
+}
+
+func (m *MockContactsClient) GetContact(ctx context.Context, contactID string) (*dav.Contact, error) {
+	if m.GetContactFunc != nil {
+		return m.GetContactFunc(ctx, contactID)
+	}
+	c := sampleContacts()[0]
+	return &c, nil
+}
+
+func (m *MockContactsClient) CreateContact(ctx context.Context, addressBookID string, contact dav.Contact) (*dav.Contact, error) {
+	if m.CreateContactFunc != nil {
+		return m.CreateContactFunc(ctx, addressBookID, contact)
+	}
+	contact.ID = "new-contact"
+	return &contact, nil
+}
+
+func (m *MockContactsClient) UpdateContact(ctx context.Context, req dav.UpdateContactRequest) (*dav.Contact, error) {
+	if m.UpdateContactFunc != nil {
+		return m.UpdateContactFunc(ctx, req)
+	}
+	c := sampleContacts()[0]
+	return &c, nil
+}
+
+func (m *MockContactsClient) DeleteContact(ctx context.Context, contactID string) error {
+	if m.DeleteContactFunc != nil {
+		return m.DeleteContactFunc(ctx, contactID)
+	}
+	return nil
+}
+
+func sampleContacts() []dav.Contact {
+	return []dav.Contact{
+		{
+			ID:           "alice-001",
+			FullName:     "Alice Example",
+			Emails:       []dav.ContactField{{Type: "work", Value: "alice@example.com"}},
+			Phones:       []dav.ContactField{{Type: "cell", Value: "+1 555 0100"}},
+			Organization: "Example Corp",
+		},
+		{
+			ID:       "bob-002",
+			FullName: "Bob Builder",
+			Emails:   []dav.ContactField{{Value: "bob@example.org"}},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	if srv := New(nil); srv != nil {
+		t.Error("expected nil server for nil client")
+	}
+}
+
+func TestNewWithMockClient(t *testing.T) {
+	srv := NewWithMockClient(&MockContactsClient{})
+	if srv == nil {
+		t.Fatal("NewWithMockClient returned nil")
+	}
+	if info := srv.Info(); info.Name != "contacts" || info.Version != "1.0.0" {
+		t.Errorf("unexpected server info: %+v", info)
+	}
+}
+
+func TestContactsServer_ToolRegistration(t *testing.T) {
+	srv := NewWithMockClient(&MockContactsClient{})
+
+	expected := map[string]server.ToolAccess{
+		"contacts.list_address_books": server.AccessRead,
+		"contacts.list":               server.AccessRead,
+		"contacts.search":             server.AccessRead,
+		"contacts.get":                server.AccessRead,
+		"contacts.create":             server.AccessWrite,
+		"contacts.update":             server.AccessWrite,
+		"contacts.delete":             server.AccessWrite,
+	}
+
+	tools := srv.Registry().ListTools()
+	if len(tools) != len(expected) {
+		t.Errorf("expected %d tools, got %d", len(expected), len(tools))
+	}
+	for _, tool := range tools {
+		access, ok := expected[tool.Name]
+		if !ok {
+			t.Errorf("unexpected tool %q", tool.Name)
+			continue
+		}
+		if tool.Access != access {
+			t.Errorf("tool %q: expected access %q, got %q", tool.Name, access, tool.Access)
+		}
+	}
+}
+
+func TestContactsServer_Search(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      string
+		setup     func(*MockContactsClient)
+		wantErr   bool
+		wantText  string
+		wantCount int
+	}{
+		{
+			name:      "matches by organization",
+			args:      `{"query": "example corp"}`,
+			wantCount: 1,
+		},
+		{
+			name:     "no matches",
+			args:     `{"query": "zed"}`,
+			wantText: "No contacts matching",
+		},
+		{
+			name:    "missing query",
+			args:    `{}`,
+			wantErr: true,
+		},
+		{
+			name: "client error",
+			args: `{"query": "alice"}`,
+			setup: func(m *MockContactsClient) {
+				m.SearchContactsFunc = func(ctx context.Context, query string, limit int) ([]dav.Contact, error) {
+					return nil, errors.New("server unavailable")
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockContactsClient{}
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			srv := NewWithMockClient(mock)
+
+			result, err := srv.handleSearch(context.Background(), json.RawMessage(tt.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if !result.IsError {
+					t.Error("expected error result")
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+			}
+			if tt.wantText != "" && !strings.Contains(result.Content[0].Text, tt.wantText) {
+				t.Errorf("expected %q in %q", tt.wantText, result.Content[0].Text)
+			}
+			if tt.wantCount > 0 {
+				var got []contactInfo
+				if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+					t.Fatalf("failed to decode result: %v", err)
+				}
+				if len(got) != tt.wantCount {
+					t.Errorf("expected %d contacts, got %d", tt.wantCount, len(got))
+				}
+			}
+		})
+	}
+}
+
+func TestContactsServer_List_Limit(t *testing.T) {
+	srv := NewWithMockClient(&MockContactsClient{})
+
+	result, err := srv.handleList(context.Background(), json.RawMessage(`{"limit": 1}`))
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %+v", err, result)
+	}
+
+	var got []contactInfo
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if len(got) != 1 || got[0].Phone != "+1 555 0100" || got[0].Email != "alice@example.com" {
+		t.Errorf("unexpected contacts: %+v", got)
+	}
+}
+
+func TestContactsServer_Create(t *testing.T) {
+	var created dav.Contact
+	mock := &MockContactsClient{
+		CreateContactFunc: func(ctx context.Context, addressBookID string, contact dav.Contact) (*dav.Contact, error) {
+			created = contact
+			contact.ID = "new-contact"
+			contact.FullName = "Dana Lee"
+			return &contact, nil
+		},
+	}
+	srv := NewWithMockClient(mock)
+
+	result, err := srv.handleCreate(context.Background(), json.RawMessage(
+		`{"given_name": "Dana", "family_name": "Lee", "emails": "work:dana@example.com, dana@home.example", "phones": "cell:+1 555 0123"}`))
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %+v", err, result)
+	}
+	if !strings.Contains(result.Content[0].Text, "new-contact") {
+		t.Errorf("expected contact ID in result, got %q", result.Content[0].Text)
+	}
+
+	if len(created.Emails) != 2 || created.Emails[0].Type != "work" || created.Emails[0].Value != "dana@example.com" || created.Emails[1].Type != "" {
+		t.Errorf("unexpected emails: %+v", created.Emails)
+	}
+	if len(created.Phones) != 1 || created.Phones[0].Type != "cell" || created.Phones[0].Value != "+1 555 0123" {
+		t.Errorf("unexpected phones: %+v", created.Phones)
+	}
+
+	// A name is required
+	result, _ = srv.handleCreate(context.Background(), json.RawMessage(`{"emails": "x@example.com"}`))
+	if !result.IsError {
+		t.Error("expected error when no name is given")
+	}
+}
+
+func TestContactsServer_Update(t *testing.T) {
+	var got dav.UpdateContactRequest
+	mock := &MockContactsClient{
+		UpdateContactFunc: func(ctx context.Context, req dav.UpdateContactRequest) (*dav.Contact, error) {
+			got = req
+			return &dav.Contact{ID: req.ContactID, FullName: "Alice Example"}, nil
+		},
+	}
+	srv := NewWithMockClient(mock)
+
+	result, err := srv.handleUpdate(context.Background(), json.RawMessage(`{"contact_id": "alice-001", "title": "CTO", "phones": ""}`))
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %+v", err, result)
+	}
+
+	if got.Title == nil || *got.Title != "CTO" {
+		t.Errorf("expected title to be set, got %v", got.Title)
+	}
+	if got.FullName != nil || got.Note != nil || got.Emails != nil {
+		t.Error("expected omitted fields to be left unchanged")
+	}
+	if got.Phones == nil || len(got.Phones) != 0 {
+		t.Errorf("expected empty phones to clear the field, got %+v", got.Phones)
+	}
+}
+
+func TestContactsServer_Delete(t *testing.T) {
+	var deleted string
+	srv := NewWithMockClient(&MockContactsClient{
+		DeleteContactFunc: func(ctx context.Context, contactID string) error {
+			deleted = contactID
+			return nil
+		},
+	})
+
+	result, err := srv.handleDelete(context.Background(), json.RawMessage(`{"contact_id": "bob-002"}`))
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %+v", err, result)
+	}
+	if deleted != "bob-002" {
+		t.Errorf("expected bob-002 to be deleted, got %q", deleted)
+	}
+
+	result, _ = srv.handleDelete(context.Background(), json.RawMessage(`{}`))
+	if !result.IsError {
+		t.Error("expected error for missing contact_id")
+	}
+}
+
+func TestContactsServer_AllResource(t *testing.T) {
+	srv := NewWithMockClient(&MockContactsClient{})
+
+	content, err := srv.handleAllResource(context.Background(), "contacts://all")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content.MimeType != "application/json" || !strings.Contains(content.Text, "Alice Example") {
+		t.Errorf("unexpected resource content: %+v", content)
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/mcp/server"
 	"github.com/quantumlife/quantumlife/internal/spaces"
@@ -19,15 +23,26 @@ type FinanceSpace interface {
 	GetAccounts() []finance.Account
 	GetTotalBalance() float64
 	GetNetWorth() (assets, liabilities, netWorth float64)
+	GetNetWorthReport() *finance.NetWorth
+	HomeCurrency() string
 	GetTransactions(filter finance.TransactionFilter) []*finance.CategorizedTransaction
 	GetSpendingSummary(period string) *finance.SpendingSummary
 	GetRecurringTransactions() []*finance.RecurringTransaction
+	GetSubscriptions() *finance.SubscriptionReport
 	GetInsights() []*finance.Insight
 	GetConnections() []*finance.Connection
-	SetBudget(category finance.Category, amount float64)
-	GetBudgets() map[finance.Category]float64
+	SetBudget(category finance.Category, amount float64, rollover bool) (*finance.Envelope, error)
+	GetBudgets() (*finance.BudgetReport, error)
+	TransferBudget(from, to finance.Category, amount float64, note string) (*finance.BudgetTransfer, error)
+	Forecast(days int) (*finance.Forecast, error)
+	AddPlannedExpense(p *finance.PlannedExpense) error
 	CreateLinkToken(ctx context.Context, userID string) (string, error)
 	GetSyncStatus() spaces.SyncStatus
+	Recategorize(transactionID string, category finance.Category) (*finance.Recategorization, error)
+	SplitTransaction(transactionID string, splits []finance.Split) (*finance.CategorizedTransaction, error)
+	ExpectReimbursement(transactionID string, amount float64, payer, note string) (*finance.Reimbursement, error)
+	GetReimbursements() *finance.ReimbursementReport
+	TaxReport(year int) (*finance.TaxReport, error)
 }
 
 // Server wraps the MCP server with finance functionality
@@ -65,6 +80,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("finance.list_accounts").
 			Description("List all connected bank accounts with balances").
+			Access(server.AccessSensitive).
 			Build(),
 		s.handleListAccounts,
 	)
@@ -73,6 +89,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("finance.get_balance").
 			Description("Get total balance and net worth across all accounts").
+			Access(server.AccessSensitive).
 			Build(),
 		s.handleGetBalance,
 	)
@@ -81,6 +98,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("finance.list_transactions").
 			Description("List transactions with optional filtering").
+			Access(server.AccessSensitive).
 			String("category", "Filter by category (food, transport, utilities, etc.)", false).
 			String("start_date", "Start date (YYYY-MM-DD)", false).
 			String("end_date", "End date (YYYY-MM-DD)", false).
@@ -97,6 +115,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("finance.spending_summary").
 			Description("Get spending summary by category").
+			Access(server.AccessSensitive).
 			Enum("period", "Time period", []string{"week", "month", "quarter", "year"}, false).
 			Build(),
 		s.handleSpendingSummary,
@@ -106,14 +125,25 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("finance.recurring").
 			Description("Get detected recurring transactions (subscriptions, bills)").
+			Access(server.AccessSensitive).
 			Build(),
 		s.handleGetRecurring,
 	)
 
+	// Subscriptions
+	s.RegisterTool(
+		server.NewTool("finance.subscriptions").
+			Description("List subscriptions with their price history, and flag price increases, duplicates and trials that turned paid").
+			Access(server.AccessSensitive).
+			Build(),
+		s.handleGetSubscriptions,
+	)
+
 	// Get insights
 	s.RegisterTool(
 		server.NewTool("finance.insights").
 			Description("Get financial insights and recommendations").
+			Access(server.AccessSensitive).
 			Build(),
 		s.handleGetInsights,
 	)
@@ -122,6 +152,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("finance.connections").
 			Description("List all bank connections").
+			Access(server.AccessSensitive).
 			Build(),
 		s.handleGetConnections,
 	)
@@ -129,9 +160,11 @@ func (s *Server) registerTools() {
 	// Set budget
 	s.RegisterTool(
 		server.NewTool("finance.set_budget").
-			Description("Set a monthly budget for a category").
+			Description("Set the budget envelope for a category, per budget period").
+			Access(server.AccessSensitive).
 			String("category", "Spending category", true).
-			Number("amount", "Monthly budget amount", true).
+			Number("amount", "Budget per period, in the home currency", true).
+			Boolean("rollover", "Carry unspent and overspent amounts into the next period", false).
 			Build(),
 		s.handleSetBudget,
 	)
@@ -139,15 +172,54 @@ func (s *Server) registerTools() {
 	// Get budgets
 	s.RegisterTool(
 		server.NewTool("finance.get_budgets").
-			Description("Get all configured budgets").
+			Description("Get each budget envelope's budget, spending and what is left this period").
+			Access(server.AccessSensitive).
 			Build(),
 		s.handleGetBudgets,
 	)
 
+	// Transfer between budget envelopes
+	s.RegisterTool(
+		server.NewTool("finance.transfer_budget").
+			Description("Move money between two budget envelopes for this period").
+			Access(server.AccessSensitive).
+			String("from", "Category to take the money from", true).
+			String("to", "Category to give the money to", true).
+			Number("amount", "Amount to move, in the home currency", true).
+			String("note", "Why the money was moved", false).
+			Build(),
+		s.handleTransferBudget,
+	)
+
+	// Cash-flow forecast
+	s.RegisterTool(
+		server.NewTool("finance.forecast").
+			Description("Forecast each cash account's balance from recurring and planned payments, with low balance warnings").
+			Access(server.AccessSensitive).
+			Integer("days", "Days to forecast, 30 to 90 (default 60)", false).
+			Build(),
+		s.handleForecast,
+	)
+
+	// Plan a one-off expense
+	s.RegisterTool(
+		server.NewTool("finance.plan_expense").
+			Description("Add a one-off expected payment to the cash-flow forecast").
+			Access(server.AccessSensitive).
+			String("name", "What the payment is for", true).
+			Number("amount", "Amount in the home currency; negative for money coming in", true).
+			String("date", "Date expected (YYYY-MM-DD)", true).
+			String("account_id", "Account it is paid from (default main checking account)", false).
+			String("category", "Spending category", false).
+			Build(),
+		s.handlePlanExpense,
+	)
+
 	// Create link token (for connecting new accounts)
 	s.RegisterTool(
 		server.NewTool("finance.create_link_token").
 			Description("Create a Plaid Link token to connect a new bank account").
+			Access(server.AccessSensitive).
 			String("user_id", "User identifier", true).
 			Build(),
 		s.handleCreateLinkToken,
@@ -157,11 +229,57 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("finance.search").
 			Description("Search transactions by merchant or description").
+			Access(server.AccessSensitive).
 			String("query", "Search query", true).
 			Integer("limit", "Max results (default 20)", false).
 			Build(),
 		s.handleSearchTransactions,
 	)
+
+	// Recategorize a transaction
+	s.RegisterTool(
+		server.NewTool("finance.recategorize").
+			Description("Move a transaction to another category; repeating a correction for a merchant creates a rule").
+			Access(server.AccessSensitive).
+			String("transaction_id", "Transaction ID", true).
+			String("category", "New spending category", true).
+			Build(),
+		s.handleRecategorize,
+	)
+
+	// Split a transaction across categories and hats
+	s.RegisterTool(
+		server.NewTool("finance.split_transaction").
+			Description("Split a transaction across categories and hats, such as a shop shared between home and a parent").
+			Access(server.AccessSensitive).
+			String("transaction_id", "Transaction ID", true).
+			String("splits", "Comma-separated amount:category[:hat] parts adding up to the transaction, e.g. 120:groceries:home,80:shopping:parent; empty removes the split", false).
+			String("currency", "Currency of the transaction, if not the home currency", false).
+			Build(),
+		s.handleSplitTransaction,
+	)
+
+	// Expect an expense to be paid back
+	s.RegisterTool(
+		server.NewTool("finance.expect_reimbursement").
+			Description("Mark an expense as reimbursable; it is matched to the credit that pays it back").
+			Access(server.AccessSensitive).
+			String("transaction_id", "Transaction ID of the expense", true).
+			Number("amount", "Amount expected back (default the whole expense)", false).
+			String("payer", "Who pays it back, e.g. an employer", false).
+			String("note", "What the expense was for", false).
+			Build(),
+		s.handleExpectReimbursement,
+	)
+
+	// List reimbursements
+	s.RegisterTool(
+		server.NewTool("finance.reimbursements").
+			Description("List expected reimbursements, whether each has been paid back, and the total still owed").
+			Access(server.AccessSensitive).
+			Build(),
+		s.handleGetReimbursements,
+	)
 }
 
 func (s *Server) registerResources() {
@@ -172,6 +290,7 @@ func (s *Server) registerResources() {
 			Name:        "Financial Summary",
 			Description: "Overview of accounts, balances, and recent activity",
 			MimeType:    "application/json",
+			Access:      server.AccessSensitive,
 		},
 		s.handleSummaryResource,
 	)
@@ -183,9 +302,22 @@ func (s *Server) registerResources() {
 			Name:        "Monthly Report",
 			Description: "Spending breakdown for the current month",
 			MimeType:    "application/json",
+			Access:      server.AccessSensitive,
 		},
 		s.handleMonthlyResource,
 	)
+
+	// Tax-year report resource
+	s.RegisterResource(
+		server.Resource{
+			URI:         "finance://report",
+			Name:        "Tax-Year Report",
+			Description: "Business, charitable, medical and deductible spending for a tax year with linked receipts; add ?year=2025 and format=csv or html",
+			MimeType:    "application/json",
+			Access:      server.AccessSensitive,
+		},
+		s.handleReportResource,
+	)
 }
 
 // Tool handlers
@@ -228,13 +360,25 @@ func (s *Server) handleGetBalance(ctx context.Context, raw json.RawMessage) (*se
 	assets, liabilities, netWorth := s.space.GetNetWorth()
 	totalBalance := s.space.GetTotalBalance()
 
-	return server.JSONResult(map[string]interface{}{
+	result := map[string]interface{}{
 		"total_balance": totalBalance,
 		"assets":        assets,
 		"liabilities":   liabilities,
 		"net_worth":     netWorth,
 		"updated_at":    time.Now().Format(time.RFC3339),
-	})
+	}
+	// Say which currency the totals are in and which conversions to trust
+	if report := s.space.GetNetWorthReport(); report != nil {
+		result["currency"] = report.Currency
+		if len(report.StaleRates) > 0 {
+			result["stale_rates"] = report.StaleRates
+		}
+		if len(report.MissingRates) > 0 {
+			result["missing_rates"] = report.MissingRates
+		}
+	}
+
+	return server.JSONResult(result)
 }
 
 func (s *Server) handleListTransactions(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
@@ -347,6 +491,71 @@ func (s *Server) handleGetRecurring(ctx context.Context, raw json.RawMessage) (*
 	})
 }
 
+func (s *Server) handleGetSubscriptions(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil || !s.space.IsConnected() {
+		return server.ErrorResult("Finance not connected. Connect a bank account first."), nil
+	}
+
+	report := s.space.GetSubscriptions()
+
+	var subscriptions []map[string]interface{}
+	for _, sub := range report.Subscriptions {
+		var history []map[string]interface{}
+		for _, p := range sub.PriceHistory {
+			history = append(history, map[string]interface{}{
+				"date":   p.Date,
+				"amount": p.Amount.Float64(),
+			})
+		}
+		summary := map[string]interface{}{
+			"id":            sub.ID,
+			"name":          sub.Name,
+			"category":      sub.Category,
+			"frequency":     sub.Frequency,
+			"amount":        sub.Amount.Float64(),
+			"currency":      sub.Amount.Currency,
+			"monthly_cost":  sub.MonthlyCost.Float64(),
+			"since":         sub.Since,
+			"last_charged":  sub.LastCharged,
+			"price_history": history,
+			"trial":         sub.Trial,
+		}
+		if sub.Kind != "" {
+			summary["kind"] = sub.Kind
+		}
+		if sub.NextCharge != "" {
+			summary["next_charge"] = sub.NextCharge
+		}
+		if sub.ReceiptItemID != "" {
+			summary["receipt_item_id"] = sub.ReceiptItemID
+		}
+		subscriptions = append(subscriptions, summary)
+	}
+
+	var alerts []map[string]interface{}
+	for _, a := range report.Alerts {
+		alerts = append(alerts, map[string]interface{}{
+			"type":             a.Type,
+			"message":          a.Message,
+			"subscription_ids": a.SubscriptionIDs,
+			"amount":           a.Amount.Float64(),
+		})
+	}
+
+	response := map[string]interface{}{
+		"currency":      report.Currency,
+		"subscriptions": subscriptions,
+		"count":         len(subscriptions),
+		"monthly_total": report.MonthlyTotal.Float64(),
+		"annual_total":  report.AnnualTotal.Float64(),
+		"alerts":        alerts,
+	}
+	if len(report.MissingRates) > 0 {
+		response["missing_rates"] = report.MissingRates
+	}
+	return server.JSONResult(response)
+}
+
 func (s *Server) handleGetInsights(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
 	if s.space == nil || !s.space.IsConnected() {
 		return server.ErrorResult("Finance not connected. Connect a bank account first."), nil
@@ -412,9 +621,18 @@ func (s *Server) handleSetBudget(ctx context.Context, raw json.RawMessage) (*ser
 		return server.ErrorResult("Amount must be positive"), nil
 	}
 
-	s.space.SetBudget(finance.Category(category), amount)
+	rollover := args.Bool("rollover")
+
+	env, err := s.space.SetBudget(finance.Category(category), amount, rollover)
+	if err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to set budget: %v", err)), nil
+	}
 
-	return server.SuccessResult(fmt.Sprintf("Budget set: $%.2f/month for %s", amount, category)), nil
+	message := fmt.Sprintf("Budget set: %s per period for %s", env.Amount, category)
+	if rollover {
+		message += ", rolling over"
+	}
+	return server.SuccessResult(message), nil
 }
 
 func (s *Server) handleGetBudgets(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
@@ -422,20 +640,161 @@ func (s *Server) handleGetBudgets(ctx context.Context, raw json.RawMessage) (*se
 		return server.ErrorResult("Finance not configured"), nil
 	}
 
-	budgets := s.space.GetBudgets()
+	report, err := s.space.GetBudgets()
+	if err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to get budgets: %v", err)), nil
+	}
 
 	var result []map[string]interface{}
-	for cat, amount := range budgets {
+	for _, env := range report.Envelopes {
 		result = append(result, map[string]interface{}{
-			"category": string(cat),
-			"budget":   amount,
+			"category":     string(env.Category),
+			"budget":       env.Budgeted.Float64(),
+			"carried_over": env.CarriedOver.Float64(),
+			"transferred":  env.Transferred.Float64(),
+			"spent":        env.Spent.Float64(),
+			"available":    env.Available.Float64(),
+			"percent_used": env.PercentUsed,
+			"rollover":     env.Rollover,
 		})
 	}
 
-	return server.JSONResult(map[string]interface{}{
-		"budgets": result,
-		"count":   len(result),
-	})
+	response := map[string]interface{}{
+		"budgets":      result,
+		"count":        len(result),
+		"period_start": report.PeriodStart,
+		"period_end":   report.PeriodEnd,
+		"currency":     report.Currency,
+		"income":       report.Income.Float64(),
+		"unassigned":   report.Unassigned.Float64(),
+	}
+	if len(report.StaleRates) > 0 {
+		response["stale_rates"] = report.StaleRates
+	}
+	return server.JSONResult(response)
+}
+
+func (s *Server) handleTransferBudget(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil {
+		return server.ErrorResult("Finance not configured"), nil
+	}
+
+	args := server.ParseArgs(raw)
+	from, err := args.RequireString("from")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	to, err := args.RequireString("to")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	amount := args.Float("amount")
+	if amount <= 0 {
+		return server.ErrorResult("Amount must be positive"), nil
+	}
+
+	transfer, err := s.space.TransferBudget(finance.Category(from), finance.Category(to), amount, args.String("note"))
+	if err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to transfer budget: %v", err)), nil
+	}
+
+	return server.SuccessResult(fmt.Sprintf("Moved %s from %s to %s", transfer.Amount, from, to)), nil
+}
+
+func (s *Server) handleForecast(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil {
+		return server.ErrorResult("Finance not configured"), nil
+	}
+
+	args := server.ParseArgs(raw)
+	forecast, err := s.space.Forecast(args.IntDefault("days", finance.DefaultForecastDays))
+	if err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to forecast: %v", err)), nil
+	}
+
+	// Summarize each account with the days something is due rather than
+	// every day's balance
+	var accounts []map[string]interface{}
+	for _, acct := range forecast.Accounts {
+		var upcoming []map[string]interface{}
+		for _, day := range acct.Days {
+			for _, e := range day.Entries {
+				upcoming = append(upcoming, map[string]interface{}{
+					"date":    day.Date,
+					"name":    e.Name,
+					"amount":  e.Amount.Float64(),
+					"source":  e.Source,
+					"balance": day.Balance.Float64(),
+				})
+			}
+		}
+		summary := map[string]interface{}{
+			"account_id": acct.AccountID,
+			"name":       acct.Name,
+			"currency":   acct.Balance.Currency,
+			"balance":    acct.Balance.Float64(),
+			"lowest":     acct.Lowest.Float64(),
+			"lowest_on":  acct.LowestOn,
+			"upcoming":   upcoming,
+		}
+		if len(acct.Days) > 0 {
+			summary["ending_balance"] = acct.Days[len(acct.Days)-1].Balance.Float64()
+		}
+		if acct.NextPayday != "" {
+			summary["next_payday"] = acct.NextPayday
+		}
+		accounts = append(accounts, summary)
+	}
+
+	var warnings []string
+	for _, w := range forecast.Warnings {
+		warning := fmt.Sprintf("%s drops to %s on %s", w.AccountName, w.Balance, w.Date)
+		if w.Payday != "" {
+			warning += fmt.Sprintf(", before payday on %s", w.Payday)
+		}
+		warnings = append(warnings, warning)
+	}
+
+	response := map[string]interface{}{
+		"from":      forecast.From,
+		"to":        forecast.To,
+		"threshold": forecast.Threshold.Float64(),
+		"accounts":  accounts,
+		"warnings":  warnings,
+	}
+	if len(forecast.MissingRates) > 0 {
+		response["missing_rates"] = forecast.MissingRates
+	}
+	return server.JSONResult(response)
+}
+
+func (s *Server) handlePlanExpense(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil {
+		return server.ErrorResult("Finance not configured"), nil
+	}
+
+	args := server.ParseArgs(raw)
+	name, err := args.RequireString("name")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	date, err := args.RequireString("date")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	planned := &finance.PlannedExpense{
+		Name:      name,
+		Amount:    finance.NewMoney(args.Float("amount"), s.space.HomeCurrency()),
+		Date:      date,
+		AccountID: args.String("account_id"),
+		Category:  finance.Category(args.String("category")),
+	}
+	if err := s.space.AddPlannedExpense(planned); err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to plan expense: %v", err)), nil
+	}
+
+	return server.SuccessResult(fmt.Sprintf("Planned %s of %s on %s", name, planned.Amount, date)), nil
 }
 
 func (s *Server) handleCreateLinkToken(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
@@ -460,6 +819,164 @@ func (s *Server) handleCreateLinkToken(ctx context.Context, raw json.RawMessage)
 	})
 }
 
+func (s *Server) handleRecategorize(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil {
+		return server.ErrorResult("Finance not configured"), nil
+	}
+
+	args := server.ParseArgs(raw)
+	transactionID, err := args.RequireString("transaction_id")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	category, err := args.RequireString("category")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	result, err := s.space.Recategorize(transactionID, finance.Category(category))
+	if err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to recategorize: %v", err)), nil
+	}
+
+	response := map[string]interface{}{
+		"transaction_id": transactionID,
+		"previous":       string(result.Previous),
+		"category":       category,
+	}
+	if result.LearnedRule != nil {
+		response["learned_rule"] = result.LearnedRule
+	}
+	return server.JSONResult(response)
+}
+
+func (s *Server) handleSplitTransaction(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil {
+		return server.ErrorResult("Finance not configured"), nil
+	}
+
+	args := server.ParseArgs(raw)
+	transactionID, err := args.RequireString("transaction_id")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	currency := args.String("currency")
+	if currency == "" {
+		currency = s.space.HomeCurrency()
+	}
+	splits, err := parseSplits(args.String("splits"), currency)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	tx, err := s.space.SplitTransaction(transactionID, splits)
+	if err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to split transaction: %v", err)), nil
+	}
+	if len(tx.Splits) == 0 {
+		return server.SuccessResult(fmt.Sprintf("Removed the split from %s", tx.Name)), nil
+	}
+
+	return server.JSONResult(map[string]interface{}{
+		"transaction_id": transactionID,
+		"name":           tx.Name,
+		"amount":         tx.Amount,
+		"splits":         tx.Splits,
+	})
+}
+
+// parseSplits parses comma-separated amount:category[:hat] parts, with
+// amounts in currency
+func parseSplits(value, currency string) ([]finance.Split, error) {
+	var splits []finance.Split
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.SplitN(part, ":", 3)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid split %q: want amount:category[:hat]", part)
+		}
+		amount, err := finance.ParseMoney(fields[0], currency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid split amount %q", fields[0])
+		}
+		split := finance.Split{Amount: amount, Category: finance.Category(strings.TrimSpace(fields[1]))}
+		if len(fields) == 3 {
+			split.HatID = core.HatID(strings.TrimSpace(fields[2]))
+		}
+		splits = append(splits, split)
+	}
+	return splits, nil
+}
+
+func (s *Server) handleExpectReimbursement(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil {
+		return server.ErrorResult("Finance not configured"), nil
+	}
+
+	args := server.ParseArgs(raw)
+	transactionID, err := args.RequireString("transaction_id")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	amount := args.Float("amount")
+	if amount < 0 {
+		return server.ErrorResult("Amount must not be negative"), nil
+	}
+
+	r, err := s.space.ExpectReimbursement(transactionID, amount, args.String("payer"), args.String("note"))
+	if err != nil {
+		return server.ErrorResult(fmt.Sprintf("Failed to expect reimbursement: %v", err)), nil
+	}
+
+	if r.Status == finance.ReimbursementReceived {
+		return server.SuccessResult(fmt.Sprintf("Expecting %s back; already received on %s", r.Amount, r.ReceivedOn)), nil
+	}
+	return server.SuccessResult(fmt.Sprintf("Expecting %s back", r.Amount)), nil
+}
+
+func (s *Server) handleGetReimbursements(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	if s.space == nil || !s.space.IsConnected() {
+		return server.ErrorResult("Finance not connected. Connect a bank account first."), nil
+	}
+
+	report := s.space.GetReimbursements()
+
+	var reimbursements []map[string]interface{}
+	for _, r := range report.Reimbursements {
+		summary := map[string]interface{}{
+			"id":             r.ID,
+			"transaction_id": r.TransactionID,
+			"amount":         r.Amount.Float64(),
+			"currency":       r.Amount.Currency,
+			"status":         r.Status,
+		}
+		if r.Payer != "" {
+			summary["payer"] = r.Payer
+		}
+		if r.Note != "" {
+			summary["note"] = r.Note
+		}
+		if r.CreditID != "" {
+			summary["credit_id"] = r.CreditID
+			summary["received_on"] = r.ReceivedOn
+		}
+		reimbursements = append(reimbursements, summary)
+	}
+
+	result := map[string]interface{}{
+		"currency":       report.Currency,
+		"reimbursements": reimbursements,
+		"outstanding":    report.Outstanding.Float64(),
+	}
+	if len(report.MissingRates) > 0 {
+		result["missing_rates"] = report.MissingRates
+	}
+	return server.JSONResult(result)
+}
+
 func (s *Server) handleSearchTransactions(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
 	if s.space == nil || !s.space.IsConnected() {
 		return server.ErrorResult("Finance not connected. Connect a bank account first."), nil
@@ -532,6 +1049,12 @@ func (s *Server) handleSummaryResource(ctx context.Context, uri string) (*server
 		"recurring_transactions": len(recurring),
 		"last_sync":              s.space.GetSyncStatus().LastSync.Format(time.RFC3339),
 	}
+	if report := s.space.GetNetWorthReport(); report != nil {
+		summary["currency"] = report.Currency
+		if len(report.StaleRates) > 0 {
+			summary["stale_rates"] = report.StaleRates
+		}
+	}
 
 	data, _ := json.MarshalIndent(summary, "", "  ")
 	return &server.ResourceContent{
@@ -594,3 +1117,48 @@ func indexOfString(s, substr string) int {
 	}
 	return -1
 }
+
+// handleReportResource reports a tax year, last year unless the URI's
+// query sets one, as JSON, CSV or printable HTML
+func (s *Server) handleReportResource(ctx context.Context, uri string) (*server.ResourceContent, error) {
+	if s.space == nil || !s.space.IsConnected() {
+		return &server.ResourceContent{
+			URI:      uri,
+			MimeType: "application/json",
+			Text:     `{"status": "not_connected"}`,
+		}, nil
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report URI: %w", err)
+	}
+	query := parsed.Query()
+	year := time.Now().Year() - 1
+	if v := query.Get("year"); v != "" {
+		if year, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid year %q", v)
+		}
+	}
+
+	report, err := s.space.TaxReport(year)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format := query.Get("format"); format {
+	case "csv":
+		var sb strings.Builder
+		if err := report.WriteCSV(&sb); err != nil {
+			return nil, err
+		}
+		return &server.ResourceContent{URI: uri, MimeType: "text/csv", Text: sb.String()}, nil
+	case "html":
+		return &server.ResourceContent{URI: uri, MimeType: "text/html", Text: report.RenderHTML()}, nil
+	case "", "json":
+		data, _ := json.MarshalIndent(report, "", "  ")
+		return &server.ResourceContent{URI: uri, MimeType: "application/json", Text: string(data)}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q: use json, csv or html", format)
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/spaces"
 )
@@ -82,15 +84,26 @@ type MockFinanceSpace struct {
 	GetAccountsFunc              func() []finance.Account
 	GetTotalBalanceFunc          func() float64
 	GetNetWorthFunc              func() (assets, liabilities, netWorth float64)
+	GetNetWorthReportFunc        func() *finance.NetWorth
 	GetTransactionsFunc          func(filter finance.TransactionFilter) []*finance.CategorizedTransaction
 	GetSpendingSummaryFunc       func(period string) *finance.SpendingSummary
 	GetRecurringTransactionsFunc func() []*finance.RecurringTransaction
+	GetSubscriptionsFunc         func() *finance.SubscriptionReport
 	GetInsightsFunc              func() []*finance.Insight
 	GetConnectionsFunc           func() []*finance.Connection
-	SetBudgetFunc                func(category finance.Category, amount float64)
-	GetBudgetsFunc               func() map[finance.Category]float64
+	SetBudgetFunc                func(category finance.Category, amount float64, rollover bool) (*finance.Envelope, error)
+	GetBudgetsFunc               func() (*finance.BudgetReport, error)
+	TransferBudgetFunc           func(from, to finance.Category, amount float64, note string) (*finance.BudgetTransfer, error)
+	ForecastFunc                 func(days int) (*finance.Forecast, error)
+	AddPlannedExpenseFunc        func(p *finance.PlannedExpense) error
+	HomeCurrencyFunc             func() string
 	CreateLinkTokenFunc          func(ctx context.Context, userID string) (string, error)
 	GetSyncStatusFunc            func() spaces.SyncStatus
+	RecategorizeFunc             func(transactionID string, category finance.Category) (*finance.Recategorization, error)
+	SplitTransactionFunc         func(transactionID string, splits []finance.Split) (*finance.CategorizedTransaction, error)
+	ExpectReimbursementFunc      func(transactionID string, amount float64, payer, note string) (*finance.Reimbursement, error)
+	GetReimbursementsFunc        func() *finance.ReimbursementReport
+	TaxReportFunc                func(year int) (*finance.TaxReport, error)
 }
 
 func (m *MockFinanceSpace) IsConnected() bool {
@@ -121,6 +134,13 @@ func (m *MockFinanceSpace) GetNetWorth() (assets, liabilities, netWorth float64)
 	return 20000.00, 5000.00, 15000.00
 }
 
+func (m *MockFinanceSpace) GetNetWorthReport() *finance.NetWorth {
+	if m.GetNetWorthReportFunc != nil {
+		return m.GetNetWorthReportFunc()
+	}
+	return nil
+}
+
 func (m *MockFinanceSpace) GetTransactions(filter finance.TransactionFilter) []*finance.CategorizedTransaction {
 	if m.GetTransactionsFunc != nil {
 		return m.GetTransactionsFunc(filter)
@@ -151,6 +171,13 @@ func (m *MockFinanceSpace) GetRecurringTransactions() []*finance.RecurringTransa
 	return sampleRecurring()
 }
 
+func (m *MockFinanceSpace) GetSubscriptions() *finance.SubscriptionReport {
+	if m.GetSubscriptionsFunc != nil {
+		return m.GetSubscriptionsFunc()
+	}
+	return sampleSubscriptions()
+}
+
 func (m *MockFinanceSpace) GetInsights() []*finance.Insight {
 	if m.GetInsightsFunc != nil {
 		return m.GetInsightsFunc()
@@ -165,20 +192,46 @@ func (m *MockFinanceSpace) GetConnections() []*finance.Connection {
 	return sampleConnections()
 }
 
-func (m *MockFinanceSpace) SetBudget(category finance.Category, amount float64) {
+func (m *MockFinanceSpace) SetBudget(category finance.Category, amount float64, rollover bool) (*finance.Envelope, error) {
 	if m.SetBudgetFunc != nil {
-		m.SetBudgetFunc(category, amount)
+		return m.SetBudgetFunc(category, amount, rollover)
 	}
+	return &finance.Envelope{Category: category, Amount: finance.NewMoney(amount, "USD"), Rollover: rollover}, nil
 }
 
-func (m *MockFinanceSpace) GetBudgets() map[finance.Category]float64 {
+func (m *MockFinanceSpace) GetBudgets() (*finance.BudgetReport, error) {
 	if m.GetBudgetsFunc != nil {
 		return m.GetBudgetsFunc()
 	}
-	return map[finance.Category]float64{
-		finance.CategoryGroceries: 600.00,
-		finance.CategoryDining:    400.00,
+	return sampleBudgetReport(), nil
+}
+
+func (m *MockFinanceSpace) TransferBudget(from, to finance.Category, amount float64, note string) (*finance.BudgetTransfer, error) {
+	if m.TransferBudgetFunc != nil {
+		return m.TransferBudgetFunc(from, to, amount, note)
 	}
+	return &finance.BudgetTransfer{ID: "btr_1", From: from, To: to, Amount: finance.NewMoney(amount, "USD"), Note: note}, nil
+}
+
+func (m *MockFinanceSpace) Forecast(days int) (*finance.Forecast, error) {
+	if m.ForecastFunc != nil {
+		return m.ForecastFunc(days)
+	}
+	return sampleForecast(), nil
+}
+
+func (m *MockFinanceSpace) AddPlannedExpense(p *finance.PlannedExpense) error {
+	if m.AddPlannedExpenseFunc != nil {
+		return m.AddPlannedExpenseFunc(p)
+	}
+	return nil
+}
+
+func (m *MockFinanceSpace) HomeCurrency() string {
+	if m.HomeCurrencyFunc != nil {
+		return m.HomeCurrencyFunc()
+	}
+	return "USD"
 }
 
 func (m *MockFinanceSpace) CreateLinkToken(ctx context.Context, userID string) (string, error) {
@@ -199,6 +252,56 @@ func (m *MockFinanceSpace) GetSyncStatus() spaces.SyncStatus {
 	}
 }
 
+func (m *MockFinanceSpace) Recategorize(transactionID string, category finance.Category) (*finance.Recategorization, error) {
+	if m.RecategorizeFunc != nil {
+		return m.RecategorizeFunc(transactionID, category)
+	}
+	tx := &finance.CategorizedTransaction{QLCategory: category, UserCategorized: true}
+	tx.TransactionID = transactionID
+	return &finance.Recategorization{Transaction: tx, Previous: finance.CategoryOther}, nil
+}
+
+func (m *MockFinanceSpace) SplitTransaction(transactionID string, splits []finance.Split) (*finance.CategorizedTransaction, error) {
+	if m.SplitTransactionFunc != nil {
+		return m.SplitTransactionFunc(transactionID, splits)
+	}
+	tx := &finance.CategorizedTransaction{QLCategory: finance.CategoryGroceries, Splits: splits}
+	tx.TransactionID = transactionID
+	tx.Name = "Costco"
+	tx.Amount = 200
+	return tx, nil
+}
+
+func (m *MockFinanceSpace) ExpectReimbursement(transactionID string, amount float64, payer, note string) (*finance.Reimbursement, error) {
+	if m.ExpectReimbursementFunc != nil {
+		return m.ExpectReimbursementFunc(transactionID, amount, payer, note)
+	}
+	return &finance.Reimbursement{
+		ID:            "rmb_1",
+		TransactionID: transactionID,
+		Amount:        finance.NewMoney(amount, "USD"),
+		Payer:         payer,
+		Note:          note,
+		Status:        finance.ReimbursementPending,
+	}, nil
+}
+
+func (m *MockFinanceSpace) GetReimbursements() *finance.ReimbursementReport {
+	if m.GetReimbursementsFunc != nil {
+		return m.GetReimbursementsFunc()
+	}
+	return sampleReimbursements()
+}
+
+func (m *MockFinanceSpace) TaxReport(year int) (*finance.TaxReport, error) {
+	if m.TaxReportFunc != nil {
+		return m.TaxReportFunc(year)
+	}
+	report := sampleTaxReport()
+	report.Year = year
+	return report, nil
+}
+
 // Sample data helpers
 func sampleAccounts() []finance.Account {
 	return []finance.Account{
@@ -299,6 +402,145 @@ func sampleInsights() []*finance.Insight {
 	}
 }
 
+func sampleForecast() *finance.Forecast {
+	usd := func(amount float64) finance.Money { return finance.NewMoney(amount, "USD") }
+	return &finance.Forecast{
+		From:      "2026-10-18",
+		To:        "2026-11-16",
+		Days:      30,
+		Threshold: usd(0),
+		Accounts: []finance.AccountForecast{{
+			AccountID:  "acc_1",
+			Name:       "Checking",
+			Balance:    usd(1400),
+			Lowest:     usd(-100),
+			LowestOn:   "2026-11-01",
+			NextPayday: "2026-11-03",
+			Days: []finance.ForecastDay{
+				{Date: "2026-10-18", Balance: usd(1400)},
+				{Date: "2026-11-01", Balance: usd(-100), Entries: []finance.ForecastEntry{
+					{Name: "Rent", Amount: usd(1500), Source: "recurring", SourceID: "rec_rent"},
+				}},
+				{Date: "2026-11-03", Balance: usd(1950), Entries: []finance.ForecastEntry{
+					{Name: "Payroll", Amount: usd(-2050), Source: "recurring", SourceID: "rec_payroll"},
+				}},
+			},
+		}},
+		Warnings: []finance.ForecastWarning{{
+			AccountID:   "acc_1",
+			AccountName: "Checking",
+			Date:        "2026-11-01",
+			Balance:     usd(-100),
+			Threshold:   usd(0),
+			Payday:      "2026-11-03",
+			Bills:       []string{"Rent"},
+		}},
+	}
+}
+
+func sampleSubscriptions() *finance.SubscriptionReport {
+	usd := func(amount float64) finance.Money { return finance.NewMoney(amount, "USD") }
+	return &finance.SubscriptionReport{
+		Currency: "USD",
+		Subscriptions: []*finance.Subscription{{
+			ID:          "rec_netflix",
+			Name:        "Netflix",
+			Category:    finance.CategoryEntertainment,
+			Kind:        "video streaming",
+			Frequency:   "monthly",
+			Amount:      usd(17.99),
+			MonthlyCost: usd(17.99),
+			Since:       "2025-12-05",
+			LastCharged: "2026-10-05",
+			NextCharge:  "2026-11-05",
+			PriceHistory: []finance.PricePoint{
+				{Date: "2025-12-05", Amount: usd(15.49)},
+				{Date: "2026-10-05", Amount: usd(17.99)},
+			},
+			ReceiptItemID: "mail_netflix",
+		}},
+		MonthlyTotal: usd(17.99),
+		AnnualTotal:  usd(215.88),
+		Alerts: []finance.SubscriptionAlert{{
+			Type:            finance.SubscriptionPriceIncrease,
+			SubscriptionIDs: []string{"rec_netflix"},
+			Message:         "Netflix went up from $15.49 to $17.99 on 2026-10-05",
+			Date:            "2026-10-05",
+			Amount:          usd(2.50),
+		}},
+	}
+}
+
+func sampleReimbursements() *finance.ReimbursementReport {
+	usd := func(amount float64) finance.Money { return finance.NewMoney(amount, "USD") }
+	return &finance.ReimbursementReport{
+		Currency: "USD",
+		Reimbursements: []*finance.Reimbursement{
+			{
+				ID:            "rmb_hotel",
+				TransactionID: "tx_hotel",
+				Amount:        usd(300),
+				Payer:         "Acme",
+				Note:          "Conference",
+				Status:        finance.ReimbursementReceived,
+				CreditID:      "tx_acme",
+				ReceivedOn:    "2026-09-20",
+			},
+			{
+				ID:            "rmb_dinner",
+				TransactionID: "tx_dinner",
+				Amount:        usd(45),
+				Payer:         "Sam",
+				Status:        finance.ReimbursementPending,
+			},
+		},
+		Outstanding: usd(45),
+	}
+}
+
+func sampleTaxReport() *finance.TaxReport {
+	usd := func(amount float64) finance.Money { return finance.NewMoney(amount, "USD") }
+	return &finance.TaxReport{
+		Year:     2025,
+		Currency: "USD",
+		Sections: []*finance.TaxSection{
+			{
+				Kind:  finance.TaxCharitable,
+				Title: "Charitable giving",
+				Total: usd(100),
+				Entries: []*finance.TaxEntry{{
+					TransactionID:  "tx_redcross",
+					Date:           "2025-05-01",
+					Name:           "Red Cross",
+					Category:       finance.CategoryCharity,
+					Amount:         usd(100),
+					Original:       usd(100),
+					ReceiptItemID:  "mail_redcross",
+					ReceiptSubject: "Thank you for your donation",
+				}},
+			},
+		},
+		Total: usd(100),
+	}
+}
+
+func sampleBudgetReport() *finance.BudgetReport {
+	usd := func(amount float64) finance.Money { return finance.NewMoney(amount, "USD") }
+	return &finance.BudgetReport{
+		PeriodStart: "2026-10-01",
+		PeriodEnd:   "2026-10-31",
+		Currency:    "USD",
+		Income:      usd(4000),
+		Budgeted:    usd(1000),
+		Spent:       usd(450),
+		Unassigned:  usd(3000),
+		Envelopes: []finance.EnvelopeStatus{
+			{Category: finance.CategoryGroceries, Budgeted: usd(600), CarriedOver: usd(50), Spent: usd(325), Available: usd(325), PercentUsed: 50, Rollover: true},
+			{Category: finance.CategoryDining, Budgeted: usd(400), Spent: usd(125), Available: usd(275), PercentUsed: 31.25},
+		},
+	}
+}
+
 func sampleConnections() []*finance.Connection {
 	return []*finance.Connection{
 		{
@@ -796,15 +1038,20 @@ func TestFinanceServer_SetBudget(t *testing.T) {
 			args: map[string]interface{}{
 				"category": "groceries",
 				"amount":   500.00,
+				"rollover": true,
 			},
 			setup: func(m *MockFinanceSpace) {
-				m.SetBudgetFunc = func(category finance.Category, amount float64) {
+				m.SetBudgetFunc = func(category finance.Category, amount float64, rollover bool) (*finance.Envelope, error) {
 					if category != finance.CategoryGroceries {
 						t.Errorf("expected groceries category, got %s", category)
 					}
 					if amount != 500.00 {
 						t.Errorf("expected amount 500, got %.2f", amount)
 					}
+					if !rollover {
+						t.Error("expected rollover")
+					}
+					return &finance.Envelope{Category: category, Amount: finance.NewMoney(amount, "USD"), Rollover: rollover}, nil
 				}
 			},
 			wantErr: false,
@@ -832,6 +1079,19 @@ func TestFinanceServer_SetBudget(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown category",
+			args: map[string]interface{}{
+				"category": "snacks",
+				"amount":   50.00,
+			},
+			setup: func(m *MockFinanceSpace) {
+				m.SetBudgetFunc = func(category finance.Category, amount float64, rollover bool) (*finance.Envelope, error) {
+					return nil, finance.ErrInvalidBudget
+				}
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -878,8 +1138,8 @@ func TestFinanceServer_GetBudgets(t *testing.T) {
 		{
 			name: "no budgets set",
 			setup: func(m *MockFinanceSpace) {
-				m.GetBudgetsFunc = func() map[finance.Category]float64 {
-					return map[finance.Category]float64{}
+				m.GetBudgetsFunc = func() (*finance.BudgetReport, error) {
+					return &finance.BudgetReport{Currency: "USD"}, nil
 				}
 			},
 			wantErr: false,
@@ -916,6 +1176,214 @@ func TestFinanceServer_GetBudgets(t *testing.T) {
 	}
 }
 
+func TestFinanceServer_GetBudgets_Report(t *testing.T) {
+	srv := NewWithMockSpace(&MockFinanceSpace{})
+
+	result, err := srv.handleGetBudgets(context.Background(), []byte("{}"))
+	if err != nil || result.IsError {
+		t.Fatalf("handleGetBudgets() = %v, %v", result, err)
+	}
+
+	var response struct {
+		Budgets []struct {
+			Category    string  `json:"category"`
+			Budget      float64 `json:"budget"`
+			CarriedOver float64 `json:"carried_over"`
+			Available   float64 `json:"available"`
+			Rollover    bool    `json:"rollover"`
+		} `json:"budgets"`
+		PeriodStart string  `json:"period_start"`
+		Unassigned  float64 `json:"unassigned"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response.PeriodStart != "2026-10-01" || response.Unassigned != 3000 || len(response.Budgets) != 2 {
+		t.Fatalf("response = %+v", response)
+	}
+	if b := response.Budgets[0]; b.Category != "groceries" || b.Budget != 600 || b.CarriedOver != 50 || b.Available != 325 || !b.Rollover {
+		t.Errorf("groceries = %+v", b)
+	}
+}
+
+func TestFinanceServer_TransferBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		setup   func(*MockFinanceSpace)
+		wantErr bool
+	}{
+		{
+			name: "transfer",
+			args: map[string]interface{}{"from": "dining", "to": "groceries", "amount": 40.00, "note": "Cooking more"},
+			setup: func(m *MockFinanceSpace) {
+				m.TransferBudgetFunc = func(from, to finance.Category, amount float64, note string) (*finance.BudgetTransfer, error) {
+					if from != finance.CategoryDining || to != finance.CategoryGroceries || amount != 40 || note != "Cooking more" {
+						t.Errorf("TransferBudget(%s, %s, %v, %q)", from, to, amount, note)
+					}
+					return &finance.BudgetTransfer{From: from, To: to, Amount: finance.NewMoney(amount, "USD")}, nil
+				}
+			},
+		},
+		{
+			name:    "missing to",
+			args:    map[string]interface{}{"from": "dining", "amount": 40.00},
+			wantErr: true,
+		},
+		{
+			name:    "zero amount",
+			args:    map[string]interface{}{"from": "dining", "to": "groceries", "amount": 0},
+			wantErr: true,
+		},
+		{
+			name: "not enough available",
+			args: map[string]interface{}{"from": "dining", "to": "groceries", "amount": 400.00},
+			setup: func(m *MockFinanceSpace) {
+				m.TransferBudgetFunc = func(from, to finance.Category, amount float64, note string) (*finance.BudgetTransfer, error) {
+					return nil, finance.ErrInvalidBudget
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockFinanceSpace{}
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			srv := NewWithMockSpace(mock)
+
+			argsJSON, _ := json.Marshal(tt.args)
+			result, err := srv.handleTransferBudget(context.Background(), argsJSON)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("IsError = %v, want %v: %s", result.IsError, tt.wantErr, result.Content[0].Text)
+			}
+		})
+	}
+}
+
+func TestFinanceServer_Subscriptions(t *testing.T) {
+	mock := &MockFinanceSpace{}
+	srv := NewWithMockSpace(mock)
+
+	result, err := srv.handleGetSubscriptions(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+
+	var response struct {
+		Subscriptions []struct {
+			Name          string                   `json:"name"`
+			PriceHistory  []map[string]interface{} `json:"price_history"`
+			ReceiptItemID string                   `json:"receipt_item_id"`
+		} `json:"subscriptions"`
+		MonthlyTotal float64 `json:"monthly_total"`
+		Alerts       []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"alerts"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(response.Subscriptions) != 1 || len(response.Subscriptions[0].PriceHistory) != 2 ||
+		response.Subscriptions[0].ReceiptItemID != "mail_netflix" || response.MonthlyTotal != 17.99 {
+		t.Errorf("response = %+v", response)
+	}
+	if len(response.Alerts) != 1 || response.Alerts[0].Type != "price_increase" {
+		t.Errorf("alerts = %+v", response.Alerts)
+	}
+
+	mock.IsConnectedFunc = func() bool { return false }
+	if result, _ := srv.handleGetSubscriptions(context.Background(), nil); !result.IsError {
+		t.Error("expected an error when not connected")
+	}
+}
+
+func TestFinanceServer_Forecast(t *testing.T) {
+	mock := &MockFinanceSpace{}
+	var gotDays int
+	mock.ForecastFunc = func(days int) (*finance.Forecast, error) {
+		gotDays = days
+		return sampleForecast(), nil
+	}
+	srv := NewWithMockSpace(mock)
+
+	result, err := srv.handleForecast(context.Background(), json.RawMessage(`{"days": 30}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+	if gotDays != 30 {
+		t.Errorf("Forecast(%d), want 30", gotDays)
+	}
+
+	var response struct {
+		Accounts []struct {
+			Upcoming      []map[string]interface{} `json:"upcoming"`
+			EndingBalance float64                  `json:"ending_balance"`
+			NextPayday    string                   `json:"next_payday"`
+		} `json:"accounts"`
+		Warnings []string `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(response.Accounts) != 1 || len(response.Accounts[0].Upcoming) != 2 ||
+		response.Accounts[0].EndingBalance != 1950 || response.Accounts[0].NextPayday != "2026-11-03" {
+		t.Errorf("accounts = %+v", response.Accounts)
+	}
+	want := "Checking drops to -$100.00 on 2026-11-01, before payday on 2026-11-03"
+	if len(response.Warnings) != 1 || response.Warnings[0] != want {
+		t.Errorf("warnings = %v, want [%s]", response.Warnings, want)
+	}
+
+	mock.ForecastFunc = func(days int) (*finance.Forecast, error) {
+		return nil, finance.ErrInvalidForecast
+	}
+	if result, _ := srv.handleForecast(context.Background(), json.RawMessage(`{"days": 365}`)); !result.IsError {
+		t.Error("expected an error for an invalid horizon")
+	}
+}
+
+func TestFinanceServer_PlanExpense(t *testing.T) {
+	mock := &MockFinanceSpace{HomeCurrencyFunc: func() string { return "EUR" }}
+	var got *finance.PlannedExpense
+	mock.AddPlannedExpenseFunc = func(p *finance.PlannedExpense) error {
+		got = p
+		return p.Validate()
+	}
+	srv := NewWithMockSpace(mock)
+
+	result, err := srv.handlePlanExpense(context.Background(),
+		json.RawMessage(`{"name": "Car repair", "amount": 250, "date": "2026-11-05", "category": "transport"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+	if got.Amount != (finance.Money{Minor: 25000, Currency: "EUR"}) || got.Category != finance.CategoryTransport {
+		t.Errorf("planned = %+v", got)
+	}
+
+	for _, args := range []string{`{"amount": 250, "date": "2026-11-05"}`, `{"name": "Gift", "amount": 50, "date": "soon"}`} {
+		if result, _ := srv.handlePlanExpense(context.Background(), json.RawMessage(args)); !result.IsError {
+			t.Errorf("expected an error for %s", args)
+		}
+	}
+}
+
 func TestFinanceServer_CreateLinkToken(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1066,6 +1534,168 @@ func TestFinanceServer_NilSpace(t *testing.T) {
 	}
 }
 
+func TestHandleRecategorize(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        string
+		setup       func(*MockFinanceSpace)
+		wantErr     bool
+		wantLearned bool
+	}{
+		{
+			name: "recategorizes",
+			args: `{"transaction_id": "tx_1", "category": "dining"}`,
+		},
+		{
+			name: "reports learned rule",
+			args: `{"transaction_id": "tx_2", "category": "dining"}`,
+			setup: func(m *MockFinanceSpace) {
+				m.RecategorizeFunc = func(id string, category finance.Category) (*finance.Recategorization, error) {
+					return &finance.Recategorization{
+						Transaction: &finance.CategorizedTransaction{QLCategory: category},
+						Previous:    finance.CategoryShopping,
+						LearnedRule: &finance.CategoryRule{ID: "rule_1", Merchant: "corner cafe", Category: category, Source: finance.RuleSourceLearned},
+					}, nil
+				}
+			},
+			wantLearned: true,
+		},
+		{
+			name:    "missing category",
+			args:    `{"transaction_id": "tx_1"}`,
+			wantErr: true,
+		},
+		{
+			name: "space error",
+			args: `{"transaction_id": "tx_404", "category": "dining"}`,
+			setup: func(m *MockFinanceSpace) {
+				m.RecategorizeFunc = func(id string, category finance.Category) (*finance.Recategorization, error) {
+					return nil, finance.ErrTransactionNotFound
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockFinanceSpace{}
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			srv := NewWithMockSpace(mock)
+
+			result, err := srv.handleRecategorize(context.Background(), []byte(tt.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v", result.IsError, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			text := result.Content[0].Text
+			if strings.Contains(text, "learned_rule") != tt.wantLearned {
+				t.Errorf("learned rule in %s, want %v", text, tt.wantLearned)
+			}
+		})
+	}
+}
+
+func TestFinanceServer_SplitTransaction(t *testing.T) {
+	mock := &MockFinanceSpace{}
+	var gotSplits []finance.Split
+	mock.SplitTransactionFunc = func(id string, splits []finance.Split) (*finance.CategorizedTransaction, error) {
+		gotSplits = splits
+		if id == "tx_404" {
+			return nil, finance.ErrTransactionNotFound
+		}
+		tx := &finance.CategorizedTransaction{Splits: splits}
+		tx.TransactionID = id
+		return tx, nil
+	}
+	srv := NewWithMockSpace(mock)
+
+	result, err := srv.handleSplitTransaction(context.Background(),
+		json.RawMessage(`{"transaction_id": "tx_1", "splits": "120:groceries:home, 80.50:shopping:parent"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+	want := []finance.Split{
+		{Amount: finance.Money{Minor: 12000, Currency: "USD"}, Category: finance.CategoryGroceries, HatID: core.HatHome},
+		{Amount: finance.Money{Minor: 8050, Currency: "USD"}, Category: finance.CategoryShopping, HatID: "parent"},
+	}
+	if !reflect.DeepEqual(gotSplits, want) {
+		t.Errorf("splits = %+v, want %+v", gotSplits, want)
+	}
+
+	// No splits removes the split
+	result, _ = srv.handleSplitTransaction(context.Background(), json.RawMessage(`{"transaction_id": "tx_1"}`))
+	if result.IsError || gotSplits != nil || !strings.Contains(result.Content[0].Text, "Removed") {
+		t.Errorf("removing split: %s, splits %+v", result.Content[0].Text, gotSplits)
+	}
+
+	for _, args := range []string{
+		`{"transaction_id": "tx_1", "splits": "groceries"}`,
+		`{"transaction_id": "tx_1", "splits": "lots:groceries"}`,
+		`{"splits": "120:groceries,80:shopping"}`,
+		`{"transaction_id": "tx_404", "splits": "120:groceries,80:shopping"}`,
+	} {
+		if result, _ := srv.handleSplitTransaction(context.Background(), json.RawMessage(args)); !result.IsError {
+			t.Errorf("%s: expected an error", args)
+		}
+	}
+}
+
+func TestFinanceServer_Reimbursements(t *testing.T) {
+	mock := &MockFinanceSpace{}
+	var gotAmount float64
+	var gotPayer string
+	mock.ExpectReimbursementFunc = func(id string, amount float64, payer, note string) (*finance.Reimbursement, error) {
+		gotAmount, gotPayer = amount, payer
+		return &finance.Reimbursement{ID: "rmb_1", TransactionID: id, Amount: finance.NewMoney(300, "USD"), Status: finance.ReimbursementPending}, nil
+	}
+	srv := NewWithMockSpace(mock)
+
+	result, err := srv.handleExpectReimbursement(context.Background(),
+		json.RawMessage(`{"transaction_id": "tx_hotel", "payer": "Acme"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || gotAmount != 0 || gotPayer != "Acme" || !strings.Contains(result.Content[0].Text, "$300.00") {
+		t.Errorf("expect reimbursement: %s, amount %.2f, payer %q", result.Content[0].Text, gotAmount, gotPayer)
+	}
+	if result, _ := srv.handleExpectReimbursement(context.Background(),
+		json.RawMessage(`{"transaction_id": "tx_hotel", "amount": -5}`)); !result.IsError {
+		t.Error("expected an error for a negative amount")
+	}
+
+	result, err = srv.handleGetReimbursements(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var response struct {
+		Reimbursements []struct {
+			ID       string `json:"id"`
+			Status   string `json:"status"`
+			CreditID string `json:"credit_id"`
+		} `json:"reimbursements"`
+		Outstanding float64 `json:"outstanding"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(response.Reimbursements) != 2 || response.Reimbursements[0].CreditID != "tx_acme" ||
+		response.Reimbursements[1].Status != "pending" || response.Outstanding != 45 {
+		t.Errorf("response = %+v", response)
+	}
+}
+
 func TestFinanceServer_ToolRegistration(t *testing.T) {
 	mock := &MockFinanceSpace{}
 	srv := NewWithMockSpace(mock)
@@ -1076,12 +1706,20 @@ func TestFinanceServer_ToolRegistration(t *testing.T) {
 		"finance.list_transactions",
 		"finance.spending_summary",
 		"finance.recurring",
+		"finance.subscriptions",
 		"finance.insights",
 		"finance.connections",
 		"finance.set_budget",
 		"finance.get_budgets",
+		"finance.transfer_budget",
+		"finance.forecast",
+		"finance.plan_expense",
 		"finance.create_link_token",
 		"finance.search",
+		"finance.recategorize",
+		"finance.split_transaction",
+		"finance.expect_reimbursement",
+		"finance.reimbursements",
 	}
 
 	tools := srv.Registry().ListTools()
@@ -1338,6 +1976,63 @@ func TestFinanceServer_MonthlyResource_NilSpace(t *testing.T) {
 	}
 }
 
+func TestFinanceServer_ReportResource(t *testing.T) {
+	mock := &MockFinanceSpace{}
+	var gotYear int
+	mock.TaxReportFunc = func(year int) (*finance.TaxReport, error) {
+		gotYear = year
+		if year > time.Now().Year() {
+			return nil, finance.ErrInvalidTaxYear
+		}
+		report := sampleTaxReport()
+		report.Year = year
+		return report, nil
+	}
+	srv := NewWithMockSpace(mock)
+	ctx := context.Background()
+
+	result, err := srv.handleReportResource(ctx, "finance://report")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotYear != time.Now().Year()-1 || result.MimeType != "application/json" {
+		t.Errorf("default report: year %d, mime type %s", gotYear, result.MimeType)
+	}
+	var report finance.TaxReport
+	if err := json.Unmarshal([]byte(result.Text), &report); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(report.Sections) != 1 || report.Sections[0].Entries[0].ReceiptItemID != "mail_redcross" {
+		t.Errorf("report = %+v", report)
+	}
+
+	result, err = srv.handleReportResource(ctx, "finance://report?year=2024&format=csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotYear != 2024 || result.MimeType != "text/csv" || !strings.Contains(result.Text, "charitable,2025-05-01,tx_redcross,Red Cross") {
+		t.Errorf("CSV report: year %d, %s\n%s", gotYear, result.MimeType, result.Text)
+	}
+
+	result, err = srv.handleReportResource(ctx, "finance://report?format=html")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.MimeType != "text/html" || !strings.Contains(result.Text, "Charitable giving") {
+		t.Errorf("HTML report: %s", result.MimeType)
+	}
+
+	for _, uri := range []string{
+		"finance://report?year=next",
+		"finance://report?year=2999",
+		"finance://report?format=pdf",
+	} {
+		if _, err := srv.handleReportResource(ctx, uri); err == nil {
+			t.Errorf("%s: expected an error", uri)
+		}
+	}
+}
+
 func TestFinanceServer_ResourceRegistration(t *testing.T) {
 	mock := &MockFinanceSpace{}
 	srv := NewWithMockSpace(mock)
@@ -1347,6 +2042,7 @@ func TestFinanceServer_ResourceRegistration(t *testing.T) {
 	expectedResources := map[string]string{
 		"finance://summary": "Financial Summary",
 		"finance://monthly": "Monthly Report",
+		"finance://report":  "Tax-Year Report",
 	}
 
 	for _, r := range resources {
@@ -1765,6 +2461,34 @@ func TestFinanceServer_GetBalance_WithData(t *testing.T) {
 	}
 }
 
+func TestFinanceServer_GetBalance_StaleRates(t *testing.T) {
+	mock := &MockFinanceSpace{
+		GetNetWorthReportFunc: func() *finance.NetWorth {
+			return &finance.NetWorth{Currency: "EUR", StaleRates: []string{"USD/EUR"}}
+		},
+	}
+
+	srv := NewWithMockSpace(mock)
+	result, err := srv.handleGetBalance(context.Background(), []byte("{}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &response); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if response["currency"] != "EUR" {
+		t.Errorf("expected currency EUR, got %v", response["currency"])
+	}
+	if stale, ok := response["stale_rates"].([]interface{}); !ok || len(stale) != 1 || stale[0] != "USD/EUR" {
+		t.Errorf("expected stale_rates [USD/EUR], got %v", response["stale_rates"])
+	}
+	if _, ok := response["missing_rates"]; ok {
+		t.Errorf("unexpected missing_rates: %v", response["missing_rates"])
+	}
+}
+
 func TestFinanceServer_GetRecurring_WithData(t *testing.T) {
 	mock := &MockFinanceSpace{
 		GetRecurringTransactionsFunc: func() []*finance.RecurringTransaction {
@@ -65,6 +65,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.list_repos").
 			Description("List your GitHub repositories").
+			Access(server.AccessRead).
 			Enum("type", "Repository type", []string{"all", "owner", "public", "private", "member"}, false).
 			Enum("sort", "Sort by", []string{"created", "updated", "pushed", "full_name"}, false).
 			Integer("limit", "Max repos to return (default 30)", false).
@@ -76,6 +77,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.get_repo").
 			Description("Get repository details").
+			Access(server.AccessRead).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			Build(),
@@ -86,6 +88,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.list_issues").
 			Description("List issues for a repository").
+			Access(server.AccessRead).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			Enum("state", "Issue state", []string{"open", "closed", "all"}, false).
@@ -99,6 +102,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.get_issue").
 			Description("Get issue details").
+			Access(server.AccessRead).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			Integer("number", "Issue number", true).
@@ -110,6 +114,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.create_issue").
 			Description("Create a new issue").
+			Access(server.AccessWrite).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			String("title", "Issue title", true).
@@ -123,6 +128,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.list_prs").
 			Description("List pull requests for a repository").
+			Access(server.AccessRead).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			Enum("state", "PR state", []string{"open", "closed", "all"}, false).
@@ -135,6 +141,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.get_pr").
 			Description("Get pull request details").
+			Access(server.AccessRead).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			Integer("number", "PR number", true).
@@ -146,6 +153,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.notifications").
 			Description("Get your GitHub notifications").
+			Access(server.AccessRead).
 			Boolean("unread_only", "Only show unread (default true)", false).
 			Integer("limit", "Max notifications (default 50)", false).
 			Build(),
@@ -156,6 +164,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.get_user").
 			Description("Get user profile").
+			Access(server.AccessRead).
 			String("username", "Username (omit for authenticated user)", false).
 			Build(),
 		s.handleGetUser,
@@ -165,6 +174,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.search_repos").
 			Description("Search GitHub repositories").
+			Access(server.AccessRead).
 			String("query", "Search query", true).
 			Enum("sort", "Sort by", []string{"stars", "forks", "updated", "help-wanted-issues"}, false).
 			Integer("limit", "Max results (default 30)", false).
@@ -176,6 +186,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.search_issues").
 			Description("Search issues and pull requests").
+			Access(server.AccessRead).
 			String("query", "Search query", true).
 			Enum("sort", "Sort by", []string{"comments", "created", "updated"}, false).
 			Integer("limit", "Max results (default 30)", false).
@@ -187,6 +198,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.get_contents").
 			Description("Get file or directory contents from a repository").
+			Access(server.AccessRead).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			String("path", "File or directory path", false).
@@ -199,6 +211,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("github.add_comment").
 			Description("Add a comment to an issue or pull request").
+			Access(server.AccessWrite).
 			String("owner", "Repository owner", true).
 			String("repo", "Repository name", true).
 			Integer("number", "Issue/PR number", true).
@@ -66,6 +66,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.list_messages").
 			Description("List email messages with optional search query").
+			Access(server.AccessRead).
 			String("query", "Gmail search query (e.g., 'is:unread', 'from:user@example.com')", false).
 			Integer("limit", "Maximum number of messages to return (default: 20)", false).
 			Build(),
@@ -76,6 +77,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.get_message").
 			Description("Get full details of an email message").
+			Access(server.AccessRead).
 			String("message_id", "The message ID to retrieve", true).
 			Build(),
 		s.handleGetMessage,
@@ -85,6 +87,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.send_message").
 			Description("Send a new email message").
+			Access(server.AccessWrite).
 			String("to", "Recipient email addresses (comma-separated)", true).
 			String("subject", "Email subject", true).
 			String("body", "Email body content", true).
@@ -97,6 +100,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.reply").
 			Description("Reply to an existing email message").
+			Access(server.AccessWrite).
 			String("message_id", "The message ID to reply to", true).
 			String("body", "Reply body content", true).
 			Boolean("reply_all", "Reply to all recipients (default: false)", false).
@@ -108,6 +112,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.archive").
 			Description("Archive an email message (remove from inbox)").
+			Access(server.AccessWrite).
 			String("message_id", "The message ID to archive", true).
 			Build(),
 		s.handleArchive,
@@ -117,6 +122,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.trash").
 			Description("Move an email message to trash").
+			Access(server.AccessWrite).
 			String("message_id", "The message ID to trash", true).
 			Build(),
 		s.handleTrash,
@@ -126,6 +132,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.star").
 			Description("Star or unstar an email message").
+			Access(server.AccessWrite).
 			String("message_id", "The message ID", true).
 			Boolean("starred", "Whether to star (true) or unstar (false)", true).
 			Build(),
@@ -136,6 +143,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.mark_read").
 			Description("Mark an email message as read or unread").
+			Access(server.AccessWrite).
 			String("message_id", "The message ID", true).
 			Boolean("read", "Whether to mark as read (true) or unread (false)", true).
 			Build(),
@@ -146,6 +154,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.label").
 			Description("Add or remove a label from an email message").
+			Access(server.AccessWrite).
 			String("message_id", "The message ID", true).
 			String("label", "Label name", true).
 			Enum("action", "Whether to add or remove the label", []string{"add", "remove"}, true).
@@ -157,6 +166,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.list_labels").
 			Description("List all Gmail labels").
+			Access(server.AccessRead).
 			Build(),
 		s.handleListLabels,
 	)
@@ -165,6 +175,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("gmail.create_draft").
 			Description("Create an email draft").
+			Access(server.AccessWrite).
 			String("to", "Recipient email addresses (comma-separated)", true).
 			String("subject", "Email subject", true).
 			String("body", "Email body content", true).
@@ -182,6 +193,7 @@ func (s *Server) registerResources() {
 			Name:        "Inbox Summary",
 			Description: "Summary of unread messages in inbox",
 			MimeType:    "application/json",
+			Access:      server.AccessRead,
 		},
 		s.handleInboxResource,
 	)
@@ -0,0 +1,227 @@
+// Package notes provides an MCP server for a local Markdown (Obsidian-style) vault.
+package notes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/mcp/server"
+	"github.com/quantumlife/quantumlife/internal/spaces/files"
+)
+
+// NotesClient defines the interface for vault operations used by the server.
+// This interface allows for mocking in unit tests.
+type NotesClient interface {
+	Search(ctx context.Context, query files.SearchQuery) ([]*files.Note, error)
+	Get(ctx context.Context, path string) (*files.Note, error)
+	Create(ctx context.Context, req files.CreateNoteRequest) (*files.Note, error)
+	Append(ctx context.Context, path, text, heading string) (*files.Note, error)
+}
+
+// Server is the Notes MCP server
+type Server struct {
+	*server.Server
+	client NotesClient
+}
+
+// New creates a new Notes MCP server from a vault
+func New(vault *files.Vault) *Server {
+	if vault == nil {
+		return nil
+	}
+	return newServer(vault)
+}
+
+// NewWithMockClient creates a new Notes MCP server with a mock client for testing.
+func NewWithMockClient(client NotesClient) *Server {
+	return newServer(client)
+}
+
+// newServer creates a new Notes MCP server with the given client.
+func newServer(client NotesClient) *Server {
+	s := &Server{
+		Server: server.New(server.Config{
+			Name:    "notes",
+			Version: "1.0.0",
+		}),
+		client: client,
+	}
+
+	s.registerTools()
+
+	return s
+}
+
+func (s *Server) registerTools() {
+	// Search notes
+	s.RegisterTool(
+		server.NewTool("notes.search").
+			Description("Search notes in the vault by text and/or tag").
+			Access(server.AccessRead).
+			String("query", "Text to match against title, path and content", false).
+			String("tag", "Only notes with this tag (e.g. project/alpha)", false).
+			Integer("limit", "Maximum number of results (default: 20)", false).
+			Build(),
+		s.handleSearch,
+	)
+
+	// Get note
+	s.RegisterTool(
+		server.NewTool("notes.get").
+			Description("Get the full content of a note").
+			Access(server.AccessRead).
+			String("path", "Note path relative to the vault, or a note name as used in [[wiki-links]]", true).
+			Build(),
+		s.handleGet,
+	)
+
+	// Create note
+	s.RegisterTool(
+		server.NewTool("notes.create").
+			Description("Create a new Markdown note in the vault").
+			Access(server.AccessWrite).
+			String("title", "Note title (also used as the file name)", true).
+			String("body", "Markdown content", false).
+			String("folder", "Folder relative to the vault root", false).
+			String("tags", "Comma-separated tags", false).
+			Build(),
+		s.handleCreate,
+	)
+
+	// Append to note
+	s.RegisterTool(
+		server.NewTool("notes.append").
+			Description("Append text to a note, optionally under a heading").
+			Access(server.AccessWrite).
+			String("path", "Note path or name", true).
+			String("text", "Markdown text to append", true).
+			String("heading", "Append to the end of this section (created if missing)", false).
+			Build(),
+		s.handleAppend,
+	)
+}
+
+// Tool handlers
+
+func (s *Server) handleSearch(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	query := files.SearchQuery{
+		Text:  args.String("query"),
+		Tag:   args.String("tag"),
+		Limit: args.IntDefault("limit", 20),
+	}
+	if query.Text == "" && query.Tag == "" {
+		return server.ErrorResult("query or tag is required"), nil
+	}
+
+	notes, err := s.client.Search(ctx, query)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	if len(notes) == 0 {
+		return server.SuccessResult("No matching notes found."), nil
+	}
+
+	return server.JSONResult(formatNotes(notes))
+}
+
+func (s *Server) handleGet(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	path, err := args.RequireString("path")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	note, err := s.client.Get(ctx, path)
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	return server.JSONResult(note)
+}
+
+func (s *Server) handleCreate(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	title, err := args.RequireString("title")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	note, err := s.client.Create(ctx, files.CreateNoteRequest{
+		Title:  title,
+		Folder: args.String("folder"),
+		Body:   args.String("body"),
+		Tags:   splitTags(args.String("tags")),
+	})
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	return server.SuccessResult(fmt.Sprintf("Note created: %s\nPath: %s", note.Title, note.Path)), nil
+}
+
+func (s *Server) handleAppend(ctx context.Context, raw json.RawMessage) (*server.ToolResult, error) {
+	args := server.ParseArgs(raw)
+	path, err := args.RequireString("path")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+	text, err := args.RequireString("text")
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	note, err := s.client.Append(ctx, path, text, args.String("heading"))
+	if err != nil {
+		return server.ErrorResult(err.Error()), nil
+	}
+
+	return server.SuccessResult(fmt.Sprintf("Appended to %s", note.Path)), nil
+}
+
+// Helper functions
+
+type noteInfo struct {
+	Path     string   `json:"path"`
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags,omitempty"`
+	Modified string   `json:"modified"`
+	Snippet  string   `json:"snippet,omitempty"`
+}
+
+func formatNotes(notes []*files.Note) []noteInfo {
+	result := make([]noteInfo, 0, len(notes))
+	for _, n := range notes {
+		result = append(result, noteInfo{
+			Path:     n.Path,
+			Title:    n.Title,
+			Tags:     n.Tags,
+			Modified: n.ModTime.Format(time.RFC3339),
+			Snippet:  snippet(n.Body, 200),
+		})
+	}
+	return result
+}
+
+// snippet returns the start of the body as a single line
+func snippet(body string, maxLen int) string {
+	s := strings.Join(strings.Fields(body), " ")
+	if r := []rune(s); len(r) > maxLen {
+		return string(r[:maxLen]) + "..."
+	}
+	return s
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,270 @@
+package notes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/mcp/server"
+	"github.com/quantumlife/quantumlife/internal/spaces/files"
+)
+
+// MockNotesClient implements a mock notes client for testing.
+type MockNotesClient struct {
+	SearchFunc func(ctx context.Context, query files.SearchQuery) ([]*files.Note, error)
+	GetFunc    func(ctx context.Context, path string) (*files.Note, error)
+	CreateFunc func(ctx context.Context, req files.CreateNoteRequest) (*files.Note, error)
+	AppendFunc func(ctx context.Context, path, text, heading string) (*files.Note, error)
+}
+
+func (m *MockNotesClient) Search(ctx context.Context, query files.SearchQuery) ([]*files.Note, error) {
+	if m.SearchFunc != nil {
+		return m.SearchFunc(ctx, query)
+	}
+	var matches []*files.Note
+	for _, n := range sampleNotes() {
+		if query.Tag != "" && !n.HasTag(query.Tag) {
+			continue
+		}
+		if query.Text != "" && !strings.Contains(strings.ToLower(n.Title+" "+n.Body), strings.ToLower(query.Text)) {
+			continue
+		}
+		matches = append(matches, n)
+	}
+	return matches, nil
+}
+
+func (m *MockNotesClient) Get(ctx context.Context, path string) (*files.Note, error) {
+	if m.GetFunc != nil {
+		return m.GetFunc(ctx, path)
+	}
+	for _, n := range sampleNotes() {
+		if n.Path == path {
+			return n, nil
+		}
+	}
+	return nil, files.ErrNoteNotFound
+}
+
+func (m *MockNotesClient) Create(ctx context.Context, req files.CreateNoteRequest) (*files.Note, error) {
+	if m.CreateFunc != nil {
+		return m.CreateFunc(ctx, req)
+	}
+	return &files.Note{Path: req.Title + ".md", Title: req.Title, Body: req.Body}, nil
+}
+
+func (m *MockNotesClient) Append(ctx context.Context, path, text, heading string) (*files.Note, error) {
+	if m.AppendFunc != nil {
+		return m.AppendFunc(ctx, path, text, heading)
+	}
+	return &files.Note{Path: path}, nil
+}
+
+func sampleNotes() []*files.Note {
+	modified := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	return []*files.Note{
+		files.ParseNote("projects/alpha.md", []byte("---\ntags: [work]\n---\n# Project Alpha\nKickoff with [[Bob]]."), modified),
+		files.ParseNote("journal/2025-03-01.md", []byte("# Saturday\nWent hiking. #personal"), modified),
+	}
+}
+
+func TestNew(t *testing.T) {
+	if srv := New(nil); srv != nil {
+		t.Error("expected nil server for nil vault")
+	}
+}
+
+func TestNewWithMockClient(t *testing.T) {
+	srv := NewWithMockClient(&MockNotesClient{})
+	if srv == nil {
+		t.Fatal("NewWithMockClient returned nil")
+	}
+	if info := srv.Info(); info.Name != "notes" || info.Version != "1.0.0" {
+		t.Errorf("unexpected server info: %+v", info)
+	}
+}
+
+func TestNotesServer_ToolRegistration(t *testing.T) {
+	srv := NewWithMockClient(&MockNotesClient{})
+
+	expected := map[string]server.ToolAccess{
+		"notes.search": server.AccessRead,
+		"notes.get":    server.AccessRead,
+		"notes.create": server.AccessWrite,
+		"notes.append": server.AccessWrite,
+	}
+
+	tools := srv.Registry().ListTools()
+	if len(tools) != len(expected) {
+		t.Errorf("expected %d tools, got %d", len(expected), len(tools))
+	}
+	for _, tool := range tools {
+		access, ok := expected[tool.Name]
+		if !ok {
+			t.Errorf("unexpected tool %q", tool.Name)
+			continue
+		}
+		if tool.Access != access {
+			t.Errorf("tool %q: expected access %q, got %q", tool.Name, access, tool.Access)
+		}
+	}
+}
+
+func TestNotesServer_Search(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      string
+		setup     func(*MockNotesClient)
+		wantErr   bool
+		wantText  string
+		wantCount int
+	}{
+		{
+			name:      "text query",
+			args:      `{"query": "kickoff"}`,
+			wantCount: 1,
+		},
+		{
+			name:      "tag filter",
+			args:      `{"tag": "personal"}`,
+			wantCount: 1,
+		},
+		{
+			name:     "no matches",
+			args:     `{"query": "taxes"}`,
+			wantText: "No matching notes",
+		},
+		{
+			name:    "missing query and tag",
+			args:    `{"limit": 5}`,
+			wantErr: true,
+		},
+		{
+			name: "client error",
+			args: `{"query": "alpha"}`,
+			setup: func(m *MockNotesClient) {
+				m.SearchFunc = func(ctx context.Context, query files.SearchQuery) ([]*files.Note, error) {
+					return nil, errors.New("permission denied")
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockNotesClient{}
+			if tt.setup != nil {
+				tt.setup(mock)
+			}
+			srv := NewWithMockClient(mock)
+
+			result, err := srv.handleSearch(context.Background(), json.RawMessage(tt.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr {
+				if !result.IsError {
+					t.Error("expected error result")
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+			}
+			if tt.wantText != "" && !strings.Contains(result.Content[0].Text, tt.wantText) {
+				t.Errorf("expected %q in %q", tt.wantText, result.Content[0].Text)
+			}
+			if tt.wantCount > 0 {
+				var got []noteInfo
+				if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+					t.Fatalf("failed to decode result: %v", err)
+				}
+				if len(got) != tt.wantCount {
+					t.Errorf("expected %d notes, got %d", tt.wantCount, len(got))
+				}
+			}
+		})
+	}
+}
+
+func TestNotesServer_Get(t *testing.T) {
+	srv := NewWithMockClient(&MockNotesClient{})
+
+	result, err := srv.handleGet(context.Background(), json.RawMessage(`{"path": "projects/alpha.md"}`))
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %+v", err, result)
+	}
+
+	var note files.Note
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &note); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if note.Title != "Project Alpha" || len(note.Links) != 1 || note.Links[0] != "Bob" {
+		t.Errorf("unexpected note: %+v", note)
+	}
+
+	result, _ = srv.handleGet(context.Background(), json.RawMessage(`{"path": "missing.md"}`))
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "not found") {
+		t.Errorf("expected not found error, got %+v", result)
+	}
+
+	result, _ = srv.handleGet(context.Background(), json.RawMessage(`{}`))
+	if !result.IsError {
+		t.Error("expected error for missing path")
+	}
+}
+
+func TestNotesServer_Create(t *testing.T) {
+	var got files.CreateNoteRequest
+	srv := NewWithMockClient(&MockNotesClient{
+		CreateFunc: func(ctx context.Context, req files.CreateNoteRequest) (*files.Note, error) {
+			got = req
+			return &files.Note{Path: "meetings/Standup.md", Title: req.Title}, nil
+		},
+	})
+
+	result, err := srv.handleCreate(context.Background(), json.RawMessage(
+		`{"title": "Standup", "folder": "meetings", "body": "Notes", "tags": "work, daily ,"}`))
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %+v", err, result)
+	}
+	if !strings.Contains(result.Content[0].Text, "meetings/Standup.md") {
+		t.Errorf("expected path in result, got %q", result.Content[0].Text)
+	}
+	if got.Folder != "meetings" || got.Body != "Notes" || len(got.Tags) != 2 || got.Tags[1] != "daily" {
+		t.Errorf("unexpected request: %+v", got)
+	}
+
+	result, _ = srv.handleCreate(context.Background(), json.RawMessage(`{"body": "no title"}`))
+	if !result.IsError {
+		t.Error("expected error when title is missing")
+	}
+}
+
+func TestNotesServer_Append(t *testing.T) {
+	var gotPath, gotText, gotHeading string
+	srv := NewWithMockClient(&MockNotesClient{
+		AppendFunc: func(ctx context.Context, path, text, heading string) (*files.Note, error) {
+			gotPath, gotText, gotHeading = path, text, heading
+			return &files.Note{Path: "projects/alpha.md"}, nil
+		},
+	})
+
+	result, err := srv.handleAppend(context.Background(), json.RawMessage(
+		`{"path": "alpha", "text": "- ship it", "heading": "Tasks"}`))
+	if err != nil || result.IsError {
+		t.Fatalf("unexpected error: %v %+v", err, result)
+	}
+	if gotPath != "alpha" || gotText != "- ship it" || gotHeading != "Tasks" {
+		t.Errorf("unexpected append call: %q %q %q", gotPath, gotText, gotHeading)
+	}
+
+	result, _ = srv.handleAppend(context.Background(), json.RawMessage(`{"path": "alpha"}`))
+	if !result.IsError {
+		t.Error("expected error when text is missing")
+	}
+}
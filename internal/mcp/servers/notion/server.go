@@ -68,6 +68,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.search").
 			Description("Search for pages and databases in Notion").
+			Access(server.AccessRead).
 			String("query", "Search query", true).
 			Enum("filter", "Filter by type", []string{"page", "database"}, false).
 			Integer("limit", "Max results (default 10)", false).
@@ -79,6 +80,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.get_page").
 			Description("Get a Notion page by ID").
+			Access(server.AccessRead).
 			String("page_id", "Page ID", true).
 			Build(),
 		s.handleGetPage,
@@ -88,6 +90,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.get_content").
 			Description("Get the content blocks of a Notion page").
+			Access(server.AccessRead).
 			String("page_id", "Page ID", true).
 			Integer("limit", "Max blocks to return (default 50)", false).
 			Build(),
@@ -98,6 +101,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.create_page").
 			Description("Create a new Notion page").
+			Access(server.AccessWrite).
 			String("parent_id", "Parent page or database ID", true).
 			String("title", "Page title", true).
 			String("content", "Page content in markdown (optional)", false).
@@ -109,6 +113,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.update_page").
 			Description("Update page properties").
+			Access(server.AccessWrite).
 			String("page_id", "Page ID", true).
 			String("title", "New title (optional)", false).
 			Boolean("archived", "Archive the page", false).
@@ -120,6 +125,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.query_database").
 			Description("Query a Notion database").
+			Access(server.AccessRead).
 			String("database_id", "Database ID", true).
 			String("filter_property", "Property name to filter by (optional)", false).
 			String("filter_value", "Value to filter for (optional)", false).
@@ -132,6 +138,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.list_databases").
 			Description("List all accessible databases").
+			Access(server.AccessRead).
 			Integer("limit", "Max databases (default 10)", false).
 			Build(),
 		s.handleListDatabases,
@@ -141,6 +148,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.get_database").
 			Description("Get database schema and properties").
+			Access(server.AccessRead).
 			String("database_id", "Database ID", true).
 			Build(),
 		s.handleGetDatabase,
@@ -150,6 +158,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.add_comment").
 			Description("Add a comment to a page").
+			Access(server.AccessWrite).
 			String("page_id", "Page ID", true).
 			String("text", "Comment text", true).
 			Build(),
@@ -160,6 +169,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("notion.get_comments").
 			Description("Get comments on a page").
+			Access(server.AccessRead).
 			String("page_id", "Page ID", true).
 			Build(),
 		s.handleGetComments,
@@ -81,6 +81,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.list_channels").
 			Description("List Slack channels the bot has access to").
+			Access(server.AccessRead).
 			Boolean("exclude_archived", "Exclude archived channels", false).
 			Integer("limit", "Max channels to return (default 100)", false).
 			Build(),
@@ -91,6 +92,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.get_messages").
 			Description("Get messages from a Slack channel").
+			Access(server.AccessRead).
 			String("channel", "Channel ID", true).
 			Integer("limit", "Max messages to return (default 20)", false).
 			Build(),
@@ -101,6 +103,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.send_message").
 			Description("Send a message to a Slack channel").
+			Access(server.AccessWrite).
 			String("channel", "Channel ID or name", true).
 			String("text", "Message text", true).
 			String("thread_ts", "Thread timestamp to reply to (optional)", false).
@@ -112,6 +115,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.add_reaction").
 			Description("Add an emoji reaction to a message").
+			Access(server.AccessWrite).
 			String("channel", "Channel ID", true).
 			String("timestamp", "Message timestamp", true).
 			String("emoji", "Emoji name (without colons)", true).
@@ -123,6 +127,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.search").
 			Description("Search for messages in Slack").
+			Access(server.AccessRead).
 			String("query", "Search query", true).
 			Integer("count", "Number of results (default 20)", false).
 			Build(),
@@ -133,6 +138,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.get_user").
 			Description("Get information about a Slack user").
+			Access(server.AccessRead).
 			String("user_id", "User ID", true).
 			Build(),
 		s.handleGetUser,
@@ -142,6 +148,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.list_users").
 			Description("List workspace members").
+			Access(server.AccessRead).
 			Integer("limit", "Max users to return (default 100)", false).
 			Build(),
 		s.handleListUsers,
@@ -151,6 +158,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.get_permalink").
 			Description("Get a permanent link to a message").
+			Access(server.AccessRead).
 			String("channel", "Channel ID", true).
 			String("timestamp", "Message timestamp", true).
 			Build(),
@@ -161,6 +169,7 @@ func (s *Server) registerTools() {
 	s.RegisterTool(
 		server.NewTool("slack.join_channel").
 			Description("Join a public channel (bot will automatically join to access messages)").
+			Access(server.AccessWrite).
 			String("channel", "Channel ID", true).
 			Build(),
 		s.handleJoinChannel,
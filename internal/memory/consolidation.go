@@ -0,0 +1,503 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/llm"
+	"github.com/quantumlife/quantumlife/internal/storage"
+)
+
+// ConsolidationConfig tunes the memory lifecycle job
+type ConsolidationConfig struct {
+	MinEpisodicAge      time.Duration // Leave recent episodic memories alone
+	MinClusterSize      int           // Related episodic memories needed to form a semantic one
+	MaxClusterSize      int           // Cap on memories summarized together
+	Similarity          float64       // Minimum word overlap (Jaccard) to relate two memories
+	ArchiveBelow        float64       // Archive memories whose importance decays below this
+	DeleteArchivedAfter time.Duration // Delete consolidated memories archived this long ago
+}
+
+// DefaultConsolidationConfig returns sensible defaults
+func DefaultConsolidationConfig() ConsolidationConfig {
+	return ConsolidationConfig{
+		MinEpisodicAge:      7 * 24 * time.Hour,
+		MinClusterSize:      3,
+		MaxClusterSize:      20,
+		Similarity:          0.3,
+		ArchiveBelow:        0.05,
+		DeleteArchivedAfter: 90 * 24 * time.Hour,
+	}
+}
+
+// ConsolidationReport summarizes one consolidation run
+type ConsolidationReport struct {
+	Decayed      int `json:"decayed"`      // Memories whose importance was lowered
+	Consolidated int `json:"consolidated"` // Semantic memories created from episodic clusters
+	Archived     int `json:"archived"`     // Memories removed from retrieval
+	Deleted      int `json:"deleted"`      // Consolidated memories deleted for good
+}
+
+// Consolidator decays, consolidates and forgets memories. Run it periodically,
+// e.g. as a daily scheduler task.
+type Consolidator struct {
+	manager *Manager
+	router  *llm.Router
+	config  ConsolidationConfig
+	now     func() time.Time
+}
+
+// NewConsolidator creates a consolidator. Without a router, episodic memories
+// are decayed and archived but not summarized.
+func NewConsolidator(m *Manager, router *llm.Router, cfg ConsolidationConfig) *Consolidator {
+	return &Consolidator{
+		manager: m,
+		router:  router,
+		config:  cfg,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run performs one pass: decay importance, summarize clusters of related
+// episodic memories, archive faded memories and delete long-archived ones.
+func (c *Consolidator) Run(ctx context.Context) (*ConsolidationReport, error) {
+	report := &ConsolidationReport{}
+
+	decayed, err := c.decay()
+	if err != nil {
+		return report, fmt.Errorf("decay failed: %w", err)
+	}
+	report.Decayed = decayed
+
+	consolidated, archived, err := c.consolidate(ctx)
+	report.Consolidated, report.Archived = consolidated, archived
+	if err != nil {
+		return report, fmt.Errorf("consolidation failed: %w", err)
+	}
+
+	archived, err = c.archiveFaded(ctx)
+	if err != nil {
+		return report, fmt.Errorf("archive failed: %w", err)
+	}
+	report.Archived += archived
+
+	deleted, err := c.deleteArchived()
+	if err != nil {
+		return report, fmt.Errorf("delete failed: %w", err)
+	}
+	report.Deleted = deleted
+
+	return report, nil
+}
+
+// lifecycleRow is the subset of a memory the lifecycle job works on
+type lifecycleRow struct {
+	id          string
+	memType     core.MemoryType
+	content     string
+	hatID       core.HatID
+	sourceItems []core.ItemID
+	entities    []string
+	importance  float64
+	decayFactor float64
+	embeddingID string
+	createdAt   time.Time
+	since       time.Time // Decay is applied from here
+}
+
+// liveMemories loads all memories that have not been archived
+func (c *Consolidator) liveMemories() ([]*lifecycleRow, error) {
+	rows, err := c.manager.db.Conn().Query(`
+		SELECT id, type, content, hat_id, source_items, entities,
+		       importance, decay_factor, embedding_id,
+		       created_at, last_access, decayed_at
+		FROM memories
+		WHERE archived_at IS NULL
+		ORDER BY created_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*lifecycleRow
+	for rows.Next() {
+		row := &lifecycleRow{}
+		var hatID, sourceItems, entities, embeddingID sql.NullString
+		var lastAccess, decayedAt sql.NullTime
+
+		err := rows.Scan(
+			&row.id, &row.memType, &row.content, &hatID, &sourceItems, &entities,
+			&row.importance, &row.decayFactor, &embeddingID,
+			&row.createdAt, &lastAccess, &decayedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		row.hatID = core.HatID(hatID.String)
+		row.embeddingID = embeddingID.String
+		json.Unmarshal([]byte(sourceItems.String), &row.sourceItems)
+		json.Unmarshal([]byte(entities.String), &row.entities)
+
+		// Decay runs from the latest of creation, last access and last decay,
+		// so retrieving a memory holds off its fading
+		row.since = row.createdAt
+		if lastAccess.Valid && lastAccess.Time.After(row.since) {
+			row.since = lastAccess.Time
+		}
+		if decayedAt.Valid && decayedAt.Time.After(row.since) {
+			row.since = decayedAt.Time
+		}
+
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// decay lowers importance exponentially; decay_factor is the rate per week
+func (c *Consolidator) decay() (int, error) {
+	memories, err := c.liveMemories()
+	if err != nil {
+		return 0, err
+	}
+
+	now := c.now()
+	decayed := 0
+	err = c.manager.db.Transaction(func(tx *sql.Tx) error {
+		for _, mem := range memories {
+			weeks := now.Sub(mem.since).Hours() / (24 * 7)
+			if weeks <= 0 || mem.decayFactor <= 0 {
+				continue
+			}
+
+			importance := mem.importance * math.Exp(-mem.decayFactor*weeks)
+			if _, err := tx.Exec(
+				"UPDATE memories SET importance = ?, decayed_at = ? WHERE id = ?",
+				importance, now, mem.id,
+			); err != nil {
+				return err
+			}
+			mem.importance = importance
+			decayed++
+		}
+		return nil
+	})
+
+	return decayed, err
+}
+
+// consolidate summarizes clusters of related episodic memories into semantic
+// memories. The episodic memories are archived and linked to the summary,
+// which inherits their source items. It returns the number of semantic
+// memories created and episodic memories archived.
+func (c *Consolidator) consolidate(ctx context.Context) (int, int, error) {
+	// Store needs embeddings; without them the summary would be dropped
+	if c.router == nil || c.manager.embedder == nil || c.manager.vectors == nil {
+		return 0, 0, nil
+	}
+
+	memories, err := c.liveMemories()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Candidates: unconsolidated episodic memories old enough to have settled
+	cutoff := c.now().Add(-c.config.MinEpisodicAge)
+	var candidates []*lifecycleRow
+	for _, mem := range memories {
+		if mem.memType == core.MemoryTypeEpisodic && mem.createdAt.Before(cutoff) {
+			candidates = append(candidates, mem)
+		}
+	}
+
+	consolidated, archived := 0, 0
+	for _, cluster := range c.cluster(candidates) {
+		if err := ctx.Err(); err != nil {
+			return consolidated, archived, err
+		}
+
+		summary, err := c.summarize(ctx, cluster)
+		if err != nil {
+			return consolidated, archived, err
+		}
+		if summary == "" {
+			continue
+		}
+
+		semantic := mergeCluster(cluster, summary)
+		if err := c.manager.Store(ctx, semantic); err != nil {
+			return consolidated, archived, err
+		}
+		consolidated++
+
+		if err := c.archive(ctx, cluster, semantic.ID); err != nil {
+			return consolidated, archived, err
+		}
+		archived += len(cluster)
+	}
+
+	return consolidated, archived, nil
+}
+
+// cluster groups related memories of the same hat. Each cluster grows around
+// its oldest memory; memories are related if they share an entity or enough
+// words.
+func (c *Consolidator) cluster(memories []*lifecycleRow) [][]*lifecycleRow {
+	minSize := c.config.MinClusterSize
+	if minSize < 2 {
+		minSize = 2
+	}
+
+	words := make(map[string]map[string]bool, len(memories))
+	for _, mem := range memories {
+		words[mem.id] = wordSet(mem.content)
+	}
+
+	assigned := make(map[string]bool)
+	var clusters [][]*lifecycleRow
+	for i, seed := range memories {
+		if assigned[seed.id] {
+			continue
+		}
+
+		cluster := []*lifecycleRow{seed}
+		for _, other := range memories[i+1:] {
+			if c.config.MaxClusterSize > 0 && len(cluster) >= c.config.MaxClusterSize {
+				break
+			}
+			if assigned[other.id] || other.hatID != seed.hatID {
+				continue
+			}
+			if sharesEntity(seed.entities, other.entities) ||
+				jaccard(words[seed.id], words[other.id]) >= c.config.Similarity {
+				cluster = append(cluster, other)
+			}
+		}
+
+		if len(cluster) < minSize {
+			continue
+		}
+		for _, mem := range cluster {
+			assigned[mem.id] = true
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
+
+// summarize asks the LLM for the durable facts behind a cluster
+func (c *Consolidator) summarize(ctx context.Context, cluster []*lifecycleRow) (string, error) {
+	system := `You consolidate a person's episodic memories into long-term knowledge.
+State, in one to three short sentences, what these memories have in common: people, places, habits, preferences or recurring events.
+Only use facts present in the memories. Reply with the sentences only.`
+
+	var sb strings.Builder
+	sb.WriteString("Memories:\n")
+	for _, mem := range cluster {
+		sb.WriteString(fmt.Sprintf("- [%s] %s\n", mem.createdAt.Format("2006-01-02"), mem.content))
+	}
+
+	// Clusters never span hats
+	sensitivity, err := storage.NewHatStore(c.manager.db).Sensitivity(cluster[0].hatID)
+	if err != nil {
+		return "", fmt.Errorf("summarize memories: %w", err)
+	}
+
+	response, err := c.router.Route(ctx, llm.RouteRequest{
+		System:      system,
+		Prompt:      sb.String(),
+		MaxTokens:   200,
+		Sensitivity: sensitivity,
+	})
+	if err != nil {
+		return "", fmt.Errorf("summarize memories: %w", err)
+	}
+
+	return strings.TrimSpace(response.Content), nil
+}
+
+// mergeCluster builds the semantic memory that replaces a cluster
+func mergeCluster(cluster []*lifecycleRow, summary string) *core.Memory {
+	semantic := &core.Memory{
+		Type:        core.MemoryTypeSemantic,
+		Content:     summary,
+		HatID:       cluster[0].hatID,
+		DecayFactor: 0.01, // Facts decay slowly
+	}
+
+	seenItems := make(map[core.ItemID]bool)
+	seenEntities := make(map[string]bool)
+	for _, mem := range cluster {
+		semantic.Importance = math.Max(semantic.Importance, mem.importance)
+		for _, id := range mem.sourceItems {
+			if !seenItems[id] {
+				seenItems[id] = true
+				semantic.SourceItems = append(semantic.SourceItems, id)
+			}
+		}
+		for _, entity := range mem.entities {
+			key := strings.ToLower(entity)
+			if !seenEntities[key] {
+				seenEntities[key] = true
+				semantic.Entities = append(semantic.Entities, entity)
+			}
+		}
+	}
+
+	// A pattern seen several times matters more than any one occurrence
+	semantic.Importance = math.Min(1, semantic.Importance+0.1)
+	return semantic
+}
+
+// archiveFaded archives memories whose importance has decayed away
+func (c *Consolidator) archiveFaded(ctx context.Context) (int, error) {
+	memories, err := c.liveMemories()
+	if err != nil {
+		return 0, err
+	}
+
+	var faded []*lifecycleRow
+	for _, mem := range memories {
+		if mem.importance < c.config.ArchiveBelow {
+			faded = append(faded, mem)
+		}
+	}
+	if len(faded) == 0 {
+		return 0, nil
+	}
+
+	return len(faded), c.archive(ctx, faded, "")
+}
+
+// archive removes memories from retrieval (vector and full-text indexes) but
+// keeps the rows and their source item links. consolidatedInto, if set,
+// records the semantic memory that now stands in for them.
+func (c *Consolidator) archive(ctx context.Context, memories []*lifecycleRow, consolidatedInto string) error {
+	now := c.now()
+
+	var embeddingIDs []string
+	err := c.manager.db.Transaction(func(tx *sql.Tx) error {
+		for _, mem := range memories {
+			_, err := tx.Exec(`
+				UPDATE memories SET
+				    archived_at = ?,
+				    consolidated_into = COALESCE(NULLIF(?, ''), consolidated_into)
+				WHERE id = ?
+			`, now, consolidatedInto, mem.id)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM memories_fts WHERE memory_id = ?", mem.id); err != nil {
+				return err
+			}
+			if mem.embeddingID != "" {
+				embeddingIDs = append(embeddingIDs, mem.embeddingID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.manager.vectors != nil && len(embeddingIDs) > 0 {
+		if err := c.manager.vectors.Delete(ctx, c.manager.collection(), embeddingIDs); err != nil {
+			return fmt.Errorf("failed to remove archived vectors: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteArchived deletes consolidated memories archived long ago. Their
+// content lives on in the semantic memory, which holds their source items.
+// Archived memories that were never consolidated are kept.
+func (c *Consolidator) deleteArchived() (int, error) {
+	rows, err := c.manager.db.Conn().Query(`
+		SELECT id, archived_at FROM memories
+		WHERE archived_at IS NOT NULL AND consolidated_into IS NOT NULL
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := c.now().Add(-c.config.DeleteArchivedAfter)
+	var expired []string
+	for rows.Next() {
+		var id string
+		var archivedAt time.Time
+		if err := rows.Scan(&id, &archivedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if archivedAt.Before(cutoff) {
+			expired = append(expired, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	err = c.manager.db.Transaction(func(tx *sql.Tx) error {
+		for _, id := range expired {
+			if _, err := tx.Exec("DELETE FROM memories WHERE id = ?", id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(expired), nil
+}
+
+// wordSet returns the distinct lowercase words of four or more letters,
+// which skips most stop words
+func wordSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(word)) >= 4 {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func sharesEntity(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/embeddings"
+	"github.com/quantumlife/quantumlife/internal/llm"
+	"github.com/quantumlife/quantumlife/internal/vectors"
+)
+
+// mockOllamaServer serves embeddings and a fixed chat reply
+func mockOllamaServer(t *testing.T, reply string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/tags":
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+		case "/api/embeddings":
+			json.NewEncoder(w).Encode(map[string]interface{}{"embedding": []float32{0.1, 0.2, 0.3}})
+		case "/api/chat":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"message": map[string]string{"role": "assistant", "content": reply},
+				"done":    true,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func isArchived(t *testing.T, m *Manager, id string) (bool, string) {
+	t.Helper()
+	var archived bool
+	var consolidatedInto *string
+	err := m.db.Conn().QueryRow(
+		"SELECT archived_at IS NOT NULL, consolidated_into FROM memories WHERE id = ?", id,
+	).Scan(&archived, &consolidatedInto)
+	if err != nil {
+		t.Fatalf("load memory %s: %v", id, err)
+	}
+	if consolidatedInto == nil {
+		return archived, ""
+	}
+	return archived, *consolidatedInto
+}
+
+func TestConsolidator_Decay(t *testing.T) {
+	db := testDB(t)
+	m := NewManager(db, nil, nil)
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+
+	insertTestMemory(t, db, &core.Memory{
+		ID: "episode", Type: core.MemoryTypeEpisodic, Content: "Dinner at Luigi's",
+		HatID: core.HatPersonal, Importance: 0.8, DecayFactor: 0.1,
+		CreatedAt: now.Add(-28 * 24 * time.Hour),
+	})
+	insertTestMemory(t, db, &core.Memory{
+		ID: "recently-used", Type: core.MemoryTypeSemantic, Content: "Allergic to peanuts",
+		HatID: core.HatHealth, Importance: 0.9, DecayFactor: 0.01,
+		CreatedAt: now.Add(-365 * 24 * time.Hour), LastAccess: now,
+	})
+
+	c := NewConsolidator(m, nil, DefaultConsolidationConfig())
+	c.now = func() time.Time { return now }
+
+	report, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Decayed != 1 || report.Archived != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	// Four weeks at 0.1 per week
+	mem, _ := m.GetByID("episode")
+	if want := 0.8 * math.Exp(-0.4); math.Abs(mem.Importance-want) > 1e-9 {
+		t.Errorf("Importance = %v, want %v", mem.Importance, want)
+	}
+
+	// Access holds off decay
+	mem, _ = m.GetByID("recently-used")
+	if mem.Importance != 0.9 {
+		t.Errorf("accessed memory decayed to %v", mem.Importance)
+	}
+
+	// Decay is not applied twice for the same period
+	report, _ = c.Run(context.Background())
+	if report.Decayed != 0 {
+		t.Errorf("second run decayed %d memories", report.Decayed)
+	}
+}
+
+func TestConsolidator_ArchivesFaded(t *testing.T) {
+	db := testDB(t)
+	m := NewManager(db, nil, nil)
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+
+	insertTestMemory(t, db, &core.Memory{
+		ID: "faded", Type: core.MemoryTypeEpisodic, Content: "Parked on level 3",
+		HatID: core.HatPersonal, Importance: 0.06, DecayFactor: 0.5,
+		SourceItems: []core.ItemID{"item-1"},
+		CreatedAt:   now.Add(-28 * 24 * time.Hour),
+	})
+
+	c := NewConsolidator(m, nil, DefaultConsolidationConfig())
+	c.now = func() time.Time { return now }
+
+	report, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Archived != 1 {
+		t.Errorf("Archived = %d, want 1", report.Archived)
+	}
+	if archived, _ := isArchived(t, m, "faded"); !archived {
+		t.Error("faded memory not archived")
+	}
+	if recent, _ := m.GetRecent(10); len(recent) != 0 {
+		t.Errorf("GetRecent() returned archived memories: %+v", recent)
+	}
+
+	// Memories that were never consolidated are the only record of their
+	// source items, so they are kept
+	c.now = func() time.Time { return now.Add(365 * 24 * time.Hour) }
+	report, _ = c.Run(context.Background())
+	if report.Deleted != 0 {
+		t.Errorf("Deleted = %d, want 0", report.Deleted)
+	}
+	mem, err := m.GetByID("faded")
+	if err != nil || len(mem.SourceItems) != 1 {
+		t.Errorf("archived memory lost: %+v, %v", mem, err)
+	}
+}
+
+func TestConsolidator_Consolidate(t *testing.T) {
+	db := testDB(t)
+	server := mockOllamaServer(t, "Has a weekly 1:1 with Bob on Mondays.")
+
+	embedder := embeddings.NewService(embeddings.Config{BaseURL: server.URL, Model: "test-model"})
+	index, err := vectors.NewEmbeddedStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+	defer index.Close()
+
+	m := NewManager(db, index, embedder)
+	router := llm.NewRouter(llm.RouterConfig{
+		Ollama:      llm.NewOllamaClient(llm.OllamaConfig{BaseURL: server.URL}),
+		PreferLocal: true,
+	})
+
+	now := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	old := now.Add(-14 * 24 * time.Hour)
+	episodes := []*core.Memory{
+		{ID: "e1", Content: "1:1 with Bob about the roadmap", Entities: []string{"Bob"}, SourceItems: []core.ItemID{"i1"}},
+		{ID: "e2", Content: "Monday 1:1 with bob, discussed hiring", Entities: []string{"bob"}, SourceItems: []core.ItemID{"i2"}},
+		{ID: "e3", Content: "Weekly sync with Bob moved to Monday", Entities: []string{"Bob"}, SourceItems: []core.ItemID{"i2", "i3"}},
+		{ID: "e4", Content: "Dentist appointment booked"},
+	}
+	for i, mem := range episodes {
+		mem.Type = core.MemoryTypeEpisodic
+		mem.HatID = core.HatProfessional
+		mem.Importance = 0.5
+		mem.DecayFactor = 0.1
+		mem.CreatedAt = old.Add(time.Duration(i) * time.Hour)
+		insertTestMemory(t, db, mem)
+	}
+	// Too recent to consolidate
+	insertTestMemory(t, db, &core.Memory{
+		ID: "e5", Type: core.MemoryTypeEpisodic, Content: "1:1 with Bob", Entities: []string{"Bob"},
+		HatID: core.HatProfessional, Importance: 0.5, DecayFactor: 0.1, CreatedAt: now.Add(-time.Hour),
+	})
+
+	c := NewConsolidator(m, router, DefaultConsolidationConfig())
+	c.now = func() time.Time { return now }
+
+	report, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Consolidated != 1 || report.Archived != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	_, semanticID := isArchived(t, m, "e1")
+	semantic, err := m.GetByID(semanticID)
+	if err != nil {
+		t.Fatalf("semantic memory not stored: %v", err)
+	}
+	if semantic.Type != core.MemoryTypeSemantic || semantic.HatID != core.HatProfessional {
+		t.Errorf("unexpected semantic memory: %+v", semantic)
+	}
+	if len(semantic.SourceItems) != 3 || len(semantic.Entities) != 1 {
+		t.Errorf("source items and entities not merged: %v %v", semantic.SourceItems, semantic.Entities)
+	}
+	for _, id := range []string{"e2", "e3"} {
+		if archived, into := isArchived(t, m, id); !archived || into != semanticID {
+			t.Errorf("%s: archived = %v, consolidated into %q", id, archived, into)
+		}
+	}
+	for _, id := range []string{"e4", "e5"} {
+		if archived, _ := isArchived(t, m, id); archived {
+			t.Errorf("%s should not be archived", id)
+		}
+	}
+
+	// The summary is retrievable; the archived episodes are not
+	memories, _ := m.Retrieve(context.Background(), "Bob Mondays", RetrieveOptions{Mode: RetrievalLexical})
+	if len(memories) != 1 || memories[0].ID != semanticID {
+		t.Errorf("Retrieve() = %+v, want only the semantic memory", memories)
+	}
+
+	// Consolidated episodes are deleted once archived long enough
+	c.now = func() time.Time { return now.Add(91 * 24 * time.Hour) }
+	report, err = c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Deleted != 3 {
+		t.Errorf("Deleted = %d, want 3", report.Deleted)
+	}
+	if _, err := m.GetByID("e1"); err != core.ErrMemoryNotFound {
+		t.Errorf("GetByID(e1) error = %v, want ErrMemoryNotFound", err)
+	}
+	if _, err := m.GetByID(semanticID); err != nil {
+		t.Errorf("semantic memory deleted: %v", err)
+	}
+}
+
+func TestConsolidator_Cluster(t *testing.T) {
+	c := NewConsolidator(nil, nil, ConsolidationConfig{MinClusterSize: 2, Similarity: 0.5})
+
+	rows := []*lifecycleRow{
+		{id: "a", hatID: core.HatPersonal, content: "Morning run along the river trail"},
+		{id: "b", hatID: core.HatPersonal, content: "Evening run along the river trail"},
+		{id: "c", hatID: core.HatProfessional, content: "Morning run along the river trail"},
+		{id: "d", hatID: core.HatPersonal, content: "Bought groceries", entities: []string{"Whole Foods"}},
+		{id: "e", hatID: core.HatPersonal, content: "Picked up coffee", entities: []string{"whole foods"}},
+	}
+
+	clusters := c.cluster(rows)
+	if len(clusters) != 2 {
+		t.Fatalf("cluster() returned %d clusters, want 2", len(clusters))
+	}
+	if len(clusters[0]) != 2 || clusters[0][0].id != "a" || clusters[0][1].id != "b" {
+		t.Errorf("word-overlap cluster = %v", clusters[0])
+	}
+	if len(clusters[1]) != 2 || clusters[1][0].id != "d" || clusters[1][1].id != "e" {
+		t.Errorf("shared-entity cluster = %v", clusters[1])
+	}
+}
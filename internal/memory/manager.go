@@ -6,6 +6,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,27 +20,33 @@ import (
 
 // Manager handles all memory operations
 type Manager struct {
-	db       *storage.DB
-	vectors  *vectors.Store
-	embedder *embeddings.Service
+	db          *storage.DB
+	vectors     vectors.Index
+	embedder    *embeddings.Service
+	collections *storage.VectorCollectionStore
 }
 
 // NewManager creates a memory manager
-func NewManager(db *storage.DB, vectors *vectors.Store, embedder *embeddings.Service) *Manager {
+func NewManager(db *storage.DB, vectors vectors.Index, embedder *embeddings.Service) *Manager {
 	return &Manager{
-		db:       db,
-		vectors:  vectors,
-		embedder: embedder,
+		db:          db,
+		vectors:     vectors,
+		embedder:    embedder,
+		collections: storage.NewVectorCollectionStore(db),
 	}
 }
 
-// Store stores a new memory
-func (m *Manager) Store(ctx context.Context, memory *core.Memory) error {
-	// Skip if embeddings not configured
-	if m.embedder == nil || m.vectors == nil {
-		return nil
+// collection returns the vector collection currently serving memories, which
+// changes when a reindex swaps in a rebuilt one
+func (m *Manager) collection() string {
+	if rec, err := m.collections.Get(vectors.CollectionMemories); err == nil && rec != nil {
+		return rec.PhysicalName
 	}
+	return vectors.CollectionMemories
+}
 
+// Store stores a new memory
+func (m *Manager) Store(ctx context.Context, memory *core.Memory) error {
 	// Generate ID if not set
 	if memory.ID == "" {
 		memory.ID = uuid.New().String()
@@ -49,100 +57,121 @@ func (m *Manager) Store(ctx context.Context, memory *core.Memory) error {
 	memory.UpdatedAt = now
 	memory.LastAccess = now
 
-	// Generate embedding
-	embedding, err := m.embedder.Embed(ctx, memory.Content)
-	if err != nil {
-		return fmt.Errorf("failed to generate embedding: %w", err)
-	}
+	// Store in the vector index, if embeddings are configured. Without them
+	// the memory is still found by lexical retrieval.
+	collection := m.collection()
+	if m.embedder != nil && m.vectors != nil {
+		embedding, err := m.embedder.Embed(ctx, memory.Content)
+		if err != nil {
+			return fmt.Errorf("failed to generate embedding: %w", err)
+		}
 
-	// Store in Qdrant
-	embeddingID := uuid.New().String()
-	err = m.vectors.Upsert(ctx, vectors.CollectionMemories, []vectors.Point{{
-		ID:     embeddingID,
-		Vector: embedding,
-		Payload: map[string]interface{}{
-			"memory_id":  memory.ID,
-			"type":       string(memory.Type),
-			"hat_id":     string(memory.HatID),
-			"importance": memory.Importance,
-			"created_at": memory.CreatedAt.Unix(),
-		},
-	}})
-	if err != nil {
-		return fmt.Errorf("failed to store vector: %w", err)
-	}
+		embeddingID := uuid.New().String()
+		err = m.vectors.Upsert(ctx, collection, []vectors.Point{{
+			ID:     embeddingID,
+			Vector: embedding,
+			Payload: map[string]interface{}{
+				"memory_id":  memory.ID,
+				"type":       string(memory.Type),
+				"hat_id":     string(memory.HatID),
+				"importance": memory.Importance,
+				"created_at": memory.CreatedAt.Unix(),
+			},
+		}})
+		if err != nil {
+			return fmt.Errorf("failed to store vector: %w", err)
+		}
 
-	memory.EmbeddingID = embeddingID
+		memory.EmbeddingID = embeddingID
+	}
 
 	// Store in SQLite
 	sourceItems, _ := json.Marshal(memory.SourceItems)
 	entities, _ := json.Marshal(memory.Entities)
 
-	_, err = m.db.Conn().Exec(`
-		INSERT INTO memories (
-		    id, type, content, summary, hat_id, source_items, entities,
-		    importance, access_count, last_access, decay_factor, embedding_id,
-		    created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
-		memory.ID, memory.Type, memory.Content, memory.Summary,
-		memory.HatID, string(sourceItems), string(entities),
-		memory.Importance, memory.AccessCount, memory.LastAccess,
-		memory.DecayFactor, memory.EmbeddingID,
-		memory.CreatedAt, memory.UpdatedAt,
-	)
+	err := m.db.Transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO memories (
+			    id, type, content, summary, hat_id, source_items, entities,
+			    importance, access_count, last_access, decay_factor, embedding_id,
+			    created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			memory.ID, memory.Type, memory.Content, memory.Summary,
+			memory.HatID, string(sourceItems), string(entities),
+			memory.Importance, memory.AccessCount, memory.LastAccess,
+			memory.DecayFactor, memory.EmbeddingID,
+			memory.CreatedAt, memory.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Keep the full-text index in step for lexical retrieval
+		_, err = tx.Exec(`
+			INSERT INTO memories_fts (memory_id, content, summary, entities)
+			VALUES (?, ?, ?, ?)
+		`, memory.ID, memory.Content, memory.Summary, strings.Join(memory.Entities, " "))
+		return err
+	})
 
 	if err != nil {
 		// Rollback vector
-		m.vectors.Delete(ctx, vectors.CollectionMemories, []string{embeddingID})
+		if memory.EmbeddingID != "" {
+			m.vectors.Delete(ctx, collection, []string{memory.EmbeddingID})
+		}
 		return fmt.Errorf("failed to store memory: %w", err)
 	}
 
 	return nil
 }
 
-// Retrieve finds relevant memories by semantic search
+// Retrieve finds relevant memories. By default it fuses full-text (BM25) and
+// vector rankings, so exact names, numbers and addresses are found as well as
+// paraphrases; opts.Mode selects a single ranking instead.
 func (m *Manager) Retrieve(ctx context.Context, query string, opts RetrieveOptions) ([]*core.Memory, error) {
-	// Return empty if embeddings not configured
-	if m.embedder == nil || m.vectors == nil {
-		return nil, nil
+	limit := opts.Limit
+	if limit == 0 {
+		limit = 10
 	}
 
-	// Generate query embedding
-	embedding, err := m.embedder.Embed(ctx, query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to embed query: %w", err)
+	mode := opts.Mode
+	if mode == "" {
+		mode = RetrievalHybrid
 	}
 
-	// Build filter
-	filter := make(map[string]interface{})
-	if opts.HatID != "" {
-		filter["hat_id"] = string(opts.HatID)
-	}
-	if opts.Type != "" {
-		filter["type"] = string(opts.Type)
+	// Fuse from a deeper pool than we return so either ranking can promote a result
+	candidates := limit
+	if mode == RetrievalHybrid {
+		candidates = limit * 3
 	}
 
-	limit := opts.Limit
-	if limit == 0 {
-		limit = 10
+	var lexical, semantic []string
+	if mode != RetrievalSemantic {
+		ids, err := m.searchText(query, opts, candidates)
+		if err != nil {
+			return nil, fmt.Errorf("text search failed: %w", err)
+		}
+		lexical = ids
+	}
+	if mode != RetrievalLexical && m.embedder != nil && m.vectors != nil {
+		ids, err := m.searchVectors(ctx, query, opts, candidates)
+		// In hybrid mode text matches still answer the query if embedding fails
+		if err != nil && (mode == RetrievalSemantic || len(lexical) == 0) {
+			return nil, err
+		}
+		semantic = ids
 	}
 
-	// Search vectors
-	results, err := m.vectors.Search(ctx, vectors.CollectionMemories, embedding, uint64(limit), filter)
-	if err != nil {
-		return nil, fmt.Errorf("vector search failed: %w", err)
+	ids := fuseRankings(lexical, semantic)
+	if len(ids) > limit {
+		ids = ids[:limit]
 	}
 
 	// Load full memories
-	memories := make([]*core.Memory, 0, len(results))
-	for _, r := range results {
-		memoryID, ok := r.Payload["memory_id"].(string)
-		if !ok {
-			continue
-		}
-
-		memory, err := m.GetByID(memoryID)
+	var memories []*core.Memory
+	for _, id := range ids {
+		memory, err := m.GetByID(id)
 		if err != nil {
 			continue
 		}
@@ -161,6 +190,108 @@ type RetrieveOptions struct {
 	HatID core.HatID
 	Type  core.MemoryType
 	Limit int
+	Mode  RetrievalMode // Defaults to RetrievalHybrid
+}
+
+// RetrievalMode selects how Retrieve ranks memories
+type RetrievalMode string
+
+const (
+	RetrievalHybrid   RetrievalMode = "hybrid"   // Reciprocal rank fusion of lexical and semantic
+	RetrievalSemantic RetrievalMode = "semantic" // Vector similarity only
+	RetrievalLexical  RetrievalMode = "lexical"  // Full-text BM25 only
+)
+
+// rrfK dampens the weight of top ranks in reciprocal rank fusion; 60 is the
+// value from the original RRF paper and works well without tuning
+const rrfK = 60
+
+// fuseRankings merges ranked ID lists by reciprocal rank fusion. IDs found by
+// both rankings rise to the top; ties keep first-seen order.
+func fuseRankings(rankings ...[]string) []string {
+	scores := make(map[string]float64)
+	var ids []string
+	for _, ranking := range rankings {
+		for rank, id := range ranking {
+			if _, seen := scores[id]; !seen {
+				ids = append(ids, id)
+			}
+			scores[id] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		return scores[ids[i]] > scores[ids[j]]
+	})
+	return ids
+}
+
+// searchText ranks memory IDs by BM25 over content, summary and entities
+func (m *Manager) searchText(query string, opts RetrieveOptions, limit int) ([]string, error) {
+	match := storage.FTSQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := m.db.Conn().Query(`
+		SELECT memories_fts.memory_id
+		FROM memories_fts
+		JOIN memories ON memories.id = memories_fts.memory_id
+		WHERE memories_fts MATCH ?
+		  AND memories.archived_at IS NULL
+		  AND (? = '' OR memories.hat_id = ?)
+		  AND (? = '' OR memories.type = ?)
+		ORDER BY bm25(memories_fts, 0.0, 1.0, 1.0, 2.0)
+		LIMIT ?
+	`, match, opts.HatID, opts.HatID, opts.Type, opts.Type, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// searchVectors ranks memory IDs by embedding similarity
+func (m *Manager) searchVectors(ctx context.Context, query string, opts RetrieveOptions, limit int) ([]string, error) {
+	// Generate query embedding
+	embedding, err := m.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	// Build filter
+	filter := make(map[string]interface{})
+	if opts.HatID != "" {
+		filter["hat_id"] = string(opts.HatID)
+	}
+	if opts.Type != "" {
+		filter["type"] = string(opts.Type)
+	}
+
+	// Search vectors
+	results, err := m.vectors.Search(ctx, m.collection(), embedding, uint64(limit), filter)
+	if err != nil {
+		return nil, fmt.Errorf("vector search failed: %w", err)
+	}
+
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		if memoryID, ok := r.Payload["memory_id"].(string); ok {
+			ids = append(ids, memoryID)
+		}
+	}
+
+	return ids, nil
 }
 
 // GetByID loads a memory by ID
@@ -282,6 +413,7 @@ func (m *Manager) GetRecent(limit int) ([]*core.Memory, error) {
 		       importance, access_count, last_access, decay_factor, embedding_id,
 		       created_at, updated_at
 		FROM memories
+		WHERE archived_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT ?
 	`, limit)
@@ -11,6 +11,7 @@ import (
 	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/embeddings"
 	"github.com/quantumlife/quantumlife/internal/storage"
+	"github.com/quantumlife/quantumlife/internal/vectors"
 )
 
 // testDB creates an in-memory SQLite database for testing
@@ -501,9 +502,8 @@ func TestManager_Store_RequiresEmbeddings(t *testing.T) {
 		t.Error("Expected nil embedder")
 	}
 
-	// Note: Calling Store with nil embedder would panic
-	// This test documents that embedder is required
-	// The actual Store behavior is tested in TestManager_Store_EmbeddingGeneration
+	// Store still indexes text for lexical retrieval; see
+	// TestManager_Store_NilEmbedder
 }
 
 // TestEmbeddingsService_MockServer tests embedding generation with mock server
@@ -752,16 +752,22 @@ func TestManager_Store_NilEmbedder(t *testing.T) {
 		Importance: 0.5,
 	}
 
-	// Store should return nil (no error) when embedder is nil
 	err := m.Store(context.Background(), memory)
 	if err != nil {
 		t.Errorf("Store with nil embedder should return nil, got: %v", err)
 	}
 
-	// Memory should NOT be stored in database (since embedder is required)
+	// The memory is stored without a vector and found lexically
 	count, _ := m.Count()
-	if count != 0 {
-		t.Errorf("Expected 0 memories stored when embedder is nil, got %d", count)
+	if count != 1 {
+		t.Errorf("Expected 1 memory stored when embedder is nil, got %d", count)
+	}
+	if memory.EmbeddingID != "" {
+		t.Errorf("EmbeddingID = %q, want none", memory.EmbeddingID)
+	}
+	memories, err := m.Retrieve(context.Background(), "memory content", RetrieveOptions{})
+	if err != nil || len(memories) != 1 || memories[0].ID != memory.ID {
+		t.Errorf("Retrieve() = %v, %v; want the stored memory", memories, err)
 	}
 }
 
@@ -901,6 +907,125 @@ func TestManager_Retrieve_NilVectors(t *testing.T) {
 	}
 }
 
+func TestManager_StoreAndRetrieve_EmbeddedIndex(t *testing.T) {
+	db := testDB(t)
+	mockServer := mockEmbeddingsServer(t)
+
+	embedder := embeddings.NewService(embeddings.Config{
+		BaseURL: mockServer.URL,
+		Model:   "test-model",
+	})
+	index, err := vectors.NewEmbeddedStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+	defer index.Close()
+
+	m := NewManager(db, index, embedder)
+	ctx := context.Background()
+
+	if err := m.StoreSemantic(ctx, "Prefers morning meetings", core.HatProfessional, 0.8); err != nil {
+		t.Fatalf("StoreSemantic() error = %v", err)
+	}
+	if err := m.StoreEpisodic(ctx, "Went hiking on Saturday", core.HatPersonal, nil); err != nil {
+		t.Fatalf("StoreEpisodic() error = %v", err)
+	}
+
+	memories, err := m.Retrieve(ctx, "meetings", RetrieveOptions{Limit: 5})
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(memories) != 2 {
+		t.Fatalf("Retrieve() returned %d memories, want 2", len(memories))
+	}
+
+	memories, err = m.Retrieve(ctx, "meetings", RetrieveOptions{HatID: core.HatProfessional})
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(memories) != 1 || memories[0].Content != "Prefers morning meetings" {
+		t.Errorf("hat-filtered Retrieve() = %+v", memories)
+	}
+	if memories[0].EmbeddingID == "" {
+		t.Error("EmbeddingID should be set")
+	}
+}
+
+func TestManager_Retrieve_Hybrid(t *testing.T) {
+	db := testDB(t)
+	mockServer := mockEmbeddingsServer(t)
+
+	embedder := embeddings.NewService(embeddings.Config{
+		BaseURL: mockServer.URL,
+		Model:   "test-model",
+	})
+	index, err := vectors.NewEmbeddedStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+	defer index.Close()
+
+	m := NewManager(db, index, embedder)
+	ctx := context.Background()
+
+	m.StoreSemantic(ctx, "Prefers morning meetings", core.HatProfessional, 0.8)
+	m.StoreEpisodic(ctx, "Went hiking on Saturday", core.HatPersonal, nil)
+	m.StoreSemantic(ctx, "Invoice INV-2024-0117 from billing@acme.io is paid", core.HatFinance, 0.6)
+
+	// The mock embeds everything identically, so only the text ranking
+	// separates results
+	memories, err := m.Retrieve(ctx, "INV-2024-0117", RetrieveOptions{})
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if len(memories) != 3 || memories[0].HatID != core.HatFinance {
+		t.Errorf("hybrid Retrieve() should rank the exact match first, got %+v", memories)
+	}
+
+	memories, _ = m.Retrieve(ctx, "billing@acme.io", RetrieveOptions{Mode: RetrievalLexical})
+	if len(memories) != 1 || memories[0].HatID != core.HatFinance {
+		t.Errorf("lexical Retrieve() = %+v", memories)
+	}
+
+	memories, _ = m.Retrieve(ctx, "meetings", RetrieveOptions{Mode: RetrievalLexical, HatID: core.HatPersonal})
+	if memories != nil {
+		t.Errorf("lexical Retrieve() should respect hat filter, got %+v", memories)
+	}
+
+	memories, _ = m.Retrieve(ctx, "zzz", RetrieveOptions{Mode: RetrievalSemantic, Limit: 2})
+	if len(memories) != 2 {
+		t.Errorf("semantic Retrieve() returned %d memories, want 2", len(memories))
+	}
+
+	// Text matches still answer the query when the embedder is down
+	mockServer.Close()
+	memories, err = m.Retrieve(ctx, "hiking", RetrieveOptions{})
+	if err != nil || len(memories) != 1 || memories[0].HatID != core.HatPersonal {
+		t.Errorf("Retrieve() with embedder down = %+v, %v", memories, err)
+	}
+	if _, err := m.Retrieve(ctx, "hiking", RetrieveOptions{Mode: RetrievalSemantic}); err == nil {
+		t.Error("semantic Retrieve() should fail when the embedder is down")
+	}
+}
+
+func TestFuseRankings(t *testing.T) {
+	got := fuseRankings([]string{"a", "b", "c"}, []string{"c", "d", "a"})
+	want := []string{"a", "c", "b", "d"}
+	if len(got) != len(want) {
+		t.Fatalf("fuseRankings() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("fuseRankings() = %v, want %v", got, want)
+			break
+		}
+	}
+
+	if got := fuseRankings(nil, nil); got != nil {
+		t.Errorf("fuseRankings(nil, nil) = %v, want nil", got)
+	}
+}
+
 // --- Test recordAccess ---
 
 func TestManager_RecordAccess_Multiple(t *testing.T) {
@@ -0,0 +1,405 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/quantumlife/quantumlife/internal/embeddings"
+	"github.com/quantumlife/quantumlife/internal/storage"
+	"github.com/quantumlife/quantumlife/internal/vectors"
+)
+
+// indexedCollections are the vector collections rebuilt from SQLite
+var indexedCollections = []string{vectors.CollectionMemories, vectors.CollectionItems}
+
+// EmbeddingMismatch reports a collection whose vectors came from a different
+// embedding model than the one configured now. Searching it returns noise (or
+// fails on a dimension change) until it is reindexed.
+type EmbeddingMismatch struct {
+	Collection    string
+	Model         string // Empty if unknown
+	Dimension     uint64
+	WantModel     string
+	WantDimension uint64
+}
+
+func (m EmbeddingMismatch) String() string {
+	model := m.Model
+	if model == "" {
+		model = "an unknown model"
+	}
+	if m.Dimension > 0 {
+		model = fmt.Sprintf("%s (%d dimensions)", model, m.Dimension)
+	}
+	return fmt.Sprintf("collection %s was embedded with %s, but %s (%d dimensions) is configured",
+		m.Collection, model, m.WantModel, m.WantDimension)
+}
+
+// PrepareIndex makes sure the collections serving memories and items exist
+// and records the embedding model behind new ones. Collections built with a
+// different model are reported, not touched; run a Reindexer to rebuild them.
+func PrepareIndex(ctx context.Context, db *storage.DB, index vectors.Index, embedder *embeddings.Service) ([]EmbeddingMismatch, error) {
+	store := storage.NewVectorCollectionStore(db)
+	model, dimension := embedder.ModelName(), embedder.Dimension()
+
+	var mismatches []EmbeddingMismatch
+	for _, name := range indexedCollections {
+		rec, err := store.Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if rec == nil {
+			// First run with this table: adopt the collection if its dimension fits
+			if err := index.CreateCollection(ctx, name, dimension); err != nil {
+				mismatches = append(mismatches, EmbeddingMismatch{
+					Collection: name, WantModel: model, WantDimension: dimension,
+				})
+				continue
+			}
+			err := store.Save(&storage.VectorCollection{
+				Name: name, PhysicalName: name, Model: model, Dimension: dimension,
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if rec.Model != model || rec.Dimension != dimension {
+			mismatches = append(mismatches, EmbeddingMismatch{
+				Collection: name, Model: rec.Model, Dimension: rec.Dimension,
+				WantModel: model, WantDimension: dimension,
+			})
+			continue
+		}
+
+		if err := index.CreateCollection(ctx, rec.PhysicalName, dimension); err != nil {
+			return nil, err
+		}
+	}
+
+	return mismatches, nil
+}
+
+// Reindexer re-embeds memories and items with the configured model into
+// shadow collections, then swaps them in. Progress is saved after every batch,
+// so an interrupted run resumes where it stopped.
+type Reindexer struct {
+	db       *storage.DB
+	index    vectors.Index
+	embedder *embeddings.Service
+	store    *storage.VectorCollectionStore
+
+	BatchSize int                                   // Texts per EmbedBatch call (default 32)
+	OnBatch   func(collection string, embedded int) // Called after each batch with the running total
+}
+
+// NewReindexer creates a reindexer
+func NewReindexer(db *storage.DB, index vectors.Index, embedder *embeddings.Service) *Reindexer {
+	return &Reindexer{
+		db:        db,
+		index:     index,
+		embedder:  embedder,
+		store:     storage.NewVectorCollectionStore(db),
+		BatchSize: 32,
+	}
+}
+
+// ReindexResult describes the rebuild of one collection
+type ReindexResult struct {
+	Collection string
+	Shadow     string
+	Embedded   int  // Points embedded in this run
+	Resumed    bool // Continued an interrupted run
+}
+
+// reindexRow is one source row to embed
+type reindexRow struct {
+	rowid   int64
+	pointID string
+	newID   bool // pointID was generated and must be saved on the row
+	text    string
+	payload map[string]interface{}
+}
+
+// Run rebuilds every collection and swaps all of them in together
+func (r *Reindexer) Run(ctx context.Context) ([]ReindexResult, error) {
+	var results []ReindexResult
+	for _, name := range indexedCollections {
+		result, err := r.rebuild(ctx, name)
+		if err != nil {
+			return results, fmt.Errorf("reindex %s: %w", name, err)
+		}
+		results = append(results, *result)
+	}
+
+	// Rows stored while later collections were rebuilt only went to the live
+	// collections; embed them just before swapping so none are lost
+	for i, name := range indexedCollections {
+		job, err := r.store.GetReindex(name)
+		if err != nil {
+			return results, fmt.Errorf("reindex %s: %w", name, err)
+		}
+		if err := r.embedPending(ctx, job, &results[i]); err != nil {
+			return results, fmt.Errorf("reindex %s: %w", name, err)
+		}
+	}
+
+	replaced, err := r.store.Swap(indexedCollections)
+	if err != nil {
+		return results, fmt.Errorf("swap collections: %w", err)
+	}
+
+	// Searches use the new collections from here on; the old ones are garbage
+	for _, old := range replaced {
+		if err := r.index.DeleteCollection(ctx, old); err != nil {
+			fmt.Printf("Warning: failed to delete old collection %s: %v\n", old, err)
+		}
+	}
+
+	return results, nil
+}
+
+// rebuild embeds one collection's source rows into its shadow collection
+func (r *Reindexer) rebuild(ctx context.Context, name string) (*ReindexResult, error) {
+	model, dimension := r.embedder.ModelName(), r.embedder.Dimension()
+
+	job, err := r.store.GetReindex(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// A run for another model is stale; start over
+	if job != nil && (job.Model != model || job.Dimension != dimension) {
+		if err := r.index.DeleteCollection(ctx, job.ShadowName); err != nil {
+			return nil, err
+		}
+		if err := r.store.DeleteReindex(name); err != nil {
+			return nil, err
+		}
+		job = nil
+	}
+
+	result := &ReindexResult{Collection: name, Resumed: job != nil}
+	if job == nil {
+		job = &storage.VectorReindex{
+			Name:       name,
+			ShadowName: fmt.Sprintf("%s_%s", name, time.Now().UTC().Format("20060102150405")),
+			Model:      model,
+			Dimension:  dimension,
+		}
+		if err := r.store.SaveReindex(job); err != nil {
+			return nil, err
+		}
+	}
+	result.Shadow = job.ShadowName
+
+	if err := r.index.CreateCollection(ctx, job.ShadowName, dimension); err != nil {
+		return nil, err
+	}
+
+	if !job.Done {
+		if err := r.embedPending(ctx, job, result); err != nil {
+			return result, err
+		}
+		job.Done = true
+	}
+
+	return result, r.store.SaveReindex(job)
+}
+
+// embedPending embeds the source rows after the job's cursor into its shadow
+// collection, saving progress after every batch
+func (r *Reindexer) embedPending(ctx context.Context, job *storage.VectorReindex, result *ReindexResult) error {
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = 32
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rows, err := r.nextRows(job.Name, job.Cursor, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		embedded, err := r.embedRows(ctx, job.Name, job.ShadowName, rows)
+		if err != nil {
+			return err
+		}
+		result.Embedded += embedded
+
+		job.Cursor = rows[len(rows)-1].rowid
+		if err := r.store.SaveReindex(job); err != nil {
+			return err
+		}
+		if r.OnBatch != nil {
+			r.OnBatch(job.Name, result.Embedded)
+		}
+	}
+}
+
+// embedRows embeds a batch into the shadow collection
+func (r *Reindexer) embedRows(ctx context.Context, name, shadow string, rows []reindexRow) (int, error) {
+	var texts []string
+	var batch []reindexRow
+	for _, row := range rows {
+		if strings.TrimSpace(row.text) != "" {
+			texts = append(texts, row.text)
+			batch = append(batch, row)
+		}
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	embedded, err := r.embedder.EmbedBatch(ctx, texts)
+	if err != nil {
+		return 0, err
+	}
+
+	points := make([]vectors.Point, len(batch))
+	for i, row := range batch {
+		points[i] = vectors.Point{ID: row.pointID, Vector: embedded[i], Payload: row.payload}
+	}
+	if err := r.index.Upsert(ctx, shadow, points); err != nil {
+		return 0, err
+	}
+
+	// Rows without a point yet get one; the live collection never had it, so
+	// recording it early is harmless
+	table := "memories"
+	if name == vectors.CollectionItems {
+		table = "items"
+	}
+	err = r.db.Transaction(func(tx *sql.Tx) error {
+		for _, row := range batch {
+			if !row.newID {
+				continue
+			}
+			if _, err := tx.Exec("UPDATE "+table+" SET embedding_id = ? WHERE rowid = ?", row.pointID, row.rowid); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(batch), nil
+}
+
+// nextRows loads the source rows after the cursor. Rows are walked by rowid,
+// so rows inserted during the run are picked up before it finishes.
+func (r *Reindexer) nextRows(name string, cursor int64, limit int) ([]reindexRow, error) {
+	if name == vectors.CollectionItems {
+		return r.nextItems(cursor, limit)
+	}
+	return r.nextMemories(cursor, limit)
+}
+
+func (r *Reindexer) nextMemories(cursor int64, limit int) ([]reindexRow, error) {
+	rows, err := r.db.Conn().Query(`
+		SELECT rowid, id, type, content, hat_id, importance, embedding_id, created_at
+		FROM memories
+		WHERE rowid > ? AND archived_at IS NULL
+		ORDER BY rowid
+		LIMIT ?
+	`, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []reindexRow
+	for rows.Next() {
+		var row reindexRow
+		var id, memType, content string
+		var hatID, embeddingID sql.NullString
+		var importance float64
+		var createdAt time.Time
+		if err := rows.Scan(&row.rowid, &id, &memType, &content, &hatID, &importance, &embeddingID, &createdAt); err != nil {
+			return nil, err
+		}
+
+		row.pointID, row.newID = pointID(embeddingID.String)
+		row.text = content
+		// Same payload as Store
+		row.payload = map[string]interface{}{
+			"memory_id":  id,
+			"type":       memType,
+			"hat_id":     hatID.String,
+			"importance": importance,
+			"created_at": createdAt.Unix(),
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *Reindexer) nextItems(cursor int64, limit int) ([]reindexRow, error) {
+	rows, err := r.db.Conn().Query(`
+		SELECT rowid, id, type, hat_id, space_id, subject, body, embedding_id
+		FROM items
+		WHERE rowid > ?
+		ORDER BY rowid
+		LIMIT ?
+	`, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []reindexRow
+	for rows.Next() {
+		var row reindexRow
+		var id, itemType, hatID string
+		var spaceID, subject, body, embeddingID sql.NullString
+		if err := rows.Scan(&row.rowid, &id, &itemType, &hatID, &spaceID, &subject, &body, &embeddingID); err != nil {
+			return nil, err
+		}
+
+		row.pointID, row.newID = pointID(embeddingID.String)
+		row.text = truncateRunes(strings.TrimSpace(subject.String+"\n\n"+body.String), 2000)
+		row.payload = map[string]interface{}{
+			"item_id":  id,
+			"type":     itemType,
+			"hat_id":   hatID,
+			"space_id": spaceID.String,
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// pointID reuses a row's vector point ID, or generates one. Point IDs must be
+// UUIDs for Qdrant.
+func pointID(existing string) (string, bool) {
+	if _, err := uuid.Parse(existing); err == nil {
+		return existing, false
+	}
+	return uuid.New().String(), true
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/embeddings"
+	"github.com/quantumlife/quantumlife/internal/storage"
+	"github.com/quantumlife/quantumlife/internal/vectors"
+)
+
+// mockModelServer embeds with a dimension that depends on the model, like
+// switching between real embedding models. It counts batch requests.
+func mockModelServer(t *testing.T, batches *int32) *httptest.Server {
+	t.Helper()
+	dims := map[string]int{"small-embed": 3, "large-embed": 4}
+	vector := func(model string) []float32 {
+		v := make([]float32, dims[model])
+		for i := range v {
+			v[i] = float32(i + 1)
+		}
+		return v
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/embeddings":
+			json.NewEncoder(w).Encode(map[string]interface{}{"embedding": vector(req.Model)})
+		case "/api/embed":
+			atomic.AddInt32(batches, 1)
+			out := make([][]float32, len(req.Input))
+			for i := range out {
+				out[i] = vector(req.Model)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": out})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReindex_ModelChange(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	var batches int32
+	server := mockModelServer(t, &batches)
+
+	dir := t.TempDir()
+	index, err := vectors.NewEmbeddedStore(dir)
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+	defer index.Close()
+
+	// Build the index with the original model
+	small := embeddings.NewService(embeddings.Config{BaseURL: server.URL, Model: "small-embed"})
+	if dim, err := small.DetectDimension(ctx); err != nil || dim != 3 {
+		t.Fatalf("DetectDimension() = %d, %v", dim, err)
+	}
+	if mismatches, err := PrepareIndex(ctx, db, index, small); err != nil || len(mismatches) != 0 {
+		t.Fatalf("PrepareIndex() = %v, %v", mismatches, err)
+	}
+
+	m := NewManager(db, index, small)
+	for _, content := range []string{"Prefers aisle seats", "Allergic to peanuts", "Gym on Tuesdays", "Likes jazz", "Reads sci-fi"} {
+		if err := m.StoreSemantic(ctx, content, core.HatPersonal, 0.6); err != nil {
+			t.Fatalf("StoreSemantic() error = %v", err)
+		}
+	}
+	items := storage.NewItemStore(db)
+	items.Create(&core.Item{ID: "item-1", Type: core.ItemTypeEmail, Status: core.ItemStatusPending,
+		HatID: core.HatPersonal, Subject: "Flight itinerary", Body: "Seat 14C"})
+	items.Create(&core.Item{ID: "item-2", Type: core.ItemTypeEmail, Status: core.ItemStatusPending,
+		HatID: core.HatPersonal})
+
+	// Switching models is detected
+	large := embeddings.NewService(embeddings.Config{BaseURL: server.URL, Model: "large-embed"})
+	large.DetectDimension(ctx)
+	mismatches, err := PrepareIndex(ctx, db, index, large)
+	if err != nil {
+		t.Fatalf("PrepareIndex() error = %v", err)
+	}
+	if len(mismatches) != 2 || mismatches[0].Model != "small-embed" || mismatches[0].WantDimension != 4 {
+		t.Fatalf("PrepareIndex() mismatches = %+v", mismatches)
+	}
+
+	// Interrupt the reindex after the first batch
+	reindexer := NewReindexer(db, index, large)
+	reindexer.BatchSize = 2
+	runCtx, cancel := context.WithCancel(ctx)
+	reindexer.OnBatch = func(collection string, embedded int) { cancel() }
+	if _, err := reindexer.Run(runCtx); err == nil {
+		t.Fatal("Run() should stop when cancelled")
+	}
+
+	// The live collection is untouched until the swap
+	store := storage.NewVectorCollectionStore(db)
+	if rec, _ := store.Get(vectors.CollectionMemories); rec.PhysicalName != vectors.CollectionMemories {
+		t.Errorf("collection swapped before reindex finished: %+v", rec)
+	}
+	job, _ := store.GetReindex(vectors.CollectionMemories)
+	if job == nil || job.Cursor == 0 || job.Done {
+		t.Fatalf("progress not saved: %+v", job)
+	}
+
+	// Resume. A memory stored once its collection is rebuilt still reaches
+	// the new collection.
+	stored := false
+	reindexer.OnBatch = func(collection string, embedded int) {
+		if collection == vectors.CollectionItems && !stored {
+			stored = true
+			if err := m.StoreSemantic(ctx, "Takes the early train", core.HatPersonal, 0.5); err != nil {
+				t.Errorf("StoreSemantic() during reindex error = %v", err)
+			}
+		}
+	}
+	results, err := reindexer.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(results) != 2 || !results[0].Resumed || results[0].Embedded != 4 || results[1].Embedded != 1 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+	if atomic.LoadInt32(&batches) == 0 {
+		t.Error("EmbedBatch did not use the batch endpoint")
+	}
+
+	rec, _ := store.Get(vectors.CollectionMemories)
+	if rec.PhysicalName != results[0].Shadow || rec.Model != "large-embed" || rec.Dimension != 4 {
+		t.Errorf("collection not swapped: %+v", rec)
+	}
+	if job, _ := store.GetReindex(vectors.CollectionMemories); job != nil {
+		t.Errorf("progress not cleared: %+v", job)
+	}
+	if _, err := os.Stat(filepath.Join(dir, vectors.CollectionMemories+".vlog")); !os.IsNotExist(err) {
+		t.Errorf("old collection not dropped: %v", err)
+	}
+	if mismatches, _ := PrepareIndex(ctx, db, index, large); len(mismatches) != 0 {
+		t.Errorf("mismatches after reindex: %+v", mismatches)
+	}
+
+	// Items got point IDs recorded
+	if item, _ := items.GetByID("item-1"); item.EmbeddingID == "" {
+		t.Error("item embedding ID not recorded")
+	}
+
+	// Semantic search works with the new model, and new memories land in the
+	// new collection
+	m = NewManager(db, index, large)
+	memories, err := m.Retrieve(ctx, "seats", RetrieveOptions{Mode: RetrievalSemantic})
+	if err != nil || len(memories) != 6 {
+		t.Errorf("Retrieve() after reindex = %d memories, %v", len(memories), err)
+	}
+	if err := m.StoreSemantic(ctx, "Vegetarian", core.HatPersonal, 0.5); err != nil {
+		t.Errorf("StoreSemantic() after reindex error = %v", err)
+	}
+	if n := index.Count(rec.PhysicalName); n != 7 {
+		t.Errorf("Count(%s) = %d, want 7", rec.PhysicalName, n)
+	}
+}
@@ -2,10 +2,12 @@ package proactive
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/storage"
 )
 
@@ -243,6 +245,130 @@ func TestRecommendationEngine_GenerateFromTrigger(t *testing.T) {
 	}
 }
 
+func usd(amount float64) finance.Money {
+	return finance.NewMoney(amount, "USD")
+}
+
+type mockFinanceSource struct {
+	budgets  []BudgetStatus
+	warnings []CashFlowWarning
+	err      error // Returned for budgets
+}
+
+func (m *mockFinanceSource) BudgetStatuses(ctx context.Context) ([]BudgetStatus, error) {
+	return m.budgets, m.err
+}
+
+func (m *mockFinanceSource) CashFlowWarnings(ctx context.Context) ([]CashFlowWarning, error) {
+	return m.warnings, nil
+}
+
+func TestTriggerDetector_BudgetThreshold(t *testing.T) {
+	db := testDB(t)
+	config := DefaultTriggerConfig()
+	config.EnableTimeTriggers = false
+	config.EnableEventTriggers = false
+	detector := NewTriggerDetector(db, nil, config)
+	detector.SetFinanceSource(&mockFinanceSource{budgets: []BudgetStatus{
+		{Category: "dining", PeriodStart: "2026-10-01", PeriodEnd: "2026-10-31", Allowance: usd(200), Spent: usd(170)},
+		{Category: "groceries", PeriodStart: "2026-10-01", PeriodEnd: "2026-10-31", Allowance: usd(500), Spent: usd(520)},
+		{Category: "travel", PeriodStart: "2026-10-01", PeriodEnd: "2026-10-31", Allowance: usd(1000), Spent: usd(100)},
+		{Category: "shopping", PeriodStart: "2026-10-01", PeriodEnd: "2026-10-31", Allowance: usd(0), Spent: usd(30)},
+	}})
+	engine := NewRecommendationEngine(db, nil, detector, DefaultRecommendationConfig())
+
+	triggers, err := detector.DetectTriggers(context.Background())
+	if err != nil {
+		t.Fatalf("DetectTriggers failed: %v", err)
+	}
+
+	got := make(map[string]Trigger)
+	for _, trig := range triggers {
+		if trig.Type == TriggerBudgetThreshold {
+			got[trig.ID] = trig
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 budget triggers, got %d: %v", len(got), got)
+	}
+	if trig, ok := got["trig_budget_dining_2026-10-01_warning"]; !ok || trig.Priority != 3 || trig.HatID != core.HatFinance {
+		t.Errorf("dining trigger = %+v", trig)
+	}
+	exceeded, ok := got["trig_budget_groceries_2026-10-01_exceeded"]
+	if !ok || exceeded.Priority != 2 {
+		t.Errorf("groceries trigger = %+v", exceeded)
+	}
+	if _, ok := got["trig_budget_shopping_2026-10-01_exceeded"]; !ok {
+		t.Error("expected spending without a budget to count as exceeded")
+	}
+
+	recs := engine.generateFromTrigger(context.Background(), exceeded)
+	if len(recs) != 1 || recs[0].Title != "Budget exceeded: groceries" || recs[0].Description != "Spent 520.00 of 500.00 USD this period" {
+		t.Errorf("recommendations = %+v", recs)
+	}
+}
+
+func TestTriggerDetector_CashFlowWarning(t *testing.T) {
+	db := testDB(t)
+	config := DefaultTriggerConfig()
+	config.EnableTimeTriggers = false
+	config.EnableEventTriggers = false
+	detector := NewTriggerDetector(db, nil, config)
+	detector.SetFinanceSource(&mockFinanceSource{warnings: []CashFlowWarning{
+		{AccountID: "acc_1", AccountName: "Checking", Date: "2026-10-28", Balance: usd(42.50), Threshold: usd(100),
+			Payday: "2026-10-31", Bills: []string{"Rent", "Netflix"}},
+		{AccountID: "acc_2", AccountName: "Joint", Date: "2026-11-02", Balance: usd(-20), Threshold: usd(100)},
+	}})
+	engine := NewRecommendationEngine(db, nil, detector, DefaultRecommendationConfig())
+
+	triggers, err := detector.DetectTriggers(context.Background())
+	if err != nil {
+		t.Fatalf("DetectTriggers failed: %v", err)
+	}
+
+	got := make(map[string]Trigger)
+	for _, trig := range triggers {
+		if trig.Type == TriggerBillDue {
+			got[trig.ID] = trig
+		}
+	}
+	low, ok := got["trig_cashflow_acc_1_2026-10-28"]
+	if !ok || low.Priority != 2 || low.HatID != core.HatFinance {
+		t.Errorf("low balance trigger = %+v", low)
+	}
+	if overdrawn, ok := got["trig_cashflow_acc_2_2026-11-02"]; !ok || overdrawn.Priority != 1 {
+		t.Errorf("overdrawn trigger = %+v", overdrawn)
+	}
+
+	recs := engine.generateFromTrigger(context.Background(), low)
+	want := "Checking is forecast to be at 42.50 USD on 2026-10-28 after Rent, Netflix, before payday on 2026-10-31"
+	if len(recs) != 1 || recs[0].Title != "Low balance ahead: Checking" || recs[0].Description != want {
+		t.Errorf("recommendations = %+v", recs)
+	}
+}
+
+func TestTriggerDetector_FinanceErrorKeepsOtherTriggers(t *testing.T) {
+	db := testDB(t)
+	config := DefaultTriggerConfig()
+	config.EnableTimeTriggers = false
+	config.EnableEventTriggers = false
+	detector := NewTriggerDetector(db, nil, config)
+	detector.SetFinanceSource(&mockFinanceSource{
+		err: errors.New("finance store locked"),
+		warnings: []CashFlowWarning{
+			{AccountID: "acc_1", AccountName: "Checking", Date: "2026-10-28", Balance: usd(42.50), Threshold: usd(100)},
+		},
+	})
+
+	triggers, err := detector.DetectTriggers(context.Background())
+	if err != nil {
+		t.Fatalf("DetectTriggers failed: %v", err)
+	}
+	if len(triggers) != 1 || triggers[0].ID != "trig_cashflow_acc_1_2026-10-28" {
+		t.Errorf("triggers = %+v, want the cash flow warning", triggers)
+	}
+}
+
 func TestRecommendationEngine_StoreAndRetrieve(t *testing.T) {
 	db := testDB(t)
 	triggerDetector := NewTriggerDetector(db, nil, DefaultTriggerConfig())
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/quantumlife/quantumlife/internal/core"
@@ -332,6 +333,70 @@ func (e *RecommendationEngine) generateFromTrigger(ctx context.Context, trigger
 			CreatedAt:   now,
 		})
 
+	case TriggerBudgetThreshold:
+		category, _ := trigger.Context["category"].(string)
+		spent, _ := trigger.Context["spent"].(string)
+		allowance, _ := trigger.Context["allowance"].(string)
+		percent, _ := trigger.Context["percent_used"].(float64)
+		currency, _ := trigger.Context["currency"].(string)
+		title := fmt.Sprintf("Budget alert: %.0f%% of %s spent", percent, category)
+		if percent >= 100 {
+			title = fmt.Sprintf("Budget exceeded: %s", category)
+		}
+		recs = append(recs, Recommendation{
+			ID:          fmt.Sprintf("rec_%s", trigger.ID),
+			Type:        RecTypeTrend,
+			Title:       title,
+			Description: fmt.Sprintf("Spent %s of %s %s this period", spent, allowance, currency),
+			Priority:    trigger.Priority,
+			Confidence:  trigger.Confidence,
+			Impact:      "Stay within your budget",
+			Actions: []RecommendedAction{
+				{ID: "view_budgets", Label: "View budgets", ActionType: "open_url", Payload: map[string]interface{}{"url": "/finance/budgets"}, IsPrimary: true},
+				{ID: "transfer", Label: "Move money from another envelope", ActionType: "execute", Payload: map[string]interface{}{"action": "transfer_budget", "to": category}},
+			},
+			Context:     trigger.Context,
+			HatID:       trigger.HatID,
+			TriggerID:   trigger.ID,
+			Status:      RecStatusPending,
+			ExpiresAt:   trigger.ExpiresAt,
+			CreatedAt:   now,
+		})
+
+	case TriggerBillDue:
+		account, _ := trigger.Context["account_name"].(string)
+		date, _ := trigger.Context["date"].(string)
+		balance, _ := trigger.Context["balance"].(string)
+		currency, _ := trigger.Context["currency"].(string)
+		payday, _ := trigger.Context["payday"].(string)
+		bills, _ := trigger.Context["bills"].([]string)
+		description := fmt.Sprintf("%s is forecast to be at %s %s on %s", account, balance, currency, date)
+		if len(bills) > 0 {
+			description += fmt.Sprintf(" after %s", strings.Join(bills, ", "))
+		}
+		if payday != "" {
+			description += fmt.Sprintf(", before payday on %s", payday)
+		}
+		recs = append(recs, Recommendation{
+			ID:          fmt.Sprintf("rec_%s", trigger.ID),
+			Type:        RecTypeAction,
+			Title:       fmt.Sprintf("Low balance ahead: %s", account),
+			Description: description,
+			Priority:    trigger.Priority,
+			Confidence:  trigger.Confidence,
+			Impact:      "Avoid overdraft fees and declined payments",
+			Actions: []RecommendedAction{
+				{ID: "view_forecast", Label: "View forecast", ActionType: "open_url", Payload: map[string]interface{}{"url": "/finance/forecast"}, IsPrimary: true},
+				{ID: "dismiss", Label: "Dismiss", ActionType: "dismiss"},
+			},
+			Context:     trigger.Context,
+			HatID:       trigger.HatID,
+			TriggerID:   trigger.ID,
+			Status:      RecStatusPending,
+			ExpiresAt:   trigger.ExpiresAt,
+			CreatedAt:   now,
+		})
+
 	case TriggerInactivityWarning:
 		daysInactive := trigger.Context["days_inactive"].(float64)
 		recs = append(recs, Recommendation{
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/quantumlife/quantumlife/internal/finance"
 	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/learning"
 	"github.com/quantumlife/quantumlife/internal/storage"
@@ -69,9 +70,38 @@ type TriggerDetector struct {
 	db             *storage.DB
 	learningService *learning.Service
 	itemStore      *storage.ItemStore
+	finance        FinanceSource
 	config         TriggerConfig
 }
 
+// BudgetStatus is how much of a budget envelope has been spent in the
+// current period
+type BudgetStatus struct {
+	Category    string
+	PeriodStart string        // YYYY-MM-DD
+	PeriodEnd   string        // Last day of the period
+	Allowance   finance.Money // Budgeted plus carried over and transferred in
+	Spent       finance.Money
+}
+
+// CashFlowWarning is a cash account forecast to drop below the low
+// balance threshold before the next payday
+type CashFlowWarning struct {
+	AccountID   string
+	AccountName string
+	Date        string        // First day below the threshold, YYYY-MM-DD
+	Balance     finance.Money // Projected balance that day
+	Threshold   finance.Money
+	Payday      string        // Next forecast income, empty if none
+	Bills       []string // Payments that take the balance there
+}
+
+// FinanceSource supplies the finance state finance triggers watch
+type FinanceSource interface {
+	BudgetStatuses(ctx context.Context) ([]BudgetStatus, error)
+	CashFlowWarnings(ctx context.Context) ([]CashFlowWarning, error)
+}
+
 // TriggerConfig configures trigger detection
 type TriggerConfig struct {
 	// Time-based settings
@@ -83,6 +113,7 @@ type TriggerConfig struct {
 	DeadlineWarningHours int  // Hours before deadline to warn (default: 24)
 	MeetingWarningMins   int  // Minutes before meeting to warn (default: 15)
 	InactivityDays       int  // Days of inactivity to warn (default: 7)
+	BudgetWarningPercent float64 // Percent of a budget spent to warn at (default: 80)
 
 	// VIP settings
 	VIPResponseThreshold time.Duration // Expected response time for VIPs
@@ -91,6 +122,7 @@ type TriggerConfig struct {
 	EnableTimeTriggers    bool
 	EnableEventTriggers   bool
 	EnablePatternTriggers bool
+	EnableFinanceTriggers bool
 }
 
 // DefaultTriggerConfig returns sensible defaults
@@ -102,10 +134,12 @@ func DefaultTriggerConfig() TriggerConfig {
 		DeadlineWarningHours:  24,
 		MeetingWarningMins:    15,
 		InactivityDays:        7,
+		BudgetWarningPercent:  80,
 		VIPResponseThreshold:  2 * time.Hour,
 		EnableTimeTriggers:    true,
 		EnableEventTriggers:   true,
 		EnablePatternTriggers: true,
+		EnableFinanceTriggers: true,
 	}
 }
 
@@ -119,6 +153,12 @@ func NewTriggerDetector(db *storage.DB, learningService *learning.Service, confi
 	}
 }
 
+// SetFinanceSource enables finance triggers on source's budgets and
+// cash-flow forecast. Set it before the detector is used.
+func (d *TriggerDetector) SetFinanceSource(source FinanceSource) {
+	d.finance = source
+}
+
 // DetectTriggers scans for active triggers
 func (d *TriggerDetector) DetectTriggers(ctx context.Context) ([]Trigger, error) {
 	var triggers []Trigger
@@ -144,6 +184,10 @@ func (d *TriggerDetector) DetectTriggers(ctx context.Context) ([]Trigger, error)
 		triggers = append(triggers, patternTriggers...)
 	}
 
+	if d.config.EnableFinanceTriggers && d.finance != nil {
+		triggers = append(triggers, d.detectFinanceTriggers(ctx)...)
+	}
+
 	return triggers, nil
 }
 
@@ -357,6 +401,115 @@ func (d *TriggerDetector) detectPatternTriggers(ctx context.Context) ([]Trigger,
 	return triggers, nil
 }
 
+// detectFinanceTriggers warns about budget envelopes nearly or fully
+// spent this period, and accounts forecast to run low. Each envelope warns
+// once at the threshold and once more when it is exceeded. Finance data
+// that can't be read is logged and skipped, so other triggers still fire.
+func (d *TriggerDetector) detectFinanceTriggers(ctx context.Context) []Trigger {
+	var triggers []Trigger
+	now := time.Now()
+
+	statuses, err := d.finance.BudgetStatuses(ctx)
+	if err != nil {
+		fmt.Printf("Error loading budgets for triggers: %v\n", err)
+	}
+
+	threshold := d.config.BudgetWarningPercent
+	if threshold <= 0 {
+		threshold = 80
+	}
+
+	for _, b := range statuses {
+		if b.Spent.Minor <= 0 {
+			continue
+		}
+		percent := 100.0
+		if b.Allowance.Minor > 0 {
+			percent = float64(b.Spent.Minor) / float64(b.Allowance.Minor) * 100
+		}
+		if percent < threshold {
+			continue
+		}
+
+		level, priority := "warning", 3
+		if percent >= 100 {
+			level, priority = "exceeded", 2
+		}
+
+		// Warn until the period ends
+		expires, err := time.ParseInLocation("2006-01-02", b.PeriodEnd, time.Local)
+		if err != nil {
+			expires = now
+		}
+
+		triggers = append(triggers, Trigger{
+			ID:         fmt.Sprintf("trig_budget_%s_%s_%s", b.Category, b.PeriodStart, level),
+			Type:       TriggerBudgetThreshold,
+			Priority:   priority,
+			Confidence: 1.0,
+			Context: map[string]interface{}{
+				"category":     b.Category,
+				"period_start": b.PeriodStart,
+				"period_end":   b.PeriodEnd,
+				"allowance":    b.Allowance.Decimal(),
+				"spent":        b.Spent.Decimal(),
+				"percent_used": percent,
+				"currency":     b.Spent.Currency,
+			},
+			HatID:     core.HatFinance,
+			ExpiresAt: expires.Add(24 * time.Hour),
+			CreatedAt: now,
+		})
+	}
+
+	warnings, err := d.finance.CashFlowWarnings(ctx)
+	if err != nil {
+		fmt.Printf("Error loading cash flow forecast for triggers: %v\n", err)
+	}
+	triggers = append(triggers, d.cashFlowTriggers(warnings, now)...)
+
+	return triggers
+}
+
+// cashFlowTriggers warns about bills forecast to take an account below
+// its low balance threshold before payday. Each dip warns once.
+func (d *TriggerDetector) cashFlowTriggers(warnings []CashFlowWarning, now time.Time) []Trigger {
+	var triggers []Trigger
+	for _, w := range warnings {
+		priority := 2
+		if w.Balance.Minor < 0 {
+			priority = 1 // Overdrawn
+		}
+
+		// Warn until the day it happens
+		expires, err := time.ParseInLocation("2006-01-02", w.Date, time.Local)
+		if err != nil {
+			expires = now
+		}
+
+		triggers = append(triggers, Trigger{
+			ID:         fmt.Sprintf("trig_cashflow_%s_%s", w.AccountID, w.Date),
+			Type:       TriggerBillDue,
+			Priority:   priority,
+			Confidence: 0.8, // Recurring payments are predicted
+			Context: map[string]interface{}{
+				"account_id":   w.AccountID,
+				"account_name": w.AccountName,
+				"date":         w.Date,
+				"balance":      w.Balance.Decimal(),
+				"threshold":    w.Threshold.Decimal(),
+				"currency":     w.Balance.Currency,
+				"payday":       w.Payday,
+				"bills":        w.Bills,
+			},
+			HatID:     core.HatFinance,
+			ExpiresAt: expires.Add(24 * time.Hour),
+			CreatedAt: now,
+		})
+	}
+	return triggers
+}
+
 // detectFollowUpNeeded finds items that may need follow-up
 func (d *TriggerDetector) detectFollowUpNeeded(ctx context.Context) ([]Trigger, error) {
 	var triggers []Trigger
@@ -342,7 +342,19 @@ func (c *Client) FindFreeTime(ctx context.Context, start, end time.Time, duratio
 		return nil, err
 	}
 
-	// Find gaps
+	return FreeSlots(events, start, end, time.Duration(durationMinutes)*time.Minute), nil
+}
+
+// TimeSlot represents a free time slot
+type TimeSlot struct {
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+}
+
+// FreeSlots returns the gaps of at least minDuration between start and end
+// that are not covered by events. Events must be sorted by start time.
+func FreeSlots(events []Event, start, end time.Time, minDuration time.Duration) []TimeSlot {
 	slots := make([]TimeSlot, 0)
 	current := start
 
@@ -350,7 +362,7 @@ func (c *Client) FindFreeTime(ctx context.Context, start, end time.Time, duratio
 		if event.Start.After(current) {
 			// There's a gap
 			gap := event.Start.Sub(current)
-			if gap >= time.Duration(durationMinutes)*time.Minute {
+			if gap >= minDuration {
 				slots = append(slots, TimeSlot{
 					Start:    current,
 					End:      event.Start,
@@ -366,7 +378,7 @@ func (c *Client) FindFreeTime(ctx context.Context, start, end time.Time, duratio
 	// Check for remaining time at the end
 	if end.After(current) {
 		gap := end.Sub(current)
-		if gap >= time.Duration(durationMinutes)*time.Minute {
+		if gap >= minDuration {
 			slots = append(slots, TimeSlot{
 				Start:    current,
 				End:      end,
@@ -375,14 +387,7 @@ func (c *Client) FindFreeTime(ctx context.Context, start, end time.Time, duratio
 		}
 	}
 
-	return slots, nil
-}
-
-// TimeSlot represents a free time slot
-type TimeSlot struct {
-	Start    time.Time     `json:"start"`
-	End      time.Time     `json:"end"`
-	Duration time.Duration `json:"duration"`
+	return slots
 }
 
 // GetFreeBusy checks free/busy information for calendars
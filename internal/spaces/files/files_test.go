@@ -0,0 +1,368 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+)
+
+const projectNote = `---
+title: Project Alpha
+tags: [work, planning]
+hat: Professional
+author: Jane
+---
+# Alpha kickoff
+
+Discussed scope with [[Bob Smith]] and [[Budget 2025|the budget]].
+See ![[diagram.png]] and [[Roadmap#Q3]]. #work/urgent #idea
+
+` + "```go\n// #notatag [[NotALink]]\n```\n"
+
+func writeNote(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseNote(t *testing.T) {
+	modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	note := ParseNote("projects/alpha.md", []byte(projectNote), modTime)
+
+	if note.Title != "Project Alpha" {
+		t.Errorf("Title = %q", note.Title)
+	}
+	wantTags := []string{"idea", "planning", "work", "work/urgent"}
+	if !reflect.DeepEqual(note.Tags, wantTags) {
+		t.Errorf("Tags = %v, want %v", note.Tags, wantTags)
+	}
+	wantLinks := []string{"Bob Smith", "Budget 2025", "diagram.png", "Roadmap"}
+	if !reflect.DeepEqual(note.Links, wantLinks) {
+		t.Errorf("Links = %v, want %v", note.Links, wantLinks)
+	}
+	if strings.HasPrefix(note.Body, "---") {
+		t.Error("Body still contains front-matter")
+	}
+	if note.HatID() != core.HatProfessional {
+		t.Errorf("HatID() = %q", note.HatID())
+	}
+	if !note.HasTag("#work") || note.HasTag("wor") {
+		t.Error("HasTag() mismatch")
+	}
+
+	item := note.ToItem("vault")
+	if item.Type != core.ItemTypeNote || item.ExternalID != "projects/alpha.md" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if item.Subject != "Project Alpha" || item.From != "Jane" || !item.Timestamp.Equal(modTime) {
+		t.Errorf("unexpected item fields: %+v", item)
+	}
+	if len(item.Entities) != len(wantLinks)+len(wantTags) {
+		t.Errorf("Entities = %v", item.Entities)
+	}
+}
+
+func TestParseNote_Title(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{"heading", "a.md", "intro\n# First Heading\n", "First Heading"},
+		{"file name", "dir/Meeting notes.md", "no heading here", "Meeting notes"},
+		{"crlf front-matter", "b.md", "---\r\ntitle: Windows\r\n---\r\nbody", "Windows"},
+		{"bom", "c.md", "\ufeff---\ntitle: Bom\n---\n", "Bom"},
+		{"unterminated front-matter", "d.md", "---\ntitle: x\n", "d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseNote(tt.path, []byte(tt.content), time.Now()).Title; got != tt.want {
+				t.Errorf("Title = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVault_ListAndGet(t *testing.T) {
+	dir := t.TempDir()
+	writeNote(t, dir, "projects/alpha.md", projectNote)
+	writeNote(t, dir, "inbox.md", "# Inbox\n")
+	writeNote(t, dir, ".obsidian/workspace.md", "hidden")
+	writeNote(t, dir, "image.png", "binary")
+
+	vault, err := NewVault(dir)
+	if err != nil {
+		t.Fatalf("NewVault() error = %v", err)
+	}
+	ctx := context.Background()
+
+	notes, err := vault.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("List() returned %d notes, want 2", len(notes))
+	}
+
+	for _, path := range []string{"projects/alpha.md", "projects/alpha", "alpha", "Project Alpha"} {
+		note, err := vault.Get(ctx, path)
+		if err != nil {
+			t.Errorf("Get(%q) error = %v", path, err)
+			continue
+		}
+		if note.Path != "projects/alpha.md" {
+			t.Errorf("Get(%q).Path = %q", path, note.Path)
+		}
+	}
+
+	if _, err := vault.Get(ctx, "missing"); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNoteNotFound", err)
+	}
+	if _, err := vault.Get(ctx, "../outside.md"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Get(../outside.md) error = %v, want ErrInvalidPath", err)
+	}
+
+	if _, err := NewVault(filepath.Join(dir, "inbox.md")); err == nil {
+		t.Error("NewVault() on a file should fail")
+	}
+}
+
+func TestVault_Search(t *testing.T) {
+	dir := t.TempDir()
+	writeNote(t, dir, "projects/alpha.md", projectNote)
+	writeNote(t, dir, "budget.md", "# Budget\nThe budget for alpha.\n#finance")
+	writeNote(t, dir, "misc.md", "# Misc\nnothing relevant")
+
+	vault, _ := NewVault(dir)
+	ctx := context.Background()
+
+	results, err := vault.Search(ctx, SearchQuery{Text: "budget"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Path != "budget.md" {
+		t.Errorf("Search(budget) = %v", notePaths(results))
+	}
+
+	results, _ = vault.Search(ctx, SearchQuery{Tag: "work"})
+	if len(results) != 1 || results[0].Path != "projects/alpha.md" {
+		t.Errorf("Search(tag work) = %v", notePaths(results))
+	}
+
+	results, _ = vault.Search(ctx, SearchQuery{Text: "alpha", Limit: 1})
+	if len(results) != 1 || results[0].Path != "projects/alpha.md" {
+		t.Errorf("Search(alpha, limit 1) = %v", notePaths(results))
+	}
+}
+
+func TestVault_CreateAndAppend(t *testing.T) {
+	dir := t.TempDir()
+	vault, _ := NewVault(dir)
+	ctx := context.Background()
+
+	note, err := vault.Create(ctx, CreateNoteRequest{
+		Title:  "Call with Bob: follow-up",
+		Folder: "meetings",
+		Body:   "Talked about [[Project Alpha]].",
+		Tags:   []string{"#Meeting"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if note.Path != "meetings/Call with Bob- follow-up.md" {
+		t.Errorf("Path = %q", note.Path)
+	}
+	if note.Title != "Call with Bob: follow-up" || !note.HasTag("meeting") {
+		t.Errorf("unexpected note: %+v", note)
+	}
+	if len(note.Links) != 1 || note.Links[0] != "Project Alpha" {
+		t.Errorf("Links = %v", note.Links)
+	}
+
+	if _, err := vault.Create(ctx, CreateNoteRequest{Title: "Call with Bob: follow-up", Folder: "meetings"}); !errors.Is(err, ErrNoteExists) {
+		t.Errorf("duplicate Create() error = %v, want ErrNoteExists", err)
+	}
+	if _, err := vault.Create(ctx, CreateNoteRequest{Title: "escape", Folder: "../.."}); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Create() outside vault error = %v, want ErrInvalidPath", err)
+	}
+
+	note, err = vault.Append(ctx, note.Path, "- send the deck", "Action Items")
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if !strings.HasSuffix(note.Body, "## Action Items\n\n- send the deck\n") {
+		t.Errorf("Body after new section:\n%s", note.Body)
+	}
+
+	note, err = vault.Append(ctx, note.Path, "- book a room", "action items")
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if !strings.Contains(note.Body, "- send the deck\n\n- book a room\n") {
+		t.Errorf("Body after section append:\n%s", note.Body)
+	}
+
+	if _, err := vault.Append(ctx, "missing.md", "text", ""); !errors.Is(err, ErrNoteNotFound) {
+		t.Errorf("Append(missing) error = %v", err)
+	}
+}
+
+func TestAppendText(t *testing.T) {
+	doc := "# Title\n\n## Tasks\n\n- one\n\n## Notes\n\nsome notes\n"
+
+	got := appendText(doc, "- two", "Tasks")
+	want := "# Title\n\n## Tasks\n\n- one\n\n- two\n\n## Notes\n\nsome notes\n"
+	if got != want {
+		t.Errorf("section append:\n%q\nwant\n%q", got, want)
+	}
+
+	got = appendText(doc, "the end", "")
+	if !strings.HasSuffix(got, "some notes\n\nthe end\n") {
+		t.Errorf("document append: %q", got)
+	}
+}
+
+func TestSpace_Sync(t *testing.T) {
+	dir := t.TempDir()
+	writeNote(t, dir, "projects/alpha.md", projectNote)
+	old := writeNote(t, dir, "old.md", "# Old\n")
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(old, past, past)
+
+	var mu sync.Mutex
+	var received []*core.Item
+	space := New(Config{
+		ID:           "vault",
+		Name:         "Vault",
+		DefaultHatID: core.HatPersonal,
+		Path:         dir,
+		OnItems: func(ctx context.Context, items []*core.Item) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, items...)
+			return nil
+		},
+	})
+
+	if space.Type() != core.SpaceTypeFiles || space.Provider() != "markdown" {
+		t.Errorf("Type/Provider = %s/%s", space.Type(), space.Provider())
+	}
+	if _, err := space.Sync(context.Background()); err == nil {
+		t.Error("Sync() before Connect should fail")
+	}
+	if err := space.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	result, err := space.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.NewItems != 2 || result.Cursor == "" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	hats := map[string]core.HatID{}
+	for _, item := range received {
+		hats[item.ExternalID] = item.HatID
+	}
+	if hats["projects/alpha.md"] != core.HatProfessional || hats["old.md"] != core.HatPersonal {
+		t.Errorf("hats = %v", hats)
+	}
+
+	// Only notes modified after the cursor are reported again
+	received = nil
+	future := time.Now().Add(time.Hour)
+	os.Chtimes(old, future, future)
+
+	result, err = space.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("second Sync() error = %v", err)
+	}
+	if result.UpdatedItems != 1 || len(received) != 1 || received[0].ExternalID != "old.md" {
+		t.Errorf("incremental sync: result %+v, items %d", result, len(received))
+	}
+}
+
+func TestSpace_Watch(t *testing.T) {
+	dir := t.TempDir()
+	existing := writeNote(t, dir, "existing.md", "# Existing\n")
+
+	changed := make(chan string, 10)
+	removed := make(chan string, 10)
+	space := New(Config{
+		ID:   "vault",
+		Path: dir,
+		OnItems: func(ctx context.Context, items []*core.Item) error {
+			for _, item := range items {
+				changed <- item.ExternalID
+			}
+			return nil
+		},
+		OnRemoved: func(ctx context.Context, externalIDs []string) error {
+			for _, id := range externalIDs {
+				removed <- id
+			}
+			return nil
+		},
+	})
+	if err := space.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- space.Watch(ctx) }()
+	time.Sleep(100 * time.Millisecond) // Let the watcher register
+
+	// A note in a folder created after the watch started
+	writeNote(t, dir, "new/idea.md", "# Idea\n")
+	waitFor(t, changed, "new/idea.md")
+
+	os.Remove(existing)
+	waitFor(t, removed, "existing.md")
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch() error = %v", err)
+	}
+}
+
+func waitFor(t *testing.T, ch chan string, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-ch:
+			if got == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+}
+
+func notePaths(notes []*Note) []string {
+	paths := make([]string, len(notes))
+	for i, n := range notes {
+		paths[i] = n.Path
+	}
+	return paths
+}
@@ -0,0 +1,209 @@
+// Package files implements a local Markdown (Obsidian-style) vault space connector.
+package files
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+)
+
+// Note is a parsed Markdown note
+type Note struct {
+	Path        string                 `json:"path"` // Slash-separated, relative to the vault root
+	Title       string                 `json:"title"`
+	FrontMatter map[string]interface{} `json:"front_matter,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Links       []string               `json:"links,omitempty"` // Wiki-link targets
+	Body        string                 `json:"body"`            // Content without front-matter
+	ModTime     time.Time              `json:"modified"`
+}
+
+var (
+	// [[Target]], [[Target|Alias]], [[Target#Heading]] and ![[Embeds]]
+	wikiLinkPattern = regexp.MustCompile(`!?\[\[([^\]\|#\^]+)(?:[#\^][^\]\|]*)?(?:\|[^\]]*)?\]\]`)
+
+	// #tag and #nested/tag, but not headings, anchors or #123
+	inlineTagPattern = regexp.MustCompile(`(?:^|[\s(\[,;])#([\p{L}\p{N}_\-/]*[\p{L}_\-/][\p{L}\p{N}_\-/]*)`)
+
+	codeFencePattern  = regexp.MustCompile("(?s)```.*?```|~~~.*?~~~")
+	inlineCodePattern = regexp.MustCompile("`[^`\n]*`")
+)
+
+// ParseNote parses a Markdown file's front-matter, tags and wiki-links
+func ParseNote(notePath string, data []byte, modTime time.Time) *Note {
+	note := &Note{
+		Path:    notePath,
+		ModTime: modTime,
+	}
+
+	frontMatter, body := splitFrontMatter(data)
+	note.Body = body
+	if len(frontMatter) > 0 {
+		var fm map[string]interface{}
+		if err := yaml.Unmarshal(frontMatter, &fm); err == nil {
+			note.FrontMatter = fm
+		}
+	}
+
+	// Strip code so tags and links inside snippets are ignored
+	text := codeFencePattern.ReplaceAllString(body, "")
+	text = inlineCodePattern.ReplaceAllString(text, "")
+
+	tags := frontMatterList(note.FrontMatter, "tags", "tag")
+	for _, m := range inlineTagPattern.FindAllStringSubmatch(text, -1) {
+		tags = append(tags, m[1])
+	}
+	note.Tags = normalizeTags(tags)
+
+	var links []string
+	for _, m := range wikiLinkPattern.FindAllStringSubmatch(text, -1) {
+		links = append(links, strings.TrimSpace(m[1]))
+	}
+	note.Links = dedupe(links)
+
+	note.Title = noteTitle(note)
+
+	return note
+}
+
+// ToItem converts a note to a QuantumLife Item
+func (n *Note) ToItem(spaceID core.SpaceID) *core.Item {
+	entities := make([]string, 0, len(n.Links)+len(n.Tags))
+	entities = append(entities, n.Links...)
+	for _, tag := range n.Tags {
+		entities = append(entities, "#"+tag)
+	}
+
+	item := &core.Item{
+		Type:       core.ItemTypeNote,
+		Status:     core.ItemStatusPending,
+		SpaceID:    spaceID,
+		ExternalID: n.Path,
+		Subject:    n.Title,
+		Body:       n.Body,
+		Timestamp:  n.ModTime,
+		Entities:   entities,
+		Priority:   3, // Default, will be updated by classifier
+	}
+
+	if author, ok := n.FrontMatter["author"].(string); ok {
+		item.From = author
+	}
+
+	return item
+}
+
+// HatID returns the hat named in the front-matter ("hat: professional"), if any
+func (n *Note) HatID() core.HatID {
+	if hat, ok := n.FrontMatter["hat"].(string); ok {
+		return core.HatID(strings.ToLower(strings.TrimSpace(hat)))
+	}
+	return ""
+}
+
+// HasTag reports whether the note carries the tag (or a nested tag below it)
+func (n *Note) HasTag(tag string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	for _, t := range n.Tags {
+		if t == tag || strings.HasPrefix(t, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFrontMatter separates a leading "---" YAML block from the body
+func splitFrontMatter(data []byte) ([]byte, string) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	normalized := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+
+	if !bytes.HasPrefix(normalized, []byte("---\n")) {
+		return nil, string(normalized)
+	}
+
+	rest := normalized[4:]
+	if bytes.HasPrefix(rest, []byte("---\n")) || bytes.Equal(rest, []byte("---")) {
+		return nil, string(bytes.TrimPrefix(rest[3:], []byte("\n")))
+	}
+
+	end := bytes.Index(rest, []byte("\n---\n"))
+	if end < 0 {
+		if bytes.HasSuffix(rest, []byte("\n---")) {
+			return rest[:len(rest)-4], ""
+		}
+		return nil, string(normalized)
+	}
+	return rest[:end], string(rest[end+5:])
+}
+
+// frontMatterList reads a list-valued key that may also be a comma or space
+// separated string
+func frontMatterList(fm map[string]interface{}, keys ...string) []string {
+	var values []string
+	for _, key := range keys {
+		switch v := fm[key].(type) {
+		case string:
+			values = append(values, strings.FieldsFunc(v, func(r rune) bool {
+				return r == ',' || r == ' '
+			})...)
+		case []interface{}:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					values = append(values, s)
+				} else if item != nil {
+					values = append(values, fmt.Sprint(item))
+				}
+			}
+		}
+	}
+	return values
+}
+
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Trim(strings.TrimSpace(tag), "#/"))
+		if tag != "" {
+			normalized = append(normalized, tag)
+		}
+	}
+	normalized = dedupe(normalized)
+	sort.Strings(normalized)
+	return normalized
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// noteTitle picks the front-matter title, the first H1, or the file name
+func noteTitle(n *Note) string {
+	if title, ok := n.FrontMatter["title"].(string); ok && strings.TrimSpace(title) != "" {
+		return strings.TrimSpace(title)
+	}
+
+	for _, line := range strings.Split(n.Body, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+
+	return strings.TrimSuffix(path.Base(n.Path), path.Ext(n.Path))
+}
@@ -0,0 +1,351 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/spaces"
+)
+
+// debounceInterval batches the burst of events editors emit on save
+const debounceInterval = 500 * time.Millisecond
+
+// Space implements a Markdown vault data source
+type Space struct {
+	id           core.SpaceID
+	name         string
+	defaultHatID core.HatID
+	path         string
+
+	onItems   func(ctx context.Context, items []*core.Item) error
+	onRemoved func(ctx context.Context, externalIDs []string) error
+
+	// Vault
+	vault *Vault
+
+	// State
+	connected  bool
+	syncStatus spaces.SyncStatus
+	syncCursor string // RFC3339Nano modification time of the newest synced note
+
+	mu sync.RWMutex
+}
+
+// Config for creating a files space
+type Config struct {
+	ID           core.SpaceID
+	Name         string
+	DefaultHatID core.HatID
+
+	// Path is the vault directory
+	Path string
+
+	// OnItems receives created or modified notes as items (optional)
+	OnItems func(ctx context.Context, items []*core.Item) error
+
+	// OnRemoved receives the paths (external IDs) of deleted notes (optional)
+	OnRemoved func(ctx context.Context, externalIDs []string) error
+}
+
+// New creates a new files space
+func New(cfg Config) *Space {
+	return &Space{
+		id:           cfg.ID,
+		name:         cfg.Name,
+		defaultHatID: cfg.DefaultHatID,
+		path:         cfg.Path,
+		onItems:      cfg.OnItems,
+		onRemoved:    cfg.OnRemoved,
+		syncStatus: spaces.SyncStatus{
+			Status: "idle",
+		},
+	}
+}
+
+// ID returns the space ID
+func (s *Space) ID() core.SpaceID {
+	return s.id
+}
+
+// Type returns the space type
+func (s *Space) Type() core.SpaceType {
+	return core.SpaceTypeFiles
+}
+
+// Provider returns the provider name
+func (s *Space) Provider() string {
+	return "markdown"
+}
+
+// Name returns the space name
+func (s *Space) Name() string {
+	return s.name
+}
+
+// IsConnected returns connection status
+func (s *Space) IsConnected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connected
+}
+
+// Connect opens the vault directory
+func (s *Space) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vault, err := NewVault(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.vault = vault
+	s.connected = true
+	s.syncStatus.Status = "idle"
+
+	return nil
+}
+
+// Disconnect closes the vault
+func (s *Space) Disconnect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.connected = false
+	s.vault = nil
+	s.syncStatus.Status = "disconnected"
+
+	return nil
+}
+
+// SetSyncCursor sets the sync cursor (modification time of the newest note)
+func (s *Space) SetSyncCursor(cursor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncCursor = cursor
+}
+
+// GetSyncCursor returns the current sync cursor
+func (s *Space) GetSyncCursor() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.syncCursor
+}
+
+// GetVault returns the vault (nil if not connected)
+func (s *Space) GetVault() *Vault {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.vault
+}
+
+// Sync scans the vault for notes modified since the cursor
+func (s *Space) Sync(ctx context.Context) (*spaces.SyncResult, error) {
+	s.mu.Lock()
+	if !s.connected {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("not connected")
+	}
+	s.syncStatus.Status = "syncing"
+	vault := s.vault
+	cursor := s.syncCursor
+	s.mu.Unlock()
+
+	start := time.Now()
+	result := &spaces.SyncResult{}
+
+	var since time.Time
+	if cursor != "" {
+		since, _ = time.Parse(time.RFC3339Nano, cursor)
+	}
+
+	notes, err := vault.List(ctx)
+	if err != nil {
+		s.setSyncError(err)
+		return nil, fmt.Errorf("list notes: %w", err)
+	}
+
+	newest := since
+	var changed []*Note
+	for _, note := range notes {
+		if note.ModTime.After(since) {
+			changed = append(changed, note)
+		}
+		if note.ModTime.After(newest) {
+			newest = note.ModTime
+		}
+	}
+
+	if err := s.deliver(ctx, changed); err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	if since.IsZero() {
+		result.NewItems = len(changed)
+	} else {
+		result.UpdatedItems = len(changed)
+	}
+	result.Duration = time.Since(start)
+	if !newest.IsZero() {
+		result.Cursor = newest.UTC().Format(time.RFC3339Nano)
+	}
+
+	s.mu.Lock()
+	s.syncCursor = result.Cursor
+	s.syncStatus.Status = "idle"
+	s.syncStatus.LastSync = time.Now()
+	s.syncStatus.ItemCount = len(notes)
+	s.mu.Unlock()
+
+	return result, nil
+}
+
+// Watch follows vault changes with fsnotify until ctx is cancelled,
+// delivering changed notes to OnItems and deleted ones to OnRemoved
+func (s *Space) Watch(ctx context.Context) error {
+	vault := s.GetVault()
+	if vault == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := addWatchDirs(watcher, vault.Root()); err != nil {
+		return err
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(debounceInterval)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if isHidden(filepath.Base(event.Name)) {
+				continue
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addWatchDirs(watcher, event.Name)
+					continue
+				}
+			}
+			if isNoteFile(filepath.Base(event.Name)) {
+				pending[event.Name] = true
+				timer.Reset(debounceInterval)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			s.setSyncError(err)
+
+		case <-timer.C:
+			s.flush(ctx, vault, pending)
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+// flush reads the pending paths and reports changes and deletions
+func (s *Space) flush(ctx context.Context, vault *Vault, pending map[string]bool) {
+	var changed []*Note
+	var removed []string
+
+	for abs := range pending {
+		note, err := vault.readNote(abs)
+		if err != nil {
+			removed = append(removed, vault.relPath(abs))
+			continue
+		}
+		changed = append(changed, note)
+	}
+
+	if err := s.deliver(ctx, changed); err != nil {
+		s.setSyncError(err)
+		return
+	}
+	if len(removed) > 0 && s.onRemoved != nil {
+		if err := s.onRemoved(ctx, removed); err != nil {
+			s.setSyncError(err)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	for _, note := range changed {
+		if cursor, _ := time.Parse(time.RFC3339Nano, s.syncCursor); note.ModTime.After(cursor) {
+			s.syncCursor = note.ModTime.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	s.syncStatus.Status = "idle"
+	s.syncStatus.LastSync = time.Now()
+	s.mu.Unlock()
+}
+
+// deliver converts notes to items and hands them to OnItems
+func (s *Space) deliver(ctx context.Context, notes []*Note) error {
+	if s.onItems == nil || len(notes) == 0 {
+		return nil
+	}
+
+	items := make([]*core.Item, 0, len(notes))
+	for _, note := range notes {
+		item := note.ToItem(s.id)
+		item.HatID = s.defaultHatID
+		if hat := note.HatID(); hat != "" {
+			item.HatID = hat
+		}
+		items = append(items, item)
+	}
+	return s.onItems(ctx, items)
+}
+
+// GetSyncStatus returns the current sync status
+func (s *Space) GetSyncStatus() spaces.SyncStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.syncStatus
+}
+
+func (s *Space) setSyncError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncStatus.Status = "error"
+	s.syncStatus.LastError = err.Error()
+}
+
+// addWatchDirs watches dir and all its non-hidden subdirectories
+func addWatchDirs(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p != dir && isHidden(d.Name()) {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(p); err != nil {
+			return fmt.Errorf("watch %s: %w", p, err)
+		}
+		return nil
+	})
+}
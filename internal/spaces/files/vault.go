@@ -0,0 +1,457 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Vault errors
+var (
+	ErrNoteNotFound = errors.New("note not found")
+	ErrNoteExists   = errors.New("note already exists")
+	ErrInvalidPath  = errors.New("path is outside the vault")
+)
+
+// Vault reads and writes Markdown notes under a root directory
+type Vault struct {
+	root string
+	mu   sync.Mutex // Serializes writes
+}
+
+// NewVault opens a vault rooted at dir
+func NewVault(dir string) (*Vault, error) {
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[2:])
+		}
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve vault path: %w", err)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("open vault: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("open vault: %s is not a directory", root)
+	}
+
+	return &Vault{root: root}, nil
+}
+
+// Root returns the absolute vault directory
+func (v *Vault) Root() string {
+	return v.root
+}
+
+// SearchQuery filters notes for Search
+type SearchQuery struct {
+	Text  string // Matched against title, path and body (case-insensitive)
+	Tag   string // Only notes with this tag (nested tags included)
+	Limit int
+}
+
+// CreateNoteRequest contains parameters for creating a note
+type CreateNoteRequest struct {
+	Title       string
+	Folder      string // Relative to the vault root
+	Body        string
+	Tags        []string
+	FrontMatter map[string]interface{}
+}
+
+// List returns every note in the vault, newest first
+func (v *Vault) List(ctx context.Context) ([]*Note, error) {
+	var notes []*Note
+
+	err := filepath.WalkDir(v.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if p != v.root && isHidden(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isNoteFile(d.Name()) {
+			return nil
+		}
+
+		note, err := v.readNote(p)
+		if err != nil {
+			return nil
+		}
+		notes = append(notes, note)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortNewestFirst(notes)
+	return notes, nil
+}
+
+// Get returns a note by relative path. A bare name without folder or
+// extension is resolved like a wiki-link.
+func (v *Vault) Get(ctx context.Context, notePath string) (*Note, error) {
+	abs, err := v.resolve(notePath)
+	if err != nil {
+		return nil, err
+	}
+
+	note, err := v.readNote(abs)
+	if err == nil {
+		return note, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	// Fall back to wiki-link resolution by file name
+	if !strings.ContainsAny(notePath, "/\\") {
+		name := strings.ToLower(strings.TrimSuffix(notePath, ".md"))
+		notes, err := v.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			base := strings.ToLower(strings.TrimSuffix(path.Base(n.Path), path.Ext(n.Path)))
+			if base == name || strings.EqualFold(n.Title, notePath) {
+				return n, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", notePath, ErrNoteNotFound)
+}
+
+// Search returns notes matching the query, best matches first
+func (v *Vault) Search(ctx context.Context, query SearchQuery) ([]*Note, error) {
+	notes, err := v.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ToLower(strings.TrimSpace(query.Text))
+	type scored struct {
+		note  *Note
+		score int
+	}
+	var matches []scored
+
+	for _, note := range notes {
+		if query.Tag != "" && !note.HasTag(query.Tag) {
+			continue
+		}
+
+		score := 1
+		if text != "" {
+			score = 0
+			if strings.Contains(strings.ToLower(note.Title), text) {
+				score += 10
+			}
+			if strings.Contains(strings.ToLower(note.Path), text) {
+				score += 3
+			}
+			score += min(strings.Count(strings.ToLower(note.Body), text), 5)
+			if score == 0 {
+				continue
+			}
+		}
+		matches = append(matches, scored{note: note, score: score})
+	}
+
+	// Stable sort keeps newest-first order among equal scores
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	limit := query.Limit
+	if limit <= 0 || limit > len(matches) {
+		limit = len(matches)
+	}
+
+	results := make([]*Note, 0, limit)
+	for _, m := range matches[:limit] {
+		results = append(results, m.note)
+	}
+	return results, nil
+}
+
+// Create writes a new note. Tags and extra front-matter are written as YAML.
+func (v *Vault) Create(ctx context.Context, req CreateNoteRequest) (*Note, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+
+	notePath := path.Join(filepath.ToSlash(req.Folder), sanitizeFileName(title)+".md")
+	abs, err := v.resolve(notePath)
+	if err != nil {
+		return nil, err
+	}
+
+	fm := make(map[string]interface{}, len(req.FrontMatter)+2)
+	for k, val := range req.FrontMatter {
+		fm[k] = val
+	}
+	if tags := normalizeTags(req.Tags); len(tags) > 0 {
+		fm["tags"] = tags
+	}
+	if _, ok := fm["created"]; !ok {
+		fm["created"] = time.Now().Format(time.RFC3339)
+	}
+
+	var content strings.Builder
+	header, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("encode front-matter: %w", err)
+	}
+	content.WriteString("---\n")
+	content.Write(header)
+	content.WriteString("---\n")
+	content.WriteString("# " + title + "\n")
+	if body := strings.TrimSpace(req.Body); body != "" {
+		content.WriteString("\n" + body + "\n")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		return nil, fmt.Errorf("create folder: %w", err)
+	}
+
+	f, err := os.OpenFile(abs, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("%s: %w", notePath, ErrNoteExists)
+		}
+		return nil, fmt.Errorf("create note: %w", err)
+	}
+	if _, err := f.WriteString(content.String()); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write note: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("write note: %w", err)
+	}
+
+	return v.readNote(abs)
+}
+
+// Append adds text to the end of a note, or to the end of the section under
+// heading when one is given (the heading is created if missing).
+func (v *Vault) Append(ctx context.Context, notePath, text, heading string) (*Note, error) {
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+
+	existing, err := v.Get(ctx, notePath)
+	if err != nil {
+		return nil, err
+	}
+	abs, err := v.resolve(existing.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("stat note: %w", err)
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("read note: %w", err)
+	}
+
+	updated := appendText(string(data), text, heading)
+	if err := writeFileAtomic(abs, []byte(updated), info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("write note: %w", err)
+	}
+
+	return v.readNote(abs)
+}
+
+// appendText inserts text at the end of the document or of a heading's section
+func appendText(doc, text, heading string) string {
+	doc = strings.ReplaceAll(doc, "\r\n", "\n")
+	heading = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(heading), "#"))
+
+	if heading == "" {
+		return strings.TrimRight(doc, "\n") + "\n\n" + text + "\n"
+	}
+
+	lines := strings.Split(strings.TrimRight(doc, "\n"), "\n")
+	start, level := -1, 0
+	for i, line := range lines {
+		if l, title := parseHeading(line); l > 0 && strings.EqualFold(title, heading) {
+			start, level = i, l
+			break
+		}
+	}
+
+	if start < 0 {
+		return strings.TrimRight(doc, "\n") + "\n\n## " + heading + "\n\n" + text + "\n"
+	}
+
+	// The section ends at the next heading of the same or higher level
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if l, _ := parseHeading(lines[i]); l > 0 && l <= level {
+			end = i
+			break
+		}
+	}
+
+	// Insert after the section's last non-blank line
+	insert := end
+	for insert > start+1 && strings.TrimSpace(lines[insert-1]) == "" {
+		insert--
+	}
+
+	result := make([]string, 0, len(lines)+3)
+	result = append(result, lines[:insert]...)
+	result = append(result, "", text)
+	if insert < len(lines) {
+		result = append(result, "")
+		result = append(result, lines[end:]...)
+	}
+	return strings.Join(result, "\n") + "\n"
+}
+
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || level >= len(line) || line[level] != ' ' {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
+
+// resolve maps a relative note path to an absolute path inside the vault
+func (v *Vault) resolve(notePath string) (string, error) {
+	notePath = strings.TrimSpace(notePath)
+	if notePath == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	if !strings.HasSuffix(strings.ToLower(notePath), ".md") {
+		notePath += ".md"
+	}
+
+	clean := filepath.Clean(filepath.FromSlash(notePath))
+	if filepath.IsAbs(clean) {
+		rel, err := filepath.Rel(v.root, clean)
+		if err != nil {
+			return "", ErrInvalidPath
+		}
+		clean = rel
+	}
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidPath
+	}
+
+	return filepath.Join(v.root, clean), nil
+}
+
+// relPath returns the slash-separated path of abs relative to the vault root
+func (v *Vault) relPath(abs string) string {
+	rel, err := filepath.Rel(v.root, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (v *Vault) readNote(abs string) (*Note, error) {
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", abs)
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseNote(v.relPath(abs), data, info.ModTime()), nil
+}
+
+func writeFileAtomic(dst string, data []byte, perm fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".ql-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// sanitizeFileName removes characters that are invalid in file names or
+// that Obsidian does not allow in note names
+func sanitizeFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|#^[]`, r) || r < ' ' {
+			return '-'
+		}
+		return r
+	}, title)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+	if name == "" {
+		name = "Untitled"
+	}
+	return name
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+func isNoteFile(name string) bool {
+	return !isHidden(name) && strings.EqualFold(filepath.Ext(name), ".md")
+}
+
+func sortNewestFirst(notes []*Note) {
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].ModTime.After(notes[j].ModTime)
+	})
+}
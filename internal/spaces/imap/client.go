@@ -0,0 +1,725 @@
+// Package imap implements a generic IMAP email space connector.
+package imap
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goimap "github.com/emersion/go-imap"
+	imapclient "github.com/emersion/go-imap/client"
+	_ "github.com/emersion/go-message/charset" // register non-UTF-8 charsets
+	"github.com/emersion/go-message/mail"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+)
+
+// Standard IMAP system flags used by the connector
+const (
+	FlagSeen     = goimap.SeenFlag
+	FlagFlagged  = goimap.FlaggedFlag
+	FlagDeleted  = goimap.DeletedFlag
+	FlagAnswered = goimap.AnsweredFlag
+)
+
+// ErrNotFound is returned when a message cannot be located on the server
+var ErrNotFound = errors.New("message not found")
+
+// ServerConfig holds the IMAP connection settings
+type ServerConfig struct {
+	Host     string
+	Port     int // Default: 993 with TLS, 143 without
+	Username string
+	Password string
+	UseTLS   bool // Implicit TLS (IMAPS)
+	StartTLS bool // Upgrade a plaintext connection with STARTTLS
+	Timeout  time.Duration
+
+	// InsecureSkipVerify disables certificate checks (self-signed servers)
+	InsecureSkipVerify bool
+}
+
+// Addr returns host:port with the default port applied
+func (c ServerConfig) Addr() string {
+	port := c.Port
+	if port == 0 {
+		port = 143
+		if c.UseTLS {
+			port = 993
+		}
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
+
+// Client wraps an authenticated IMAP connection. IMAP connections are
+// stateful (one selected mailbox at a time), so all commands are serialized.
+type Client struct {
+	conn    *imapclient.Client
+	cfg     ServerConfig
+	updates chan imapclient.Update
+	changed chan struct{} // Signalled when the server reports EXISTS or RECENT
+	mu      sync.Mutex
+}
+
+// Dial connects and logs in to the IMAP server
+func Dial(ctx context.Context, cfg ServerConfig) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("imap host not configured")
+	}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	tlsConfig := &tls.Config{ServerName: cfg.Host, InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	var conn *imapclient.Client
+	var err error
+	if cfg.UseTLS {
+		conn, err = imapclient.DialWithDialerTLS(dialer, cfg.Addr(), tlsConfig)
+	} else {
+		conn, err = imapclient.DialWithDialer(dialer, cfg.Addr())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", cfg.Addr(), err)
+	}
+	conn.Timeout = timeout
+
+	c := &Client{conn: conn, cfg: cfg, changed: make(chan struct{}, 1)}
+	c.watchUpdates()
+
+	if cfg.StartTLS && !cfg.UseTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Logout()
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if err := conn.Login(cfg.Username, cfg.Password); err != nil {
+		conn.Logout()
+		return nil, fmt.Errorf("login: %w", err)
+	}
+
+	if ctx.Err() != nil {
+		conn.Logout()
+		return nil, ctx.Err()
+	}
+
+	return c, nil
+}
+
+// watchUpdates drains unilateral server responses so the reader never
+// blocks, and tells Idle when the selected mailbox changed. The update
+// carries the library's live MailboxStatus, so its counts aren't read here.
+func (c *Client) watchUpdates() {
+	c.updates = make(chan imapclient.Update, 32)
+	c.conn.Updates = c.updates
+
+	go func() {
+		for {
+			select {
+			case update := <-c.updates:
+				switch u := update.(type) {
+				case *imapclient.MailboxUpdate:
+					select {
+					case c.changed <- struct{}{}:
+					default:
+					}
+				case *updateBarrier:
+					select {
+					case <-c.changed:
+					default:
+					}
+					close(u.done)
+				}
+			case <-c.conn.LoggedOut():
+				return
+			}
+		}
+	}()
+}
+
+// updateBarrier is queued behind pending updates; once watchUpdates reaches
+// it, every update received before it has been handled
+type updateBarrier struct {
+	imapclient.Update
+	done chan struct{}
+}
+
+// discardUpdates drops change signals for updates already received, such as
+// the EXISTS and RECENT responses to SELECT
+func (c *Client) discardUpdates(ctx context.Context) error {
+	barrier := &updateBarrier{done: make(chan struct{})}
+	select {
+	case c.updates <- barrier:
+	case <-c.conn.LoggedOut():
+		return fmt.Errorf("connection closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-barrier.done:
+		return nil
+	case <-c.conn.LoggedOut():
+		return fmt.Errorf("connection closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close logs out and closes the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.Logout()
+}
+
+// MessageRef identifies a message on the server. UIDs are only meaningful
+// together with the mailbox and its UIDVALIDITY.
+type MessageRef struct {
+	Mailbox     string
+	UIDValidity uint32
+	UID         uint32
+}
+
+// String encodes the reference as "mailbox:uidvalidity:uid". It is used as
+// the item ExternalID so action handlers can find the message again.
+func (r MessageRef) String() string {
+	return fmt.Sprintf("%s:%d:%d", r.Mailbox, r.UIDValidity, r.UID)
+}
+
+// ParseMessageRef parses a reference produced by MessageRef.String
+func ParseMessageRef(s string) (MessageRef, error) {
+	// Mailbox names may contain ':', so split from the right
+	last := strings.LastIndex(s, ":")
+	if last <= 0 {
+		return MessageRef{}, fmt.Errorf("invalid message reference: %q", s)
+	}
+	mid := strings.LastIndex(s[:last], ":")
+	if mid <= 0 {
+		return MessageRef{}, fmt.Errorf("invalid message reference: %q", s)
+	}
+
+	validity, err := strconv.ParseUint(s[mid+1:last], 10, 32)
+	if err != nil {
+		return MessageRef{}, fmt.Errorf("invalid uidvalidity in %q", s)
+	}
+	uid, err := strconv.ParseUint(s[last+1:], 10, 32)
+	if err != nil || uid == 0 {
+		return MessageRef{}, fmt.Errorf("invalid uid in %q", s)
+	}
+
+	return MessageRef{Mailbox: s[:mid], UIDValidity: uint32(validity), UID: uint32(uid)}, nil
+}
+
+// Message contains full message details
+type Message struct {
+	Ref       MessageRef
+	MessageID string // RFC 5322 Message-ID header
+	From      string
+	To        string
+	Subject   string
+	Body      string
+	Date      time.Time
+	Flags     []string
+	IsUnread  bool
+}
+
+// ToItem converts an IMAP message to a QuantumLife Item
+func (m *Message) ToItem(spaceID core.SpaceID) *core.Item {
+	return &core.Item{
+		Type:       core.ItemTypeEmail,
+		Status:     core.ItemStatusPending,
+		SpaceID:    spaceID,
+		ExternalID: m.Ref.String(),
+		From:       m.From,
+		To:         []string{m.To},
+		Subject:    m.Subject,
+		Body:       m.Body,
+		Timestamp:  m.Date,
+		Priority:   3, // Default, will be updated by classifier
+	}
+}
+
+// MailboxStatus describes a selected mailbox
+type MailboxStatus struct {
+	Name        string
+	UIDValidity uint32
+	UIDNext     uint32
+	Messages    uint32
+
+	// Keywords reports whether arbitrary keywords can be stored (PERMANENTFLAGS \*)
+	Keywords bool
+}
+
+// Examine opens a mailbox read-only and returns its status
+func (c *Client) Examine(ctx context.Context, mailbox string) (*MailboxStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.selectMailbox(mailbox, true)
+}
+
+func (c *Client) selectMailbox(mailbox string, readOnly bool) (*MailboxStatus, error) {
+	mbox, err := c.conn.Select(mailbox, readOnly)
+	if err != nil {
+		return nil, fmt.Errorf("select %s: %w", mailbox, err)
+	}
+
+	status := &MailboxStatus{
+		Name:        mbox.Name,
+		UIDValidity: mbox.UidValidity,
+		UIDNext:     mbox.UidNext,
+		Messages:    mbox.Messages,
+	}
+	for _, flag := range mbox.PermanentFlags {
+		if flag == goimap.TryCreateFlag {
+			status.Keywords = true
+		}
+	}
+	return status, nil
+}
+
+// selectRef selects the message's mailbox for writing and checks that its
+// UIDs are still valid
+func (c *Client) selectRef(ref MessageRef) (*MailboxStatus, error) {
+	status, err := c.selectMailbox(ref.Mailbox, false)
+	if err != nil {
+		return nil, err
+	}
+	if ref.UIDValidity != 0 && status.UIDValidity != ref.UIDValidity {
+		return nil, fmt.Errorf("mailbox %s was reset (uidvalidity %d != %d): %w",
+			ref.Mailbox, status.UIDValidity, ref.UIDValidity, ErrNotFound)
+	}
+	return status, nil
+}
+
+// SearchUIDs returns the UIDs in the mailbox matching the criteria
+func (c *Client) SearchUIDs(ctx context.Context, mailbox string, criteria *goimap.SearchCriteria) ([]uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.selectMailbox(mailbox, true); err != nil {
+		return nil, err
+	}
+	uids, err := c.conn.UidSearch(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("search %s: %w", mailbox, err)
+	}
+	return uids, nil
+}
+
+// FetchMessages fetches full messages by UID from a mailbox. The bodies are
+// fetched with BODY.PEEK so the \Seen flag is left untouched.
+func (c *Client) FetchMessages(ctx context.Context, mailbox string, uids []uint32) ([]*Message, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, err := c.selectMailbox(mailbox, true)
+	if err != nil {
+		return nil, err
+	}
+
+	seqset := new(goimap.SeqSet)
+	seqset.AddNum(uids...)
+
+	section := &goimap.BodySectionName{Peek: true}
+	items := []goimap.FetchItem{goimap.FetchUid, goimap.FetchFlags, goimap.FetchInternalDate, goimap.FetchEnvelope, section.FetchItem()}
+
+	ch := make(chan *goimap.Message, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.conn.UidFetch(seqset, items, ch)
+	}()
+
+	var messages []*Message
+	for raw := range ch {
+		msg := parseMessage(raw, section)
+		msg.Ref = MessageRef{Mailbox: mailbox, UIDValidity: status.UIDValidity, UID: raw.Uid}
+		messages = append(messages, msg)
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("fetch messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// GetMessage fetches a single message by reference
+func (c *Client) GetMessage(ctx context.Context, ref MessageRef) (*Message, error) {
+	messages, err := c.FetchMessages(ctx, ref.Mailbox, []uint32{ref.UID})
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrNotFound
+	}
+	if ref.UIDValidity != 0 && messages[0].Ref.UIDValidity != ref.UIDValidity {
+		return nil, ErrNotFound
+	}
+	return messages[0], nil
+}
+
+// AddFlags adds flags or keywords to a message
+func (c *Client) AddFlags(ctx context.Context, ref MessageRef, flags ...string) error {
+	return c.storeFlags(ref, goimap.AddFlags, flags)
+}
+
+// RemoveFlags removes flags or keywords from a message
+func (c *Client) RemoveFlags(ctx context.Context, ref MessageRef, flags ...string) error {
+	return c.storeFlags(ref, goimap.RemoveFlags, flags)
+}
+
+func (c *Client) storeFlags(ref MessageRef, op goimap.FlagsOp, flags []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.selectRef(ref); err != nil {
+		return err
+	}
+
+	seqset := new(goimap.SeqSet)
+	seqset.AddNum(ref.UID)
+
+	values := make([]interface{}, len(flags))
+	for i, f := range flags {
+		values[i] = f
+	}
+
+	if err := c.conn.UidStore(seqset, goimap.FormatFlagsOp(op, true), values, nil); err != nil {
+		return fmt.Errorf("store flags: %w", err)
+	}
+	return nil
+}
+
+// SupportsKeywords reports whether the mailbox accepts arbitrary keywords
+func (c *Client) SupportsKeywords(ctx context.Context, mailbox string) (bool, error) {
+	status, err := c.Examine(ctx, mailbox)
+	if err != nil {
+		return false, err
+	}
+	return status.Keywords, nil
+}
+
+// EnsureMailbox creates the mailbox if it does not exist
+func (c *Client) EnsureMailbox(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ensureMailbox(name)
+}
+
+func (c *Client) ensureMailbox(name string) error {
+	ch := make(chan *goimap.MailboxInfo, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.conn.List("", name, ch)
+	}()
+
+	exists := false
+	for range ch {
+		exists = true
+	}
+	if err := <-done; err != nil {
+		return fmt.Errorf("list mailboxes: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	if err := c.conn.Create(name); err != nil {
+		return fmt.Errorf("create mailbox %s: %w", name, err)
+	}
+	return nil
+}
+
+// Move moves a message to another mailbox (creating it if needed) and
+// returns the message's reference in the destination mailbox.
+func (c *Client) Move(ctx context.Context, ref MessageRef, dest string) (MessageRef, error) {
+	return c.transfer(ref, dest, true)
+}
+
+// Copy copies a message to another mailbox (creating it if needed) and
+// returns the reference of the copy.
+func (c *Client) Copy(ctx context.Context, ref MessageRef, dest string) (MessageRef, error) {
+	return c.transfer(ref, dest, false)
+}
+
+func (c *Client) transfer(ref MessageRef, dest string, move bool) (MessageRef, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.ensureMailbox(dest); err != nil {
+		return MessageRef{}, err
+	}
+	if _, err := c.selectRef(ref); err != nil {
+		return MessageRef{}, err
+	}
+
+	// The Message-ID lets us find the message again in the destination,
+	// since UIDPLUS (COPYUID) is not available on every server.
+	messageID, err := c.messageID(ref.UID)
+	if err != nil {
+		return MessageRef{}, err
+	}
+
+	seqset := new(goimap.SeqSet)
+	seqset.AddNum(ref.UID)
+
+	if move {
+		if err := c.conn.UidMove(seqset, dest); err != nil {
+			// Servers without a working MOVE: copy, delete and expunge
+			if err := c.conn.UidCopy(seqset, dest); err != nil {
+				return MessageRef{}, fmt.Errorf("move message: %w", err)
+			}
+			item := goimap.FormatFlagsOp(goimap.AddFlags, true)
+			if err := c.conn.UidStore(seqset, item, []interface{}{FlagDeleted}, nil); err != nil {
+				return MessageRef{}, fmt.Errorf("move message: %w", err)
+			}
+			if err := c.conn.Expunge(nil); err != nil {
+				return MessageRef{}, fmt.Errorf("move message: %w", err)
+			}
+		}
+	} else if err := c.conn.UidCopy(seqset, dest); err != nil {
+		return MessageRef{}, fmt.Errorf("copy message: %w", err)
+	}
+
+	return c.locate(dest, messageID)
+}
+
+// Delete marks a message as deleted and expunges the mailbox
+func (c *Client) Delete(ctx context.Context, ref MessageRef) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.selectRef(ref); err != nil {
+		return err
+	}
+
+	seqset := new(goimap.SeqSet)
+	seqset.AddNum(ref.UID)
+	item := goimap.FormatFlagsOp(goimap.AddFlags, true)
+	if err := c.conn.UidStore(seqset, item, []interface{}{FlagDeleted}, nil); err != nil {
+		return fmt.Errorf("delete message: %w", err)
+	}
+	if err := c.conn.Expunge(nil); err != nil {
+		return fmt.Errorf("expunge: %w", err)
+	}
+	return nil
+}
+
+// messageID returns the Message-ID header of a message in the selected mailbox
+func (c *Client) messageID(uid uint32) (string, error) {
+	seqset := new(goimap.SeqSet)
+	seqset.AddNum(uid)
+
+	ch := make(chan *goimap.Message, 1)
+	if err := c.conn.UidFetch(seqset, []goimap.FetchItem{goimap.FetchEnvelope}, ch); err != nil {
+		return "", fmt.Errorf("fetch envelope: %w", err)
+	}
+	msg := <-ch
+	if msg == nil || msg.Envelope == nil {
+		return "", ErrNotFound
+	}
+	return msg.Envelope.MessageId, nil
+}
+
+// locate finds the newest message with the given Message-ID in a mailbox
+func (c *Client) locate(mailbox, messageID string) (MessageRef, error) {
+	status, err := c.selectMailbox(mailbox, true)
+	if err != nil {
+		return MessageRef{}, err
+	}
+	if messageID == "" {
+		return MessageRef{Mailbox: mailbox, UIDValidity: status.UIDValidity}, nil
+	}
+
+	criteria := goimap.NewSearchCriteria()
+	criteria.Header.Add("Message-Id", messageID)
+	uids, err := c.conn.UidSearch(criteria)
+	if err != nil {
+		return MessageRef{}, fmt.Errorf("search %s: %w", mailbox, err)
+	}
+
+	ref := MessageRef{Mailbox: mailbox, UIDValidity: status.UIDValidity}
+	for _, uid := range uids {
+		if uid > ref.UID {
+			ref.UID = uid
+		}
+	}
+	return ref, nil
+}
+
+// Idle selects the mailbox and waits until the server reports new messages,
+// the timeout elapses or ctx is cancelled. It returns true if new messages
+// arrived. Servers without IDLE are polled with NOOP.
+func (c *Client) Idle(ctx context.Context, mailbox string, timeout time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.selectMailbox(mailbox, true); err != nil {
+		return false, err
+	}
+
+	// SELECT reports EXISTS as unilateral updates, so only later ones mean
+	// new mail
+	if err := c.discardUpdates(ctx); err != nil {
+		return false, err
+	}
+
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- c.conn.Idle(stop, &imapclient.IdleOptions{PollInterval: time.Minute})
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	// Any later EXISTS or RECENT is treated as new mail; Sync only fetches
+	// UIDs above the cursor, so a false alarm costs one search
+	changed := false
+wait:
+	for {
+		select {
+		case <-c.changed:
+			changed = true
+			break wait
+		case <-timer.C:
+			break wait
+		case <-ctx.Done():
+			break wait
+		case err := <-done:
+			if err != nil {
+				return false, fmt.Errorf("idle: %w", err)
+			}
+			return false, nil
+		}
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		return changed, fmt.Errorf("idle: %w", err)
+	}
+	return changed, ctx.Err()
+}
+
+// parseMessage converts a fetched IMAP message to our Message struct
+func parseMessage(raw *goimap.Message, section *goimap.BodySectionName) *Message {
+	result := &Message{
+		Flags:    raw.Flags,
+		Date:     raw.InternalDate,
+		IsUnread: true,
+	}
+
+	for _, flag := range raw.Flags {
+		if flag == FlagSeen {
+			result.IsUnread = false
+		}
+	}
+
+	if env := raw.Envelope; env != nil {
+		result.MessageID = env.MessageId
+		result.Subject = env.Subject
+		result.From = formatAddresses(env.From)
+		result.To = formatAddresses(env.To)
+		if !env.Date.IsZero() {
+			result.Date = env.Date
+		}
+	}
+
+	if body := raw.GetBody(section); body != nil {
+		result.Body = extractBody(body)
+	}
+
+	return result
+}
+
+// extractBody returns the plain text body of a message, falling back to
+// stripped HTML when there is no text/plain part
+func extractBody(r io.Reader) string {
+	mr, err := mail.CreateReader(r)
+	if err != nil {
+		return ""
+	}
+
+	var htmlBody string
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		header, ok := part.Header.(*mail.InlineHeader)
+		if !ok {
+			continue // Attachment
+		}
+
+		contentType, _, _ := header.ContentType()
+		data, err := io.ReadAll(part.Body)
+		if err != nil {
+			continue
+		}
+
+		switch contentType {
+		case "text/plain", "":
+			return strings.TrimSpace(string(data))
+		case "text/html":
+			if htmlBody == "" {
+				htmlBody = stripHTML(string(data))
+			}
+		}
+	}
+
+	return htmlBody
+}
+
+func formatAddresses(addrs []*goimap.Address) string {
+	parts := make([]string, 0, len(addrs))
+	for _, a := range addrs {
+		addr := a.Address()
+		if a.PersonalName != "" {
+			addr = fmt.Sprintf("%s <%s>", a.PersonalName, addr)
+		}
+		parts = append(parts, addr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// stripHTML removes HTML tags
+func stripHTML(s string) string {
+	var result strings.Builder
+	inTag := false
+
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case !inTag:
+			result.WriteRune(r)
+		}
+	}
+
+	// Clean up whitespace
+	lines := strings.Split(result.String(), "\n")
+	var cleaned []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+
+	return strings.Join(cleaned, "\n")
+}
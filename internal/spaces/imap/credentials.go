@@ -0,0 +1,80 @@
+package imap
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/email"
+)
+
+// Credentials are the account settings stored (encrypted) in the credential
+// store for an IMAP space
+type Credentials struct {
+	Host           string `json:"host"`
+	Port           int    `json:"port,omitempty"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	UseTLS         bool   `json:"use_tls"`
+	StartTLS       bool   `json:"starttls,omitempty"`
+	Mailbox        string `json:"mailbox,omitempty"`
+	ArchiveMailbox string `json:"archive_mailbox,omitempty"`
+
+	// Outbound mail; the IMAP username and password are reused
+	SMTPHost string `json:"smtp_host,omitempty"`
+	SMTPPort int    `json:"smtp_port,omitempty"`
+	FromName string `json:"from_name,omitempty"`
+}
+
+// ServerConfig returns the IMAP connection settings
+func (c *Credentials) ServerConfig() ServerConfig {
+	return ServerConfig{
+		Host:     c.Host,
+		Port:     c.Port,
+		Username: c.Username,
+		Password: c.Password,
+		UseTLS:   c.UseTLS,
+		StartTLS: c.StartTLS,
+	}
+}
+
+// Sender returns an SMTP sender for the account, or nil if no SMTP host is set
+func (c *Credentials) Sender() *email.Sender {
+	if c.SMTPHost == "" {
+		return nil
+	}
+
+	port := c.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+
+	return email.NewSender(email.Config{
+		SMTPHost:    c.SMTPHost,
+		SMTPPort:    port,
+		Username:    c.Username,
+		Password:    c.Password,
+		FromEmail:   c.Username,
+		FromName:    c.FromName,
+		UseTLS:      port == 465,
+		UseStartTLS: port != 465,
+		Timeout:     30 * time.Second,
+	})
+}
+
+// CredentialsToJSON serializes credentials to JSON
+func CredentialsToJSON(creds *Credentials) ([]byte, error) {
+	return json.Marshal(creds)
+}
+
+// CredentialsFromJSON deserializes credentials from JSON
+func CredentialsFromJSON(data []byte) (*Credentials, error) {
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	if creds.Host == "" || creds.Username == "" {
+		return nil, fmt.Errorf("incomplete imap credentials")
+	}
+	return &creds, nil
+}
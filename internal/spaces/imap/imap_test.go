@@ -0,0 +1,452 @@
+package imap
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/email"
+	"github.com/quantumlife/quantumlife/internal/testutil/mockservers"
+)
+
+func newTestSpace(t *testing.T, mock *mockservers.IMAPMockServer, onItems func(ctx context.Context, items []*core.Item) error) *Space {
+	t.Helper()
+
+	space := New(Config{
+		ID:           "imap-test",
+		Name:         "Fastmail",
+		DefaultHatID: core.HatPersonal,
+		Server: ServerConfig{
+			Host:     mock.Host,
+			Port:     mock.Port,
+			Username: mock.Username,
+			Password: mock.Password,
+		},
+		OnItems: onItems,
+	})
+	if err := space.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	t.Cleanup(func() { space.Disconnect(context.Background()) })
+	return space
+}
+
+func TestMessageRef_RoundTrip(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    MessageRef
+		wantErr bool
+	}{
+		{in: "INBOX:1:42", want: MessageRef{Mailbox: "INBOX", UIDValidity: 1, UID: 42}},
+		{in: "Work:Clients:7:3", want: MessageRef{Mailbox: "Work:Clients", UIDValidity: 7, UID: 3}},
+		{in: "INBOX:42", wantErr: true},
+		{in: "INBOX:x:1", wantErr: true},
+		{in: "INBOX:1:0", wantErr: true},
+		{in: "18c2f1a9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMessageRef(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseMessageRef(%q) expected error, got %+v", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMessageRef(%q) error = %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMessageRef(%q) = %+v, want %+v", tt.in, got, tt.want)
+			}
+			if got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestMessage_ToItem(t *testing.T) {
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	msg := &Message{
+		Ref:     MessageRef{Mailbox: "INBOX", UIDValidity: 1, UID: 9},
+		From:    "alice@example.com",
+		To:      "user@example.com",
+		Subject: "Hello",
+		Body:    "Hi there",
+		Date:    date,
+	}
+
+	item := msg.ToItem("space-1")
+	if item.Type != core.ItemTypeEmail || item.Status != core.ItemStatusPending {
+		t.Errorf("unexpected type/status: %s/%s", item.Type, item.Status)
+	}
+	if item.ExternalID != "INBOX:1:9" {
+		t.Errorf("ExternalID = %q, want INBOX:1:9", item.ExternalID)
+	}
+	if item.SpaceID != "space-1" || item.Subject != "Hello" || item.Body != "Hi there" || !item.Timestamp.Equal(date) {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if len(item.To) != 1 || item.To[0] != "user@example.com" {
+		t.Errorf("To = %v", item.To)
+	}
+}
+
+func TestSpace_Identity(t *testing.T) {
+	space := New(Config{ID: "imap-1", Name: "Mail"})
+
+	if space.Type() != core.SpaceTypeEmail {
+		t.Errorf("Type() = %s", space.Type())
+	}
+	if space.Provider() != "imap" {
+		t.Errorf("Provider() = %s", space.Provider())
+	}
+	if space.ArchiveMailbox() != "Archive" {
+		t.Errorf("ArchiveMailbox() = %s, want default Archive", space.ArchiveMailbox())
+	}
+	if space.IsConnected() {
+		t.Error("new space should not be connected")
+	}
+	if _, err := space.Sync(context.Background()); err == nil {
+		t.Error("Sync() on disconnected space should fail")
+	}
+}
+
+func TestSpace_Connect_BadPassword(t *testing.T) {
+	mock := mockservers.NewIMAPMockServer(t)
+
+	space := New(Config{
+		ID:     "imap-test",
+		Server: ServerConfig{Host: mock.Host, Port: mock.Port, Username: mock.Username, Password: "wrong"},
+	})
+	if err := space.Connect(context.Background()); err == nil {
+		t.Fatal("expected login failure")
+	}
+	if space.IsConnected() {
+		t.Error("space should not be connected after failed login")
+	}
+}
+
+func TestSpace_Sync_InitialAndIncremental(t *testing.T) {
+	mock := mockservers.NewIMAPMockServer(t)
+
+	var received []*core.Item
+	space := newTestSpace(t, mock, func(ctx context.Context, items []*core.Item) error {
+		received = append(received, items...)
+		return nil
+	})
+	ctx := context.Background()
+
+	// Initial sync picks up both recent messages
+	result, err := space.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.NewItems != 2 || len(received) != 2 {
+		t.Fatalf("NewItems = %d, received %d, want 2", result.NewItems, len(received))
+	}
+	if result.Cursor != "1:2" {
+		t.Errorf("Cursor = %q, want 1:2", result.Cursor)
+	}
+
+	first := received[0]
+	if first.Subject != "Quarterly report" || first.From != "Alice Example <alice@example.com>" {
+		t.Errorf("unexpected first item: %+v", first)
+	}
+	if !strings.Contains(first.Body, "attached numbers") {
+		t.Errorf("plain body not extracted: %q", first.Body)
+	}
+	if first.HatID != core.HatPersonal || first.ExternalID != "INBOX:1:1" {
+		t.Errorf("HatID/ExternalID = %s/%s", first.HatID, first.ExternalID)
+	}
+	if received[1].Body != "Tacos at noon?" {
+		t.Errorf("html body not stripped: %q", received[1].Body)
+	}
+
+	// Fetching must not mark messages as read
+	for _, flag := range mock.Message("INBOX", 1).Flags {
+		if flag == FlagSeen {
+			t.Error("sync marked message as \\Seen")
+		}
+	}
+
+	// Nothing new
+	received = nil
+	result, err = space.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.NewItems != 0 || result.Cursor != "1:2" {
+		t.Errorf("NewItems = %d, Cursor = %q; want 0, 1:2", result.NewItems, result.Cursor)
+	}
+
+	// A new (old, already read) message is still picked up incrementally
+	mock.AddMessage("INBOX", time.Now().AddDate(0, -1, 0), []string{FlagSeen}, "From: carol@example.net\r\n"+
+		"Subject: Late delivery\r\n"+
+		"Message-ID: <late-003@example.net>\r\n"+
+		"\r\n"+
+		"Sorry for the delay.\r\n")
+
+	result, err = space.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.NewItems != 1 || len(received) != 1 || received[0].Subject != "Late delivery" {
+		t.Fatalf("incremental sync: NewItems = %d, received %+v", result.NewItems, received)
+	}
+	if space.GetSyncCursor() != "1:3" {
+		t.Errorf("GetSyncCursor() = %q, want 1:3", space.GetSyncCursor())
+	}
+
+	status := space.GetSyncStatus()
+	if status.Status != "idle" || status.ItemCount != 3 {
+		t.Errorf("GetSyncStatus() = %+v", status)
+	}
+}
+
+func TestSpace_Sync_UIDValidityChange(t *testing.T) {
+	mock := mockservers.NewIMAPMockServer(t)
+	var ids []core.ItemID
+	space := newTestSpace(t, mock, func(ctx context.Context, items []*core.Item) error {
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return nil
+	})
+	if _, err := space.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	first := ids
+	ids = nil
+
+	// A cursor from a different UIDVALIDITY forces a full resync
+	space.SetSyncCursor("99:500")
+
+	result, err := space.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.NewItems != 2 || result.Cursor != "1:2" {
+		t.Errorf("NewItems = %d, Cursor = %q; want 2, 1:2", result.NewItems, result.Cursor)
+	}
+
+	// The resynced messages keep their item IDs
+	if len(first) != 2 || len(ids) != 2 || first[0] != ids[0] || first[1] != ids[1] || first[0] == first[1] {
+		t.Errorf("item IDs = %v then %v; want the same two IDs", first, ids)
+	}
+}
+
+func TestItemID(t *testing.T) {
+	msg := &Message{Ref: MessageRef{Mailbox: "INBOX", UIDValidity: 1, UID: 7}, MessageID: "<a@example.com>"}
+	renumbered := &Message{Ref: MessageRef{Mailbox: "INBOX", UIDValidity: 2, UID: 3}, MessageID: "<a@example.com>"}
+
+	id := itemID("ann@mail.example.com", "INBOX", msg)
+	if !strings.HasPrefix(string(id), "imap_") || id != itemID("ann@mail.example.com", "INBOX", renumbered) {
+		t.Errorf("itemID() = %s, want a stable imap_ ID", id)
+	}
+	if id == itemID("bob@mail.example.com", "INBOX", msg) || id == itemID("ann@mail.example.com", "Archive", msg) {
+		t.Error("itemID() should differ by account and mailbox")
+	}
+
+	// Without a Message-ID the UID is all there is
+	msg.MessageID, renumbered.MessageID = "", ""
+	if itemID("ann@mail.example.com", "INBOX", msg) == itemID("ann@mail.example.com", "INBOX", renumbered) {
+		t.Error("itemID() without a Message-ID should follow the UID")
+	}
+}
+
+func TestSpace_Sync_KeepsCursorOnSaveError(t *testing.T) {
+	mock := mockservers.NewIMAPMockServer(t)
+	fail := true
+	space := newTestSpace(t, mock, func(ctx context.Context, items []*core.Item) error {
+		if fail {
+			return errors.New("disk full")
+		}
+		return nil
+	})
+	ctx := context.Background()
+
+	result, err := space.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(result.Errors) != 1 || result.Cursor != "" || space.GetSyncCursor() != "" {
+		t.Errorf("Errors = %v, Cursor = %q, GetSyncCursor() = %q; want the old cursor",
+			result.Errors, result.Cursor, space.GetSyncCursor())
+	}
+	if status := space.GetSyncStatus(); status.Status != "error" {
+		t.Errorf("GetSyncStatus() = %+v, want error", status)
+	}
+
+	// The same messages come back once saving works
+	fail = false
+	result, err = space.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if result.NewItems != 2 || result.Cursor != "1:2" {
+		t.Errorf("NewItems = %d, Cursor = %q; want 2, 1:2", result.NewItems, result.Cursor)
+	}
+}
+
+func TestSpace_Watch_ReconnectsAndSavesCursor(t *testing.T) {
+	mock := mockservers.NewIMAPMockServer(t)
+	saved := make(chan string, 4)
+	space := New(Config{
+		ID:   "imap-test",
+		Name: "Fastmail",
+		Server: ServerConfig{
+			Host:     mock.Host,
+			Port:     mock.Port,
+			Username: mock.Username,
+			Password: mock.Password,
+		},
+		OnCursor: func(ctx context.Context, cursor string) error {
+			saved <- cursor
+			return nil
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := space.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer space.Disconnect(context.Background())
+
+	// Simulate the sync connection dropping before Watch starts
+	dropped := space.GetClient()
+	dropped.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- space.Watch(ctx) }()
+
+	select {
+	case cursor := <-saved:
+		if cursor != "1:2" {
+			t.Errorf("saved cursor = %q, want 1:2", cursor)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Watch did not reconnect and save the cursor")
+	}
+	if space.GetClient() == dropped {
+		t.Error("sync connection was not replaced")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestClient_FlagsAndMove(t *testing.T) {
+	mock := mockservers.NewIMAPMockServer(t)
+	space := newTestSpace(t, mock, nil)
+	client := space.GetClient()
+	ctx := context.Background()
+
+	ref := MessageRef{Mailbox: "INBOX", UIDValidity: 1, UID: 1}
+
+	if err := client.AddFlags(ctx, ref, FlagFlagged, "Important"); err != nil {
+		t.Fatalf("AddFlags() error = %v", err)
+	}
+	msg, err := client.GetMessage(ctx, ref)
+	if err != nil {
+		t.Fatalf("GetMessage() error = %v", err)
+	}
+	if !hasFlag(msg.Flags, FlagFlagged) || !hasFlag(msg.Flags, "Important") {
+		t.Errorf("flags not applied: %v", msg.Flags)
+	}
+
+	if err := client.RemoveFlags(ctx, ref, "Important"); err != nil {
+		t.Fatalf("RemoveFlags() error = %v", err)
+	}
+
+	moved, err := client.Move(ctx, ref, "Archive")
+	if err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if moved.Mailbox != "Archive" || moved.UID == 0 {
+		t.Fatalf("Move() = %+v", moved)
+	}
+	if mock.Message("INBOX", 1) != nil {
+		t.Error("message still in INBOX after move")
+	}
+
+	archived, err := client.GetMessage(ctx, moved)
+	if err != nil || archived.Subject != "Quarterly report" {
+		t.Fatalf("GetMessage(archived) = %+v, %v", archived, err)
+	}
+
+	// Stale UIDVALIDITY is rejected
+	if err := client.AddFlags(ctx, MessageRef{Mailbox: "INBOX", UIDValidity: 7, UID: 2}, FlagFlagged); err == nil {
+		t.Error("expected error for stale uidvalidity")
+	}
+}
+
+func TestClient_Idle_Timeout(t *testing.T) {
+	mock := mockservers.NewIMAPMockServer(t)
+	space := newTestSpace(t, mock, nil)
+
+	changed, err := space.GetClient().Idle(context.Background(), "INBOX", 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Idle() error = %v", err)
+	}
+	if changed {
+		t.Error("Idle() reported a change without new mail")
+	}
+}
+
+func TestSpace_Send_NotConfigured(t *testing.T) {
+	space := New(Config{ID: "imap-1", Name: "Mail", Sender: email.NewSender(email.Config{})})
+
+	err := space.Send(context.Background(), &email.Message{To: []string{"a@example.com"}, Subject: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "smtp not configured") {
+		t.Errorf("Send() error = %v", err)
+	}
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCredentials_RoundTrip(t *testing.T) {
+	creds := &Credentials{
+		Host:     "imap.fastmail.com",
+		Port:     993,
+		Username: "me@fastmail.com",
+		Password: "app-password",
+		UseTLS:   true,
+		SMTPHost: "smtp.fastmail.com",
+	}
+
+	data, err := CredentialsToJSON(creds)
+	if err != nil {
+		t.Fatalf("CredentialsToJSON() error = %v", err)
+	}
+	got, err := CredentialsFromJSON(data)
+	if err != nil {
+		t.Fatalf("CredentialsFromJSON() error = %v", err)
+	}
+	if *got != *creds {
+		t.Errorf("round trip = %+v, want %+v", got, creds)
+	}
+	if got.ServerConfig().Addr() != "imap.fastmail.com:993" {
+		t.Errorf("Addr() = %s", got.ServerConfig().Addr())
+	}
+	if sender := got.Sender(); sender == nil || !sender.IsConfigured() {
+		t.Error("expected a configured SMTP sender")
+	}
+
+	if _, err := CredentialsFromJSON([]byte(`{"password":"x"}`)); err == nil {
+		t.Error("expected error for incomplete credentials")
+	}
+}
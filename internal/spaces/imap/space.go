@@ -0,0 +1,529 @@
+package imap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	goimap "github.com/emersion/go-imap"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/email"
+	"github.com/quantumlife/quantumlife/internal/spaces"
+)
+
+const (
+	// maxSyncMessages bounds a single sync, matching the Gmail space
+	maxSyncMessages = 100
+
+	// idleTimeout restarts IDLE before the 30 minute server timeout (RFC 2177)
+	idleTimeout = 25 * time.Minute
+)
+
+// Space implements a generic IMAP data source
+type Space struct {
+	id           core.SpaceID
+	name         string
+	defaultHatID core.HatID
+
+	server         ServerConfig
+	mailbox        string
+	archiveMailbox string
+	sender         *email.Sender
+	onItems        func(ctx context.Context, items []*core.Item) error
+	onCursor       func(ctx context.Context, cursor string) error
+
+	// Client
+	client *Client
+
+	// State
+	connected  bool
+	syncStatus spaces.SyncStatus
+	syncCursor string // "uidvalidity:lastUID"
+
+	mu sync.RWMutex
+}
+
+// Config for creating an IMAP space
+type Config struct {
+	ID           core.SpaceID
+	Name         string
+	DefaultHatID core.HatID
+	Server       ServerConfig
+
+	// Mailbox to sync (default: INBOX)
+	Mailbox string
+
+	// ArchiveMailbox is where archived messages are moved (default: Archive)
+	ArchiveMailbox string
+
+	// Sender delivers outbound mail over SMTP (optional)
+	Sender *email.Sender
+
+	// OnItems receives the items fetched by each sync (optional)
+	OnItems func(ctx context.Context, items []*core.Item) error
+
+	// OnCursor persists the cursor after each complete sync run by Watch (optional)
+	OnCursor func(ctx context.Context, cursor string) error
+}
+
+// New creates a new IMAP space
+func New(cfg Config) *Space {
+	if cfg.Mailbox == "" {
+		cfg.Mailbox = "INBOX"
+	}
+	if cfg.ArchiveMailbox == "" {
+		cfg.ArchiveMailbox = "Archive"
+	}
+
+	return &Space{
+		id:             cfg.ID,
+		name:           cfg.Name,
+		defaultHatID:   cfg.DefaultHatID,
+		server:         cfg.Server,
+		mailbox:        cfg.Mailbox,
+		archiveMailbox: cfg.ArchiveMailbox,
+		sender:         cfg.Sender,
+		onItems:        cfg.OnItems,
+		onCursor:       cfg.OnCursor,
+		syncStatus: spaces.SyncStatus{
+			Status: "idle",
+		},
+	}
+}
+
+// ID returns the space ID
+func (s *Space) ID() core.SpaceID {
+	return s.id
+}
+
+// Type returns the space type
+func (s *Space) Type() core.SpaceType {
+	return core.SpaceTypeEmail
+}
+
+// Provider returns the provider name
+func (s *Space) Provider() string {
+	return "imap"
+}
+
+// Name returns the space name
+func (s *Space) Name() string {
+	return s.name
+}
+
+// IsConnected returns connection status
+func (s *Space) IsConnected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connected
+}
+
+// EmailAddress returns the account's login name
+func (s *Space) EmailAddress() string {
+	return s.server.Username
+}
+
+// ArchiveMailbox returns the mailbox archived messages are moved to
+func (s *Space) ArchiveMailbox() string {
+	return s.archiveMailbox
+}
+
+// Connect logs in to the IMAP server and verifies the mailbox exists.
+// Calling it again replaces the current connection.
+func (s *Space) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	client, err := Dial(ctx, s.server)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+
+	if _, err := client.Examine(ctx, s.mailbox); err != nil {
+		client.Close()
+		return fmt.Errorf("verify connection: %w", err)
+	}
+
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.client = client
+	s.connected = true
+	s.syncStatus.Status = "idle"
+
+	return nil
+}
+
+// SetSyncCursor sets the sync cursor ("uidvalidity:lastUID")
+func (s *Space) SetSyncCursor(cursor string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncCursor = cursor
+}
+
+// GetSyncCursor returns the current sync cursor
+func (s *Space) GetSyncCursor() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.syncCursor
+}
+
+// Disconnect closes the connection
+func (s *Space) Disconnect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.connected = false
+	s.client = nil
+	s.syncStatus.Status = "disconnected"
+
+	return nil
+}
+
+// Sync fetches messages that arrived since the cursor. Without a cursor, or
+// when the mailbox UIDVALIDITY changed, it falls back to unread and recent
+// messages like the Gmail space's initial sync.
+func (s *Space) Sync(ctx context.Context) (*spaces.SyncResult, error) {
+	s.mu.Lock()
+	if !s.connected {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("not connected")
+	}
+	s.syncStatus.Status = "syncing"
+	client := s.client
+	cursor := s.syncCursor
+	s.mu.Unlock()
+
+	start := time.Now()
+	result := &spaces.SyncResult{}
+
+	status, err := client.Examine(ctx, s.mailbox)
+	if err != nil {
+		s.setSyncError(err)
+		return nil, fmt.Errorf("examine mailbox: %w", err)
+	}
+
+	var uids []uint32
+	validity, lastUID, ok := parseCursor(cursor)
+	if ok && validity == status.UIDValidity {
+		// Incremental sync: everything above the last seen UID, oldest first
+		uids, err = client.SearchUIDs(ctx, s.mailbox, uidsAfter(lastUID))
+		uids = filterAbove(uids, lastUID)
+		sortUIDs(uids)
+		if len(uids) > maxSyncMessages {
+			uids = uids[:maxSyncMessages]
+		}
+	} else {
+		// Initial sync (or mailbox reset) - unread or from the last week
+		uids, err = client.SearchUIDs(ctx, s.mailbox, recentCriteria(time.Now()))
+		sortUIDs(uids)
+		if len(uids) > maxSyncMessages {
+			uids = uids[len(uids)-maxSyncMessages:]
+		}
+		lastUID = 0
+		if status.UIDNext > 0 {
+			lastUID = status.UIDNext - 1
+		}
+	}
+	if err != nil {
+		s.setSyncError(err)
+		return nil, fmt.Errorf("search messages: %w", err)
+	}
+
+	messages, err := client.FetchMessages(ctx, s.mailbox, uids)
+	if err != nil {
+		s.setSyncError(err)
+		return nil, fmt.Errorf("fetch messages: %w", err)
+	}
+
+	items := make([]*core.Item, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Ref.UID > lastUID {
+			lastUID = msg.Ref.UID
+		}
+		item := msg.ToItem(s.id)
+		item.ID = itemID(s.server.Username+"@"+s.server.Host, s.mailbox, msg)
+		item.HatID = s.defaultHatID
+		items = append(items, item)
+	}
+
+	if s.onItems != nil && len(items) > 0 {
+		if err := s.onItems(ctx, items); err != nil {
+			result.Errors = append(result.Errors, err)
+		}
+	}
+
+	result.NewItems = len(items)
+	result.Duration = time.Since(start)
+
+	// Messages that weren't saved are fetched again next time
+	if len(result.Errors) > 0 {
+		result.Cursor = cursor
+		s.setSyncError(result.Errors[0])
+		return result, nil
+	}
+	result.Cursor = formatCursor(status.UIDValidity, lastUID)
+
+	s.mu.Lock()
+	s.syncCursor = result.Cursor
+	s.syncStatus.Status = "idle"
+	s.syncStatus.LastSync = time.Now()
+	s.syncStatus.ItemCount += result.NewItems
+	s.mu.Unlock()
+
+	return result, nil
+}
+
+// Watch keeps a dedicated IDLE connection open and syncs whenever the
+// server reports new messages. After a failure both connections are
+// redialled with backoff. It blocks until ctx is cancelled.
+func (s *Space) Watch(ctx context.Context) error {
+	backoff := time.Second
+
+	for ctx.Err() == nil {
+		idler, err := Dial(ctx, s.server)
+		if err == nil {
+			backoff = time.Second
+			err = s.watchLoop(ctx, idler)
+			idler.Close()
+		}
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		s.setSyncError(err)
+		if !sleepContext(ctx, backoff) {
+			break
+		}
+		backoff = min(backoff*2, 5*time.Minute)
+
+		// The sync connection usually drops along with the IDLE one
+		if err := s.Connect(ctx); err != nil {
+			s.setSyncError(err)
+		}
+	}
+
+	return ctx.Err()
+}
+
+func (s *Space) watchLoop(ctx context.Context, idler *Client) error {
+	// Catch up on anything that arrived while we were not idling
+	if s.IsConnected() {
+		if err := s.syncAndSave(ctx); err != nil {
+			return err
+		}
+	}
+
+	for {
+		changed, err := idler.Idle(ctx, s.mailbox, idleTimeout)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if changed {
+			if err := s.syncAndSave(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// syncAndSave syncs and hands the new cursor to OnCursor. A cursor that
+// can't be saved is reported but doesn't stop watching.
+func (s *Space) syncAndSave(ctx context.Context) error {
+	result, err := s.Sync(ctx)
+	if err != nil {
+		return err
+	}
+	if s.onCursor != nil && len(result.Errors) == 0 {
+		if err := s.onCursor(ctx, result.Cursor); err != nil {
+			s.setSyncError(fmt.Errorf("save cursor: %w", err))
+		}
+	}
+	return nil
+}
+
+// Send delivers a message through the configured SMTP sender
+func (s *Space) Send(ctx context.Context, msg *email.Message) error {
+	if s.sender == nil || !s.sender.IsConfigured() {
+		return fmt.Errorf("smtp not configured for %s", s.name)
+	}
+	return s.sender.Send(ctx, msg)
+}
+
+// GetMessage fetches a message over the current connection
+func (s *Space) GetMessage(ctx context.Context, ref MessageRef) (*Message, error) {
+	client, err := s.activeClient()
+	if err != nil {
+		return nil, err
+	}
+	return client.GetMessage(ctx, ref)
+}
+
+// Move moves a message over the current connection
+func (s *Space) Move(ctx context.Context, ref MessageRef, dest string) (MessageRef, error) {
+	client, err := s.activeClient()
+	if err != nil {
+		return MessageRef{}, err
+	}
+	return client.Move(ctx, ref, dest)
+}
+
+// Copy copies a message over the current connection
+func (s *Space) Copy(ctx context.Context, ref MessageRef, dest string) (MessageRef, error) {
+	client, err := s.activeClient()
+	if err != nil {
+		return MessageRef{}, err
+	}
+	return client.Copy(ctx, ref, dest)
+}
+
+// Delete deletes a message over the current connection
+func (s *Space) Delete(ctx context.Context, ref MessageRef) error {
+	client, err := s.activeClient()
+	if err != nil {
+		return err
+	}
+	return client.Delete(ctx, ref)
+}
+
+// AddFlags adds flags to a message over the current connection
+func (s *Space) AddFlags(ctx context.Context, ref MessageRef, flags ...string) error {
+	client, err := s.activeClient()
+	if err != nil {
+		return err
+	}
+	return client.AddFlags(ctx, ref, flags...)
+}
+
+// RemoveFlags removes flags from a message over the current connection
+func (s *Space) RemoveFlags(ctx context.Context, ref MessageRef, flags ...string) error {
+	client, err := s.activeClient()
+	if err != nil {
+		return err
+	}
+	return client.RemoveFlags(ctx, ref, flags...)
+}
+
+// SupportsKeywords reports whether the mailbox accepts custom keywords
+func (s *Space) SupportsKeywords(ctx context.Context, mailbox string) (bool, error) {
+	client, err := s.activeClient()
+	if err != nil {
+		return false, err
+	}
+	return client.SupportsKeywords(ctx, mailbox)
+}
+
+// activeClient returns the current connection, which changes when Watch
+// reconnects, so callers shouldn't hold on to it
+func (s *Space) activeClient() (*Client, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.connected || s.client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return s.client, nil
+}
+
+// GetSyncStatus returns the current sync status
+func (s *Space) GetSyncStatus() spaces.SyncStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.syncStatus
+}
+
+// GetClient returns the IMAP client (nil if not connected)
+func (s *Space) GetClient() *Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+func (s *Space) setSyncError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncStatus.Status = "error"
+	s.syncStatus.LastError = err.Error()
+}
+
+// itemID derives an item ID from the account, mailbox and Message-ID, so a
+// message keeps its item when the server renumbers the mailbox. Messages
+// without a Message-ID fall back to their UID.
+func itemID(account, mailbox string, msg *Message) core.ItemID {
+	key := msg.MessageID
+	if key == "" {
+		key = msg.Ref.String()
+	}
+	sum := sha256.Sum256([]byte(account + "\x00" + mailbox + "\x00" + key))
+	return core.ItemID("imap_" + hex.EncodeToString(sum[:16]))
+}
+
+// parseCursor parses "uidvalidity:lastUID"
+func parseCursor(cursor string) (uint32, uint32, bool) {
+	var validity, uid uint32
+	if _, err := fmt.Sscanf(cursor, "%d:%d", &validity, &uid); err != nil {
+		return 0, 0, false
+	}
+	return validity, uid, true
+}
+
+func formatCursor(validity, uid uint32) string {
+	return fmt.Sprintf("%d:%d", validity, uid)
+}
+
+// uidsAfter matches UIDs greater than uid. Note "n:*" always includes the
+// highest UID, so results still need filterAbove.
+func uidsAfter(uid uint32) *goimap.SearchCriteria {
+	criteria := goimap.NewSearchCriteria()
+	criteria.Uid = new(goimap.SeqSet)
+	criteria.Uid.AddRange(uid+1, 0)
+	return criteria
+}
+
+// recentCriteria matches unread messages or messages from the last 7 days
+func recentCriteria(now time.Time) *goimap.SearchCriteria {
+	unseen := goimap.NewSearchCriteria()
+	unseen.WithoutFlags = []string{FlagSeen}
+
+	recent := goimap.NewSearchCriteria()
+	recent.Since = now.AddDate(0, 0, -7)
+
+	criteria := goimap.NewSearchCriteria()
+	criteria.Or = [][2]*goimap.SearchCriteria{{unseen, recent}}
+	return criteria
+}
+
+func filterAbove(uids []uint32, min uint32) []uint32 {
+	filtered := uids[:0]
+	for _, uid := range uids {
+		if uid > min {
+			filtered = append(filtered, uid)
+		}
+	}
+	return filtered
+}
+
+func sortUIDs(uids []uint32) {
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+}
+
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
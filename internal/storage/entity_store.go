@@ -0,0 +1,605 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/quantumlife/quantumlife/internal/core"
+)
+
+// EntityStore manages the entity graph: canonical people, organizations and
+// places, the aliases they go by, and their links to items, hats and events
+type EntityStore struct {
+	db *DB
+}
+
+// NewEntityStore creates a new entity store
+func NewEntityStore(db *DB) *EntityStore {
+	return &EntityStore{db: db}
+}
+
+// EntityProfile is everything known about one entity
+type EntityProfile struct {
+	Entity    *core.Entity `json:"entity"`
+	ItemCount int          `json:"item_count"`
+	Items     []*core.Item `json:"items"`  // Most recent first
+	Hats      []EntityHat  `json:"hats"`   // Most items first
+	Events    []string     `json:"events"` // Event IDs, most recent first
+}
+
+// EntityHat counts an entity's items in one hat
+type EntityHat struct {
+	HatID core.HatID `json:"hat_id"`
+	Items int        `json:"items"`
+}
+
+// NormalizeAlias returns the form aliases are stored and matched in
+func NormalizeAlias(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+// ParseAddress splits "Alice Smith <alice@example.com>" into a display name
+// and a normalized email address. A bare name comes back with no email.
+func ParseAddress(s string) (name, email string) {
+	s = strings.TrimSpace(s)
+	if addr, err := mail.ParseAddress(s); err == nil {
+		return strings.TrimSpace(addr.Name), NormalizeAlias(addr.Address)
+	}
+	if strings.Contains(s, "@") && !strings.ContainsAny(s, " <>") {
+		return "", NormalizeAlias(s)
+	}
+	return s, ""
+}
+
+// addressAliases returns the aliases for a parsed address, email first so it
+// wins when resolving
+func addressAliases(name, email string) []core.EntityAlias {
+	var aliases []core.EntityAlias
+	if email != "" {
+		aliases = append(aliases, core.EntityAlias{Type: core.AliasTypeEmail, Value: email})
+	}
+	if name != "" {
+		aliases = append(aliases, core.EntityAlias{Type: core.AliasTypeName, Value: NormalizeAlias(name)})
+	}
+	return aliases
+}
+
+// LinkItem resolves the item's sender, recipients and mentioned entities and
+// links them to it, replacing links from an earlier call. Kinds classifies
+// mentioned entities by name; senders and recipients are people.
+func (s *EntityStore) LinkItem(item *core.Item, kinds map[string]core.EntityKind) error {
+	seen := item.Timestamp.UTC()
+	if item.Timestamp.IsZero() {
+		seen = time.Now().UTC()
+	}
+
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		touched, err := unlinkTx(tx, core.EntityTargetItem, string(item.ID))
+		if err != nil {
+			return err
+		}
+
+		link := func(kind core.EntityKind, name string, aliases []core.EntityAlias, relation core.EntityRelation) error {
+			if len(aliases) == 0 {
+				return nil
+			}
+			id, err := resolveTx(tx, kind, name, aliases)
+			if err != nil {
+				return err
+			}
+			touched[id] = true
+			return linkTx(tx, id, core.EntityTargetItem, string(item.ID), relation, aliases[0].Value, seen)
+		}
+
+		if item.From != "" {
+			name, email := ParseAddress(item.From)
+			if err := link(core.EntityKindPerson, name, addressAliases(name, email), core.RelationSender); err != nil {
+				return err
+			}
+		}
+		for _, to := range item.To {
+			name, email := ParseAddress(to)
+			if err := link(core.EntityKindPerson, name, addressAliases(name, email), core.RelationRecipient); err != nil {
+				return err
+			}
+		}
+		for _, mention := range item.Entities {
+			kind, ok := kinds[mention]
+			if !ok {
+				kind = core.EntityKindOther
+			}
+			name := strings.TrimSpace(mention)
+			if err := link(kind, name, addressAliases(name, ""), core.RelationMentioned); err != nil {
+				return err
+			}
+		}
+
+		for id := range touched {
+			if err := refreshHatsTx(tx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LinkEvent links a calendar event to its attendees, replacing links from an
+// earlier call
+func (s *EntityStore) LinkEvent(eventID string, start time.Time, attendees []*mail.Address) error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		if _, err := unlinkTx(tx, core.EntityTargetEvent, eventID); err != nil {
+			return err
+		}
+
+		for _, attendee := range attendees {
+			aliases := addressAliases(strings.TrimSpace(attendee.Name), NormalizeAlias(attendee.Address))
+			if len(aliases) == 0 {
+				continue
+			}
+			id, err := resolveTx(tx, core.EntityKindPerson, attendee.Name, aliases)
+			if err != nil {
+				return err
+			}
+			if err := linkTx(tx, id, core.EntityTargetEvent, eventID, core.RelationAttendee, aliases[0].Value, start.UTC()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get returns an entity with its aliases
+func (s *EntityStore) Get(id string) (*core.Entity, error) {
+	entity := &core.Entity{}
+	err := s.db.conn.QueryRow(`
+		SELECT id, kind, name, created_at, updated_at FROM entities WHERE id = ?
+	`, id).Scan(&entity.ID, &entity.Kind, &entity.Name, &entity.CreatedAt, &entity.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, core.ErrEntityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.conn.Query(`
+		SELECT type, value FROM entity_aliases WHERE entity_id = ? ORDER BY type, created_at, value
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias core.EntityAlias
+		if err := rows.Scan(&alias.Type, &alias.Value); err != nil {
+			return nil, err
+		}
+		entity.Aliases = append(entity.Aliases, alias)
+	}
+
+	return entity, rows.Err()
+}
+
+// Resolve finds the entity behind an email address, "Name <address>" or name
+func (s *EntityStore) Resolve(ref string) (*core.Entity, error) {
+	name, email := ParseAddress(ref)
+	for _, alias := range addressAliases(name, email) {
+		var id string
+		err := s.db.conn.QueryRow(`
+			SELECT entity_id FROM entity_aliases WHERE type = ? AND value = ?
+		`, alias.Type, alias.Value).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return s.Get(id)
+	}
+	return nil, core.ErrEntityNotFound
+}
+
+// Search returns entities whose name or an alias contains the query
+func (s *EntityStore) Search(query string, limit int) ([]*core.Entity, error) {
+	pattern := "%" + NormalizeAlias(query) + "%"
+	rows, err := s.db.conn.Query(`
+		SELECT DISTINCT e.id, e.name
+		FROM entities e
+		LEFT JOIN entity_aliases a ON a.entity_id = e.id
+		WHERE LOWER(e.name) LIKE ? OR a.value LIKE ?
+		ORDER BY e.name
+		LIMIT ?
+	`, pattern, pattern, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	entities := make([]*core.Entity, 0, len(ids))
+	for _, id := range ids {
+		entity, err := s.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// Names returns the display names of all entities of a kind
+func (s *EntityStore) Names(kind core.EntityKind) ([]string, error) {
+	rows, err := s.db.conn.Query(`SELECT name FROM entities WHERE kind = ? ORDER BY name`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// About resolves a reference and returns the entity's profile with up to
+// limit recent items and events
+func (s *EntityStore) About(ref string, limit int) (*EntityProfile, error) {
+	entity, err := s.Resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile(entity, limit)
+}
+
+// Profile returns an entity's profile with up to limit recent items and events
+func (s *EntityStore) Profile(id string, limit int) (*EntityProfile, error) {
+	entity, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.profile(entity, limit)
+}
+
+func (s *EntityStore) profile(entity *core.Entity, limit int) (*EntityProfile, error) {
+	profile := &EntityProfile{Entity: entity}
+
+	err := s.db.conn.QueryRow(`
+		SELECT COUNT(DISTINCT target_id) FROM entity_edges WHERE entity_id = ? AND target_type = ?
+	`, entity.ID, core.EntityTargetItem).Scan(&profile.ItemCount)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.conn.Query(`
+		SELECT id, type, status, space_id, external_id, hat_id, confidence,
+		       subject, body, summary, sender, recipients, item_timestamp,
+		       priority, sentiment, entities, action_items,
+		       has_attachments, attachment_ids, embedding_id,
+		       created_at, updated_at
+		FROM items
+		WHERE id IN (SELECT target_id FROM entity_edges WHERE entity_id = ? AND target_type = ?)
+		ORDER BY item_timestamp DESC
+		LIMIT ?
+	`, entity.ID, core.EntityTargetItem, limit)
+	if err != nil {
+		return nil, err
+	}
+	profile.Items, err = NewItemStore(s.db).scanItems(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = s.db.conn.Query(`
+		SELECT target_id, weight FROM entity_edges
+		WHERE entity_id = ? AND target_type = ?
+		ORDER BY weight DESC, target_id
+	`, entity.ID, core.EntityTargetHat)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var hat EntityHat
+		if err := rows.Scan(&hat.HatID, &hat.Items); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		profile.Hats = append(profile.Hats, hat)
+	}
+	rows.Close()
+
+	rows, err = s.db.conn.Query(`
+		SELECT target_id FROM entity_edges
+		WHERE entity_id = ? AND target_type = ?
+		ORDER BY last_seen DESC
+		LIMIT ?
+	`, entity.ID, core.EntityTargetEvent, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var eventID string
+		if err := rows.Scan(&eventID); err != nil {
+			return nil, err
+		}
+		profile.Events = append(profile.Events, eventID)
+	}
+
+	return profile, rows.Err()
+}
+
+// Merge folds source into target: its aliases and links move over and source
+// is deleted. Use it when one person turned up under two addresses.
+func (s *EntityStore) Merge(targetID, sourceID string) (*core.Entity, error) {
+	if targetID == sourceID {
+		return nil, fmt.Errorf("%w: cannot merge an entity into itself", core.ErrInvalidInput)
+	}
+
+	err := s.db.Transaction(func(tx *sql.Tx) error {
+		var targetKind, sourceKind core.EntityKind
+		if err := tx.QueryRow("SELECT kind FROM entities WHERE id = ?", targetID).Scan(&targetKind); err != nil {
+			return entityErr(err)
+		}
+		if err := tx.QueryRow("SELECT kind FROM entities WHERE id = ?", sourceID).Scan(&sourceKind); err != nil {
+			return entityErr(err)
+		}
+
+		if _, err := tx.Exec("UPDATE entity_aliases SET entity_id = ? WHERE entity_id = ?", targetID, sourceID); err != nil {
+			return err
+		}
+
+		// Hat links are derived from item links and rebuilt below
+		_, err := tx.Exec(`
+			INSERT INTO entity_edges (entity_id, target_type, target_id, relation, alias, weight, last_seen)
+			SELECT ?, target_type, target_id, relation, alias, weight, last_seen
+			FROM entity_edges WHERE entity_id = ? AND target_type != ?
+			ON CONFLICT (entity_id, target_type, target_id, relation) DO UPDATE SET
+			    last_seen = MAX(last_seen, excluded.last_seen)
+		`, targetID, sourceID, core.EntityTargetHat)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM entity_edges WHERE entity_id = ?", sourceID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM entities WHERE id = ?", sourceID); err != nil {
+			return err
+		}
+
+		if targetKind == core.EntityKindOther {
+			targetKind = sourceKind
+		}
+		_, err = tx.Exec("UPDATE entities SET kind = ?, updated_at = ? WHERE id = ?", targetKind, time.Now().UTC(), targetID)
+		if err != nil {
+			return err
+		}
+
+		return refreshHatsTx(tx, targetID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(targetID)
+}
+
+// Split moves the given aliases, and the links they produced, off an entity
+// onto a new one named name. Use it when two people were taken for one.
+func (s *EntityStore) Split(id string, aliases []string, name string) (*core.Entity, error) {
+	if len(aliases) == 0 {
+		return nil, fmt.Errorf("%w: no aliases to split off", core.ErrInvalidInput)
+	}
+
+	newID := uuid.New().String()
+	err := s.db.Transaction(func(tx *sql.Tx) error {
+		var kind core.EntityKind
+		if err := tx.QueryRow("SELECT kind FROM entities WHERE id = ?", id).Scan(&kind); err != nil {
+			return entityErr(err)
+		}
+
+		var remaining int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM entity_aliases WHERE entity_id = ?", id).Scan(&remaining); err != nil {
+			return err
+		}
+
+		moving := make([]string, 0, len(aliases))
+		for _, alias := range aliases {
+			value := NormalizeAlias(alias)
+			var count int
+			err := tx.QueryRow(`
+				SELECT COUNT(*) FROM entity_aliases WHERE entity_id = ? AND value = ?
+			`, id, value).Scan(&count)
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return fmt.Errorf("%w: %q is not an alias of this entity", core.ErrInvalidInput, alias)
+			}
+			moving = append(moving, value)
+			remaining -= count
+		}
+		if remaining <= 0 {
+			return fmt.Errorf("%w: cannot split off every alias", core.ErrInvalidInput)
+		}
+
+		if strings.TrimSpace(name) == "" {
+			name = moving[0]
+		}
+		now := time.Now().UTC()
+		_, err := tx.Exec(`
+			INSERT INTO entities (id, kind, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		`, newID, kind, strings.TrimSpace(name), now, now)
+		if err != nil {
+			return err
+		}
+
+		for _, value := range moving {
+			_, err := tx.Exec(`
+				UPDATE entity_aliases SET entity_id = ? WHERE entity_id = ? AND value = ?
+			`, newID, id, value)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`
+				UPDATE entity_edges SET entity_id = ?
+				WHERE entity_id = ? AND target_type != ? AND alias = ?
+			`, newID, id, core.EntityTargetHat, value)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := refreshHatsTx(tx, id); err != nil {
+			return err
+		}
+		return refreshHatsTx(tx, newID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(newID)
+}
+
+// resolveTx returns the entity behind the first known alias, creating one if
+// none is known, and attaches the aliases that are new
+func resolveTx(tx *sql.Tx, kind core.EntityKind, name string, aliases []core.EntityAlias) (string, error) {
+	now := time.Now().UTC()
+	name = strings.TrimSpace(name)
+
+	var id string
+	for _, alias := range aliases {
+		err := tx.QueryRow(`
+			SELECT entity_id FROM entity_aliases WHERE type = ? AND value = ?
+		`, alias.Type, alias.Value).Scan(&id)
+		if err == nil {
+			break
+		}
+		if err != sql.ErrNoRows {
+			return "", err
+		}
+	}
+
+	if id == "" {
+		id = uuid.New().String()
+		if name == "" {
+			name = aliases[0].Value
+		}
+		_, err := tx.Exec(`
+			INSERT INTO entities (id, kind, name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+		`, id, kind, name, now, now)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		// Learn what an entity is, and a real name for one only known by address
+		_, err := tx.Exec(`
+			UPDATE entities SET
+			    kind = CASE WHEN kind = ? THEN ? ELSE kind END,
+			    name = CASE WHEN ? != '' AND name LIKE '%@%' THEN ? ELSE name END,
+			    updated_at = ?
+			WHERE id = ?
+		`, core.EntityKindOther, kind, name, name, now, id)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Aliases already claimed by another entity stay there; merge to combine
+	for _, alias := range aliases {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO entity_aliases (type, value, entity_id, created_at) VALUES (?, ?, ?, ?)
+		`, alias.Type, alias.Value, id, now)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return id, nil
+}
+
+// linkTx creates or refreshes an edge
+func linkTx(tx *sql.Tx, entityID string, targetType core.EntityTarget, targetID string, relation core.EntityRelation, alias string, seen time.Time) error {
+	_, err := tx.Exec(`
+		INSERT INTO entity_edges (entity_id, target_type, target_id, relation, alias, weight, last_seen)
+		VALUES (?, ?, ?, ?, ?, 1, ?)
+		ON CONFLICT (entity_id, target_type, target_id, relation) DO UPDATE SET
+		    alias = excluded.alias,
+		    last_seen = excluded.last_seen
+	`, entityID, targetType, targetID, relation, alias, seen)
+	return err
+}
+
+// unlinkTx removes a target's edges and returns the entities they belonged to
+func unlinkTx(tx *sql.Tx, targetType core.EntityTarget, targetID string) (map[string]bool, error) {
+	rows, err := tx.Query(`
+		SELECT DISTINCT entity_id FROM entity_edges WHERE target_type = ? AND target_id = ?
+	`, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	touched := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		touched[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("DELETE FROM entity_edges WHERE target_type = ? AND target_id = ?", targetType, targetID)
+	return touched, err
+}
+
+// refreshHatsTx rebuilds an entity's hat links from the hats of its items
+func refreshHatsTx(tx *sql.Tx, entityID string) error {
+	_, err := tx.Exec("DELETE FROM entity_edges WHERE entity_id = ? AND target_type = ?", entityID, core.EntityTargetHat)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO entity_edges (entity_id, target_type, target_id, relation, weight, last_seen)
+		SELECT e.entity_id, ?, i.hat_id, ?, COUNT(DISTINCT i.id), MAX(e.last_seen)
+		FROM entity_edges e
+		JOIN items i ON i.id = e.target_id
+		WHERE e.entity_id = ? AND e.target_type = ?
+		GROUP BY i.hat_id
+	`, core.EntityTargetHat, core.RelationActiveIn, entityID, core.EntityTargetItem)
+	return err
+}
+
+func entityErr(err error) error {
+	if err == sql.ErrNoRows {
+		return core.ErrEntityNotFound
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxFTSTerms caps the number of terms in a full-text query
+const maxFTSTerms = 32
+
+// FTSQuery turns free text into an FTS5 MATCH expression. Each whitespace
+// separated term becomes a quoted phrase, so "alice@example.com" or
+// "INV-2024-001" match their tokens in sequence, and terms are ORed so BM25
+// can rank partial matches. It returns "" when nothing is searchable.
+func FTSQuery(text string) string {
+	var phrases []string
+	for _, term := range strings.Fields(text) {
+		term = strings.TrimFunc(term, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if utf8.RuneCountInString(term) < 2 {
+			continue
+		}
+		phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+		if len(phrases) == maxFTSTerms {
+			break
+		}
+	}
+	return strings.Join(phrases, " OR ")
+}
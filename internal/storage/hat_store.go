@@ -23,7 +23,7 @@ func (s *HatStore) GetAll() ([]*core.Hat, error) {
 	rows, err := s.db.conn.Query(`
 		SELECT id, name, description, icon, color, priority,
 		       is_system, is_active, auto_respond, auto_prioritize, personality,
-		       created_at, updated_at
+		       sensitivity, created_at, updated_at
 		FROM hats
 		ORDER BY priority ASC
 	`)
@@ -41,7 +41,7 @@ func (s *HatStore) GetAll() ([]*core.Hat, error) {
 			&hat.ID, &hat.Name, &description, &hat.Icon, &hat.Color,
 			&hat.Priority, &hat.IsSystem, &hat.IsActive,
 			&hat.AutoRespond, &hat.AutoPrioritize, &personality,
-			&hat.CreatedAt, &hat.UpdatedAt,
+			&hat.Sensitivity, &hat.CreatedAt, &hat.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -63,13 +63,13 @@ func (s *HatStore) GetByID(id core.HatID) (*core.Hat, error) {
 	err := s.db.conn.QueryRow(`
 		SELECT id, name, description, icon, color, priority,
 		       is_system, is_active, auto_respond, auto_prioritize, personality,
-		       created_at, updated_at
+		       sensitivity, created_at, updated_at
 		FROM hats WHERE id = ?
 	`, id).Scan(
 		&hat.ID, &hat.Name, &description, &hat.Icon, &hat.Color,
 		&hat.Priority, &hat.IsSystem, &hat.IsActive,
 		&hat.AutoRespond, &hat.AutoPrioritize, &personality,
-		&hat.CreatedAt, &hat.UpdatedAt,
+		&hat.Sensitivity, &hat.CreatedAt, &hat.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -93,12 +93,12 @@ func (s *HatStore) Create(hat *core.Hat) error {
 	_, err := s.db.conn.Exec(`
 		INSERT INTO hats (id, name, description, icon, color, priority,
 		                 is_system, is_active, auto_respond, auto_prioritize, personality,
-		                 created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		                 sensitivity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		hat.ID, hat.Name, hat.Description, hat.Icon, hat.Color, hat.Priority,
 		hat.IsSystem, hat.IsActive, hat.AutoRespond, hat.AutoPrioritize, hat.Personality,
-		hat.CreatedAt, hat.UpdatedAt,
+		hat.Sensitivity, hat.CreatedAt, hat.UpdatedAt,
 	)
 
 	return err
@@ -112,18 +112,39 @@ func (s *HatStore) Update(hat *core.Hat) error {
 		UPDATE hats SET
 		    name = ?, description = ?, icon = ?, color = ?,
 		    is_active = ?, auto_respond = ?, auto_prioritize = ?, personality = ?,
-		    updated_at = ?
+		    sensitivity = ?, updated_at = ?
 		WHERE id = ? AND (is_system = FALSE OR id = ?)
 	`,
 		hat.Name, hat.Description, hat.Icon, hat.Color,
 		hat.IsActive, hat.AutoRespond, hat.AutoPrioritize, hat.Personality,
-		hat.UpdatedAt,
+		hat.Sensitivity, hat.UpdatedAt,
 		hat.ID, hat.ID, // System hats can only update themselves
 	)
 
 	return err
 }
 
+// Sensitivity returns the strictest sensitivity of the given hats. Hats
+// that are not found use their default.
+func (s *HatStore) Sensitivity(ids ...core.HatID) (core.Sensitivity, error) {
+	level := core.SensitivityStandard
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		hat, err := s.GetByID(id)
+		if err == core.ErrHatNotFound {
+			level = level.Max(core.DefaultSensitivity(id))
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		level = level.Max(hat.EffectiveSensitivity())
+	}
+	return level, nil
+}
+
 // Delete deletes a non-system hat
 func (s *HatStore) Delete(id core.HatID) error {
 	result, err := s.db.conn.Exec(`DELETE FROM hats WHERE id = ? AND is_system = FALSE`, id)
@@ -144,7 +165,7 @@ func (s *HatStore) GetActive() ([]*core.Hat, error) {
 	rows, err := s.db.conn.Query(`
 		SELECT id, name, description, icon, color, priority,
 		       is_system, is_active, auto_respond, auto_prioritize, personality,
-		       created_at, updated_at
+		       sensitivity, created_at, updated_at
 		FROM hats
 		WHERE is_active = TRUE
 		ORDER BY priority ASC
@@ -163,7 +184,7 @@ func (s *HatStore) GetActive() ([]*core.Hat, error) {
 			&hat.ID, &hat.Name, &description, &hat.Icon, &hat.Color,
 			&hat.Priority, &hat.IsSystem, &hat.IsActive,
 			&hat.AutoRespond, &hat.AutoPrioritize, &personality,
-			&hat.CreatedAt, &hat.UpdatedAt,
+			&hat.Sensitivity, &hat.CreatedAt, &hat.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
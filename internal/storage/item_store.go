@@ -30,24 +30,28 @@ func (s *ItemStore) Create(item *core.Item) error {
 	actionItems, _ := json.Marshal(item.ActionItems)
 	attachmentIDs, _ := json.Marshal(item.AttachmentIDs)
 
-	_, err := s.db.conn.Exec(`
-		INSERT INTO items (
-		    id, type, status, space_id, external_id, hat_id, confidence,
-		    subject, body, summary, sender, recipients, item_timestamp,
-		    priority, sentiment, entities, action_items,
-		    has_attachments, attachment_ids, embedding_id,
-		    created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`,
-		item.ID, item.Type, item.Status, item.SpaceID, item.ExternalID,
-		item.HatID, item.Confidence, item.Subject, item.Body, item.Summary,
-		item.From, string(recipients), item.Timestamp,
-		item.Priority, item.Sentiment, string(entities), string(actionItems),
-		item.HasAttachments, string(attachmentIDs), item.EmbeddingID,
-		item.CreatedAt, item.UpdatedAt,
-	)
-
-	return err
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO items (
+			    id, type, status, space_id, external_id, hat_id, confidence,
+			    subject, body, summary, sender, recipients, item_timestamp,
+			    priority, sentiment, entities, action_items,
+			    has_attachments, attachment_ids, embedding_id,
+			    created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			item.ID, item.Type, item.Status, item.SpaceID, item.ExternalID,
+			item.HatID, item.Confidence, item.Subject, item.Body, item.Summary,
+			item.From, string(recipients), item.Timestamp,
+			item.Priority, item.Sentiment, string(entities), string(actionItems),
+			item.HasAttachments, string(attachmentIDs), item.EmbeddingID,
+			item.CreatedAt, item.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return indexItemText(tx, item.ID)
+	})
 }
 
 // GetByID returns an item by ID
@@ -105,21 +109,158 @@ func (s *ItemStore) Update(item *core.Item) error {
 	entities, _ := json.Marshal(item.Entities)
 	actionItems, _ := json.Marshal(item.ActionItems)
 
-	_, err := s.db.conn.Exec(`
-		UPDATE items SET
-		    status = ?, hat_id = ?, confidence = ?,
-		    summary = ?, priority = ?, sentiment = ?,
-		    entities = ?, action_items = ?, embedding_id = ?,
-		    recipients = ?, updated_at = ?
-		WHERE id = ?
-	`,
-		item.Status, item.HatID, item.Confidence,
-		item.Summary, item.Priority, item.Sentiment,
-		string(entities), string(actionItems), item.EmbeddingID,
-		string(recipients), item.UpdatedAt,
-		item.ID,
-	)
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE items SET
+			    status = ?, hat_id = ?, confidence = ?,
+			    summary = ?, priority = ?, sentiment = ?,
+			    entities = ?, action_items = ?, embedding_id = ?,
+			    recipients = ?, updated_at = ?
+			WHERE id = ?
+		`,
+			item.Status, item.HatID, item.Confidence,
+			item.Summary, item.Priority, item.Sentiment,
+			string(entities), string(actionItems), item.EmbeddingID,
+			string(recipients), item.UpdatedAt,
+			item.ID,
+		)
+		if err != nil {
+			return err
+		}
+		return indexItemText(tx, item.ID)
+	})
+}
+
+// GetByExternalID returns the item a space synced from its source system
+func (s *ItemStore) GetByExternalID(spaceID core.SpaceID, externalID string) (*core.Item, error) {
+	var id core.ItemID
+	err := s.db.conn.QueryRow(`
+		SELECT id FROM items
+		WHERE space_id = ? AND external_id = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, spaceID, externalID).Scan(&id)
+
+	if err == sql.ErrNoRows {
+		return nil, core.ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+// UpdateContent replaces the source content of an item that changed upstream
+// (e.g. an edited note), keeping its ID and classification
+func (s *ItemStore) UpdateContent(item *core.Item) error {
+	item.UpdatedAt = time.Now().UTC()
+
+	entities, _ := json.Marshal(item.Entities)
+
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE items SET
+			    external_id = COALESCE(NULLIF(?, ''), external_id),
+			    subject = ?, body = ?, sender = ?, item_timestamp = ?,
+			    entities = ?, updated_at = ?
+			WHERE id = ?
+		`,
+			item.ExternalID, item.Subject, item.Body, item.From, item.Timestamp,
+			string(entities), item.UpdatedAt,
+			item.ID,
+		)
+		if err != nil {
+			return err
+		}
+		return indexItemText(tx, item.ID)
+	})
+}
+
+// Upsert creates the item, or updates the content of the item already synced
+// with the same space and external ID. Failing that, an item with the same ID
+// is updated and takes the new external ID, so sources that give items stable
+// IDs can renumber them. It reports whether a new item was created.
+func (s *ItemStore) Upsert(item *core.Item) (bool, error) {
+	existing, err := s.GetByExternalID(item.SpaceID, item.ExternalID)
+	if err == core.ErrItemNotFound && item.ID != "" {
+		existing, err = s.GetByID(item.ID)
+	}
+	if err == core.ErrItemNotFound {
+		return true, s.Create(item)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	item.ID = existing.ID
+	item.CreatedAt = existing.CreatedAt
+	return false, s.UpdateContent(item)
+}
+
+// DeleteByExternalID removes the item a space synced from its source system
+func (s *ItemStore) DeleteByExternalID(spaceID core.SpaceID, externalID string) error {
+	return s.db.Transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			DELETE FROM items_fts WHERE item_id IN (
+			    SELECT id FROM items WHERE space_id = ? AND external_id = ?
+			)
+		`, spaceID, externalID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			"DELETE FROM items WHERE space_id = ? AND external_id = ?",
+			spaceID, externalID,
+		)
+		return err
+	})
+}
 
+// SearchText returns items matching the query by full-text search, best BM25
+// match first. An empty hatID searches all hats.
+func (s *ItemStore) SearchText(query string, hatID core.HatID, limit int) ([]*core.Item, error) {
+	match := FTSQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	// Subject and sender weigh more than the body
+	rows, err := s.db.conn.Query(`
+		SELECT i.id, i.type, i.status, i.space_id, i.external_id, i.hat_id, i.confidence,
+		       i.subject, i.body, i.summary, i.sender, i.recipients, i.item_timestamp,
+		       i.priority, i.sentiment, i.entities, i.action_items,
+		       i.has_attachments, i.attachment_ids, i.embedding_id,
+		       i.created_at, i.updated_at
+		FROM items_fts
+		JOIN items i ON i.id = items_fts.item_id
+		WHERE items_fts MATCH ? AND (? = '' OR i.hat_id = ?)
+		ORDER BY bm25(items_fts, 0.0, 3.0, 1.0, 2.0, 1.5, 2.0)
+		LIMIT ?
+	`, match, hatID, hatID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return s.scanItems(rows)
+}
+
+// indexItemText rewrites the full-text row for an item from its stored columns
+func indexItemText(tx *sql.Tx, id core.ItemID) error {
+	if _, err := tx.Exec("DELETE FROM items_fts WHERE item_id = ?", id); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`
+		INSERT INTO items_fts (item_id, subject, body, sender, summary, entities)
+		SELECT id, COALESCE(subject, ''), COALESCE(body, ''), COALESCE(sender, ''),
+		       COALESCE(summary, ''), COALESCE(entities, '')
+		FROM items WHERE id = ?
+	`, id)
 	return err
 }
 
@@ -0,0 +1,74 @@
+package storage
+
+// LLMUsage is one provider's token usage and spend on one day
+type LLMUsage struct {
+	Day          string  `json:"day"` // YYYY-MM-DD
+	Provider     string  `json:"provider"`
+	Requests     int64   `json:"requests"`
+	Failures     int64   `json:"failures"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// LLMUsageStore keeps daily LLM usage so budgets survive restarts
+type LLMUsageStore struct {
+	db *DB
+}
+
+// NewLLMUsageStore creates a new LLM usage store
+func NewLLMUsageStore(db *DB) *LLMUsageStore {
+	return &LLMUsageStore{db: db}
+}
+
+// Add records one request against a provider's totals for the day
+func (s *LLMUsageStore) Add(day, provider string, inputTokens, outputTokens int, costUSD float64, failed bool) error {
+	failures := 0
+	if failed {
+		failures = 1
+	}
+	_, err := s.db.conn.Exec(`
+		INSERT INTO llm_usage (day, provider, requests, failures, input_tokens, output_tokens, cost_usd)
+		VALUES (?, ?, 1, ?, ?, ?, ?)
+		ON CONFLICT(day, provider) DO UPDATE SET
+			requests = requests + 1,
+			failures = failures + excluded.failures,
+			input_tokens = input_tokens + excluded.input_tokens,
+			output_tokens = output_tokens + excluded.output_tokens,
+			cost_usd = cost_usd + excluded.cost_usd
+	`, day, provider, failures, inputTokens, outputTokens, costUSD)
+	return err
+}
+
+// SpendSince returns the total spend from day onwards
+func (s *LLMUsageStore) SpendSince(day string) (float64, error) {
+	var total float64
+	err := s.db.conn.QueryRow(`
+		SELECT COALESCE(SUM(cost_usd), 0) FROM llm_usage WHERE day >= ?
+	`, day).Scan(&total)
+	return total, err
+}
+
+// List returns usage from day onwards, oldest first
+func (s *LLMUsageStore) List(since string) ([]LLMUsage, error) {
+	rows, err := s.db.conn.Query(`
+		SELECT day, provider, requests, failures, input_tokens, output_tokens, cost_usd
+		FROM llm_usage WHERE day >= ?
+		ORDER BY day, provider
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []LLMUsage
+	for rows.Next() {
+		var u LLMUsage
+		if err := rows.Scan(&u.Day, &u.Provider, &u.Requests, &u.Failures,
+			&u.InputTokens, &u.OutputTokens, &u.CostUSD); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,30 @@
+-- Full-text indexes for lexical retrieval (exact names, invoice numbers,
+-- email addresses) alongside vector search.
+-- Rows are keyed by the source ID, not rowid, and maintained by the stores.
+CREATE VIRTUAL TABLE IF NOT EXISTS memories_fts USING fts5(
+    memory_id UNINDEXED,
+    content,
+    summary,
+    entities,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS items_fts USING fts5(
+    item_id UNINDEXED,
+    subject,
+    body,
+    sender,
+    summary,
+    entities,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- Backfill existing rows
+INSERT INTO memories_fts (memory_id, content, summary, entities)
+SELECT id, content, COALESCE(summary, ''), COALESCE(entities, '')
+FROM memories;
+
+INSERT INTO items_fts (item_id, subject, body, sender, summary, entities)
+SELECT id, COALESCE(subject, ''), COALESCE(body, ''), COALESCE(sender, ''),
+       COALESCE(summary, ''), COALESCE(entities, '')
+FROM items;
@@ -0,0 +1,6 @@
+-- Memory lifecycle: importance decay, consolidation and archiving
+ALTER TABLE memories ADD COLUMN decayed_at DATETIME;        -- Last time decay was applied
+ALTER TABLE memories ADD COLUMN archived_at DATETIME;       -- Faded out of retrieval
+ALTER TABLE memories ADD COLUMN consolidated_into TEXT;     -- Semantic memory that summarizes this one
+
+CREATE INDEX IF NOT EXISTS idx_memories_archived_at ON memories(archived_at);
@@ -0,0 +1,20 @@
+-- Embedding model and dimension behind each vector collection
+CREATE TABLE IF NOT EXISTS vector_collections (
+    name TEXT PRIMARY KEY,              -- Logical collection: memories, items, entities
+    physical_name TEXT NOT NULL,        -- Collection in the vector index serving it
+    model TEXT NOT NULL,                -- Embedding model that produced its vectors
+    dimension INTEGER NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Progress of an in-flight reindex into a shadow collection
+CREATE TABLE IF NOT EXISTS vector_reindex (
+    name TEXT PRIMARY KEY,              -- Logical collection being rebuilt
+    shadow_name TEXT NOT NULL,
+    model TEXT NOT NULL,
+    dimension INTEGER NOT NULL,
+    cursor INTEGER NOT NULL DEFAULT 0,  -- rowid of the last source row embedded
+    done INTEGER NOT NULL DEFAULT 0,
+    started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,37 @@
+-- Entity graph: people, organizations and places linked to what you do
+CREATE TABLE IF NOT EXISTS entities (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,                 -- person, organization, place, other
+    name TEXT NOT NULL,                 -- Display name
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every way an entity is referred to; one alias belongs to one entity
+CREATE TABLE IF NOT EXISTS entity_aliases (
+    type TEXT NOT NULL,                 -- email, name
+    value TEXT NOT NULL,                -- Lowercase, trimmed
+    entity_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (type, value),
+    FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_aliases_entity ON entity_aliases(entity_id);
+
+-- Links from entities to items, hats and events
+CREATE TABLE IF NOT EXISTS entity_edges (
+    entity_id TEXT NOT NULL,
+    target_type TEXT NOT NULL,          -- item, hat, event
+    target_id TEXT NOT NULL,
+    relation TEXT NOT NULL,             -- sender, recipient, mentioned, attendee, active_in
+    alias TEXT NOT NULL DEFAULT '',     -- Alias that produced the link, so splits can move it
+    weight INTEGER NOT NULL DEFAULT 1,  -- Items behind a hat link
+    last_seen DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (entity_id, target_type, target_id, relation),
+    FOREIGN KEY (entity_id) REFERENCES entities(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_entity_edges_target ON entity_edges(target_type, target_id);
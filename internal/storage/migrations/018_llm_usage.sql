@@ -0,0 +1,12 @@
+-- LLM token usage and spend per provider per day, for budgets and reporting
+CREATE TABLE IF NOT EXISTS llm_usage (
+    day TEXT NOT NULL,                  -- YYYY-MM-DD, local time
+    provider TEXT NOT NULL,             -- claude, azure, ollama
+    requests INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (day, provider)
+);
@@ -0,0 +1,6 @@
+-- Migration 019: Per-hat data sensitivity
+-- Decides which LLM providers may see a hat's data. Empty falls back to the
+-- built-in default for the hat.
+ALTER TABLE hats ADD COLUMN sensitivity TEXT NOT NULL DEFAULT '';
+
+UPDATE hats SET sensitivity = 'confidential' WHERE id IN ('health', 'finance', 'partner');
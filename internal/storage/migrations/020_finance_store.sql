@@ -0,0 +1,122 @@
+-- Finance tables referenced identities(id), but the table is identity, so
+-- no row could ever be inserted. The affected tables are necessarily empty
+-- and are recreated with the right reference.
+
+DROP TABLE IF EXISTS bank_connections;
+CREATE TABLE bank_connections (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    item_id TEXT NOT NULL UNIQUE,
+    institution_id TEXT NOT NULL,
+    institution_name TEXT NOT NULL,
+    access_token_encrypted TEXT NOT NULL,  -- Base64 of the token encrypted with the identity key
+    status TEXT NOT NULL DEFAULT 'active', -- active, error, pending
+    sync_cursor TEXT,
+    last_sync TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_connections_user ON bank_connections(user_id);
+CREATE INDEX IF NOT EXISTS idx_bank_connections_status ON bank_connections(status);
+
+DROP TABLE IF EXISTS recurring_transactions;
+CREATE TABLE recurring_transactions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    merchant_name TEXT NOT NULL,
+    category TEXT NOT NULL,
+    average_amount REAL NOT NULL,
+    frequency TEXT NOT NULL,       -- weekly, biweekly, monthly, annual
+    day_of_month INTEGER,
+    next_expected TIMESTAMP,
+    last_seen TIMESTAMP,
+    is_active BOOLEAN DEFAULT TRUE,
+    transaction_ids TEXT,          -- JSON array
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_user ON recurring_transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_active ON recurring_transactions(is_active);
+CREATE INDEX IF NOT EXISTS idx_recurring_next ON recurring_transactions(next_expected);
+
+DROP TABLE IF EXISTS budgets;
+CREATE TABLE budgets (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    category TEXT NOT NULL,
+    amount REAL NOT NULL,
+    period TEXT NOT NULL DEFAULT 'monthly',  -- weekly, monthly, annual
+    start_date TEXT,
+    end_date TEXT,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, category, period)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_user ON budgets(user_id);
+CREATE INDEX IF NOT EXISTS idx_budgets_category ON budgets(category);
+
+DROP TABLE IF EXISTS financial_insights;
+CREATE TABLE financial_insights (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    type TEXT NOT NULL,            -- spending_summary, budget_alert, anomaly, etc.
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    severity TEXT DEFAULT 'info',  -- info, warning, alert
+    amount REAL,
+    category TEXT,
+    period TEXT,
+    trend TEXT,                    -- up, down, stable
+    data_json TEXT,                -- Additional structured data
+    dismissed BOOLEAN DEFAULT FALSE,
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_insights_user ON financial_insights(user_id);
+CREATE INDEX IF NOT EXISTS idx_insights_type ON financial_insights(type);
+CREATE INDEX IF NOT EXISTS idx_insights_dismissed ON financial_insights(dismissed);
+CREATE INDEX IF NOT EXISTS idx_insights_expires ON financial_insights(expires_at);
+
+DROP TABLE IF EXISTS financial_alerts;
+CREATE TABLE financial_alerts (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    severity TEXT DEFAULT 'info',
+    action_url TEXT,
+    dismissed BOOLEAN DEFAULT FALSE,
+    triggered_by TEXT,             -- Transaction ID that triggered
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_user ON financial_alerts(user_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_dismissed ON financial_alerts(dismissed);
+
+DROP TABLE IF EXISTS spending_summaries;
+CREATE TABLE spending_summaries (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    period TEXT NOT NULL,          -- YYYY-MM for monthly, YYYY-Wnn for weekly
+    total_spent REAL NOT NULL DEFAULT 0,
+    total_income REAL NOT NULL DEFAULT 0,
+    net_cash_flow REAL NOT NULL DEFAULT 0,
+    by_category_json TEXT,         -- JSON object
+    top_merchants_json TEXT,       -- JSON array
+    transaction_count INTEGER DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, period)
+);
+
+CREATE INDEX IF NOT EXISTS idx_summaries_user ON spending_summaries(user_id);
+CREATE INDEX IF NOT EXISTS idx_summaries_period ON spending_summaries(period);
+
+-- Currency of each transaction, as reported by the bank
+ALTER TABLE transactions ADD COLUMN iso_currency_code TEXT;
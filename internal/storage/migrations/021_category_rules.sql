@@ -0,0 +1,33 @@
+-- User categorization rules, applied before Plaid categories and keywords
+CREATE TABLE IF NOT EXISTS category_rules (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    merchant TEXT,                 -- Case-insensitive merchant or name
+    pattern TEXT,                  -- Regular expression over merchant and name
+    min_amount REAL,
+    max_amount REAL,
+    category TEXT NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    source TEXT NOT NULL DEFAULT 'user',  -- user, learned
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_rules_user ON category_rules(user_id);
+
+-- The latest category the user chose for each transaction, which rules
+-- are learned from
+CREATE TABLE IF NOT EXISTS category_corrections (
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    transaction_id TEXT NOT NULL,
+    merchant TEXT NOT NULL,        -- Lowercased merchant or name
+    category TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, transaction_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_corrections_merchant ON category_corrections(user_id, merchant);
+
+-- Which rule categorized a transaction, and whether the user chose it
+ALTER TABLE transactions ADD COLUMN rule_id TEXT;
+ALTER TABLE transactions ADD COLUMN user_categorized BOOLEAN DEFAULT FALSE;
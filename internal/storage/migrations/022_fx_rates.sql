@@ -0,0 +1,9 @@
+-- Cached exchange rates: one unit of base buys rate units of quote
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base TEXT NOT NULL,
+    quote TEXT NOT NULL,
+    rate TEXT NOT NULL,            -- Decimal string, never rounded through float
+    as_of TEXT,                    -- Day the provider quoted the rate for
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base, quote)
+);
@@ -0,0 +1,55 @@
+-- Envelope budgets, replacing the unused budgets table. Amounts are in
+-- minor units (cents) of currency.
+DROP TABLE IF EXISTS budgets;
+CREATE TABLE budgets (
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    category TEXT NOT NULL,
+    amount_minor INTEGER NOT NULL, -- Budgeted each period
+    currency TEXT NOT NULL,
+    rollover BOOLEAN NOT NULL DEFAULT FALSE,  -- Carry unspent and overspent amounts forward
+    starts_on TEXT NOT NULL,       -- Start of the first period tracked
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, category)
+);
+
+-- How budget periods repeat
+CREATE TABLE IF NOT EXISTS budget_settings (
+    user_id TEXT PRIMARY KEY REFERENCES identity(id),
+    period_kind TEXT NOT NULL DEFAULT 'monthly',  -- monthly, custom
+    start_day INTEGER,             -- Monthly: day of the month periods start on
+    anchor TEXT,                   -- Custom: a day a period starts on
+    days INTEGER,                  -- Custom: length of each period
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Budget against actual spending for each envelope and period
+CREATE TABLE IF NOT EXISTS budget_periods (
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    category TEXT NOT NULL,
+    period_start TEXT NOT NULL,
+    period_end TEXT NOT NULL,      -- Last day of the period
+    currency TEXT NOT NULL,
+    budgeted_minor INTEGER NOT NULL,
+    carried_minor INTEGER NOT NULL DEFAULT 0,
+    transferred_minor INTEGER NOT NULL DEFAULT 0,
+    spent_minor INTEGER NOT NULL DEFAULT 0,
+    available_minor INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, category, period_start)
+);
+
+-- Money moved between envelopes within a period
+CREATE TABLE IF NOT EXISTS budget_transfers (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    period_start TEXT NOT NULL,
+    from_category TEXT NOT NULL,
+    to_category TEXT NOT NULL,
+    amount_minor INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_transfers_user ON budget_transfers(user_id, period_start);
@@ -0,0 +1,15 @@
+-- One-off payments the user expects, included in cash-flow forecasts
+CREATE TABLE IF NOT EXISTS planned_expenses (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    name TEXT NOT NULL,
+    amount_minor INTEGER NOT NULL,  -- Positive is money out, negative money in
+    currency TEXT NOT NULL,
+    date TEXT NOT NULL,             -- YYYY-MM-DD
+    account_id TEXT,                -- Main checking account if empty
+    category TEXT,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_planned_expenses_user_date ON planned_expenses(user_id, date);
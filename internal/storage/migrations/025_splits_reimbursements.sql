@@ -0,0 +1,20 @@
+-- Parts of a transaction in other categories or hats, as a JSON array.
+-- Bank updates leave them alone.
+ALTER TABLE transactions ADD COLUMN splits TEXT;
+
+-- Expenses the user expects to be paid back, and the credit that did
+CREATE TABLE IF NOT EXISTS reimbursements (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES identity(id),
+    transaction_id TEXT NOT NULL,   -- The expense
+    amount_minor INTEGER NOT NULL,  -- Expected back, in the expense's currency
+    currency TEXT NOT NULL,
+    payer TEXT,                     -- Who pays it back, matched against credit names
+    note TEXT,
+    credit_id TEXT,                 -- The credit that paid it back, once received
+    received_on TEXT,               -- YYYY-MM-DD
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, transaction_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reimbursements_user ON reimbursements(user_id);
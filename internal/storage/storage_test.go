@@ -2,6 +2,8 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
+	"net/mail"
 	"testing"
 	"time"
 
@@ -371,6 +373,37 @@ func TestHatStore_GetActive(t *testing.T) {
 	}
 }
 
+func TestHatStore_Sensitivity(t *testing.T) {
+	db := testDB(t)
+	store := NewHatStore(db)
+
+	// Health, finance and partner are confidential out of the box
+	health, err := store.GetByID(core.HatHealth)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if health.EffectiveSensitivity() != core.SensitivityConfidential {
+		t.Errorf("health sensitivity = %v, want confidential", health.EffectiveSensitivity())
+	}
+
+	level, err := store.Sensitivity(core.HatProfessional, core.HatSocial, "")
+	if err != nil || level != core.SensitivityStandard {
+		t.Errorf("Sensitivity(professional, social) = %v, %v, want standard", level, err)
+	}
+	if level, _ := store.Sensitivity(core.HatProfessional, core.HatFinance); level != core.SensitivityConfidential {
+		t.Errorf("Sensitivity(professional, finance) = %v, want confidential", level)
+	}
+
+	// A hat can be made stricter, and the strictest hat wins
+	health.Sensitivity = core.SensitivityRestricted
+	if err := store.Update(health); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if level, _ := store.Sensitivity(core.HatFinance, core.HatHealth); level != core.SensitivityRestricted {
+		t.Errorf("Sensitivity(finance, health) = %v, want restricted", level)
+	}
+}
+
 // =============================================================================
 // ItemStore Tests
 // =============================================================================
@@ -486,6 +519,187 @@ func TestItemStore_Update(t *testing.T) {
 	}
 }
 
+func TestItemStore_UpsertByExternalID(t *testing.T) {
+	db := testDB(t)
+	store := NewItemStore(db)
+
+	item := &core.Item{
+		ID:         "note-1",
+		Type:       core.ItemTypeNote,
+		Status:     core.ItemStatusPending,
+		SpaceID:    "vault",
+		ExternalID: "projects/alpha.md",
+		HatID:      core.HatProfessional,
+		Subject:    "Alpha",
+		Body:       "first draft",
+	}
+	created, err := store.Upsert(item)
+	if err != nil || !created {
+		t.Fatalf("Upsert() = %v, %v; want created", created, err)
+	}
+
+	// Classification survives a content update
+	item.Summary = "kickoff notes"
+	store.Update(item)
+
+	edited := &core.Item{
+		ID:         "note-ignored",
+		Type:       core.ItemTypeNote,
+		Status:     core.ItemStatusPending,
+		SpaceID:    "vault",
+		ExternalID: "projects/alpha.md",
+		HatID:      core.HatProfessional,
+		Subject:    "Alpha (revised)",
+		Body:       "second draft",
+		Entities:   []string{"Bob"},
+	}
+	created, err = store.Upsert(edited)
+	if err != nil || created {
+		t.Fatalf("Upsert() = %v, %v; want update", created, err)
+	}
+	if edited.ID != "note-1" {
+		t.Errorf("ID = %s, want note-1", edited.ID)
+	}
+
+	retrieved, err := store.GetByExternalID("vault", "projects/alpha.md")
+	if err != nil {
+		t.Fatalf("GetByExternalID() error = %v", err)
+	}
+	if retrieved.Subject != "Alpha (revised)" || retrieved.Body != "second draft" {
+		t.Errorf("content not updated: %q / %q", retrieved.Subject, retrieved.Body)
+	}
+	if retrieved.Summary != "kickoff notes" || len(retrieved.Entities) != 1 {
+		t.Errorf("unexpected item: %+v", retrieved)
+	}
+	if count, _ := store.Count(); count != 1 {
+		t.Errorf("Count() = %d, want 1", count)
+	}
+
+	if _, err := store.GetByExternalID("other", "projects/alpha.md"); err != core.ErrItemNotFound {
+		t.Errorf("GetByExternalID(other space) error = %v, want ErrItemNotFound", err)
+	}
+
+	if err := store.DeleteByExternalID("vault", "projects/alpha.md"); err != nil {
+		t.Fatalf("DeleteByExternalID() error = %v", err)
+	}
+	if _, err := store.GetByID("note-1"); err != core.ErrItemNotFound {
+		t.Errorf("GetByID() after delete error = %v", err)
+	}
+}
+
+func TestItemStore_UpsertByStableID(t *testing.T) {
+	db := testDB(t)
+	store := NewItemStore(db)
+
+	item := &core.Item{
+		ID:         "imap_abc",
+		Type:       core.ItemTypeEmail,
+		Status:     core.ItemStatusPending,
+		SpaceID:    "mail",
+		ExternalID: "INBOX:1:42",
+		HatID:      core.HatPersonal,
+		Subject:    "Hello",
+	}
+	if created, err := store.Upsert(item); err != nil || !created {
+		t.Fatalf("Upsert() = %v, %v; want created", created, err)
+	}
+
+	// The mailbox was renumbered; the item keeps its ID and takes the new reference
+	renumbered := *item
+	renumbered.ExternalID = "INBOX:2:7"
+	if created, err := store.Upsert(&renumbered); err != nil || created {
+		t.Fatalf("Upsert(renumbered) = %v, %v; want update", created, err)
+	}
+	retrieved, err := store.GetByID("imap_abc")
+	if err != nil || retrieved.ExternalID != "INBOX:2:7" {
+		t.Errorf("GetByID() = %+v, %v; want external ID INBOX:2:7", retrieved, err)
+	}
+	if count, _ := store.Count(); count != 1 {
+		t.Errorf("Count() = %d, want 1", count)
+	}
+}
+
+func TestItemStore_SearchText(t *testing.T) {
+	db := testDB(t)
+	store := NewItemStore(db)
+
+	items := []*core.Item{
+		{ID: "inv", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatFinance, SpaceID: "mail", ExternalID: "msg-1",
+			Subject: "Invoice INV-2024-0117", Body: "Payment due in 30 days", From: "billing@acme.io"},
+		{ID: "lunch", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatPersonal,
+			Subject: "Lunch?", Body: "Are you free on Friday", From: "alice@example.com"},
+		{ID: "review", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatProfessional,
+			Subject: "Design review", Body: "Alice shared the invoice template", From: "bob@example.com"},
+	}
+	for _, item := range items {
+		if err := store.Create(item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		hatID core.HatID
+		want  []core.ItemID
+	}{
+		{"invoice number", "INV-2024-0117", "", []core.ItemID{"inv"}},
+		{"email address", "alice@example.com", "", []core.ItemID{"lunch"}},
+		{"subject outranks body", "invoice", "", []core.ItemID{"inv", "review"}},
+		{"hat filter", "invoice", core.HatProfessional, []core.ItemID{"review"}},
+		{"no match", "quarterly", "", nil},
+		{"nothing searchable", "a ? !", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.SearchText(tt.query, tt.hatID, 10)
+			if err != nil {
+				t.Fatalf("SearchText() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("SearchText() returned %d items, want %d", len(got), len(tt.want))
+			}
+			for i, item := range got {
+				if item.ID != tt.want[i] {
+					t.Errorf("result[%d] = %s, want %s", i, item.ID, tt.want[i])
+				}
+			}
+		})
+	}
+
+	// The index follows updates and deletes
+	items[1].Summary = "Lunch with the quarterly planning group"
+	store.Update(items[1])
+	if got, _ := store.SearchText("quarterly", "", 10); len(got) != 1 || got[0].ID != "lunch" {
+		t.Errorf("SearchText() after Update = %v", got)
+	}
+
+	store.DeleteByExternalID("mail", "msg-1")
+	var rows int
+	db.Conn().QueryRow("SELECT COUNT(*) FROM items_fts WHERE item_id = 'inv'").Scan(&rows)
+	if rows != 0 {
+		t.Errorf("items_fts has %d rows for deleted item", rows)
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"alice@example.com", `"alice@example.com"`},
+		{"invoice INV-42, please", `"invoice" OR "INV-42" OR "please"`},
+		{`say "hi"`, `"say" OR "hi"`},
+		{`it's a O"Brien`, `"it's" OR "O""Brien"`},
+		{"  ? a ", ""},
+	}
+	for _, tt := range tests {
+		if got := FTSQuery(tt.text); got != tt.want {
+			t.Errorf("FTSQuery(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
 func TestItemStore_GetByHat(t *testing.T) {
 	db := testDB(t)
 	store := NewItemStore(db)
@@ -654,6 +868,188 @@ func TestItemStore_CountByHat(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// EntityStore Tests
+// =============================================================================
+
+func TestEntityStore_LinkItem(t *testing.T) {
+	db := testDB(t)
+	items := NewItemStore(db)
+	store := NewEntityStore(db)
+	day := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	work := &core.Item{ID: "work", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatProfessional,
+		Subject: "Roadmap", From: "Alice Smith <Alice@Acme.io>", To: []string{"me@example.com"},
+		Entities: []string{"Acme", "Berlin", "Alice Smith"}, Timestamp: day}
+	home := &core.Item{ID: "home", Type: core.ItemTypeEmail, Status: core.ItemStatusPending, HatID: core.HatPersonal,
+		Subject: "Dinner", From: "alice@home.net", Timestamp: day.Add(24 * time.Hour)}
+	for _, item := range []*core.Item{work, home} {
+		if err := items.Create(item); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	kinds := map[string]core.EntityKind{"Acme": core.EntityKindOrganization, "Berlin": core.EntityKindPlace}
+	if err := store.LinkItem(work, kinds); err != nil {
+		t.Fatalf("LinkItem() error = %v", err)
+	}
+	if err := store.LinkItem(home, nil); err != nil {
+		t.Fatalf("LinkItem() error = %v", err)
+	}
+
+	// The sender and the mention of their name are one entity
+	alice, err := store.Resolve("alice@acme.io")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if alice.Kind != core.EntityKindPerson || alice.Name != "Alice Smith" || len(alice.Aliases) != 2 {
+		t.Errorf("unexpected entity: %+v", alice)
+	}
+	if byName, _ := store.Resolve("alice smith"); byName == nil || byName.ID != alice.ID {
+		t.Errorf("Resolve(name) = %+v, want %s", byName, alice.ID)
+	}
+	if acme, _ := store.Resolve("Acme"); acme == nil || acme.Kind != core.EntityKindOrganization {
+		t.Errorf("Resolve(Acme) = %+v", acme)
+	}
+	if _, err := store.Resolve("nobody@example.com"); err != core.ErrEntityNotFound {
+		t.Errorf("Resolve(unknown) error = %v, want ErrEntityNotFound", err)
+	}
+
+	// Relinking replaces the item's links
+	work.Entities = []string{"Acme"}
+	store.LinkItem(work, kinds)
+	berlin, _ := store.Resolve("Berlin")
+	if profile, _ := store.Profile(berlin.ID, 10); profile.ItemCount != 0 {
+		t.Errorf("stale link kept: %+v", profile)
+	}
+
+	// The home address is a separate entity until merged
+	other, _ := store.Resolve("alice@home.net")
+	if other.ID == alice.ID {
+		t.Fatal("addresses resolved to one entity before merging")
+	}
+
+	merged, err := store.Merge(alice.ID, other.ID)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(merged.Aliases) != 3 {
+		t.Errorf("aliases not moved: %+v", merged.Aliases)
+	}
+	if _, err := store.Get(other.ID); err != core.ErrEntityNotFound {
+		t.Errorf("merged entity still exists: %v", err)
+	}
+
+	profile, err := store.About("Alice Smith <alice@home.net>", 10)
+	if err != nil {
+		t.Fatalf("About() error = %v", err)
+	}
+	if profile.ItemCount != 2 || len(profile.Items) != 2 || profile.Items[0].ID != "home" {
+		t.Errorf("unexpected items: %d %+v", profile.ItemCount, profile.Items)
+	}
+	if len(profile.Hats) != 2 {
+		t.Errorf("Hats = %+v, want professional and personal", profile.Hats)
+	}
+
+	// Split the home address back off, with the item it brought
+	split, err := store.Split(alice.ID, []string{"alice@home.net"}, "Alice Jones")
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	profile, _ = store.Profile(split.ID, 10)
+	if split.Name != "Alice Jones" || profile.ItemCount != 1 || profile.Items[0].ID != "home" {
+		t.Errorf("unexpected split profile: %+v %+v", split, profile)
+	}
+	if len(profile.Hats) != 1 || profile.Hats[0].HatID != core.HatPersonal {
+		t.Errorf("split hats = %+v", profile.Hats)
+	}
+	profile, _ = store.Profile(alice.ID, 10)
+	if profile.ItemCount != 1 || len(profile.Hats) != 1 || profile.Hats[0].HatID != core.HatProfessional {
+		t.Errorf("unexpected remaining profile: %+v", profile)
+	}
+
+	if _, err := store.Split(alice.ID, []string{"bob@example.com"}, ""); !errors.Is(err, core.ErrInvalidInput) {
+		t.Errorf("Split(unknown alias) error = %v, want ErrInvalidInput", err)
+	}
+	if _, err := store.Split(split.ID, []string{"alice@home.net"}, ""); !errors.Is(err, core.ErrInvalidInput) {
+		t.Errorf("Split(every alias) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestEntityStore_LinkEvent(t *testing.T) {
+	db := testDB(t)
+	store := NewEntityStore(db)
+	start := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+
+	attendees := []*mail.Address{{Name: "Bob", Address: "bob@example.com"}, {Address: "carol@example.com"}}
+	if err := store.LinkEvent("standup", start, attendees); err != nil {
+		t.Fatalf("LinkEvent() error = %v", err)
+	}
+	store.LinkEvent("retro", start.Add(48*time.Hour), attendees[:1])
+
+	profile, err := store.About("bob@example.com", 10)
+	if err != nil {
+		t.Fatalf("About() error = %v", err)
+	}
+	if len(profile.Events) != 2 || profile.Events[0] != "retro" {
+		t.Errorf("Events = %v, want [retro standup]", profile.Events)
+	}
+
+	found, err := store.Search("CAROL", 10)
+	if err != nil || len(found) != 1 || found[0].Name != "carol@example.com" {
+		t.Errorf("Search() = %+v, %v", found, err)
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		in, name, email string
+	}{
+		{"Alice Smith <Alice@Example.com>", "Alice Smith", "alice@example.com"},
+		{"bob@example.com", "", "bob@example.com"},
+		{"Acme Corp", "Acme Corp", ""},
+	}
+	for _, tt := range tests {
+		name, email := ParseAddress(tt.in)
+		if name != tt.name || email != tt.email {
+			t.Errorf("ParseAddress(%q) = %q, %q, want %q, %q", tt.in, name, email, tt.name, tt.email)
+		}
+	}
+}
+
+// =============================================================================
+// LLMUsageStore Tests
+// =============================================================================
+
+func TestLLMUsageStore(t *testing.T) {
+	db := testDB(t)
+	store := NewLLMUsageStore(db)
+
+	store.Add("2026-09-30", "claude", 100, 50, 1.5, false)
+	store.Add("2026-10-01", "claude", 200, 100, 2, false)
+	store.Add("2026-10-01", "claude", 0, 0, 0, true)
+	store.Add("2026-10-02", "ollama", 300, 30, 0, false)
+
+	spent, err := store.SpendSince("2026-10-01")
+	if err != nil {
+		t.Fatalf("SpendSince() error = %v", err)
+	}
+	if spent != 2 {
+		t.Errorf("SpendSince() = %v, want 2", spent)
+	}
+
+	usage, err := store.List("2026-10-01")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("List() returned %d rows, want 2", len(usage))
+	}
+	if u := usage[0]; u.Provider != "claude" || u.Requests != 2 || u.Failures != 1 || u.InputTokens != 200 {
+		t.Errorf("List()[0] = %+v", u)
+	}
+}
+
 // =============================================================================
 // SpaceStore Tests
 // =============================================================================
@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// VectorCollection records which physical collection serves a logical one and
+// the embedding model that produced its vectors
+type VectorCollection struct {
+	Name         string
+	PhysicalName string
+	Model        string
+	Dimension    uint64
+	UpdatedAt    time.Time
+}
+
+// VectorReindex tracks a resumable rebuild of a collection into a shadow
+type VectorReindex struct {
+	Name       string
+	ShadowName string
+	Model      string
+	Dimension  uint64
+	Cursor     int64 // rowid of the last source row embedded
+	Done       bool
+	StartedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// VectorCollectionStore manages vector collection metadata
+type VectorCollectionStore struct {
+	db *DB
+}
+
+// NewVectorCollectionStore creates a new vector collection store
+func NewVectorCollectionStore(db *DB) *VectorCollectionStore {
+	return &VectorCollectionStore{db: db}
+}
+
+// Get returns the record for a logical collection, or nil if none exists
+func (s *VectorCollectionStore) Get(name string) (*VectorCollection, error) {
+	c := &VectorCollection{}
+	err := s.db.conn.QueryRow(`
+		SELECT name, physical_name, model, dimension, updated_at
+		FROM vector_collections WHERE name = ?
+	`, name).Scan(&c.Name, &c.PhysicalName, &c.Model, &c.Dimension, &c.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save creates or replaces the record for a logical collection
+func (s *VectorCollectionStore) Save(c *VectorCollection) error {
+	c.UpdatedAt = time.Now().UTC()
+	_, err := s.db.conn.Exec(`
+		INSERT INTO vector_collections (name, physical_name, model, dimension, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+		    physical_name = excluded.physical_name,
+		    model = excluded.model,
+		    dimension = excluded.dimension,
+		    updated_at = excluded.updated_at
+	`, c.Name, c.PhysicalName, c.Model, c.Dimension, c.UpdatedAt)
+	return err
+}
+
+// GetReindex returns the in-flight reindex of a collection, or nil
+func (s *VectorCollectionStore) GetReindex(name string) (*VectorReindex, error) {
+	r := &VectorReindex{}
+	err := s.db.conn.QueryRow(`
+		SELECT name, shadow_name, model, dimension, cursor, done, started_at, updated_at
+		FROM vector_reindex WHERE name = ?
+	`, name).Scan(&r.Name, &r.ShadowName, &r.Model, &r.Dimension, &r.Cursor, &r.Done, &r.StartedAt, &r.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SaveReindex creates or updates reindex progress
+func (s *VectorCollectionStore) SaveReindex(r *VectorReindex) error {
+	r.UpdatedAt = time.Now().UTC()
+	if r.StartedAt.IsZero() {
+		r.StartedAt = r.UpdatedAt
+	}
+	_, err := s.db.conn.Exec(`
+		INSERT INTO vector_reindex (name, shadow_name, model, dimension, cursor, done, started_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+		    shadow_name = excluded.shadow_name,
+		    model = excluded.model,
+		    dimension = excluded.dimension,
+		    cursor = excluded.cursor,
+		    done = excluded.done,
+		    updated_at = excluded.updated_at
+	`, r.Name, r.ShadowName, r.Model, r.Dimension, r.Cursor, r.Done, r.StartedAt, r.UpdatedAt)
+	return err
+}
+
+// DeleteReindex discards reindex progress
+func (s *VectorCollectionStore) DeleteReindex(name string) error {
+	_, err := s.db.conn.Exec("DELETE FROM vector_reindex WHERE name = ?", name)
+	return err
+}
+
+// Swap points every named collection at its finished shadow in a single
+// transaction and clears the progress. It returns the physical collections
+// that were replaced, which the caller can drop.
+func (s *VectorCollectionStore) Swap(names []string) ([]string, error) {
+	var replaced []string
+	err := s.db.Transaction(func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		for _, name := range names {
+			var shadow, model string
+			var dimension uint64
+			var done bool
+			err := tx.QueryRow(`
+				SELECT shadow_name, model, dimension, done FROM vector_reindex WHERE name = ?
+			`, name).Scan(&shadow, &model, &dimension, &done)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if !done {
+				return fmt.Errorf("reindex of %s has not finished", name)
+			}
+
+			// Collections predating this table are served under their logical name
+			old := name
+			err = tx.QueryRow("SELECT physical_name FROM vector_collections WHERE name = ?", name).Scan(&old)
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if old != shadow {
+				replaced = append(replaced, old)
+			}
+
+			_, err = tx.Exec(`
+				INSERT INTO vector_collections (name, physical_name, model, dimension, updated_at)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT(name) DO UPDATE SET
+				    physical_name = excluded.physical_name,
+				    model = excluded.model,
+				    dimension = excluded.dimension,
+				    updated_at = excluded.updated_at
+			`, name, shadow, model, dimension, now)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM vector_reindex WHERE name = ?", name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
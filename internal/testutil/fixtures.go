@@ -234,6 +234,97 @@ func DefaultAccountFixture() AccountFixture {
 	}
 }
 
+// TriageFixture is an email labelled with how it should be triaged.
+type TriageFixture struct {
+	Email    EmailFixture
+	HatID    string   // Expected hat
+	Priority int      // Expected priority, 1 (low) to 4 (critical)
+	Actions  []string // Actions triage is expected to suggest
+}
+
+// TriageFixtures returns labelled emails covering the common hats, used as
+// a golden set for triage evaluation.
+func TriageFixtures() []TriageFixture {
+	email := func(from, subject, body string) EmailFixture {
+		return NewEmailBuilder().WithFrom(from).WithSubject(subject).WithBody(body).Build()
+	}
+
+	return []TriageFixture{
+		{
+			Email:    email("manager@acme-corp.com", "Q3 roadmap review moved to Thursday", "Hi, the roadmap review is now Thursday 10am. Please bring the updated project estimates and confirm you can make it."),
+			HatID:    "professional",
+			Priority: 3,
+			Actions:  []string{"reply", "schedule"},
+		},
+		{
+			Email:    email("alerts@github.com", "[acme/api] CI failed on main", "The build for commit 4f2a1c failed: 3 tests failing in the payments service. Deploys are blocked until main is green."),
+			HatID:    "professional",
+			Priority: 4,
+			Actions:  []string{"flag"},
+		},
+		{
+			Email:    email("statements@firstbank.com", "Your October statement is ready", "Your monthly statement for the account ending 4821 is now available to view in online banking."),
+			HatID:    "finance",
+			Priority: 1,
+			Actions:  []string{"archive"},
+		},
+		{
+			Email:    email("billing@cityenergy.com", "Invoice overdue: payment required", "Your electricity invoice of $182.40 was due on 1 October. Please pay within 7 days to avoid a late fee."),
+			HatID:    "finance",
+			Priority: 3,
+			Actions:  []string{"remind"},
+		},
+		{
+			Email:    email("appointments@riversideclinic.org", "Appointment reminder: Dr. Patel", "This is a reminder of your appointment with Dr. Patel on Monday at 9:15am. Reply C to confirm or call us to reschedule."),
+			HatID:    "health",
+			Priority: 3,
+			Actions:  []string{"reply", "schedule"},
+		},
+		{
+			Email:    email("pharmacy@healthplus.com", "Prescription ready for pickup", "Your prescription is ready for collection at the Main Street pharmacy. It will be held for 10 days."),
+			HatID:    "health",
+			Priority: 2,
+			Actions:  []string{"remind"},
+		},
+		{
+			Email:    email("office@oakwood-primary.sch.uk", "Parents evening sign-up", "Parents evening for Year 3 is on the 14th. Please choose a slot for your child's meeting with their teacher."),
+			HatID:    "parent",
+			Priority: 2,
+			Actions:  []string{"schedule"},
+		},
+		{
+			Email:    email("noreply@coursera.org", "Week 3 of Machine Learning is open", "New lectures and the week 3 quiz are now available. The quiz is due next Sunday."),
+			HatID:    "learner",
+			Priority: 2,
+			Actions:  []string{"remind"},
+		},
+		{
+			Email:    email("support@propertymgmt.com", "Boiler service visit", "Our engineer will service the boiler at your home next Wednesday between 8am and noon. Please make sure someone is in."),
+			HatID:    "home",
+			Priority: 2,
+			Actions:  []string{"schedule"},
+		},
+		{
+			Email:    email("events@runclub.org", "Saturday park run and brunch", "Join us for the 5k park run on Saturday followed by brunch at the cafe. Everyone is welcome!"),
+			HatID:    "social",
+			Priority: 1,
+			Actions:  []string{"schedule"},
+		},
+		{
+			Email:    email("deals@shopnow.com", "48 hour flash sale - 60% off", "Don't miss out on our biggest sale of the year. Shop now before it's gone!"),
+			HatID:    "personal",
+			Priority: 1,
+			Actions:  []string{"archive"},
+		},
+		{
+			Email:    email("elections@citycouncil.gov", "Your polling card", "The local election is on 7 May. Your polling station is Oakwood Community Hall, open 7am to 10pm."),
+			HatID:    "citizen",
+			Priority: 2,
+			Actions:  []string{"remind"},
+		},
+	}
+}
+
 // EmailFixtureBuilder builds email fixtures with a fluent interface.
 type EmailFixtureBuilder struct {
 	fixture EmailFixture
@@ -0,0 +1,353 @@
+package mockservers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Well-known paths served by DAVMockServer
+const (
+	DAVPrincipal       = "/principals/user/"
+	DAVCalendarHome    = "/calendars/user/"
+	DAVDefaultCalendar = "/calendars/user/personal/"
+	DAVTaskList        = "/calendars/user/tasks/"
+	DAVAddressBookHome = "/addressbooks/user/"
+	DAVAddressBook     = "/addressbooks/user/contacts/"
+)
+
+// DAVMockServer is an in-memory CalDAV/CardDAV server for testing. It
+// supports discovery, REPORT (returning every object in the collection),
+// and GET/PUT/DELETE with ETag preconditions.
+type DAVMockServer struct {
+	Server   *httptest.Server
+	Username string
+	Password string
+
+	collections map[string]*davCollection
+	requests    []string
+	mu          sync.Mutex
+	t           *testing.T
+}
+
+type davCollection struct {
+	name       string
+	kind       string // calendar, addressbook
+	components []string
+	objects    map[string]*davMockObject
+}
+
+type davMockObject struct {
+	data string
+	etag string
+}
+
+// NewDAVMockServer creates a new mock CalDAV/CardDAV server.
+func NewDAVMockServer(t *testing.T) *DAVMockServer {
+	t.Helper()
+
+	mock := &DAVMockServer{
+		Username:    "user",
+		Password:    "app-password",
+		collections: make(map[string]*davCollection),
+		t:           t,
+	}
+
+	mock.SetupDefaults()
+
+	mock.Server = httptest.NewServer(http.HandlerFunc(mock.handle))
+
+	t.Cleanup(func() {
+		mock.Server.Close()
+	})
+
+	return mock
+}
+
+// SetupDefaults creates a personal calendar, a task list and an address book,
+// each with one sample object.
+func (m *DAVMockServer) SetupDefaults() {
+	m.AddCollection(DAVDefaultCalendar, "Personal", "calendar", "VEVENT")
+	m.AddCollection(DAVTaskList, "Tasks", "calendar", "VTODO")
+	m.AddCollection(DAVAddressBook, "Contacts", "addressbook")
+
+	m.PutObject(DAVDefaultCalendar+"standup.ics", "BEGIN:VCALENDAR\r\n"+
+		"VERSION:2.0\r\n"+
+		"PRODID:-//Mock//EN\r\n"+
+		"BEGIN:VEVENT\r\n"+
+		"UID:standup-001\r\n"+
+		"DTSTAMP:20240110T090000Z\r\n"+
+		"DTSTART:20240115T100000Z\r\n"+
+		"DTEND:20240115T103000Z\r\n"+
+		"SUMMARY:Team Standup\r\n"+
+		"LOCATION:Room 4\r\n"+
+		"X-MOCK-CUSTOM:keep me\r\n"+
+		"ATTENDEE;CN=Alice Example;PARTSTAT=ACCEPTED:mailto:alice@example.com\r\n"+
+		"BEGIN:VALARM\r\n"+
+		"ACTION:DISPLAY\r\n"+
+		"DESCRIPTION:Team Standup\r\n"+
+		"TRIGGER:-PT10M\r\n"+
+		"END:VALARM\r\n"+
+		"END:VEVENT\r\n"+
+		"END:VCALENDAR\r\n")
+
+	m.PutObject(DAVAddressBook+"alice.vcf", "BEGIN:VCARD\r\n"+
+		"VERSION:3.0\r\n"+
+		"UID:alice-001\r\n"+
+		"FN:Alice Example\r\n"+
+		"N:Example;Alice;;;\r\n"+
+		"EMAIL;TYPE=INTERNET,WORK:alice@example.com\r\n"+
+		"TEL;TYPE=CELL:+1 555 0100\r\n"+
+		"ORG:Example Corp\r\n"+
+		"PHOTO;VALUE=URI:https://example.com/alice.jpg\r\n"+
+		"END:VCARD\r\n")
+}
+
+// AddCollection creates an empty calendar or address book collection.
+func (m *DAVMockServer) AddCollection(href, name, kind string, components ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.collections[href] = &davCollection{
+		name:       name,
+		kind:       kind,
+		components: components,
+		objects:    make(map[string]*davMockObject),
+	}
+}
+
+// PutObject stores an object resource, replacing any existing one.
+func (m *DAVMockServer) PutObject(href, data string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	col := m.collectionFor(href)
+	if col == nil {
+		m.t.Fatalf("no collection for %s", href)
+	}
+	col.objects[href] = &davMockObject{data: data, etag: etagFor(data)}
+}
+
+// Object returns the stored data of an object resource.
+func (m *DAVMockServer) Object(href string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if col := m.collectionFor(href); col != nil {
+		if obj, ok := col.objects[href]; ok {
+			return obj.data, true
+		}
+	}
+	return "", false
+}
+
+// ObjectCount returns the number of objects in a collection.
+func (m *DAVMockServer) ObjectCount(collection string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if col, ok := m.collections[collection]; ok {
+		return len(col.objects)
+	}
+	return 0
+}
+
+// Requests returns the "METHOD path" of every request received.
+func (m *DAVMockServer) Requests() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.requests...)
+}
+
+func (m *DAVMockServer) collectionFor(href string) *davCollection {
+	idx := strings.LastIndex(strings.TrimSuffix(href, "/"), "/")
+	if idx < 0 {
+		return nil
+	}
+	return m.collections[href[:idx+1]]
+}
+
+func etagFor(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+func (m *DAVMockServer) handle(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests = append(m.requests, r.Method+" "+r.URL.Path)
+
+	if user, pass, ok := r.BasicAuth(); !ok || user != m.Username || pass != m.Password {
+		w.Header().Set("WWW-Authenticate", `Basic realm="mock"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := r.URL.Path
+	if strings.HasPrefix(path, "/.well-known/") {
+		http.Redirect(w, r, "/", http.StatusMovedPermanently)
+		return
+	}
+
+	switch r.Method {
+	case "PROPFIND":
+		m.handlePropfind(w, r)
+	case "REPORT":
+		m.handleReport(w, r)
+	case http.MethodGet:
+		col := m.collectionFor(path)
+		if col == nil || col.objects[path] == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		obj := col.objects[path]
+		w.Header().Set("ETag", obj.etag)
+		io.WriteString(w, obj.data)
+	case http.MethodPut:
+		col := m.collectionFor(path)
+		if col == nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		existing := col.objects[path]
+		if r.Header.Get("If-None-Match") == "*" && existing != nil {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (existing == nil || existing.etag != match) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		obj := &davMockObject{data: string(body), etag: etagFor(string(body))}
+		col.objects[path] = obj
+		w.Header().Set("ETag", obj.etag)
+		if existing == nil {
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case http.MethodDelete:
+		col := m.collectionFor(path)
+		if col == nil || col.objects[path] == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && col.objects[path].etag != match {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		delete(col.objects, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (m *DAVMockServer) handlePropfind(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	var responses []string
+
+	switch path {
+	case "/", DAVPrincipal:
+		responses = append(responses, davPropResponse(path,
+			`<D:current-user-principal><D:href>`+DAVPrincipal+`</D:href></D:current-user-principal>`+
+				`<C:calendar-home-set><D:href>`+DAVCalendarHome+`</D:href></C:calendar-home-set>`+
+				`<CR:addressbook-home-set><D:href>`+DAVAddressBookHome+`</D:href></CR:addressbook-home-set>`))
+	case DAVCalendarHome, DAVAddressBookHome:
+		responses = append(responses, davPropResponse(path, `<D:resourcetype><D:collection/></D:resourcetype>`))
+		if r.Header.Get("Depth") == "1" {
+			hrefs := make([]string, 0, len(m.collections))
+			for href := range m.collections {
+				if strings.HasPrefix(href, path) {
+					hrefs = append(hrefs, href)
+				}
+			}
+			sort.Strings(hrefs)
+			for _, href := range hrefs {
+				responses = append(responses, davPropResponse(href, m.collections[href].props()))
+			}
+		}
+	default:
+		col, ok := m.collections[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		responses = append(responses, davPropResponse(path, col.props()))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+func (m *DAVMockServer) handleReport(w http.ResponseWriter, r *http.Request) {
+	col, ok := m.collections[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	hrefs := make([]string, 0, len(col.objects))
+	for href := range col.objects {
+		hrefs = append(hrefs, href)
+	}
+	sort.Strings(hrefs)
+
+	dataProp := "C:calendar-data"
+	if col.kind == "addressbook" {
+		dataProp = "CR:address-data"
+	}
+
+	responses := make([]string, 0, len(hrefs))
+	for _, href := range hrefs {
+		obj := col.objects[href]
+		responses = append(responses, davPropResponse(href,
+			`<D:getetag>`+html.EscapeString(obj.etag)+`</D:getetag>`+
+				`<`+dataProp+`>`+html.EscapeString(obj.data)+`</`+dataProp+`>`))
+	}
+
+	writeMultistatus(w, responses)
+}
+
+func (c *davCollection) props() string {
+	var b strings.Builder
+	b.WriteString(`<D:displayname>` + html.EscapeString(c.name) + `</D:displayname>`)
+	if c.kind == "addressbook" {
+		b.WriteString(`<D:resourcetype><D:collection/><CR:addressbook/></D:resourcetype>`)
+		return b.String()
+	}
+
+	b.WriteString(`<D:resourcetype><D:collection/><C:calendar/></D:resourcetype>`)
+	b.WriteString(`<C:supported-calendar-component-set>`)
+	for _, comp := range c.components {
+		fmt.Fprintf(&b, `<C:comp name="%s"/>`, comp)
+	}
+	b.WriteString(`</C:supported-calendar-component-set>`)
+	return b.String()
+}
+
+func davPropResponse(href, props string) string {
+	return `<D:response><D:href>` + href + `</D:href>` +
+		`<D:propstat><D:prop>` + props + `</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>` +
+		`</D:response>`
+}
+
+func writeMultistatus(w http.ResponseWriter, responses []string) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, `<?xml version="1.0" encoding="utf-8"?>`+
+		`<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav" xmlns:CR="urn:ietf:params:xml:ns:carddav">`)
+	for _, resp := range responses {
+		io.WriteString(w, resp)
+	}
+	io.WriteString(w, `</D:multistatus>`)
+}
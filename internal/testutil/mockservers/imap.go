@@ -0,0 +1,136 @@
+package mockservers
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+)
+
+// IMAPMockServer is an in-memory IMAP server for testing. It supports
+// SELECT/SEARCH/FETCH/STORE/COPY/EXPUNGE and IDLE (without push updates).
+type IMAPMockServer struct {
+	Server   *server.Server
+	Host     string
+	Port     int
+	Username string
+	Password string
+
+	user backend.User
+	t    *testing.T
+}
+
+// NewIMAPMockServer creates a new mock IMAP server listening on localhost.
+func NewIMAPMockServer(t *testing.T) *IMAPMockServer {
+	t.Helper()
+
+	be := memory.New()
+	mock := &IMAPMockServer{
+		Username: "username",
+		Password: "password",
+		t:        t,
+	}
+
+	user, err := be.Login(nil, mock.Username, mock.Password)
+	if err != nil {
+		t.Fatalf("imap mock login: %v", err)
+	}
+	mock.user = user
+
+	mock.SetupDefaults()
+
+	mock.Server = server.New(be)
+	mock.Server.AllowInsecureAuth = true
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("imap mock listen: %v", err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	mock.Host = addr.IP.String()
+	mock.Port = addr.Port
+
+	go mock.Server.Serve(listener)
+
+	t.Cleanup(func() {
+		mock.Server.Close()
+	})
+
+	return mock
+}
+
+// Addr returns the host:port the server listens on.
+func (m *IMAPMockServer) Addr() string {
+	return net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+}
+
+// SetupDefaults replaces the backend's sample data with an INBOX holding an
+// unread plain-text message and a read HTML message.
+func (m *IMAPMockServer) SetupDefaults() {
+	m.Mailbox("INBOX").Messages = nil
+
+	m.AddMessage("INBOX", time.Now().Add(-2*time.Hour), nil, "From: Alice Example <alice@example.com>\r\n"+
+		"To: user@example.com\r\n"+
+		"Subject: Quarterly report\r\n"+
+		"Date: "+time.Now().Add(-2*time.Hour).Format(time.RFC1123Z)+"\r\n"+
+		"Message-ID: <report-001@example.com>\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n"+
+		"\r\n"+
+		"Please review the attached numbers before Friday.\r\n")
+
+	m.AddMessage("INBOX", time.Now().Add(-time.Hour), []string{"\\Seen"}, "From: bob@example.org\r\n"+
+		"To: user@example.com\r\n"+
+		"Subject: Lunch?\r\n"+
+		"Date: "+time.Now().Add(-time.Hour).Format(time.RFC1123Z)+"\r\n"+
+		"Message-ID: <lunch-002@example.org>\r\n"+
+		"Content-Type: text/html; charset=utf-8\r\n"+
+		"\r\n"+
+		"<p>Tacos at <b>noon</b>?</p>\r\n")
+}
+
+// Mailbox returns a mailbox, creating it if needed.
+func (m *IMAPMockServer) Mailbox(name string) *memory.Mailbox {
+	mbox, err := m.user.GetMailbox(name)
+	if err != nil {
+		if err := m.user.CreateMailbox(name); err != nil {
+			m.t.Fatalf("imap mock create mailbox %s: %v", name, err)
+		}
+		mbox, _ = m.user.GetMailbox(name)
+	}
+	return mbox.(*memory.Mailbox)
+}
+
+// AddMessage appends a raw RFC 5322 message to a mailbox and returns its UID.
+func (m *IMAPMockServer) AddMessage(mailbox string, date time.Time, flags []string, raw string) uint32 {
+	mbox := m.Mailbox(mailbox)
+
+	var uid uint32 = 1
+	for _, msg := range mbox.Messages {
+		if msg.Uid >= uid {
+			uid = msg.Uid + 1
+		}
+	}
+
+	mbox.Messages = append(mbox.Messages, &memory.Message{
+		Uid:   uid,
+		Date:  date,
+		Size:  uint32(len(raw)),
+		Flags: append([]string(nil), flags...),
+		Body:  []byte(raw),
+	})
+	return uid
+}
+
+// Message returns a message by UID, or nil.
+func (m *IMAPMockServer) Message(mailbox string, uid uint32) *memory.Message {
+	for _, msg := range m.Mailbox(mailbox).Messages {
+		if msg.Uid == uid {
+			return msg
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ package triage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/quantumlife/quantumlife/internal/core"
 	"github.com/quantumlife/quantumlife/internal/llm"
 	"github.com/quantumlife/quantumlife/internal/memory"
+	"github.com/quantumlife/quantumlife/internal/storage"
 )
 
 // RetrievedMemory wraps a memory with relevance score
@@ -23,6 +25,8 @@ type RetrievedMemory struct {
 type Engine struct {
 	router        *llm.Router
 	memoryManager *memory.Manager
+	entities      *storage.EntityStore
+	hats          *storage.HatStore
 	config        EngineConfig
 }
 
@@ -62,12 +66,24 @@ func NewEngine(router *llm.Router, mm *memory.Manager, cfg EngineConfig) *Engine
 	}
 }
 
+// SetEntityStore lets triage look senders up in the entity graph
+func (e *Engine) SetEntityStore(entities *storage.EntityStore) {
+	e.entities = entities
+}
+
+// SetHatStore uses the sensitivity configured per hat when deciding where
+// triage prompts may be sent. Without it the built-in defaults apply.
+func (e *Engine) SetHatStore(hats *storage.HatStore) {
+	e.hats = hats
+}
+
 // TriageResult contains the triage decision
 type TriageResult struct {
 	// Primary decision
 	HatID        core.HatID `json:"hat_id"`
 	Confidence   float64    `json:"confidence"`
 	Reasoning    string     `json:"reasoning"`
+	Fallback     bool       `json:"fallback,omitempty"` // Keyword classification, the model gave no usable answer
 
 	// Priority assessment
 	Priority     Priority   `json:"priority"`
@@ -128,12 +144,46 @@ const (
 	ActionDraft      ActionType = "draft"
 )
 
+// actionTypes lists the actions triage may suggest
+var actionTypes = []ActionType{
+	ActionReply, ActionArchive, ActionDelegate, ActionSchedule,
+	ActionRemind, ActionLabel, ActionFlag, ActionDraft,
+}
+
+// triageSchema is the structure of a triage response
+var triageSchema = func() *llm.Schema {
+	actions := make([]string, 0, len(actionTypes))
+	for _, a := range actionTypes {
+		actions = append(actions, string(a))
+	}
+	schema := llm.ObjectSchema(map[string]*llm.Schema{
+		"hat_id": llm.StringSchema(
+			string(core.HatPersonal), string(core.HatProfessional), string(core.HatFinance),
+			string(core.HatHealth), string(core.HatPartner), string(core.HatSocial),
+			string(core.HatLearner), string(core.HatCreative), string(core.HatHome),
+			string(core.HatSpiritual), string(core.HatCitizen), string(core.HatParent),
+		),
+		"confidence": llm.NumberSchema(0, 1),
+		"priority":   llm.IntegerSchema(1, 4),
+		"urgency":    llm.IntegerSchema(0, 4),
+		"reasoning":  llm.StringSchema(),
+		"actions": llm.ArraySchema(llm.ObjectSchema(map[string]*llm.Schema{
+			"type":        llm.StringSchema(actions...),
+			"description": llm.StringSchema(),
+			"confidence":  llm.NumberSchema(0, 1),
+		}, "type", "description")),
+	}, "hat_id", "confidence", "priority", "urgency", "reasoning")
+	schema.Title = "triage"
+	return schema
+}()
+
 // ContextItem represents RAG context
 type ContextItem struct {
 	Type       string  `json:"type"`
 	Content    string  `json:"content"`
 	Relevance  float64 `json:"relevance"`
 	Source     string  `json:"source"`
+	HatID      core.HatID `json:"hat_id,omitempty"` // Hat the context belongs to, if known
 }
 
 // Triage analyzes an item and returns a triage decision
@@ -160,17 +210,17 @@ func (e *Engine) Triage(ctx context.Context, item *core.Item) (*TriageResult, er
 6. Suggested actions
 
 Available hats:
-- personal: Personal matters, family, friends
+- personal: Personal matters, family, friends, travel
 - professional: Work-related items
-- financial: Banking, investments, bills
+- finance: Banking, investments, bills
 - health: Medical, wellness, fitness
+- partner: Relationship, shared plans with your partner
 - social: Social events, community
-- learning: Education, courses, books
+- learner: Education, courses, books
 - creative: Art, writing, music projects
-- travel: Trips, bookings, planning
 - home: Household, maintenance, utilities
 - spiritual: Faith, meditation, philosophy
-- civic: Politics, volunteering, causes
+- citizen: Politics, volunteering, causes
 - parent: Children, parenting, school
 
 Respond in JSON format:
@@ -186,16 +236,20 @@ Respond in JSON format:
   ]
 }`
 
-	response, err := e.router.Route(ctx, llm.RouteRequest{
-		System: system,
-		Prompt: prompt,
-	})
-	if err != nil {
+	response, err := e.router.RouteJSON(ctx, llm.RouteRequest{
+		System:      system,
+		Prompt:      prompt,
+		Sensitivity: e.sensitivity(item, ragContext),
+	}, triageSchema)
+	if err != nil && !errors.Is(err, llm.ErrInvalidOutput) {
 		return nil, fmt.Errorf("triage AI call failed: %w", err)
 	}
 
-	// Parse response
-	result, err := e.parseTriageResponse(response.Content)
+	// Parse response; output that never matched the schema is not trusted
+	var result *TriageResult
+	if err == nil {
+		result, err = e.parseTriageResponse(response.Content)
+	}
 	if err != nil {
 		// Fallback to basic classification
 		result = e.fallbackTriage(item)
@@ -213,21 +267,75 @@ Respond in JSON format:
 	return result, nil
 }
 
+// sensitivity returns the strictest sensitivity of the hats whose data goes
+// into the triage prompt: the item's current hat and that of any context
+func (e *Engine) sensitivity(item *core.Item, ragContext []ContextItem) core.Sensitivity {
+	ids := []core.HatID{item.HatID}
+	for _, c := range ragContext {
+		ids = append(ids, c.HatID)
+	}
+
+	// Items that were never classified only carry their space's default hat
+	floor := core.SensitivityStandard
+	if item.Confidence == 0 {
+		floor = core.UnclassifiedSensitivity
+	}
+
+	if e.hats != nil {
+		level, err := e.hats.Sensitivity(ids...)
+		if err == nil {
+			return level.Max(floor)
+		}
+	}
+	// Fall back to the defaults rather than treating the data as standard
+	level := floor
+	for _, id := range ids {
+		if id != "" {
+			level = level.Max(core.DefaultSensitivity(id))
+		}
+	}
+	return level
+}
+
 // buildRAGContext retrieves relevant context using Adaptive RAG
 func (e *Engine) buildRAGContext(ctx context.Context, item *core.Item) ([]ContextItem, error) {
-	if !e.config.EnableRAG || e.memoryManager == nil {
+	if !e.config.EnableRAG || (e.memoryManager == nil && e.entities == nil) {
 		return nil, nil
 	}
 
+	contextItems := make([]ContextItem, 0)
+	if e.memoryManager != nil {
+		memoryItems, err := e.memoryContext(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		contextItems = append(contextItems, memoryItems...)
+	}
+
+	// Also check for sender patterns
+	if item.From != "" {
+		senderContext, err := e.getSenderContext(ctx, item.From)
+		if err == nil && senderContext != nil {
+			contextItems = append(contextItems, *senderContext)
+		}
+	}
+
+	return contextItems, nil
+}
+
+// memoryContext retrieves memories similar to the item
+func (e *Engine) memoryContext(ctx context.Context, item *core.Item) ([]ContextItem, error) {
 	// Build search query from item
 	searchText := fmt.Sprintf("%s %s", item.Subject, item.Body)
 	if len(searchText) > 500 {
 		searchText = searchText[:500]
 	}
 
-	// Retrieve similar memories
+	// Retrieve similar memories; hybrid ranking also catches exact names,
+	// invoice numbers and addresses that embeddings blur
 	memories, err := e.memoryManager.Retrieve(ctx, searchText, memory.RetrieveOptions{
 		Limit: e.config.MaxMemories,
+		Mode:  memory.RetrievalHybrid,
 	})
 	if err != nil {
 		return nil, err
@@ -244,55 +352,101 @@ func (e *Engine) buildRAGContext(ctx context.Context, item *core.Item) ([]Contex
 				Content:   mem.Content,
 				Relevance: relevance,
 				Source:    "memory",
+				HatID:     mem.HatID,
 			})
 		}
 	}
 
-	// Also check for sender patterns
-	if item.From != "" {
-		senderContext, err := e.getSenderContext(ctx, item.From)
-		if err == nil && senderContext != nil {
-			contextItems = append(contextItems, *senderContext)
-		}
-	}
-
 	return contextItems, nil
 }
 
-// getSenderContext retrieves historical context about a sender
+// getSenderContext retrieves what is known about a sender: their profile in
+// the entity graph and memories of earlier interactions
 func (e *Engine) getSenderContext(ctx context.Context, sender string) (*ContextItem, error) {
-	if e.memoryManager == nil {
-		return nil, nil
+	var parts []string
+	source := "sender_history"
+	query := sender
+
+	if e.entities != nil {
+		profile, err := e.entities.About(sender, 3)
+		if err != nil && err != core.ErrEntityNotFound {
+			return nil, err
+		}
+		if profile != nil {
+			parts = append(parts, describeEntity(profile))
+			source = "entity_graph"
+			// Memories mention people by name, not by address
+			query = profile.Entity.Name
+		}
 	}
 
-	// Search for previous interactions with this sender
-	memories, err := e.memoryManager.Retrieve(ctx, fmt.Sprintf("from:%s", sender), memory.RetrieveOptions{
-		Limit: 3,
-	})
-	if err != nil || len(memories) == 0 {
-		return nil, err
-	}
+	if e.memoryManager != nil {
+		// Search for previous interactions with this sender
+		memories, err := e.memoryManager.Retrieve(ctx, query, memory.RetrieveOptions{
+			Limit: 3,
+			Mode:  memory.RetrievalHybrid,
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	// Summarize sender patterns
-	var patterns []string
-	for _, mem := range memories {
-		if mem.Importance > 0.5 {
-			patterns = append(patterns, mem.Content)
+		// Summarize sender patterns
+		var patterns []string
+		for _, mem := range memories {
+			if mem.Importance > 0.5 {
+				patterns = append(patterns, mem.Content)
+			}
+		}
+		if len(patterns) > 0 {
+			parts = append(parts, fmt.Sprintf("Previous interactions with %s: %s", sender, strings.Join(patterns, "; ")))
 		}
 	}
 
-	if len(patterns) == 0 {
+	if len(parts) == 0 {
 		return nil, nil
 	}
 
 	return &ContextItem{
 		Type:      "sender_pattern",
-		Content:   fmt.Sprintf("Previous interactions with %s: %s", sender, strings.Join(patterns, "; ")),
+		Content:   strings.Join(parts, "\n"),
 		Relevance: 0.8,
-		Source:    "sender_history",
+		Source:    source,
 	}, nil
 }
 
+// describeEntity summarizes an entity profile in one line
+func describeEntity(profile *storage.EntityProfile) string {
+	var sb strings.Builder
+	entity := profile.Entity
+
+	sb.WriteString(fmt.Sprintf("Sender is %s (%s", entity.Name, entity.Kind))
+	var others []string
+	for _, alias := range entity.Aliases {
+		if alias.Type == core.AliasTypeEmail {
+			others = append(others, alias.Value)
+		}
+	}
+	if len(others) > 1 {
+		sb.WriteString(", writes from " + strings.Join(others, ", "))
+	}
+	sb.WriteString(fmt.Sprintf("): %d earlier items", profile.ItemCount))
+	if len(profile.Hats) > 0 {
+		sb.WriteString(fmt.Sprintf(", most in %s", profile.Hats[0].HatID))
+	}
+
+	var subjects []string
+	for _, item := range profile.Items {
+		if item.Subject != "" {
+			subjects = append(subjects, item.Subject)
+		}
+	}
+	if len(subjects) > 0 {
+		sb.WriteString(". Recent: " + strings.Join(subjects, "; "))
+	}
+
+	return sb.String()
+}
+
 // buildTriagePrompt constructs the prompt for triage
 func (e *Engine) buildTriagePrompt(item *core.Item, ragContext []ContextItem) string {
 	var sb strings.Builder
@@ -388,6 +542,7 @@ func (e *Engine) fallbackTriage(item *core.Item) *TriageResult {
 		Priority:   PriorityMedium,
 		Urgency:    UrgencyLow,
 		Reasoning:  "Fallback classification based on keywords",
+		Fallback:   true,
 		Actions:    []SuggestedAction{},
 	}
 }
@@ -1,10 +1,18 @@
 package triage
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/quantumlife/quantumlife/internal/core"
+	"github.com/quantumlife/quantumlife/internal/llm"
+	"github.com/quantumlife/quantumlife/internal/storage"
 )
 
 // ============================================================================
@@ -50,6 +58,26 @@ func TestNewEngine(t *testing.T) {
 	}
 }
 
+func TestEngine_Sensitivity_Unclassified(t *testing.T) {
+	engine := NewEngine(nil, nil, DefaultEngineConfig())
+
+	// Still on its space's default hat, so the content is unknown
+	unclassified := &core.Item{HatID: core.HatPersonal}
+	if got := engine.sensitivity(unclassified, nil); got != core.SensitivityConfidential {
+		t.Errorf("unclassified sensitivity = %s, want confidential", got)
+	}
+
+	classified := &core.Item{HatID: core.HatPersonal, Confidence: 0.9}
+	if got := engine.sensitivity(classified, nil); got != core.SensitivityStandard {
+		t.Errorf("classified sensitivity = %s, want standard", got)
+	}
+
+	health := &core.Item{HatID: core.HatHealth, Confidence: 0.9}
+	if got := engine.sensitivity(health, nil); got != core.SensitivityConfidential {
+		t.Errorf("health sensitivity = %s, want confidential", got)
+	}
+}
+
 func TestNewEngine_CustomConfig(t *testing.T) {
 	cfg := EngineConfig{
 		MaxMemories:         10,
@@ -183,6 +211,68 @@ func TestParseTriageResponse_MinimalJSON(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// Triage Tests
+// ============================================================================
+
+// ollamaTriageServer answers every chat request with the next reply, repeating
+// the last one
+func ollamaTriageServer(t *testing.T, replies ...string) (*llm.Router, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"models": []interface{}{}})
+			return
+		}
+		reply := replies[len(replies)-1]
+		if calls < len(replies) {
+			reply = replies[calls]
+		}
+		calls++
+		json.NewEncoder(w).Encode(llm.OllamaChatResponse{Message: llm.OllamaChatMessage{Content: reply}, Done: true})
+	}))
+	t.Cleanup(server.Close)
+
+	router := llm.NewRouter(llm.RouterConfig{
+		Ollama:      llm.NewOllamaClient(llm.OllamaConfig{BaseURL: server.URL}),
+		PreferLocal: true,
+	})
+	return router, &calls
+}
+
+func TestTriage_RepairsInvalidResponse(t *testing.T) {
+	router, calls := ollamaTriageServer(t,
+		`{"hat_id": "financial", "confidence": 0.9, "priority": 3, "urgency": 2, "reasoning": "Bill"}`,
+		`{"hat_id": "finance", "confidence": 0.9, "priority": 3, "urgency": 2, "reasoning": "Bill"}`,
+	)
+	engine := NewEngine(router, nil, EngineConfig{EnableRAG: false})
+
+	result, err := engine.Triage(context.Background(), &core.Item{Subject: "Your bill", Body: "Amount due"})
+	if err != nil {
+		t.Fatalf("Triage() error = %v", err)
+	}
+	if result.HatID != core.HatFinance || result.Confidence != 0.9 || *calls != 2 {
+		t.Errorf("Triage() = %+v after %d calls", result, *calls)
+	}
+}
+
+func TestTriage_FallsBackWhenOutputStaysInvalid(t *testing.T) {
+	router, calls := ollamaTriageServer(t, "I think this is about health.")
+	engine := NewEngine(router, nil, EngineConfig{EnableRAG: false})
+
+	result, err := engine.Triage(context.Background(), &core.Item{Subject: "Doctor appointment", Body: "Tomorrow at 9"})
+	if err != nil {
+		t.Fatalf("Triage() error = %v", err)
+	}
+	if result.HatID != core.HatHealth || result.Confidence != 0.4 {
+		t.Errorf("Triage() = %+v, want the keyword fallback", result)
+	}
+	if *calls != 3 {
+		t.Errorf("calls = %d, want the first attempt and two repairs", *calls)
+	}
+}
+
 // ============================================================================
 // fallbackTriage Tests
 // ============================================================================
@@ -750,6 +840,52 @@ func TestGetSenderContext_NoMemoryManager(t *testing.T) {
 	}
 }
 
+func TestGetSenderContext_EntityGraph(t *testing.T) {
+	db, err := storage.Open(storage.Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	items := storage.NewItemStore(db)
+	entities := storage.NewEntityStore(db)
+	for i, from := range []string{"Alice Smith <alice@acme.io>", "alice@home.net"} {
+		item := &core.Item{ID: core.ItemID(fmt.Sprintf("item-%d", i)), Type: core.ItemTypeEmail,
+			Status: core.ItemStatusRouted, HatID: core.HatProfessional, Subject: fmt.Sprintf("Roadmap v%d", i), From: from,
+			Timestamp: time.Now().Add(time.Duration(i) * time.Hour)}
+		items.Create(item)
+		entities.LinkItem(item, nil)
+	}
+	alice, _ := entities.Resolve("alice@acme.io")
+	home, _ := entities.Resolve("alice@home.net")
+	entities.Merge(alice.ID, home.ID)
+
+	engine := NewEngine(nil, nil, DefaultEngineConfig())
+	engine.SetEntityStore(entities)
+
+	// Either address finds the same person
+	senderCtx, err := engine.getSenderContext(context.Background(), "alice@home.net")
+	if err != nil {
+		t.Fatalf("getSenderContext() error = %v", err)
+	}
+	if senderCtx == nil || senderCtx.Source != "entity_graph" {
+		t.Fatalf("getSenderContext() = %+v, want entity graph context", senderCtx)
+	}
+	for _, want := range []string{"Alice Smith", "2 earlier items", "professional", "Roadmap v1"} {
+		if !strings.Contains(senderCtx.Content, want) {
+			t.Errorf("context %q does not mention %q", senderCtx.Content, want)
+		}
+	}
+
+	// Unknown senders have no context
+	if senderCtx, _ := engine.getSenderContext(context.Background(), "nobody@example.com"); senderCtx != nil {
+		t.Errorf("getSenderContext(unknown) = %+v, want nil", senderCtx)
+	}
+}
+
 // ============================================================================
 // recordDecision Tests (without memory manager)
 // ============================================================================
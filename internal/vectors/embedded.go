@@ -0,0 +1,486 @@
+package vectors
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EmbeddedStore is a flat (exact) cosine-similarity index kept in memory and
+// persisted as one append-only log per collection. It needs no extra services
+// and is fast enough for a personal corpus of tens of thousands of points.
+type EmbeddedStore struct {
+	dir         string
+	collections map[string]*embeddedCollection
+	mu          sync.RWMutex
+}
+
+type embeddedCollection struct {
+	dimension int
+	points    map[string]*embeddedPoint
+	log       *os.File
+	records   int // Records in the log, live or superseded
+}
+
+type embeddedPoint struct {
+	vector  []float32 // Normalized to unit length
+	payload map[string]interface{}
+}
+
+// logRecord is one line of a collection log
+type logRecord struct {
+	Op        string                 `json:"op"` // "dim", "upsert" or "delete"
+	ID        string                 `json:"id,omitempty"`
+	Dimension int                    `json:"dimension,omitempty"`
+	Vector    []float32              `json:"vector,omitempty"`
+	Payload   map[string]interface{} `json:"payload,omitempty"`
+}
+
+const logSuffix = ".vlog"
+
+// NewEmbeddedStore opens (or creates) an embedded index in dir
+func NewEmbeddedStore(dir string) (*EmbeddedStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("embedded vector index requires a directory")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create vector index directory: %w", err)
+	}
+
+	s := &EmbeddedStore{
+		dir:         dir,
+		collections: make(map[string]*embeddedCollection),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read vector index directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), logSuffix) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), logSuffix)
+		if _, err := s.openCollection(name); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Close flushes and closes the collection logs
+func (s *EmbeddedStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var firstErr error
+	for _, c := range s.collections {
+		if c.log == nil {
+			continue
+		}
+		if err := c.log.Sync(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err := c.log.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		c.log = nil
+	}
+	return firstErr
+}
+
+// EnsureCollections creates all required collections
+func (s *EmbeddedStore) EnsureCollections(ctx context.Context, dimension uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, name := range []string{CollectionItems, CollectionMemories, CollectionEntities} {
+		if err := s.createCollection(name, dimension); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateCollection creates a collection if it does not exist yet
+func (s *EmbeddedStore) CreateCollection(ctx context.Context, name string, dimension uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.createCollection(name, dimension)
+}
+
+// DeleteCollection drops a collection and removes its log
+func (s *EmbeddedStore) DeleteCollection(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.collections[name]; ok {
+		if c.log != nil {
+			c.log.Close()
+		}
+		delete(s.collections, name)
+	}
+
+	path := filepath.Join(s.dir, name+logSuffix)
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete collection %s: %w", name, err)
+	}
+	return nil
+}
+
+// createCollection opens a collection and records its dimension, or checks it
+// against the one already recorded. Callers must hold the write lock.
+func (s *EmbeddedStore) createCollection(name string, dimension uint64) error {
+	c, err := s.openCollection(name)
+	if err != nil {
+		return err
+	}
+	if c.dimension == 0 {
+		if err := c.append(logRecord{Op: "dim", Dimension: int(dimension)}); err != nil {
+			return err
+		}
+		c.dimension = int(dimension)
+	} else if c.dimension != int(dimension) {
+		return fmt.Errorf("collection %s has dimension %d, want %d", name, c.dimension, dimension)
+	}
+	return nil
+}
+
+// Upsert inserts or updates vectors
+func (s *EmbeddedStore) Upsert(ctx context.Context, collection string, points []Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, err := s.openCollection(collection)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range points {
+		if p.ID == "" {
+			return fmt.Errorf("failed to upsert points: point ID required")
+		}
+		if c.dimension == 0 {
+			c.dimension = len(p.Vector)
+		}
+		if len(p.Vector) != c.dimension {
+			return fmt.Errorf("failed to upsert points: vector dimension %d, collection %s expects %d", len(p.Vector), collection, c.dimension)
+		}
+	}
+
+	for _, p := range points {
+		payload := normalizePayload(p.Payload)
+		if err := c.append(logRecord{Op: "upsert", ID: p.ID, Vector: p.Vector, Payload: payload}); err != nil {
+			return fmt.Errorf("failed to upsert points: %w", err)
+		}
+		c.points[p.ID] = &embeddedPoint{vector: normalize(p.Vector), payload: payload}
+	}
+
+	return nil
+}
+
+// Search performs semantic search. Filter values must equal the payload value.
+func (s *EmbeddedStore) Search(ctx context.Context, collection string, vector []float32, limit uint64, filter map[string]interface{}) ([]SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return []SearchResult{}, nil
+	}
+	if c.dimension != 0 && len(vector) != c.dimension {
+		return nil, fmt.Errorf("search failed: vector dimension %d, collection %s expects %d", len(vector), collection, c.dimension)
+	}
+	if limit == 0 {
+		limit = 10
+	}
+
+	query := normalize(vector)
+	filter = normalizePayload(filter)
+
+	results := make([]SearchResult, 0, limit)
+	for id, p := range c.points {
+		if !matchesFilter(p.payload, filter) {
+			continue
+		}
+
+		score := dot(query, p.vector)
+		if uint64(len(results)) == limit && score <= results[len(results)-1].Score {
+			continue
+		}
+
+		// Insert in descending score order, keeping at most limit results
+		i := sort.Search(len(results), func(i int) bool { return results[i].Score < score })
+		if uint64(len(results)) < limit {
+			results = append(results, SearchResult{})
+		}
+		copy(results[i+1:], results[i:])
+		results[i] = SearchResult{ID: id, Score: score, Payload: copyPayload(p.payload)}
+	}
+
+	return results, nil
+}
+
+// Delete removes points by ID
+func (s *EmbeddedStore) Delete(ctx context.Context, collection string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[collection]
+	if !ok {
+		return nil
+	}
+
+	for _, id := range ids {
+		if _, exists := c.points[id]; !exists {
+			continue
+		}
+		if err := c.append(logRecord{Op: "delete", ID: id}); err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+		delete(c.points, id)
+	}
+
+	return nil
+}
+
+// Count returns the number of points in a collection
+func (s *EmbeddedStore) Count(collection string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if c, ok := s.collections[collection]; ok {
+		return len(c.points)
+	}
+	return 0
+}
+
+// openCollection returns a loaded collection, replaying its log on first use.
+// Callers must hold the write lock (or be in the constructor).
+func (s *EmbeddedStore) openCollection(name string) (*embeddedCollection, error) {
+	if c, ok := s.collections[name]; ok {
+		return c, nil
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid collection name: %q", name)
+	}
+
+	path := filepath.Join(s.dir, name+logSuffix)
+	c := &embeddedCollection{points: make(map[string]*embeddedPoint)}
+	if err := c.replay(path); err != nil {
+		return nil, fmt.Errorf("load collection %s: %w", name, err)
+	}
+
+	// Rewrite the log when most of it is superseded records
+	if c.records > 1000 && c.records > 2*(len(c.points)+1) {
+		if err := c.compact(path); err != nil {
+			return nil, fmt.Errorf("compact collection %s: %w", name, err)
+		}
+	}
+
+	log, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open collection %s: %w", name, err)
+	}
+	c.log = log
+
+	s.collections[name] = c
+	return c, nil
+}
+
+func (c *embeddedCollection) replay(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var valid int64 // Bytes up to the last complete record
+	torn := false
+
+	reader := bufio.NewReader(f)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			f.Close()
+			return readErr
+		}
+		if len(line) == 0 {
+			break
+		}
+
+		var rec logRecord
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		if readErr == io.EOF || dec.Decode(&rec) != nil {
+			// A torn final write from a crash; everything before it is intact
+			torn = true
+			break
+		}
+		valid += int64(len(line))
+		c.records++
+
+		switch rec.Op {
+		case "dim":
+			c.dimension = rec.Dimension
+		case "upsert":
+			if c.dimension == 0 {
+				c.dimension = len(rec.Vector)
+			}
+			c.points[rec.ID] = &embeddedPoint{vector: normalize(rec.Vector), payload: normalizePayload(rec.Payload)}
+		case "delete":
+			delete(c.points, rec.ID)
+		}
+	}
+	f.Close()
+
+	if torn {
+		return os.Truncate(path, valid)
+	}
+	return nil
+}
+
+func (c *embeddedCollection) append(rec logRecord) error {
+	if c.log == nil {
+		return fmt.Errorf("vector index is closed")
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := c.log.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	c.records++
+	return nil
+}
+
+// compact rewrites the log with only the live points. Vectors are stored
+// normalized, which does not change cosine scores.
+func (c *embeddedCollection) compact(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".compact-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	records := 0
+	if c.dimension > 0 {
+		enc.Encode(logRecord{Op: "dim", Dimension: c.dimension})
+		records++
+	}
+	for id, p := range c.points {
+		if err := enc.Encode(logRecord{Op: "upsert", ID: id, Vector: p.vector, Payload: p.payload}); err != nil {
+			tmp.Close()
+			return err
+		}
+		records++
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	c.records = records
+	return nil
+}
+
+// normalize returns v scaled to unit length
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	out := make([]float32, len(v))
+	if sum == 0 {
+		return out
+	}
+	norm := math.Sqrt(sum)
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// normalizePayload converts values to the types Qdrant returns (string,
+// int64, float64, bool) and drops anything else, so both backends behave alike
+func normalizePayload(payload map[string]interface{}) map[string]interface{} {
+	if payload == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		switch val := v.(type) {
+		case string, int64, float64, bool:
+			result[k] = val
+		case int:
+			result[k] = int64(val)
+		case int32:
+			result[k] = int64(val)
+		case float32:
+			result[k] = float64(val)
+		case json.Number:
+			if i, err := val.Int64(); err == nil {
+				result[k] = i
+			} else if f, err := val.Float64(); err == nil {
+				result[k] = f
+			}
+		}
+	}
+	return result
+}
+
+func matchesFilter(payload, filter map[string]interface{}) bool {
+	for k, want := range filter {
+		got, ok := payload[k]
+		if !ok || got != want {
+			return false
+		}
+	}
+	return true
+}
+
+func copyPayload(payload map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		result[k] = v
+	}
+	return result
+}
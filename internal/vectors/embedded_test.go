@@ -0,0 +1,229 @@
+package vectors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testEmbeddedStore(t *testing.T, dir string) *EmbeddedStore {
+	t.Helper()
+	store, err := NewEmbeddedStore(dir)
+	if err != nil {
+		t.Fatalf("NewEmbeddedStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func samplePoints() []Point {
+	return []Point{
+		{ID: "a", Vector: []float32{1, 0, 0}, Payload: map[string]interface{}{"hat_id": "professional", "importance": 0.9, "created_at": 1700000000}},
+		{ID: "b", Vector: []float32{0.9, 0.1, 0}, Payload: map[string]interface{}{"hat_id": "personal"}},
+		{ID: "c", Vector: []float32{0, 1, 0}, Payload: map[string]interface{}{"hat_id": "professional"}},
+		{ID: "d", Vector: []float32{0, 0, 1}, Payload: map[string]interface{}{"hat_id": "health", "pinned": true}},
+	}
+}
+
+func TestEmbeddedStore_Search(t *testing.T) {
+	store := testEmbeddedStore(t, t.TempDir())
+	ctx := context.Background()
+
+	if err := store.EnsureCollections(ctx, 3); err != nil {
+		t.Fatalf("EnsureCollections() error = %v", err)
+	}
+	if err := store.Upsert(ctx, CollectionMemories, samplePoints()); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	results, err := store.Search(ctx, CollectionMemories, []float32{2, 0, 0}, 2, nil)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "a" || results[1].ID != "b" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].Score < 0.999 || results[1].Score >= results[0].Score {
+		t.Errorf("unexpected scores: %v, %v", results[0].Score, results[1].Score)
+	}
+	if results[0].Payload["created_at"] != int64(1700000000) {
+		t.Errorf("int payload = %T %v, want int64", results[0].Payload["created_at"], results[0].Payload["created_at"])
+	}
+
+	// Payload filters
+	results, _ = store.Search(ctx, CollectionMemories, []float32{1, 0, 0}, 10, map[string]interface{}{"hat_id": "professional"})
+	if len(results) != 2 || results[0].ID != "a" || results[1].ID != "c" {
+		t.Errorf("filtered results: %+v", results)
+	}
+	results, _ = store.Search(ctx, CollectionMemories, []float32{1, 0, 0}, 10, map[string]interface{}{"pinned": true})
+	if len(results) != 1 || results[0].ID != "d" {
+		t.Errorf("bool filter results: %+v", results)
+	}
+
+	// Wrong dimension
+	if _, err := store.Search(ctx, CollectionMemories, []float32{1, 0}, 10, nil); err == nil {
+		t.Error("expected error for mismatched query dimension")
+	}
+	if err := store.Upsert(ctx, CollectionMemories, []Point{{ID: "x", Vector: []float32{1}}}); err == nil {
+		t.Error("expected error for mismatched point dimension")
+	}
+
+	// Unknown collection is empty
+	results, err = store.Search(ctx, "unknown", []float32{1, 0, 0}, 10, nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("Search(unknown) = %v, %v", results, err)
+	}
+}
+
+func TestEmbeddedStore_UpsertAndDelete(t *testing.T) {
+	store := testEmbeddedStore(t, t.TempDir())
+	ctx := context.Background()
+
+	store.Upsert(ctx, CollectionItems, samplePoints())
+
+	// Upsert replaces the vector and payload
+	store.Upsert(ctx, CollectionItems, []Point{{ID: "a", Vector: []float32{0, 1, 0}, Payload: map[string]interface{}{"hat_id": "finance"}}})
+	results, _ := store.Search(ctx, CollectionItems, []float32{0, 1, 0}, 1, map[string]interface{}{"hat_id": "finance"})
+	if len(results) != 1 || results[0].ID != "a" {
+		t.Errorf("upserted point not found: %+v", results)
+	}
+
+	if err := store.Delete(ctx, CollectionItems, []string{"a", "missing"}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if store.Count(CollectionItems) != 3 {
+		t.Errorf("Count() = %d, want 3", store.Count(CollectionItems))
+	}
+}
+
+func TestEmbeddedStore_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, err := NewEmbeddedStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.EnsureCollections(ctx, 3)
+	store.Upsert(ctx, CollectionMemories, samplePoints())
+	store.Delete(ctx, CollectionMemories, []string{"c"})
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Simulate a crash in the middle of a write
+	logPath := filepath.Join(dir, CollectionMemories+logSuffix)
+	f, _ := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	f.WriteString(`{"op":"upsert","id":"torn","vec`)
+	f.Close()
+
+	reopened := testEmbeddedStore(t, dir)
+	if reopened.Count(CollectionMemories) != 3 {
+		t.Errorf("Count() after reopen = %d, want 3", reopened.Count(CollectionMemories))
+	}
+	results, _ := reopened.Search(ctx, CollectionMemories, []float32{1, 0, 0}, 1, map[string]interface{}{"hat_id": "professional"})
+	if len(results) != 1 || results[0].ID != "a" || results[0].Payload["importance"] != 0.9 {
+		t.Errorf("unexpected results after reopen: %+v", results)
+	}
+
+	// The torn record is dropped and new writes land on a clean line
+	if err := reopened.Upsert(ctx, CollectionMemories, []Point{{ID: "e", Vector: []float32{0, 1, 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(logPath)
+	if strings.Contains(string(data), "torn") {
+		t.Error("torn record still present in log")
+	}
+
+	if err := reopened.EnsureCollections(ctx, 768); err == nil {
+		t.Error("expected dimension mismatch error")
+	}
+}
+
+func TestEmbeddedStore_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	store, _ := NewEmbeddedStore(dir)
+	for i := 0; i < 1500; i++ {
+		store.Upsert(ctx, CollectionItems, []Point{{ID: "same", Vector: []float32{float32(i), 1, 0}}})
+	}
+	store.Close()
+
+	logPath := filepath.Join(dir, CollectionItems+logSuffix)
+	before, _ := os.Stat(logPath)
+
+	reopened := testEmbeddedStore(t, dir)
+	after, _ := os.Stat(logPath)
+	if after.Size() >= before.Size()/100 {
+		t.Errorf("log not compacted: %d -> %d bytes", before.Size(), after.Size())
+	}
+	if reopened.Count(CollectionItems) != 1 {
+		t.Errorf("Count() = %d, want 1", reopened.Count(CollectionItems))
+	}
+}
+
+func TestEmbeddedStore_CreateAndDeleteCollection(t *testing.T) {
+	dir := t.TempDir()
+	store := testEmbeddedStore(t, dir)
+	ctx := context.Background()
+
+	if err := store.CreateCollection(ctx, "memories_v2", 3); err != nil {
+		t.Fatalf("CreateCollection() error = %v", err)
+	}
+	if err := store.CreateCollection(ctx, "memories_v2", 3); err != nil {
+		t.Errorf("CreateCollection() should be idempotent, got %v", err)
+	}
+	if err := store.CreateCollection(ctx, "memories_v2", 4); err == nil {
+		t.Error("expected dimension mismatch error")
+	}
+	store.Upsert(ctx, "memories_v2", samplePoints())
+
+	if err := store.DeleteCollection(ctx, "memories_v2"); err != nil {
+		t.Fatalf("DeleteCollection() error = %v", err)
+	}
+	if store.Count("memories_v2") != 0 {
+		t.Error("deleted collection still has points")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "memories_v2"+logSuffix)); !os.IsNotExist(err) {
+		t.Errorf("collection log not removed: %v", err)
+	}
+	if err := store.DeleteCollection(ctx, "missing"); err != nil {
+		t.Errorf("DeleteCollection(missing) error = %v", err)
+	}
+
+	// A deleted collection can be recreated at another dimension
+	if err := store.CreateCollection(ctx, "memories_v2", 4); err != nil {
+		t.Errorf("CreateCollection() after delete error = %v", err)
+	}
+}
+
+func TestOpen_Backends(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	index, err := Open(ctx, OpenConfig{Backend: BackendEmbedded, Dir: dir})
+	if err != nil {
+		t.Fatalf("Open(embedded) error = %v", err)
+	}
+	if _, ok := index.(*EmbeddedStore); !ok {
+		t.Errorf("Open(embedded) = %T", index)
+	}
+	index.Close()
+
+	// Auto falls back to the embedded index when Qdrant is unreachable
+	index, err = Open(ctx, OpenConfig{Backend: BackendAuto, Qdrant: Config{Host: "127.0.0.1", Port: 1}, Dir: dir})
+	if err != nil {
+		t.Fatalf("Open(auto) error = %v", err)
+	}
+	if _, ok := index.(*EmbeddedStore); !ok {
+		t.Errorf("Open(auto) = %T, want embedded fallback", index)
+	}
+	index.Close()
+
+	if _, err := Open(ctx, OpenConfig{Backend: "faiss"}); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
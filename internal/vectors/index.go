@@ -0,0 +1,63 @@
+package vectors
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Index is the vector storage surface used by memory and the agent. It is
+// implemented by Store (Qdrant) and EmbeddedStore (local, no extra services).
+type Index interface {
+	EnsureCollections(ctx context.Context, dimension uint64) error
+	CreateCollection(ctx context.Context, name string, dimension uint64) error
+	DeleteCollection(ctx context.Context, name string) error
+	Upsert(ctx context.Context, collection string, points []Point) error
+	Search(ctx context.Context, collection string, vector []float32, limit uint64, filter map[string]interface{}) ([]SearchResult, error)
+	Delete(ctx context.Context, collection string, ids []string) error
+	Close() error
+}
+
+// Backend names
+const (
+	BackendAuto     = "auto"     // Qdrant if reachable, otherwise embedded
+	BackendQdrant   = "qdrant"   // Qdrant over gRPC
+	BackendEmbedded = "embedded" // Local index stored in the data directory
+)
+
+// OpenConfig selects and configures a vector index
+type OpenConfig struct {
+	Backend string // BackendAuto (default), BackendQdrant or BackendEmbedded
+	Qdrant  Config // Qdrant connection settings
+	Dir     string // Directory for the embedded index
+}
+
+// Open returns the configured vector index
+func Open(ctx context.Context, cfg OpenConfig) (Index, error) {
+	switch cfg.Backend {
+	case BackendEmbedded:
+		return NewEmbeddedStore(cfg.Dir)
+
+	case BackendQdrant:
+		return NewStore(cfg.Qdrant)
+
+	case "", BackendAuto:
+		store, err := NewStore(cfg.Qdrant)
+		if err == nil {
+			healthCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			err = store.Health(healthCtx)
+			cancel()
+			if err == nil {
+				return store, nil
+			}
+			store.Close()
+		}
+		if cfg.Dir == "" {
+			return nil, err
+		}
+		return NewEmbeddedStore(cfg.Dir)
+
+	default:
+		return nil, fmt.Errorf("unknown vector backend: %s", cfg.Backend)
+	}
+}
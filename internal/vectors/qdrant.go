@@ -1,4 +1,4 @@
-// Package vectors provides vector storage via Qdrant.
+// Package vectors provides vector storage via Qdrant or an embedded index.
 package vectors
 
 import (
@@ -70,6 +70,14 @@ func (s *Store) Close() error {
 	return s.client.Close()
 }
 
+// Health checks that Qdrant is reachable
+func (s *Store) Health(ctx context.Context) error {
+	if _, err := s.client.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("qdrant health check failed: %w", err)
+	}
+	return nil
+}
+
 // Collection names
 const (
 	CollectionItems    = "items"
@@ -100,6 +108,30 @@ func (s *Store) EnsureCollections(ctx context.Context, dimension uint64) error {
 	return nil
 }
 
+// CreateCollection creates a collection if it does not exist yet
+func (s *Store) CreateCollection(ctx context.Context, name string, dimension uint64) error {
+	exists, err := s.collectionExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := s.createCollection(ctx, name, dimension); err != nil {
+			return err
+		}
+	}
+	s.collections[name] = true
+	return nil
+}
+
+// DeleteCollection drops a collection and its points
+func (s *Store) DeleteCollection(ctx context.Context, name string) error {
+	if err := s.client.DeleteCollection(ctx, name); err != nil {
+		return fmt.Errorf("failed to delete collection %s: %w", name, err)
+	}
+	delete(s.collections, name)
+	return nil
+}
+
 func (s *Store) collectionExists(ctx context.Context, name string) (bool, error) {
 	exists, err := s.client.CollectionExists(ctx, name)
 	if err != nil {